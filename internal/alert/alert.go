@@ -0,0 +1,134 @@
+// Package alert reports failed syncs to a webhook or a local command, so
+// machines synced unattended do not stay broken without anyone noticing.
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/sync"
+)
+
+// EventSyncFailed is the event of a report sent after a sync with failures.
+const EventSyncFailed = "sync.failed"
+
+// timeout bounds each failure action, so a hung webhook or script cannot
+// hold the lock.
+const timeout = 30 * time.Second
+
+// Report is the JSON payload sent to the webhook and on the command's stdin.
+type Report struct {
+	Event       string           `json:"event"`
+	Host        string           `json:"host"`
+	Time        time.Time        `json:"time"`
+	Clewfile    string           `json:"clewfile,omitempty"`
+	ClewVersion string           `json:"clew_version"`
+	Failed      int              `json:"failed"`
+	Attention   []string         `json:"attention,omitempty"`
+	Operations  []sync.Operation `json:"operations"` // The failed operations
+}
+
+// NewReport builds the report for a sync result, keeping only the failed
+// operations.
+func NewReport(result *sync.Result, clewfilePath, clewVersion string) *Report {
+	host, _ := os.Hostname()
+	r := &Report{
+		Event:       EventSyncFailed,
+		Host:        host,
+		Time:        time.Now().UTC(),
+		Clewfile:    clewfilePath,
+		ClewVersion: clewVersion,
+		Failed:      result.Failed,
+		Attention:   result.Attention,
+		Operations:  []sync.Operation{},
+	}
+	for _, op := range result.Operations {
+		if !op.Success && !op.Skipped {
+			r.Operations = append(r.Operations, op)
+		}
+	}
+	return r
+}
+
+// Sender runs the failure actions configured in the Clewfile.
+type Sender struct {
+	client *http.Client
+	shell  string
+}
+
+// NewSender returns a sender using the default HTTP client settings and sh.
+func NewSender() *Sender {
+	return &Sender{client: &http.Client{Timeout: timeout}, shell: "sh"}
+}
+
+// Send POSTs the report to the webhook and runs the command, whichever are
+// configured. Both are attempted; their errors are joined.
+func (s *Sender) Send(cfg config.AlertsConfig, report *Report) error {
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	var errs []error
+	if cfg.Webhook != "" {
+		if err := s.post(cfg.Webhook, payload); err != nil {
+			errs = append(errs, fmt.Errorf("alert webhook: %w", err))
+		}
+	}
+	if cfg.Exec != "" {
+		if err := s.run(cfg.Exec, payload, report); err != nil {
+			errs = append(errs, fmt.Errorf("alert command: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// post sends the payload to a webhook and expects a 2xx response.
+func (s *Sender) post(url string, payload []byte) error {
+	logging.Tracef("alert: POST %s (%d bytes)", url, len(payload))
+	resp, err := s.client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// run runs the command through the shell with the payload on stdin. The
+// event and failure count are also passed as CLEW_ALERT_EVENT and
+// CLEW_ALERT_FAILED for scripts that do not parse JSON.
+func (s *Sender) run(command string, payload []byte, report *Report) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.shell, "-c", command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"CLEW_ALERT_EVENT="+report.Event,
+		"CLEW_ALERT_FAILED="+strconv.Itoa(report.Failed),
+	)
+
+	done := logging.StartCommand(s.shell, []string{"-c", command})
+	out, err := cmd.CombinedOutput()
+	done(out, err)
+	if err != nil {
+		return fmt.Errorf("'%s' failed: %w", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/sync"
+)
+
+func testResult() *sync.Result {
+	return &sync.Result{
+		Installed: 1,
+		Failed:    1,
+		Errors:    []error{errors.New("install failed")},
+		Operations: []sync.Operation{
+			{ID: 1, Type: "plugin", Name: "ok@m", Action: "add", Success: true},
+			{ID: 2, Type: "plugin", Name: "broken@m", Action: "add", Error: "install failed"},
+			{ID: 3, Type: "plugin", Name: "skipped@m", Action: "add", Skipped: true},
+		},
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	r := NewReport(testResult(), "/home/me/Clewfile.yaml", "0.9.0")
+	if r.Event != EventSyncFailed || r.Failed != 1 || r.ClewVersion != "0.9.0" {
+		t.Errorf("NewReport() = %+v", r)
+	}
+	if len(r.Operations) != 1 || r.Operations[0].Name != "broken@m" {
+		t.Errorf("Operations = %+v, want only the failed one", r.Operations)
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var got Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("payload is not JSON: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	report := NewReport(testResult(), "", "0.9.0")
+	if err := NewSender().Send(config.AlertsConfig{Webhook: server.URL}, report); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got.Event != EventSyncFailed || len(got.Operations) != 1 {
+		t.Errorf("webhook received %+v", got)
+	}
+}
+
+func TestSendWebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := NewSender().Send(config.AlertsConfig{Webhook: server.URL}, NewReport(testResult(), "", "0.9.0"))
+	if err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("Send() error = %v, want status 500", err)
+	}
+}
+
+func TestSendExec(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "alert")
+	cfg := config.AlertsConfig{Exec: `{ echo "$CLEW_ALERT_EVENT $CLEW_ALERT_FAILED"; cat; } > '` + out + `'`}
+
+	if err := NewSender().Send(cfg, NewReport(testResult(), "", "0.9.0")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, payload, _ := strings.Cut(string(data), "\n")
+	if first != "sync.failed 1" {
+		t.Errorf("environment = %q, want \"sync.failed 1\"", first)
+	}
+	if !strings.Contains(payload, `"name":"broken@m"`) {
+		t.Errorf("stdin payload = %s", payload)
+	}
+
+	if err := NewSender().Send(config.AlertsConfig{Exec: "exit 3"}, NewReport(testResult(), "", "0.9.0")); err == nil {
+		t.Error("Send() should report a failing command")
+	}
+}
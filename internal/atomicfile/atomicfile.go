@@ -0,0 +1,106 @@
+// Package atomicfile writes files so that readers never observe a partial
+// write, even if clew crashes or the machine loses power mid-write.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// BackupSuffix is appended to a file's name for the copy kept by
+// WriteFileWithBackup.
+const BackupSuffix = ".bak"
+
+// WriteFile writes data to a temporary file in the same directory, fsyncs
+// it, and renames it over path. The directory is fsynced afterwards so the
+// rename itself is durable. When path is a symlink, the file it points to
+// is replaced and the link is kept.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	target, err := resolve(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(target)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+
+	// Clean up the temp file on any failure before the rename
+	renamed := false
+	defer func() {
+		if !renamed {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmpPath, target); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	renamed = true
+
+	return syncDir(dir)
+}
+
+// WriteFileWithBackup behaves like WriteFile but first preserves the current
+// contents of path (if any) as path + BackupSuffix.
+func WriteFileWithBackup(path string, data []byte, perm os.FileMode) error {
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := WriteFile(path+BackupSuffix, existing, perm); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read %s for backup: %w", path, err)
+	}
+
+	return WriteFile(path, data, perm)
+}
+
+// resolve returns the file path names after following symlinks, or path
+// itself when it does not exist yet. Renaming over the resolved file keeps
+// links, such as a settings file symlinked into a dotfiles repository.
+func resolve(path string) (string, error) {
+	target, err := filepath.EvalSymlinks(path)
+	if os.IsNotExist(err) {
+		return path, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return target, nil
+}
+
+// syncDir fsyncs a directory so a completed rename survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory %s: %w", dir, err)
+	}
+	defer func() { _ = d.Close() }()
+
+	// Some filesystems do not support fsync on directories; the rename has
+	// already happened, so this is best effort.
+	_ = d.Sync()
+	return nil
+}
@@ -0,0 +1,98 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "installed_plugins.json")
+
+	if err := WriteFile(path, []byte(`{"version": 2}`), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"version": 2}` {
+		t.Errorf("contents = %q", data)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("perm = %v, want 0600", info.Mode().Perm())
+	}
+
+	// No temp files left behind
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want 1", len(entries))
+	}
+}
+
+func TestWriteFile_MissingDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "file.json")
+	if err := WriteFile(path, []byte("{}"), 0644); err == nil {
+		t.Error("WriteFile() into missing directory should fail")
+	}
+}
+
+func TestWriteFileWithBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+
+	// First write has nothing to back up
+	if err := WriteFileWithBackup(path, []byte("v1"), 0644); err != nil {
+		t.Fatalf("WriteFileWithBackup() error = %v", err)
+	}
+	if _, err := os.Stat(path + BackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected no backup on first write, stat err = %v", err)
+	}
+
+	if err := WriteFileWithBackup(path, []byte("v2"), 0644); err != nil {
+		t.Fatalf("WriteFileWithBackup() error = %v", err)
+	}
+
+	backup, err := os.ReadFile(path + BackupSuffix)
+	if err != nil || string(backup) != "v1" {
+		t.Errorf("backup = %q, %v; want v1", backup, err)
+	}
+	current, _ := os.ReadFile(path)
+	if string(current) != "v2" {
+		t.Errorf("current = %q, want v2", current)
+	}
+}
+
+func TestWriteFile_Symlink(t *testing.T) {
+	dotfiles := t.TempDir()
+	target := filepath.Join(dotfiles, "settings.json")
+	if err := os.WriteFile(target, []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(link, []byte(`{"model": "opus"}`), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("link replaced by a regular file: %v", err)
+	}
+	if data, err := os.ReadFile(target); err != nil || string(data) != `{"model": "opus"}` {
+		t.Errorf("target = %q, %v; want the new contents", data, err)
+	}
+	// The temp file was created next to the target, not the link
+	if entries, _ := os.ReadDir(filepath.Dir(link)); len(entries) != 1 {
+		t.Errorf("link directory has %d entries, want 1", len(entries))
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"sort"
 	"time"
 
+	"github.com/adamancini/clew/internal/atomicfile"
 	"github.com/adamancini/clew/internal/state"
+	"github.com/adamancini/clew/internal/xdg"
 )
 
 // Backup represents a single backup snapshot.
@@ -18,6 +20,7 @@ type Backup struct {
 	CreatedAt   time.Time    `json:"created_at"`
 	Note        string       `json:"note,omitempty"`
 	ClewVersion string       `json:"clew_version"`
+	Home        string       `json:"home,omitempty"` // Home directory on the machine that made the backup
 	State       BackupState  `json:"state"`
 }
 
@@ -53,6 +56,11 @@ func NewManager(version string) (*Manager, error) {
 	}, nil
 }
 
+// Dir returns the directory backups are stored in.
+func (m *Manager) Dir() string {
+	return m.backupDir
+}
+
 // NewManagerWithDir creates a backup manager with a custom directory (for testing).
 func NewManagerWithDir(backupDir, version string) *Manager {
 	return &Manager{
@@ -63,16 +71,11 @@ func NewManagerWithDir(backupDir, version string) *Manager {
 
 // getBackupDir returns the default backup directory path.
 func getBackupDir() (string, error) {
-	// Use XDG_CACHE_HOME or default to ~/.cache
-	cacheDir := os.Getenv("XDG_CACHE_HOME")
-	if cacheDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to determine home directory: %w", err)
-		}
-		cacheDir = filepath.Join(home, ".cache")
+	dir, err := xdg.CacheDir()
+	if err != nil {
+		return "", err
 	}
-	return filepath.Join(cacheDir, "clew", "backups"), nil
+	return filepath.Join(dir, "backups"), nil
 }
 
 // Create creates a new backup from the current state.
@@ -86,11 +89,15 @@ func (m *Manager) Create(currentState *state.State, note string) (*Backup, error
 	now := time.Now()
 	id := now.Format("2006-01-02-150405")
 
+	// Recorded so restore on another machine can remap paths under it
+	home, _ := os.UserHomeDir()
+
 	backup := &Backup{
 		ID:          id,
 		CreatedAt:   now,
 		Note:        note,
 		ClewVersion: m.clewVersion,
+		Home:        home,
 		State: BackupState{
 			Marketplaces: currentState.Marketplaces,
 			Plugins:      currentState.Plugins,
@@ -106,7 +113,7 @@ func (m *Manager) Create(currentState *state.State, note string) (*Backup, error
 		return nil, fmt.Errorf("failed to marshal backup: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	if err := atomicfile.WriteFile(path, data, 0644); err != nil {
 		return nil, fmt.Errorf("failed to write backup file: %w", err)
 	}
 
@@ -2,6 +2,9 @@ package backup
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/adamancini/clew/internal/config"
 )
 
 // DefaultKeepCount is the default number of backups to retain.
@@ -45,3 +48,58 @@ func (m *Manager) Prune(keep int) (*PruneResult, error) {
 
 	return result, nil
 }
+
+// PruneByPolicy removes the backups that no rule of policy keeps.
+func (m *Manager) PruneByPolicy(policy config.RetentionPolicy) (*PruneResult, error) {
+	backups, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := Retained(backups, policy)
+	result := &PruneResult{Kept: len(keep)}
+	for _, backup := range backups {
+		if keep[backup.ID] {
+			continue
+		}
+		if err := m.Delete(backup.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete backup %s: %w", backup.ID, err)
+		}
+		result.Deleted = append(result.Deleted, backup)
+	}
+
+	return result, nil
+}
+
+// Retained returns the IDs of the backups policy keeps. backups must be
+// sorted newest first, as List returns them. Periods are in local time and
+// weeks are ISO weeks.
+func Retained(backups []BackupInfo, policy config.RetentionPolicy) map[string]bool {
+	keep := make(map[string]bool)
+	for i := 0; i < policy.KeepLast && i < len(backups); i++ {
+		keep[backups[i].ID] = true
+	}
+	keepPeriods(keep, backups, policy.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") })
+	keepPeriods(keep, backups, policy.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	keepPeriods(keep, backups, policy.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") })
+	return keep
+}
+
+// keepPeriods keeps the newest backup of each of the n most recent
+// periods that have a backup, where period names a backup's period.
+func keepPeriods(keep map[string]bool, backups []BackupInfo, n int, period func(time.Time) string) {
+	seen := make(map[string]bool)
+	for _, backup := range backups {
+		if len(seen) == n {
+			return
+		}
+		p := period(backup.CreatedAt.Local())
+		if !seen[p] {
+			seen[p] = true
+			keep[backup.ID] = true
+		}
+	}
+}
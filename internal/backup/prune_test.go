@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/adamancini/clew/internal/config"
 	"github.com/adamancini/clew/internal/state"
 )
 
@@ -153,3 +154,69 @@ func TestDefaultKeepCount(t *testing.T) {
 		t.Errorf("DefaultKeepCount = %v, want 30", DefaultKeepCount)
 	}
 }
+
+func TestRetained(t *testing.T) {
+	// Newest first: two on Mar 16, one a day back to Mar 10, then Feb and Jan
+	dates := []string{
+		"2026-03-16T18:00:00", "2026-03-16T09:00:00", "2026-03-15T09:00:00", "2026-03-14T09:00:00",
+		"2026-03-13T09:00:00", "2026-03-12T09:00:00", "2026-03-11T09:00:00", "2026-03-10T09:00:00",
+		"2026-02-20T09:00:00", "2026-01-05T09:00:00",
+	}
+	var backups []BackupInfo
+	for _, d := range dates {
+		created, err := time.ParseInLocation("2006-01-02T15:04:05", d, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		backups = append(backups, BackupInfo{ID: d, CreatedAt: created})
+	}
+
+	tests := []struct {
+		name   string
+		policy config.RetentionPolicy
+		want   []string
+	}{
+		{"keep last", config.RetentionPolicy{KeepLast: 2}, dates[:2]},
+		// One per day: the 18:00 backup stands for Mar 16
+		{"keep daily", config.RetentionPolicy{KeepDaily: 3}, []string{dates[0], dates[2], dates[3]}},
+		// ISO weeks: Mar 16 (W12), Mar 15-10 (W11), Feb 20 (W08)
+		{"keep weekly", config.RetentionPolicy{KeepWeekly: 3}, []string{dates[0], dates[2], dates[8]}},
+		{"keep monthly", config.RetentionPolicy{KeepMonthly: 12}, []string{dates[0], dates[8], dates[9]}},
+		{"rules combine", config.RetentionPolicy{KeepLast: 1, KeepDaily: 2, KeepMonthly: 2}, []string{dates[0], dates[2], dates[8]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep := Retained(backups, tt.policy)
+			if len(keep) != len(tt.want) {
+				t.Errorf("Retained() kept %v, want %v", keep, tt.want)
+			}
+			for _, id := range tt.want {
+				if !keep[id] {
+					t.Errorf("Retained() dropped %s", id)
+				}
+			}
+		})
+	}
+}
+
+func TestManager_PruneByPolicy(t *testing.T) {
+	manager := NewManagerWithDir(t.TempDir(), "v1.0.0")
+	currentState := &state.State{
+		Marketplaces: make(map[string]state.MarketplaceState),
+		Plugins:      make(map[string]state.PluginState),
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := manager.Create(currentState, ""); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		time.Sleep(time.Second)
+	}
+
+	result, err := manager.PruneByPolicy(config.RetentionPolicy{KeepLast: 1})
+	if err != nil {
+		t.Fatalf("PruneByPolicy() error = %v", err)
+	}
+	if result.Kept != 1 || len(result.Deleted) != 2 {
+		t.Errorf("PruneByPolicy() kept %d, deleted %d, want 1 and 2", result.Kept, len(result.Deleted))
+	}
+}
@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PathMapping rewrites paths under From to the same path under To.
+type PathMapping struct {
+	From string
+	To   string
+}
+
+// String formats the mapping as given to --map.
+func (m PathMapping) String() string {
+	return m.From + "=" + m.To
+}
+
+// ParsePathMappings parses "FROM=TO" specs, e.g. "/Users/alice=/home/alice".
+func ParsePathMappings(specs []string) ([]PathMapping, error) {
+	var mappings []PathMapping
+	for _, spec := range specs {
+		from, to, ok := strings.Cut(spec, "=")
+		from, to = filepath.Clean(from), filepath.Clean(to)
+		if !ok || !filepath.IsAbs(from) || !filepath.IsAbs(to) {
+			return nil, fmt.Errorf("invalid path mapping %q: want FROM=TO with absolute paths, e.g. /Users/alice=/home/alice", spec)
+		}
+		mappings = append(mappings, PathMapping{From: from, To: to})
+	}
+	return mappings, nil
+}
+
+// DetectHome returns the home directory of the machine the backup was made
+// on. Backups record it since it was added; for older ones it is inferred
+// from the first path under a ".claude" directory, or "" if there is none.
+func (b *Backup) DetectHome() string {
+	if b.Home != "" {
+		return b.Home
+	}
+
+	var paths []string
+	for _, m := range b.State.Marketplaces {
+		paths = append(paths, m.InstallLocation)
+	}
+	for _, p := range b.State.Plugins {
+		paths = append(paths, p.InstallPath)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if i := strings.Index(path, "/.claude/"); i > 0 {
+			return path[:i]
+		}
+	}
+	return ""
+}
+
+// HomeMappings combines explicit mappings with one from the backup's home
+// directory to home, unless they are the same or an explicit mapping
+// already covers it.
+func (b *Backup) HomeMappings(explicit []PathMapping, home string) []PathMapping {
+	from := b.DetectHome()
+	if from == "" || home == "" || from == home {
+		return explicit
+	}
+	for _, m := range explicit {
+		if m.From == from {
+			return explicit
+		}
+	}
+	return append(explicit, PathMapping{From: from, To: home})
+}
+
+// Remap rewrites absolute paths in the backup's state: marketplace
+// repositories and clone locations, and plugin install and project paths.
+// The longest matching prefix wins.
+func (b *Backup) Remap(mappings []PathMapping) {
+	if len(mappings) == 0 {
+		return
+	}
+	sorted := append([]PathMapping(nil), mappings...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].From) > len(sorted[j].From) })
+
+	for alias, m := range b.State.Marketplaces {
+		m.Repo = remapPath(m.Repo, sorted)
+		m.InstallLocation = remapPath(m.InstallLocation, sorted)
+		b.State.Marketplaces[alias] = m
+	}
+	for name, p := range b.State.Plugins {
+		p.InstallPath = remapPath(p.InstallPath, sorted)
+		for i := range p.Installs {
+			p.Installs[i].InstallPath = remapPath(p.Installs[i].InstallPath, sorted)
+			p.Installs[i].ProjectPath = remapPath(p.Installs[i].ProjectPath, sorted)
+		}
+		b.State.Plugins[name] = p
+	}
+}
+
+// remapPath applies the first mapping whose From is path or a parent of it.
+func remapPath(path string, mappings []PathMapping) string {
+	if !filepath.IsAbs(path) {
+		return path // Repos such as "owner/repo" are not paths
+	}
+	for _, m := range mappings {
+		if path == m.From {
+			return m.To
+		}
+		if rest, ok := strings.CutPrefix(path, m.From+"/"); ok {
+			return filepath.Join(m.To, rest)
+		}
+	}
+	return path
+}
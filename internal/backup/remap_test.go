@@ -0,0 +1,93 @@
+package backup
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestParsePathMappings(t *testing.T) {
+	got, err := ParsePathMappings([]string{"/Users/alice=/home/alice", "/opt/src/=/srv/src"})
+	if err != nil {
+		t.Fatalf("ParsePathMappings() error = %v", err)
+	}
+	want := []PathMapping{{"/Users/alice", "/home/alice"}, {"/opt/src", "/srv/src"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePathMappings() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"/Users/alice", "Users/alice=/home/alice", "/Users/alice=~/x"} {
+		if _, err := ParsePathMappings([]string{bad}); err == nil {
+			t.Errorf("ParsePathMappings(%q) should fail", bad)
+		}
+	}
+}
+
+func TestDetectHome(t *testing.T) {
+	b := &Backup{State: BackupState{
+		Marketplaces: map[string]state.MarketplaceState{
+			"m": {InstallLocation: "/Users/alice/.claude/plugins/marketplaces/m"},
+		},
+	}}
+	if got := b.DetectHome(); got != "/Users/alice" {
+		t.Errorf("DetectHome() = %q, want inferred /Users/alice", got)
+	}
+
+	b.Home = "/Users/bob"
+	if got := b.DetectHome(); got != "/Users/bob" {
+		t.Errorf("DetectHome() = %q, want recorded /Users/bob", got)
+	}
+}
+
+func TestHomeMappings(t *testing.T) {
+	b := &Backup{Home: "/Users/alice"}
+
+	if got := b.HomeMappings(nil, "/home/alice"); !reflect.DeepEqual(got, []PathMapping{{"/Users/alice", "/home/alice"}}) {
+		t.Errorf("HomeMappings() = %v, want home mapping", got)
+	}
+	if got := b.HomeMappings(nil, "/Users/alice"); len(got) != 0 {
+		t.Errorf("HomeMappings() = %v, want none on the same machine", got)
+	}
+	explicit := []PathMapping{{"/Users/alice", "/data/alice"}}
+	if got := b.HomeMappings(explicit, "/home/alice"); !reflect.DeepEqual(got, explicit) {
+		t.Errorf("HomeMappings() = %v, want explicit mapping to win", got)
+	}
+}
+
+func TestRemap(t *testing.T) {
+	b := &Backup{State: BackupState{
+		Marketplaces: map[string]state.MarketplaceState{
+			"github": {Repo: "owner/repo", InstallLocation: "/Users/alice/.claude/plugins/marketplaces/github"},
+			"local":  {Repo: "/Users/alice/src/marketplace"},
+		},
+		Plugins: map[string]state.PluginState{
+			"foo@local": {
+				InstallPath: "/Users/alice/.claude/plugins/cache/foo",
+				Installs: []state.PluginInstall{
+					{Scope: "project", ProjectPath: "/Users/alice/src/app", InstallPath: "/Users/alice/.claude/plugins/cache/foo"},
+				},
+			},
+			"bar@github": {InstallPath: "/Users/alicesmith/bar"}, // Not under /Users/alice
+		},
+	}}
+
+	b.Remap([]PathMapping{
+		{"/Users/alice", "/home/alice"},
+		{"/Users/alice/src", "/srv/src"}, // Longer prefix wins
+	})
+
+	if m := b.State.Marketplaces["github"]; m.Repo != "owner/repo" || m.InstallLocation != "/home/alice/.claude/plugins/marketplaces/github" {
+		t.Errorf("github marketplace = %+v", m)
+	}
+	if got := b.State.Marketplaces["local"].Repo; got != "/srv/src/marketplace" {
+		t.Errorf("local repo = %q, want /srv/src/marketplace", got)
+	}
+	foo := b.State.Plugins["foo@local"]
+	if foo.InstallPath != "/home/alice/.claude/plugins/cache/foo" || foo.Installs[0].ProjectPath != "/srv/src/app" {
+		t.Errorf("foo@local = %+v", foo)
+	}
+	if got := b.State.Plugins["bar@github"].InstallPath; got != "/Users/alicesmith/bar" {
+		t.Errorf("bar@github path = %q, want unchanged", got)
+	}
+}
@@ -0,0 +1,238 @@
+// Package baseline records an approved snapshot of the installed
+// marketplaces and plugins, so teams that sign off on every change can
+// detect when a machine no longer matches what was approved.
+//
+// A baseline is meant to be committed. It keeps only the fields a reviewer
+// cares about (sources, refs, enabled state, scope and version) and leaves
+// out install paths and timestamps, which differ between machines and runs.
+// The exception is a local marketplace, whose directory is its source.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// FormatVersion is the baseline file format version.
+const FormatVersion = 1
+
+// DefaultFile is the baseline file name used when none is given.
+const DefaultFile = "clew-baseline.json"
+
+// Baseline is an approved snapshot of the system state.
+type Baseline struct {
+	FormatVersion int           `json:"format_version"`
+	CreatedAt     time.Time     `json:"created_at"`
+	ClewVersion   string        `json:"clew_version"`
+	Hash          string        `json:"hash"`
+	Marketplaces  []Marketplace `json:"marketplaces"`
+	Plugins       []Plugin      `json:"plugins"`
+}
+
+// Marketplace is the approved state of a marketplace.
+type Marketplace struct {
+	Alias string `json:"alias"`
+	Repo  string `json:"repo"`
+	Ref   string `json:"ref,omitempty"`
+	Path  string `json:"path,omitempty"` // Directory of a local marketplace
+}
+
+// Plugin is the approved state of a plugin.
+type Plugin struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Scope   string `json:"scope,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// New snapshots s.
+func New(s *state.State, clewVersion string) (*Baseline, error) {
+	marketplaces, plugins := snapshot(s)
+	hash, err := hashSnapshot(marketplaces, plugins)
+	if err != nil {
+		return nil, err
+	}
+	return &Baseline{
+		FormatVersion: FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		ClewVersion:   clewVersion,
+		Hash:          hash,
+		Marketplaces:  marketplaces,
+		Plugins:       plugins,
+	}, nil
+}
+
+// snapshot extracts the approved fields of s, sorted by name.
+func snapshot(s *state.State) ([]Marketplace, []Plugin) {
+	marketplaces := make([]Marketplace, 0, len(s.Marketplaces))
+	for alias, m := range s.Marketplaces {
+		approved := Marketplace{Alias: alias, Repo: m.Repo, Ref: m.Ref}
+		if m.Repo == "" {
+			approved.Path = m.InstallLocation
+		}
+		marketplaces = append(marketplaces, approved)
+	}
+	sort.Slice(marketplaces, func(i, j int) bool { return marketplaces[i].Alias < marketplaces[j].Alias })
+
+	plugins := make([]Plugin, 0, len(s.Plugins))
+	for name, p := range s.Plugins {
+		plugins = append(plugins, Plugin{Name: name, Enabled: p.Enabled, Scope: p.Scope, Version: p.Version})
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+
+	return marketplaces, plugins
+}
+
+// hashSnapshot returns the hex SHA256 of a snapshot.
+func hashSnapshot(marketplaces []Marketplace, plugins []Plugin) (string, error) {
+	data, err := json.Marshal(struct {
+		Marketplaces []Marketplace `json:"marketplaces"`
+		Plugins      []Plugin      `json:"plugins"`
+	}{marketplaces, plugins})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash state: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Save writes the baseline to path as indented JSON.
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+	data = append(data, '\n')
+	if err := atomicfile.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write baseline file: %w", err)
+	}
+	return nil
+}
+
+// Load reads a baseline file and verifies that its hash matches its
+// contents, so a hand-edited baseline is not silently accepted.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline file: %w", err)
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline file: %w", err)
+	}
+	if b.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported baseline format version %d (expected %d)", b.FormatVersion, FormatVersion)
+	}
+
+	hash, err := hashSnapshot(b.Marketplaces, b.Plugins)
+	if err != nil {
+		return nil, err
+	}
+	if hash != b.Hash {
+		return nil, fmt.Errorf("%s does not match its recorded hash; it was edited by hand (run 'clew baseline save' to approve the current state)", path)
+	}
+	return &b, nil
+}
+
+// Change is a difference between the baseline and the live system.
+type Change struct {
+	Type   string `json:"type" yaml:"type"` // "marketplace", "plugin", or "baseline" for a hash mismatch
+	Name   string `json:"name" yaml:"name"`
+	Before string `json:"before,omitempty" yaml:"before,omitempty"` // Empty if added since the baseline
+	After  string `json:"after,omitempty" yaml:"after,omitempty"`   // Empty if removed since the baseline
+}
+
+// Compare returns how s differs from the baseline, marketplaces first,
+// each sorted by name. It returns nil if s matches. A snapshot whose hash
+// differs even though no item reads differently is still reported, as a
+// change of the baseline hash.
+func (b *Baseline) Compare(s *state.State) ([]Change, error) {
+	marketplaces, plugins := snapshot(s)
+	hash, err := hashSnapshot(marketplaces, plugins)
+	if err != nil {
+		return nil, err
+	}
+	if hash == b.Hash {
+		return nil, nil
+	}
+
+	before := make(map[string]string)
+	after := make(map[string]string)
+	for _, m := range b.Marketplaces {
+		before[m.Alias] = describeMarketplace(m)
+	}
+	for _, m := range marketplaces {
+		after[m.Alias] = describeMarketplace(m)
+	}
+	changes := compare("marketplace", before, after)
+
+	before = make(map[string]string)
+	after = make(map[string]string)
+	for _, p := range b.Plugins {
+		before[p.Name] = describePlugin(p)
+	}
+	for _, p := range plugins {
+		after[p.Name] = describePlugin(p)
+	}
+	changes = append(changes, compare("plugin", before, after)...)
+	if len(changes) == 0 {
+		changes = []Change{{Type: "baseline", Name: "hash", Before: b.Hash, After: hash}}
+	}
+	return changes, nil
+}
+
+// compare diffs two name-to-description maps.
+func compare(itemType string, before, after map[string]string) []Change {
+	names := make(map[string]bool)
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+
+	var changes []Change
+	for name := range names {
+		if before[name] != after[name] {
+			changes = append(changes, Change{Type: itemType, Name: name, Before: before[name], After: after[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// describeMarketplace renders a marketplace as "repo", "repo@ref" or, for a
+// local one, "local /path".
+func describeMarketplace(m Marketplace) string {
+	if m.Repo == "" {
+		return "local " + m.Path
+	}
+	if m.Ref == "" {
+		return m.Repo
+	}
+	return m.Repo + "@" + m.Ref
+}
+
+// describePlugin renders a plugin as "enabled, user, 1.2.0".
+func describePlugin(p Plugin) string {
+	s := "disabled"
+	if p.Enabled {
+		s = "enabled"
+	}
+	if p.Scope != "" {
+		s += ", " + p.Scope
+	}
+	if p.Version != "" {
+		s += ", " + p.Version
+	}
+	return s
+}
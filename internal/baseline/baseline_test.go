@@ -0,0 +1,143 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/state"
+)
+
+func testState() *state.State {
+	return &state.State{
+		Marketplaces: map[string]state.MarketplaceState{
+			"official": {Alias: "official", Repo: "anthropics/claude-plugins", InstallLocation: "/home/a/.claude/plugins/official"},
+		},
+		Plugins: map[string]state.PluginState{
+			"linter@official": {Name: "linter", Marketplace: "official", Enabled: true, Scope: "user", Version: "1.0.0", LastUpdated: "2026-01-01"},
+			"review@official": {Name: "review", Marketplace: "official", Enabled: false, Scope: "user"},
+		},
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	b, err := New(testState(), "1.2.3")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	if err := b.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Hash != b.Hash || !reflect.DeepEqual(loaded.Plugins, b.Plugins) {
+		t.Errorf("Load() = %+v, want %+v", loaded, b)
+	}
+
+	changes, err := loaded.Compare(testState())
+	if err != nil || changes != nil {
+		t.Errorf("Compare() = %v, %v; want no changes", changes, err)
+	}
+}
+
+func TestHashIgnoresMachineSpecificFields(t *testing.T) {
+	b, _ := New(testState(), "")
+
+	s := testState()
+	m := s.Marketplaces["official"]
+	m.InstallLocation = "/Users/b/.claude/plugins/official"
+	s.Marketplaces["official"] = m
+	p := s.Plugins["linter@official"]
+	p.LastUpdated = "2026-06-01"
+	p.InstallPath = "/elsewhere"
+	s.Plugins["linter@official"] = p
+
+	if changes, _ := b.Compare(s); changes != nil {
+		t.Errorf("Compare() = %v, want no changes", changes)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	b, _ := New(testState(), "")
+
+	s := testState()
+	s.Marketplaces["official"] = state.MarketplaceState{Alias: "official", Repo: "anthropics/claude-plugins", Ref: "v2"}
+	p := s.Plugins["linter@official"]
+	p.Version = "1.1.0"
+	s.Plugins["linter@official"] = p
+	delete(s.Plugins, "review@official")
+	s.Plugins["extra@official"] = state.PluginState{Name: "extra", Marketplace: "official", Enabled: true, Scope: "user"}
+
+	changes, err := b.Compare(s)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	want := []Change{
+		{Type: "marketplace", Name: "official", Before: "anthropics/claude-plugins", After: "anthropics/claude-plugins@v2"},
+		{Type: "plugin", Name: "extra@official", After: "enabled, user"},
+		{Type: "plugin", Name: "linter@official", Before: "enabled, user, 1.0.0", After: "enabled, user, 1.1.0"},
+		{Type: "plugin", Name: "review@official", Before: "disabled, user"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Compare() =\n%+v\nwant\n%+v", changes, want)
+	}
+}
+
+func TestCompareLocalMarketplaces(t *testing.T) {
+	b, _ := New(testState(), "")
+
+	s := testState()
+	s.Marketplaces["dev"] = state.MarketplaceState{Alias: "dev", InstallLocation: "/home/a/src/plugins"}
+	changes, err := b.Compare(s)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	want := []Change{{Type: "marketplace", Name: "dev", After: "local /home/a/src/plugins"}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Compare() = %+v, want %+v", changes, want)
+	}
+
+	// Moving the directory is a change too
+	b, _ = New(s, "")
+	s.Marketplaces["dev"] = state.MarketplaceState{Alias: "dev", InstallLocation: "/home/a/src/other"}
+	if changes, _ := b.Compare(s); len(changes) != 1 || changes[0].After != "local /home/a/src/other" {
+		t.Errorf("Compare() = %+v, want the moved local marketplace", changes)
+	}
+}
+
+func TestCompareReportsHashMismatch(t *testing.T) {
+	b, _ := New(testState(), "")
+	b.Hash = "stale"
+
+	changes, err := b.Compare(testState())
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].Type != "baseline" || changes[0].Before != "stale" {
+		t.Errorf("Compare() = %+v, want a baseline hash change", changes)
+	}
+}
+
+func TestLoadRejectsEditedBaseline(t *testing.T) {
+	b, _ := New(testState(), "")
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	edited := strings.Replace(string(data), `"enabled": false`, `"enabled": true`, 1)
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "edited by hand") {
+		t.Errorf("Load() error = %v, want a hash mismatch", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Package catalog reads the plugin listings published by installed
+// marketplaces (their .claude-plugin/marketplace.json).
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// IndexFile is the marketplace index path relative to a marketplace clone.
+const IndexFile = ".claude-plugin/marketplace.json"
+
+// Entry is one plugin listed by a marketplace.
+type Entry struct {
+	Name        string   `json:"name" yaml:"name"`
+	Marketplace string   `json:"marketplace" yaml:"marketplace"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string   `json:"version,omitempty" yaml:"version,omitempty"`
+	Category    string   `json:"category,omitempty" yaml:"category,omitempty"`
+	Keywords    []string `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+	// Repo is the GitHub "owner/repo" hosting the plugin's source: its own
+	// repository, or the marketplace's when the plugin lives inside it
+	Repo string `json:"repo,omitempty" yaml:"repo,omitempty"`
+	// Dir is the plugin's directory inside the marketplace clone, for
+	// plugins whose source is a relative path; empty otherwise
+	Dir string `json:"-" yaml:"-"`
+}
+
+// FullName returns the plugin@marketplace name used in Clewfiles.
+func (e Entry) FullName() string {
+	return e.Name + "@" + e.Marketplace
+}
+
+// fsIndex represents the relevant parts of marketplace.json.
+type fsIndex struct {
+	Plugins []struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		Version     string          `json:"version"`
+		Category    string          `json:"category"`
+		Keywords    []string        `json:"keywords"`
+		Tags        []string        `json:"tags"`
+		Source      json.RawMessage `json:"source"`
+		Repository  string          `json:"repository"`
+	} `json:"plugins"`
+}
+
+// Load reads the index of every marketplace with an install location and
+// returns their entries sorted by full name. Unreadable indexes are skipped.
+func Load(marketplaces map[string]state.MarketplaceState) []Entry {
+	var entries []Entry
+	for alias, m := range marketplaces {
+		if m.InstallLocation == "" {
+			continue
+		}
+		listed, err := LoadIndex(alias, m)
+		if err != nil {
+			logging.Decisionf("catalog: skipping marketplace %s: %v", alias, err)
+			continue
+		}
+		entries = append(entries, listed...)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].FullName() < entries[j].FullName() })
+	return entries
+}
+
+// LoadIndex reads a single marketplace's index.
+func LoadIndex(alias string, m state.MarketplaceState) ([]Entry, error) {
+	path := filepath.Join(m.InstallLocation, IndexFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	logging.Tracef("catalog: read %s (%d bytes)", path, len(data))
+
+	var index fsIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	entries := make([]Entry, 0, len(index.Plugins))
+	for _, p := range index.Plugins {
+		if p.Name == "" {
+			continue
+		}
+		repo := sourceRepo(p.Source)
+		if repo == "" {
+			repo = GitHubRepo(p.Repository)
+		}
+		if repo == "" {
+			repo = GitHubRepo(m.Repo)
+		}
+		entries = append(entries, Entry{
+			Name:        p.Name,
+			Marketplace: alias,
+			Description: p.Description,
+			Version:     p.Version,
+			Category:    p.Category,
+			Keywords:    append(p.Keywords, p.Tags...),
+			Repo:        repo,
+			Dir:         sourceDir(m.InstallLocation, p.Source),
+		})
+	}
+	return entries, nil
+}
+
+// sourceRepo returns the GitHub repo of an object-form plugin source
+// ({"source": "github", "repo": "owner/repo"} or a github.com URL).
+func sourceRepo(raw json.RawMessage) string {
+	var src struct {
+		Repo string `json:"repo"`
+		URL  string `json:"url"`
+	}
+	if err := json.Unmarshal(raw, &src); err != nil {
+		return "" // Relative path within the marketplace
+	}
+	if src.Repo != "" {
+		return GitHubRepo(src.Repo)
+	}
+	return GitHubRepo(src.URL)
+}
+
+// sourceDir returns the directory of a relative-path plugin source inside
+// the marketplace clone at root, or "" for remote sources.
+func sourceDir(root string, raw json.RawMessage) string {
+	var rel string
+	if err := json.Unmarshal(raw, &rel); err != nil || rel == "" {
+		return ""
+	}
+	dir := filepath.Join(root, rel)
+	// Ignore sources that point outside the clone
+	if r, err := filepath.Rel(root, dir); err != nil || strings.HasPrefix(r, "..") {
+		return ""
+	}
+	return dir
+}
+
+// GitHubRepo normalizes "owner/repo" and github.com URLs to "owner/repo".
+// It returns "" for anything that is not hosted on GitHub.
+func GitHubRepo(s string) string {
+	s = strings.TrimSpace(s)
+	for _, prefix := range []string{"https://github.com/", "http://github.com/", "git@github.com:", "github.com/"} {
+		if strings.HasPrefix(s, prefix) {
+			s = strings.TrimPrefix(s, prefix)
+			break
+		}
+	}
+	if strings.Contains(s, "://") || strings.HasPrefix(s, ".") || strings.HasPrefix(s, "/") {
+		return ""
+	}
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git")
+
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// Search returns entries whose name, description, category or keywords
+// contain every word of query (case-insensitive).
+func Search(entries []Entry, query string) []Entry {
+	words := strings.Fields(strings.ToLower(query))
+	var matches []Entry
+	for _, e := range entries {
+		haystack := strings.ToLower(strings.Join(append([]string{e.FullName(), e.Description, e.Category}, e.Keywords...), " "))
+		matched := true
+		for _, w := range words {
+			if !strings.Contains(haystack, w) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, e)
+		}
+	}
+	return matches
+}
+
+// Find returns the entries for a plugin name. A full plugin@marketplace
+// name matches at most one entry; a bare name may match several.
+func Find(entries []Entry, name string) []Entry {
+	var found []Entry
+	for _, e := range entries {
+		if e.FullName() == name || (!strings.Contains(name, "@") && e.Name == name) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
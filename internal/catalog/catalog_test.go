@@ -0,0 +1,116 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adamancini/clew/internal/state"
+)
+
+const testIndex = `{
+  "name": "official",
+  "plugins": [
+    {"name": "code-review", "description": "Review pull requests", "version": "1.0.0", "category": "development", "source": "./plugins/code-review"},
+    {"name": "go-tools", "description": "Go helpers", "keywords": ["golang"], "source": {"source": "github", "repo": "someone/go-tools"}},
+    {"name": "docs", "description": "Docs writer", "source": {"source": "url", "url": "https://gitlab.com/x/docs.git"}}
+  ]
+}`
+
+func writeIndex(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, IndexFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	marketplaces := map[string]state.MarketplaceState{
+		"official": {Alias: "official", Repo: "anthropics/claude-plugins", InstallLocation: writeIndex(t, testIndex)},
+		"broken":   {Alias: "broken", InstallLocation: writeIndex(t, "{not json")},
+		"missing":  {Alias: "missing", InstallLocation: t.TempDir()},
+	}
+
+	entries := Load(marketplaces)
+	if len(entries) != 3 {
+		t.Fatalf("Load() returned %d entries, want 3: %v", len(entries), entries)
+	}
+
+	byName := map[string]Entry{}
+	for _, e := range entries {
+		byName[e.FullName()] = e
+	}
+
+	tests := []struct {
+		name string
+		repo string
+	}{
+		{"code-review@official", "anthropics/claude-plugins"}, // Lives in the marketplace repo
+		{"go-tools@official", "someone/go-tools"},
+		{"docs@official", "anthropics/claude-plugins"}, // Non-GitHub source falls back
+	}
+	for _, tt := range tests {
+		if got := byName[tt.name].Repo; got != tt.repo {
+			t.Errorf("%s Repo = %q, want %q", tt.name, got, tt.repo)
+		}
+	}
+
+	if e := byName["code-review@official"]; e.Version != "1.0.0" || e.Category != "development" {
+		t.Errorf("code-review entry = %+v", e)
+	}
+	want := filepath.Join(marketplaces["official"].InstallLocation, "plugins", "code-review")
+	if got := byName["code-review@official"].Dir; got != want {
+		t.Errorf("code-review Dir = %q, want %q", got, want)
+	}
+	if got := byName["go-tools@official"].Dir; got != "" {
+		t.Errorf("go-tools Dir = %q, want none for a remote source", got)
+	}
+}
+
+func TestGitHubRepo(t *testing.T) {
+	tests := map[string]string{
+		"owner/repo":                           "owner/repo",
+		"https://github.com/owner/repo.git":    "owner/repo",
+		"https://github.com/owner/repo/tree/x": "owner/repo",
+		"git@github.com:owner/repo.git":        "owner/repo",
+		"https://gitlab.com/owner/repo":        "",
+		"./plugins/local":                      "",
+		"":                                     "",
+	}
+	for in, want := range tests {
+		if got := GitHubRepo(in); got != want {
+			t.Errorf("GitHubRepo(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSearchAndFind(t *testing.T) {
+	entries := []Entry{
+		{Name: "code-review", Marketplace: "official", Description: "Review pull requests"},
+		{Name: "go-tools", Marketplace: "official", Keywords: []string{"golang"}},
+		{Name: "go-tools", Marketplace: "community", Description: "Go helpers"},
+	}
+
+	if got := Search(entries, "REVIEW pull"); len(got) != 1 || got[0].Name != "code-review" {
+		t.Errorf("Search(review pull) = %v", got)
+	}
+	if got := Search(entries, "golang"); len(got) != 1 || got[0].Marketplace != "official" {
+		t.Errorf("Search(golang) = %v", got)
+	}
+	if got := Search(entries, "nothing"); len(got) != 0 {
+		t.Errorf("Search(nothing) = %v", got)
+	}
+
+	if got := Find(entries, "go-tools"); len(got) != 2 {
+		t.Errorf("Find(go-tools) = %v, want both marketplaces", got)
+	}
+	if got := Find(entries, "go-tools@community"); len(got) != 1 || got[0].Marketplace != "community" {
+		t.Errorf("Find(go-tools@community) = %v", got)
+	}
+}
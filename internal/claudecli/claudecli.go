@@ -0,0 +1,154 @@
+// Package claudecli detects which plugin commands and flags the installed
+// claude CLI supports, so clew can adapt the commands it runs instead of
+// failing mid-sync on an older or newer release.
+package claudecli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/xdg"
+)
+
+// Runner runs the claude CLI. sync.CommandRunner satisfies it.
+type Runner interface {
+	Run(name string, args ...string) ([]byte, error)
+}
+
+// Capabilities are the plugin commands and flags a claude release supports.
+type Capabilities struct {
+	Version      string `json:"version"`       // Output of `claude --version`
+	Marketplace  bool   `json:"marketplace"`   // claude plugin marketplace add
+	Install      bool   `json:"install"`       // claude plugin install
+	InstallScope bool   `json:"install_scope"` // claude plugin install --scope
+	Enable       bool   `json:"enable"`        // claude plugin enable/disable
+}
+
+// All assumes every capability, for when the CLI cannot be probed.
+func All() *Capabilities {
+	return &Capabilities{Marketplace: true, Install: true, InstallScope: true, Enable: true}
+}
+
+// Missing describes a required capability the release lacks, or returns ""
+// if it has it. need is one of "marketplace", "install" or "enable".
+func (c *Capabilities) Missing(need string) string {
+	var ok bool
+	var command string
+	switch need {
+	case "marketplace":
+		ok, command = c.Marketplace, "claude plugin marketplace"
+	case "install":
+		ok, command = c.Install, "claude plugin install"
+	case "enable":
+		ok, command = c.Enable, "claude plugin enable/disable"
+	default:
+		return ""
+	}
+	if ok {
+		return ""
+	}
+	version := c.Version
+	if version == "" {
+		version = "(unknown version)"
+	}
+	return fmt.Sprintf("claude %s does not support '%s' (update Claude Code with 'claude update')", version, command)
+}
+
+// DefaultCachePath returns the capability cache in clew's cache directory.
+func DefaultCachePath() (string, error) {
+	dir, err := xdg.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "claude-capabilities.json"), nil
+}
+
+// Detect returns the capabilities of the claude CLI. The help output is
+// probed once per claude version and cached in cachePath; later runs only
+// ask for the version. A probe that failed or could not read the help is
+// used for this run but not cached, so the next run probes again.
+func Detect(runner Runner, cachePath string) (*Capabilities, error) {
+	out, err := runner.Run("claude", "--version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run claude --version: %w", err)
+	}
+	version := strings.TrimSpace(string(out))
+
+	var cached Capabilities
+	if data, err := os.ReadFile(cachePath); err == nil {
+		if json.Unmarshal(data, &cached) == nil && cached.Version == version {
+			return &cached, nil
+		}
+	}
+
+	caps, ok := probe(runner)
+	caps.Version = version
+	if !ok {
+		return caps, nil
+	}
+	if data, err := json.Marshal(caps); err == nil {
+		if os.MkdirAll(filepath.Dir(cachePath), 0755) == nil {
+			_ = atomicfile.WriteFile(cachePath, data, 0644)
+		}
+	}
+	return caps, nil
+}
+
+// probe reads the help of `claude plugin` and `claude plugin install`. ok
+// reports whether every help was read and understood; otherwise caps is a
+// guess for this run only.
+func probe(runner Runner) (caps *Capabilities, ok bool) {
+	caps = &Capabilities{}
+	out, err := runner.Run("claude", "plugin", "--help")
+	if err != nil {
+		return caps, false // No plugin command, or claude failed this once
+	}
+	commands := parseCommands(string(out))
+	if commands == nil {
+		return All(), false // Unrecognized help layout; do not guess wrong
+	}
+	caps.Marketplace = commands["marketplace"]
+	caps.Install = commands["install"]
+	caps.Enable = commands["enable"] && commands["disable"]
+
+	if caps.Install {
+		out, err := runner.Run("claude", "plugin", "install", "--help")
+		if err != nil {
+			return caps, false
+		}
+		caps.InstallScope = strings.Contains(string(out), "--scope")
+	}
+	return caps, true
+}
+
+// parseCommands returns the subcommand names (and aliases) listed under the
+// "Commands:" heading of help output, or nil if there is no such heading.
+func parseCommands(help string) map[string]bool {
+	var commands map[string]bool
+	scanner := bufio.NewScanner(strings.NewReader(help))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if commands == nil {
+			if strings.EqualFold(trimmed, "Commands:") {
+				commands = make(map[string]bool)
+			}
+			continue
+		}
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			break // Next heading
+		}
+		for _, name := range strings.Split(strings.Fields(trimmed)[0], "|") {
+			commands[name] = true
+		}
+	}
+	return commands
+}
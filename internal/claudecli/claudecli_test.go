@@ -0,0 +1,117 @@
+package claudecli
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fakeRunner struct {
+	outputs map[string]string
+	calls   []string
+}
+
+func (f *fakeRunner) Run(name string, args ...string) ([]byte, error) {
+	cmd := name + " " + strings.Join(args, " ")
+	f.calls = append(f.calls, cmd)
+	out, ok := f.outputs[cmd]
+	if !ok {
+		return nil, errors.New("error: unknown command")
+	}
+	return []byte(out), nil
+}
+
+const pluginHelp = `Usage: claude plugin [options] [command]
+
+Manage Claude Code plugins
+
+Options:
+  -h, --help                 Display help for command
+
+Commands:
+  validate <path>            Validate a plugin or marketplace manifest
+  marketplace                Manage Claude Code marketplaces
+  install|i [options] <plugin>  Install a plugin from available marketplaces
+  uninstall|remove <plugin>  Uninstall an installed plugin
+  enable <plugin>            Enable a disabled plugin
+  disable <plugin>           Disable an enabled plugin
+  help [command]             display help for command
+`
+
+func TestDetect(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{
+		"claude --version":             "1.0.40 (Claude Code)\n",
+		"claude plugin --help":         pluginHelp,
+		"claude plugin install --help": "Options:\n  -s, --scope <scope>  Installation scope\n",
+	}}
+	cachePath := filepath.Join(t.TempDir(), "caps.json")
+
+	caps, err := Detect(runner, cachePath)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	want := Capabilities{Version: "1.0.40 (Claude Code)", Marketplace: true, Install: true, InstallScope: true, Enable: true}
+	if *caps != want {
+		t.Errorf("Detect() = %+v, want %+v", *caps, want)
+	}
+
+	// The same version is served from the cache
+	runner.calls = nil
+	if _, err := Detect(runner, cachePath); err != nil {
+		t.Fatal(err)
+	}
+	if len(runner.calls) != 1 {
+		t.Errorf("calls = %v, want only claude --version", runner.calls)
+	}
+
+	// A new version is probed again
+	runner.outputs["claude --version"] = "0.9.0 (Claude Code)\n"
+	runner.outputs["claude plugin --help"] = "Commands:\n  install <plugin>  Install a plugin\n"
+	delete(runner.outputs, "claude plugin install --help")
+	caps, err = Detect(runner, cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = Capabilities{Version: "0.9.0 (Claude Code)", Install: true}
+	if *caps != want {
+		t.Errorf("Detect() after upgrade = %+v, want %+v", *caps, want)
+	}
+	if missing := caps.Missing("marketplace"); !strings.Contains(missing, "does not support 'claude plugin marketplace'") {
+		t.Errorf("Missing(marketplace) = %q", missing)
+	}
+	if missing := caps.Missing("install"); missing != "" {
+		t.Errorf("Missing(install) = %q, want none", missing)
+	}
+}
+
+func TestDetectWithoutPluginCommand(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{"claude --version": "0.2.0\n"}}
+	caps, err := Detect(runner, filepath.Join(t.TempDir(), "caps.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.Marketplace || caps.Install || caps.Enable {
+		t.Errorf("Detect() = %+v, want no plugin capabilities", *caps)
+	}
+}
+
+func TestDetectDoesNotCacheFailedProbe(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{"claude --version": "1.0.40 (Claude Code)\n"}}
+	cachePath := filepath.Join(t.TempDir(), "caps.json")
+
+	// claude plugin --help fails this once
+	if _, err := Detect(runner, cachePath); err != nil {
+		t.Fatal(err)
+	}
+
+	runner.outputs["claude plugin --help"] = pluginHelp
+	runner.outputs["claude plugin install --help"] = "Options:\n  -s, --scope <scope>  Installation scope\n"
+	caps, err := Detect(runner, cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !caps.Marketplace || !caps.InstallScope {
+		t.Errorf("Detect() = %+v, want the failed probe retried", *caps)
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"text/tabwriter"
@@ -12,6 +13,8 @@ import (
 	"github.com/adamancini/clew/internal/backup"
 	"github.com/adamancini/clew/internal/config"
 	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/logging"
 	"github.com/adamancini/clew/internal/output"
 	"github.com/adamancini/clew/internal/state"
 	"github.com/adamancini/clew/internal/sync"
@@ -69,7 +72,9 @@ func newBackupListCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
 		Short: "List all backups",
-		Long:  `List displays all available backups with their creation time, notes, and size.`,
+		Long: `List displays all available backups with their creation time, notes, and size.
+Creation times are shown relative to now ("2 days ago"); use -v for exact
+times, or -o json for full timestamps.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBackupList()
 		},
@@ -77,7 +82,13 @@ func newBackupListCmd() *cobra.Command {
 }
 
 func newBackupRestoreCmd() *cobra.Command {
-	var yes bool
+	var (
+		yes       bool
+		wait      bool
+		maps      []string
+		noAutoMap bool
+		dryRun    bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "restore <id>",
@@ -86,15 +97,33 @@ func newBackupRestoreCmd() *cobra.Command {
 
 Use 'latest' as the ID to restore the most recent backup.
 
+Backups record absolute paths, such as local marketplace repositories and
+project directories. When a backup made on another machine is restored,
+paths under its home directory are rewritten to this machine's home
+directory. Use --map FROM=TO (repeatable) for other paths, and --no-auto-map
+to keep paths as recorded.
+
 This command shows the changes that will be made and prompts for confirmation
-before applying them.`,
+before applying them. Use --dry-run to print the exact commands a restore
+would run, as 'clew sync --show-commands' does, without changing anything.`,
+		Example: `  clew backup restore latest
+  clew backup restore latest --dry-run
+  clew backup restore 2025-01-15-093000 --map /Users/alice=/home/alice`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runBackupRestore(args[0], yes)
+			mappings, err := backup.ParsePathMappings(maps)
+			if err != nil {
+				return err
+			}
+			return runBackupRestore(args[0], yes, wait, dryRun, mappings, !noAutoMap)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for another running clew process instead of failing")
+	cmd.Flags().StringArrayVar(&maps, "map", nil, "Rewrite paths under FROM to TO (FROM=TO, repeatable)")
+	cmd.Flags().BoolVar(&noAutoMap, "no-auto-map", false, "Do not rewrite the backup machine's home directory to this one")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the commands the restore would run and exit")
 
 	return cmd
 }
@@ -112,20 +141,36 @@ func newBackupDeleteCmd() *cobra.Command {
 }
 
 func newBackupPruneCmd() *cobra.Command {
-	var keep int
+	var (
+		keep   int
+		policy string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "prune",
 		Short: "Remove old backups",
 		Long: `Prune deletes old backups, keeping only the most recent N backups.
 
-By default, keeps the 30 most recent backups.`,
+By default, keeps the 30 most recent backups.
+
+With --policy NAME, the backups kept are chosen by a retention policy from
+the Clewfile's backups.retention section, which can keep the newest backup
+of each recent day, week and month as well as the last N. The "default"
+policy keeps the last 10, 7 daily and 4 weekly unless the Clewfile
+declares its own.`,
+		Example: `  clew backup prune --keep 10
+  clew backup prune --policy default`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if policy != "" {
+				return runBackupPrunePolicy(policy)
+			}
 			return runBackupPrune(keep)
 		},
 	}
 
 	cmd.Flags().IntVar(&keep, "keep", backup.DefaultKeepCount, "Number of backups to keep")
+	cmd.Flags().StringVar(&policy, "policy", "", "Keep the backups chosen by this Clewfile retention policy")
+	cmd.MarkFlagsMutuallyExclusive("keep", "policy")
 
 	return cmd
 }
@@ -206,7 +251,7 @@ func runBackupList() error {
 			}
 			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
 				b.ID,
-				b.CreatedAt.Format("2006-01-02 15:04:05"),
+				output.Timestamp(b.CreatedAt, verbose),
 				note,
 				sizeStr,
 			)
@@ -220,8 +265,10 @@ func runBackupList() error {
 	return nil
 }
 
-// runBackupRestore restores from a backup.
-func runBackupRestore(id string, skipConfirm bool) error {
+// runBackupRestore restores from a backup, rewriting paths with mappings and,
+// if autoMap is set, from the backup's home directory to this one. With
+// dryRun it only prints the commands the restore would run.
+func runBackupRestore(id string, skipConfirm, wait, dryRun bool, mappings []backup.PathMapping, autoMap bool) error {
 	manager, err := backup.NewManager(clewVersion)
 	if err != nil {
 		return err
@@ -238,6 +285,18 @@ func runBackupRestore(id string, skipConfirm bool) error {
 	if bak.Note != "" {
 		fmt.Printf("Note: %s\n", bak.Note)
 	}
+	if autoMap {
+		if home, err := os.UserHomeDir(); err == nil {
+			mappings = bak.HomeMappings(mappings, home)
+		}
+	}
+	if len(mappings) > 0 {
+		bak.Remap(mappings)
+		fmt.Println("Remapping paths:")
+		for _, m := range mappings {
+			fmt.Printf("  %s -> %s\n", m.From, m.To)
+		}
+	}
 	fmt.Println()
 
 	// Read current state
@@ -265,21 +324,29 @@ func runBackupRestore(id string, skipConfirm bool) error {
 	printRestoreDiff(diffResult)
 	fmt.Println()
 
+	if dryRun {
+		printRestoreCommands(os.Stdout, diffResult)
+		return nil
+	}
+
 	// Confirm
 	if !skipConfirm {
-		fmt.Print("Proceed? [y/n] ")
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+		ok, err := confirmProceed()
 		if err != nil {
-			return fmt.Errorf("failed to read response: %w", err)
+			return err
 		}
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
+		if !ok {
 			fmt.Println("Restore cancelled.")
 			return nil
 		}
 	}
 
+	l, err := acquireLock("backup restore", wait)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = l.Release() }()
+
 	// Execute sync to restore
 	syncer := sync.NewSyncer()
 	result, err := syncer.Execute(diffResult, sync.Options{
@@ -313,6 +380,36 @@ func runBackupRestore(id string, skipConfirm bool) error {
 	return nil
 }
 
+// printRestoreCommands prints the commands a restore would run, in the
+// form sync --show-commands uses. Like sync, restore does not remove items
+// missing from the backup, so removals are left out.
+func printRestoreCommands(w io.Writer, diffResult *diff.Result) {
+	var commands []diff.Command
+	for _, c := range diffResult.GenerateCommands() {
+		if !c.Removal {
+			commands = append(commands, c)
+		}
+	}
+	if len(commands) == 0 {
+		fmt.Fprintln(w, "# No commands needed (items missing from the backup are not removed)")
+		return
+	}
+	fmt.Fprintln(w, "# Dry run: a restore would run these commands")
+	fmt.Fprint(w, diff.FormatCommands(commands, true))
+}
+
+// confirmProceed asks the user whether to continue.
+func confirmProceed() (bool, error) {
+	fmt.Print("Proceed? [y/n] ")
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read response: %w", err)
+	}
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
+
 // runBackupDelete deletes a backup.
 func runBackupDelete(id string) error {
 	manager, err := backup.NewManager(clewVersion)
@@ -339,7 +436,50 @@ func runBackupPrune(keep int) error {
 	if err != nil {
 		return err
 	}
+	return printPruneResult(result)
+}
+
+// runBackupPrunePolicy removes the backups a named retention policy does
+// not keep.
+func runBackupPrunePolicy(name string) error {
+	policy, err := retentionPolicy(name)
+	if err != nil {
+		return err
+	}
+	logging.Decisionf("Retention policy %s: keep_last %d, keep_daily %d, keep_weekly %d, keep_monthly %d",
+		name, policy.KeepLast, policy.KeepDaily, policy.KeepWeekly, policy.KeepMonthly)
+
+	manager, err := backup.NewManager(clewVersion)
+	if err != nil {
+		return err
+	}
+	result, err := manager.PruneByPolicy(policy)
+	if err != nil {
+		return err
+	}
+	return printPruneResult(result)
+}
+
+// retentionPolicy looks up a retention policy in the Clewfile. The default
+// policy is available without a Clewfile.
+func retentionPolicy(name string) (config.RetentionPolicy, error) {
+	var backups config.BackupsConfig
+	if clewfilePath, err := config.FindClewfile(configPath); err == nil {
+		clewfile, err := config.Load(clewfilePath)
+		if err != nil {
+			return config.RetentionPolicy{}, err
+		}
+		backups = clewfile.Backups
+	}
+	policy, ok := backups.Policy(name)
+	if !ok {
+		return config.RetentionPolicy{}, fmt.Errorf("no retention policy %q in the Clewfile's backups.retention", name)
+	}
+	return policy, nil
+}
 
+// printPruneResult reports the backups prune deleted.
+func printPruneResult(result *backup.PruneResult) error {
 	format, err := output.ParseFormat(outputFormat)
 	if err != nil {
 		return err
@@ -347,11 +487,11 @@ func runBackupPrune(keep int) error {
 
 	if format == output.FormatText {
 		if len(result.Deleted) == 0 {
-			fmt.Printf("No backups to prune. Keeping %d backups.\n", result.Kept)
+			fmt.Println(i18n.N(i18n.BackupPruneNone, result.Kept))
 			return nil
 		}
 
-		fmt.Printf("Pruned %d backup(s), keeping %d:\n", len(result.Deleted), result.Kept)
+		fmt.Println(i18n.N(i18n.BackupPruned, len(result.Deleted), result.Kept))
 		for _, b := range result.Deleted {
 			fmt.Printf("  - %s (%s)\n", b.ID, b.CreatedAt.Format("2006-01-02 15:04:05"))
 		}
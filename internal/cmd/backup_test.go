@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestPrintRestoreCommands(t *testing.T) {
+	result := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "tools", Action: diff.ActionAdd, Desired: &config.Marketplace{Repo: "acme/tools"}},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "lint@tools", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "lint@tools"}},
+			{Name: "extra@tools", Action: diff.ActionRemove, Current: &state.PluginState{Marketplace: "tools"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	printRestoreCommands(&buf, result)
+	got := buf.String()
+	for _, want := range []string{"claude plugin marketplace add acme/tools", "claude plugin install lint@tools"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "uninstall") {
+		t.Errorf("output includes a removal restore would not run:\n%s", got)
+	}
+
+	buf.Reset()
+	printRestoreCommands(&buf, &diff.Result{Plugins: result.Plugins[1:]})
+	if !strings.Contains(buf.String(), "No commands needed") {
+		t.Errorf("removal-only restore printed:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/baseline"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func newBaselineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "baseline",
+		Short: "Approve the current state and detect changes to it",
+		Long: `Baseline records an approved snapshot of the installed marketplaces and
+plugins in a file meant to be committed, and checks the live system against
+it. Changes then need an explicit 'clew baseline save' (and a reviewed
+commit of the file) before checks pass again.
+
+The snapshot covers marketplace sources and refs, and plugin enabled state,
+scope and version. Install paths and timestamps are left out, so the same
+baseline can be checked on every machine; only a local marketplace is
+recorded by its directory, since that is its source.`,
+	}
+
+	cmd.AddCommand(newBaselineSaveCmd())
+	cmd.AddCommand(newBaselineCheckCmd())
+
+	return cmd
+}
+
+func newBaselineSaveCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "save",
+		Short: "Record the current state as the approved baseline",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBaselineSave(file)
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", baseline.DefaultFile, "Baseline file to write")
+
+	return cmd
+}
+
+func newBaselineCheckCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Fail if the live system differs from the approved baseline",
+		Long: `Check compares the installed marketplaces and plugins with the baseline
+file and exits non-zero if anything was added, removed or changed since it
+was approved.`,
+		Example: `  clew baseline check
+  clew baseline check --file approved/laptop.json --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBaselineCheck(file)
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", baseline.DefaultFile, "Baseline file to check against")
+
+	return cmd
+}
+
+// runBaselineSave snapshots the current state to file.
+func runBaselineSave(file string) error {
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	b, err := baseline.New(currentState, clewVersion)
+	if err != nil {
+		return err
+	}
+	if err := b.Save(file); err != nil {
+		return err
+	}
+
+	if !quiet {
+		fmt.Println(i18n.N(i18n.BaselineSaved, len(b.Plugins), i18n.N(i18n.BaselineMarketplaces, len(b.Marketplaces)), file))
+	}
+	return nil
+}
+
+// BaselineCheck is the result of checking the live system against a baseline.
+type BaselineCheck struct {
+	File     string            `json:"file" yaml:"file"`
+	Approved string            `json:"approved" yaml:"approved"` // When the baseline was saved
+	InSync   bool              `json:"in_sync" yaml:"in_sync"`
+	Changes  []baseline.Change `json:"changes,omitempty" yaml:"changes,omitempty"`
+}
+
+// runBaselineCheck compares the current state with file and fails on any change.
+func runBaselineCheck(file string) error {
+	b, err := baseline.Load(file)
+	if err != nil {
+		return err
+	}
+
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	changes, err := b.Compare(currentState)
+	if err != nil {
+		return err
+	}
+	result := &BaselineCheck{
+		File:     file,
+		Approved: b.CreatedAt.Format("2006-01-02 15:04:05 MST"),
+		InSync:   len(changes) == 0,
+		Changes:  changes,
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		if err := writer.Write(result); err != nil {
+			return err
+		}
+	} else if !quiet || !result.InSync {
+		printBaselineCheck(os.Stdout, result)
+	}
+
+	if !result.InSync {
+		return errors.New(i18n.N(i18n.BaselineChanges, len(changes)))
+	}
+	return nil
+}
+
+// printBaselineCheck prints the changes since the baseline was approved.
+func printBaselineCheck(out io.Writer, result *BaselineCheck) {
+	if result.InSync {
+		_, _ = fmt.Fprintf(out, "Matches the baseline approved %s\n", result.Approved)
+		return
+	}
+
+	_, _ = fmt.Fprintf(out, "Differs from the baseline approved %s:\n", result.Approved)
+	for _, c := range result.Changes {
+		switch {
+		case c.Before == "":
+			_, _ = fmt.Fprintf(out, "  + %s %s (%s)\n", c.Type, c.Name, c.After)
+		case c.After == "":
+			_, _ = fmt.Fprintf(out, "  - %s %s (%s)\n", c.Type, c.Name, c.Before)
+		default:
+			_, _ = fmt.Fprintf(out, "  ~ %s %s (%s -> %s)\n", c.Type, c.Name, c.Before, c.After)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/adamancini/clew/internal/baseline"
+)
+
+func TestPrintBaselineCheck(t *testing.T) {
+	result := &BaselineCheck{
+		Approved: "2026-10-01 09:00:00 UTC",
+		Changes: []baseline.Change{
+			{Type: "marketplace", Name: "official", Before: "anthropics/claude-plugins", After: "anthropics/claude-plugins@v2"},
+			{Type: "plugin", Name: "extra@official", After: "enabled, user"},
+			{Type: "plugin", Name: "review@official", Before: "disabled, user"},
+		},
+	}
+
+	var buf bytes.Buffer
+	printBaselineCheck(&buf, result)
+	want := `Differs from the baseline approved 2026-10-01 09:00:00 UTC:
+  ~ marketplace official (anthropics/claude-plugins -> anthropics/claude-plugins@v2)
+  + plugin extra@official (enabled, user)
+  - plugin review@official (disabled, user)
+`
+	if buf.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	printBaselineCheck(&buf, &BaselineCheck{Approved: "2026-10-01 09:00:00 UTC", InSync: true})
+	if want := "Matches the baseline approved 2026-10-01 09:00:00 UTC\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/xdg"
+)
+
+// BootstrapOptions configures the bootstrap workflow.
+type BootstrapOptions struct {
+	From         string // URL or git repository to fetch the Clewfile from
+	Dest         string // Directory to write a fetched Clewfile into
+	Force        bool   // Overwrite an existing Clewfile in Dest
+	NoBackup     bool   // Skip creating a backup before sync
+	SkipGitCheck bool   // Skip git status checks for local repositories
+	Wait         bool   // Wait for another clew process to release the lock
+}
+
+func newBootstrapCmd() *cobra.Command {
+	var opts BootstrapOptions
+
+	cmd := &cobra.Command{
+		Use:   "bootstrap",
+		Short: "One-shot machine setup: fetch Clewfile, backup, sync, verify",
+		Long: `Bootstrap prepares a machine in a single, idempotent step. It is intended
+for dotfiles installers and onboarding scripts and never prompts.
+
+Steps:
+  1. Check that the claude CLI is installed
+  2. Locate the Clewfile, or fetch it with --from (URL or git repository)
+  3. Create a backup of the current state (skip with --no-backup)
+  4. Sync the system to match the Clewfile
+  5. Verify the system is in sync
+
+A fetched Clewfile is written to $XDG_CONFIG_HOME/claude (or --dest). If a
+Clewfile already exists there it is reused unless --force is given, so running
+bootstrap repeatedly is safe.
+
+Examples:
+  clew bootstrap
+  clew bootstrap --from https://example.com/dotfiles/Clewfile.yaml
+  clew bootstrap --from owner/dotfiles`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBootstrap(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.From, "from", "", "Fetch the Clewfile from a URL or git repository (owner/repo, *.git)")
+	cmd.Flags().StringVar(&opts.Dest, "dest", "", "Directory to write a fetched Clewfile (default: $XDG_CONFIG_HOME/claude)")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Overwrite an existing Clewfile when using --from")
+	cmd.Flags().BoolVar(&opts.NoBackup, "no-backup", false, "Skip creating backup before sync")
+	cmd.Flags().BoolVar(&opts.SkipGitCheck, "skip-git-check", false, "Skip git status checks for local repositories")
+	cmd.Flags().BoolVar(&opts.Wait, "wait", false, "Wait for another running clew process instead of failing")
+
+	return cmd
+}
+
+// runBootstrap executes the bootstrap workflow.
+func runBootstrap(opts BootstrapOptions) error {
+	// 1. Install check
+	if _, err := exec.LookPath("claude"); err != nil {
+		return fmt.Errorf("claude CLI not found in PATH: install Claude Code before running bootstrap")
+	}
+
+	// 2. Locate or fetch the Clewfile
+	clewfilePath, fetched, err := resolveBootstrapClewfile(opts)
+	if err != nil {
+		return err
+	}
+	if !quiet {
+		if fetched {
+			fmt.Printf("Fetched Clewfile: %s\n", clewfilePath)
+		} else {
+			fmt.Printf("Using Clewfile: %s\n", clewfilePath)
+		}
+	}
+
+	// 3-4. Backup and sync (non-interactive)
+	service := NewSyncService(clewfilePath, clewVersion)
+	ctx, stop := interruptContext()
+	defer stop()
+	service.SetContext(ctx)
+	err = service.Run(SyncOptions{
+		CreateBackup: !opts.NoBackup,
+		SkipGitCheck: opts.SkipGitCheck,
+		Wait:         opts.Wait,
+		OutputFormat: "text",
+		Verbose:      verbose,
+		Quiet:        quiet,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 5. Verify
+	return verifyBootstrap(service)
+}
+
+// resolveBootstrapClewfile returns the Clewfile path to bootstrap from and
+// whether it was freshly fetched.
+func resolveBootstrapClewfile(opts BootstrapOptions) (string, bool, error) {
+	if opts.From == "" {
+		path, err := config.FindClewfile(configPath)
+		if err != nil {
+			return "", false, fmt.Errorf("%w (use --from to fetch one)", err)
+		}
+		return path, false, nil
+	}
+
+	destDir := opts.Dest
+	if destDir == "" {
+		var err error
+		destDir, err = defaultClewfileDir()
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	existing, exists := config.FindClewfileInDir(destDir)
+	if exists && !opts.Force {
+		return existing, false, nil
+	}
+
+	// Fetch next to the target and validate before touching the existing
+	// Clewfile, so a bad remote file leaves the working one in place
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", false, fmt.Errorf("failed to create directory %s: %w", destDir, err)
+	}
+	tmpDir, err := os.MkdirTemp(destDir, ".clew-fetch-")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	fetched, err := config.Fetch(opts.From, tmpDir)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := config.Load(fetched); err != nil {
+		return "", false, fmt.Errorf("fetched Clewfile is invalid: %w", err)
+	}
+
+	path := filepath.Join(destDir, filepath.Base(fetched))
+	if err := os.Rename(fetched, path); err != nil {
+		return "", false, fmt.Errorf("failed to install fetched Clewfile: %w", err)
+	}
+
+	// A replaced Clewfile with a different extension would otherwise
+	// shadow the fetched one on the next run
+	if exists && existing != path {
+		_ = os.Remove(existing)
+	}
+
+	return path, true, nil
+}
+
+// defaultClewfileDir returns $XDG_CONFIG_HOME/claude, the highest-precedence
+// standard Clewfile location.
+func defaultClewfileDir() (string, error) {
+	configHome, err := xdg.ConfigHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configHome, "claude"), nil
+}
+
+// verifyBootstrap re-reads state after sync and reports anything still pending.
+func verifyBootstrap(service *SyncService) error {
+	clewfile, _, err := service.LoadConfiguration()
+	if err != nil {
+		return err
+	}
+
+	currentState, err := service.ReadCurrentState()
+	if err != nil {
+		return err
+	}
+
+	pending := pendingChanges(service.ComputeDiff(clewfile, currentState))
+	if len(pending) > 0 {
+		return fmt.Errorf("bootstrap incomplete, still pending after sync: %s", strings.Join(pending, ", "))
+	}
+
+	if !quiet {
+		fmt.Println("Bootstrap complete. System matches Clewfile.")
+	}
+	return nil
+}
+
+// pendingChanges lists the changes sync is able to apply. Updates that need
+// a manual reinstall and unmanaged items are left out: sync reports them
+// but cannot act on them.
+func pendingChanges(d *diff.Result) []string {
+	var pending []string
+	for _, m := range d.Marketplaces {
+		if m.Action == diff.ActionAdd {
+			pending = append(pending, "marketplace: "+m.Alias)
+		}
+	}
+	for _, p := range d.Plugins {
+		switch p.Action {
+		case diff.ActionAdd, diff.ActionEnable, diff.ActionDisable:
+			pending = append(pending, "plugin: "+p.Name)
+		}
+	}
+	for _, k := range d.Keybindings {
+		if k.Action == diff.ActionAdd || k.Action == diff.ActionUpdate {
+			pending = append(pending, "keybinding: "+k.Name())
+		}
+	}
+	return pending
+}
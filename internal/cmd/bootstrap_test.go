@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveBootstrapClewfile_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("version: 1\nplugins: []\n"))
+	}))
+	defer server.Close()
+
+	destDir := t.TempDir()
+	opts := BootstrapOptions{From: server.URL + "/Clewfile.yaml", Dest: destDir}
+
+	path, fetched, err := resolveBootstrapClewfile(opts)
+	if err != nil {
+		t.Fatalf("resolveBootstrapClewfile() error = %v", err)
+	}
+	if !fetched {
+		t.Error("expected Clewfile to be fetched")
+	}
+	if path != filepath.Join(destDir, "Clewfile.yaml") {
+		t.Errorf("path = %s, want %s", path, filepath.Join(destDir, "Clewfile.yaml"))
+	}
+
+	// Second run reuses the existing file (idempotent)
+	_, fetched, err = resolveBootstrapClewfile(opts)
+	if err != nil {
+		t.Fatalf("resolveBootstrapClewfile() error = %v", err)
+	}
+	if fetched {
+		t.Error("expected existing Clewfile to be reused")
+	}
+}
+
+func TestResolveBootstrapClewfile_ForceReplacesExisting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("version = 1\n"))
+	}))
+	defer server.Close()
+
+	destDir := t.TempDir()
+	existing := filepath.Join(destDir, "Clewfile.yaml")
+	if err := os.WriteFile(existing, []byte("version: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, fetched, err := resolveBootstrapClewfile(BootstrapOptions{
+		From:  server.URL + "/Clewfile.toml",
+		Dest:  destDir,
+		Force: true,
+	})
+	if err != nil {
+		t.Fatalf("resolveBootstrapClewfile() error = %v", err)
+	}
+	if !fetched || filepath.Base(path) != "Clewfile.toml" {
+		t.Errorf("got %s (fetched=%v), want fetched Clewfile.toml", path, fetched)
+	}
+	if _, err := os.Stat(existing); !os.IsNotExist(err) {
+		t.Error("expected shadowing Clewfile.yaml to be removed")
+	}
+}
+
+func TestResolveBootstrapClewfile_InvalidRemote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("version: 1\nplugins:\n  - not-a-valid-name\n"))
+	}))
+	defer server.Close()
+
+	_, _, err := resolveBootstrapClewfile(BootstrapOptions{
+		From: server.URL + "/Clewfile.yaml",
+		Dest: t.TempDir(),
+	})
+	if err == nil {
+		t.Error("expected validation error for invalid remote Clewfile")
+	}
+}
+
+func TestResolveBootstrapClewfile_InvalidRemoteKeepsExisting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("version = 1\nplugins = [\"not-a-valid-name\"]\n"))
+	}))
+	defer server.Close()
+
+	destDir := t.TempDir()
+	existing := filepath.Join(destDir, "Clewfile.yaml")
+	if err := os.WriteFile(existing, []byte("version: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := resolveBootstrapClewfile(BootstrapOptions{
+		From:  server.URL + "/Clewfile.toml",
+		Dest:  destDir,
+		Force: true,
+	})
+	if err == nil {
+		t.Fatal("expected validation error for invalid remote Clewfile")
+	}
+	if data, err := os.ReadFile(existing); err != nil || string(data) != "version: 1\n" {
+		t.Errorf("existing Clewfile = %q, %v; want it untouched", data, err)
+	}
+	entries, _ := os.ReadDir(destDir)
+	if len(entries) != 1 {
+		t.Errorf("destination has %d entries, want only the existing Clewfile", len(entries))
+	}
+}
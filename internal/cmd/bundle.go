@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/logging"
+)
+
+// BundleExecOptions configures the bundle exec command.
+type BundleExecOptions struct {
+	Sync     bool // Sync first when the Clewfile is not satisfied
+	NoBackup bool // Skip the backup before that sync
+	Wait     bool // Wait for another clew process to release the lock
+}
+
+func newBundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Run commands against the plugin set declared in the Clewfile",
+	}
+
+	cmd.AddCommand(newBundleExecCmd())
+
+	return cmd
+}
+
+func newBundleExecCmd() *cobra.Command {
+	var opts BundleExecOptions
+
+	cmd := &cobra.Command{
+		Use:   "exec [flags] [--] <command> [args...]",
+		Short: "Run a command once the Clewfile is satisfied",
+		Long: `Bundle exec checks that every marketplace and plugin in the Clewfile is
+installed with the declared enabled state, then replaces itself with the
+given command. Use it to start Claude with the declared plugin set:
+
+  clew bundle exec claude
+  alias claude='clew bundle exec --sync claude'
+
+The command is looked up on PATH, not run by a shell, so an alias like the
+one above does not call itself, and shell builtins and functions cannot be
+run.
+
+The check reads local state only and does not contact the network. If
+something is missing the command is not run; with --sync, clew syncs
+(backing up first unless --no-backup) and runs it once the sync leaves
+nothing pending. Unmanaged items do not block the command.
+
+Flags for clew go before the command; everything from the command on is
+passed to it unchanged.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBundleExec(opts, args)
+		},
+	}
+
+	// Stop at the command, so its own flags are not parsed as clew's
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().BoolVar(&opts.Sync, "sync", false, "Sync first if the Clewfile is not satisfied")
+	cmd.Flags().BoolVar(&opts.NoBackup, "no-backup", false, "Skip creating backup before sync")
+	cmd.Flags().BoolVar(&opts.Wait, "wait", false, "Wait for another running clew process instead of failing")
+
+	return cmd
+}
+
+// runBundleExec ensures the Clewfile is satisfied and execs args.
+func runBundleExec(opts BundleExecOptions, args []string) error {
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return fmt.Errorf("cannot run %s: %w", args[0], err)
+	}
+
+	clewfilePath, err := config.FindClewfile(configPath)
+	if err != nil {
+		return err
+	}
+	logging.Decisionf("Using Clewfile: %s", clewfilePath)
+
+	service := NewSyncService(clewfilePath, clewVersion)
+	pending, err := bundlePending(service)
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		if !opts.Sync {
+			return fmt.Errorf("not running %s, the Clewfile is not satisfied: %s (sync with 'clew sync' or pass --sync)",
+				args[0], strings.Join(pending, ", "))
+		}
+		ctx, stop := interruptContext()
+		service.SetContext(ctx)
+		err := service.Run(SyncOptions{
+			CreateBackup: !opts.NoBackup,
+			Short:        true,
+			Wait:         opts.Wait,
+			OutputFormat: "text",
+			Verbose:      verbose,
+			Quiet:        quiet,
+		})
+		stop()
+		if err != nil {
+			return err
+		}
+		if pending, err = bundlePending(service); err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("not running %s, the Clewfile is still not satisfied after sync: %s",
+				args[0], strings.Join(pending, ", "))
+		}
+	}
+
+	logging.Decisionf("Clewfile satisfied, running %s", path)
+	return execCommand(path, args)
+}
+
+// bundlePending returns what sync would still change.
+func bundlePending(service *SyncService) ([]string, error) {
+	clewfile, _, err := service.LoadConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	currentState, err := service.ReadCurrentState()
+	if err != nil {
+		return nil, err
+	}
+	return pendingChanges(service.ComputeDiff(clewfile, currentState)), nil
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunBundleExecUnsatisfied(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(tmpDir, "cache"))
+
+	clewfilePath := filepath.Join(tmpDir, "Clewfile.yaml")
+	content := "version: 1\nmarketplaces:\n  official:\n    repo: owner/official\nplugins:\n  - lint@official\n"
+	if err := os.WriteFile(clewfilePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldConfigPath := configPath
+	configPath = clewfilePath
+	defer func() { configPath = oldConfigPath }()
+
+	// Nothing is installed, so the command must not run
+	err := runBundleExec(BundleExecOptions{}, []string{"true"})
+	if err == nil {
+		t.Fatal("runBundleExec() ran the command with the Clewfile unsatisfied")
+	}
+	for _, want := range []string{"marketplace: official", "plugin: lint@official", "--sync"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	if err := runBundleExec(BundleExecOptions{}, []string{"clew-no-such-command"}); err == nil || !strings.Contains(err.Error(), "cannot run") {
+		t.Errorf("runBundleExec() error = %v, want a missing command error", err)
+	}
+}
@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/output"
+)
+
+// ANSI colors used by the Clewfile highlighter.
+const (
+	ansiReset = "\033[0m"
+	ansiKey   = "\033[36m" // Cyan
+	ansiGray  = "\033[90m"
+)
+
+func newCatCmd() *cobra.Command {
+	var noColor bool
+
+	cmd := &cobra.Command{
+		Use:   "cat",
+		Short: "Print the effective Clewfile",
+		Long: `Cat prints the Clewfile as clew sees it: environment variables expanded
+(unless it sets expand_env: false) and entries normalized, whatever format it is written in. Each marketplace
+and plugin is annotated with the file and line it came from, and with the
+text as written when expansion changed it.
+
+Output is highlighted when writing to a terminal; set NO_COLOR or pass
+--no-color to disable it. Use --output json for the resolved Clewfile and
+its annotations in machine-readable form.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCat(noColor)
+		},
+	}
+
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "Disable syntax highlighting")
+
+	return cmd
+}
+
+// runCat prints the effective Clewfile.
+func runCat(noColor bool) error {
+	clewfilePath, err := config.FindClewfile(configPath)
+	if err != nil {
+		return err
+	}
+	resolved, err := config.Resolve(clewfilePath)
+	if err != nil {
+		return err
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		return writer.Write(resolved)
+	}
+
+	text, err := renderEffectiveClewfile(resolved)
+	if err != nil {
+		return err
+	}
+	if colorOutput(noColor) {
+		text = highlightYAML(text)
+	}
+	fmt.Print(text)
+	return nil
+}
+
+// renderEffectiveClewfile renders the resolved Clewfile as annotated YAML.
+func renderEffectiveClewfile(r *config.Resolved) (string, error) {
+	scalar := func(value string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	root.Content = append(root.Content,
+		scalar("version"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(r.Clewfile.Version)})
+	if e := r.Clewfile.ExpandEnv; e != nil {
+		root.Content = append(root.Content, scalar("expand_env"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(*e)})
+	}
+
+	if len(r.Clewfile.Vars) > 0 {
+		names := make([]string, 0, len(r.Clewfile.Vars))
+		for name := range r.Clewfile.Vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		vars := &yaml.Node{Kind: yaml.MappingNode}
+		for _, name := range names {
+			vars.Content = append(vars.Content, scalar(name), scalar(r.Clewfile.Vars[name]))
+		}
+		root.Content = append(root.Content, scalar("vars"), vars)
+	}
+	if r.Clewfile.OnFailure != "" {
+		root.Content = append(root.Content, scalar("on_failure"), scalar(r.Clewfile.OnFailure))
+	}
+
+	if len(r.Clewfile.Marketplaces) > 0 {
+		aliases := make([]string, 0, len(r.Clewfile.Marketplaces))
+		for alias := range r.Clewfile.Marketplaces {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+
+		marketplaces := &yaml.Node{Kind: yaml.MappingNode}
+		for _, alias := range aliases {
+			m := r.Clewfile.Marketplaces[alias]
+			entry := &yaml.Node{Kind: yaml.MappingNode}
+			entry.Content = append(entry.Content, scalar("repo"), scalar(m.Repo))
+			if m.Ref != "" {
+				entry.Content = append(entry.Content, scalar("ref"), scalar(m.Ref))
+			}
+			if m.OnFailure != "" {
+				entry.Content = append(entry.Content, scalar("on_failure"), scalar(m.OnFailure))
+			}
+			if d := m.Defaults; d != nil && (d.Enabled != nil || d.Scope != "") {
+				defaults := &yaml.Node{Kind: yaml.MappingNode}
+				if d.Enabled != nil {
+					defaults.Content = append(defaults.Content, scalar("enabled"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(*d.Enabled)})
+				}
+				if d.Scope != "" {
+					defaults.Content = append(defaults.Content, scalar("scope"), scalar(d.Scope))
+				}
+				entry.Content = append(entry.Content, scalar("defaults"), defaults)
+			}
+			key := scalar(alias)
+			key.LineComment = originComment(r.Marketplaces[alias])
+			marketplaces.Content = append(marketplaces.Content, key, entry)
+		}
+		root.Content = append(root.Content, scalar("marketplaces"), marketplaces)
+	}
+
+	plugins := &yaml.Node{Kind: yaml.SequenceNode}
+	for i, p := range r.Clewfile.Plugins {
+		var item *yaml.Node
+		if p.Simple() {
+			item = scalar(p.Name)
+		} else {
+			item = &yaml.Node{Kind: yaml.MappingNode}
+			item.Content = append(item.Content, scalar("name"), scalar(p.Name))
+			if p.Enabled != nil {
+				item.Content = append(item.Content, scalar("enabled"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(*p.Enabled)})
+			}
+			if p.Scope != "" {
+				item.Content = append(item.Content, scalar("scope"), scalar(p.Scope))
+			}
+			if p.SHA256 != "" {
+				item.Content = append(item.Content, scalar("sha256"), scalar(p.SHA256))
+			}
+			if p.Commit != "" {
+				item.Content = append(item.Content, scalar("commit"), &yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: p.Commit})
+			}
+			if p.OnFailure != "" {
+				item.Content = append(item.Content, scalar("on_failure"), scalar(p.OnFailure))
+			}
+			// Comment the first line of the mapping
+			item.Content[0].LineComment = originComment(r.Plugins[i])
+		}
+		if item.Kind == yaml.ScalarNode {
+			item.LineComment = originComment(r.Plugins[i])
+		}
+		plugins.Content = append(plugins.Content, item)
+	}
+	root.Content = append(root.Content, scalar("plugins"), plugins)
+
+	if disable := r.Clewfile.Lint.Disable; len(disable) > 0 {
+		rules := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, rule := range disable {
+			rules.Content = append(rules.Content, scalar(rule))
+		}
+		lint := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalar("disable"), rules}}
+		root.Content = append(root.Content, scalar("lint"), lint)
+	}
+
+	if ig := r.Clewfile.Ignore; len(ig.Marketplaces) > 0 || len(ig.Plugins) > 0 {
+		ignore := &yaml.Node{Kind: yaml.MappingNode}
+		for _, list := range []struct {
+			key   string
+			names []string
+		}{{"marketplaces", ig.Marketplaces}, {"plugins", ig.Plugins}} {
+			if len(list.names) == 0 {
+				continue
+			}
+			names := &yaml.Node{Kind: yaml.SequenceNode}
+			for _, name := range list.names {
+				names.Content = append(names.Content, scalar(name))
+			}
+			ignore.Content = append(ignore.Content, scalar(list.key), names)
+		}
+		root.Content = append(root.Content, scalar("ignore"), ignore)
+	}
+
+	if notify := r.Clewfile.Updates.Notify; notify != nil {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(*notify)}
+		updates := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalar("notify"), value}}
+		root.Content = append(root.Content, scalar("updates"), updates)
+	}
+
+	if sc := r.Clewfile.Scan; sc.BlockScore > 0 || len(sc.Allow) > 0 {
+		scan := &yaml.Node{Kind: yaml.MappingNode}
+		if sc.BlockScore > 0 {
+			scan.Content = append(scan.Content, scalar("block_score"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(sc.BlockScore)})
+		}
+		if len(sc.Allow) > 0 {
+			allow := &yaml.Node{Kind: yaml.SequenceNode}
+			for _, name := range sc.Allow {
+				allow.Content = append(allow.Content, scalar(name))
+			}
+			scan.Content = append(scan.Content, scalar("allow"), allow)
+		}
+		root.Content = append(root.Content, scalar("scan"), scan)
+	}
+
+	if g := r.Clewfile.Git; !g.IsZero() {
+		git := &yaml.Node{Kind: yaml.MappingNode}
+		if g.AllowedSigners != "" {
+			git.Content = append(git.Content, scalar("allowed_signers"), scalar(g.AllowedSigners))
+		}
+		if len(g.AllowedGPGKeys) > 0 {
+			keys := &yaml.Node{Kind: yaml.SequenceNode}
+			for _, key := range g.AllowedGPGKeys {
+				keys.Content = append(keys.Content, scalar(key))
+			}
+			git.Content = append(git.Content, scalar("allowed_gpg_keys"), keys)
+		}
+		if g.FetchInterval != "" {
+			git.Content = append(git.Content, scalar("fetch_interval"), scalar(g.FetchInterval))
+		}
+		root.Content = append(root.Content, scalar("git"), git)
+	}
+
+	if claude := r.Clewfile.Requires.Claude; claude != "" {
+		requires := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalar("claude"), scalar(claude)}}
+		root.Content = append(root.Content, scalar("requires"), requires)
+	}
+
+	if a := r.Clewfile.Alerts; a.Enabled() {
+		alerts := &yaml.Node{Kind: yaml.MappingNode}
+		if a.Webhook != "" {
+			alerts.Content = append(alerts.Content, scalar("webhook"), scalar(redactWebhook(a.Webhook)))
+		}
+		if a.Exec != "" {
+			alerts.Content = append(alerts.Content, scalar("exec"), scalar(a.Exec))
+		}
+		root.Content = append(root.Content, scalar("alerts"), alerts)
+	}
+
+	backups := &yaml.Node{Kind: yaml.MappingNode}
+	if auto := r.Clewfile.Backups.Auto; auto != nil {
+		backups.Content = append(backups.Content, scalar("auto"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(*auto)})
+	}
+	if retention := r.Clewfile.Backups.Retention; len(retention) > 0 {
+		names := make([]string, 0, len(retention))
+		for name := range retention {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		policies := &yaml.Node{Kind: yaml.MappingNode}
+		for _, name := range names {
+			p := retention[name]
+			policy := &yaml.Node{Kind: yaml.MappingNode}
+			for _, rule := range []struct {
+				key   string
+				count int
+			}{{"keep_last", p.KeepLast}, {"keep_daily", p.KeepDaily}, {"keep_weekly", p.KeepWeekly}, {"keep_monthly", p.KeepMonthly}} {
+				if rule.count > 0 {
+					policy.Content = append(policy.Content, scalar(rule.key), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(rule.count)})
+				}
+			}
+			policies.Content = append(policies.Content, scalar(name), policy)
+		}
+		backups.Content = append(backups.Content, scalar("retention"), policies)
+	}
+	if len(backups.Content) > 0 {
+		root.Content = append(root.Content, scalar("backups"), backups)
+	}
+
+	if len(r.Clewfile.Keybindings) > 0 {
+		keybindings := &yaml.Node{Kind: yaml.MappingNode}
+		for _, context := range slices.Sorted(maps.Keys(r.Clewfile.Keybindings)) {
+			declared := r.Clewfile.Keybindings[context]
+			bindings := &yaml.Node{Kind: yaml.MappingNode}
+			for _, key := range slices.Sorted(maps.Keys(declared)) {
+				action := scalar(declared[key])
+				if declared[key] == "" {
+					action = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+				}
+				bindings.Content = append(bindings.Content, scalar(key), action)
+			}
+			keybindings.Content = append(keybindings.Content, scalar(context), bindings)
+		}
+		root.Content = append(root.Content, scalar("keybindings"), keybindings)
+	}
+
+	doc := &yaml.Node{
+		Kind:        yaml.DocumentNode,
+		HeadComment: "Effective Clewfile: " + r.Path,
+		Content:     []*yaml.Node{root},
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", fmt.Errorf("failed to render Clewfile: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to render Clewfile: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// originComment describes where an entry came from, e.g.
+// "Clewfile.yaml:4, written ${ORG}/plugins".
+func originComment(o config.Origin) string {
+	comment := filepath.Base(o.File)
+	if o.Line > 0 {
+		comment += ":" + strconv.Itoa(o.Line)
+	}
+	if o.Written != "" {
+		comment += ", written " + o.Written
+	}
+	return comment
+}
+
+// highlightYAML colors mapping keys and comments in YAML text.
+func highlightYAML(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		body := strings.TrimRight(line, "\n")
+		newline := line[len(body):]
+
+		code, comment := splitYAMLComment(body)
+		if comment != "" {
+			comment = ansiGray + comment + ansiReset
+		}
+
+		// Keys: indentation, an optional "- ", then up to the first ":"
+		trimmed := strings.TrimLeft(code, " ")
+		indent := code[:len(code)-len(trimmed)]
+		if strings.HasPrefix(trimmed, "- ") {
+			indent += "- "
+			trimmed = trimmed[2:]
+		}
+		if colon := strings.Index(trimmed, ":"); colon > 0 && !strings.ContainsAny(trimmed[:colon], `"'`) &&
+			(colon == len(trimmed)-1 || trimmed[colon+1] == ' ') {
+			code = indent + ansiKey + trimmed[:colon] + ansiReset + trimmed[colon:]
+		}
+
+		lines[i] = code + comment + newline
+	}
+	return strings.Join(lines, "")
+}
+
+// splitYAMLComment splits a line at a "#" comment outside quotes.
+func splitYAMLComment(line string) (code, comment string) {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' '):
+			return line[:i], line[i:]
+		}
+	}
+	return line, ""
+}
+
+// redactWebhook returns a webhook URL with only its scheme and host. The
+// rest of the URL is usually the secret that authorises posting to it.
+func redactWebhook(webhook string) string {
+	u, err := url.Parse(webhook)
+	if err != nil || u.Host == "" {
+		return "[redacted]"
+	}
+	return u.Scheme + "://" + u.Host + "/[redacted]"
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+)
+
+func TestRenderEffectiveClewfile(t *testing.T) {
+	disabled := false
+	r := &config.Resolved{
+		Path: "/home/me/.config/claude/Clewfile.yaml",
+		Clewfile: &config.Clewfile{
+			Version: 1,
+			Marketplaces: map[string]config.Marketplace{
+				"company": {Repo: "acme/plugins"},
+			},
+			Plugins: []config.Plugin{
+				{Name: "context7@company"},
+				{Name: "linter@company", Enabled: &disabled},
+			},
+			Ignore: config.IgnoreConfig{Plugins: []string{"scratch@company"}},
+			Alerts: config.AlertsConfig{Webhook: "https://hooks.slack.com/services/T000/B000/XXXXSECRET"},
+		},
+		Marketplaces: map[string]config.Origin{
+			"company": {File: "/home/me/.config/claude/Clewfile.yaml", Line: 3, Written: "${ORG}/plugins"},
+		},
+		Plugins: []config.Origin{
+			{File: "/home/me/.config/claude/Clewfile.yaml", Line: 6},
+			{File: "/home/me/.config/claude/Clewfile.yaml", Line: 7},
+		},
+	}
+
+	got, err := renderEffectiveClewfile(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Effective Clewfile: /home/me/.config/claude/Clewfile.yaml",
+		"company: # Clewfile.yaml:3, written ${ORG}/plugins",
+		"- context7@company # Clewfile.yaml:6",
+		"- name: linter@company # Clewfile.yaml:7\n    enabled: false",
+		"ignore:\n  plugins:\n    - scratch@company",
+		"alerts:\n  webhook: https://hooks.slack.com/[redacted]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "SECRET") {
+		t.Errorf("webhook URL not redacted:\n%s", got)
+	}
+}
+
+func TestHighlightYAML(t *testing.T) {
+	in := "plugins:\n  - name: \"a:b\" # x.yaml:2\n  - 'c#d'\n"
+	want := ansiKey + "plugins" + ansiReset + ":\n" +
+		"  - " + ansiKey + "name" + ansiReset + ": \"a:b\" " + ansiGray + "# x.yaml:2" + ansiReset + "\n" +
+		"  - 'c#d'\n"
+	if got := highlightYAML(in); got != want {
+		t.Errorf("highlightYAML() = %q, want %q", got, want)
+	}
+}
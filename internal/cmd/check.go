@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/i18n"
+)
+
+// CheckResult mirrors the result shape of an Ansible module so clew can be
+// wrapped directly in a CM task: changed/failed flags, a message, and
+// optional per-item before/after diffs.
+type CheckResult struct {
+	Changed bool       `json:"changed" yaml:"changed"`
+	Failed  bool       `json:"failed" yaml:"failed"`
+	Msg     string     `json:"msg" yaml:"msg"`
+	Diff    []ItemDiff `json:"diff,omitempty" yaml:"diff,omitempty"`
+}
+
+// ItemDiff is the before/after state of a single item, using Ansible's
+// diff key names.
+type ItemDiff struct {
+	BeforeHeader string `json:"before_header" yaml:"before_header"`
+	AfterHeader  string `json:"after_header" yaml:"after_header"`
+	Before       string `json:"before" yaml:"before"`
+	After        string `json:"after" yaml:"after"`
+}
+
+// buildCheckResult computes the check-mode result for a diff. Only actions
+// sync actually applies count as changes; unmanaged items and updates that
+// need a manual reinstall are left alone by sync and so never "change".
+func buildCheckResult(d *diff.Result, includeDiff bool) *CheckResult {
+	result := &CheckResult{}
+	changes := 0
+
+	for _, m := range d.Marketplaces {
+		if m.Action != diff.ActionAdd {
+			continue
+		}
+		changes++
+		if includeDiff {
+			header := "marketplace " + m.Alias
+			result.Diff = append(result.Diff, ItemDiff{
+				BeforeHeader: header,
+				AfterHeader:  header,
+				Before:       "",
+				After:        marketplaceDesiredText(m),
+			})
+		}
+	}
+
+	for _, p := range d.Plugins {
+		switch p.Action {
+		case diff.ActionAdd, diff.ActionEnable, diff.ActionDisable:
+		default:
+			continue
+		}
+		changes++
+		if includeDiff {
+			header := "plugin " + p.Name
+			result.Diff = append(result.Diff, ItemDiff{
+				BeforeHeader: header,
+				AfterHeader:  header,
+				Before:       pluginCurrentText(p),
+				After:        pluginDesiredText(p),
+			})
+		}
+	}
+
+	for _, k := range d.Keybindings {
+		if k.Action != diff.ActionAdd && k.Action != diff.ActionUpdate {
+			continue
+		}
+		changes++
+		if includeDiff {
+			header := "keybinding " + k.Name()
+			before := ""
+			if k.Action == diff.ActionUpdate {
+				before = keybindingText(k.Current)
+			}
+			result.Diff = append(result.Diff, ItemDiff{
+				BeforeHeader: header,
+				AfterHeader:  header,
+				Before:       before,
+				After:        keybindingText(k.Desired),
+			})
+		}
+	}
+
+	result.Changed = changes > 0
+	if result.Changed {
+		result.Msg = i18n.N(i18n.CheckChanges, changes)
+	} else {
+		result.Msg = "already in sync"
+	}
+
+	return result
+}
+
+// marketplaceDesiredText renders the desired state of a marketplace.
+func marketplaceDesiredText(m diff.MarketplaceDiff) string {
+	if m.Desired == nil {
+		return ""
+	}
+	s := fmt.Sprintf("repo: %s\n", m.Desired.Repo)
+	if m.Desired.Ref != "" {
+		s += fmt.Sprintf("ref: %s\n", m.Desired.Ref)
+	}
+	return s
+}
+
+// pluginCurrentText renders the current state of a plugin.
+func pluginCurrentText(p diff.PluginDiff) string {
+	if p.Current == nil {
+		return ""
+	}
+	return fmt.Sprintf("installed: true\nenabled: %t\n", p.Current.Enabled)
+}
+
+// pluginDesiredText renders the desired state of a plugin.
+func pluginDesiredText(p diff.PluginDiff) string {
+	if p.Desired == nil {
+		return ""
+	}
+	enabled := p.Desired.Enabled == nil || *p.Desired.Enabled
+	return fmt.Sprintf("installed: true\nenabled: %t\n", enabled)
+}
+
+// keybindingText renders a key binding's action; null unbinds the key.
+func keybindingText(action string) string {
+	if action == "" {
+		return "action: null\n"
+	}
+	return fmt.Sprintf("action: %s\n", action)
+}
+
+// printItemDiffs writes diffs in a unified-diff-like layout.
+func printItemDiffs(w io.Writer, diffs []ItemDiff) {
+	for _, d := range diffs {
+		_, _ = fmt.Fprintf(w, "--- before: %s\n", d.BeforeHeader)
+		_, _ = fmt.Fprintf(w, "+++ after: %s\n", d.AfterHeader)
+		for _, line := range splitLines(d.Before) {
+			_, _ = fmt.Fprintf(w, "-%s\n", line)
+		}
+		for _, line := range splitLines(d.After) {
+			_, _ = fmt.Fprintf(w, "+%s\n", line)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+}
+
+// splitLines splits s into lines, ignoring a trailing newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
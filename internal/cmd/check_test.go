@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestBuildCheckResult(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name        string
+		diff        *diff.Result
+		wantChanged bool
+		wantDiffs   int
+	}{
+		{
+			name: "in sync",
+			diff: &diff.Result{
+				Plugins: []diff.PluginDiff{{Name: "p@m", Action: diff.ActionNone}},
+			},
+			wantChanged: false,
+		},
+		{
+			name: "unmanaged items are not changes",
+			diff: &diff.Result{
+				Marketplaces: []diff.MarketplaceDiff{{Alias: "extra", Action: diff.ActionRemove}},
+				Plugins:      []diff.PluginDiff{{Name: "extra@m", Action: diff.ActionRemove}},
+			},
+			wantChanged: false,
+		},
+		{
+			name: "manual reinstall is not a change",
+			diff: &diff.Result{
+				Plugins: []diff.PluginDiff{{Name: "p@m", Action: diff.ActionUpdate}},
+			},
+			wantChanged: false,
+		},
+		{
+			name: "adds and state flips",
+			diff: &diff.Result{
+				Marketplaces: []diff.MarketplaceDiff{
+					{Alias: "m", Action: diff.ActionAdd, Desired: &config.Marketplace{Repo: "owner/m"}},
+				},
+				Plugins: []diff.PluginDiff{
+					{Name: "new@m", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "new@m"}},
+					{
+						Name:    "off@m",
+						Action:  diff.ActionDisable,
+						Current: &state.PluginState{Enabled: true},
+						Desired: &config.Plugin{Name: "off@m", Enabled: &disabled},
+					},
+				},
+			},
+			wantChanged: true,
+			wantDiffs:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildCheckResult(tt.diff, true)
+			if got.Changed != tt.wantChanged {
+				t.Errorf("Changed = %v, want %v", got.Changed, tt.wantChanged)
+			}
+			if len(got.Diff) != tt.wantDiffs {
+				t.Errorf("len(Diff) = %d, want %d", len(got.Diff), tt.wantDiffs)
+			}
+			if got.Failed {
+				t.Error("Failed should be false in check mode")
+			}
+		})
+	}
+}
+
+func TestBuildCheckResult_WithoutDiff(t *testing.T) {
+	d := &diff.Result{
+		Plugins: []diff.PluginDiff{{Name: "new@m", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "new@m"}}},
+	}
+
+	got := buildCheckResult(d, false)
+	if !got.Changed {
+		t.Error("Changed = false, want true")
+	}
+	if got.Diff != nil {
+		t.Errorf("Diff = %v, want nil when not requested", got.Diff)
+	}
+}
+
+func TestPrintItemDiffs(t *testing.T) {
+	var buf bytes.Buffer
+	printItemDiffs(&buf, []ItemDiff{{
+		BeforeHeader: "plugin off@m",
+		AfterHeader:  "plugin off@m",
+		Before:       "installed: true\nenabled: true\n",
+		After:        "installed: true\nenabled: false\n",
+	}})
+
+	got := buf.String()
+	for _, want := range []string{
+		"--- before: plugin off@m",
+		"+++ after: plugin off@m",
+		"-enabled: true",
+		"+enabled: false",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q\n%s", want, got)
+		}
+	}
+}
@@ -1,18 +1,29 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/atomicfile"
 )
 
 func newCompletionCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "completion [bash|zsh|fish]",
 		Short: "Generate shell completion script",
 		Long: `Generate shell completion script for clew.
 
-To load completions:
+To install completions for your shell in one step:
+
+  $ clew completion install
+
+To load completions by hand:
 
 Bash:
   $ source <(clew completion bash)
@@ -44,15 +55,203 @@ Fish:
 		ValidArgs:             []string{"bash", "zsh", "fish"},
 		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			switch args[0] {
-			case "bash":
-				return cmd.Root().GenBashCompletion(os.Stdout)
-			case "zsh":
-				return cmd.Root().GenZshCompletion(os.Stdout)
-			case "fish":
-				return cmd.Root().GenFishCompletion(os.Stdout, true)
+			return genCompletion(cmd.Root(), args[0], os.Stdout)
+		},
+	}
+
+	cmd.AddCommand(newCompletionInstallCmd())
+
+	return cmd
+}
+
+func newCompletionInstallCmd() *cobra.Command {
+	var noRC bool
+
+	cmd := &cobra.Command{
+		Use:   "install [bash|zsh|fish]",
+		Short: "Install shell completions for the current user",
+		Long: `Install writes clew's completion script where your shell looks for it
+and, for bash and zsh, adds the lines that load it to your startup file:
+
+  bash  ~/.local/share/bash-completion/completions/clew, sourced from ~/.bashrc
+  zsh   ~/.zsh/completions/_clew, added to fpath in ~/.zshrc (which must
+        run compinit, as most setups do)
+  fish  ~/.config/fish/completions/clew.fish (loaded automatically)
+
+XDG_DATA_HOME, XDG_CONFIG_HOME and ZDOTDIR are honored. With no argument the
+shell is taken from $SHELL. Running install again regenerates the script and
+leaves the startup file alone if it already loads it, so it is safe to run
+after every clew upgrade.`,
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shell := ""
+			if len(args) > 0 {
+				shell = args[0]
 			}
-			return nil
+			return runCompletionInstall(cmd.Root(), shell, noRC)
 		},
 	}
+
+	cmd.Flags().BoolVar(&noRC, "no-rc", false, "Only write the completion script; do not edit the startup file")
+
+	return cmd
+}
+
+// genCompletion writes the completion script for shell.
+func genCompletion(root *cobra.Command, shell string, w io.Writer) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletion(w)
+	case "zsh":
+		return root.GenZshCompletion(w)
+	case "fish":
+		return root.GenFishCompletion(w, true)
+	}
+	return fmt.Errorf("unsupported shell %q (must be bash, zsh or fish)", shell)
+}
+
+// completionRCMarker starts the block install adds to a startup file, and
+// is how a later install recognizes it.
+const completionRCMarker = "# clew shell completion"
+
+// completionTarget is where install puts the completion script for a
+// shell, and what it adds to the shell's startup file (RC is empty when
+// the shell loads the script on its own).
+type completionTarget struct {
+	File    string
+	RC      string
+	RCBlock string
+}
+
+// completionTargetFor returns the conventional per-user locations for
+// shell, with getenv supplying XDG_DATA_HOME, XDG_CONFIG_HOME and ZDOTDIR.
+func completionTargetFor(shell, home string, getenv func(string) string) (completionTarget, error) {
+	dataHome := getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	configHome := getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	zdotdir := getenv("ZDOTDIR")
+	if zdotdir == "" {
+		zdotdir = home
+	}
+
+	switch shell {
+	case "bash":
+		// bash-completion loads this directory lazily; sourcing it from
+		// ~/.bashrc also covers systems without bash-completion
+		file := filepath.Join(dataHome, "bash-completion", "completions", "clew")
+		return completionTarget{
+			File:    file,
+			RC:      filepath.Join(home, ".bashrc"),
+			RCBlock: fmt.Sprintf("[ -f %s ] && . %s\n", shellQuote(file), shellQuote(file)),
+		}, nil
+	case "zsh":
+		// compinit reads fpath when it runs: a later compinit picks the
+		// directory up, and one that already ran (a plugin framework's) is
+		// told about clew with compdef. Running compinit again here would
+		// slow every shell start
+		dir := filepath.Join(zdotdir, ".zsh", "completions")
+		return completionTarget{
+			File:    filepath.Join(dir, "_clew"),
+			RC:      filepath.Join(zdotdir, ".zshrc"),
+			RCBlock: fmt.Sprintf("fpath=(%s $fpath)\n(( $+functions[compdef] )) && autoload -Uz _clew && compdef _clew clew\n", shellQuote(dir)),
+		}, nil
+	case "fish":
+		return completionTarget{File: filepath.Join(configHome, "fish", "completions", "clew.fish")}, nil
+	}
+	return completionTarget{}, fmt.Errorf("unsupported shell %q (must be bash, zsh or fish)", shell)
+}
+
+// runCompletionInstall writes the completion script for shell and, unless
+// noRC is set, makes sure the startup file loads it.
+func runCompletionInstall(root *cobra.Command, shell string, noRC bool) error {
+	if shell == "" {
+		var err error
+		if shell, err = detectShell(); err != nil {
+			return err
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	target, err := completionTargetFor(shell, home, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	var script bytes.Buffer
+	if err := genCompletion(root, shell, &script); err != nil {
+		return fmt.Errorf("failed to generate %s completion: %w", shell, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(target.File), 0755); err != nil {
+		return fmt.Errorf("failed to create completion directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(target.File, script.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write completion script: %w", err)
+	}
+	if !quiet {
+		fmt.Printf("Wrote %s completion to %s\n", shell, target.File)
+	}
+
+	if target.RC != "" && !noRC {
+		added, err := appendCompletionRC(target.RC, target.RCBlock)
+		if err != nil {
+			return err
+		}
+		if !quiet {
+			if added {
+				fmt.Printf("Added completion setup to %s\n", target.RC)
+			} else {
+				fmt.Printf("%s already loads clew completions\n", target.RC)
+			}
+		}
+	}
+
+	if !quiet {
+		fmt.Println("Start a new shell to use them.")
+	}
+	return nil
+}
+
+// appendCompletionRC appends block, under completionRCMarker, to the
+// startup file at path unless the marker is already there. It reports
+// whether the file changed. The file is appended to in place, so a
+// symlinked startup file (as dotfile managers keep them) stays a symlink.
+func appendCompletionRC(path, block string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if strings.Contains(string(data), completionRCMarker) {
+		return false, nil
+	}
+
+	var b strings.Builder
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		b.WriteString("\n")
+	}
+	if len(data) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(completionRCMarker + "\n" + block)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		_ = f.Close()
+		return false, fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	return true, nil
 }
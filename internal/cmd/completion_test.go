@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompletionTargetFor(t *testing.T) {
+	env := map[string]string{"ZDOTDIR": "/home/u/.config/zsh", "XDG_CONFIG_HOME": "/home/u/.cfg"}
+	getenv := func(k string) string { return env[k] }
+
+	tests := []struct {
+		shell, file, rc string
+	}{
+		{"bash", "/home/u/.local/share/bash-completion/completions/clew", "/home/u/.bashrc"},
+		{"zsh", "/home/u/.config/zsh/.zsh/completions/_clew", "/home/u/.config/zsh/.zshrc"},
+		{"fish", "/home/u/.cfg/fish/completions/clew.fish", ""},
+	}
+	for _, tt := range tests {
+		got, err := completionTargetFor(tt.shell, "/home/u", getenv)
+		if err != nil {
+			t.Fatalf("completionTargetFor(%s) error = %v", tt.shell, err)
+		}
+		if got.File != tt.file || got.RC != tt.rc {
+			t.Errorf("completionTargetFor(%s) = %+v, want file %s, rc %q", tt.shell, got, tt.file, tt.rc)
+		}
+	}
+
+	if _, err := completionTargetFor("tcsh", "/home/u", getenv); err == nil {
+		t.Error("completionTargetFor(tcsh) should fail")
+	}
+}
+
+func TestRunCompletionInstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("ZDOTDIR", "")
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	savedQuiet := quiet
+	quiet = true
+	t.Cleanup(func() { quiet = savedQuiet })
+
+	// The startup file is a symlink into a dotfiles checkout
+	rc := filepath.Join(home, "dotfiles", "zshrc")
+	if err := os.MkdirAll(filepath.Dir(rc), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rc, []byte("export EDITOR=vi"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(rc, filepath.Join(home, ".zshrc")); err != nil {
+		t.Fatal(err)
+	}
+
+	root := &cobra.Command{Use: "clew"}
+	for range 2 {
+		if err := runCompletionInstall(root, "", false); err != nil {
+			t.Fatalf("runCompletionInstall() error = %v", err)
+		}
+	}
+
+	script, err := os.ReadFile(filepath.Join(home, ".zsh", "completions", "_clew"))
+	if err != nil || !strings.Contains(string(script), "#compdef clew") {
+		t.Errorf("completion script = %.40q, %v; want the zsh script", script, err)
+	}
+
+	data, err := os.ReadFile(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), completionRCMarker); n != 1 {
+		t.Errorf(".zshrc has %d completion blocks, want 1:\n%s", n, data)
+	}
+	if !strings.HasPrefix(string(data), "export EDITOR=vi\n\n") {
+		t.Errorf(".zshrc lost its contents:\n%s", data)
+	}
+	if strings.Contains(string(data), "compinit") {
+		t.Errorf(".zshrc runs compinit again:\n%s", data)
+	}
+	if info, err := os.Stat(rc); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf(".zshrc mode = %v, want 0600 kept", info.Mode().Perm())
+	}
+	if info, err := os.Lstat(filepath.Join(home, ".zshrc")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf(".zshrc is no longer a symlink: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/output"
+)
+
+func newDedupeCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Merge plugins declared more than once in the Clewfile",
+		Long: `A plugin declared more than once makes the Clewfile invalid. Dedupe merges
+each plugin's declarations into the position of the first one, keeping the
+most specific settings: a field set on any declaration beats a default, and
+a later declaration beats an earlier one.
+
+The original Clewfile is kept with a .bak suffix. Use --dry-run to see
+what would be merged.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDedupe(dryRun)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be merged without changing the Clewfile")
+
+	return cmd
+}
+
+// runDedupe merges duplicate plugin declarations.
+func runDedupe(dryRun bool) error {
+	clewfilePath, err := config.FindClewfile(configPath)
+	if err != nil {
+		return err
+	}
+
+	var duplicates []config.Duplicate
+	if dryRun {
+		duplicates, err = config.DuplicatesIn(clewfilePath)
+	} else {
+		duplicates, err = config.Dedupe(clewfilePath)
+	}
+	if err != nil {
+		return err
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		return writer.Write(duplicates)
+	}
+
+	if len(duplicates) == 0 {
+		fmt.Println("No duplicate plugins.")
+		return nil
+	}
+	for _, d := range duplicates {
+		positions := make([]string, 0, len(d.Indexes))
+		for _, i := range d.Indexes {
+			positions = append(positions, "plugins["+strconv.Itoa(i)+"]")
+		}
+		fmt.Printf("  %s: %s -> %s\n", d.Name, strings.Join(positions, ", "), describePlugin(d.Merged))
+	}
+	if dryRun {
+		fmt.Printf("\n%s\n", i18n.N(i18n.DedupeWouldMerge, len(duplicates), clewfilePath))
+	} else {
+		fmt.Printf("\n%s\n", i18n.N(i18n.DedupeMerged, len(duplicates), clewfilePath))
+	}
+	return nil
+}
+
+// describePlugin summarizes a plugin's settings, e.g. "enabled: false, scope: user".
+func describePlugin(p config.Plugin) string {
+	var settings []string
+	if p.Enabled != nil {
+		settings = append(settings, "enabled: "+strconv.FormatBool(*p.Enabled))
+	}
+	if p.Scope != "" {
+		settings = append(settings, "scope: "+p.Scope)
+	}
+	if len(settings) == 0 {
+		return "defaults"
+	}
+	return strings.Join(settings, ", ")
+}
@@ -2,13 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/adamancini/clew/internal/config"
 	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/i18n"
 	"github.com/adamancini/clew/internal/interactive"
+	"github.com/adamancini/clew/internal/logging"
 	"github.com/adamancini/clew/internal/output"
 	"github.com/adamancini/clew/internal/state"
 )
@@ -17,25 +22,50 @@ func newDiffCmd() *cobra.Command {
 	var (
 		interactiveMode bool
 		showCommands    bool
+		noColor         bool
+		exitCode        bool
+		stat            bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "diff",
 		Short: "Show what would change (dry-run)",
-		Long:  `Diff compares the Clewfile against current state and shows what sync would do.`,
+		Long: `Diff compares the Clewfile against current state and shows what sync would do.
+
+For updated items, each changed field is shown before and after, with the
+changed words highlighted when writing to a terminal; set NO_COLOR or pass
+--no-color to disable it.
+
+Use --stat for a one-glance overview: a line per category with the number of
+items to add (+), update, enable or disable (~), not in the Clewfile (-) and
+blocked (!), and a bar like git diff --stat.
+
+With --exit-code, diff exits 0 when already in sync and 2 when there are
+differences, so scripts can branch on drift without parsing the output.
+Errors still exit 1.`,
+		Example: `  clew diff
+  clew diff --stat
+  clew diff --exit-code >/dev/null || clew sync`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDiff(interactiveMode, showCommands)
+			return runDiff(interactiveMode, showCommands, stat, colorOutput(noColor), exitCode)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&interactiveMode, "interactive", "i", false, "Preview changes with prompts (dry-run)")
 	cmd.Flags().BoolVar(&showCommands, "show-commands", false, "Output CLI commands to reconcile state")
+	cmd.Flags().BoolVar(&stat, "stat", false, "Show counts per category instead of every item")
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "Disable highlighting of changed words")
+	cmd.Flags().BoolVar(&exitCode, "exit-code", false, "Exit 2 if there are differences, 0 if in sync")
 
 	return cmd
 }
 
+// exitDiffChanges is the exit code of diff --exit-code when the system
+// differs from the Clewfile. 1 is left for errors.
+const exitDiffChanges = 2
+
 // runDiff executes the diff workflow (dry-run mode).
-func runDiff(interactiveMode bool, showCommands bool) error {
+func runDiff(interactiveMode bool, showCommands bool, stat bool, color bool, exitCode bool) error {
 	// 1. Find Clewfile
 	clewfilePath, err := config.FindClewfile(configPath)
 	if err != nil {
@@ -43,9 +73,7 @@ func runDiff(interactiveMode bool, showCommands bool) error {
 		os.Exit(1)
 	}
 
-	if verbose {
-		fmt.Fprintf(os.Stderr, "Using Clewfile: %s\n", clewfilePath)
-	}
+	logging.Decisionf("Using Clewfile: %s", clewfilePath)
 
 	// 2. Load Clewfile
 	clewfile, err := config.Load(clewfilePath)
@@ -56,9 +84,7 @@ func runDiff(interactiveMode bool, showCommands bool) error {
 
 	// 3. Infer scope
 	scope := config.InferScope(clewfilePath)
-	if verbose {
-		fmt.Fprintf(os.Stderr, "Inferred scope: %s\n", scope)
-	}
+	logging.Decisionf("Inferred scope: %s", scope)
 
 	// 4. Read current state
 	reader := &state.FilesystemReader{}
@@ -70,12 +96,14 @@ func runDiff(interactiveMode bool, showCommands bool) error {
 
 	// 5. Compute diff
 	diffResult := diff.Compute(clewfile, currentState)
+	applySnoozes(diffResult)
 
 	// 5a. Handle --show-commands flag
 	if showCommands {
 		commands := diffResult.GenerateCommands()
 		if len(commands) == 0 {
 			fmt.Println("# No commands needed - already in sync")
+			exitIfChanged(exitCode, diffResult)
 			return nil
 		}
 
@@ -95,6 +123,28 @@ func runDiff(interactiveMode bool, showCommands bool) error {
 				os.Exit(1)
 			}
 		}
+		exitIfChanged(exitCode, diffResult)
+		return nil
+	}
+
+	// 5b. Handle --stat flag
+	if stat {
+		format, err := output.ParseFormat(outputFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if format == output.FormatText {
+			printDiffStat(os.Stdout, diffStats(diffResult), color)
+		} else {
+			writer := output.NewWriter(os.Stdout, format)
+			if err := writer.Write(diffStats(diffResult)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		exitIfChanged(exitCode, diffResult)
 		return nil
 	}
 
@@ -114,7 +164,7 @@ func runDiff(interactiveMode bool, showCommands bool) error {
 			// Show what would have been selected (dry-run only, no execution)
 			filteredResult := interactive.FilterDiffBySelection(diffResult, selection)
 			fmt.Println("\n--- Dry-run complete. No changes were made. ---")
-			printDiffResultText(filteredResult)
+			printDiffResultText(os.Stdout, filteredResult, color)
 		}
 		return nil
 	}
@@ -127,7 +177,7 @@ func runDiff(interactiveMode bool, showCommands bool) error {
 	}
 
 	if format == output.FormatText {
-		printDiffResultText(diffResult)
+		printDiffResultText(os.Stdout, diffResult, color)
 	} else {
 		writer := output.NewWriter(os.Stdout, format)
 		if err := writer.Write(diffResult); err != nil {
@@ -136,21 +186,37 @@ func runDiff(interactiveMode bool, showCommands bool) error {
 		}
 	}
 
+	exitIfChanged(exitCode, diffResult)
 	return nil
 }
 
+// exitIfChanged exits with exitDiffChanges when --exit-code is set and
+// result has anything to report.
+func exitIfChanged(exitCode bool, result *diff.Result) {
+	if exitCode && hasDiff(result) {
+		os.Exit(exitDiffChanges)
+	}
+}
+
+// hasDiff reports whether result has anything to report, including items
+// clew cannot change.
+func hasDiff(result *diff.Result) bool {
+	add, update, remove, attention := result.Summary()
+	return add+update+remove+attention > 0
+}
+
 // printDiffResultText outputs the diff result in human-readable format.
-func printDiffResultText(result *diff.Result) {
+func printDiffResultText(out io.Writer, result *diff.Result, color bool) {
 	add, update, remove, attention := result.Summary()
 
 	// Print summary first
 	if add == 0 && update == 0 && remove == 0 && attention == 0 {
-		fmt.Println("Already in sync. Nothing would change.")
+		fmt.Fprintln(out, "Already in sync. Nothing would change.")
 		return
 	}
 
-	fmt.Println("Changes that would be made:")
-	fmt.Println()
+	fmt.Fprintln(out, "Changes that would be made:")
+	fmt.Fprintln(out)
 
 	// Marketplaces
 	hasMarketplaceChanges := false
@@ -159,10 +225,11 @@ func printDiffResultText(result *diff.Result) {
 			continue
 		}
 		if !hasMarketplaceChanges {
-			fmt.Println("Marketplaces:")
+			fmt.Fprintln(out, "Marketplaces:")
 			hasMarketplaceChanges = true
 		}
-		printDiffItem("marketplace", m.Alias, m.Action, m.Desired != nil, m.Current != nil)
+		printDiffItem(out, m.Alias, m.Action)
+		printFieldChanges(out, m.Changes(), color)
 	}
 
 	// Plugins
@@ -173,22 +240,164 @@ func printDiffResultText(result *diff.Result) {
 		}
 		if !hasPluginChanges {
 			if hasMarketplaceChanges {
-				fmt.Println()
+				fmt.Fprintln(out)
 			}
-			fmt.Println("Plugins:")
+			fmt.Fprintln(out, "Plugins:")
 			hasPluginChanges = true
 		}
-		printDiffItem("plugin", p.Name, p.Action, p.Desired != nil, p.Current != nil)
+		name := p.Name
+		if (p.Action == diff.ActionEnable || p.Action == diff.ActionDisable) &&
+			p.Current != nil && p.Current.EnabledSource == state.SettingsLocalFile {
+			name += " (set in " + state.SettingsLocalFile + ")"
+		}
+		printDiffItem(out, name, p.Action)
+		if p.Action == diff.ActionUpdate {
+			printFieldChanges(out, p.Changes(), color)
+		}
+	}
+
+	// Key bindings
+	hasKeybindingChanges := false
+	for _, k := range result.Keybindings {
+		if k.Action == diff.ActionNone {
+			continue
+		}
+		if !hasKeybindingChanges {
+			if hasMarketplaceChanges || hasPluginChanges {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintln(out, "Keybindings:")
+			hasKeybindingChanges = true
+		}
+		printDiffItem(out, k.Name(), k.Action)
+		printFieldChanges(out, k.Changes(), color)
 	}
 
 	// Summary
-	fmt.Println()
-	fmt.Printf("Summary: %d to add, %d to update, %d to remove, %d unmanaged\n",
-		add, update, remove, attention)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, i18n.T(i18n.DiffSummary, add, update, remove, attention))
+}
+
+// DiffStat counts the changes in one category of the diff.
+type DiffStat struct {
+	Category string `json:"category" yaml:"category"`
+	Add      int    `json:"add" yaml:"add"`         // +
+	Change   int    `json:"change" yaml:"change"`   // ~ update, enable or disable
+	Remove   int    `json:"remove" yaml:"remove"`   // - not in the Clewfile
+	Blocked  int    `json:"blocked" yaml:"blocked"` // ! managed, blocked by scan, or skipped for git status
+}
+
+// Total is the number of items in the category that differ.
+func (s DiffStat) Total() int {
+	return s.Add + s.Change + s.Remove + s.Blocked
+}
+
+// diffStats counts the changes in result per category, leaving out
+// categories without any.
+func diffStats(result *diff.Result) []DiffStat {
+	marketplaces := DiffStat{Category: "marketplaces"}
+	for _, m := range result.Marketplaces {
+		marketplaces.count(m.Action)
+	}
+	plugins := DiffStat{Category: "plugins"}
+	for _, p := range result.Plugins {
+		plugins.count(p.Action)
+	}
+
+	keybindings := DiffStat{Category: "keybindings"}
+	for _, k := range result.Keybindings {
+		keybindings.count(k.Action)
+	}
+
+	stats := []DiffStat{}
+	for _, s := range []DiffStat{marketplaces, plugins, keybindings} {
+		if s.Total() > 0 {
+			stats = append(stats, s)
+		}
+	}
+	return stats
+}
+
+// count adds an item with action to the stat.
+func (s *DiffStat) count(action diff.Action) {
+	switch action {
+	case diff.ActionAdd:
+		s.Add++
+	case diff.ActionUpdate, diff.ActionEnable, diff.ActionDisable:
+		s.Change++
+	case diff.ActionRemove:
+		s.Remove++
+	case diff.ActionManaged, diff.ActionBlocked, diff.ActionSkipGit:
+		s.Blocked++
+	}
+}
+
+// maxStatBar is the widest bar printDiffStat draws; larger counts are scaled.
+const maxStatBar = 40
+
+// printDiffStat prints a line per category with its counts and a bar of
+// +, ~, - and ! characters, then the totals.
+func printDiffStat(out io.Writer, stats []DiffStat, color bool) {
+	if len(stats) == 0 {
+		fmt.Fprintln(out, "Already in sync. Nothing would change.")
+		return
+	}
+
+	var total DiffStat
+	largest, nameWidth := 0, 0
+	counts := make([]string, len(stats))
+	countsWidth := 0
+	for i, s := range stats {
+		total.Add += s.Add
+		total.Change += s.Change
+		total.Remove += s.Remove
+		total.Blocked += s.Blocked
+		largest = max(largest, s.Total())
+		nameWidth = max(nameWidth, len(s.Category))
+
+		var parts []string
+		for _, c := range []struct {
+			symbol string
+			n      int
+		}{{"+", s.Add}, {"~", s.Change}, {"-", s.Remove}, {"!", s.Blocked}} {
+			if c.n > 0 {
+				parts = append(parts, fmt.Sprintf("%s%d", c.symbol, c.n))
+			}
+		}
+		counts[i] = strings.Join(parts, " ")
+		countsWidth = max(countsWidth, len(counts[i]))
+	}
+
+	scale := func(n int) int {
+		if n == 0 || largest <= maxStatBar {
+			return n
+		}
+		return max(1, n*maxStatBar/largest)
+	}
+	for i, s := range stats {
+		var bar strings.Builder
+		for _, seg := range []struct {
+			symbol, color string
+			n             int
+		}{
+			{"+", ansiAdded, s.Add},
+			{"~", ansiChanged, s.Change},
+			{"-", ansiRemoved, s.Remove},
+			{"!", ansiBlocked, s.Blocked},
+		} {
+			chars := strings.Repeat(seg.symbol, scale(seg.n))
+			if color && chars != "" {
+				chars = seg.color + chars + ansiReset
+			}
+			bar.WriteString(chars)
+		}
+		fmt.Fprintf(out, " %-*s | %-*s %s\n", nameWidth, s.Category, countsWidth, counts[i], bar.String())
+	}
+	fmt.Fprintln(out, i18n.T(i18n.DiffStatTotal, total.Add, total.Change, total.Remove, total.Blocked))
 }
 
 // printDiffItem prints a single diff item with appropriate formatting.
-func printDiffItem(itemType, name string, action diff.Action, hasDesired, hasCurrent bool) {
+func printDiffItem(out io.Writer, name string, action diff.Action) {
 	var symbol, verb string
 	switch action {
 	case diff.ActionAdd:
@@ -206,10 +415,57 @@ func printDiffItem(itemType, name string, action diff.Action, hasDesired, hasCur
 	case diff.ActionDisable:
 		symbol = "-"
 		verb = "disable"
+	case diff.ActionManaged:
+		symbol = "!"
+		verb = "managed — cannot change"
+	case diff.ActionBlocked:
+		symbol = "!"
+		verb = "blocked by scan policy"
 	default:
 		symbol = " "
 		verb = ""
 	}
 
-	fmt.Printf("  %s %s: %s\n", symbol, name, verb)
+	fmt.Fprintf(out, "  %s %s: %s\n", symbol, name, verb)
+}
+
+// colorOutput reports whether to highlight output: stdout is a terminal,
+// and neither --no-color nor NO_COLOR is set.
+func colorOutput(noColor bool) bool {
+	return !noColor && os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// ANSI colors for changed words in field changes.
+const (
+	ansiRemoved = "\033[31m" // Red
+	ansiAdded   = "\033[32m" // Green
+	ansiChanged = "\033[33m" // Yellow
+	ansiBlocked = "\033[35m" // Magenta
+)
+
+// printFieldChanges prints each changed field before and after, marking
+// the words that differ.
+func printFieldChanges(out io.Writer, changes []diff.FieldChange, color bool) {
+	for _, c := range changes {
+		from, to := diff.Words(c.Current, c.Desired)
+		fmt.Fprintf(out, "      - %s: %s\n", c.Field, renderSegments(from, ansiRemoved, color))
+		fmt.Fprintf(out, "      + %s: %s\n", c.Field, renderSegments(to, ansiAdded, color))
+	}
+}
+
+// renderSegments joins segments, coloring the changed ones. An empty value
+// is shown as (none).
+func renderSegments(segments []diff.Segment, changedColor string, color bool) string {
+	if len(segments) == 0 {
+		return "(none)"
+	}
+	var b strings.Builder
+	for _, seg := range segments {
+		if seg.Changed && color {
+			b.WriteString(changedColor + seg.Text + ansiReset)
+		} else {
+			b.WriteString(seg.Text)
+		}
+	}
+	return b.String()
 }
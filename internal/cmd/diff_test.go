@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestPrintDiffResultTextFieldChanges(t *testing.T) {
+	result := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{{
+			Alias:   "tools",
+			Action:  diff.ActionUpdate,
+			Current: &state.MarketplaceState{Repo: "acme/tools", Ref: "v1.2.0"},
+			Desired: &config.Marketplace{Repo: "acme/tools", Ref: "v1.3.0"},
+		}},
+	}
+
+	var buf bytes.Buffer
+	printDiffResultText(&buf, result, false)
+	want := `  ~ tools: update
+      - ref: v1.2.0
+      + ref: v1.3.0
+`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("printDiffResultText() =\n%s\nwant it to contain:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	printDiffResultText(&buf, result, true)
+	if !strings.Contains(buf.String(), "- ref: v1."+ansiRemoved+"2"+ansiReset+".0") ||
+		!strings.Contains(buf.String(), "+ ref: v1."+ansiAdded+"3"+ansiReset+".0") {
+		t.Errorf("printDiffResultText(color) did not highlight the changed word:\n%q", buf.String())
+	}
+}
+
+func TestHasDiff(t *testing.T) {
+	inSync := &diff.Result{
+		Plugins: []diff.PluginDiff{{Name: "lint@tools", Action: diff.ActionNone}},
+	}
+	if hasDiff(inSync) {
+		t.Error("hasDiff(in sync) = true")
+	}
+
+	for _, action := range []diff.Action{diff.ActionAdd, diff.ActionRemove, diff.ActionDisable, diff.ActionManaged} {
+		result := &diff.Result{Plugins: []diff.PluginDiff{{Name: "lint@tools", Action: action}}}
+		if !hasDiff(result) {
+			t.Errorf("hasDiff(%s) = false", action)
+		}
+	}
+}
+
+func TestPrintDiffStat(t *testing.T) {
+	result := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "tools", Action: diff.ActionAdd},
+			{Alias: "core", Action: diff.ActionNone},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "a@tools", Action: diff.ActionAdd},
+			{Name: "b@tools", Action: diff.ActionAdd},
+			{Name: "c@tools", Action: diff.ActionAdd},
+			{Name: "d@core", Action: diff.ActionDisable},
+			{Name: "e@core", Action: diff.ActionRemove},
+			{Name: "f@core", Action: diff.ActionRemove},
+			{Name: "g@core", Action: diff.ActionManaged},
+		},
+	}
+
+	var buf bytes.Buffer
+	printDiffStat(&buf, diffStats(result), false)
+	want := ` marketplaces | +1          +
+ plugins      | +3 ~1 -2 !1 +++~--!
+ 4 to add, 1 to update, 2 not in Clewfile, 1 blocked
+`
+	if buf.String() != want {
+		t.Errorf("printDiffStat() =\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	printDiffStat(&buf, diffStats(&diff.Result{}), false)
+	if !strings.Contains(buf.String(), "Already in sync") {
+		t.Errorf("printDiffStat(in sync) = %q", buf.String())
+	}
+}
+
+func TestPrintDiffStatScalesBar(t *testing.T) {
+	stats := []DiffStat{
+		{Category: "marketplaces", Remove: 1},
+		{Category: "plugins", Add: 3 * maxStatBar},
+	}
+
+	var buf bytes.Buffer
+	printDiffStat(&buf, stats, false)
+	var bars []string
+	for _, line := range strings.Split(buf.String(), "\n")[:2] {
+		fields := strings.Fields(line)
+		bars = append(bars, fields[len(fields)-1])
+	}
+	if bars[0] != "-" || bars[1] != strings.Repeat("+", maxStatBar) {
+		t.Errorf("bars = %q, want one - and %d +", bars, maxStatBar)
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/backup"
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/lock"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/stamp"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// claudeVersionTimeout bounds how long `claude --version` may take.
+const claudeVersionTimeout = 5 * time.Second
+
+// EnvInfo describes the resolved paths and effective configuration.
+type EnvInfo struct {
+	ClewVersion   string `json:"clew_version" yaml:"clew_version"`
+	Platform      string `json:"platform" yaml:"platform"`
+	Clewfile      string `json:"clewfile" yaml:"clewfile"`
+	ClewfileError string `json:"clewfile_error,omitempty" yaml:"clewfile_error,omitempty"`
+	Scope         string `json:"scope,omitempty" yaml:"scope,omitempty"`
+	ClaudeDir     string `json:"claude_dir" yaml:"claude_dir"`
+	CacheDir      string `json:"cache_dir" yaml:"cache_dir"`
+	BackupDir     string `json:"backup_dir" yaml:"backup_dir"`
+	LockFile      string `json:"lock_file" yaml:"lock_file"`
+	LastSyncFile  string `json:"last_sync_file" yaml:"last_sync_file"`
+	ClaudeBinary  string `json:"claude_binary" yaml:"claude_binary"`
+	ClaudeVersion string `json:"claude_version" yaml:"claude_version"`
+	ReaderMode    string `json:"reader_mode" yaml:"reader_mode"`
+	Profile       string `json:"profile" yaml:"profile"`
+}
+
+func newEnvCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "env",
+		Short: "Show resolved paths and effective configuration",
+		Long: `Env prints the resolved Clewfile path, Claude directory, cache and backup
+directories, detected claude binary and version, state reader mode, and
+active profile. Include its output when reporting issues.
+
+Examples:
+  clew env
+  clew env -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEnv()
+		},
+	}
+}
+
+func runEnv() error {
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	info, err := collectEnv()
+	if err != nil {
+		return err
+	}
+
+	if format == output.FormatText {
+		printEnvText(info)
+		return nil
+	}
+	return output.NewWriter(os.Stdout, format).Write(info)
+}
+
+// collectEnv resolves everything env reports. A missing Clewfile or claude
+// binary is reported rather than treated as an error.
+func collectEnv() (*EnvInfo, error) {
+	claudeDir, err := state.DefaultClaudeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &EnvInfo{
+		ClewVersion: clewVersion,
+		Platform:    runtime.GOOS + "/" + runtime.GOARCH,
+		ClaudeDir:   claudeDir,
+		// clew reads Claude's plugin JSON files directly
+		ReaderMode: "filesystem",
+		// Profiles are not supported yet; the whole Clewfile is always applied
+		Profile: "default",
+	}
+
+	if path, err := config.FindClewfile(configPath); err != nil {
+		info.ClewfileError = err.Error()
+	} else {
+		info.Clewfile = path
+		info.Scope = config.InferScope(path)
+	}
+
+	mgr, err := backup.NewManager(clewVersion)
+	if err != nil {
+		return nil, err
+	}
+	info.BackupDir = mgr.Dir()
+	info.CacheDir = filepath.Dir(info.BackupDir)
+
+	if info.LockFile, err = lock.DefaultPath(claudeDir); err != nil {
+		return nil, err
+	}
+
+	if info.LastSyncFile, err = stamp.DefaultPath(); err != nil {
+		return nil, err
+	}
+
+	info.ClaudeBinary, info.ClaudeVersion = detectClaude()
+
+	return info, nil
+}
+
+// detectClaude returns the path and version of the claude CLI, or empty
+// strings if it is not on PATH.
+func detectClaude() (string, string) {
+	path, err := exec.LookPath("claude")
+	if err != nil {
+		return "", ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), claudeVersionTimeout)
+	defer cancel()
+
+	done := logging.StartCommand(path, []string{"--version"})
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	done(out, err)
+	if err != nil {
+		return path, ""
+	}
+	return path, strings.TrimSpace(string(out))
+}
+
+// printEnvText outputs the environment in human-readable format.
+func printEnvText(info *EnvInfo) {
+	orNone := func(s string) string {
+		if s == "" {
+			return "(none)"
+		}
+		return s
+	}
+
+	clewfile := orNone(info.Clewfile)
+	if info.ClewfileError != "" {
+		clewfile = "(not found: " + info.ClewfileError + ")"
+	}
+
+	claudeBinary := info.ClaudeBinary
+	if claudeBinary == "" {
+		claudeBinary = "(not found on PATH)"
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "clew version:\t%s\n", info.ClewVersion)
+	_, _ = fmt.Fprintf(tw, "platform:\t%s\n", info.Platform)
+	_, _ = fmt.Fprintf(tw, "Clewfile:\t%s\n", clewfile)
+	_, _ = fmt.Fprintf(tw, "scope:\t%s\n", orNone(info.Scope))
+	_, _ = fmt.Fprintf(tw, "Claude dir:\t%s\n", info.ClaudeDir)
+	_, _ = fmt.Fprintf(tw, "cache dir:\t%s\n", info.CacheDir)
+	_, _ = fmt.Fprintf(tw, "backup dir:\t%s\n", info.BackupDir)
+	_, _ = fmt.Fprintf(tw, "lock file:\t%s\n", info.LockFile)
+	_, _ = fmt.Fprintf(tw, "last sync file:\t%s\n", info.LastSyncFile)
+	_, _ = fmt.Fprintf(tw, "claude binary:\t%s\n", claudeBinary)
+	_, _ = fmt.Fprintf(tw, "claude version:\t%s\n", orNone(info.ClaudeVersion))
+	_, _ = fmt.Fprintf(tw, "reader mode:\t%s\n", info.ReaderMode)
+	_, _ = fmt.Fprintf(tw, "profile:\t%s\n", info.Profile)
+	_ = tw.Flush()
+}
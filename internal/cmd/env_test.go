@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCollectEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(tmpDir, "cache"))
+	t.Setenv("PATH", tmpDir) // no claude binary
+
+	clewfilePath := filepath.Join(tmpDir, "Clewfile.yaml")
+	if err := os.WriteFile(clewfilePath, []byte("version: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldConfigPath := configPath
+	configPath = clewfilePath
+	defer func() { configPath = oldConfigPath }()
+
+	info, err := collectEnv()
+	if err != nil {
+		t.Fatalf("collectEnv() error = %v", err)
+	}
+
+	if info.Clewfile != clewfilePath {
+		t.Errorf("Clewfile = %s, want %s", info.Clewfile, clewfilePath)
+	}
+	if info.ClaudeDir != filepath.Join(tmpDir, ".claude") {
+		t.Errorf("ClaudeDir = %s", info.ClaudeDir)
+	}
+	if info.BackupDir != filepath.Join(tmpDir, "cache", "clew", "backups") {
+		t.Errorf("BackupDir = %s", info.BackupDir)
+	}
+	if info.CacheDir != filepath.Join(tmpDir, "cache", "clew") {
+		t.Errorf("CacheDir = %s", info.CacheDir)
+	}
+	if info.ClaudeBinary != "" {
+		t.Errorf("ClaudeBinary = %s, want empty", info.ClaudeBinary)
+	}
+	if info.ReaderMode != "filesystem" {
+		t.Errorf("ReaderMode = %s, want filesystem", info.ReaderMode)
+	}
+}
+
+func TestCollectEnv_MissingClewfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("PATH", tmpDir)
+
+	oldConfigPath := configPath
+	configPath = filepath.Join(tmpDir, "missing.yaml")
+	defer func() { configPath = oldConfigPath }()
+
+	info, err := collectEnv()
+	if err != nil {
+		t.Fatalf("collectEnv() error = %v", err)
+	}
+	if info.Clewfile != "" || info.ClewfileError == "" {
+		t.Errorf("expected missing Clewfile to be reported, got %+v", info)
+	}
+
+	out := captureStdout(t, func() { printEnvText(info) })
+	if !strings.Contains(out, "not found") {
+		t.Errorf("text output should report missing Clewfile:\n%s", out)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !unix
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// clew only ships for darwin and linux; elsewhere the program runs as a
+// child and clew exits with its status.
+
+func execCommand(path string, args []string) error {
+	c := exec.Command(path, args[1:]...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := c.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// execCommand replaces clew with the program at path, so signals and the
+// exit status go straight to it.
+func execCommand(path string, args []string) error {
+	return syscall.Exec(path, args, os.Environ())
+}
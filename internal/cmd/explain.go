@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// Explanation is the reasoning behind the action planned for one item.
+type Explanation struct {
+	Type     string      `json:"type" yaml:"type"` // "marketplace" or "plugin"
+	Name     string      `json:"name" yaml:"name"`
+	Action   diff.Action `json:"action" yaml:"action"`
+	Declared string      `json:"declared,omitempty" yaml:"declared,omitempty"` // Clewfile location, e.g. Clewfile.yaml:12
+	Desired  string      `json:"desired,omitempty" yaml:"desired,omitempty"`   // The entry as the Clewfile declares it
+	Current  string      `json:"current,omitempty" yaml:"current,omitempty"`   // Observed state
+	Sources  []string    `json:"sources,omitempty" yaml:"sources,omitempty"`   // Files the observed state was read from
+	Rule     string      `json:"rule" yaml:"rule"`
+}
+
+func newExplainCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain <plugin|marketplace>",
+		Short: "Explain why a change is planned for an item",
+		Long: `Explain shows the reasoning behind what sync would do with a plugin or
+marketplace: where the Clewfile declares it (file:line), the state observed
+on this machine and the file it was read from, and the comparison that
+decided the action.
+
+A bare plugin name must match a single plugin; use plugin@marketplace to
+pick one.`,
+		Example: `  clew explain linter@claude-plugins-official
+  clew explain claude-plugins-official`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExplain(args[0])
+		},
+	}
+}
+
+// runExplain explains the action planned for name.
+func runExplain(name string) error {
+	clewfilePath, err := config.FindClewfile(configPath)
+	if err != nil {
+		return err
+	}
+	resolved, err := config.Resolve(clewfilePath)
+	if err != nil {
+		return err
+	}
+
+	claudeDir, err := state.DefaultClaudeDir()
+	if err != nil {
+		return err
+	}
+	reader := &state.FilesystemReader{ClaudeDir: claudeDir}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	e, err := explain(name, resolved, currentState, claudeDir)
+	if err != nil {
+		return err
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		return writer.Write(e)
+	}
+	printExplanation(os.Stdout, e)
+	return nil
+}
+
+// explain finds name in the diff between the resolved Clewfile and
+// currentState and assembles the reasoning for its action.
+func explain(name string, r *config.Resolved, currentState *state.State, claudeDir string) (*Explanation, error) {
+	clewfile := r.Clewfile
+	for _, ignored := range [][]string{clewfile.Ignore.Marketplaces, clewfile.Ignore.Plugins} {
+		for _, n := range ignored {
+			if n == name {
+				return &Explanation{
+					Name:     name,
+					Action:   diff.ActionNone,
+					Declared: formatOrigin(config.Origin{File: r.Path}),
+					Rule:     "listed under ignore in the Clewfile, so it is left alone and not reported",
+				}, nil
+			}
+		}
+	}
+
+	result := diff.Compute(clewfile, currentState)
+	marketplacesFile := filepath.Join(claudeDir, "plugins", "known_marketplaces.json")
+	pluginsFile := filepath.Join(claudeDir, "plugins", "installed_plugins.json")
+
+	if !strings.Contains(name, "@") {
+		for _, m := range result.Marketplaces {
+			if m.Alias != name {
+				continue
+			}
+			e := &Explanation{Type: "marketplace", Name: m.Alias, Action: m.Action, Rule: m.Reason()}
+			if m.Desired != nil {
+				e.Declared = formatOrigin(r.Marketplaces[m.Alias])
+				e.Desired = describeMarketplace(m.Desired.Repo, m.Desired.Ref)
+			}
+			if m.Current != nil {
+				e.Current = "installed from " + describeMarketplace(m.Current.Repo, m.Current.Ref)
+				e.Sources = []string{marketplacesFile}
+			} else {
+				e.Current = "not installed"
+			}
+			return e, nil
+		}
+	}
+
+	p, err := findPluginDiff(name, result)
+	if err != nil {
+		return nil, err
+	}
+	e := &Explanation{Type: "plugin", Name: p.Name, Action: p.Action, Rule: p.Reason()}
+	if p.Desired != nil {
+		for i, declared := range clewfile.Plugins {
+			if declared.Name == p.Name {
+				e.Declared = formatOrigin(r.Plugins[i])
+				break
+			}
+		}
+		e.Desired = p.Name + " (" + describePlugin(*p.Desired) + ")"
+	}
+	if c := p.Current; c != nil {
+		enabled := "enabled"
+		if !c.Enabled {
+			enabled = "disabled"
+		}
+		e.Current = enabled
+		if c.Scope != "" {
+			e.Current += ", scope " + c.Scope
+		}
+		if c.Version != "" {
+			e.Current += ", version " + c.Version
+		}
+		e.Sources = append(e.Sources, pluginsFile)
+		switch c.EnabledSource {
+		case "":
+			e.Current += " (no settings entry, so enabled by default)"
+		case state.ManagedSettingsFile:
+			if currentState.Managed != nil && currentState.Managed.Path != "" {
+				e.Sources = append(e.Sources, currentState.Managed.Path)
+			} else {
+				e.Sources = append(e.Sources, state.DefaultManagedSettingsPath())
+			}
+		default:
+			e.Sources = append(e.Sources, filepath.Join(claudeDir, c.EnabledSource))
+		}
+	} else {
+		e.Current = "not installed"
+	}
+	return e, nil
+}
+
+// findPluginDiff finds a plugin in the diff by full or bare name.
+func findPluginDiff(name string, result *diff.Result) (diff.PluginDiff, error) {
+	var matches []diff.PluginDiff
+	for _, p := range result.Plugins {
+		if p.Name == name {
+			return p, nil
+		}
+		if bare, _, ok := strings.Cut(p.Name, "@"); ok && bare == name && !strings.Contains(name, "@") {
+			matches = append(matches, p)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return diff.PluginDiff{}, fmt.Errorf("%q is neither declared in the Clewfile nor installed", name)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, p := range matches {
+			names = append(names, p.Name)
+		}
+		sort.Strings(names)
+		return diff.PluginDiff{}, fmt.Errorf("plugin %q is ambiguous: %s", name, strings.Join(names, ", "))
+	}
+}
+
+// formatOrigin renders an origin as file:line.
+func formatOrigin(o config.Origin) string {
+	s := o.File
+	if o.Line > 0 {
+		s += ":" + strconv.Itoa(o.Line)
+	}
+	if o.Written != "" {
+		s += " (written " + o.Written + ")"
+	}
+	return s
+}
+
+// describeMarketplace renders a repo and optional ref.
+func describeMarketplace(repo, ref string) string {
+	if ref == "" {
+		return repo
+	}
+	return repo + " at " + ref
+}
+
+// printExplanation prints the reasoning chain for one item.
+func printExplanation(out io.Writer, e *Explanation) {
+	if e.Type != "" {
+		_, _ = fmt.Fprintf(out, "%s %s: %s\n\n", e.Type, e.Name, e.Action)
+	} else {
+		_, _ = fmt.Fprintf(out, "%s: %s\n\n", e.Name, e.Action)
+	}
+
+	declared := "not declared"
+	if e.Declared != "" {
+		declared = e.Declared
+		if e.Desired != "" {
+			declared += "\n           " + e.Desired
+		}
+	}
+	_, _ = fmt.Fprintf(out, "Clewfile:  %s\n", declared)
+	if e.Current != "" {
+		_, _ = fmt.Fprintf(out, "Current:   %s\n", e.Current)
+		for _, source := range e.Sources {
+			_, _ = fmt.Fprintf(out, "           from %s\n", source)
+		}
+	}
+	_, _ = fmt.Fprintf(out, "Rule:      %s\n", e.Rule)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestExplain(t *testing.T) {
+	disabled := false
+	path := "/home/me/.config/claude/Clewfile.yaml"
+	r := &config.Resolved{
+		Path: path,
+		Clewfile: &config.Clewfile{
+			Marketplaces: map[string]config.Marketplace{"official": {Repo: "anthropics/claude-plugins", Ref: "v2"}},
+			Plugins: []config.Plugin{
+				{Name: "context7@official"},
+				{Name: "linter@official", Enabled: &disabled},
+			},
+			Ignore: config.IgnoreConfig{Plugins: []string{"scratch@official"}},
+		},
+		Marketplaces: map[string]config.Origin{"official": {File: path, Line: 3}},
+		Plugins:      []config.Origin{{File: path, Line: 7}, {File: path, Line: 8}},
+	}
+	currentState := &state.State{
+		Marketplaces: map[string]state.MarketplaceState{
+			"official": {Alias: "official", Repo: "anthropics/claude-plugins"},
+		},
+		Plugins: map[string]state.PluginState{
+			"linter@official": {Name: "linter", Marketplace: "official", Enabled: true, Scope: "user", EnabledSource: state.SettingsLocalFile},
+		},
+	}
+	claudeDir := "/home/me/.claude"
+
+	tests := []struct {
+		name       string
+		wantAction diff.Action
+		wantIn     []string
+	}{
+		{"linter", diff.ActionDisable, []string{
+			path + ":8",
+			"enabled, scope user",
+			filepath.Join(claudeDir, "plugins", "installed_plugins.json"),
+			filepath.Join(claudeDir, state.SettingsLocalFile),
+			"sets enabled: false",
+		}},
+		{"context7@official", diff.ActionAdd, []string{path + ":7", "not installed", "not installed"}},
+		{"official", diff.ActionUpdate, []string{path + ":3", "ref differs: the Clewfile has v2, installed at (none)"}},
+		{"scratch@official", diff.ActionNone, []string{"listed under ignore"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := explain(tt.name, r, currentState, claudeDir)
+			if err != nil {
+				t.Fatalf("explain() error = %v", err)
+			}
+			if e.Action != tt.wantAction {
+				t.Errorf("Action = %s, want %s", e.Action, tt.wantAction)
+			}
+			var buf bytes.Buffer
+			printExplanation(&buf, e)
+			for _, want := range tt.wantIn {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+
+	if _, err := explain("unknown", r, currentState, claudeDir); err == nil {
+		t.Error("explain() of an unknown item should fail")
+	}
+}
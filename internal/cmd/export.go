@@ -9,19 +9,37 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/adamancini/clew/internal/i18n"
 	"github.com/adamancini/clew/internal/output"
 	"github.com/adamancini/clew/internal/state"
 )
 
 func newExportCmd() *cobra.Command {
-	return &cobra.Command{
+	var redact bool
+
+	cmd := &cobra.Command{
 		Use:   "export",
 		Short: "Export current state as Clewfile",
-		Long:  `Export reads the current Claude Code configuration and outputs it as a Clewfile.`,
+		Long: `Export reads the current Claude Code configuration and outputs it as a Clewfile.
+
+With --redact, credentials embedded in marketplace repo URLs (the user info of
+an https URL, and query parameters named like token, key, secret or password)
+are replaced with ${VAR} references named after the marketplace, such as
+${ACME_TOKEN}. The Clewfile can then be shared publicly and still works for
+anyone who sets those variables.`,
+		Example: `  clew export > Clewfile
+  clew export --redact > Clewfile`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runExport()
+			return runExport(redact)
 		},
 	}
+
+	cmd.Flags().BoolVar(&redact, "redact", false, "Replace credentials in marketplace URLs with ${VAR} references")
+
+	cmd.AddCommand(newExportDevcontainerCmd())
+	cmd.AddCommand(newExportNixCmd())
+
+	return cmd
 }
 
 // ExportedClewfile represents the exported configuration in Clewfile format.
@@ -30,6 +48,7 @@ type ExportedClewfile struct {
 	Version      int                           `json:"version" yaml:"version"`
 	Marketplaces map[string]ExportedMarketplace `json:"marketplaces,omitempty" yaml:"marketplaces,omitempty"`
 	Plugins      []ExportedPlugin              `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	Keybindings  state.Keybindings             `json:"keybindings,omitempty" yaml:"keybindings,omitempty"`
 }
 
 // ExportedMarketplace represents a marketplace for export.
@@ -46,25 +65,17 @@ type ExportedPlugin struct {
 }
 
 // runExport executes the export workflow.
-func runExport() error {
-	// 1. Read current state
-	reader := &state.FilesystemReader{}
-	currentState, err := reader.Read()
+func runExport(redact bool) error {
+	// 1-3. Read current state and convert to Clewfile structure
+	exported, err := exportCurrentState()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading current state: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// 2. Resolve marketplaces directory for orphan detection
-	home, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home directory: %v\n", err)
-		os.Exit(1)
+	if redact {
+		printRedactionNote(redactClewfile(exported))
 	}
-	marketplacesDir := filepath.Join(home, ".claude", "plugins", "marketplaces")
-
-	// 3. Convert state to Clewfile structure
-	exported := convertStateToClewfile(currentState, marketplacesDir)
 
 	// 4. Output in the specified format
 	format, err := output.ParseFormat(outputFormat)
@@ -87,6 +98,25 @@ func runExport() error {
 	return nil
 }
 
+// exportCurrentState reads the current state and converts it to a Clewfile
+// structure, skipping entries that cannot be represented portably.
+func exportCurrentState() (*ExportedClewfile, error) {
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	// Resolve marketplaces directory for orphan detection
+	claudeDir, err := state.DefaultClaudeDir()
+	if err != nil {
+		return nil, err
+	}
+	marketplacesDir := filepath.Join(claudeDir, "plugins", "marketplaces")
+
+	return convertStateToClewfile(currentState, marketplacesDir), nil
+}
+
 // convertStateToClewfile converts the current state to a Clewfile structure.
 // marketplacesDir is the path to the marketplaces directory (e.g., ~/.claude/plugins/marketplaces)
 // used to verify that plugins still exist in their marketplace before exporting.
@@ -118,15 +148,22 @@ func convertStateToClewfile(s *state.State, marketplacesDir string) *ExportedCle
 	}
 	if len(skippedMarketplaces) > 0 {
 		sort.Strings(skippedMarketplaces)
-		fmt.Fprintf(os.Stderr, "Note: Skipped %d local marketplace(s) (no repo): %v\n",
-			len(skippedMarketplaces), skippedMarketplaces)
+		fmt.Fprintln(os.Stderr, i18n.N(i18n.ExportSkippedMarketplaces, len(skippedMarketplaces), skippedMarketplaces))
 	}
 
 	// Convert plugins, skipping those that reference non-existent marketplaces
 	// or whose directory no longer exists in the marketplace.
 	var skippedNoMarketplace []string // plugin references a marketplace not in exported state
 	var skippedOrphaned []string      // plugin's marketplace exists but plugin directory doesn't
+	var skippedProject []string       // plugin only installed at project or local scope
 	for fullName, p := range s.Plugins {
+		// Clewfiles only declare user-scope plugins; installs for single
+		// projects are left to each project's own settings
+		if !p.InstalledAt("user") {
+			skippedProject = append(skippedProject, fullName)
+			continue
+		}
+
 		// Parse plugin@marketplace format and check if marketplace exists
 		if parts := strings.SplitN(fullName, "@", 2); len(parts) == 2 {
 			pluginName := parts[0]
@@ -152,23 +189,21 @@ func convertStateToClewfile(s *state.State, marketplacesDir string) *ExportedCle
 			enabled := false
 			ep.Enabled = &enabled
 		}
-		// Only include scope if not empty
-		if p.Scope != "" && p.Scope != "user" {
-			ep.Scope = p.Scope
-		}
 		exported.Plugins = append(exported.Plugins, ep)
 	}
 
 	// Log skipped plugins to stderr
 	if len(skippedNoMarketplace) > 0 {
 		sort.Strings(skippedNoMarketplace)
-		fmt.Fprintf(os.Stderr, "Note: Skipped %d plugin(s) referencing non-marketplace sources: %v\n",
-			len(skippedNoMarketplace), skippedNoMarketplace)
+		fmt.Fprintln(os.Stderr, i18n.N(i18n.ExportSkippedNoMarketplace, len(skippedNoMarketplace), skippedNoMarketplace))
+	}
+	if len(skippedProject) > 0 {
+		sort.Strings(skippedProject)
+		fmt.Fprintln(os.Stderr, i18n.N(i18n.ExportSkippedProject, len(skippedProject), skippedProject))
 	}
 	if len(skippedOrphaned) > 0 {
 		sort.Strings(skippedOrphaned)
-		fmt.Fprintf(os.Stderr, "Note: Skipped %d plugin(s) not found in marketplace directory: %v\n",
-			len(skippedOrphaned), skippedOrphaned)
+		fmt.Fprintln(os.Stderr, i18n.N(i18n.ExportSkippedOrphaned, len(skippedOrphaned), skippedOrphaned))
 	}
 
 	// Sort plugins by marketplace name, then by plugin name for readability
@@ -203,5 +238,11 @@ func convertStateToClewfile(s *state.State, marketplacesDir string) *ExportedCle
 		exported.Plugins = nil
 	}
 
+	// Custom key bindings are exported whole; sync manages only the keys
+	// the Clewfile keeps
+	if len(s.Keybindings) > 0 {
+		exported.Keybindings = s.Keybindings
+	}
+
 	return exported
 }
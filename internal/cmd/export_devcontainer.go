@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/update"
+)
+
+// DevcontainerOptions configures devcontainer feature generation.
+type DevcontainerOptions struct {
+	Dir         string // Output directory for the feature
+	Clewfile    string // Clewfile path relative to the workspace folder
+	ClewVersion string // clew release to install ("latest" or a version)
+}
+
+func newExportDevcontainerCmd() *cobra.Command {
+	var opts DevcontainerOptions
+
+	cmd := &cobra.Command{
+		Use:   "devcontainer",
+		Short: "Generate a devcontainer feature that installs clew and syncs",
+		Long: `Generate a devcontainer feature (devcontainer-feature.json + install.sh).
+
+The feature installs the clew binary when the container image is built and
+runs 'clew sync --ci' against the project Clewfile when the container is
+created. Reference it from devcontainer.json:
+
+  "features": {
+    "./features/clew": {}
+  }
+
+The claude CLI must also be present in the container; the feature is ordered
+after the official Claude Code feature.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExportDevcontainer(opts)
+		},
+	}
+
+	defaultVersion := "latest"
+	if v, err := update.ParseVersion(clewVersion); err == nil {
+		defaultVersion = v.String()
+	}
+
+	cmd.Flags().StringVar(&opts.Dir, "dir", filepath.Join(".devcontainer", "features", "clew"), "Output directory for the feature")
+	cmd.Flags().StringVar(&opts.Clewfile, "clewfile", "Clewfile", "Clewfile path relative to the workspace folder")
+	cmd.Flags().StringVar(&opts.ClewVersion, "clew-version", defaultVersion, "clew release to install (latest or a version)")
+
+	return cmd
+}
+
+// runExportDevcontainer writes the devcontainer feature files.
+func runExportDevcontainer(opts DevcontainerOptions) error {
+	files, err := generateDevcontainerFeature(opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", opts.Dir, err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		perm := os.FileMode(0644)
+		if strings.HasSuffix(name, ".sh") {
+			perm = 0755
+		}
+		path := filepath.Join(opts.Dir, name)
+		if err := atomicfile.WriteFile(path, files[name], perm); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if !quiet {
+			fmt.Printf("Wrote %s\n", path)
+		}
+	}
+
+	return nil
+}
+
+// devcontainerFeature is the devcontainer-feature.json metadata.
+type devcontainerFeature struct {
+	ID                string                        `json:"id"`
+	Version           string                        `json:"version"`
+	Name              string                        `json:"name"`
+	Description       string                        `json:"description"`
+	DocumentationURL  string                        `json:"documentationURL"`
+	Options           map[string]devcontainerOption `json:"options"`
+	PostCreateCommand string                        `json:"postCreateCommand"`
+	InstallsAfter     []string                      `json:"installsAfter"`
+}
+
+// devcontainerOption is a single user-facing feature option.
+type devcontainerOption struct {
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Description string `json:"description"`
+}
+
+// devcontainerPostCreate is where install.sh places the post-create hook.
+const devcontainerPostCreate = "/usr/local/share/clew/post-create.sh"
+
+// generateDevcontainerFeature renders the feature files keyed by file name.
+func generateDevcontainerFeature(opts DevcontainerOptions) (map[string][]byte, error) {
+	if opts.Clewfile == "" {
+		return nil, fmt.Errorf("clewfile path cannot be empty")
+	}
+	if opts.ClewVersion == "" {
+		opts.ClewVersion = "latest"
+	}
+
+	featureVersion := "1.0.0"
+	if v, err := update.ParseVersion(opts.ClewVersion); err == nil {
+		featureVersion = v.String()
+	}
+
+	metadata := devcontainerFeature{
+		ID:               "clew",
+		Version:          featureVersion,
+		Name:             "clew",
+		Description:      "Installs clew and syncs Claude Code plugins from the project Clewfile",
+		DocumentationURL: "https://github.com/adamancini/clew",
+		Options: map[string]devcontainerOption{
+			"version": {
+				Type:        "string",
+				Default:     opts.ClewVersion,
+				Description: "clew release to install (latest or a version)",
+			},
+			"clewfile": {
+				Type:        "string",
+				Default:     opts.Clewfile,
+				Description: "Clewfile path relative to the workspace folder",
+			},
+		},
+		PostCreateCommand: devcontainerPostCreate,
+		InstallsAfter:     []string{"ghcr.io/anthropics/devcontainer-features/claude-code"},
+	}
+
+	metadataJSON, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal feature metadata: %w", err)
+	}
+
+	return map[string][]byte{
+		"devcontainer-feature.json": append(metadataJSON, '\n'),
+		"install.sh":                []byte(devcontainerInstallScript),
+	}, nil
+}
+
+// devcontainerInstallScript installs clew at image build time and writes the
+// post-create hook. Feature options arrive as upper-cased environment variables.
+const devcontainerInstallScript = `#!/bin/sh
+# Generated by 'clew export devcontainer'.
+set -eu
+
+CLEW_VERSION="${VERSION:-latest}"
+CLEWFILE_PATH="${CLEWFILE:-Clewfile}"
+INSTALL_DIR="/usr/local/bin"
+SHARE_DIR="/usr/local/share/clew"
+
+case "$(uname -s)" in
+    Linux)  os="linux" ;;
+    Darwin) os="darwin" ;;
+    *) echo "clew: unsupported OS $(uname -s)" >&2; exit 1 ;;
+esac
+
+case "$(uname -m)" in
+    x86_64|amd64)  arch="amd64" ;;
+    aarch64|arm64) arch="arm64" ;;
+    *) echo "clew: unsupported architecture $(uname -m)" >&2; exit 1 ;;
+esac
+
+binary="clew-${os}-${arch}"
+if [ "$CLEW_VERSION" = "latest" ]; then
+    base_url="https://github.com/adamancini/clew/releases/latest/download"
+else
+    base_url="https://github.com/adamancini/clew/releases/download/v${CLEW_VERSION#v}"
+fi
+
+fetch() {
+    if command -v curl >/dev/null 2>&1; then
+        curl -fsSL "$1" -o "$2"
+    elif command -v wget >/dev/null 2>&1; then
+        wget -qO "$2" "$1"
+    else
+        echo "clew: curl or wget is required" >&2
+        exit 1
+    fi
+}
+
+tmp="$(mktemp -d)"
+trap 'rm -rf "$tmp"' EXIT
+
+echo "Installing clew ${CLEW_VERSION} (${os}/${arch})..."
+fetch "${base_url}/${binary}" "${tmp}/${binary}"
+fetch "${base_url}/checksums.txt" "${tmp}/checksums.txt"
+
+if command -v sha256sum >/dev/null 2>&1; then
+    (cd "$tmp" && grep " ${binary}\$" checksums.txt | sha256sum -c -)
+else
+    echo "clew: sha256sum not found, skipping checksum verification" >&2
+fi
+
+install -m 0755 "${tmp}/${binary}" "${INSTALL_DIR}/clew"
+
+mkdir -p "$SHARE_DIR"
+cat > "${SHARE_DIR}/post-create.sh" <<EOF
+#!/bin/sh
+# Runs from the workspace folder when the container is created.
+if [ -f "${CLEWFILE_PATH}" ]; then
+    exec clew sync --ci --config "${CLEWFILE_PATH}"
+fi
+echo "clew: no Clewfile at ${CLEWFILE_PATH}, skipping sync"
+EOF
+chmod 0755 "${SHARE_DIR}/post-create.sh"
+
+echo "clew installed to ${INSTALL_DIR}/clew"
+`
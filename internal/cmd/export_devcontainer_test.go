@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDevcontainerFeature(t *testing.T) {
+	files, err := generateDevcontainerFeature(DevcontainerOptions{
+		Clewfile:    ".devcontainer/Clewfile.yaml",
+		ClewVersion: "v1.2.3",
+	})
+	if err != nil {
+		t.Fatalf("generateDevcontainerFeature() error = %v", err)
+	}
+
+	var metadata devcontainerFeature
+	if err := json.Unmarshal(files["devcontainer-feature.json"], &metadata); err != nil {
+		t.Fatalf("devcontainer-feature.json is not valid JSON: %v", err)
+	}
+
+	if metadata.ID != "clew" {
+		t.Errorf("ID = %q, want clew", metadata.ID)
+	}
+	if metadata.Version != "1.2.3" {
+		t.Errorf("Version = %q, want 1.2.3", metadata.Version)
+	}
+	if got := metadata.Options["clewfile"].Default; got != ".devcontainer/Clewfile.yaml" {
+		t.Errorf("clewfile option default = %q", got)
+	}
+	if metadata.PostCreateCommand != devcontainerPostCreate {
+		t.Errorf("PostCreateCommand = %q, want %q", metadata.PostCreateCommand, devcontainerPostCreate)
+	}
+
+	script := string(files["install.sh"])
+	for _, want := range []string{"#!/bin/sh", "clew sync --ci", "checksums.txt", "post-create.sh"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("install.sh missing %q", want)
+		}
+	}
+}
+
+func TestGenerateDevcontainerFeature_DevVersion(t *testing.T) {
+	files, err := generateDevcontainerFeature(DevcontainerOptions{Clewfile: "Clewfile", ClewVersion: "dev"})
+	if err != nil {
+		t.Fatalf("generateDevcontainerFeature() error = %v", err)
+	}
+
+	var metadata devcontainerFeature
+	if err := json.Unmarshal(files["devcontainer-feature.json"], &metadata); err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Version != "1.0.0" {
+		t.Errorf("Version = %q, want fallback 1.0.0", metadata.Version)
+	}
+}
+
+func TestGenerateDevcontainerFeature_EmptyClewfile(t *testing.T) {
+	if _, err := generateDevcontainerFeature(DevcontainerOptions{}); err == nil {
+		t.Error("expected error for empty clewfile path")
+	}
+}
+
+func TestRunExportDevcontainer_WritesFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "features", "clew")
+
+	_ = captureStdout(t, func() {
+		if err := runExportDevcontainer(DevcontainerOptions{Dir: dir, Clewfile: "Clewfile", ClewVersion: "latest"}); err != nil {
+			t.Fatalf("runExportDevcontainer() error = %v", err)
+		}
+	})
+
+	info, err := os.Stat(filepath.Join(dir, "install.sh"))
+	if err != nil {
+		t.Fatalf("install.sh not written: %v", err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("install.sh is not executable: %v", info.Mode())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "devcontainer-feature.json")); err != nil {
+		t.Errorf("devcontainer-feature.json not written: %v", err)
+	}
+}
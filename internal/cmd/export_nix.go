@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newExportNixCmd() *cobra.Command {
+	var clewBin string
+
+	cmd := &cobra.Command{
+		Use:   "nix",
+		Short: "Export current state as a home-manager module",
+		Long: `Export the current configuration as a home-manager module.
+
+The generated module embeds the Clewfile as a Nix attribute set, installs it
+to ~/.config/claude/Clewfile.json and runs 'clew sync --ci' during home-manager
+activation, so the Nix configuration becomes the single declarative source.
+
+Import it from home.nix:
+
+  imports = [ ./clew.nix ];
+
+Example:
+  clew export nix > ~/.config/home-manager/clew.nix`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exported, err := exportCurrentState()
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprint(os.Stdout, renderNixModule(exported, clewBin))
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&clewBin, "clew-bin", "clew", "clew executable invoked during activation")
+
+	return cmd
+}
+
+// renderNixModule renders an exported Clewfile as a home-manager module.
+func renderNixModule(exported *ExportedClewfile, clewBin string) string {
+	var b strings.Builder
+
+	b.WriteString("# Generated by 'clew export nix'.\n")
+	b.WriteString("{ config, lib, pkgs, ... }:\n\n")
+	b.WriteString("let\n")
+	b.WriteString("  clewfile = {\n")
+	fmt.Fprintf(&b, "    version = %d;\n", exported.Version)
+
+	if len(exported.Marketplaces) > 0 {
+		aliases := make([]string, 0, len(exported.Marketplaces))
+		for alias := range exported.Marketplaces {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+
+		b.WriteString("    marketplaces = {\n")
+		for _, alias := range aliases {
+			m := exported.Marketplaces[alias]
+			fmt.Fprintf(&b, "      %s = { repo = %s;", nixAttrName(alias), nixString(m.Repo))
+			if m.Ref != "" {
+				fmt.Fprintf(&b, " ref = %s;", nixString(m.Ref))
+			}
+			b.WriteString(" };\n")
+		}
+		b.WriteString("    };\n")
+	}
+
+	b.WriteString("    plugins = [\n")
+	for _, p := range exported.Plugins {
+		if p.Enabled == nil && p.Scope == "" {
+			fmt.Fprintf(&b, "      %s\n", nixString(p.Name))
+			continue
+		}
+		fmt.Fprintf(&b, "      { name = %s;", nixString(p.Name))
+		if p.Enabled != nil {
+			fmt.Fprintf(&b, " enabled = %t;", *p.Enabled)
+		}
+		if p.Scope != "" {
+			fmt.Fprintf(&b, " scope = %s;", nixString(p.Scope))
+		}
+		b.WriteString(" }\n")
+	}
+	b.WriteString("    ];\n")
+
+	if len(exported.Keybindings) > 0 {
+		contexts := make([]string, 0, len(exported.Keybindings))
+		for context := range exported.Keybindings {
+			contexts = append(contexts, context)
+		}
+		sort.Strings(contexts)
+
+		b.WriteString("    keybindings = {\n")
+		for _, context := range contexts {
+			bindings := exported.Keybindings[context]
+			keys := make([]string, 0, len(bindings))
+			for key := range bindings {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			fmt.Fprintf(&b, "      %s = {", nixAttrName(context))
+			for _, key := range keys {
+				fmt.Fprintf(&b, " %s = %s;", nixAttrName(key), nixString(bindings[key]))
+			}
+			b.WriteString(" };\n")
+		}
+		b.WriteString("    };\n")
+	}
+
+	b.WriteString("  };\n")
+	b.WriteString("in\n")
+	b.WriteString("{\n")
+	b.WriteString("  xdg.configFile.\"claude/Clewfile.json\".text = builtins.toJSON clewfile;\n\n")
+	b.WriteString("  home.activation.clewSync = lib.hm.dag.entryAfter [ \"writeBoundary\" ] ''\n")
+	fmt.Fprintf(&b, "    run %s sync --ci --config ${config.xdg.configHome}/claude/Clewfile.json\n", nixIndentedString(clewBin))
+	b.WriteString("  '';\n")
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// nixString quotes s as a Nix double-quoted string.
+func nixString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", `\${`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// nixIndentedString escapes s for use inside a Nix indented string.
+func nixIndentedString(s string) string {
+	r := strings.NewReplacer("''", "'''", "${", "''${")
+	return r.Replace(s)
+}
+
+// nixAttrName returns alias as a Nix attribute name, quoting it when it is
+// not a plain identifier.
+func nixAttrName(alias string) string {
+	for i, r := range alias {
+		isAlpha := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_'
+		isOther := (r >= '0' && r <= '9') || r == '-' || r == '\''
+		if !isAlpha && (i == 0 || !isOther) {
+			return nixString(alias)
+		}
+	}
+	if alias == "" {
+		return `""`
+	}
+	return alias
+}
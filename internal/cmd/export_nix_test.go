@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestRenderNixModule(t *testing.T) {
+	disabled := false
+	exported := &ExportedClewfile{
+		Version: 1,
+		Marketplaces: map[string]ExportedMarketplace{
+			"official":  {Repo: "anthropics/claude-plugins-official"},
+			"1password": {Repo: "owner/op", Ref: "v2"},
+		},
+		Plugins: []ExportedPlugin{
+			{Name: "context7@official"},
+			{Name: "linear@official", Enabled: &disabled},
+		},
+		Keybindings: state.Keybindings{
+			"Chat": {"ctrl+e": "chat:submit", "ctrl+g": ""},
+		},
+	}
+
+	got := renderNixModule(exported, "clew")
+
+	for _, want := range []string{
+		"{ config, lib, pkgs, ... }:",
+		"version = 1;",
+		`official = { repo = "anthropics/claude-plugins-official"; };`,
+		`"1password" = { repo = "owner/op"; ref = "v2"; };`,
+		`"context7@official"`,
+		`{ name = "linear@official"; enabled = false; }`,
+		`Chat = { "ctrl+e" = "chat:submit"; "ctrl+g" = ""; };`,
+		`xdg.configFile."claude/Clewfile.json".text = builtins.toJSON clewfile;`,
+		"lib.hm.dag.entryAfter",
+		"run clew sync --ci --config ${config.xdg.configHome}/claude/Clewfile.json",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered module missing %q\n%s", want, got)
+		}
+	}
+
+	// Marketplaces are sorted for stable output
+	if strings.Index(got, `"1password"`) > strings.Index(got, "official =") {
+		t.Error("marketplaces are not sorted")
+	}
+}
+
+func TestNixString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", `"plain"`},
+		{`quo"te`, `"quo\"te"`},
+		{`back\slash`, `"back\\slash"`},
+		{"${interp}", `"\${interp}"`},
+	}
+
+	for _, tt := range tests {
+		if got := nixString(tt.in); got != tt.want {
+			t.Errorf("nixString(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNixAttrName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"official", "official"},
+		{"claude-plugins-official", "claude-plugins-official"},
+		{"my_market2", "my_market2"},
+		{"1password", `"1password"`},
+		{"has.dot", `"has.dot"`},
+	}
+
+	for _, tt := range tests {
+		if got := nixAttrName(tt.in); got != tt.want {
+			t.Errorf("nixAttrName(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
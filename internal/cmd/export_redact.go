@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/adamancini/clew/internal/i18n"
+)
+
+// secretParamPattern matches URL query parameter names whose values are
+// credentials, such as private_token or api_key.
+var secretParamPattern = regexp.MustCompile(`(?i)(token|key|secret|password)`)
+
+// envNameUnsafe matches the characters replaced when a marketplace alias is
+// turned into an environment variable name.
+var envNameUnsafe = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// redactClewfile replaces credentials embedded in marketplace repo URLs with
+// ${VAR} references, so the Clewfile can be shared and still works for anyone
+// who sets the variables. It returns the variables it introduced.
+func redactClewfile(exported *ExportedClewfile) []string {
+	var vars []string
+	for alias, m := range exported.Marketplaces {
+		repo, added := redactRepoURL(alias, m.Repo)
+		if len(added) == 0 {
+			continue
+		}
+		m.Repo = repo
+		exported.Marketplaces[alias] = m
+		vars = append(vars, added...)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// redactRepoURL replaces the credentials in a repo URL's user info and in
+// secret-looking query parameters with references to environment variables
+// named after the marketplace alias: ${ALIAS_TOKEN} for the user info and
+// ${ALIAS_PARAM} for a parameter. Other repo forms are returned unchanged.
+func redactRepoURL(alias, repo string) (string, []string) {
+	scheme, rest, ok := strings.Cut(repo, "://")
+	if !ok {
+		// owner/repo and scp-style git@host:path carry no credentials
+		return repo, nil
+	}
+	prefix := envNameUnsafe.ReplaceAllString(strings.ToUpper(alias), "_")
+	var vars []string
+
+	rest, fragment, hasFragment := strings.Cut(rest, "#")
+	rest, query, hasQuery := strings.Cut(rest, "?")
+	authority, path, hasPath := strings.Cut(rest, "/")
+
+	if at := strings.LastIndex(authority, "@"); at >= 0 && isHTTPScheme(scheme) {
+		user, _, hasPassword := strings.Cut(authority[:at], ":")
+		name := prefix + "_TOKEN"
+		if hasPassword {
+			// user:password, where the user is usually x-access-token or oauth2
+			authority = user + ":${" + name + "}" + authority[at:]
+		} else {
+			// https://TOKEN@host
+			authority = "${" + name + "}" + authority[at:]
+		}
+		vars = append(vars, name)
+	}
+
+	if hasQuery {
+		params := strings.Split(query, "&")
+		for i, param := range params {
+			key, _, hasValue := strings.Cut(param, "=")
+			if !hasValue || !secretParamPattern.MatchString(key) {
+				continue
+			}
+			name := prefix + "_" + envNameUnsafe.ReplaceAllString(strings.ToUpper(key), "_")
+			params[i] = key + "=${" + name + "}"
+			vars = append(vars, name)
+		}
+		query = strings.Join(params, "&")
+	}
+
+	out := scheme + "://" + authority
+	if hasPath {
+		out += "/" + path
+	}
+	if hasQuery {
+		out += "?" + query
+	}
+	if hasFragment {
+		out += "#" + fragment
+	}
+	return out, vars
+}
+
+// isHTTPScheme reports whether credentials in the user info are secrets; for
+// ssh URLs the user is an account name such as git.
+func isHTTPScheme(scheme string) bool {
+	scheme = strings.ToLower(scheme)
+	return scheme == "http" || scheme == "https"
+}
+
+// printRedactionNote tells the user which variables the shared Clewfile
+// now needs.
+func printRedactionNote(vars []string) {
+	if len(vars) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, i18n.N(i18n.ExportRedacted, len(vars), strings.Join(vars, ", ")))
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/fleet"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/output"
+)
+
+func newFleetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fleet",
+		Short: "Check several machines against a shared Clewfile",
+		Long: `Fleet compares the Claude Code configuration of several machines with
+your Clewfile. Hosts are listed in a YAML file:
+
+  hosts:
+    - name: laptop
+      ssh: me@laptop          # runs 'clew export --output json' over ssh
+    - name: build-box
+      ssh: ci@build-box
+      clew: /opt/bin/clew     # clew binary on the host (default: clew)
+    - name: desktop
+      manifest: desktop.json  # saved 'clew export --output json' output
+
+Manifest paths are relative to the hosts file. SSH runs non-interactively,
+so hosts must accept key-based authentication.`,
+	}
+
+	cmd.AddCommand(newFleetStatusCmd())
+
+	return cmd
+}
+
+func newFleetStatusCmd() *cobra.Command {
+	var hostsPath string
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show which hosts are in sync with the Clewfile",
+		Long: `Status checks every host in the hosts file against the Clewfile and prints
+a consolidated table, followed by what drifted on each host. Hosts are
+checked in parallel; a host that cannot be reached is reported, not fatal.`,
+		Example: `  clew fleet status --hosts hosts.yaml
+  clew fleet status --hosts hosts.yaml --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFleetStatus(hostsPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&hostsPath, "hosts", "hosts.yaml", "Hosts file listing the machines to check")
+
+	return cmd
+}
+
+// runFleetStatus checks every host and prints the consolidated status.
+func runFleetStatus(hostsPath string) error {
+	clewfilePath, err := config.FindClewfile(configPath)
+	if err != nil {
+		return err
+	}
+	clewfile, err := config.Load(clewfilePath)
+	if err != nil {
+		return err
+	}
+	inventory, err := fleet.LoadInventory(hostsPath)
+	if err != nil {
+		return err
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	statuses := fleet.Check(clewfile, inventory.Hosts, fleet.DefaultRunner)
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		return writer.Write(statuses)
+	}
+	return printFleetStatus(os.Stdout, statuses)
+}
+
+// printFleetStatus prints one row per host followed by each host's drift.
+func printFleetStatus(out io.Writer, statuses []fleet.Status) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "HOST\tSOURCE\tSTATUS")
+	inSync := 0
+	for _, s := range statuses {
+		status := "in sync"
+		switch {
+		case s.Error != "":
+			status = "unreachable"
+		case !s.InSync:
+			status = strconv.Itoa(len(s.Drift)) + " drifted"
+		default:
+			inSync++
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", s.Host, s.Source, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, s := range statuses {
+		if s.Error != "" {
+			_, _ = fmt.Fprintf(out, "\n%s:\n  ! %s\n", s.Host, s.Error)
+			continue
+		}
+		if s.InSync {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "\n%s:\n", s.Host)
+		for _, d := range s.Drift {
+			_, _ = fmt.Fprintf(out, "  %s %s %s (%s)\n", driftSymbol(d.Action), d.Type, d.Name, driftDescription(d.Action))
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "\n%s\n", i18n.N(i18n.FleetInSync, len(statuses), inSync))
+	return nil
+}
+
+// driftSymbol returns the diff symbol for a drift action.
+func driftSymbol(action diff.Action) string {
+	switch action {
+	case diff.ActionAdd:
+		return "+"
+	case diff.ActionRemove:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// driftDescription says how a host differs from the Clewfile.
+func driftDescription(action diff.Action) string {
+	switch action {
+	case diff.ActionAdd:
+		return "missing"
+	case diff.ActionRemove:
+		return "not in Clewfile"
+	case diff.ActionEnable:
+		return "should be enabled"
+	case diff.ActionDisable:
+		return "should be disabled"
+	case diff.ActionUpdate:
+		return "differs from Clewfile"
+	default:
+		return string(action)
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/fleet"
+)
+
+func TestPrintFleetStatus(t *testing.T) {
+	statuses := []fleet.Status{
+		{Host: "laptop", Source: "ssh me@laptop", InSync: true},
+		{Host: "desk", Source: "desk.json", Drift: []fleet.Drift{
+			{Type: "plugin", Name: "context7@official", Action: diff.ActionAdd},
+			{Type: "plugin", Name: "linter@official", Action: diff.ActionDisable},
+		}},
+		{Host: "down", Source: "ssh me@down", Error: "Connection refused"},
+	}
+
+	var buf bytes.Buffer
+	if err := printFleetStatus(&buf, statuses); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"laptop  ssh me@laptop  in sync",
+		"desk    desk.json      2 drifted",
+		"down    ssh me@down    unreachable",
+		"desk:\n  + plugin context7@official (missing)\n  ~ plugin linter@official (should be disabled)",
+		"down:\n  ! Connection refused",
+		"1 of 3 hosts in sync",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/journal"
+	"github.com/adamancini/clew/internal/output"
+)
+
+func newHistoryCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List past sync and apply runs",
+		Long: `History lists every sync and apply run clew has executed, newest first,
+with its outcome, change counts, and the backup taken beforehand. Start times
+are shown relative to now ("2 days ago"); use -v for exact times.
+
+Runs are stored in ~/.local/state/clew/history/. Use 'clew history show <run-id>'
+to see every operation a run performed, and 'clew backup restore <backup-id>'
+to undo it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryList(limit)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of runs to list (0 for all)")
+
+	cmd.AddCommand(newHistoryShowCmd())
+
+	return cmd
+}
+
+func newHistoryShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <run-id>",
+		Short: "Show the operations of a past run",
+		Long: `Show prints the full operation list of a recorded run.
+
+Use 'latest' as the ID to show the most recent run.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryShow(args[0])
+		},
+	}
+}
+
+// runHistoryList lists recorded runs.
+func runHistoryList(limit int) error {
+	j, err := journal.New()
+	if err != nil {
+		return err
+	}
+
+	runs, err := j.List()
+	if err != nil {
+		return err
+	}
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		return writer.Write(runs)
+	}
+
+	if len(runs) == 0 {
+		fmt.Println("No runs recorded.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tStarted\tCommand\tOutcome\tInstalled\tUpdated\tFailed\tBackup")
+	for _, r := range runs {
+		backupID := r.BackupID
+		if backupID == "" {
+			backupID = "-"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%s\n",
+			r.ID,
+			output.Timestamp(r.StartedAt, verbose),
+			r.Command,
+			r.Outcome,
+			r.Installed,
+			r.Updated,
+			r.Failed,
+			backupID,
+		)
+	}
+	return w.Flush()
+}
+
+// runHistoryShow prints a single run with its operations.
+func runHistoryShow(id string) error {
+	j, err := journal.New()
+	if err != nil {
+		return err
+	}
+
+	run, err := j.Get(id)
+	if err != nil {
+		return err
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		return writer.Write(run)
+	}
+
+	printHistoryRun(run)
+	return nil
+}
+
+// printHistoryRun prints a run in human-readable format.
+func printHistoryRun(r *journal.Run) {
+	fmt.Printf("Run:      %s (%s)\n", r.ID, r.Command)
+	fmt.Printf("Started:  %s (took %s)\n", r.StartedAt.Local().Format("2006-01-02 15:04:05"), r.Duration)
+	fmt.Printf("Outcome:  %s\n", r.Outcome)
+	if r.Clewfile != "" {
+		fmt.Printf("Clewfile: %s\n", r.Clewfile)
+	}
+	if r.BackupID != "" {
+		fmt.Printf("Backup:   %s\n", r.BackupID)
+	}
+	fmt.Printf("Summary:  %d installed, %d updated, %d skipped, %d failed\n",
+		r.Installed, r.Updated, r.Skipped, r.Failed)
+
+	if len(r.Operations) > 0 {
+		fmt.Println("\nOperations:")
+		for _, op := range r.Operations {
+			outcome := "OK"
+			switch {
+			case op.Skipped:
+				outcome = "SKIPPED"
+			case !op.Success:
+				outcome = "FAILED"
+			}
+			fmt.Printf("  %d. %s: %s [%s]\n", op.ID, capitalizeAction(op.Action), op.Description, outcome)
+			if op.Command != "" {
+				fmt.Printf("    -> %s\n", op.Command)
+			}
+			if op.Error != "" {
+				fmt.Printf("    error: %s\n", op.Error)
+			}
+		}
+	}
+
+	if len(r.Attention) > 0 {
+		fmt.Println("\nNeeded attention:")
+		for _, a := range r.Attention {
+			fmt.Printf("  ! %s\n", a)
+		}
+	}
+}
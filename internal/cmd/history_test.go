@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamancini/clew/internal/journal"
+	"github.com/adamancini/clew/internal/sync"
+)
+
+func TestHistoryListAndShow(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(t.TempDir(), "cache"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(t.TempDir(), "state"))
+
+	oldFormat := outputFormat
+	outputFormat = "text"
+	defer func() { outputFormat = oldFormat }()
+
+	out := captureStdout(t, func() {
+		if err := runHistoryList(0); err != nil {
+			t.Errorf("runHistoryList() error = %v", err)
+		}
+	})
+	if !strings.Contains(out, "No runs recorded") {
+		t.Errorf("empty history output = %q", out)
+	}
+
+	j, err := journal.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := journal.NewRun("sync", time.Now(), &sync.Result{
+		Installed: 1,
+		Failed:    1,
+		Operations: []sync.Operation{
+			{ID: 1, Type: "plugin", Name: "good@m", Action: "add", Description: "plugin good@m", Success: true},
+			{ID: 2, Type: "plugin", Name: "bad@m", Action: "add", Description: "plugin bad@m", Error: "install failed"},
+		},
+	})
+	run.BackupID = "2025-01-01-120000"
+	if err := j.Record(run); err != nil {
+		t.Fatal(err)
+	}
+
+	out = captureStdout(t, func() {
+		if err := runHistoryList(0); err != nil {
+			t.Errorf("runHistoryList() error = %v", err)
+		}
+	})
+	for _, want := range []string{run.ID, "partial", run.BackupID} {
+		if !strings.Contains(out, want) {
+			t.Errorf("history list missing %q:\n%s", want, out)
+		}
+	}
+
+	out = captureStdout(t, func() {
+		if err := runHistoryShow("latest"); err != nil {
+			t.Errorf("runHistoryShow() error = %v", err)
+		}
+	})
+	for _, want := range []string{"1. Add: plugin good@m [OK]", "2. Add: plugin bad@m [FAILED]", "install failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("history show missing %q:\n%s", want, out)
+		}
+	}
+
+	if err := runHistoryShow("does-not-exist"); err == nil {
+		t.Error("runHistoryShow() for unknown run should fail")
+	}
+}
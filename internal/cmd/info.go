@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/catalog"
+	"github.com/adamancini/clew/internal/github"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// MarketplaceInfo describes an installed marketplace.
+type MarketplaceInfo struct {
+	Alias           string `json:"alias" yaml:"alias"`
+	Repo            string `json:"repo,omitempty" yaml:"repo,omitempty"`
+	InstallLocation string `json:"install_location,omitempty" yaml:"install_location,omitempty"`
+	LastUpdated     string `json:"last_updated,omitempty" yaml:"last_updated,omitempty"`
+	Plugins         int    `json:"plugins" yaml:"plugins"` // Plugins listed in its index
+
+	Repository *github.Repository `json:"repository,omitempty" yaml:"repository,omitempty"`
+}
+
+// InfoResult is the output of clew info: a marketplace, or every plugin
+// matching the name.
+type InfoResult struct {
+	Marketplace *MarketplaceInfo `json:"marketplace,omitempty" yaml:"marketplace,omitempty"`
+	Plugins     []PluginInfo     `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+}
+
+func newInfoCmd() *cobra.Command {
+	var noMetadata bool
+
+	cmd := &cobra.Command{
+		Use:   "info <plugin|marketplace>",
+		Short: "Show details about a plugin or marketplace",
+		Long: `Info shows what a marketplace lists about a plugin (description, version,
+category), whether it is installed, and repository metadata from GitHub
+(stars, description, last push, archived) to help judge whether it is
+maintained. Given a marketplace alias, it describes the marketplace.
+
+A bare plugin name shows every marketplace that lists it; use
+plugin@marketplace to pick one.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInfo(args[0], noMetadata)
+		},
+	}
+
+	cmd.Flags().BoolVar(&noMetadata, "no-metadata", false, "Skip fetching repository metadata from GitHub")
+
+	return cmd
+}
+
+// runInfo looks up a plugin or marketplace.
+func runInfo(name string, noMetadata bool) error {
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	result, err := lookupInfo(name, currentState)
+	if err != nil {
+		return err
+	}
+
+	if !noMetadata {
+		f := newMetadataFetcher()
+		if m := result.Marketplace; m != nil {
+			m.Repository = f.fetch(catalog.GitHubRepo(m.Repo))
+		}
+		attachRepositories(f, result.Plugins)
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		return writer.Write(result)
+	}
+
+	if result.Marketplace != nil {
+		printMarketplaceInfo(result.Marketplace)
+	}
+	for i, p := range result.Plugins {
+		if i > 0 || result.Marketplace != nil {
+			fmt.Println()
+		}
+		printPluginInfo(p)
+	}
+	return nil
+}
+
+// lookupInfo resolves name to a marketplace and/or plugins.
+func lookupInfo(name string, currentState *state.State) (*InfoResult, error) {
+	result := &InfoResult{}
+
+	if m, ok := currentState.Marketplaces[name]; ok {
+		listed, _ := catalog.LoadIndex(name, m)
+		result.Marketplace = &MarketplaceInfo{
+			Alias:           name,
+			Repo:            m.Repo,
+			InstallLocation: m.InstallLocation,
+			LastUpdated:     m.LastUpdated,
+			Plugins:         len(listed),
+		}
+	}
+
+	for _, e := range catalog.Find(catalog.Load(currentState.Marketplaces), name) {
+		result.Plugins = append(result.Plugins, newPluginInfo(e, currentState))
+	}
+
+	// Installed plugins missing from every index (e.g. local plugins)
+	if len(result.Plugins) == 0 {
+		if p, ok := currentState.Plugins[name]; ok {
+			result.Plugins = append(result.Plugins, PluginInfo{
+				Entry:            catalog.Entry{Name: p.Name, Marketplace: p.Marketplace},
+				Installed:        true,
+				Enabled:          p.Enabled,
+				InstalledVersion: p.Version,
+				Scope:            p.Scope,
+			})
+		}
+	}
+
+	if result.Marketplace == nil && len(result.Plugins) == 0 {
+		return nil, fmt.Errorf("no plugin or marketplace named %q (try 'clew search %s')", name, name)
+	}
+	return result, nil
+}
+
+// printMarketplaceInfo prints a marketplace in human-readable format.
+func printMarketplaceInfo(m *MarketplaceInfo) {
+	fmt.Printf("Marketplace: %s\n", m.Alias)
+	if m.Repo != "" {
+		fmt.Printf("  Repo:         %s\n", m.Repo)
+	}
+	if m.InstallLocation != "" {
+		fmt.Printf("  Location:     %s\n", m.InstallLocation)
+	}
+	if m.LastUpdated != "" {
+		fmt.Printf("  Last updated: %s\n", m.LastUpdated)
+	}
+	fmt.Printf("  Plugins:      %d\n", m.Plugins)
+	printRepositoryInfo(m.Repository)
+}
+
+// printPluginInfo prints a plugin in human-readable format.
+func printPluginInfo(p PluginInfo) {
+	fmt.Printf("Plugin: %s\n", p.FullName())
+	if p.Description != "" {
+		fmt.Printf("  Description:  %s\n", p.Description)
+	}
+	if p.Version != "" {
+		fmt.Printf("  Version:      %s\n", p.Version)
+	}
+	if p.Category != "" {
+		fmt.Printf("  Category:     %s\n", p.Category)
+	}
+	if p.Installed {
+		enabled := "enabled"
+		if !p.Enabled {
+			enabled = "disabled"
+		}
+		fmt.Printf("  Installed:    %s (%s, %s scope)\n", p.InstalledVersion, enabled, p.Scope)
+	} else {
+		fmt.Println("  Installed:    no")
+	}
+	if p.Repo != "" {
+		fmt.Printf("  Repo:         %s\n", p.Repo)
+	}
+	printRepositoryInfo(p.Repository)
+}
+
+// printRepositoryInfo prints repository metadata, if it was fetched.
+func printRepositoryInfo(r *github.Repository) {
+	if r == nil {
+		return
+	}
+	stars, pushed := repositoryCells(r)
+	fmt.Printf("  Stars:        %s\n", stars)
+	fmt.Printf("  Last push:    %s\n", pushed)
+	if r.Description != "" {
+		fmt.Printf("  About:        %s\n", r.Description)
+	}
+}
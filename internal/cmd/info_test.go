@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/catalog"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func writeTestMarketplaceIndex(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, catalog.IndexFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLookupInfo(t *testing.T) {
+	current := &state.State{
+		Marketplaces: map[string]state.MarketplaceState{
+			"official": {
+				Alias:           "official",
+				Repo:            "owner/plugins",
+				InstallLocation: writeTestMarketplaceIndex(t, `{"plugins": [{"name": "review", "description": "Reviews code"}, {"name": "lint"}]}`),
+			},
+		},
+		Plugins: map[string]state.PluginState{
+			"review@official": {Name: "review", Marketplace: "official", Version: "1.2.0", Enabled: true, Scope: "user"},
+			"mine@local":      {Name: "mine", Marketplace: "local", Version: "0.1.0", Scope: "user"},
+		},
+	}
+
+	result, err := lookupInfo("official", current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Marketplace == nil || result.Marketplace.Plugins != 2 {
+		t.Errorf("marketplace info = %+v", result.Marketplace)
+	}
+
+	result, err = lookupInfo("review", current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Plugins) != 1 {
+		t.Fatalf("plugins = %+v", result.Plugins)
+	}
+	p := result.Plugins[0]
+	if !p.Installed || p.InstalledVersion != "1.2.0" || p.Description != "Reviews code" || p.Repo != "owner/plugins" {
+		t.Errorf("review info = %+v", p)
+	}
+
+	// Installed but not listed in any index
+	result, err = lookupInfo("mine@local", current)
+	if err != nil || len(result.Plugins) != 1 || !result.Plugins[0].Installed {
+		t.Errorf("lookupInfo(mine@local) = %+v, %v", result, err)
+	}
+
+	if _, err := lookupInfo("unknown", current); err == nil || !strings.Contains(err.Error(), "clew search") {
+		t.Errorf("lookupInfo(unknown) error = %v", err)
+	}
+}
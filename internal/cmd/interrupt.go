@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// interruptContext returns a context that is cancelled by the first Ctrl-C
+// or SIGTERM. That stops the running claude or git command (and its
+// children) and lets the command skip what is left and record the run. A
+// second Ctrl-C quits immediately. Call stop when done.
+func interruptContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			fmt.Fprintln(os.Stderr, "\nInterrupted: stopping the current command (Ctrl-C again to quit now)")
+			cancel()
+		case <-ctx.Done():
+		}
+		// Restore default handling so the next signal ends the process
+		signal.Stop(signals)
+	}()
+	return ctx, cancel
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/lint"
+	"github.com/adamancini/clew/internal/output"
+)
+
+func newLintCmd() *cobra.Command {
+	var listRules bool
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check the Clewfile against best practices",
+		Long: `Lint reports Clewfile entries that are valid but likely to cause trouble:
+unpinned marketplaces, credentials written inline, local paths that only
+exist on this machine, and marketplaces no plugin uses. It exits non-zero
+when it finds anything, so it can run in CI.
+
+Rules can be disabled in the Clewfile:
+
+  lint:
+    disable:
+      - unpinned-marketplace
+
+Use --list-rules to see every rule.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if listRules {
+				return printLintRules()
+			}
+			return runLint()
+		},
+	}
+
+	cmd.Flags().BoolVar(&listRules, "list-rules", false, "List the available rules")
+
+	return cmd
+}
+
+// runLint lints the Clewfile and fails if there are findings.
+func runLint() error {
+	clewfilePath, err := config.FindClewfile(configPath)
+	if err != nil {
+		return err
+	}
+	resolved, err := config.Resolve(clewfilePath)
+	if err != nil {
+		return err
+	}
+	findings, err := lint.Run(resolved)
+	if err != nil {
+		return err
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		if err := writer.Write(findings); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			location := filepath.Base(f.Location.File)
+			if f.Location.Line > 0 {
+				location += ":" + strconv.Itoa(f.Location.Line)
+			}
+			fmt.Printf("%s: %s: %s (%s)\n", location, f.Field, f.Message, f.Rule)
+		}
+	}
+
+	if len(findings) > 0 {
+		return errors.New(i18n.N(i18n.LintProblems, len(findings)))
+	}
+	if format == output.FormatText {
+		fmt.Println("No problems found.")
+	}
+	return nil
+}
+
+// printLintRules lists every lint rule.
+func printLintRules() error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "RULE\tCHECKS FOR")
+	for _, r := range lint.Rules {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", r.Name, r.Description)
+	}
+	return w.Flush()
+}
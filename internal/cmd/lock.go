@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/adamancini/clew/internal/lock"
+)
+
+// acquireLock takes the cross-process lock on the Claude plugins directory
+// for a mutating command. With wait set, it reports the current holder and
+// blocks until the lock is free.
+func acquireLock(command string, wait bool) (*lock.Lock, error) {
+	path, err := lock.DefaultPath("")
+	if err != nil {
+		return nil, err
+	}
+
+	return lock.Acquire(path, command, wait, func(h *lock.Holder) {
+		if h != nil {
+			fmt.Fprintf(os.Stderr, "Waiting for lock held by %s...\n", h)
+		} else {
+			fmt.Fprintln(os.Stderr, "Waiting for lock held by another clew process...")
+		}
+	})
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/mcp"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func newMCPCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Inspect the MCP servers Claude Code is configured with",
+		Long: `MCP works with the MCP servers declared in ~/.claude.json (user and
+per-project servers) and in the current project's .mcp.json.`,
+	}
+
+	cmd.AddCommand(newMCPTestCmd())
+	cmd.AddCommand(newMCPDoctorCmd())
+
+	return cmd
+}
+
+func newMCPTestCmd() *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "test <name>",
+		Short: "Start a stdio MCP server and print its capabilities",
+		Long: `Test launches a stdio MCP server the way Claude Code would, with its
+configured command, arguments and environment, sends an MCP initialize
+request and prints the server's reported name, protocol version and
+capabilities. The server is stopped as soon as it answers.
+
+Use it to check that a server starts before Claude ever launches it. If the
+server fails, anything it printed on stderr is included in the error.
+
+When a name is declared more than once, the declaration Claude uses is
+tested: local (per-project in ~/.claude.json), then .mcp.json, then user.
+
+For http and sse servers, use 'clew status --check-remote'.`,
+		Example: `  clew mcp test github
+  clew mcp test filesystem --timeout 2m
+  clew mcp test github -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMCPTest(args[0], timeout)
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", mcp.DefaultStartTimeout, "How long to wait for the server to answer")
+
+	return cmd
+}
+
+func runMCPTest(name string, timeout time.Duration) error {
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	configDir, err := state.GlobalConfigDir()
+	if err != nil {
+		return err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	servers, err := mcp.Declared(configDir, dir)
+	if err != nil {
+		return err
+	}
+	server, ok := mcp.Find(servers, name)
+	if !ok {
+		return fmt.Errorf("no MCP server named %q in %s or %s", name, "~/"+mcp.UserConfigFile, mcp.ProjectFile)
+	}
+	if server.Remote() {
+		return fmt.Errorf("%s is an %s server; use 'clew status --check-remote' to check it", name, server.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if format == output.FormatText && !quiet {
+		fmt.Fprintf(os.Stderr, "Starting %s (%s scope)...\n", name, server.Scope)
+	}
+	result, err := mcp.Initialize(ctx, server, clewVersion)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	if format != output.FormatText {
+		return output.NewWriter(os.Stdout, format).Write(result)
+	}
+	printMCPTestResult(os.Stdout, name, result)
+	return nil
+}
+
+// printMCPTestResult prints the initialize result as text.
+func printMCPTestResult(out io.Writer, name string, result *mcp.InitializeResult) {
+	serverName := result.ServerInfo.Name
+	if result.ServerInfo.Version != "" {
+		serverName += " " + result.ServerInfo.Version
+	}
+	fmt.Fprintf(out, "%s is working\n", name)
+	fmt.Fprintf(out, "  Server:    %s\n", serverName)
+	fmt.Fprintf(out, "  Protocol:  %s\n", result.ProtocolVersion)
+
+	if len(result.Capabilities) == 0 {
+		fmt.Fprintln(out, "  Capabilities: none")
+	} else {
+		fmt.Fprintln(out, "  Capabilities:")
+		for _, capability := range sortedKeys(result.Capabilities) {
+			// Flags such as listChanged and subscribe are shown when set
+			var flags []string
+			for _, key := range sortedKeys(result.Capabilities[capability]) {
+				if on, _ := result.Capabilities[capability][key].(bool); on {
+					flags = append(flags, key)
+				}
+			}
+			if len(flags) > 0 {
+				fmt.Fprintf(out, "    %s (%s)\n", capability, strings.Join(flags, ", "))
+			} else {
+				fmt.Fprintf(out, "    %s\n", capability)
+			}
+		}
+	}
+
+	if result.Instructions != "" {
+		fmt.Fprintln(out, "  Instructions:")
+		for _, line := range strings.Split(strings.TrimSpace(result.Instructions), "\n") {
+			fmt.Fprintf(out, "    %s\n", line)
+		}
+	}
+}
+
+func newMCPDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor [name]",
+		Short: "Check MCP server configuration for common mistakes",
+		Long: `Doctor checks each declared MCP server's configuration without starting
+it, and reports per server:
+
+  - ${VAR} references in the command, arguments, URL, environment or
+    headers that are not set (references with a :-default are fine)
+  - a command that is not on PATH, and file arguments (absolute, ./, ../
+    or ~/ paths) that do not exist
+  - a URL that does not parse or is not http or https
+  - environment values and headers that look like placeholders copied from
+    setup instructions, such as YOUR_TOKEN, <api-key> or changeme
+
+Give a name to check only that server's declarations. Environment and header
+values are never printed. Exits non-zero when any server has a problem.`,
+		Example: `  clew mcp doctor
+  clew mcp doctor github
+  clew mcp doctor -o json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+			return runMCPDoctor(name)
+		},
+	}
+
+	return cmd
+}
+
+func runMCPDoctor(name string) error {
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	configDir, err := state.GlobalConfigDir()
+	if err != nil {
+		return err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	servers, err := mcp.Declared(configDir, dir)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		var matched []mcp.Server
+		for _, s := range servers {
+			if s.Name == name {
+				matched = append(matched, s)
+			}
+		}
+		if len(matched) == 0 {
+			return fmt.Errorf("no MCP server named %q in %s or %s", name, "~/"+mcp.UserConfigFile, mcp.ProjectFile)
+		}
+		servers = matched
+	}
+
+	diagnoses := mcp.Diagnose(servers)
+	if format != output.FormatText {
+		if err := output.NewWriter(os.Stdout, format).Write(diagnoses); err != nil {
+			return err
+		}
+	} else {
+		printMCPDiagnoses(os.Stdout, diagnoses)
+	}
+
+	failing := 0
+	for _, d := range diagnoses {
+		if len(d.Findings) > 0 {
+			failing++
+		}
+	}
+	if failing > 0 {
+		return errors.New(i18n.N(i18n.MCPProblems, failing))
+	}
+	return nil
+}
+
+// printMCPDiagnoses prints the doctor's findings as text, one block per server.
+func printMCPDiagnoses(out io.Writer, diagnoses []mcp.Diagnosis) {
+	if len(diagnoses) == 0 {
+		fmt.Fprintln(out, "No MCP servers declared")
+		return
+	}
+	for _, d := range diagnoses {
+		if len(d.Findings) == 0 {
+			if !quiet {
+				fmt.Fprintf(out, "%s (%s): ok\n", d.Name, d.Scope)
+			}
+			continue
+		}
+		fmt.Fprintf(out, "%s (%s, %s):\n", d.Name, d.Scope, d.Source)
+		for _, f := range d.Findings {
+			fmt.Fprintf(out, "  %s: %s\n", f.Field, f.Problem)
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/adamancini/clew/internal/mcp"
+)
+
+func TestPrintMCPTestResult(t *testing.T) {
+	result := &mcp.InitializeResult{
+		ProtocolVersion: "2025-06-18",
+		ServerInfo:      mcp.ServerInfo{Name: "github-mcp", Version: "0.4.0"},
+		Capabilities: map[string]map[string]any{
+			"tools":     {"listChanged": true},
+			"resources": {"subscribe": true, "listChanged": false},
+			"logging":   {},
+		},
+		Instructions: "Use the search tool first.",
+	}
+
+	var buf bytes.Buffer
+	printMCPTestResult(&buf, "github", result)
+
+	want := `github is working
+  Server:    github-mcp 0.4.0
+  Protocol:  2025-06-18
+  Capabilities:
+    logging
+    resources (subscribe)
+    tools (listChanged)
+  Instructions:
+    Use the search tool first.
+`
+	if buf.String() != want {
+		t.Errorf("printMCPTestResult() =\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
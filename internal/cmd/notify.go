@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/github"
+	"github.com/adamancini/clew/internal/update"
+)
+
+// updateNoticeTimeout bounds the daily release check so a slow network
+// never noticeably delays a command.
+const updateNoticeTimeout = 3 * time.Second
+
+// noUpdateNotice lists commands that never show the update notice.
+var noUpdateNotice = map[string]bool{
+	"version":    true, // Reports updates itself
+	"completion": true,
+	"__complete": true,
+}
+
+// printUpdateNotice prints the new-release notice to stderr after a
+// successful command. It stays silent for machine-readable output, --quiet,
+// non-terminals and when the Clewfile sets updates.notify: false.
+func printUpdateNotice(cmd *cobra.Command) {
+	if !shouldNotifyUpdate(cmd) {
+		return
+	}
+
+	cachePath, err := update.DefaultNotifyCachePath()
+	if err != nil {
+		return
+	}
+	checker := update.NewGitHubChecker(clewVersion, "adamancini", "clew").
+		WithToken(github.TokenFromEnv()).
+		WithTimeout(updateNoticeTimeout)
+	writeUpdateNotice(os.Stderr, update.NewNotifier(clewVersion, checker, cachePath))
+}
+
+// shouldNotifyUpdate reports whether the notice may be shown after cmd.
+func shouldNotifyUpdate(cmd *cobra.Command) bool {
+	if quiet || outputFormat != "text" || noUpdateNotice[cmd.Name()] {
+		return false
+	}
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return false
+	}
+
+	if clewfilePath, err := config.FindClewfile(configPath); err == nil {
+		if clewfile, err := config.Load(clewfilePath); err == nil && !clewfile.Updates.NotifyEnabled() {
+			return false
+		}
+	}
+	return true
+}
+
+// writeUpdateNotice writes the notifier's notice, if any, to out.
+func writeUpdateNotice(out io.Writer, n *update.Notifier) {
+	if notice := n.Notice(); notice != "" {
+		_, _ = fmt.Fprintf(out, "\n%s\n", notice)
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/adamancini/clew/internal/update"
+)
+
+// releaseChecker reports a fixed latest release.
+type releaseChecker string
+
+func (c releaseChecker) CheckForUpdate() (*update.UpdateInfo, error) {
+	return &update.UpdateInfo{LatestVersion: string(c)}, nil
+}
+
+func TestWriteUpdateNotice(t *testing.T) {
+	cache := filepath.Join(t.TempDir(), "update-check.json")
+
+	var buf bytes.Buffer
+	writeUpdateNotice(&buf, update.NewNotifier("0.8.2", releaseChecker("v0.9.0"), cache))
+	if want := "\nclew v0.9.0 available, run clew version --update\n"; buf.String() != want {
+		t.Errorf("notice = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	writeUpdateNotice(&buf, update.NewNotifier("0.9.0", releaseChecker("v0.9.0"), filepath.Join(t.TempDir(), "update-check.json")))
+	if buf.Len() != 0 {
+		t.Errorf("notice = %q when up to date, want none", buf.String())
+	}
+}
+
+func TestShouldNotifyUpdateSkipsMachineOutput(t *testing.T) {
+	saved := outputFormat
+	outputFormat = "json"
+	t.Cleanup(func() { outputFormat = saved })
+
+	if shouldNotifyUpdate(newStatusCmd()) {
+		t.Error("shouldNotifyUpdate() = true for JSON output")
+	}
+	outputFormat = "text"
+	if shouldNotifyUpdate(newVersionCmd()) {
+		t.Error("shouldNotifyUpdate() = true for clew version")
+	}
+}
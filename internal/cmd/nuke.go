@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/state"
+	"github.com/adamancini/clew/internal/sync"
+	"github.com/adamancini/clew/internal/xdg"
+)
+
+// nukeConfirmation is the word that must be typed before clew nuke runs.
+const nukeConfirmation = "nuke"
+
+func newNukeCmd() *cobra.Command {
+	var (
+		dryRun bool
+		wait   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "nuke",
+		Short: "Remove everything clew manages from this machine",
+		Long: `Nuke resets the machine to how it was before clew managed it.
+
+It uninstalls the plugins and removes the marketplaces the Clewfile declares
+that are installed, then deletes clew's own files: backups and caches
+under ~/.cache/clew, and run history, snoozes and the last-sync record under
+~/.local/state/clew. Plugins and marketplaces the Clewfile does not declare
+are left alone, as is any marketplace an undeclared plugin still uses. The
+Clewfile itself is not touched.
+
+Backups are deleted too, so 'clew backup restore' cannot undo this. Take a
+copy of ~/.cache/clew/backups first if you may want one. If any uninstall or
+removal fails, clew's files are kept so the backups stay available; fix the
+failure and run nuke again.
+
+Everything to be removed is listed first, and the command only proceeds when
+"nuke" is typed in answer. There is no flag to skip the confirmation.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runNuke(os.Stdin, dryRun, wait)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List what would be removed without removing it")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for another running clew process instead of failing")
+
+	return cmd
+}
+
+// runNuke lists what clew manages, asks for the typed confirmation read
+// from in, and removes it. It holds the clew lock from before reading state,
+// so what it lists is what it removes.
+func runNuke(in io.Reader, dryRun, wait bool) error {
+	l, err := acquireLock("nuke", wait)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = l.Release() }()
+
+	var clewfile *config.Clewfile
+	if clewfilePath, err := config.FindClewfile(configPath); err == nil {
+		if clewfile, err = config.Load(clewfilePath); err != nil {
+			return fmt.Errorf("failed to load Clewfile: %w", err)
+		}
+		fmt.Printf("Clewfile: %s\n", clewfilePath)
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: no Clewfile found (%v); only clew's own files will be removed\n", err)
+	}
+
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	plugins, marketplaces, kept := nukeTargets(clewfile, currentState)
+	dirs, err := clewDirs()
+	if err != nil {
+		return err
+	}
+	dirs = existingDirs(dirs)
+
+	plan := sync.RemovalPlan(plugins, marketplaces)
+	if len(plan) == 0 && len(dirs) == 0 {
+		fmt.Println("Nothing to remove: clew manages nothing on this machine.")
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println("To be removed:")
+	for _, op := range plan {
+		fmt.Printf("  %s\n", op.Description)
+		fmt.Printf("    -> %s\n", op.Command)
+	}
+	for _, dir := range dirs {
+		fmt.Printf("  Delete directory: %s\n", dir)
+	}
+	for _, line := range kept {
+		fmt.Printf("  Kept: %s\n", line)
+	}
+	fmt.Println()
+
+	if dryRun {
+		fmt.Println("Dry run: nothing was removed.")
+		return nil
+	}
+
+	if !confirmNuke(in, os.Stdout) {
+		fmt.Println("Nuke cancelled.")
+		return nil
+	}
+
+	var failed []error
+	if len(plan) > 0 {
+		result, err := sync.NewSyncer().Revert(plan, currentState, sync.Options{Verbose: verbose, Quiet: quiet})
+		if err != nil {
+			return fmt.Errorf("nuke failed: %w", err)
+		}
+		failed = append(failed, result.Errors...)
+	}
+
+	// Backups and history are the only way back while plugins are left
+	// half removed, so keep them until the removals succeed
+	if len(failed) > 0 {
+		fmt.Println(i18n.N(i18n.NukeFailures, len(failed)))
+		for _, e := range failed {
+			fmt.Printf("  - %v\n", e)
+		}
+		for _, dir := range dirs {
+			fmt.Println(i18n.T(i18n.NukeKeptDir, dir))
+		}
+		return fmt.Errorf("nuke completed with errors; clew's files were kept, fix the failures and run clew nuke again")
+	}
+
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			failed = append(failed, fmt.Errorf("failed to delete %s: %w", dir, err))
+		}
+	}
+
+	if len(failed) > 0 {
+		fmt.Println(i18n.N(i18n.NukeFailures, len(failed)))
+		for _, e := range failed {
+			fmt.Printf("  - %v\n", e)
+		}
+		return fmt.Errorf("nuke completed with errors")
+	}
+	fmt.Println(i18n.N(i18n.NukeRemoved, len(plugins), i18n.N(i18n.NukeMarketplaces, len(marketplaces))))
+	for _, dir := range dirs {
+		fmt.Printf("Deleted %s\n", dir)
+	}
+	return nil
+}
+
+// nukeTargets returns the installed plugins and marketplaces the Clewfile
+// declares, sorted. Marketplaces still used by plugins that stay are left
+// out and described in kept instead.
+func nukeTargets(clewfile *config.Clewfile, currentState *state.State) (plugins, marketplaces, kept []string) {
+	if clewfile == nil {
+		return nil, nil, nil
+	}
+	for _, p := range clewfile.Plugins {
+		if _, ok := currentState.Plugins[p.Name]; ok {
+			plugins = append(plugins, p.Name)
+		}
+	}
+	slices.Sort(plugins)
+	plugins = slices.Compact(plugins)
+
+	var declared []string
+	for alias := range clewfile.Marketplaces {
+		if _, ok := currentState.Marketplaces[alias]; ok {
+			declared = append(declared, alias)
+		}
+	}
+	slices.Sort(declared)
+
+	// Only plugins that stay installed keep a marketplace
+	orphans := sync.RemovalOrphans(plugins, declared, nil, currentState)
+	for _, alias := range declared {
+		if users, ok := orphans[alias]; ok {
+			kept = append(kept, fmt.Sprintf("marketplace %s - still used by %s", alias, strings.Join(users, ", ")))
+			continue
+		}
+		marketplaces = append(marketplaces, alias)
+	}
+	return plugins, marketplaces, kept
+}
+
+// clewDirs returns the directories clew keeps its own files in: the cache
+// ($XDG_CACHE_HOME/clew) and state ($XDG_STATE_HOME/clew) directories.
+func clewDirs() ([]string, error) {
+	cacheDir, err := xdg.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	stateDir, err := xdg.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	return []string{cacheDir, stateDir}, nil
+}
+
+// existingDirs returns the directories in dirs that exist.
+func existingDirs(dirs []string) []string {
+	var existing []string
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			existing = append(existing, dir)
+		}
+	}
+	return existing
+}
+
+// confirmNuke asks for the confirmation word on out and reports whether it
+// was typed exactly.
+func confirmNuke(in io.Reader, out io.Writer) bool {
+	_, _ = fmt.Fprintf(out, "This cannot be undone. Type %q to continue: ", nukeConfirmation)
+	response, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && response == "" {
+		_, _ = fmt.Fprintln(out)
+		return false
+	}
+	return strings.TrimSpace(response) == nukeConfirmation
+}
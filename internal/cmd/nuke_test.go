@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestNukeTargets(t *testing.T) {
+	clewfile := &config.Clewfile{
+		Marketplaces: map[string]config.Marketplace{
+			"official": {Repo: "o/official"},
+			"shared":   {Repo: "o/shared"},
+			"missing":  {Repo: "o/missing"},
+		},
+		Plugins: []config.Plugin{{Name: "lint@official"}, {Name: "fmt@shared"}, {Name: "gone@official"}},
+	}
+	current := &state.State{
+		Marketplaces: map[string]state.MarketplaceState{"official": {}, "shared": {}, "mine": {}},
+		Plugins: map[string]state.PluginState{
+			"lint@official": {Marketplace: "official"},
+			"fmt@shared":    {Marketplace: "shared"},
+			"own@shared":    {Marketplace: "shared"}, // not in the Clewfile
+		},
+	}
+
+	plugins, marketplaces, kept := nukeTargets(clewfile, current)
+	if want := []string{"fmt@shared", "lint@official"}; !reflect.DeepEqual(plugins, want) {
+		t.Errorf("plugins = %v, want %v", plugins, want)
+	}
+	if want := []string{"official"}; !reflect.DeepEqual(marketplaces, want) {
+		t.Errorf("marketplaces = %v, want %v", marketplaces, want)
+	}
+	if len(kept) != 1 || !strings.Contains(kept[0], "shared - still used by own@shared") {
+		t.Errorf("kept = %v, want shared kept for own@shared", kept)
+	}
+
+	if plugins, marketplaces, _ := nukeTargets(nil, current); plugins != nil || marketplaces != nil {
+		t.Errorf("without a Clewfile: %v, %v; want nothing", plugins, marketplaces)
+	}
+}
+
+func TestConfirmNuke(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"nuke\n", true},
+		{"  nuke  \n", true},
+		{"nuke", true},
+		{"y\n", false},
+		{"NUKE\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := confirmNuke(strings.NewReader(tt.input), io.Discard); got != tt.want {
+			t.Errorf("confirmNuke(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRunNukeKeepsFilesWhenRemovalFails(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	t.Setenv("CLEWFILE", "")
+
+	// Every claude command fails
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte("#!/bin/sh\necho uninstall failed >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	claudeDir := filepath.Join(home, ".claude")
+	installed := `{"version": 2, "plugins": {"lint@official": [{"scope": "user", "version": "1.0.0"}]}}`
+	for path, data := range map[string]string{
+		filepath.Join(claudeDir, "plugins", "installed_plugins.json"): installed,
+		filepath.Join(claudeDir, "Clewfile.yaml"):                     "version: 1\nmarketplaces:\n  official:\n    repo: o/official\nplugins:\n  - lint@official\n",
+		filepath.Join(home, ".cache", "clew", "backups", "b1.json"):   "{}",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	savedConfig, savedQuiet := configPath, quiet
+	configPath, quiet = "", true
+	t.Cleanup(func() { configPath, quiet = savedConfig, savedQuiet })
+
+	err := runNuke(strings.NewReader("nuke\n"), false, false)
+	if err == nil || !strings.Contains(err.Error(), "kept") {
+		t.Fatalf("runNuke() error = %v, want the failure reported", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".cache", "clew", "backups", "b1.json")); err != nil {
+		t.Errorf("backup deleted after a failed removal: %v", err)
+	}
+}
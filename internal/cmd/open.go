@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/catalog"
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// openURL opens url with the platform's default handler.
+var openURL = func(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+func newOpenCmd() *cobra.Command {
+	var printOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "open <plugin|marketplace>",
+		Short: "Open a plugin's or marketplace's source in a browser",
+		Long: `Open looks up the repository of a plugin or marketplace, from installed
+marketplaces or your Clewfile, and opens it in the default browser so you
+can review it before enabling it. Use --print to print the URL instead.
+
+A bare plugin name must match a single marketplace; use plugin@marketplace
+to pick one.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runOpen(args[0], printOnly)
+		},
+	}
+
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the URL instead of opening it")
+
+	return cmd
+}
+
+// runOpen resolves name to a URL and opens or prints it.
+func runOpen(name string, printOnly bool) error {
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	// The Clewfile is optional; it covers marketplaces not installed yet
+	var clewfile *config.Clewfile
+	if path, err := config.FindClewfile(configPath); err == nil {
+		if clewfile, err = config.Load(path); err != nil {
+			return fmt.Errorf("failed to load Clewfile: %w", err)
+		}
+	}
+
+	url, err := resolveSourceURL(name, currentState, clewfile)
+	if err != nil {
+		return err
+	}
+
+	if printOnly {
+		fmt.Println(url)
+		return nil
+	}
+	if err := openURL(url); err != nil {
+		return fmt.Errorf("failed to open %s: %w", url, err)
+	}
+	fmt.Printf("Opened %s\n", url)
+	return nil
+}
+
+// resolveSourceURL returns the browser URL of a marketplace or plugin
+// repository.
+func resolveSourceURL(name string, currentState *state.State, clewfile *config.Clewfile) (string, error) {
+	// marketplaceRepo returns a marketplace's repository from state or the Clewfile.
+	marketplaceRepo := func(alias string) string {
+		if m, ok := currentState.Marketplaces[alias]; ok && m.Repo != "" {
+			return m.Repo
+		}
+		if clewfile != nil {
+			if m, ok := clewfile.Marketplaces[alias]; ok {
+				return m.Repo
+			}
+		}
+		return ""
+	}
+
+	repo := ""
+	if !strings.Contains(name, "@") {
+		repo = marketplaceRepo(name)
+	}
+
+	if repo == "" {
+		entries := catalog.Find(catalog.Load(currentState.Marketplaces), name)
+		switch {
+		case len(entries) == 1:
+			repo = entries[0].Repo
+		case len(entries) > 1:
+			names := make([]string, 0, len(entries))
+			for _, e := range entries {
+				names = append(names, e.FullName())
+			}
+			return "", fmt.Errorf("%q is listed by several marketplaces: %s", name, strings.Join(names, ", "))
+		}
+	}
+
+	// Plugins missing from every index fall back to their marketplace
+	if repo == "" {
+		if fullName, err := resolveInstalledPlugin(name, currentState); err == nil {
+			repo = marketplaceRepo(currentState.Plugins[fullName].Marketplace)
+		} else if _, alias, ok := strings.Cut(name, "@"); ok {
+			repo = marketplaceRepo(alias)
+		}
+	}
+
+	if repo == "" {
+		return "", fmt.Errorf("no repository known for %q", name)
+	}
+	url := browseURL(repo)
+	if url == "" {
+		return "", fmt.Errorf("cannot open %q: %s is not a web-hosted repository", name, repo)
+	}
+	return url, nil
+}
+
+// browseURL converts a repository reference ("owner/repo", an HTTPS or SSH
+// clone URL) to a URL a browser can open, or "" if it has none.
+func browseURL(repo string) string {
+	repo = strings.TrimSuffix(strings.TrimSpace(repo), ".git")
+	switch {
+	case strings.HasPrefix(repo, "https://"), strings.HasPrefix(repo, "http://"):
+		return repo
+	case strings.HasPrefix(repo, "git@"):
+		// git@host:owner/repo
+		host, path, ok := strings.Cut(strings.TrimPrefix(repo, "git@"), ":")
+		if !ok {
+			return ""
+		}
+		return "https://" + host + "/" + path
+	}
+	if r := catalog.GitHubRepo(repo); r != "" {
+		return "https://github.com/" + r
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestBrowseURL(t *testing.T) {
+	tests := map[string]string{
+		"owner/repo":                          "https://github.com/owner/repo",
+		"https://github.com/owner/repo.git":   "https://github.com/owner/repo",
+		"https://gitlab.com/team/plugins.git": "https://gitlab.com/team/plugins",
+		"git@gitlab.com:team/plugins.git":     "https://gitlab.com/team/plugins",
+		"/local/path":                         "",
+	}
+	for in, want := range tests {
+		if got := browseURL(in); got != want {
+			t.Errorf("browseURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestResolveSourceURL(t *testing.T) {
+	current := &state.State{
+		Marketplaces: map[string]state.MarketplaceState{
+			"official": {
+				Alias:           "official",
+				Repo:            "owner/plugins",
+				InstallLocation: writeTestMarketplaceIndex(t, `{"plugins": [{"name": "review"}, {"name": "go-tools", "source": {"source": "github", "repo": "someone/go-tools"}}]}`),
+			},
+		},
+		Plugins: map[string]state.PluginState{
+			"unlisted@official": {Name: "unlisted", Marketplace: "official"},
+		},
+	}
+	clewfile := &config.Clewfile{
+		Marketplaces: map[string]config.Marketplace{
+			"company": {Repo: "https://gitlab.com/company/plugins.git"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"official", "https://github.com/owner/plugins"},
+		{"company", "https://gitlab.com/company/plugins"}, // Only in the Clewfile
+		{"go-tools", "https://github.com/someone/go-tools"},
+		{"review@official", "https://github.com/owner/plugins"},
+		{"unlisted", "https://github.com/owner/plugins"},
+		{"tool@company", "https://gitlab.com/company/plugins"},
+	}
+	for _, tt := range tests {
+		got, err := resolveSourceURL(tt.name, current, clewfile)
+		if err != nil || got != tt.want {
+			t.Errorf("resolveSourceURL(%q) = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := resolveSourceURL("nothing", current, nil); err == nil {
+		t.Error("unknown name should fail")
+	}
+}
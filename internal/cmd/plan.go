@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/estimate"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/plan"
+	"github.com/adamancini/clew/internal/sync"
+)
+
+func newPlanCmd() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Compute and optionally save a sync plan",
+		Long: `Plan computes what sync would do and, with --out, saves it as a plan file.
+
+The plan file records the diff together with fingerprints of the current
+system state and Clewfile. 'clew apply <planfile>' executes exactly that diff
+and refuses to run if the system changed in between, enabling a
+review-then-apply workflow.
+
+Examples:
+  clew plan --out plan.json
+  clew apply plan.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlan(out)
+		},
+	}
+
+	cmd.Flags().StringVar(&out, "out", "", "Write the plan to this file")
+
+	return cmd
+}
+
+func newApplyCmd() *cobra.Command {
+	var (
+		strict         bool
+		doBackup       bool
+		noBackup       bool
+		short          bool
+		wait           bool
+		settingsTarget string
+		directSettings bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "apply <planfile>",
+		Short: "Apply a saved plan",
+		Long: `Apply executes a plan file created by 'clew plan --out'.
+
+Apply refuses to run if the system state changed since the plan was created.
+A backup is created first unless --no-backup is given or the Clewfile sets
+backups.auto: false (--backup overrides that for one run).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := sync.ParseSettingsTarget(settingsTarget)
+			if err != nil {
+				return err
+			}
+			return runApply(args[0], SyncOptions{
+				Strict:       strict,
+				CreateBackup: doBackup || !noBackup,
+				ForceBackup:  doBackup,
+				Short:        short,
+				Wait:         wait,
+				OutputFormat: outputFormat,
+				Verbose:      verbose,
+				Quiet:        quiet,
+
+				SettingsTarget: target,
+				DirectSettings: directSettings,
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&strict, "strict", false, "Exit non-zero on any failure")
+	cmd.Flags().BoolVar(&doBackup, "backup", false, "Create backup before apply, even when the Clewfile sets backups.auto: false")
+	cmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup before apply")
+	cmd.Flags().BoolVar(&short, "short", false, "One-line per item output format")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for another running clew process instead of failing")
+	cmd.Flags().StringVar(&settingsTarget, "settings-target", string(sync.SettingsTargetAuto), "Settings file for enable/disable changes: auto, settings, local")
+	cmd.Flags().BoolVar(&directSettings, "direct-settings", false, "Write enable/disable changes in one settings file edit instead of one claude command each")
+
+	return cmd
+}
+
+// runPlan computes the diff and saves or prints it.
+func runPlan(out string) error {
+	service := NewSyncService(configPath, clewVersion)
+
+	clewfile, clewfilePath, err := service.LoadConfiguration()
+	if err != nil {
+		return err
+	}
+	logging.Decisionf("Using Clewfile: %s", clewfilePath)
+
+	currentState, err := service.ReadCurrentState()
+	if err != nil {
+		return err
+	}
+
+	// A saved plan holds the desired marketplace repos in plain text
+	if out != "" && clewfile.Encrypted {
+		return fmt.Errorf("%s is encrypted with SOPS; clew does not save plans of encrypted Clewfiles, which would store their decrypted values. Run clew sync instead", clewfilePath)
+	}
+
+	diffResult := service.ComputeDiff(clewfile, currentState)
+
+	p, err := plan.New(diffResult, currentState, clewfilePath, clewVersion)
+	if err != nil {
+		return err
+	}
+	var total time.Duration
+	p.Estimate, total = loadTimings().Plan(diffResult)
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	if format == output.FormatText {
+		printDiffResultText(os.Stdout, diffResult, colorOutput(false))
+		printEstimate(os.Stdout, p.Estimate, total)
+	} else if out == "" {
+		return service.FormatOutput(format, p)
+	}
+
+	if out == "" {
+		return nil
+	}
+
+	if err := p.Save(out); err != nil {
+		return err
+	}
+	if !quiet {
+		fmt.Printf("\nPlan saved to %s. Apply it with: clew apply %s\n", out, out)
+	}
+	return nil
+}
+
+// runApply verifies a saved plan against the current state and executes it.
+func runApply(planPath string, opts SyncOptions) error {
+	startedAt := time.Now()
+	p, err := plan.Load(planPath)
+	if err != nil {
+		return err
+	}
+
+	service := NewSyncService(configPath, clewVersion)
+
+	currentState, err := service.ReadCurrentState()
+	if err != nil {
+		return err
+	}
+
+	if err := p.Verify(currentState); err != nil {
+		return err
+	}
+
+	if p.ClewfileChanged() && !opts.Quiet {
+		fmt.Fprintf(os.Stderr, "Warning: %s changed since the plan was created; applying the saved plan\n", p.ClewfilePath)
+	}
+
+	if service.IsInSync(p.Diff) {
+		if !opts.Quiet {
+			fmt.Println("Plan contains no changes. Nothing to do.")
+		}
+		return nil
+	}
+
+	l, err := acquireLock("apply", opts.Wait)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = l.Release() }()
+
+	// Re-verify under the lock in case another process changed state while we waited
+	if opts.Wait {
+		if currentState, err = service.ReadCurrentState(); err != nil {
+			return err
+		}
+		if err := p.Verify(currentState); err != nil {
+			return err
+		}
+	}
+
+	backupsAuto, onFailure := applySettings(p.ClewfilePath)
+	opts.OnFailure = onFailure
+
+	var backupID string
+	if opts.CreateBackup && !opts.ForceBackup && !backupsAuto {
+		logging.Decisionf("Skipping backup: backups.auto is false")
+	} else if opts.CreateBackup {
+		backupID = service.handleBackup(currentState)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	service.SetContext(ctx)
+
+	result, err := service.ExecuteSync(p.Diff, opts)
+	if err != nil {
+		return fmt.Errorf("apply failed: %w", err)
+	}
+	service.recordPluginHashes(result)
+	service.recordRun("apply", p.ClewfilePath, backupID, startedAt, result)
+	service.recordStamp(p.ClewfilePath, result)
+
+	return service.handleOutput(result, opts)
+}
+
+// applySettings returns whether the plan's Clewfile leaves automatic
+// backups on, and its top-level on_failure policy. A Clewfile that no
+// longer loads keeps backups on and the default policy.
+func applySettings(clewfilePath string) (backupsAuto bool, onFailure string) {
+	clewfile, err := config.Load(clewfilePath)
+	if err != nil {
+		logging.Decisionf("Backing up, with the default on_failure: %s does not load: %v", clewfilePath, err)
+		return true, ""
+	}
+	return clewfile.Backups.AutoEnabled(), clewfile.OnFailure
+}
+
+// printEstimate lists the planned operations with their estimated
+// durations and the total. Estimates without timings from this machine are
+// marked as defaults.
+func printEstimate(out io.Writer, ops []estimate.Operation, total time.Duration) {
+	if len(ops) == 0 {
+		return
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Estimated time: %s\n", estimate.Format(total))
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, op := range ops {
+		fmt.Fprintf(w, "  %s\t%s %s %s", estimate.Format(op.Estimate), op.Action, op.Type, op.Name)
+		if !op.Measured {
+			fmt.Fprint(w, "\t(default)")
+		}
+		fmt.Fprintln(w)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyRefusesWhenStateChanged(t *testing.T) {
+	ts := newTestSetup(t)
+	defer ts.cleanup()
+
+	oldConfigPath := configPath
+	defer func() { configPath = oldConfigPath }()
+
+	t.Setenv("HOME", ts.tmpDir)
+	configPath = ts.clewfile
+	quiet = true
+
+	ts.writeClewfile(t, `version: 1
+marketplaces:
+  official:
+    repo: anthropics/claude-plugins-official
+plugins:
+  - context7@official
+`)
+	ts.writeMarketplaces(t, map[string]interface{}{
+		"official": map[string]interface{}{
+			"source": map[string]interface{}{"source": "github", "repo": "anthropics/claude-plugins-official"},
+		},
+	})
+	ts.writeInstalledPlugins(t, map[string]interface{}{
+		"context7@official": []map[string]interface{}{{"scope": "user", "installPath": "/tmp/context7"}},
+	})
+
+	planPath := filepath.Join(ts.tmpDir, "plan.json")
+	_ = captureStdout(t, func() {
+		if err := runPlan(planPath); err != nil {
+			t.Fatalf("runPlan() error = %v", err)
+		}
+	})
+
+	// Unchanged state: plan applies (and has nothing to do)
+	_ = captureStdout(t, func() {
+		if err := runApply(planPath, SyncOptions{OutputFormat: "text", Quiet: true}); err != nil {
+			t.Errorf("runApply() on unchanged state error = %v", err)
+		}
+	})
+
+	// Disabling the plugin out-of-band changes the state fingerprint
+	ts.writeSettings(t, map[string]interface{}{
+		"enabledPlugins": map[string]bool{"context7@official": false},
+	})
+
+	err := runApply(planPath, SyncOptions{OutputFormat: "text", Quiet: true})
+	if err == nil || !strings.Contains(err.Error(), "state changed") {
+		t.Errorf("runApply() error = %v, want state changed error", err)
+	}
+}
+
+func TestApplySettings(t *testing.T) {
+	dir := t.TempDir()
+	off := filepath.Join(dir, "off.yaml")
+	if err := os.WriteFile(off, []byte("version: 1\non_failure: abort\nbackups:\n  auto: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	on := filepath.Join(dir, "on.yaml")
+	if err := os.WriteFile(on, []byte("version: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if auto, onFailure := applySettings(off); auto || onFailure != "abort" {
+		t.Errorf("applySettings() = %t, %q; want false, abort", auto, onFailure)
+	}
+	if auto, onFailure := applySettings(on); !auto || onFailure != "" {
+		t.Errorf("applySettings() = %t, %q; want backups on by default", auto, onFailure)
+	}
+	if auto, _ := applySettings(filepath.Join(dir, "missing.yaml")); !auto {
+		t.Error("applySettings() = false for a missing Clewfile, want backups kept on")
+	}
+}
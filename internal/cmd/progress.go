@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/adamancini/clew/internal/sync"
+)
+
+// startProgress shows the operation the syncer is running on one line of
+// stderr, when enabled and stderr is a terminal. The returned function
+// stops the display and clears the line; it is safe to call more than once.
+func startProgress(syncer *sync.Syncer, enabled bool) func() {
+	if !enabled || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return func() {}
+	}
+	events := make(chan sync.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		showProgress(os.Stderr, events)
+	}()
+	syncer.SetEvents(events)
+
+	stopped := false
+	return func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		syncer.SetEvents(nil)
+		close(events)
+		<-done
+	}
+}
+
+// showProgress writes each started operation over the previous line and
+// clears it when the operation finishes, until events is closed.
+func showProgress(out io.Writer, events <-chan sync.Event) {
+	finished := 0
+	for ev := range events {
+		switch ev.Kind {
+		case sync.OperationStarted:
+			_, _ = fmt.Fprintf(out, "\r\033[K[%d] %s %s %s...", finished+1, ev.Action, ev.Type, ev.Name)
+		case sync.OperationFinished:
+			finished++
+			_, _ = fmt.Fprint(out, "\r\033[K")
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/projects"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func newProjectsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "projects",
+		Short: "Inspect per-project Claude Code configuration",
+		Long: `Projects reports on configuration that lives in individual repositories
+rather than your Clewfile: project-scope plugin installs, plugins enabled
+in a project's .claude/settings.json, and MCP servers in .mcp.json.`,
+	}
+
+	cmd.AddCommand(newProjectsScanCmd())
+
+	return cmd
+}
+
+func newProjectsScanCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "scan [dir]",
+		Short: "Find Claude projects under a directory and report their drift",
+		Long: `Scan walks dir (default: the current directory) for Claude projects and
+prints one combined report: each project's plugins and MCP servers, plugins
+a project enables that are not installed for it, stdio MCP servers whose
+command is not on PATH or is not an executable file, and project installs
+whose directory no longer exists.
+
+Hidden directories, node_modules and vendor are skipped. clew does not
+manage project configuration; the report tells you what to fix by hand.`,
+		Example: `  clew projects scan ~/src
+  clew projects scan ~/src --output json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := "."
+			if len(args) == 1 {
+				root = args[0]
+			}
+			return runProjectsScan(root)
+		},
+	}
+}
+
+// runProjectsScan scans root and prints the combined report.
+func runProjectsScan(root string) error {
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	report, err := projects.Scan(root, currentState)
+	if err != nil {
+		return err
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		return writer.Write(report)
+	}
+	return printProjectsReport(os.Stdout, report)
+}
+
+// printProjectsReport prints a summary table followed by each issue.
+func printProjectsReport(out io.Writer, report *projects.Report) error {
+	if len(report.Projects) == 0 && len(report.Stale) == 0 {
+		_, _ = fmt.Fprintf(out, "No Claude projects found under %s.\n", report.Root)
+		return nil
+	}
+
+	// Paths are shown relative to the scanned directory
+	display := func(path string) string {
+		if rel, err := filepath.Rel(report.Root, path); err == nil {
+			return rel
+		}
+		return path
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PROJECT\tPLUGINS\tMCP SERVERS\tSTATUS")
+	issues := 0
+	for _, p := range report.Projects {
+		status := "ok"
+		if n := len(p.Issues); n > 0 {
+			status = i18n.N(i18n.ProjectsIssues, n)
+			issues += n
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", display(p.Path), len(p.Plugins), len(p.MCPServers), status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, p := range report.Projects {
+		if len(p.Issues) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "\n%s:\n", display(p.Path))
+		for _, issue := range p.Issues {
+			if issue.Plugin != "" {
+				_, _ = fmt.Fprintf(out, "  ! %s: %s\n", issue.Plugin, issue.Message)
+			} else if issue.Server != "" {
+				_, _ = fmt.Fprintf(out, "  ! mcp %s: %s\n", issue.Server, issue.Message)
+			} else {
+				_, _ = fmt.Fprintf(out, "  ! %s\n", issue.Message)
+			}
+		}
+	}
+
+	if len(report.Stale) > 0 {
+		_, _ = fmt.Fprintln(out, "\nInstalls for projects that no longer exist:")
+		for _, s := range report.Stale {
+			_, _ = fmt.Fprintf(out, "  ! %s: %s\n", s.Plugin, s.ProjectPath)
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "\n%s\n", i18n.N(i18n.ProjectsSummary, len(report.Projects),
+		i18n.N(i18n.ProjectsIssues, issues), i18n.N(i18n.ProjectsStale, len(report.Stale))))
+	return nil
+}
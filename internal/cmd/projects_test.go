@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/projects"
+)
+
+func TestPrintProjectsReport(t *testing.T) {
+	report := &projects.Report{
+		Root: "/src",
+		Projects: []projects.Project{
+			{Path: "/src/api", Plugins: []projects.Plugin{{Name: "linter@official", Enabled: true}}, Issues: []projects.Issue{
+				{Kind: projects.IssueNotInstalled, Plugin: "linter@official", Message: "enabled in .claude/settings.json but not installed"},
+			}},
+			{Path: "/src/web", MCPServers: []string{"postgres"}},
+		},
+		Stale: []projects.Stale{{Plugin: "tester@official", ProjectPath: "/src/gone"}},
+	}
+
+	var buf bytes.Buffer
+	if err := printProjectsReport(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"api      1        0            1 issue",
+		"web      0        1            ok",
+		"api:\n  ! linter@official: enabled in .claude/settings.json but not installed",
+		"  ! tester@official: /src/gone",
+		"2 projects, 1 issue, 1 stale install",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestPrintProjectsReportEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printProjectsReport(&buf, &projects.Report{Root: "/src"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "No Claude projects found under /src.\n" {
+		t.Errorf("output = %q", got)
+	}
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/adamancini/clew/internal/catalog"
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/interactive"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/recommend"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// RecommendResult is the output of clew recommend.
+type RecommendResult struct {
+	Languages       []string                   `json:"languages" yaml:"languages"`
+	Recommendations []recommend.Recommendation `json:"recommendations" yaml:"recommendations"`
+}
+
+func newRecommendCmd() *cobra.Command {
+	var limit int
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "recommend",
+		Short: "Suggest plugins for the current project",
+		Long: `Recommend suggests plugins from your installed marketplaces based on the
+project in the current directory (go.mod, package.json, pyproject.toml,
+Cargo.toml, ...) and the plugins you already have.
+
+Plugins that are installed or already in your Clewfile are never suggested.
+In a terminal, each suggestion can be added to the Clewfile with a single
+keystroke; run 'clew sync' afterwards to install them.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRecommend(limit, yes)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "Maximum number of suggestions")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Add every suggestion to the Clewfile without prompting")
+
+	return cmd
+}
+
+// runRecommend lists suggestions and offers to add them to the Clewfile.
+func runRecommend(limit int, yes bool) error {
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to determine working directory: %w", err)
+	}
+
+	have := make(map[string]bool)
+	for name := range currentState.Plugins {
+		have[name] = true
+	}
+
+	// Without a Clewfile there is nothing to add to, but suggestions still help
+	clewfilePath, err := config.FindClewfile(configPath)
+	if err != nil {
+		clewfilePath = ""
+	} else {
+		clewfile, err := config.Load(clewfilePath)
+		if err != nil {
+			return fmt.Errorf("failed to load Clewfile: %w", err)
+		}
+		for _, p := range clewfile.Plugins {
+			have[p.Name] = true
+		}
+	}
+
+	result := RecommendResult{Languages: recommend.DetectLanguages(cwd)}
+	result.Recommendations = recommend.Recommend(catalog.Load(currentState.Marketplaces), result.Languages, have)
+	if limit > 0 && len(result.Recommendations) > limit {
+		result.Recommendations = result.Recommendations[:limit]
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		return writer.Write(result)
+	}
+
+	if len(result.Languages) > 0 {
+		fmt.Printf("Detected: %s\n\n", strings.Join(result.Languages, ", "))
+	}
+	if len(result.Recommendations) == 0 {
+		fmt.Println("No recommendations. Install more marketplaces to widen the search.")
+		return nil
+	}
+	if err := printRecommendations(result.Recommendations); err != nil {
+		return err
+	}
+
+	if clewfilePath == "" {
+		return nil
+	}
+
+	var selected []string
+	switch {
+	case yes:
+		for _, r := range result.Recommendations {
+			selected = append(selected, r.FullName())
+		}
+	case interactive.IsTerminal():
+		fmt.Println()
+		selected, err = selectRecommendations(result.Recommendations, readKey, os.Stdout)
+		if err != nil {
+			return err
+		}
+	default:
+		fmt.Println("\nRun 'clew recommend --yes' to add these to your Clewfile.")
+		return nil
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+
+	if err := config.AddPlugins(clewfilePath, selected, clewfileMarketplaces(currentState)); err != nil {
+		return err
+	}
+	fmt.Printf("\n%s\n", i18n.N(i18n.RecommendAdded, len(selected), clewfilePath))
+	return nil
+}
+
+// printRecommendations prints a numbered suggestion table.
+func printRecommendations(recs []recommend.Recommendation) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "#\tNAME\tWHY\tDESCRIPTION")
+	for i, r := range recs {
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, r.FullName(), strings.Join(r.Reasons, ", "), truncate(r.Description, 50))
+	}
+	return w.Flush()
+}
+
+// selectRecommendations asks about each suggestion, reading one key per
+// answer: y adds it, n or Enter skips it, a adds it and the rest, q stops.
+func selectRecommendations(recs []recommend.Recommendation, readKey func() (byte, error), out io.Writer) ([]string, error) {
+	var selected []string
+	all := false
+	for _, r := range recs {
+		if all {
+			selected = append(selected, r.FullName())
+			continue
+		}
+
+		_, _ = fmt.Fprintf(out, "Add %s to Clewfile? [y/n/a/q] ", r.FullName())
+		key, err := readKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		_, _ = fmt.Fprintf(out, "%c\n", printableKey(key))
+
+		switch key {
+		case 'y', 'Y':
+			selected = append(selected, r.FullName())
+		case 'a', 'A':
+			all = true
+			selected = append(selected, r.FullName())
+		case 'q', 'Q', 3, 27: // Ctrl-C and Esc quit too
+			return selected, nil
+		}
+	}
+	return selected, nil
+}
+
+// printableKey returns the key to echo after a prompt.
+func printableKey(key byte) rune {
+	if key < ' ' || key > '~' {
+		return ' '
+	}
+	return rune(key)
+}
+
+// readKey reads a single keystroke from the terminal without waiting for Enter.
+func readKey() (byte, error) {
+	fd := int(os.Stdin.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = term.Restore(fd, oldState) }()
+
+	var buf [1]byte
+	if _, err := os.Stdin.Read(buf[:]); err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+// clewfileMarketplaces returns the Clewfile declarations for installed
+// marketplaces that come from a repository.
+func clewfileMarketplaces(currentState *state.State) map[string]config.Marketplace {
+	marketplaces := make(map[string]config.Marketplace)
+	for alias, m := range currentState.Marketplaces {
+		if m.Repo != "" {
+			marketplaces[alias] = config.Marketplace{Repo: m.Repo}
+		}
+	}
+	return marketplaces
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/adamancini/clew/internal/catalog"
+	"github.com/adamancini/clew/internal/recommend"
+)
+
+func keys(s string) func() (byte, error) {
+	return func() (byte, error) {
+		if s == "" {
+			return 0, errors.New("EOF")
+		}
+		k := s[0]
+		s = s[1:]
+		return k, nil
+	}
+}
+
+func TestSelectRecommendations(t *testing.T) {
+	recs := []recommend.Recommendation{
+		{Entry: catalog.Entry{Name: "a", Marketplace: "m"}},
+		{Entry: catalog.Entry{Name: "b", Marketplace: "m"}},
+		{Entry: catalog.Entry{Name: "c", Marketplace: "m"}},
+		{Entry: catalog.Entry{Name: "d", Marketplace: "m"}},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"yes, no and enter", "yn\ry", []string{"a@m", "d@m"}},
+		{"all", "na", []string{"b@m", "c@m", "d@m"}},
+		{"quit", "yq", []string{"a@m"}},
+		{"ctrl-c", "\x03", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := selectRecommendations(recs, keys(tt.input), &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := selectRecommendations(recs, keys(""), &bytes.Buffer{}); err == nil {
+		t.Error("a read error should be returned")
+	}
+}
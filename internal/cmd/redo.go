@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/journal"
+	"github.com/adamancini/clew/internal/sync"
+)
+
+// RedoOptions configures the redo command.
+type RedoOptions struct {
+	All  bool // Retry every failed operation of the last run
+	Wait bool // Wait for another clew process to release the lock
+}
+
+func newRedoCmd() *cobra.Command {
+	var opts RedoOptions
+
+	cmd := &cobra.Command{
+		Use:   "redo [operation-id...]",
+		Short: "Retry failed operations from the last sync",
+		Long: `Redo retries individual failed operations from the most recent sync,
+apply or redo, referenced by the operation IDs shown in its report and in
+'clew history show'. After 'clew undo', it works on the run undo reverted.
+
+Only the selected items are re-evaluated against the run's Clewfile and
+executed; the rest of the diff is left alone. Items that no longer need a
+change are reported and skipped.
+
+Examples:
+  clew redo 3
+  clew redo 3 5
+  clew redo --all`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.All == (len(args) > 0) {
+				return fmt.Errorf("specify operation IDs or --all")
+			}
+			ids := make([]int, 0, len(args))
+			for _, a := range args {
+				id, err := strconv.Atoi(a)
+				if err != nil || id < 1 {
+					return fmt.Errorf("invalid operation ID %q", a)
+				}
+				ids = append(ids, id)
+			}
+			return runRedo(ids, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Retry every failed operation of the last run")
+	cmd.Flags().BoolVar(&opts.Wait, "wait", false, "Wait for another running clew process instead of failing")
+
+	return cmd
+}
+
+// runRedo retries failed operations of the run LastRedoable selects.
+func runRedo(ids []int, opts RedoOptions) error {
+	startedAt := time.Now()
+
+	l, err := acquireLock("redo", opts.Wait)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = l.Release() }()
+
+	j, err := journal.New()
+	if err != nil {
+		return err
+	}
+	run, err := j.LastRedoable()
+	if err != nil {
+		return err
+	}
+
+	ops, err := selectFailedOperations(run, ids, opts.All)
+	if err != nil {
+		return err
+	}
+
+	service := NewSyncService(run.Clewfile, clewVersion)
+	clewfile, clewfilePath, err := service.LoadConfiguration()
+	if err != nil {
+		return err
+	}
+	currentState, err := service.ReadCurrentState()
+	if err != nil {
+		return err
+	}
+
+	redoDiff, done := filterDiffByOperations(service.ComputeDiff(clewfile, currentState), ops)
+	if !quiet {
+		for _, op := range done {
+			fmt.Printf("Operation %d (%s %s %s) no longer needs a change\n", op.ID, op.Type, op.Action, op.Name)
+		}
+	}
+	if len(redoDiff.Marketplaces) == 0 && len(redoDiff.Plugins) == 0 {
+		if !quiet {
+			fmt.Println("Nothing to redo.")
+		}
+		return nil
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	service.SetContext(ctx)
+
+	syncOpts := SyncOptions{
+		OnFailure:    clewfile.OnFailure,
+		OutputFormat: outputFormat,
+		Verbose:      verbose,
+		Quiet:        quiet,
+	}
+	result, err := service.ExecuteSync(redoDiff, syncOpts)
+	if err != nil {
+		if redacted := clewfile.Redact(err.Error()); redacted != err.Error() {
+			err = errors.New(redacted)
+		}
+		return fmt.Errorf("redo failed: %w", err)
+	}
+	redactResult(clewfile, result)
+	service.recordPluginHashes(result)
+	service.recordRun("redo", clewfilePath, "", startedAt, result)
+
+	return service.handleOutput(result, syncOpts)
+}
+
+// selectFailedOperations returns the failed operations of run with the
+// given IDs, or all failed operations when all is set.
+func selectFailedOperations(run *journal.Run, ids []int, all bool) ([]sync.Operation, error) {
+	byID := make(map[int]sync.Operation, len(run.Operations))
+	var failed []sync.Operation
+	for _, op := range run.Operations {
+		byID[op.ID] = op
+		if !op.Success {
+			failed = append(failed, op)
+		}
+	}
+
+	if all {
+		if len(failed) == 0 {
+			return nil, fmt.Errorf("run %s has no failed operations", run.ID)
+		}
+		return failed, nil
+	}
+
+	ops := make([]sync.Operation, 0, len(ids))
+	for _, id := range ids {
+		op, ok := byID[id]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("run %s has no operation %d", run.ID, id)
+		case op.Success:
+			return nil, fmt.Errorf("operation %d (%s %s %s) did not fail", id, op.Type, op.Action, op.Name)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// filterDiffByOperations keeps only the diff items the given operations
+// acted on. Operations whose item no longer needs a change are returned
+// as done.
+func filterDiffByOperations(d *diff.Result, ops []sync.Operation) (*diff.Result, []sync.Operation) {
+	filtered := &diff.Result{}
+	var done []sync.Operation
+
+	for _, op := range ops {
+		found := false
+		switch op.Type {
+		case "marketplace":
+			for _, m := range d.Marketplaces {
+				if m.Alias == op.Name && m.Action == diff.ActionAdd {
+					filtered.Marketplaces = append(filtered.Marketplaces, m)
+					found = true
+				}
+			}
+		case "plugin":
+			for _, p := range d.Plugins {
+				if p.Name != op.Name {
+					continue
+				}
+				switch p.Action {
+				case diff.ActionAdd, diff.ActionEnable, diff.ActionDisable:
+					filtered.Plugins = append(filtered.Plugins, p)
+					found = true
+				}
+			}
+		case "keybinding":
+			for _, k := range d.Keybindings {
+				if k.Name() == op.Name && (k.Action == diff.ActionAdd || k.Action == diff.ActionUpdate) {
+					filtered.Keybindings = append(filtered.Keybindings, k)
+					found = true
+				}
+			}
+		}
+		if !found {
+			done = append(done, op)
+		}
+	}
+
+	return filtered, done
+}
+
+// printRedoHint tells the user how to retry the failed operations of a run.
+func printRedoHint(result *sync.Result) {
+	var ids []string
+	for _, op := range result.Operations {
+		if !op.Success && op.ID > 0 {
+			ids = append(ids, strconv.Itoa(op.ID))
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	fmt.Printf("\nRetry failed operations with: clew redo %s (or clew redo --all)\n", strings.Join(ids, " "))
+}
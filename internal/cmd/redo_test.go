@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/journal"
+	"github.com/adamancini/clew/internal/sync"
+)
+
+func TestSelectFailedOperations(t *testing.T) {
+	run := &journal.Run{
+		ID: "2025-01-01-120000",
+		Operations: []sync.Operation{
+			{ID: 1, Type: "marketplace", Name: "m", Action: "add", Success: true},
+			{ID: 2, Type: "plugin", Name: "a@m", Action: "add"},
+			{ID: 3, Type: "plugin", Name: "b@m", Action: "enable"},
+		},
+	}
+
+	ops, err := selectFailedOperations(run, nil, true)
+	if err != nil || len(ops) != 2 {
+		t.Fatalf("selectFailedOperations(all) = %v, %v; want 2 ops", ops, err)
+	}
+
+	ops, err = selectFailedOperations(run, []int{3}, false)
+	if err != nil || len(ops) != 1 || ops[0].Name != "b@m" {
+		t.Errorf("selectFailedOperations(3) = %v, %v", ops, err)
+	}
+
+	if _, err := selectFailedOperations(run, []int{1}, false); err == nil || !strings.Contains(err.Error(), "did not fail") {
+		t.Errorf("selecting a successful operation: err = %v", err)
+	}
+	if _, err := selectFailedOperations(run, []int{9}, false); err == nil {
+		t.Error("selecting an unknown operation should fail")
+	}
+
+	run.Operations = run.Operations[:1]
+	if _, err := selectFailedOperations(run, nil, true); err == nil {
+		t.Error("--all on a run without failures should fail")
+	}
+}
+
+func TestFilterDiffByOperations(t *testing.T) {
+	d := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "m", Action: diff.ActionAdd, Desired: &config.Marketplace{Repo: "o/m"}},
+			{Alias: "other", Action: diff.ActionAdd, Desired: &config.Marketplace{Repo: "o/other"}},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "a@m", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "a@m"}},
+			{Name: "b@m", Action: diff.ActionNone},
+			{Name: "c@m", Action: diff.ActionEnable},
+		},
+	}
+	ops := []sync.Operation{
+		{ID: 1, Type: "marketplace", Name: "m", Action: "add"},
+		{ID: 2, Type: "plugin", Name: "a@m", Action: "add"},
+		{ID: 3, Type: "plugin", Name: "b@m", Action: "enable"},
+	}
+
+	filtered, done := filterDiffByOperations(d, ops)
+
+	if len(filtered.Marketplaces) != 1 || filtered.Marketplaces[0].Alias != "m" {
+		t.Errorf("Marketplaces = %v, want only m", filtered.Marketplaces)
+	}
+	if len(filtered.Plugins) != 1 || filtered.Plugins[0].Name != "a@m" {
+		t.Errorf("Plugins = %v, want only a@m", filtered.Plugins)
+	}
+	if len(done) != 1 || done[0].ID != 3 {
+		t.Errorf("done = %v, want operation 3", done)
+	}
+}
+
+func TestPrintRedoHint(t *testing.T) {
+	out := captureStdout(t, func() {
+		printRedoHint(&sync.Result{Operations: []sync.Operation{
+			{ID: 1, Success: true},
+			{ID: 2},
+			{ID: 4},
+		}})
+	})
+	if !strings.Contains(out, "clew redo 2 4") {
+		t.Errorf("hint = %q", out)
+	}
+
+	out = captureStdout(t, func() {
+		printRedoHint(&sync.Result{Operations: []sync.Operation{{ID: 1, Success: true}}})
+	})
+	if out != "" {
+		t.Errorf("hint without failures = %q, want empty", out)
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/repair"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func newRepairCmd() *cobra.Command {
+	var (
+		check bool
+		wait  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "repair",
+		Short: "Detect and repair corrupted Claude state files",
+		Long: `Repair checks installed_plugins.json, known_marketplaces.json and settings.json
+for trailing garbage, duplicate keys, and entries that no longer match the
+expected schema.
+
+Files with problems are backed up next to the original (*.corrupt-<timestamp>)
+and rewritten with everything that could be salvaged. Files that cannot be
+parsed at all are reconstructed from the plugins directory and the Clewfile.
+
+Examples:
+  clew repair --check   # Report problems without changing anything
+  clew repair           # Back up and repair damaged files`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRepair(check, wait)
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "Report problems without modifying any files")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for another running clew process instead of failing")
+
+	return cmd
+}
+
+func runRepair(check, wait bool) error {
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	claudeDir, err := state.DefaultClaudeDir()
+	if err != nil {
+		return err
+	}
+
+	// The Clewfile is only needed to reconstruct unreadable files
+	var clewfile *config.Clewfile
+	if path, err := config.FindClewfile(configPath); err == nil {
+		if clewfile, err = config.Load(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring invalid Clewfile %s: %v\n", path, err)
+		}
+	}
+
+	repairer := repair.NewRepairer(claudeDir, clewfile)
+
+	var report *repair.Report
+	if check {
+		report, err = repairer.Check()
+	} else {
+		l, lockErr := acquireLock("repair", wait)
+		if lockErr != nil {
+			return lockErr
+		}
+		defer func() { _ = l.Release() }()
+		report, err = repairer.Repair()
+	}
+	if err != nil {
+		return err
+	}
+
+	if format == output.FormatText {
+		printRepairReportText(report)
+	} else if err := output.NewWriter(os.Stdout, format).Write(report); err != nil {
+		return err
+	}
+
+	if check && report.HasIssues() {
+		return fmt.Errorf("state files need repair (run 'clew repair')")
+	}
+	return nil
+}
+
+// printRepairReportText outputs the repair report in human-readable format.
+func printRepairReportText(report *repair.Report) {
+	if !report.HasIssues() {
+		if !quiet {
+			fmt.Println("All Claude state files are healthy.")
+		}
+		return
+	}
+
+	for _, f := range report.Files {
+		if len(f.Issues) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", f.Path)
+		for _, issue := range f.Issues {
+			fmt.Printf("  - %s\n", issue)
+		}
+		switch {
+		case f.Reconstructed:
+			fmt.Printf("  Reconstructed from plugins directory and Clewfile (backup: %s)\n", f.BackupPath)
+		case f.Repaired:
+			fmt.Printf("  Repaired (backup: %s)\n", f.BackupPath)
+		}
+	}
+}
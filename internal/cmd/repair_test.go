@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunRepair(t *testing.T) {
+	ts := newTestSetup(t)
+	defer ts.cleanup()
+	t.Setenv("HOME", ts.tmpDir)
+
+	ts.writeClewfile(t, `version: 1
+plugins: []
+`)
+	oldConfigPath := configPath
+	configPath = ts.clewfile
+	defer func() { configPath = oldConfigPath }()
+
+	settingsPath := filepath.Join(ts.tmpDir, ".claude", "settings.json")
+	if err := os.WriteFile(settingsPath, []byte(`{"theme": "dark"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// --check reports the problem without touching the file
+	var err error
+	out := captureStdout(t, func() { err = runRepair(true, false) })
+	if err == nil {
+		t.Error("runRepair(check) should fail when files need repair")
+	}
+	if !strings.Contains(out, "trailing garbage") {
+		t.Errorf("check output missing issue:\n%s", out)
+	}
+
+	out = captureStdout(t, func() { err = runRepair(false, false) })
+	if err != nil {
+		t.Fatalf("runRepair() error = %v", err)
+	}
+	if !strings.Contains(out, "Repaired (backup: ") {
+		t.Errorf("repair output missing backup path:\n%s", out)
+	}
+
+	out = captureStdout(t, func() { err = runRepair(true, false) })
+	if err != nil {
+		t.Errorf("runRepair(check) after repair error = %v", err)
+	}
+	if !strings.Contains(out, "healthy") {
+		t.Errorf("expected healthy report, got:\n%s", out)
+	}
+}
@@ -1,15 +1,28 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/state"
+	"github.com/adamancini/clew/internal/update"
 )
 
 var (
 	// Global flags
 	outputFormat string
 	configPath   string
-	verbose      bool
+	verbose      bool // True at any verbosity level (-v or more)
+	verbosity    int  // Number of -v flags
 	quiet        bool
+	trace        bool
+	claudeDir    string // --claude-dir, exported as CLAUDE_CONFIG_DIR
+	useProject   bool   // --project, use the repository's .clew/ Clewfile
 )
 
 func Execute(version, commit, date string) error {
@@ -21,16 +34,33 @@ func Execute(version, commit, date string) error {
 Define your desired configuration in a Clewfile, sync it across machines with clew sync.`,
 		Version: version,
 		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			verbose = verbosity > 0
+			logging.SetLevel(logging.Level(verbosity))
+			logging.SetTrace(trace)
+			if err := useProjectClewfile(useProject); err != nil {
+				return err
+			}
+			return useClaudeDir(claudeDir)
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			printUpdateNotice(cmd)
+		},
 	}
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text, json, yaml")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text, json, yaml (status also accepts badge)")
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to Clewfile")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Verbose output (-v decisions, -vv external command output, -vvv state file parsing)")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Quiet mode (errors only)")
+	rootCmd.PersistentFlags().BoolVar(&trace, "trace", false, "Print each external command as it runs, with exit code and duration (-vv adds its output)")
+	rootCmd.PersistentFlags().BoolVar(&useProject, "project", false, "Use the Clewfile in .clew/ at the root of the current git repository")
+	rootCmd.PersistentFlags().StringVar(&claudeDir, "claude-dir", "", "Claude Code configuration directory (default $CLAUDE_CONFIG_DIR or ~/.claude)")
+	_ = rootCmd.MarkPersistentFlagDirname("claude-dir")
 
 	// Set version for backup metadata and version command
 	SetVersion(version)
+	buildInfo = update.NewBuildInfo(version, commit, date)
 
 	// Add subcommands
 	rootCmd.AddCommand(newSyncCmd())
@@ -40,11 +70,83 @@ Define your desired configuration in a Clewfile, sync it across machines with cl
 	rootCmd.AddCommand(newCompletionCmd())
 	rootCmd.AddCommand(newBackupCmd())
 	rootCmd.AddCommand(newVersionCmd())
+	rootCmd.AddCommand(newBootstrapCmd())
+	rootCmd.AddCommand(newPlanCmd())
+	rootCmd.AddCommand(newApplyCmd())
+	rootCmd.AddCommand(newEnvCmd())
+	rootCmd.AddCommand(newRepairCmd())
+	rootCmd.AddCommand(newHistoryCmd())
+	rootCmd.AddCommand(newUndoCmd())
+	rootCmd.AddCommand(newRedoCmd())
+	rootCmd.AddCommand(newInfoCmd())
+	rootCmd.AddCommand(newSearchCmd())
+	rootCmd.AddCommand(newRecommendCmd())
+	rootCmd.AddCommand(newWhichCmd())
+	rootCmd.AddCommand(newOpenCmd())
+	rootCmd.AddCommand(newCatCmd())
+	rootCmd.AddCommand(newLintCmd())
+	rootCmd.AddCommand(newDedupeCmd())
+	rootCmd.AddCommand(newProjectsCmd())
+	rootCmd.AddCommand(newFleetCmd())
+	rootCmd.AddCommand(newExplainCmd())
+	rootCmd.AddCommand(newBaselineCmd())
+	rootCmd.AddCommand(newScanCmd())
+	rootCmd.AddCommand(newShellenvCmd())
+	rootCmd.AddCommand(newMCPCmd())
+	rootCmd.AddCommand(newSnoozeCmd())
+	rootCmd.AddCommand(newBundleCmd())
+	rootCmd.AddCommand(newNukeCmd())
+	rootCmd.AddCommand(newStateCmd())
+	rootCmd.AddCommand(newSetupCmd())
 
 	// Register completion function for output flag
 	_ = rootCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if cmd.Name() == "status" {
+			return []string{"text", "json", "yaml", badgeFormat}, cobra.ShellCompDirectiveNoFileComp
+		}
 		return []string{"text", "json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
 	})
 
 	return rootCmd.Execute()
 }
+
+// useProjectClewfile points --config at the .clew/ Clewfile of the
+// repository containing the current directory when enabled. Project
+// Clewfiles are only used on request: everything clew installs is
+// user-wide.
+func useProjectClewfile(enabled bool) error {
+	if !enabled {
+		return nil
+	}
+	if configPath != "" {
+		return fmt.Errorf("--project and --config cannot be used together")
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to determine current directory: %w", err)
+	}
+	path, ok := config.FindProjectClewfile(cwd)
+	if !ok {
+		return fmt.Errorf("--project: no Clewfile in %s/ at the root of the current git repository", config.ProjectDir)
+	}
+	logging.Decisionf("Using project Clewfile %s (--project)", path)
+	configPath = path
+	return nil
+}
+
+// useClaudeDir points clew, and the claude commands it runs, at dir by
+// setting CLAUDE_CONFIG_DIR. Empty leaves the environment as it is.
+func useClaudeDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid --claude-dir: %w", err)
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return fmt.Errorf("--claude-dir %s is not a directory", dir)
+	}
+	logging.Decisionf("Using Claude directory %s (--claude-dir)", abs)
+	return os.Setenv(state.ConfigDirEnv, abs)
+}
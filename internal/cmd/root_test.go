@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+)
+
+func TestUseProjectClewfile(t *testing.T) {
+	savedConfig := configPath
+	t.Cleanup(func() { configPath = savedConfig })
+
+	root := t.TempDir()
+	for _, dir := range []string{filepath.Join(root, ".git"), filepath.Join(root, config.ProjectDir)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(root)
+
+	configPath = ""
+	if err := useProjectClewfile(false); err != nil || configPath != "" {
+		t.Errorf("useProjectClewfile(false) = %v, configPath %q; want nothing changed", err, configPath)
+	}
+	if err := useProjectClewfile(true); err == nil {
+		t.Error("useProjectClewfile(true) without a project Clewfile should fail")
+	}
+
+	want := filepath.Join(root, config.ProjectDir, "Clewfile.yaml")
+	if err := os.WriteFile(want, []byte("version: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := useProjectClewfile(true); err != nil {
+		t.Fatalf("useProjectClewfile(true) error = %v", err)
+	}
+	if got, _ := filepath.EvalSymlinks(configPath); got != mustEvalSymlinks(t, want) {
+		t.Errorf("configPath = %q, want %q", configPath, want)
+	}
+
+	configPath = "/elsewhere/Clewfile"
+	if err := useProjectClewfile(true); err == nil {
+		t.Error("--project with --config should fail")
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/scan"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func newScanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scan [plugin...]",
+		Short: "Scan plugin hooks and scripts for risky patterns",
+		Long: `Scan inspects the files of installed and declared plugins for risky
+patterns and scores each plugin from 0 to 10:
+
+  pipe-to-shell      (8)  a download piped straight into a shell (curl ... | bash)
+  credential-read    (7)  reads SSH keys, cloud credentials or other secrets
+  obfuscated-script  (6)  decodes and runs hidden code, or embeds a long payload
+
+A plugin's score is the sum of the distinct rules it matches, capped at 10.
+Findings are heuristics to guide review, not proof that a plugin is malicious.
+
+With no arguments, every plugin in the Clewfile and every installed plugin is
+scanned. Declared plugins that are not installed are scanned from their
+cloned marketplace. Plugins whose files are not on this machine are reported
+but cannot be scored.
+
+Setting scan.block_score in the Clewfile makes sync skip plugins scoring at
+or above it, and makes this command exit non-zero. List reviewed plugins in
+scan.allow to trust them anyway.`,
+		Example: `  clew scan
+  clew scan devops-toolkit@devops-toolkit --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScan(args)
+		},
+	}
+
+	return cmd
+}
+
+// ScanResult is the output of clew scan.
+type ScanResult struct {
+	BlockScore int           `json:"block_score,omitempty" yaml:"block_score,omitempty"`
+	Plugins    []scan.Report `json:"plugins" yaml:"plugins"`
+}
+
+// runScan scans the named plugins, or all declared and installed ones.
+func runScan(names []string) error {
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	// The Clewfile is optional: without one, nothing is blocked
+	var policy config.ScanConfig
+	var declared []string
+	if clewfilePath, err := config.FindClewfile(configPath); err == nil {
+		clewfile, err := config.Load(clewfilePath)
+		if err != nil {
+			return err
+		}
+		policy = clewfile.Scan
+		for _, p := range clewfile.Plugins {
+			declared = append(declared, p.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		names = scanTargets(declared, currentState)
+	}
+	result := &ScanResult{
+		BlockScore: policy.BlockScore,
+		Plugins:    scan.Plugins(names, currentState, policy),
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		if err := writer.Write(result); err != nil {
+			return err
+		}
+	} else {
+		printScanResult(os.Stdout, result)
+	}
+
+	blocked := 0
+	for _, r := range result.Plugins {
+		if r.Blocked {
+			blocked++
+		}
+	}
+	if blocked > 0 {
+		return errors.New(i18n.N(i18n.ScanBlocked, blocked, policy.BlockScore))
+	}
+	return nil
+}
+
+// scanTargets returns the union of declared and installed plugins, sorted.
+func scanTargets(declared []string, s *state.State) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range declared {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for name := range s.Plugins {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printScanResult prints a score table followed by each plugin's findings.
+func printScanResult(out io.Writer, result *ScanResult) {
+	if len(result.Plugins) == 0 {
+		_, _ = fmt.Fprintln(out, "No plugins to scan")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PLUGIN\tSCORE\tSTATUS")
+	for _, r := range result.Plugins {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", r.Plugin, scanScore(r), scanStatus(r))
+	}
+	_ = w.Flush()
+
+	for _, r := range result.Plugins {
+		if len(r.Findings) == 0 && r.Error == "" {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "\n%s:\n", r.Plugin)
+		if r.Error != "" {
+			_, _ = fmt.Fprintf(out, "  error: %s\n", r.Error)
+		}
+		for _, f := range r.Findings {
+			_, _ = fmt.Fprintf(out, "  %s:%d %s: %s\n", f.File, f.Line, f.Rule, f.Text)
+		}
+	}
+}
+
+// scanScore formats a plugin's score, or "-" if it could not be scanned.
+func scanScore(r scan.Report) string {
+	if r.Error != "" && len(r.Findings) == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", r.Score)
+}
+
+// scanStatus describes a plugin's scan outcome.
+func scanStatus(r scan.Report) string {
+	switch {
+	case r.Blocked:
+		return "blocked"
+	case r.Error != "" && len(r.Findings) == 0:
+		return "not scanned"
+	case len(r.Findings) > 0:
+		return "review"
+	default:
+		return "clean"
+	}
+}
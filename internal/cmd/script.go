@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/state"
+	"github.com/adamancini/clew/internal/sync"
+)
+
+// scriptClaudeDir is the Claude directory as an emitted script finds it, so
+// the script works for whoever runs it.
+const scriptClaudeDir = `"${CLAUDE_CONFIG_DIR:-$HOME/.claude}"`
+
+// scriptEdit is a change sync makes by editing one of Claude's JSON files
+// rather than with a claude command. sh cannot edit JSON, so the script
+// runs program with python3, passing the file and the changes as JSON.
+type scriptEdit struct {
+	Description string
+	File        string // In the Claude directory
+	Program     string
+	Changes     any
+}
+
+// scriptJSONLoad and scriptJSONSave read and write the edited file in the
+// edit programs, keeping the order of its keys.
+const (
+	scriptJSONLoad = `import json, sys
+path, changes = sys.argv[1], json.loads(sys.argv[2])
+try:
+    with open(path) as f:
+        doc = json.load(f)
+except FileNotFoundError:
+    doc = {}
+`
+	scriptJSONSave = `with open(path, "w") as f:
+    json.dump(doc, f, indent=2, ensure_ascii=False)
+    f.write("\n")
+`
+)
+
+// scriptSetEnabled sets enabledPlugins, as state.FilesystemWriter does.
+const scriptSetEnabled = scriptJSONLoad + `plugins = doc.get("enabledPlugins")
+if not isinstance(plugins, dict):
+    plugins = doc["enabledPlugins"] = {}
+plugins.update(changes)
+` + scriptJSONSave
+
+// scriptSetKeybindings sets bindings in the last block of each context, as
+// state.SetKeybindings does.
+const scriptSetKeybindings = scriptJSONLoad + `blocks = doc.get("bindings") or []
+for context in sorted(changes):
+    block = None
+    for b in blocks:
+        if b.get("context") == context:
+            block = b
+    if block is None:
+        block = {"context": context, "bindings": {}}
+        blocks.append(block)
+    if not isinstance(block.get("bindings"), dict):
+        block["bindings"] = {}
+    block["bindings"].update(changes[context])
+doc["bindings"] = blocks
+` + scriptJSONSave
+
+// scriptSteps splits what sync would do for d into claude commands and file
+// edits. Enable/disable changes sync writes to a settings file itself, with
+// --direct-settings or for settings.local.json, become edits of that file
+// instead of claude commands, as do key bindings.
+func scriptSteps(d *diff.Result, opts SyncOptions) ([]diff.Command, []scriptEdit) {
+	plugins := make(map[string]diff.PluginDiff)
+	for _, p := range d.Plugins {
+		plugins[p.Name] = p
+	}
+
+	var commands []diff.Command
+	enabled := make(map[string]map[string]bool)
+	for _, c := range d.GenerateCommands() {
+		if c.Removal || len(c.Args) < 4 || (c.Args[2] != "enable" && c.Args[2] != "disable") {
+			commands = append(commands, c)
+			continue
+		}
+		p := plugins[c.Args[3]]
+		local := sync.WritesLocalSettings(p, opts.SettingsTarget)
+		if !local && !opts.DirectSettings {
+			commands = append(commands, c)
+			continue
+		}
+		file := state.SettingsFile
+		if local {
+			file = state.SettingsLocalFile
+		}
+		if enabled[file] == nil {
+			enabled[file] = make(map[string]bool)
+		}
+		enabled[file][c.Args[3]] = c.Args[2] == "enable"
+	}
+
+	var edits []scriptEdit
+	for _, file := range []string{state.SettingsFile, state.SettingsLocalFile} {
+		if changes := enabled[file]; len(changes) > 0 {
+			var names []string
+			for _, name := range slices.Sorted(maps.Keys(changes)) {
+				names = append(names, fmt.Sprintf("%s=%t", name, changes[name]))
+			}
+			edits = append(edits, scriptEdit{
+				Description: fmt.Sprintf("Set enabledPlugins in %s: %s", file, strings.Join(names, ", ")),
+				File:        file,
+				Program:     scriptSetEnabled,
+				Changes:     changes,
+			})
+		}
+	}
+
+	bindings := make(map[string]map[string]*string)
+	var names []string
+	for _, k := range d.Keybindings {
+		if k.Action != diff.ActionAdd && k.Action != diff.ActionUpdate {
+			continue
+		}
+		if bindings[k.Context] == nil {
+			bindings[k.Context] = make(map[string]*string)
+		}
+		var action *string
+		if k.Desired != "" {
+			action = &k.Desired
+		}
+		bindings[k.Context][k.Key] = action
+		names = append(names, k.Name())
+	}
+	if len(bindings) > 0 {
+		edits = append(edits, scriptEdit{
+			Description: "Set key bindings: " + strings.Join(names, ", "),
+			File:        state.KeybindingsFile,
+			Program:     scriptSetKeybindings,
+			Changes:     bindings,
+		})
+	}
+
+	return commands, edits
+}
+
+// formatScript formats commands and edits as a standalone POSIX shell
+// script that stops at the first failing step. Each line of header becomes
+// a comment at the top. Removals are listed as comments only, since sync
+// does not run them.
+func formatScript(commands []diff.Command, edits []scriptEdit, header []string) (string, error) {
+	var out strings.Builder
+	out.WriteString("#!/bin/sh\n")
+	for _, line := range header {
+		fmt.Fprintf(&out, "# %s\n", line)
+	}
+	out.WriteString("set -eu\n\n")
+	out.WriteString("if ! command -v claude >/dev/null 2>&1; then\n")
+	out.WriteString("  echo \"claude CLI not found on PATH\" >&2\n")
+	out.WriteString("  exit 1\n")
+	out.WriteString("fi\n")
+	if len(edits) > 0 {
+		out.WriteString("if ! command -v python3 >/dev/null 2>&1; then\n")
+		out.WriteString("  echo \"python3 not found on PATH; it edits Claude's settings and key bindings\" >&2\n")
+		out.WriteString("  exit 1\n")
+		out.WriteString("fi\n")
+	}
+
+	var removals []diff.Command
+	for _, cmd := range commands {
+		if cmd.Removal {
+			removals = append(removals, cmd)
+			continue
+		}
+		fmt.Fprintf(&out, "\n# %s\n%s\n", cmd.Description, shellCommand(cmd))
+	}
+
+	for _, e := range edits {
+		changes, err := json.Marshal(e.Changes)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode %s changes: %w", e.File, err)
+		}
+		fmt.Fprintf(&out, "\n# %s\npython3 - %s/%s %s <<'EOF'\n%sEOF\n",
+			e.Description, scriptClaudeDir, shellQuote(e.File), shellQuote(string(changes)), e.Program)
+	}
+
+	if len(removals) == 0 {
+		return out.String(), nil
+	}
+
+	out.WriteString("\n# Installed but not in the Clewfile. Sync leaves these alone; uncomment\n")
+	out.WriteString("# to remove them.\n")
+	for _, cmd := range removals {
+		fmt.Fprintf(&out, "# %s\n", shellCommand(cmd))
+	}
+	return out.String(), nil
+}
+
+// shellCommand returns cmd with each argument quoted for sh where needed.
+func shellCommand(cmd diff.Command) string {
+	if cmd.Args == nil {
+		return cmd.Command
+	}
+	words := make([]string, len(cmd.Args))
+	for i, arg := range cmd.Args {
+		words[i] = shellQuote(arg)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/state"
+	"github.com/adamancini/clew/internal/sync"
+)
+
+func TestFormatScript(t *testing.T) {
+	r := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "team", Action: diff.ActionAdd, Desired: &config.Marketplace{Repo: "https://git.example.com/plugins.git?ref=a&b"}},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "review@team", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "review@team"}},
+			{Name: "scratch@team", Action: diff.ActionRemove, Current: &state.PluginState{}},
+		},
+	}
+
+	commands, edits := scriptSteps(r, SyncOptions{})
+	script, err := formatScript(commands, edits, []string{"Generated for a test."})
+	if err != nil {
+		t.Fatalf("formatScript() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"#!/bin/sh\n# Generated for a test.\nset -eu\n",
+		"# Add marketplace: team\nclaude plugin marketplace add 'https://git.example.com/plugins.git?ref=a&b'\n",
+		"# Install plugin: review@team\nclaude plugin install review@team\n",
+		"# claude plugin uninstall scratch@team\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "\nclaude plugin uninstall") {
+		t.Errorf("script runs a removal:\n%s", script)
+	}
+	if strings.Contains(script, "python3") {
+		t.Errorf("script without file edits needs python3:\n%s", script)
+	}
+}
+
+func TestScriptStepsEditsFiles(t *testing.T) {
+	disabled := false
+	r := &diff.Result{
+		Plugins: []diff.PluginDiff{
+			{Name: "review@team", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "review@team", Enabled: &disabled}},
+			{Name: "lint@team", Action: diff.ActionEnable, Current: &state.PluginState{EnabledSource: state.SettingsLocalFile}},
+			{Name: "docs@team", Action: diff.ActionDisable, Current: &state.PluginState{}},
+		},
+		Keybindings: []diff.KeybindingDiff{
+			{Context: "Chat", Key: "ctrl+e", Action: diff.ActionUpdate, Desired: "chat:submit"},
+			{Context: "Global", Key: "ctrl+t", Action: diff.ActionAdd},
+		},
+	}
+
+	// Without --direct-settings only settings.local.json is edited directly
+	commands, edits := scriptSteps(r, SyncOptions{SettingsTarget: sync.SettingsTargetAuto})
+	script, err := formatScript(commands, edits, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"\nclaude plugin disable review@team\n",
+		"\nclaude plugin disable docs@team\n",
+		"# Set enabledPlugins in settings.local.json: lint@team=true\n",
+		"# Set key bindings: Chat ctrl+e, Global ctrl+t\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "claude plugin enable lint@team") {
+		t.Errorf("script enables a plugin whose state comes from settings.local.json through claude:\n%s", script)
+	}
+
+	// With it every enable/disable change is a settings edit
+	commands, edits = scriptSteps(r, SyncOptions{SettingsTarget: sync.SettingsTargetAuto, DirectSettings: true})
+	script, err = formatScript(commands, edits, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(script, "claude plugin disable") {
+		t.Errorf("script disables through claude with --direct-settings:\n%s", script)
+	}
+	if !strings.Contains(script, "# Set enabledPlugins in settings.json: docs@team=false, review@team=false\n") {
+		t.Errorf("settings.json edit missing:\n%s", script)
+	}
+}
+
+func TestFormatScriptEditsRun(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not available")
+	}
+	claudeDir := t.TempDir()
+	settings := `{"model": "opus", "enabledPlugins": {"lint@team": true}, "cleanupPeriodDays": 30}`
+	if err := os.WriteFile(filepath.Join(claudeDir, state.SettingsFile), []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &diff.Result{
+		Plugins: []diff.PluginDiff{
+			{Name: "docs@team", Action: diff.ActionDisable, Current: &state.PluginState{}},
+		},
+		Keybindings: []diff.KeybindingDiff{
+			{Context: "Chat", Key: "ctrl+e", Action: diff.ActionAdd, Desired: "chat:submit"},
+			{Context: "Global", Key: "ctrl+t", Action: diff.ActionAdd},
+		},
+	}
+	_, edits := scriptSteps(r, SyncOptions{DirectSettings: true})
+	script, err := formatScript(nil, edits, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Run only the edits; the script would stop without claude on PATH
+	script = script[strings.Index(script, "\n# Set "):]
+
+	cmd := exec.Command("sh", "-eu")
+	cmd.Stdin = strings.NewReader(script)
+	cmd.Env = append(os.Environ(), "CLAUDE_CONFIG_DIR="+claudeDir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("script failed: %v\n%s\n%s", err, out, script)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(claudeDir, state.SettingsFile))
+	want := "{\n  \"model\": \"opus\",\n  \"enabledPlugins\": {\n    \"lint@team\": true,\n    \"docs@team\": false\n  },\n  \"cleanupPeriodDays\": 30\n}\n"
+	if string(data) != want {
+		t.Errorf("settings.json =\n%s\nwant\n%s", data, want)
+	}
+
+	bindings, err := state.ReadKeybindings(filepath.Join(claudeDir, state.KeybindingsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bindings["Chat"]["ctrl+e"] != "chat:submit" {
+		t.Errorf("keybindings = %v, want Chat ctrl+e bound", bindings)
+	}
+	if action, ok := bindings["Global"]["ctrl+t"]; !ok || action != "" {
+		t.Errorf("keybindings = %v, want Global ctrl+t unbound", bindings)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"context7@official":  "context7@official",
+		"owner/repo":         "owner/repo",
+		"":                   "''",
+		"has space":          "'has space'",
+		"it's":               `'it'\''s'`,
+		"$(rm -rf ~)":        "'$(rm -rf ~)'",
+		"git@github.com:o/r": "git@github.com:o/r",
+	}
+	for in, want := range tests {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/catalog"
+	"github.com/adamancini/clew/internal/github"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// PluginInfo is a marketplace listing combined with local install state and
+// repository metadata.
+type PluginInfo struct {
+	catalog.Entry `yaml:",inline"`
+
+	Installed        bool   `json:"installed" yaml:"installed"`
+	Enabled          bool   `json:"enabled" yaml:"enabled"`
+	InstalledVersion string `json:"installed_version,omitempty" yaml:"installed_version,omitempty"`
+	Scope            string `json:"scope,omitempty" yaml:"scope,omitempty"`
+
+	Repository *github.Repository `json:"repository,omitempty" yaml:"repository,omitempty"`
+}
+
+func newSearchCmd() *cobra.Command {
+	var noMetadata bool
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search plugins in installed marketplaces",
+		Long: `Search lists plugins from your installed marketplaces whose name,
+description, category or keywords match every word of the query.
+
+Results include repository metadata fetched from GitHub (stars, last push)
+to help judge whether a plugin is maintained. Responses are cached; use
+--no-metadata to skip the lookup entirely.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSearch(strings.Join(args, " "), noMetadata)
+		},
+	}
+
+	cmd.Flags().BoolVar(&noMetadata, "no-metadata", false, "Skip fetching repository metadata from GitHub")
+
+	return cmd
+}
+
+// runSearch searches the marketplace catalogs.
+func runSearch(query string, noMetadata bool) error {
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	matches := catalog.Search(catalog.Load(currentState.Marketplaces), query)
+	results := make([]PluginInfo, 0, len(matches))
+	for _, e := range matches {
+		results = append(results, newPluginInfo(e, currentState))
+	}
+	if !noMetadata {
+		attachRepositories(newMetadataFetcher(), results)
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		return writer.Write(results)
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No plugins match %q.\n", query)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tINSTALLED\tSTARS\tLAST PUSH\tDESCRIPTION")
+	for _, r := range results {
+		installed := "-"
+		if r.Installed {
+			installed = "yes"
+		}
+		stars, pushed := repositoryCells(r.Repository)
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.FullName(), installed, stars, pushed, truncate(r.Description, 60))
+	}
+	return w.Flush()
+}
+
+// newPluginInfo combines a catalog entry with the plugin's install state.
+func newPluginInfo(e catalog.Entry, currentState *state.State) PluginInfo {
+	info := PluginInfo{Entry: e}
+	if p, ok := currentState.Plugins[e.FullName()]; ok {
+		info.Installed = true
+		info.Enabled = p.Enabled
+		info.InstalledVersion = p.Version
+		info.Scope = p.Scope
+	}
+	return info
+}
+
+// metadataFetcher looks up repository metadata, remembering results and
+// reporting the first failure only so a rate limit does not flood stderr.
+type metadataFetcher struct {
+	client *github.Client
+	repos  map[string]*github.Repository
+	warned bool
+}
+
+func newMetadataFetcher() *metadataFetcher {
+	client := github.NewClient(nil, github.DefaultBaseURL).WithToken(github.TokenFromEnv())
+	if dir, err := github.DefaultCacheDir(); err == nil {
+		client = client.WithCache(dir)
+	}
+	return &metadataFetcher{client: client, repos: make(map[string]*github.Repository)}
+}
+
+// fetch returns metadata for repo, or nil if it is unavailable.
+func (f *metadataFetcher) fetch(repo string) *github.Repository {
+	if repo == "" {
+		return nil
+	}
+	if r, ok := f.repos[repo]; ok {
+		return r
+	}
+
+	r, err := f.client.Repository(repo)
+	if err != nil && !f.warned {
+		f.warned = true
+		var rlErr *github.RateLimitError
+		if errors.As(err, &rlErr) {
+			fmt.Fprintf(os.Stderr, "Warning: %v; showing results without repository metadata\n", rlErr)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	f.repos[repo] = r
+	return r
+}
+
+// attachRepositories fills in repository metadata for each result.
+func attachRepositories(f *metadataFetcher, results []PluginInfo) {
+	for i := range results {
+		results[i].Repository = f.fetch(results[i].Repo)
+	}
+}
+
+// repositoryCells formats stars and last push for tables.
+func repositoryCells(r *github.Repository) (stars, pushed string) {
+	if r == nil {
+		return "-", "-"
+	}
+	stars = strconv.Itoa(r.Stars)
+	pushed = "-"
+	if !r.PushedAt.IsZero() {
+		pushed = r.PushedAt.Local().Format("2006-01-02")
+	}
+	if r.Archived {
+		pushed += " (archived)"
+	}
+	return stars, pushed
+}
+
+// truncate shortens s to at most n runes, marking the cut with "...".
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-3]) + "..."
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/interactive"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func newSetupCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "setup",
+		Short: "Set up clew on this machine, step by step",
+		Long: `Setup walks a new user through getting started:
+
+  1. Checks that the claude CLI is installed
+  2. Creates a Clewfile, either exported from the plugins and marketplaces
+     already installed or empty, at --config or in a standard location of
+     your choice
+  3. Checks that the Clewfile loads
+  4. Offers to install shell completions, which edits your shell's rc file
+
+An existing Clewfile is kept and only checked; a repository's project
+Clewfile (.clew/) is never used, since clew installs for the user. Every
+question has a default, shown in brackets, that pressing Enter accepts.
+
+Setup asks its questions on the terminal and refuses to run when stdin is
+not one. --yes accepts every default without asking instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in := io.Reader(os.Stdin)
+			switch {
+			case yes:
+				// At the end of input every question takes its default
+				in = strings.NewReader("")
+			case !interactive.IsTerminal():
+				return fmt.Errorf("setup asks questions, but stdin is not a terminal; run it in one, or pass --yes to accept every default")
+			}
+			return runSetup(cmd.Root(), in, os.Stdout)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Accept every default without asking")
+
+	return cmd
+}
+
+// setupPrompt asks the setup questions on out and reads answers from in.
+// At the end of input every question takes its default.
+type setupPrompt struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints question with its default and returns the trimmed answer, or
+// def when the answer is empty.
+func (p *setupPrompt) ask(question, def string) string {
+	_, _ = fmt.Fprintf(p.out, "%s [%s] ", question, def)
+	answer, err := p.in.ReadString('\n')
+	if err != nil && answer == "" {
+		_, _ = fmt.Fprintln(p.out)
+	}
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return def
+	}
+	return answer
+}
+
+// confirm asks a yes/no question.
+func (p *setupPrompt) confirm(question string, def bool) bool {
+	defAnswer := "y"
+	if !def {
+		defAnswer = "n"
+	}
+	answer := strings.ToLower(p.ask(question+" (y/n)", defAnswer))
+	return answer == "y" || answer == "yes"
+}
+
+// runSetup runs the setup steps, asking on out and reading from in.
+func runSetup(root *cobra.Command, in io.Reader, out io.Writer) error {
+	p := &setupPrompt{in: bufio.NewReader(in), out: out}
+
+	_, _ = fmt.Fprintln(out, "Setting up clew. Press Enter to accept the answer in brackets.")
+
+	_, _ = fmt.Fprintln(out, "\nClaude Code")
+	if path, version := detectClaude(); path == "" {
+		_, _ = fmt.Fprintln(out, "  claude was not found on PATH. Install Claude Code before running clew sync;")
+		_, _ = fmt.Fprintln(out, "  the rest of setup works without it.")
+	} else if version == "" {
+		_, _ = fmt.Fprintf(out, "  Found %s\n", path)
+	} else {
+		_, _ = fmt.Fprintf(out, "  Found %s (%s)\n", path, version)
+	}
+
+	_, _ = fmt.Fprintln(out, "\nClewfile")
+	clewfilePath, err := setupClewfile()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(clewfilePath); err == nil {
+		_, _ = fmt.Fprintf(out, "  Using the existing Clewfile at %s\n", clewfilePath)
+	} else if clewfilePath, err = createClewfile(p, clewfilePath); err != nil {
+		return err
+	}
+	if _, err := config.Load(clewfilePath); err != nil {
+		_, _ = fmt.Fprintf(out, "  Warning: %s does not load: %v\n", clewfilePath, err)
+	} else {
+		_, _ = fmt.Fprintf(out, "  ✓ %s is valid\n", clewfilePath)
+	}
+
+	_, _ = fmt.Fprintln(out, "\nShell completions")
+	if shell, err := detectShell(); err != nil {
+		_, _ = fmt.Fprintf(out, "  Skipped: %v\n  Run 'clew completion install <shell>' later.\n", err)
+	} else if p.confirm(fmt.Sprintf("  Install %s completions?", shell), true) {
+		if err := runCompletionInstall(root, shell, false); err != nil {
+			_, _ = fmt.Fprintf(out, "  Warning: %v\n", err)
+		}
+	}
+
+	_, _ = fmt.Fprintln(out, "\nSetup complete. Next:")
+	_, _ = fmt.Fprintln(out, "  clew diff    # preview what sync would change")
+	_, _ = fmt.Fprintln(out, "  clew sync    # apply the Clewfile")
+	return nil
+}
+
+// setupClewfile returns the Clewfile setup works on: the --config path,
+// whether or not it exists yet, or else the Clewfile FindClewfile finds,
+// from CLEWFILE or the standard locations. It returns "" when there is
+// none, for createClewfile to ask where one goes. --project is ignored, and
+// so is CLEWFILE when it names a repository's project Clewfile.
+func setupClewfile() (string, error) {
+	if configPath != "" && !useProject {
+		return configPath, nil
+	}
+	if useProject {
+		logging.Decisionf("Ignoring --project: setup configures clew for the user")
+	}
+	path, err := config.FindClewfile("")
+	if err != nil || isProjectClewfile(path) {
+		return "", nil
+	}
+	return path, nil
+}
+
+// isProjectClewfile reports whether path is the Clewfile in a repository's
+// project directory.
+func isProjectClewfile(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	root, ok := config.RepoRoot(filepath.Dir(abs))
+	return ok && filepath.Dir(abs) == filepath.Join(root, config.ProjectDir)
+}
+
+// createClewfile asks what the new Clewfile should contain and, unless
+// path is set, where it goes, writes it, and returns its path.
+func createClewfile(p *setupPrompt, path string) (string, error) {
+	exported := &ExportedClewfile{Version: 1}
+	if p.confirm("  No Clewfile found. Start from the plugins and marketplaces installed now?", true) {
+		current, err := exportCurrentState()
+		if err != nil {
+			_, _ = fmt.Fprintf(p.out, "  Warning: %v; starting with an empty Clewfile\n", err)
+		} else {
+			exported = current
+		}
+	}
+
+	if path == "" {
+		locations, err := setupLocations()
+		if err != nil {
+			return "", err
+		}
+		_, _ = fmt.Fprintln(p.out, "  Where should it go?")
+		for i, location := range locations {
+			_, _ = fmt.Fprintf(p.out, "    %d) %s\n", i+1, location)
+		}
+		for path == "" {
+			n, err := strconv.Atoi(p.ask("  Location", "1"))
+			if err != nil || n < 1 || n > len(locations) {
+				_, _ = fmt.Fprintf(p.out, "  Enter a number from 1 to %d.\n", len(locations))
+				continue
+			}
+			path = locations[n-1]
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := output.NewWriter(&buf, output.FormatYAML).Write(exported); err != nil {
+		return "", fmt.Errorf("failed to render Clewfile: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := atomicfile.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write Clewfile: %w", err)
+	}
+	_, _ = fmt.Fprintf(p.out, "  Wrote %s (%d marketplaces, %d plugins)\n", path, len(exported.Marketplaces), len(exported.Plugins))
+	return path, nil
+}
+
+// setupLocations returns the standard Clewfile locations setup offers, in
+// the order FindClewfile searches them when CLEWFILE names no Clewfile.
+func setupLocations() ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	clewfileDir, err := defaultClewfileDir()
+	if err != nil {
+		return nil, err
+	}
+	claudeDir, err := state.DefaultClaudeDir()
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		filepath.Join(clewfileDir, "Clewfile.yaml"),
+		filepath.Join(claudeDir, "Clewfile.yaml"),
+		filepath.Join(home, ".Clewfile.yaml"),
+	}, nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRunSetup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	t.Setenv("CLEWFILE", "")
+	t.Setenv("SHELL", "/bin/bash")
+	t.Chdir(home)
+	savedQuiet := quiet
+	quiet = true
+	t.Cleanup(func() { quiet = savedQuiet })
+
+	// Start empty, pick an out-of-range location first, then ~/.claude,
+	// and install completions
+	in := strings.NewReader("n\n7\n2\ny\n")
+	var out bytes.Buffer
+	if err := runSetup(&cobra.Command{Use: "clew"}, in, &out); err != nil {
+		t.Fatalf("runSetup() error = %v\n%s", err, out.String())
+	}
+
+	path := filepath.Join(home, ".claude", "Clewfile.yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Clewfile not written: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(string(data), "version: 1") {
+		t.Errorf("Clewfile = %q, want version 1", data)
+	}
+	if !strings.Contains(out.String(), "Enter a number from 1 to 3") {
+		t.Errorf("invalid location not rejected:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "✓ "+path+" is valid") {
+		t.Errorf("Clewfile not checked:\n%s", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(home, ".local", "share", "bash-completion", "completions", "clew")); err != nil {
+		t.Errorf("completions not installed: %v", err)
+	}
+
+	// A second run keeps the Clewfile and takes every default at end of input
+	out.Reset()
+	if err := runSetup(&cobra.Command{Use: "clew"}, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("second runSetup() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Using the existing Clewfile at "+path) {
+		t.Errorf("existing Clewfile not reused:\n%s", out.String())
+	}
+}
+
+func TestRunSetupConfigPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	t.Setenv("SHELL", "")
+	savedQuiet, savedConfig, savedProject := quiet, configPath, useProject
+	t.Cleanup(func() { quiet, configPath, useProject = savedQuiet, savedConfig, savedProject })
+	quiet = true
+
+	// A project Clewfile, from CLEWFILE or --project, is not the user's
+	repo := filepath.Join(home, "repo")
+	project := filepath.Join(repo, ".clew", "Clewfile.yaml")
+	if err := os.MkdirAll(filepath.Dir(project), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(project, []byte("version: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CLEWFILE", project)
+	t.Chdir(repo)
+	configPath, useProject = project, true
+	if path, err := setupClewfile(); err != nil || path != "" {
+		t.Errorf("setupClewfile() = %q, %v; want no Clewfile", path, err)
+	}
+
+	// --config names the Clewfile to create, without asking where
+	configPath, useProject = filepath.Join(home, "dotfiles", "Clewfile.yaml"), false
+	var out bytes.Buffer
+	if err := runSetup(&cobra.Command{Use: "clew"}, strings.NewReader("n\n"), &out); err != nil {
+		t.Fatalf("runSetup() error = %v\n%s", err, out.String())
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		t.Errorf("Clewfile not written at --config: %v\n%s", err, out.String())
+	}
+	if strings.Contains(out.String(), "Where should it go?") {
+		t.Errorf("location asked despite --config:\n%s", out.String())
+	}
+}
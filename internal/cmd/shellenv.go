@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// shellEnv is what clew shellenv exports.
+type shellEnv struct {
+	Clew      string // Path of the clew binary, for loading completions
+	Clewfile  string // Empty if no Clewfile was found
+	ClaudeDir string
+}
+
+func newShellenvCmd() *cobra.Command {
+	var noCompletion bool
+
+	cmd := &cobra.Command{
+		Use:   "shellenv [bash|zsh|fish]",
+		Short: "Print shell commands that set up clew's environment",
+		Long: `Shellenv prints commands for your shell that export CLEWFILE (the Clewfile
+clew would use) and CLAUDE_CONFIG_DIR (the Claude directory it manages, which
+claude reads as well), and load clew's shell completions. Evaluate it from your shell's startup file:
+
+  bash  (~/.bashrc)                   eval "$(clew shellenv bash)"
+  zsh   (~/.zshrc, after compinit)    eval "$(clew shellenv zsh)"
+  fish  (~/.config/fish/config.fish)  clew shellenv fish | source
+
+With no argument the shell is taken from $SHELL. CLEWFILE is only exported
+when a Clewfile is found, so later runs keep using the same one even from
+another directory.`,
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shell := ""
+			if len(args) > 0 {
+				shell = args[0]
+			}
+			return runShellenv(shell, noCompletion)
+		},
+	}
+
+	cmd.Flags().BoolVar(&noCompletion, "no-completion", false, "Do not load shell completions")
+
+	return cmd
+}
+
+func runShellenv(shell string, noCompletion bool) error {
+	if shell == "" {
+		var err error
+		if shell, err = detectShell(); err != nil {
+			return err
+		}
+	}
+
+	claudeDir, err := state.DefaultClaudeDir()
+	if err != nil {
+		return err
+	}
+	env := shellEnv{ClaudeDir: claudeDir}
+
+	// A missing Clewfile is fine: the snippet just leaves CLEWFILE unset
+	if path, err := config.FindClewfile(configPath); err == nil {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		env.Clewfile = path
+	}
+	if !noCompletion {
+		if env.Clew, err = os.Executable(); err != nil {
+			env.Clew = "clew"
+		}
+	}
+
+	printShellenv(os.Stdout, shell, env)
+	return nil
+}
+
+// detectShell returns the shell named by $SHELL, if clew supports it.
+func detectShell() (string, error) {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	if shell != "bash" && shell != "zsh" && shell != "fish" {
+		return "", fmt.Errorf("cannot detect shell from SHELL=%q; pass bash, zsh or fish", os.Getenv("SHELL"))
+	}
+	return shell, nil
+}
+
+// printShellenv writes the snippet for shell. Completions are skipped when
+// env.Clew is empty.
+func printShellenv(out io.Writer, shell string, env shellEnv) {
+	if shell == "fish" {
+		if env.Clewfile != "" {
+			_, _ = fmt.Fprintf(out, "set -gx CLEWFILE %s;\n", fishQuote(env.Clewfile))
+		}
+		_, _ = fmt.Fprintf(out, "set -gx CLAUDE_CONFIG_DIR %s;\n", fishQuote(env.ClaudeDir))
+		if env.Clew != "" {
+			_, _ = fmt.Fprintf(out, "%s completion fish | source;\n", fishQuote(env.Clew))
+		}
+		return
+	}
+
+	if env.Clewfile != "" {
+		_, _ = fmt.Fprintf(out, "export CLEWFILE=%s;\n", shellQuote(env.Clewfile))
+	}
+	_, _ = fmt.Fprintf(out, "export CLAUDE_CONFIG_DIR=%s;\n", shellQuote(env.ClaudeDir))
+	if env.Clew == "" {
+		return
+	}
+	switch shell {
+	case "bash":
+		_, _ = fmt.Fprintf(out, "source <(%s completion bash);\n", shellQuote(env.Clew))
+	case "zsh":
+		// The completion script calls compdef, which compinit defines
+		_, _ = fmt.Fprintf(out, "(( $+functions[compdef] )) && source <(%s completion zsh);\n", shellQuote(env.Clew))
+	}
+}
+
+// shellQuote quotes s for sh, bash and zsh unless it only contains
+// characters that are never special.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s for fish, where backslash and quote are escaped
+// inside single quotes.
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintShellenv(t *testing.T) {
+	env := shellEnv{
+		Clew:      "/usr/local/bin/clew",
+		Clewfile:  "/home/me/it's/Clewfile.yaml",
+		ClaudeDir: "/home/me/.claude",
+	}
+
+	tests := []struct {
+		shell string
+		env   shellEnv
+		want  string
+	}{
+		{"bash", env, `export CLEWFILE='/home/me/it'\''s/Clewfile.yaml';
+export CLAUDE_CONFIG_DIR=/home/me/.claude;
+source <(/usr/local/bin/clew completion bash);
+`},
+		{"zsh", env, `export CLEWFILE='/home/me/it'\''s/Clewfile.yaml';
+export CLAUDE_CONFIG_DIR=/home/me/.claude;
+(( $+functions[compdef] )) && source <(/usr/local/bin/clew completion zsh);
+`},
+		{"fish", env, `set -gx CLEWFILE '/home/me/it\'s/Clewfile.yaml';
+set -gx CLAUDE_CONFIG_DIR '/home/me/.claude';
+'/usr/local/bin/clew' completion fish | source;
+`},
+		// No Clewfile found, --no-completion
+		{"bash", shellEnv{ClaudeDir: "/home/me/.claude"}, `export CLAUDE_CONFIG_DIR=/home/me/.claude;
+`},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		printShellenv(&buf, tt.shell, tt.env)
+		if buf.String() != tt.want {
+			t.Errorf("printShellenv(%s) =\n%s\nwant:\n%s", tt.shell, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestRunShellenvUnknownShell(t *testing.T) {
+	t.Setenv("SHELL", "/bin/tcsh")
+	if err := runShellenv("", false); err == nil {
+		t.Error("runShellenv() should fail when $SHELL is not supported")
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/snooze"
+)
+
+func newSnoozeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snooze",
+		Short: "Stop reporting a plugin or marketplace for a while",
+		Long: `Snooze leaves a plugin or marketplace out of diff, status and sync until
+the snooze expires: a plugin that fails to install is not retried, and an
+unmanaged one is not reported. Snoozing a marketplace also snoozes its
+plugins. Snoozes are local to this machine and stored in
+~/.local/state/clew/snoozes.json; to stop managing an item for good, use the
+Clewfile's ignore block instead.
+
+Durations are whole days or weeks (7d, 2w) or Go durations (36h).`,
+		Example: `  clew snooze plugin experimental@team --for 7d
+  clew snooze marketplace flaky-mirror --for 36h
+  clew snooze list
+  clew snooze clear experimental@team`,
+	}
+
+	cmd.AddCommand(newSnoozeItemCmd(snooze.TypePlugin, "plugin <plugin@marketplace>"))
+	cmd.AddCommand(newSnoozeItemCmd(snooze.TypeMarketplace, "marketplace <alias>"))
+	cmd.AddCommand(newSnoozeListCmd())
+	cmd.AddCommand(newSnoozeClearCmd())
+
+	return cmd
+}
+
+func newSnoozeItemCmd(itemType, use string) *cobra.Command {
+	var length string
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: fmt.Sprintf("Snooze a %s", itemType),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSnooze(itemType, args[0], length)
+		},
+	}
+
+	cmd.Flags().StringVar(&length, "for", "7d", "How long to snooze (e.g. 7d, 2w, 36h)")
+
+	return cmd
+}
+
+func newSnoozeListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List snoozed items",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSnoozeList()
+		},
+	}
+}
+
+func newSnoozeClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear [name]",
+		Short: "Remove a snooze, or all of them",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+			return runSnoozeClear(name)
+		},
+	}
+}
+
+// loadSnoozes reads the snooze list, dropping expired snoozes.
+func loadSnoozes() (*snooze.Store, error) {
+	path, err := snooze.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	store, err := snooze.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	store.Prune(time.Now())
+	return store, nil
+}
+
+// runSnooze snoozes a single item.
+func runSnooze(itemType, name, length string) error {
+	d, err := snooze.ParseDuration(length)
+	if err != nil {
+		return err
+	}
+	store, err := loadSnoozes()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	e := store.Add(itemType, name, now.Add(d), now)
+	if err := store.Save(); err != nil {
+		return err
+	}
+	if !quiet {
+		fmt.Printf("Snoozed %s %s until %s\n", e.Type, e.Name, e.Until.Local().Format(output.TimeLayout))
+	}
+	return nil
+}
+
+// runSnoozeList prints the active snoozes.
+func runSnoozeList() error {
+	store, err := loadSnoozes()
+	if err != nil {
+		return err
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format != output.FormatText {
+		entries := store.Entries
+		if entries == nil {
+			entries = []snooze.Entry{}
+		}
+		return output.NewWriter(os.Stdout, format).Write(entries)
+	}
+
+	if len(store.Entries) == 0 {
+		fmt.Println("Nothing is snoozed.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "Type\tName\tUntil")
+	for _, e := range store.Entries {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", e.Type, e.Name, output.Timestamp(e.Until, verbose))
+	}
+	return w.Flush()
+}
+
+// runSnoozeClear removes the snoozes of name, or every snooze if name is
+// empty.
+func runSnoozeClear(name string) error {
+	store, err := loadSnoozes()
+	if err != nil {
+		return err
+	}
+
+	cleared := len(store.Entries)
+	if name != "" {
+		cleared = len(store.Remove("", name))
+		if cleared == 0 {
+			return fmt.Errorf("%s is not snoozed", name)
+		}
+	} else {
+		store.Entries = nil
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	if !quiet {
+		fmt.Println(i18n.N(i18n.SnoozeCleared, cleared))
+	}
+	return nil
+}
+
+// applySnoozes leaves snoozed items out of result. A snooze list that cannot
+// be read is reported with -v and otherwise ignored, so it never blocks a
+// sync.
+func applySnoozes(result *diff.Result) {
+	store, err := loadSnoozes()
+	if err != nil {
+		logging.Decisionf("Ignoring snoozes: %v", err)
+		return
+	}
+	for _, e := range store.Filter(result, time.Now()) {
+		logging.Decisionf("Snoozed %s %s until %s", e.Type, e.Name, e.Until.Local().Format(output.TimeLayout))
+	}
+}
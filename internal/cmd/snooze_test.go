@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/adamancini/clew/internal/diff"
+)
+
+func TestSnoozeLifecycle(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	savedQuiet := quiet
+	quiet = true
+	t.Cleanup(func() { quiet = savedQuiet })
+
+	if err := runSnooze("plugin", "broken@official", "soon"); err == nil {
+		t.Error("runSnooze() accepted an invalid duration")
+	}
+	if err := runSnooze("plugin", "broken@official", "7d"); err != nil {
+		t.Fatalf("runSnooze() error = %v", err)
+	}
+
+	result := &diff.Result{
+		Plugins: []diff.PluginDiff{
+			{Name: "broken@official", Action: diff.ActionAdd},
+			{Name: "context7@official", Action: diff.ActionAdd},
+		},
+	}
+	applySnoozes(result)
+	if len(result.Plugins) != 1 || result.Plugins[0].Name != "context7@official" {
+		t.Errorf("Plugins = %+v, want only context7@official", result.Plugins)
+	}
+
+	if err := runSnoozeClear("other@official"); err == nil {
+		t.Error("runSnoozeClear() of an item that is not snoozed should fail")
+	}
+	if err := runSnoozeClear("broken@official"); err != nil {
+		t.Fatalf("runSnoozeClear() error = %v", err)
+	}
+	result.Plugins = append(result.Plugins, diff.PluginDiff{Name: "broken@official", Action: diff.ActionAdd})
+	applySnoozes(result)
+	if len(result.Plugins) != 2 {
+		t.Errorf("Plugins = %+v, want the cleared snooze not to apply", result.Plugins)
+	}
+}
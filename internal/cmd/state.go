@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func newStateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Inspect the Claude Code state clew reads",
+	}
+
+	cmd.AddCommand(newStateDumpCmd())
+
+	return cmd
+}
+
+func newStateDumpCmd() *cobra.Command {
+	var raw bool
+
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Print the Claude Code state as clew reads it",
+		Long: `Dump prints the marketplaces, plugins, settings and key bindings clew read
+from Claude Code's files, along with the layout it recognised in
+known_marketplaces.json and any fields it did not know.
+
+With --raw, each file is printed exactly as it is on disk instead. Use it when
+clew warns that a file has changed layout, to see what Claude now writes.`,
+		Example: `  clew state dump
+  clew state dump --raw`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reader := &state.FilesystemReader{}
+			if raw {
+				return dumpRawState(os.Stdout, reader)
+			}
+			return dumpState(reader)
+		},
+	}
+
+	cmd.Flags().BoolVar(&raw, "raw", false, "Print the state files verbatim instead of parsing them")
+
+	return cmd
+}
+
+// stateDump is the output of clew state dump.
+type stateDump struct {
+	Files              []string                  `json:"files" yaml:"files"`
+	MarketplacesLayout *state.MarketplacesLayout `json:"marketplaces_layout,omitempty" yaml:"marketplaces_layout,omitempty"`
+	State              *state.State              `json:"state" yaml:"state"`
+}
+
+// dumpState prints the state reader parses, as JSON unless YAML was asked
+// for.
+func dumpState(reader *state.FilesystemReader) error {
+	files, err := reader.Files()
+	if err != nil {
+		return err
+	}
+	current, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+	dump := stateDump{Files: files, State: current}
+	if data, err := os.ReadFile(files[0]); err == nil {
+		if _, layout, err := state.DecodeKnownMarketplaces(data); err == nil {
+			dump.MarketplacesLayout = &layout
+		}
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format == output.FormatText {
+		format = output.FormatJSON
+	}
+	return output.NewWriter(os.Stdout, format).Write(dump)
+}
+
+// dumpRawState prints each file reader reads, verbatim, under a header
+// naming it.
+func dumpRawState(out io.Writer, reader *state.FilesystemReader) error {
+	files, err := reader.Files()
+	if err != nil {
+		return err
+	}
+	for i, path := range files {
+		if i > 0 {
+			_, _ = fmt.Fprintln(out)
+		}
+		_, _ = fmt.Fprintf(out, "==> %s <==\n", path)
+		data, err := os.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+			_, _ = fmt.Fprintln(out, "(not found)")
+		case err != nil:
+			_, _ = fmt.Fprintf(out, "(unreadable: %v)\n", err)
+		default:
+			_, _ = out.Write(data)
+			if len(data) > 0 && data[len(data)-1] != '\n' {
+				_, _ = fmt.Fprintln(out)
+			}
+		}
+	}
+	return nil
+}
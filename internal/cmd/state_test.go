@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestDumpRawState(t *testing.T) {
+	claudeDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	marketplaces := `{"version": 3, "marketplaces": []}`
+	if err := os.WriteFile(filepath.Join(claudeDir, "plugins", state.KnownMarketplacesFile), []byte(marketplaces), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := &state.FilesystemReader{ClaudeDir: claudeDir, ManagedSettingsPath: filepath.Join(claudeDir, "managed.json")}
+	var out bytes.Buffer
+	if err := dumpRawState(&out, reader); err != nil {
+		t.Fatalf("dumpRawState() error = %v", err)
+	}
+
+	got := out.String()
+	if !strings.Contains(got, "==> "+filepath.Join(claudeDir, "plugins", state.KnownMarketplacesFile)+" <==\n"+marketplaces+"\n") {
+		t.Errorf("known_marketplaces.json not printed verbatim:\n%s", got)
+	}
+	if !strings.Contains(got, "==> "+filepath.Join(claudeDir, state.SettingsFile)+" <==\n(not found)") {
+		t.Errorf("missing settings.json not reported:\n%s", got)
+	}
+	if !strings.Contains(got, filepath.Join(claudeDir, "managed.json")) {
+		t.Errorf("managed settings path not listed:\n%s", got)
+	}
+}
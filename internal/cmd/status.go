@@ -3,24 +3,100 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/adamancini/clew/internal/config"
 	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/drift"
+	"github.com/adamancini/clew/internal/git"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/mcp"
 	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/stamp"
 	"github.com/adamancini/clew/internal/state"
 )
 
+// StatusOptions configures the status command.
+type StatusOptions struct {
+	Contents bool     // Detect content changes in local plugins
+	Detailed bool     // Include per-plugin rows
+	Columns  []string // Columns shown in the detailed text table
+	Sort     string   // Column the detailed rows are sorted by
+	GroupBy  string   // Nest the detailed rows by this field ("marketplace")
+	Remote   bool     // Check that http/sse MCP servers are reachable
+	Project  string   // Limit MCP servers to user servers and this project's
+	NoFetch  bool     // Compare git repos with their local tracking refs without fetching
+}
+
 func newStatusCmd() *cobra.Command {
-	return &cobra.Command{
+	var opts StatusOptions
+
+	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show sync status summary",
-		Long:  `Status shows a quick summary of the sync state between Clewfile and system.`,
+		Long: `Status shows a quick summary of the sync state between Clewfile and system.
+
+Use --contents to also hash each local plugin's commands, agents and hooks
+and report plugins whose contents changed since clew installed them.
+
+Use --detailed for one row per plugin (status, version, enabled, scope,
+marketplace, last updated, and git state for local plugins). Select and order
+table columns with --columns and sort rows with --sort. Last updated is shown
+relative to now ("2 days ago"); add -v for exact times. JSON and YAML output
+always include every field.
+
+Use --group-by marketplace to nest the detailed rows under their marketplace,
+with per-marketplace counts by status. It implies --detailed.
+
+Status counts the MCP servers declared for the user and for every project
+recorded in ~/.claude.json (local servers there, and the project's
+.mcp.json). Use --project <dir> to only include user servers and that
+project's.
+
+Use --check-remote to also contact each of those http and sse servers, and
+list those that cannot be reached. Unreachable servers are reported
+separately and do not count as drift.
+
+Use --output badge to print shields.io endpoint JSON ("clew: in sync" or
+"drift: 3") for a live drift badge, e.g. published from CI.
+
+Examples:
+  clew status --detailed
+  clew status --detailed --columns plugin,version,updated --sort updated
+  clew status --group-by marketplace
+  clew status --check-remote
+  clew status --check-remote --project .
+  clew status --output badge > badge.json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runStatus()
+			if err := validateStatusColumns(opts.Columns, opts.Sort); err != nil {
+				return err
+			}
+			if err := validateStatusGroupBy(opts.GroupBy); err != nil {
+				return err
+			}
+			if opts.GroupBy != "" {
+				opts.Detailed = true
+			}
+			return runStatus(opts)
 		},
 	}
+
+	cmd.Flags().BoolVar(&opts.Contents, "contents", false, "Detect content changes in local plugins since install")
+	cmd.Flags().BoolVar(&opts.Detailed, "detailed", false, "Show one row per plugin")
+	cmd.Flags().StringSliceVar(&opts.Columns, "columns", defaultStatusColumns, "Columns for --detailed: "+strings.Join(statusColumns, ", "))
+	cmd.Flags().StringVar(&opts.Sort, "sort", "plugin", "Sort --detailed rows by column")
+	cmd.Flags().StringVar(&opts.GroupBy, "group-by", "", "Group --detailed rows by: "+strings.Join(statusGroupings, ", "))
+	cmd.Flags().BoolVar(&opts.Remote, "check-remote", false, "Check that http/sse MCP servers are reachable")
+	cmd.Flags().StringVar(&opts.Project, "project", "", "Only include MCP servers for the user and this project directory")
+	cmd.Flags().BoolVar(&opts.NoFetch, "no-fetch", false, "Report git ahead/behind from local tracking refs without fetching (offline)")
+
+	return cmd
 }
 
 // StatusSummary represents a summary of the sync status.
@@ -30,19 +106,78 @@ type StatusSummary struct {
 	Update    int  `json:"update" yaml:"update"`
 	Remove    int  `json:"remove" yaml:"remove"`
 	Unmanaged int  `json:"unmanaged" yaml:"unmanaged"`
+
+	// Per-plugin rows (with --detailed)
+	Items []StatusRow `json:"items,omitempty" yaml:"items,omitempty"`
+
+	// Per-plugin rows nested by marketplace (with --group-by marketplace)
+	Groups []StatusGroup `json:"groups,omitempty" yaml:"groups,omitempty"`
+
+	// Local plugins whose contents changed since install (with --contents)
+	ContentChanged []drift.Change `json:"content_changed,omitempty" yaml:"content_changed,omitempty"`
+
+	// Local plugins whose HEAD commit is not signed by an allowed key (with git signer lists set)
+	Unsigned []UnsignedRepo `json:"unsigned,omitempty" yaml:"unsigned,omitempty"`
+
+	// MCP servers declared for the user and each project
+	MCP []MCPServers `json:"mcp,omitempty" yaml:"mcp,omitempty"`
+
+	// Remote MCP servers that could not be reached (with --check-remote); not drift
+	UnreachableMCP []mcp.Unreachable `json:"unreachable_mcp,omitempty" yaml:"unreachable_mcp,omitempty"`
+
+	// Last sync without failures on this machine, and why it may be out of date
+	LastSync  *stamp.Stamp `json:"last_sync,omitempty" yaml:"last_sync,omitempty"`
+	StaleSync []string     `json:"stale_sync,omitempty" yaml:"stale_sync,omitempty"`
+}
+
+// MCPServers names the MCP servers declared for the user (no project) or
+// for one project.
+type MCPServers struct {
+	Project string   `json:"project,omitempty" yaml:"project,omitempty"`
+	Servers []string `json:"servers" yaml:"servers"`
+}
+
+// UnsignedRepo is a local repository that fails the signature policy.
+type UnsignedRepo struct {
+	Type    string `json:"type" yaml:"type"` // "marketplace" or "plugin"
+	Name    string `json:"name" yaml:"name"`
+	Path    string `json:"path" yaml:"path"`
+	Problem string `json:"problem" yaml:"problem"`
 }
 
 // String implements fmt.Stringer for text output.
 func (s StatusSummary) String() string {
 	if s.InSync {
-		return "In sync"
+		return i18n.T(i18n.StatusInSync)
 	}
-	return fmt.Sprintf("Add: %d, Update: %d, Remove: %d, Unmanaged: %d",
-		s.Add, s.Update, s.Remove, s.Unmanaged)
+	return i18n.T(i18n.StatusSummary, s.Add, s.Update, s.Remove, s.Unmanaged)
+}
+
+// badgeFormat is the status-only output format for shields.io endpoint JSON.
+const badgeFormat = "badge"
+
+// Badge is a shields.io endpoint badge.
+// See https://shields.io/badges/endpoint-badge.
+type Badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// statusBadge summarizes the status as a badge. Drift counts every pending
+// change plus items needing attention, changed local plugin contents and
+// unsigned local repositories.
+func statusBadge(s StatusSummary) Badge {
+	drifted := s.Add + s.Update + s.Remove + s.Unmanaged + len(s.ContentChanged) + len(s.Unsigned)
+	if drifted == 0 {
+		return Badge{SchemaVersion: 1, Label: "clew", Message: "in sync", Color: "brightgreen"}
+	}
+	return Badge{SchemaVersion: 1, Label: "drift", Message: fmt.Sprintf("%d", drifted), Color: "orange"}
 }
 
 // runStatus executes the status workflow.
-func runStatus() error {
+func runStatus(opts StatusOptions) error {
 	// 1. Find Clewfile
 	clewfilePath, err := config.FindClewfile(configPath)
 	if err != nil {
@@ -50,9 +185,7 @@ func runStatus() error {
 		os.Exit(1)
 	}
 
-	if verbose {
-		fmt.Fprintf(os.Stderr, "Using Clewfile: %s\n", clewfilePath)
-	}
+	logging.Decisionf("Using Clewfile: %s", clewfilePath)
 
 	// 2. Load Clewfile
 	clewfile, err := config.Load(clewfilePath)
@@ -63,9 +196,7 @@ func runStatus() error {
 
 	// 3. Infer scope
 	scope := config.InferScope(clewfilePath)
-	if verbose {
-		fmt.Fprintf(os.Stderr, "Inferred scope: %s\n", scope)
-	}
+	logging.Decisionf("Inferred scope: %s", scope)
 
 	// 4. Read current state
 	reader := &state.FilesystemReader{}
@@ -77,6 +208,7 @@ func runStatus() error {
 
 	// 5. Compute diff
 	diffResult := diff.Compute(clewfile, currentState)
+	applySnoozes(diffResult)
 
 	// 6. Get summary counts
 	add, update, remove, attention := diffResult.Summary()
@@ -89,7 +221,54 @@ func runStatus() error {
 		Unmanaged: attention,
 	}
 
+	summary.LastSync, summary.StaleSync = lastSync(clewfilePath, time.Now())
+
+	if opts.Detailed {
+		summary.Items = buildStatusRows(diffResult, clewfile.Git, opts.Columns, opts.Sort, opts.NoFetch)
+		if opts.GroupBy == "marketplace" {
+			summary.Groups = groupStatusRows(diffResult, summary.Items)
+			summary.Items = nil
+		}
+	}
+
+	if opts.Contents {
+		changed, err := detectContentDrift(currentState)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking plugin contents: %v\n", err)
+			os.Exit(1)
+		}
+		summary.ContentChanged = changed
+	}
+
+	if clewfile.Git.RequireSignatures() {
+		checker := git.NewChecker()
+		checker.SetSignaturePolicy(clewfile.Git)
+		summary.Unsigned = checkSignatures(checker, currentState)
+	}
+
+	servers, err := declaredMCP(opts.Project)
+	if err != nil {
+		if opts.Remote {
+			fmt.Fprintf(os.Stderr, "Error checking MCP servers: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: failed to read MCP servers: %v\n", err)
+	}
+	summary.MCP = groupMCPServers(servers)
+	if opts.Remote {
+		summary.UnreachableMCP = mcp.NewChecker(mcp.DefaultTimeout).CheckAll(servers)
+	}
+
 	// 7. Format and display output
+	if outputFormat == badgeFormat {
+		writer := output.NewWriter(os.Stdout, output.FormatJSON)
+		if err := writer.Write(statusBadge(summary)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		return nil
+	}
+
 	format, err := output.ParseFormat(outputFormat)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -98,6 +277,11 @@ func runStatus() error {
 
 	if format == output.FormatText {
 		printStatusText(summary)
+		if opts.GroupBy != "" {
+			printStatusGroups(summary.Groups, opts.Columns)
+		} else if opts.Detailed {
+			printStatusRows(summary.Items, opts.Columns)
+		}
 	} else {
 		writer := output.NewWriter(os.Stdout, format)
 		if err := writer.Write(summary); err != nil {
@@ -109,29 +293,241 @@ func runStatus() error {
 	return nil
 }
 
+// detectContentDrift compares local plugins against the hashes recorded at install.
+func detectContentDrift(currentState *state.State) ([]drift.Change, error) {
+	path, err := drift.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	store, err := drift.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return store.Detect(currentState.Plugins)
+}
+
+// checkSignatures verifies the HEAD commit of every local marketplace and
+// local plugin repository, returning the failures marketplaces first.
+func checkSignatures(checker *git.Checker, currentState *state.State) []UnsignedRepo {
+	var unsigned []UnsignedRepo
+	for alias, m := range currentState.Marketplaces {
+		if m.Repo != "" || m.InstallLocation == "" {
+			continue
+		}
+		if err := checker.VerifySignature(m.InstallLocation); err != nil {
+			unsigned = append(unsigned, UnsignedRepo{Type: "marketplace", Name: alias, Path: m.InstallLocation, Problem: err.Error()})
+		}
+	}
+	for name, p := range currentState.Plugins {
+		if !p.IsLocal || p.InstallPath == "" {
+			continue
+		}
+		if err := checker.VerifySignature(p.InstallPath); err != nil {
+			unsigned = append(unsigned, UnsignedRepo{Type: "plugin", Name: name, Path: p.InstallPath, Problem: err.Error()})
+		}
+	}
+	sort.Slice(unsigned, func(i, j int) bool {
+		if unsigned[i].Type != unsigned[j].Type {
+			return unsigned[i].Type < unsigned[j].Type
+		}
+		return unsigned[i].Name < unsigned[j].Name
+	})
+	return unsigned
+}
+
+// declaredMCP returns the MCP servers declared for the user and every
+// known project, or only for the user and project when it is set.
+func declaredMCP(project string) ([]mcp.Server, error) {
+	configDir, err := state.GlobalConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	servers, err := mcp.DeclaredAll(configDir)
+	if err != nil || project == "" {
+		return servers, err
+	}
+
+	dir, err := filepath.Abs(project)
+	if err != nil {
+		return nil, err
+	}
+	servers = mcp.ForProject(servers, dir)
+	// A project ~/.claude.json does not know may still have an .mcp.json
+	for _, s := range servers {
+		if s.Project == dir {
+			return servers, nil
+		}
+	}
+	return mcp.Declared(configDir, dir)
+}
+
+// groupMCPServers names the servers declared for the user, then for each
+// project. Servers declared both locally and in .mcp.json are listed once.
+func groupMCPServers(servers []mcp.Server) []MCPServers {
+	var groups []MCPServers
+	seen := make(map[string]bool)
+	for _, s := range servers {
+		if len(groups) == 0 || groups[len(groups)-1].Project != s.Project {
+			groups = append(groups, MCPServers{Project: s.Project})
+		}
+		if key := s.Project + "\x00" + s.Name; !seen[key] {
+			seen[key] = true
+			g := &groups[len(groups)-1]
+			g.Servers = append(g.Servers, s.Name)
+		}
+	}
+	return groups
+}
+
 // printStatusText outputs the status summary in human-readable format.
 func printStatusText(summary StatusSummary) {
+	defer printUnreachableMCP(summary.UnreachableMCP)
+	defer printMCPServers(summary.MCP)
+	defer printUnsigned(summary.Unsigned)
+	defer printContentChanges(summary.ContentChanged)
+
 	if summary.InSync {
-		fmt.Println("Status: In sync")
+		fmt.Println(i18n.T(i18n.StatusInSyncLine))
+		printLastSync(summary)
 		return
 	}
 
-	fmt.Println("Status: Out of sync")
+	fmt.Println(i18n.T(i18n.StatusOutOfSyncLine))
+	printLastSync(summary)
 	fmt.Println()
 
 	if summary.Add > 0 {
-		fmt.Printf("  To add:       %d\n", summary.Add)
+		fmt.Println(i18n.T(i18n.StatusToAdd, summary.Add))
 	}
 	if summary.Update > 0 {
-		fmt.Printf("  To update:    %d\n", summary.Update)
+		fmt.Println(i18n.T(i18n.StatusToUpdate, summary.Update))
 	}
 	if summary.Remove > 0 {
-		fmt.Printf("  To remove:    %d\n", summary.Remove)
+		fmt.Println(i18n.T(i18n.StatusToRemove, summary.Remove))
 	}
 	if summary.Unmanaged > 0 {
-		fmt.Printf("  Unmanaged:     %d\n", summary.Unmanaged)
+		fmt.Println(i18n.T(i18n.StatusUnmanaged, summary.Unmanaged))
+	}
+
+	fmt.Println()
+	fmt.Println(i18n.T(i18n.StatusNextSteps))
+}
+
+// lastSync reads the machine's last sync and lists why it may be out of
+// date for the Clewfile at clewfilePath. A stamp that cannot be read is
+// reported with -v and otherwise treated as missing.
+func lastSync(clewfilePath string, now time.Time) (*stamp.Stamp, []string) {
+	path, err := stamp.DefaultPath()
+	if err != nil {
+		return nil, nil
+	}
+	last, err := stamp.Load(path)
+	if err != nil {
+		logging.Decisionf("Ignoring last sync: %v", err)
+		return nil, nil
+	}
+	if last == nil {
+		return nil, nil
+	}
+
+	var stale []string
+	if abs, err := filepath.Abs(clewfilePath); err == nil && abs != last.Clewfile {
+		stale = append(stale, i18n.T(i18n.StatusOtherClewfile, last.Clewfile))
+	} else if last.ClewfileChanged(clewfilePath) {
+		stale = append(stale, i18n.T(i18n.StatusClewfileEdit))
+	}
+	if last.Stale(now) {
+		stale = append(stale, i18n.N(i18n.StatusStaleSync, int(stamp.StaleAfter.Hours()/24)))
+	}
+	return last, stale
+}
+
+// printLastSync prints when the machine last synced, and why that may be
+// out of date.
+func printLastSync(summary StatusSummary) {
+	if summary.LastSync == nil {
+		return
+	}
+	fmt.Printf("Last synced %s from %s@%s\n", output.Timestamp(summary.LastSync.SyncedAt, verbose),
+		filepath.Base(summary.LastSync.Clewfile), summary.LastSync.ShortHash())
+	for _, reason := range summary.StaleSync {
+		fmt.Printf("  Warning: %s\n", reason)
+	}
+}
+
+// printContentChanges lists local plugins whose contents drifted since install.
+func printContentChanges(changes []drift.Change) {
+	if len(changes) == 0 {
+		return
 	}
 
 	fmt.Println()
-	fmt.Println("Run 'clew diff' for details or 'clew sync' to apply changes.")
+	fmt.Println("Plugin contents changed since install:")
+	for _, c := range changes {
+		fmt.Printf("  ~ %s (installed %s)\n", c.Name, c.RecordedAt.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Println("Reinstall these plugins to pick up the changes.")
+}
+
+// printUnsigned lists local repositories that fail the signature policy.
+func printUnsigned(unsigned []UnsignedRepo) {
+	if len(unsigned) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Local repositories without an allowed signature:")
+	for _, u := range unsigned {
+		fmt.Printf("  ! %s %s: %s\n", u.Type, u.Name, u.Problem)
+	}
+	fmt.Println("Sync skips these, and the plugins of these marketplaces, until HEAD is signed")
+	fmt.Println("by a key in the Clewfile's git section.")
+}
+
+// printMCPServers prints how many MCP servers are declared for the user and
+// across projects; -v lists them per project.
+func printMCPServers(groups []MCPServers) {
+	if len(groups) == 0 {
+		return
+	}
+
+	user, inProjects, projects := 0, 0, 0
+	for _, g := range groups {
+		if g.Project == "" {
+			user = len(g.Servers)
+			continue
+		}
+		inProjects += len(g.Servers)
+		projects++
+	}
+	fmt.Println()
+	fmt.Println(i18n.N(i18n.StatusMCPServers, projects, user, inProjects))
+	if !verbose {
+		return
+	}
+	for _, g := range groups {
+		label := g.Project
+		if label == "" {
+			label = "user"
+		}
+		fmt.Printf("  %s: %s\n", label, strings.Join(g.Servers, ", "))
+	}
+}
+
+// printUnreachableMCP lists remote MCP servers that did not answer.
+func printUnreachableMCP(unreachable []mcp.Unreachable) {
+	if len(unreachable) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Unreachable MCP servers:")
+	for _, u := range unreachable {
+		if u.Project != "" {
+			fmt.Printf("  ! %s (%s) in %s: %s\n", u.Name, u.URL, u.Project, u.Problem)
+		} else {
+			fmt.Printf("  ! %s (%s): %s\n", u.Name, u.URL, u.Problem)
+		}
+	}
+	fmt.Println("Claude cannot use these until the endpoints respond; check the URL and your network.")
 }
@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/git"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/output"
+)
+
+// StatusRow is one plugin in the detailed status view.
+type StatusRow struct {
+	Plugin      string `json:"plugin" yaml:"plugin"`
+	Status      string `json:"status" yaml:"status"`
+	Version     string `json:"version,omitempty" yaml:"version,omitempty"`
+	Enabled     bool   `json:"enabled" yaml:"enabled"`
+	Scope       string `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Marketplace string `json:"marketplace,omitempty" yaml:"marketplace,omitempty"`
+	LastUpdated string `json:"last_updated,omitempty" yaml:"last_updated,omitempty"`
+	Git         string `json:"git,omitempty" yaml:"git,omitempty"` // Local plugins only
+}
+
+// statusColumns lists the detailed view columns in their default order.
+var statusColumns = []string{"plugin", "status", "version", "enabled", "scope", "marketplace", "updated", "git"}
+
+// defaultStatusColumns leaves out git, which can be slow (it fetches remotes
+// unless --no-fetch is set).
+var defaultStatusColumns = []string{"plugin", "status", "version", "enabled", "scope", "marketplace", "updated"}
+
+// validateStatusColumns rejects unknown --columns or --sort values.
+func validateStatusColumns(columns []string, sortBy string) error {
+	valid := make(map[string]bool, len(statusColumns))
+	for _, c := range statusColumns {
+		valid[c] = true
+	}
+	for _, c := range columns {
+		if !valid[c] {
+			return fmt.Errorf("unknown column %q (valid: %s)", c, strings.Join(statusColumns, ", "))
+		}
+	}
+	if !valid[sortBy] {
+		return fmt.Errorf("unknown sort column %q (valid: %s)", sortBy, strings.Join(statusColumns, ", "))
+	}
+	return nil
+}
+
+// statusLabel describes a plugin's diff action from the user's point of view.
+func statusLabel(action diff.Action) string {
+	switch action {
+	case diff.ActionNone:
+		return "ok"
+	case diff.ActionAdd:
+		return "missing"
+	case diff.ActionRemove:
+		return "unmanaged"
+	case diff.ActionEnable:
+		return "needs enable"
+	case diff.ActionDisable:
+		return "needs disable"
+	case diff.ActionUpdate:
+		return "needs update"
+	case diff.ActionManaged:
+		return "managed"
+	case diff.ActionBlocked:
+		return "blocked by scan"
+	default:
+		return string(action)
+	}
+}
+
+// buildStatusRows builds one row per plugin from the diff. Git state is only
+// looked up when the git column is shown or sorted on, and without fetching
+// remotes when noFetch is set. A local checkout is expected at the ref its
+// marketplace declares, or on the remote's default branch.
+func buildStatusRows(d *diff.Result, gitConfig config.GitConfig, columns []string, sortBy string, noFetch bool) []StatusRow {
+	withGit := sortBy == "git"
+	for _, c := range columns {
+		if c == "git" {
+			withGit = true
+		}
+	}
+
+	var checker *git.Checker
+	if withGit {
+		checker = git.NewChecker()
+		checker.SetSignaturePolicy(gitConfig)
+		checker.SetFetchInterval(gitConfig.FetchDelay())
+		checker.SetNoFetch(noFetch)
+	}
+
+	refs := make(map[string]string)
+	for _, m := range d.Marketplaces {
+		if m.Desired != nil {
+			refs[m.Alias] = m.Desired.Ref
+		}
+	}
+
+	rows := make([]StatusRow, 0, len(d.Plugins))
+	for _, p := range d.Plugins {
+		row := StatusRow{
+			Plugin: p.Name,
+			Status: statusLabel(p.Action),
+		}
+		if _, marketplace, ok := strings.Cut(p.Name, "@"); ok {
+			row.Marketplace = marketplace
+		}
+
+		if c := p.Current; c != nil {
+			row.Version = c.Version
+			row.Enabled = c.Enabled
+			row.Scope = c.Scope
+			row.LastUpdated = c.LastUpdated
+			if c.IsLocal && checker != nil && c.InstallPath != "" {
+				row.Git = checker.CheckRepositoryAtRef(c.InstallPath, refs[row.Marketplace]).Message
+			}
+		} else if p.Desired != nil {
+			row.Scope = p.Desired.Scope
+		}
+
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := statusCell(rows[i], sortBy), statusCell(rows[j], sortBy)
+		if sortBy == "updated" {
+			// Relative times do not sort; RFC 3339 timestamps do
+			a, b = rows[i].LastUpdated, rows[j].LastUpdated
+		}
+		if a != b {
+			return a < b
+		}
+		return rows[i].Plugin < rows[j].Plugin
+	})
+
+	return rows
+}
+
+// statusCell returns the text shown for a row in the given column.
+func statusCell(r StatusRow, column string) string {
+	switch column {
+	case "plugin":
+		return r.Plugin
+	case "status":
+		return r.Status
+	case "version":
+		return r.Version
+	case "enabled":
+		if r.Status == statusLabel(diff.ActionAdd) {
+			return ""
+		}
+		return fmt.Sprintf("%t", r.Enabled)
+	case "scope":
+		return r.Scope
+	case "marketplace":
+		return r.Marketplace
+	case "updated":
+		if t, err := time.Parse(time.RFC3339, r.LastUpdated); err == nil {
+			return output.Timestamp(t, verbose)
+		}
+		return r.LastUpdated
+	case "git":
+		return r.Git
+	default:
+		return ""
+	}
+}
+
+// printStatusRows prints the detailed rows as a table.
+func printStatusRows(rows []StatusRow, columns []string) {
+	if len(rows) == 0 {
+		return
+	}
+
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, r := range rows {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = statusCell(r, c)
+			if cells[i] == "" {
+				cells[i] = "-"
+			}
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	_ = tw.Flush()
+}
+
+// statusGroupings lists the valid --group-by values.
+var statusGroupings = []string{"marketplace"}
+
+// StatusGroup is one marketplace and its plugins in the grouped status view.
+type StatusGroup struct {
+	Marketplace string         `json:"marketplace" yaml:"marketplace"`
+	Status      string         `json:"status,omitempty" yaml:"status,omitempty"` // The marketplace's own status
+	Counts      map[string]int `json:"counts" yaml:"counts"`                     // Plugins per status
+	Plugins     []StatusRow    `json:"plugins" yaml:"plugins"`
+}
+
+// validateStatusGroupBy rejects unknown --group-by values.
+func validateStatusGroupBy(groupBy string) error {
+	if groupBy == "" || slices.Contains(statusGroupings, groupBy) {
+		return nil
+	}
+	return fmt.Errorf("unknown grouping %q (valid: %s)", groupBy, strings.Join(statusGroupings, ", "))
+}
+
+// groupStatusRows nests rows under their marketplace, keeping their order.
+// Marketplaces in the diff without plugins get an empty group; plugins
+// without a marketplace are grouped last under an empty name.
+func groupStatusRows(d *diff.Result, rows []StatusRow) []StatusGroup {
+	byName := make(map[string]*StatusGroup)
+	group := func(name string) *StatusGroup {
+		g, ok := byName[name]
+		if !ok {
+			g = &StatusGroup{Marketplace: name, Counts: map[string]int{}, Plugins: []StatusRow{}}
+			byName[name] = g
+		}
+		return g
+	}
+
+	for _, m := range d.Marketplaces {
+		group(m.Alias).Status = statusLabel(m.Action)
+	}
+	for _, r := range rows {
+		g := group(r.Marketplace)
+		g.Plugins = append(g.Plugins, r)
+		g.Counts[r.Status]++
+	}
+
+	groups := make([]StatusGroup, 0, len(byName))
+	for _, g := range byName {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i].Marketplace, groups[j].Marketplace
+		if a == "" || b == "" {
+			return b == ""
+		}
+		return a < b
+	})
+	return groups
+}
+
+// statusGroupHeading summarizes a group, e.g.
+// "official: 3 plugins (2 ok, 1 missing)".
+func statusGroupHeading(g StatusGroup) string {
+	name := g.Marketplace
+	if name == "" {
+		name = "(no marketplace)"
+	}
+	if g.Status != "" && g.Status != statusLabel(diff.ActionNone) {
+		name += " [" + g.Status + "]"
+	}
+
+	statuses := make([]string, 0, len(g.Counts))
+	for s := range g.Counts {
+		statuses = append(statuses, s)
+	}
+	sort.Strings(statuses)
+	counts := make([]string, len(statuses))
+	for i, s := range statuses {
+		counts[i] = fmt.Sprintf("%d %s", g.Counts[s], s)
+	}
+
+	heading := i18n.N(i18n.StatusGroupPlugins, len(g.Plugins), name)
+	if len(counts) > 0 {
+		heading += " (" + strings.Join(counts, ", ") + ")"
+	}
+	return heading
+}
+
+// printStatusGroups prints each group's heading followed by its rows. The
+// marketplace column is left out since the heading already names it.
+func printStatusGroups(groups []StatusGroup, columns []string) {
+	if len(groups) == 0 {
+		return
+	}
+	columns = slices.DeleteFunc(slices.Clone(columns), func(c string) bool { return c == "marketplace" })
+
+	// Headings have no tabs, so they do not widen the table's columns
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "  "+strings.ToUpper(strings.Join(columns, "\t")))
+	for _, g := range groups {
+		_, _ = fmt.Fprintln(tw, statusGroupHeading(g))
+		for _, r := range g.Plugins {
+			cells := make([]string, len(columns))
+			for i, c := range columns {
+				cells[i] = statusCell(r, c)
+				if cells[i] == "" {
+					cells[i] = "-"
+				}
+			}
+			_, _ = fmt.Fprintln(tw, "  "+strings.Join(cells, "\t"))
+		}
+	}
+	_ = tw.Flush()
+}
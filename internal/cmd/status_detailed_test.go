@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestBuildStatusRows(t *testing.T) {
+	d := &diff.Result{
+		Plugins: []diff.PluginDiff{
+			{
+				Name:    "zeta@official",
+				Action:  diff.ActionNone,
+				Current: &state.PluginState{Name: "zeta", Version: "1.2.0", Enabled: true, Scope: "user", LastUpdated: "2025-01-02T03:04:05Z"},
+			},
+			{
+				Name:    "alpha@official",
+				Action:  diff.ActionAdd,
+				Desired: &config.Plugin{Name: "alpha@official", Scope: "project"},
+			},
+			{
+				Name:    "beta@other",
+				Action:  diff.ActionEnable,
+				Current: &state.PluginState{Name: "beta", Version: "0.1.0", Scope: "user"},
+			},
+		},
+	}
+
+	rows := buildStatusRows(d, config.GitConfig{}, defaultStatusColumns, "plugin", false)
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+
+	want := []string{"alpha@official", "beta@other", "zeta@official"}
+	for i, name := range want {
+		if rows[i].Plugin != name {
+			t.Errorf("rows[%d].Plugin = %q, want %q", i, rows[i].Plugin, name)
+		}
+	}
+
+	if rows[0].Status != "missing" || rows[0].Scope != "project" || rows[0].Marketplace != "official" {
+		t.Errorf("missing plugin row = %+v", rows[0])
+	}
+	if statusCell(rows[0], "enabled") != "" {
+		t.Errorf("enabled cell for missing plugin = %q, want empty", statusCell(rows[0], "enabled"))
+	}
+	if rows[1].Status != "needs enable" || rows[1].Marketplace != "other" {
+		t.Errorf("enable row = %+v", rows[1])
+	}
+	if rows[2].Version != "1.2.0" || !rows[2].Enabled || rows[2].LastUpdated == "" {
+		t.Errorf("installed row = %+v", rows[2])
+	}
+
+	rows = buildStatusRows(d, config.GitConfig{}, defaultStatusColumns, "status", false)
+	if rows[0].Status != "missing" || rows[1].Status != "needs enable" || rows[2].Status != "ok" {
+		t.Errorf("sort by status = %v, %v, %v", rows[0].Status, rows[1].Status, rows[2].Status)
+	}
+}
+
+func TestBuildStatusRowsSortByUpdated(t *testing.T) {
+	d := &diff.Result{
+		Plugins: []diff.PluginDiff{
+			// "1 day ago" sorts before "3 days ago" as text; the timestamps must win
+			{Name: "recent@m", Current: &state.PluginState{LastUpdated: time.Now().Add(-26 * time.Hour).Format(time.RFC3339)}},
+			{Name: "older@m", Current: &state.PluginState{LastUpdated: time.Now().Add(-3 * 24 * time.Hour).Format(time.RFC3339)}},
+		},
+	}
+
+	rows := buildStatusRows(d, config.GitConfig{}, defaultStatusColumns, "updated", false)
+	if rows[0].Plugin != "older@m" || rows[1].Plugin != "recent@m" {
+		t.Errorf("sort by updated = %s, %s; want older@m first", rows[0].Plugin, rows[1].Plugin)
+	}
+	if got := statusCell(rows[1], "updated"); got != "1 day ago" {
+		t.Errorf("updated cell = %q, want \"1 day ago\"", got)
+	}
+}
+
+func TestValidateStatusColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		sort    string
+		wantErr bool
+	}{
+		{"defaults", defaultStatusColumns, "plugin", false},
+		{"all columns", statusColumns, "git", false},
+		{"unknown column", []string{"plugin", "size"}, "plugin", true},
+		{"unknown sort", []string{"plugin"}, "size", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStatusColumns(tt.columns, tt.sort)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateStatusColumns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGroupStatusRows(t *testing.T) {
+	d := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "official", Action: diff.ActionNone},
+			{Alias: "tools", Action: diff.ActionAdd},
+			{Alias: "empty", Action: diff.ActionNone},
+		},
+	}
+	rows := []StatusRow{
+		{Plugin: "alpha@official", Status: "missing", Marketplace: "official"},
+		{Plugin: "lint@tools", Status: "missing", Marketplace: "tools"},
+		{Plugin: "loose", Status: "unmanaged"},
+		{Plugin: "zeta@official", Status: "ok", Marketplace: "official"},
+	}
+
+	groups := groupStatusRows(d, rows)
+	var names []string
+	for _, g := range groups {
+		names = append(names, g.Marketplace)
+	}
+	if got := strings.Join(names, ","); got != "empty,official,tools," {
+		t.Fatalf("groups = %q, want empty,official,tools and the unnamed group last", got)
+	}
+
+	official := groups[1]
+	if len(official.Plugins) != 2 || official.Plugins[0].Plugin != "alpha@official" {
+		t.Errorf("official plugins = %+v", official.Plugins)
+	}
+	if got := statusGroupHeading(official); got != "official: 2 plugins (1 missing, 1 ok)" {
+		t.Errorf("heading = %q", got)
+	}
+	if got := statusGroupHeading(groups[2]); got != "tools [missing]: 1 plugin (1 missing)" {
+		t.Errorf("heading = %q", got)
+	}
+	if got := statusGroupHeading(groups[3]); got != "(no marketplace): 1 plugin (1 unmanaged)" {
+		t.Errorf("heading = %q", got)
+	}
+	if got := statusGroupHeading(groups[0]); got != "empty: 0 plugins" {
+		t.Errorf("heading = %q", got)
+	}
+
+	if err := validateStatusGroupBy("plugin"); err == nil {
+		t.Error("validateStatusGroupBy() accepted an unknown grouping")
+	}
+}
+
+func TestBuildStatusRowsGitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "--quiet")
+	run("commit", "--quiet", "--allow-empty", "-m", "init")
+	run("checkout", "--quiet", "-b", "experiment")
+
+	d := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{{Alias: "local", Desired: &config.Marketplace{Repo: "acme/local", Ref: "release"}}},
+		Plugins: []diff.PluginDiff{{
+			Name:    "dev@local",
+			Current: &state.PluginState{Name: "dev", IsLocal: true, InstallPath: repo},
+		}},
+	}
+
+	rows := buildStatusRows(d, config.GitConfig{}, []string{"plugin", "git"}, "plugin", true)
+	if want := "on branch experiment, expected release"; !strings.Contains(rows[0].Git, want) {
+		t.Errorf("Git = %q, want %q", rows[0].Git, want)
+	}
+}
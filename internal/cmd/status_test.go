@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/adamancini/clew/internal/drift"
+	"github.com/adamancini/clew/internal/mcp"
+)
+
+func TestStatusBadge(t *testing.T) {
+	tests := []struct {
+		name        string
+		summary     StatusSummary
+		wantLabel   string
+		wantMessage string
+		wantColor   string
+	}{
+		{"in sync", StatusSummary{InSync: true}, "clew", "in sync", "brightgreen"},
+		{"pending changes", StatusSummary{Add: 1, Update: 1, Unmanaged: 1}, "drift", "3", "orange"},
+		{"content changes only", StatusSummary{InSync: true, ContentChanged: []drift.Change{{Name: "local"}}}, "drift", "1", "orange"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := statusBadge(tt.summary)
+			if b.SchemaVersion != 1 || b.Label != tt.wantLabel || b.Message != tt.wantMessage || b.Color != tt.wantColor {
+				t.Errorf("statusBadge() = %+v", b)
+			}
+		})
+	}
+}
+
+func TestStatusBadgeJSON(t *testing.T) {
+	data, err := json.Marshal(statusBadge(StatusSummary{InSync: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"schemaVersion":1,"label":"clew","message":"in sync","color":"brightgreen"}`
+	if string(data) != want {
+		t.Errorf("badge JSON = %s, want %s", data, want)
+	}
+}
+
+func TestGroupMCPServers(t *testing.T) {
+	servers := []mcp.Server{
+		{Name: "gh", Scope: mcp.ScopeUser},
+		{Name: "db", Scope: mcp.ScopeLocal, Project: "/p1"},
+		{Name: "db", Scope: mcp.ScopeProject, Project: "/p1"},
+		{Name: "web", Scope: mcp.ScopeProject, Project: "/p1"},
+		{Name: "docs", Scope: mcp.ScopeProject, Project: "/p2"},
+	}
+
+	got := groupMCPServers(servers)
+	want := []MCPServers{
+		{Servers: []string{"gh"}},
+		{Project: "/p1", Servers: []string{"db", "web"}},
+		{Project: "/p2", Servers: []string{"docs"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupMCPServers() = %+v, want %+v", got, want)
+	}
+}
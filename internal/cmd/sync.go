@@ -9,6 +9,7 @@ import (
 
 	"github.com/adamancini/clew/internal/diff"
 	"github.com/adamancini/clew/internal/git"
+	"github.com/adamancini/clew/internal/i18n"
 	"github.com/adamancini/clew/internal/sync"
 )
 
@@ -20,7 +21,17 @@ func newSyncCmd() *cobra.Command {
 		noBackup        bool
 		short           bool
 		showCommands    bool
+		emitScript      string
 		skipGitCheck    bool
+		skipPreflight   bool
+		ci              bool
+		check           bool
+		showDiff        bool
+		wait            bool
+		timings         bool
+		settingsTarget  string
+		directSettings  bool
+		refresh         bool
 	)
 
 	cmd := &cobra.Command{
@@ -30,47 +41,121 @@ func newSyncCmd() *cobra.Command {
 
 By default, shows executed commands with descriptions and results.
 Use --short for one-line-per-item output format.
-Use --backup to create a backup before making changes (default behavior).
+A backup is created before making changes unless --no-backup is given or
+the Clewfile sets backups.auto: false; --backup creates one regardless.
 
 For local marketplaces and plugins, git status is checked before sync:
 - Uncommitted changes: Warning + skip that repository
 - Behind remote: Info + suggest 'git pull'
 - Ahead of remote: Info + suggest 'git push'
 
-Use --skip-git-check to bypass git status checking.`,
+Use --skip-git-check to bypass git status checking.
+
+Before changing anything, sync runs preflight checks and stops with what to
+fix if one fails: the claude CLI is on PATH and satisfies requires.claude in
+the Clewfile, the hosts of marketplaces to add are reachable, and there is
+enough free disk space. Use --skip-preflight to bypass them.
+
+Use --check to report what would change without making changes, in the style
+of Ansible check mode: prints changed=true/false (or an Ansible-style result
+with -o json) and always exits 0. Add --diff to print per-item before/after.
+
+Use --emit-script <file> to write the commands sync would run to a
+commented shell script instead, for machines where clew cannot be installed:
+review it, then run it with sh. Removals are listed as comments only. Key
+bindings and the settings edits sync makes itself (--direct-settings, or
+settings.local.json) are made with python3.
+
+Use --refresh-marketplaces to run 'claude plugin marketplace update' for the
+Clewfile's marketplaces before installing plugins, so plugins published since
+a marketplace was added can be installed. Marketplaces are updated a few at a
+time; failed updates are retried with backoff and then only warned about.
+
+Use --ci in automation (containers, pipelines): it never prompts, skips the
+backup, uses short output and exits non-zero on any failure.
+
+When operations fail, the actions in the Clewfile's alerts section run so
+unattended machines are not left broken silently: alerts.webhook receives a
+JSON report of the failed operations by POST, and alerts.exec runs through
+sh with the same report on stdin.
+
+Enabled state is read from settings.json and settings.local.json, with
+settings.local.json taking precedence as in Claude. --settings-target selects
+where enable/disable changes go: "auto" (default) edits settings.local.json
+when that is where the current value comes from, "settings" always uses
+'claude plugin enable/disable', and "local" always edits settings.local.json.
+
+Use --direct-settings to write all enable/disable changes with a single edit
+of each settings file after the installs, instead of running one claude
+command per plugin. If settings.json has a layout clew does not recognise,
+its changes fall back to the claude commands.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := sync.ParseSettingsTarget(settingsTarget)
+			if err != nil {
+				return err
+			}
 			// --backup flag takes precedence, --no-backup disables
 			createBackup := doBackup || !noBackup
-			return runSync(strict, interactiveMode, createBackup, short, showCommands, skipGitCheck)
+			if ci {
+				interactiveMode = false
+				createBackup = false
+				short = true
+				strict = true
+			}
+			return runSync(SyncOptions{
+				Strict:        strict,
+				Interactive:   interactiveMode,
+				CreateBackup:  createBackup,
+				ForceBackup:   doBackup && !ci,
+				Short:         short,
+				ShowCommands:  showCommands,
+				EmitScript:    emitScript,
+				SkipGitCheck:  skipGitCheck,
+				SkipPreflight: skipPreflight,
+				Check:         check,
+				Diff:          showDiff,
+				Wait:          wait,
+				Timings:       timings,
+				Refresh:       refresh,
+
+				SettingsTarget: target,
+				DirectSettings: directSettings,
+			})
 		},
 	}
 
 	cmd.Flags().BoolVar(&strict, "strict", false, "Exit non-zero on any failure")
 	cmd.Flags().BoolVarP(&interactiveMode, "interactive", "i", false, "Prompt for confirmation of each change")
-	cmd.Flags().BoolVar(&doBackup, "backup", false, "Create backup before sync (default behavior)")
+	cmd.Flags().BoolVar(&doBackup, "backup", false, "Create backup before sync, even when the Clewfile sets backups.auto: false")
 	cmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creating backup before sync")
 	cmd.Flags().BoolVar(&short, "short", false, "One-line per item output format")
 	cmd.Flags().BoolVar(&showCommands, "show-commands", false, "Output CLI commands instead of executing")
+	cmd.Flags().StringVar(&emitScript, "emit-script", "", "Write the commands to a shell script instead of executing (- for stdout)")
 	cmd.Flags().BoolVar(&skipGitCheck, "skip-git-check", false, "Skip git status checks for local repositories")
+	cmd.Flags().BoolVar(&skipPreflight, "skip-preflight", false, "Skip the claude, network and disk checks before sync")
+	cmd.Flags().BoolVar(&check, "check", false, "Report whether anything would change without making changes")
+	cmd.Flags().BoolVar(&showDiff, "diff", false, "Show per-item before/after state")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for another running clew process instead of failing")
+	cmd.Flags().BoolVar(&timings, "timings", false, "Print a per-phase timing breakdown (included in JSON output)")
+	cmd.Flags().BoolVar(&refresh, "refresh-marketplaces", false, "Update the Clewfile's marketplaces before installing plugins")
+	cmd.Flags().StringVar(&settingsTarget, "settings-target", string(sync.SettingsTargetAuto), "Settings file for enable/disable changes: auto, settings, local")
+	cmd.Flags().BoolVar(&directSettings, "direct-settings", false, "Write enable/disable changes in one settings file edit instead of one claude command each")
+	cmd.Flags().BoolVar(&ci, "ci", false, "Non-interactive automation mode (implies --no-backup --short --strict)")
 
 	return cmd
 }
 
 // runSync executes the sync workflow using the SyncService.
-func runSync(strict bool, interactiveMode bool, createBackup bool, short bool, showCommands bool, skipGitCheck bool) error {
+// Global output flags are filled in from the root command.
+func runSync(opts SyncOptions) error {
 	service := NewSyncService(configPath, clewVersion)
+	ctx, stop := interruptContext()
+	defer stop()
+	service.SetContext(ctx)
 
-	opts := SyncOptions{
-		Strict:       strict,
-		Interactive:  interactiveMode,
-		CreateBackup: createBackup,
-		Short:        short,
-		ShowCommands: showCommands,
-		SkipGitCheck: skipGitCheck,
-		OutputFormat: outputFormat,
-		Verbose:      verbose,
-		Quiet:        quiet,
-	}
+	opts.OutputFormat = outputFormat
+	opts.Verbose = verbose
+	opts.Quiet = quiet
 
 	err := service.Run(opts)
 	if err != nil {
@@ -133,30 +218,40 @@ func printSyncResultVerbose(result *sync.Result) {
 	}
 
 	// Print summary
-	fmt.Println("Summary:")
-	fmt.Printf("  Installed: %d\n", result.Installed)
-	fmt.Printf("  Updated: %d\n", result.Updated)
-	fmt.Printf("  Failed: %d\n", result.Failed)
+	fmt.Println(i18n.T(i18n.SummaryHeader))
+	fmt.Println(i18n.T(i18n.SyncInstalled, result.Installed))
+	fmt.Println(i18n.T(i18n.SyncUpdated, result.Updated))
+	fmt.Println(i18n.T(i18n.SyncFailed, result.Failed))
 
 	if result.Skipped > 0 {
-		fmt.Printf("  Skipped: %d\n", result.Skipped)
+		fmt.Println(i18n.T(i18n.SyncSkipped, result.Skipped))
+	}
+	if result.Aborted != "" {
+		fmt.Println(i18n.T(i18n.SyncAborted, result.Aborted))
+	}
+	if result.Interrupted {
+		fmt.Println(i18n.T(i18n.SyncInterrupted))
+	}
+	if result.NotLoggedIn {
+		fmt.Println(i18n.T(i18n.SyncNotLoggedIn, sync.LoginHint))
 	}
 
 	// TODO: Format git warnings from result.GitWarnings when issue #39 is implemented
 
 	if len(result.Attention) > 0 {
-		fmt.Println("\nUnmanaged items:")
+		fmt.Println("\n" + i18n.T(i18n.UnmanagedItems))
 		for _, item := range result.Attention {
 			fmt.Printf("  - %s\n", item)
 		}
 	}
 
 	if len(result.Errors) > 0 {
-		fmt.Println("\nErrors:")
+		fmt.Println("\n" + i18n.T(i18n.ErrorsHeader))
 		for _, err := range result.Errors {
 			fmt.Printf("  - %v\n", err)
 		}
 	}
+	printRedoHint(result)
 }
 
 // printSyncResultShort outputs a one-line-per-item summary of sync results.
@@ -181,22 +276,33 @@ func printSyncResultShort(result *sync.Result) {
 	// Print summary
 	parts := []string{}
 	if result.Installed > 0 || result.Updated > 0 || result.Failed == 0 {
-		parts = append(parts, fmt.Sprintf("%d installed", result.Installed))
-		parts = append(parts, fmt.Sprintf("%d updated", result.Updated))
+		parts = append(parts, i18n.T(i18n.SyncShortInstalled, result.Installed))
+		parts = append(parts, i18n.T(i18n.SyncShortUpdated, result.Updated))
 	}
 	if result.Failed > 0 {
-		parts = append(parts, fmt.Sprintf("%d failed", result.Failed))
+		parts = append(parts, i18n.T(i18n.SyncShortFailed, result.Failed))
+	}
+	if result.Aborted != "" {
+		parts = append(parts, i18n.T(i18n.SyncShortAborted, result.Aborted))
+	}
+	if result.Interrupted {
+		parts = append(parts, i18n.T(i18n.SyncShortInterrupted))
 	}
-	fmt.Printf("Summary: %s\n", strings.Join(parts, ", "))
+	if result.NotLoggedIn {
+		parts = append(parts, i18n.T(i18n.SyncShortNotLoggedIn))
+	}
+	fmt.Println(i18n.T(i18n.SyncShortSummary, strings.Join(parts, i18n.T(i18n.ListSeparator))))
 
 	// TODO: Format git warnings from result.GitWarnings when issue #39 is implemented
 
 	if len(result.Attention) > 0 {
-		fmt.Println("\nUnmanaged items:")
+		fmt.Println("\n" + i18n.T(i18n.UnmanagedItems))
 		for _, item := range result.Attention {
 			fmt.Printf("  - %s\n", item)
 		}
 	}
+
+	printRedoHint(result)
 }
 
 // capitalizeAction capitalizes the first letter of an action string.
@@ -218,6 +324,7 @@ func filterDiffByGitStatus(d *diff.Result, gitResult *git.CheckResult) *diff.Res
 	filtered := &diff.Result{
 		Marketplaces: make([]diff.MarketplaceDiff, 0, len(d.Marketplaces)),
 		Plugins:      make([]diff.PluginDiff, 0, len(d.Plugins)),
+		Keybindings:  d.Keybindings,
 	}
 
 	// Filter marketplaces - skip those with git issues
@@ -2,30 +2,60 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/adamancini/clew/internal/alert"
+	"github.com/adamancini/clew/internal/atomicfile"
 	"github.com/adamancini/clew/internal/backup"
+	"github.com/adamancini/clew/internal/claudecli"
 	"github.com/adamancini/clew/internal/config"
 	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/drift"
+	"github.com/adamancini/clew/internal/estimate"
 	"github.com/adamancini/clew/internal/git"
+	"github.com/adamancini/clew/internal/i18n"
 	"github.com/adamancini/clew/internal/interactive"
+	"github.com/adamancini/clew/internal/journal"
+	"github.com/adamancini/clew/internal/logging"
 	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/pin"
+	"github.com/adamancini/clew/internal/preflight"
+	"github.com/adamancini/clew/internal/scan"
+	"github.com/adamancini/clew/internal/stamp"
 	"github.com/adamancini/clew/internal/state"
 	"github.com/adamancini/clew/internal/sync"
+	"github.com/adamancini/clew/internal/timing"
 )
 
 // SyncOptions configures sync behavior.
 type SyncOptions struct {
-	Strict       bool   // Exit non-zero on any failure
-	Interactive  bool   // Prompt for confirmation of each change
-	CreateBackup bool   // Create backup before sync
-	Short        bool   // One-line per item output format
-	ShowCommands bool   // Output CLI commands instead of executing
-	SkipGitCheck bool   // Skip git status checks for local repositories
-	OutputFormat string // Output format (text, json, yaml)
-	Verbose      bool   // Verbose output
-	Quiet        bool   // Quiet mode (errors only)
+	Strict         bool                // Exit non-zero on any failure
+	Interactive    bool                // Prompt for confirmation of each change
+	CreateBackup   bool                // Create backup before sync
+	ForceBackup    bool                // Back up even when the Clewfile sets backups.auto: false
+	Short          bool                // One-line per item output format
+	ShowCommands   bool                // Output CLI commands instead of executing
+	SkipGitCheck   bool                // Skip git status checks for local repositories
+	SkipPreflight  bool                // Skip the claude, network and disk checks before executing
+	Check          bool                // Report what would change without mutating (Ansible check mode)
+	Diff           bool                // Print per-item before/after state
+	Wait           bool                // Wait for another clew process to release the lock
+	Timings        bool                // Record and report per-phase durations
+	Refresh        bool                // Update the Clewfile's marketplaces before installing plugins
+	SettingsTarget sync.SettingsTarget // Settings file that receives enable/disable changes
+	DirectSettings bool                // Batch enable/disable changes into one settings file edit
+	OnFailure      string              // Failure policy for entries without one (from the Clewfile)
+	EmitScript     string              // Write the commands to this shell script instead of executing ("-" for stdout)
+	OutputFormat   string              // Output format (text, json, yaml)
+	Verbose        bool                // Verbose output
+	Quiet          bool                // Quiet mode (errors only)
 }
 
 // SyncService orchestrates the sync workflow with proper separation of concerns.
@@ -70,6 +100,16 @@ func NewSyncServiceWithDeps(
 	}
 }
 
+// SetContext makes the service's claude and git commands stop when ctx is
+// cancelled. An interrupted sync skips its remaining items but still
+// records the run.
+func (s *SyncService) SetContext(ctx context.Context) {
+	s.syncer.SetContext(ctx)
+	if s.gitChecker != nil {
+		s.gitChecker.SetContext(ctx)
+	}
+}
+
 // LoadConfiguration finds and loads the Clewfile.
 func (s *SyncService) LoadConfiguration() (*config.Clewfile, string, error) {
 	clewfilePath, err := config.FindClewfile(s.configPath)
@@ -94,9 +134,12 @@ func (s *SyncService) ReadCurrentState() (*state.State, error) {
 	return currentState, nil
 }
 
-// ComputeDiff computes the differences between desired and current state.
+// ComputeDiff computes the differences between desired and current state,
+// leaving out snoozed items.
 func (s *SyncService) ComputeDiff(clewfile *config.Clewfile, currentState *state.State) *diff.Result {
-	return diff.Compute(clewfile, currentState)
+	result := diff.Compute(clewfile, currentState)
+	applySnoozes(result)
+	return result
 }
 
 // IsInSync checks if the system is already in sync with the Clewfile.
@@ -134,33 +177,42 @@ func (s *SyncService) CreateBackup(currentState *state.State) (*backup.Backup, e
 }
 
 // GetUserApproval prompts the user for confirmation in interactive mode.
-// Returns the filtered diff and whether to proceed.
-func (s *SyncService) GetUserApproval(diffResult *diff.Result) (*diff.Result, bool, error) {
+// Returns the filtered diff, the selection made, and whether to proceed.
+func (s *SyncService) GetUserApproval(diffResult *diff.Result) (*diff.Result, *interactive.Selection, bool, error) {
 	if s.prompter == nil {
 		s.prompter = interactive.NewPrompter()
 	}
 
 	if !interactive.IsTerminal() {
-		return diffResult, true, fmt.Errorf("not running in a terminal, falling back to non-interactive mode")
+		return diffResult, nil, true, fmt.Errorf("not running in a terminal, falling back to non-interactive mode")
 	}
 
 	selection, proceed := s.prompter.PromptForSelection(diffResult)
 	if !proceed {
-		return nil, false, nil
+		return nil, nil, false, nil
 	}
 
 	filtered := interactive.FilterDiffBySelection(diffResult, selection)
-	return filtered, true, nil
+	return filtered, selection, true, nil
 }
 
-// ExecuteSync applies the diff to bring the system in line with the Clewfile.
+// ExecuteSync applies the diff to bring the system in line with the Clewfile,
+// checking that each plugin it installs actually landed.
 func (s *SyncService) ExecuteSync(diffResult *diff.Result, opts SyncOptions) (*sync.Result, error) {
-	return s.syncer.Execute(diffResult, sync.Options{
-		Strict:  opts.Strict,
-		Verbose: opts.Verbose,
-		Quiet:   opts.Quiet,
-		Short:   opts.Short,
+	s.syncer.SetInstallVerifier(s.checkInstall)
+	result, err := s.syncer.Execute(diffResult, sync.Options{
+		Strict:         opts.Strict,
+		Verbose:        opts.Verbose,
+		Quiet:          opts.Quiet,
+		Short:          opts.Short,
+		SettingsTarget: opts.SettingsTarget,
+		DirectSettings: opts.DirectSettings,
+		OnFailure:      opts.OnFailure,
 	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // GenerateCommands generates CLI commands without executing them.
@@ -177,31 +229,77 @@ func (s *SyncService) FormatOutput(format output.Format, data interface{}) error
 // Run executes the complete sync workflow.
 // This is the main entry point that orchestrates all the steps.
 func (s *SyncService) Run(opts SyncOptions) error {
+	startedAt := time.Now()
+	var rec *timing.Recorder
+	if opts.Timings {
+		rec = timing.NewRecorder()
+	}
+
 	// 1. Load configuration
+	stop := rec.Track("config load")
 	clewfile, clewfilePath, err := s.LoadConfiguration()
+	stop()
 	if err != nil {
+		s.alertLoadFailure(err)
 		return err
 	}
 
-	if opts.Verbose {
-		fmt.Fprintf(os.Stderr, "Using Clewfile: %s\n", clewfilePath)
-		fmt.Fprintf(os.Stderr, "Inferred scope: %s\n", config.InferScope(clewfilePath))
+	logging.Decisionf("Using Clewfile: %s", clewfilePath)
+	logging.Decisionf("Inferred scope: %s", config.InferScope(clewfilePath))
+
+	// A script holds the desired marketplace repos in plain text
+	if opts.EmitScript != "" && clewfile.Encrypted {
+		return fmt.Errorf("%s is encrypted with SOPS; clew does not write scripts of encrypted Clewfiles, which would hold their decrypted values. Run clew sync instead", clewfilePath)
 	}
 
-	// 2. Read current state
+	// 2. Take the cross-process lock before reading state, so the diff,
+	// backup and changes all work on state no other clew process is
+	// changing. --check, --emit-script and --show-commands change nothing.
+	if !opts.Check && opts.EmitScript == "" && !opts.ShowCommands {
+		l, err := acquireLock("sync", opts.Wait)
+		if err != nil {
+			s.alertFailure(clewfile, clewfilePath, err)
+			return err
+		}
+		defer func() { _ = l.Release() }()
+	}
+
+	// 2a. Read current state
+	stop = rec.Track("state read")
 	currentState, err := s.ReadCurrentState()
+	stop()
 	if err != nil {
+		s.alertFailure(clewfile, clewfilePath, err)
 		return err
 	}
 
 	// 3. Compute diff
+	stop = rec.Track("diff")
 	diffResult := s.ComputeDiff(clewfile, currentState)
+	stop()
+	if clewfile.Scan.BlockScore > 0 {
+		stop = rec.Track("scan")
+		applyScanPolicy(diffResult, scanPendingPlugins(diffResult, currentState, clewfile.Scan))
+		stop()
+	}
+	logDiffDecisions(diffResult)
+
+	// 3a. Handle --check (report only, no mutations)
+	if opts.Check {
+		return s.handleCheck(diffResult, opts)
+	}
+
+	// 3b. Handle --emit-script (commands for running by hand)
+	if opts.EmitScript != "" {
+		return s.handleEmitScript(diffResult, clewfilePath, opts)
+	}
 
 	// 4. Check if already in sync
 	if s.IsInSync(diffResult) {
 		if !opts.Quiet {
 			fmt.Println("Already in sync. Nothing to do.")
 		}
+		s.recordStamp(clewfilePath, &sync.Result{})
 		return nil
 	}
 
@@ -211,8 +309,9 @@ func (s *SyncService) Run(opts SyncOptions) error {
 	}
 
 	// 6. Handle interactive mode
+	var selection *interactive.Selection
 	if opts.Interactive {
-		diffResult, err = s.handleInteractiveMode(diffResult)
+		diffResult, selection, err = s.handleInteractiveMode(diffResult)
 		if err != nil {
 			if !opts.Quiet {
 				fmt.Fprintln(os.Stderr, "Warning: "+err.Error())
@@ -224,26 +323,158 @@ func (s *SyncService) Run(opts SyncOptions) error {
 		}
 	}
 
+	// 6a. Adapt commands to the claude CLI and fail fast if the operations
+	// cannot succeed
+	caps := s.detectCapabilities()
+	s.syncer.SetCapabilities(caps)
+	if !opts.SkipPreflight {
+		stop = rec.Track("preflight")
+		err := s.preflight(clewfile, diffResult, caps)
+		stop()
+		if err != nil {
+			s.alertFailure(clewfile, clewfilePath, err)
+			return err
+		}
+	}
+
 	// 7. Create backup
-	if opts.CreateBackup {
-		s.handleBackup(currentState, opts.Verbose)
+	var backupID string
+	if opts.CreateBackup && !opts.ForceBackup && !clewfile.Backups.AutoEnabled() {
+		logging.Decisionf("Skipping backup: backups.auto is false")
+	} else if opts.CreateBackup {
+		stop = rec.Track("backup")
+		backupID = s.handleBackup(currentState)
+		stop()
 	}
 
 	// 8. Check git status
 	if !opts.SkipGitCheck {
-		diffResult = s.handleGitCheck(clewfile, diffResult, opts.Verbose)
+		stop = rec.Track("git check")
+		diffResult = s.handleGitCheck(clewfile, currentState, diffResult)
+		stop()
+	}
+
+	// Show the running operation on stderr until the sync returns
+	format, _ := output.ParseFormat(opts.OutputFormat)
+	stopProgress := startProgress(s.syncer, !opts.Quiet && format == output.FormatText)
+	defer stopProgress()
+
+	// 8a. Refresh marketplaces so newly published plugins can be installed
+	if opts.Refresh {
+		stop = rec.Track("refresh")
+		s.refreshMarketplaces(clewfile, currentState)
+		stop()
 	}
 
 	// 9. Execute sync
+	if opts.Diff && !opts.Quiet && format == output.FormatText {
+		printItemDiffs(os.Stdout, buildCheckResult(diffResult, true).Diff)
+	}
+	stop = rec.Track("sync")
+	opts.OnFailure = clewfile.OnFailure
 	result, err := s.ExecuteSync(diffResult, opts)
+	stopProgress()
 	if err != nil {
+		if redacted := clewfile.Redact(err.Error()); redacted != err.Error() {
+			err = errors.New(redacted)
+		}
+		s.alertFailure(clewfile, clewfilePath, err)
 		return fmt.Errorf("sync failed: %w", err)
 	}
+	redactResult(clewfile, result)
+	for _, op := range result.Operations {
+		rec.Add(fmt.Sprintf("%s %s %s", op.Type, op.Action, op.Name), op.Duration)
+	}
+	stop()
+	s.verifyPins(clewfile, result, opts)
+	if selection != nil && !result.Interrupted && !result.NotLoggedIn {
+		s.resolveExtras(clewfilePath, clewfile, currentState, selection, result, opts)
+	}
+	s.recordPluginHashes(result)
+	s.recordRun("sync", clewfilePath, backupID, startedAt, result)
+	s.recordStamp(clewfilePath, result)
+	s.sendAlerts(clewfile.Alerts, clewfilePath, result)
+	result.Timings = rec.Phases()
 
 	// 10. Format and display output
 	return s.handleOutput(result, opts)
 }
 
+// refreshMarketplaces updates the Clewfile's marketplaces that are already
+// added. Marketplaces added by this sync are current anyway. A failed
+// refresh only warns: installs then use the marketplace as last fetched.
+func (s *SyncService) refreshMarketplaces(clewfile *config.Clewfile, currentState *state.State) {
+	var aliases []string
+	for alias := range clewfile.Marketplaces {
+		if _, ok := currentState.Marketplaces[alias]; ok {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+
+	for _, op := range s.syncer.RefreshMarketplaces(aliases) {
+		if !op.Success {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", op.Error)
+			continue
+		}
+		logging.Decisionf("Refreshed marketplace %s in %s", op.Name, op.Duration.Round(time.Millisecond))
+	}
+}
+
+// preflight checks that claude, the network and the disk are ready for the
+// operations in the diff.
+func (s *SyncService) preflight(clewfile *config.Clewfile, diffResult *diff.Result, caps *claudecli.Capabilities) error {
+	var repos []string
+	needs := make(map[string]bool)
+	for _, m := range diffResult.Marketplaces {
+		if m.Action == diff.ActionAdd && m.Desired != nil {
+			repos = append(repos, m.Desired.Repo)
+			needs["marketplace"] = true
+		}
+	}
+	for _, p := range diffResult.Plugins {
+		if p.Action == diff.ActionAdd {
+			needs["install"] = true
+		}
+	}
+
+	opts := preflight.Options{
+		Requires:     clewfile.Requires.Claude,
+		Capabilities: caps,
+		Hosts:        preflight.MarketplaceHosts(repos),
+	}
+	for _, need := range []string{"marketplace", "install"} {
+		if needs[need] {
+			opts.Needs = append(opts.Needs, need)
+		}
+	}
+	opts.ClaudePath, opts.ClaudeVersion = detectClaude()
+	if claudeDir, err := state.DefaultClaudeDir(); err == nil {
+		opts.DiskPath = claudeDir
+		if _, err := os.Stat(opts.DiskPath); err != nil {
+			opts.DiskPath = filepath.Dir(claudeDir)
+		}
+	}
+	return preflight.New().Run(opts)
+}
+
+// detectCapabilities probes the claude CLI for supported plugin commands.
+// It returns nil, meaning assume everything, if claude cannot be probed.
+func (s *SyncService) detectCapabilities() *claudecli.Capabilities {
+	cachePath, err := claudecli.DefaultCachePath()
+	if err != nil {
+		return nil
+	}
+	caps, err := claudecli.Detect(&sync.DefaultCommandRunner{}, cachePath)
+	if err != nil {
+		logging.Decisionf("Cannot probe claude capabilities: %v", err)
+		return nil
+	}
+	logging.Decisionf("claude %s: marketplace=%t install=%t install --scope=%t enable/disable=%t",
+		caps.Version, caps.Marketplace, caps.Install, caps.InstallScope, caps.Enable)
+	return caps
+}
+
 // handleShowCommands handles the --show-commands flag.
 func (s *SyncService) handleShowCommands(diffResult *diff.Result, opts SyncOptions) error {
 	commands := s.GenerateCommands(diffResult)
@@ -265,36 +496,184 @@ func (s *SyncService) handleShowCommands(diffResult *diff.Result, opts SyncOptio
 	return nil
 }
 
+// handleEmitScript writes the commands sync would run as a shell script,
+// for machines where clew cannot be installed.
+func (s *SyncService) handleEmitScript(diffResult *diff.Result, clewfilePath string, opts SyncOptions) error {
+	commands, edits := scriptSteps(diffResult, opts)
+	count := len(edits)
+	for _, c := range commands {
+		if !c.Removal {
+			count++
+		}
+	}
+
+	header := []string{
+		fmt.Sprintf("Generated by clew %s from %s at %s.", s.version, clewfilePath, time.Now().Format(output.TimeLayout)),
+		"Runs the commands 'clew sync' would run to match the Clewfile, for machines",
+		"without clew. Review it, then run it with sh.",
+	}
+	if count == 0 {
+		header = append(header, "Already in sync: there is nothing to run.")
+	}
+	script, err := formatScript(commands, edits, header)
+	if err != nil {
+		return err
+	}
+
+	if opts.EmitScript == "-" {
+		fmt.Print(script)
+		return nil
+	}
+	if err := atomicfile.WriteFile(opts.EmitScript, []byte(script), 0755); err != nil {
+		return fmt.Errorf("failed to write script: %w", err)
+	}
+	if !opts.Quiet {
+		fmt.Println(i18n.N(i18n.SyncWroteScript, count, opts.EmitScript))
+	}
+	return nil
+}
+
+// handleCheck reports what sync would change without executing anything.
+func (s *SyncService) handleCheck(diffResult *diff.Result, opts SyncOptions) error {
+	result := buildCheckResult(diffResult, opts.Diff)
+
+	format, err := output.ParseFormat(opts.OutputFormat)
+	if err != nil {
+		return err
+	}
+
+	if format != output.FormatText {
+		return s.FormatOutput(format, result)
+	}
+
+	if opts.Diff {
+		printItemDiffs(os.Stdout, result.Diff)
+	}
+	fmt.Printf("changed=%t\n", result.Changed)
+	if !opts.Quiet {
+		fmt.Println(result.Msg)
+	}
+	return nil
+}
+
 // handleInteractiveMode handles the interactive mode workflow.
-func (s *SyncService) handleInteractiveMode(diffResult *diff.Result) (*diff.Result, error) {
-	filtered, proceed, err := s.GetUserApproval(diffResult)
+func (s *SyncService) handleInteractiveMode(diffResult *diff.Result) (*diff.Result, *interactive.Selection, error) {
+	s.prompter.SetEstimate(loadTimings().Total)
+	filtered, selection, proceed, err := s.GetUserApproval(diffResult)
 	if err != nil {
-		return diffResult, err // Return original diff with warning
+		return diffResult, nil, err // Return original diff with warning
 	}
 	if !proceed {
-		return nil, nil
+		return nil, nil, nil
 	}
-	return filtered, nil
+	return filtered, selection, nil
 }
 
-// handleBackup creates a backup before sync.
-func (s *SyncService) handleBackup(currentState *state.State, verbose bool) {
-	bak, err := s.CreateBackup(currentState)
+// resolveExtras applies what the user chose for items installed but not in
+// the Clewfile: adopted and ignored items are written to the Clewfile, and
+// removed items are uninstalled and reported with the sync result.
+func (s *SyncService) resolveExtras(clewfilePath string, clewfile *config.Clewfile, currentState *state.State, selection *interactive.Selection, result *sync.Result, opts SyncOptions) {
+	edit, skipped := extrasEdit(selection, clewfile, currentState)
+	for _, name := range skipped {
+		result.Attention = append(result.Attention,
+			fmt.Sprintf("plugin (not adopted): %s - its marketplace has no repo to declare; add the marketplace to the Clewfile first", name))
+	}
+	if len(edit.Plugins) > 0 || len(edit.Marketplaces) > 0 || len(edit.Ignore.Marketplaces) > 0 || len(edit.Ignore.Plugins) > 0 {
+		if err := config.ApplyEdit(clewfilePath, edit); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Errorf("failed to update Clewfile: %w", err))
+		} else {
+			logging.Decisionf("Updated %s: adopted %d plugin(s) and %d marketplace(s), ignored %d item(s)",
+				clewfilePath, len(edit.Plugins), len(edit.Marketplaces), len(edit.Ignore.Marketplaces)+len(edit.Ignore.Plugins))
+		}
+	}
+
+	marketplaces, plugins := selection.Extras(interactive.ResolutionRemove)
+	orphans := sync.RemovalOrphans(plugins, marketplaces, clewfile, currentState)
+	kept := marketplaces[:0]
+	for _, alias := range marketplaces {
+		if users, ok := orphans[alias]; ok {
+			result.Attention = append(result.Attention,
+				fmt.Sprintf("marketplace (kept): %s - still used by %s", alias, strings.Join(users, ", ")))
+			continue
+		}
+		kept = append(kept, alias)
+	}
+	marketplaces = kept
+	if len(marketplaces)+len(plugins) == 0 {
+		return
+	}
+	removed, err := s.syncer.Revert(sync.RemovalPlan(plugins, marketplaces), currentState, sync.Options{SettingsTarget: opts.SettingsTarget})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to create backup: %v\n", err)
+		result.Errors = append(result.Errors, err)
 		return
 	}
-	if verbose {
-		fmt.Fprintf(os.Stderr, "Backup created: %s\n", bak.ID)
+	result.Merge(removed)
+}
+
+// extrasEdit builds the Clewfile edit for adopted and ignored extras.
+// Adopted plugins keep their current enabled state and bring along the
+// marketplace they come from. A plugin whose marketplace is neither
+// declared in clewfile nor has a repo to declare it with (a local
+// marketplace) would make the Clewfile invalid; it is left out and
+// returned in skipped.
+func extrasEdit(selection *interactive.Selection, clewfile *config.Clewfile, currentState *state.State) (edit config.Edit, skipped []string) {
+	edit = config.Edit{Marketplaces: make(map[string]config.Marketplace)}
+	adopt := func(alias string) bool {
+		if _, ok := clewfile.Marketplaces[alias]; ok {
+			return true
+		}
+		if m, ok := currentState.Marketplaces[alias]; ok && m.Repo != "" {
+			edit.Marketplaces[alias] = config.Marketplace{Repo: m.Repo, Ref: m.Ref}
+			return true
+		}
+		return false
+	}
+
+	marketplaces, plugins := selection.Extras(interactive.ResolutionAdopt)
+	for _, alias := range marketplaces {
+		adopt(alias)
+	}
+	for _, name := range plugins {
+		p := config.Plugin{Name: name}
+		if cur, ok := currentState.Plugins[name]; ok {
+			if !cur.Enabled {
+				disabled := false
+				p.Enabled = &disabled
+			}
+			if !adopt(cur.Marketplace) {
+				skipped = append(skipped, name)
+				continue
+			}
+		}
+		edit.Plugins = append(edit.Plugins, p)
 	}
+
+	edit.Ignore.Marketplaces, edit.Ignore.Plugins = selection.Extras(interactive.ResolutionIgnore)
+	return edit, skipped
+}
+
+// handleBackup creates a backup before sync and returns its ID, or "" if
+// the backup failed.
+func (s *SyncService) handleBackup(currentState *state.State) string {
+	bak, err := s.CreateBackup(currentState)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create backup: %v\n", err)
+		return ""
+	}
+	logging.Decisionf("Backup created: %s", bak.ID)
+	return bak.ID
 }
 
 // handleGitCheck performs git status checking for local repositories.
-func (s *SyncService) handleGitCheck(clewfile *config.Clewfile, diffResult *diff.Result, verbose bool) *diff.Result {
+func (s *SyncService) handleGitCheck(clewfile *config.Clewfile, currentState *state.State, diffResult *diff.Result) *diff.Result {
 	gitResult := s.ValidateGitStatus(clewfile)
 	if gitResult == nil {
 		return diffResult
 	}
+	if clewfile.Git.RequireSignatures() {
+		skipUnsigned(gitResult, checkSignatures(s.gitChecker, currentState), diffResult)
+	}
 
 	// Display git warnings
 	if gitResult.HasWarnings() {
@@ -305,16 +684,372 @@ func (s *SyncService) handleGitCheck(clewfile *config.Clewfile, diffResult *diff
 	}
 
 	// Display git info (if verbose)
-	if gitResult.HasInfo() && verbose {
-		fmt.Fprintln(os.Stderr, "\nGit Status Info:")
+	if gitResult.HasInfo() && logging.Enabled(logging.LevelDecisions) {
+		logging.Decisionf("\nGit Status Info:")
 		for _, info := range gitResult.Info {
-			fmt.Fprintf(os.Stderr, "  - %s\n", info)
+			logging.Decisionf("  - %s", info)
 		}
 	}
 
 	return s.FilterDiffByGitStatus(diffResult, gitResult)
 }
 
+// skipUnsigned warns about the unsigned local repositories and marks the
+// changes sync would make to them for skipping, including every change to
+// a plugin from an unsigned local marketplace.
+func skipUnsigned(gitResult *git.CheckResult, unsigned []UnsignedRepo, d *diff.Result) {
+	marketplaces := make(map[string]bool)
+	plugins := make(map[string]bool)
+	for _, u := range unsigned {
+		gitResult.Warnings = append(gitResult.Warnings, fmt.Sprintf("%s %s: %s", u.Type, u.Name, u.Problem))
+		if u.Type == "marketplace" {
+			marketplaces[u.Name] = true
+		} else {
+			plugins[u.Name] = true
+		}
+	}
+
+	for _, m := range d.Marketplaces {
+		if marketplaces[m.Alias] && m.Action != diff.ActionNone && m.Action != diff.ActionRemove {
+			gitResult.SkipMarketplaces[m.Alias] = true
+		}
+	}
+	for _, p := range d.Plugins {
+		if p.Action == diff.ActionNone || p.Action == diff.ActionRemove {
+			continue
+		}
+		marketplace := p.Name[strings.LastIndex(p.Name, "@")+1:]
+		if p.Current != nil && p.Current.Marketplace != "" {
+			marketplace = p.Current.Marketplace
+		}
+		if plugins[p.Name] || marketplaces[marketplace] {
+			gitResult.SkipPlugins[p.Name] = true
+		}
+	}
+}
+
+// scanPendingPlugins scans the plugins the diff would install or enable.
+func scanPendingPlugins(d *diff.Result, currentState *state.State, policy config.ScanConfig) []scan.Report {
+	var names []string
+	for _, p := range d.Plugins {
+		if p.Action == diff.ActionAdd || p.Action == diff.ActionEnable {
+			names = append(names, p.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return scan.Plugins(names, currentState, policy)
+}
+
+// applyScanPolicy marks plugins blocked by the scan policy so sync skips
+// them and reports them for review.
+func applyScanPolicy(d *diff.Result, reports []scan.Report) {
+	blocked := make(map[string]int)
+	for _, r := range reports {
+		if r.Blocked {
+			blocked[r.Plugin] = r.Score
+		}
+	}
+	for i, p := range d.Plugins {
+		if score, ok := blocked[p.Name]; ok && (p.Action == diff.ActionAdd || p.Action == diff.ActionEnable) {
+			d.Plugins[i].Action = diff.ActionBlocked
+			logging.Decisionf("Plugin %s blocked by scan policy (score %d)", p.Name, score)
+		}
+	}
+}
+
+// checkInstall reports why the plugin claude just installed as name has no
+// user-scope entry in installed_plugins.json whose install path exists, or
+// nil. An install is not failed when the state cannot be read.
+func (s *SyncService) checkInstall(name string) error {
+	currentState, err := s.ReadCurrentState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cannot verify that plugin %s installed: %v\n", name, err)
+		return nil
+	}
+	return verifyInstall(name, currentState)
+}
+
+// verifyInstall reports why name is not installed at user scope, or nil.
+func verifyInstall(name string, currentState *state.State) error {
+	p, ok := currentState.Plugins[name]
+	if !ok {
+		return fmt.Errorf("plugin %s is missing from installed_plugins.json after install", name)
+	}
+	if p, ok = p.AtScope("user", ""); !ok {
+		return fmt.Errorf("plugin %s has no user-scope install in installed_plugins.json after install", name)
+	}
+	if p.InstallPath == "" {
+		return fmt.Errorf("plugin %s has no install path in installed_plugins.json", name)
+	}
+	if _, err := os.Stat(p.InstallPath); err != nil {
+		return fmt.Errorf("plugin %s install path %s does not exist after install", name, p.InstallPath)
+	}
+	return nil
+}
+
+// verifyPins checks every installed plugin the Clewfile pins against its
+// sha256 and commit pins. A plugin that fails them is uninstalled if this
+// run installed it, and disabled otherwise, so its content never runs.
+func (s *SyncService) verifyPins(clewfile *config.Clewfile, result *sync.Result, opts SyncOptions) {
+	var pinned []config.Plugin
+	for _, p := range clewfile.Plugins {
+		if p.Pinned() {
+			pinned = append(pinned, p)
+		}
+	}
+	if len(pinned) == 0 {
+		return
+	}
+
+	currentState, err := s.ReadCurrentState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to check plugin pins: %v\n", err)
+		return
+	}
+	installed := make(map[string]int)
+	for i, op := range result.Operations {
+		if op.Type == "plugin" && op.Action == "add" && op.Success && !op.Skipped {
+			installed[op.Name] = i
+		}
+	}
+
+	var plan []sync.Operation
+	for _, p := range pinned {
+		cur, ok := currentState.Plugins[p.Name]
+		if !ok {
+			continue
+		}
+		err := pin.Verify(p, cur)
+		if err == nil {
+			logging.Decisionf("Plugin %s matches its pins", p.Name)
+			continue
+		}
+		result.Failed++
+		result.Errors = append(result.Errors, err)
+
+		if i, ok := installed[p.Name]; ok {
+			inv, _ := sync.Inverse(result.Operations[i])
+			plan = append(plan, inv)
+			result.Operations[i].Success = false
+			result.Operations[i].Error = err.Error()
+			result.Installed--
+			result.Attention = append(result.Attention, "plugin (pin): "+p.Name+" - installed content does not match its pins; uninstalled it")
+			continue
+		}
+		if cur.Enabled {
+			inv, _ := sync.Inverse(sync.Operation{Type: "plugin", Name: p.Name, Action: "enable", Success: true})
+			plan = append(plan, inv)
+		}
+		result.Attention = append(result.Attention, "plugin (pin): "+p.Name+" - installed content does not match its pins; disabled it until it is reinstalled")
+	}
+	if len(plan) == 0 {
+		return
+	}
+
+	reverted, err := s.syncer.Revert(plan, currentState, sync.Options{SettingsTarget: opts.SettingsTarget})
+	if err != nil {
+		result.Errors = append(result.Errors, err)
+		return
+	}
+	result.Merge(reverted)
+}
+
+// recordPluginHashes records content hashes for local plugins so status
+// --contents can later tell whether they changed since install. Failures
+// only warn; they must not fail an otherwise successful sync.
+func (s *SyncService) recordPluginHashes(result *sync.Result) {
+	path, err := drift.DefaultPath()
+	if err != nil {
+		return
+	}
+	store, err := drift.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+
+	currentState, err := s.ReadCurrentState()
+	if err != nil {
+		return
+	}
+
+	reinstalled := make(map[string]bool)
+	for _, op := range result.Operations {
+		if op.Type == "plugin" && op.Action == "add" && op.Success && !op.Skipped {
+			reinstalled[op.Name] = true
+		}
+	}
+
+	changed, err := store.Update(currentState.Plugins, reinstalled)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to hash local plugins: %v\n", err)
+		return
+	}
+	if !changed {
+		return
+	}
+	if err := store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save plugin hashes: %v\n", err)
+	}
+}
+
+// redactResult replaces an encrypted Clewfile's secrets in the commands,
+// output and errors of result, before it is shown, recorded in history or
+// sent in alerts.
+func redactResult(clewfile *config.Clewfile, result *sync.Result) {
+	if !clewfile.Encrypted {
+		return
+	}
+	for i := range result.Operations {
+		op := &result.Operations[i]
+		op.Command = clewfile.Redact(op.Command)
+		op.Description = clewfile.Redact(op.Description)
+		op.Error = clewfile.Redact(op.Error)
+		op.Output = clewfile.Redact(op.Output)
+	}
+	for i, item := range result.Attention {
+		result.Attention[i] = clewfile.Redact(item)
+	}
+	for i, err := range result.Errors {
+		if redacted := clewfile.Redact(err.Error()); redacted != err.Error() {
+			result.Errors[i] = errors.New(redacted)
+		}
+	}
+}
+
+// recordRun adds the run to the history journal. Like recordPluginHashes,
+// failures only warn.
+func (s *SyncService) recordRun(command, clewfilePath, backupID string, startedAt time.Time, result *sync.Result) {
+	recordTimings(result)
+
+	j, err := journal.New()
+	if err != nil {
+		return
+	}
+
+	run := journal.NewRun(command, startedAt, result)
+	run.Clewfile = clewfilePath
+	run.BackupID = backupID
+	run.ClewVersion = s.version
+	if err := j.Record(run); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record run history: %v\n", err)
+		return
+	}
+	logging.Decisionf("Run recorded: %s", run.ID)
+}
+
+// recordTimings adds the durations of the operations that ran to the
+// timings plan and interactive mode estimate from. Like recordRun,
+// failures only warn.
+func recordTimings(result *sync.Result) {
+	path, err := estimate.DefaultPath()
+	if err != nil {
+		return
+	}
+	h, err := estimate.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	observed := 0
+	for _, op := range result.Operations {
+		// Skipped and failed operations end early and would skew the average
+		if op.Success && !op.Skipped && op.Duration > 0 {
+			h.Observe(estimate.Kind(op.Type, op.Action), op.Duration)
+			observed++
+		}
+	}
+	if observed == 0 {
+		return
+	}
+	if err := h.Save(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// loadTimings returns the recorded operation timings, or an empty history
+// (estimating from defaults) when they cannot be read.
+func loadTimings() *estimate.History {
+	h := &estimate.History{Kinds: map[string]estimate.Timing{}}
+	path, err := estimate.DefaultPath()
+	if err != nil {
+		return h
+	}
+	loaded, err := estimate.Load(path)
+	if err != nil {
+		logging.Decisionf("Estimating from defaults: %v", err)
+		return h
+	}
+	return loaded
+}
+
+// recordStamp records a sync without failures as the machine's last sync,
+// shown by clew status. Like recordRun, failures only warn.
+func (s *SyncService) recordStamp(clewfilePath string, result *sync.Result) {
+	if result.Failed > 0 {
+		return
+	}
+	path, err := stamp.DefaultPath()
+	if err != nil {
+		return
+	}
+	if _, err := stamp.Write(path, s.version, clewfilePath, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// sendAlerts runs the Clewfile's failure actions when operations failed.
+// Like recordRun, failures only warn.
+func (s *SyncService) sendAlerts(cfg config.AlertsConfig, clewfilePath string, result *sync.Result) {
+	if result.Failed == 0 || !cfg.Enabled() {
+		return
+	}
+	logging.Decisionf("Sending failure alert for %d failed operation(s)", result.Failed)
+	if err := alert.NewSender().Send(cfg, alert.NewReport(result, clewfilePath, s.version)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// alertFailure sends the Clewfile's failure alerts for a sync that failed
+// before or instead of running its operations, with err as the one item
+// needing attention.
+func (s *SyncService) alertFailure(clewfile *config.Clewfile, clewfilePath string, err error) {
+	s.sendAlerts(clewfile.Alerts, clewfilePath, &sync.Result{Failed: 1, Attention: []string{clewfile.Redact(err.Error())}})
+}
+
+// alertLoadFailure sends the alerts of a Clewfile that failed to load, when
+// its alerts section can still be read.
+func (s *SyncService) alertLoadFailure(err error) {
+	clewfilePath, findErr := config.FindClewfile(s.configPath)
+	if findErr != nil {
+		return
+	}
+	clewfile, loadErr := config.LoadAlerts(clewfilePath)
+	if loadErr != nil {
+		logging.Decisionf("Not alerting: %s cannot be read: %v", clewfilePath, loadErr)
+		return
+	}
+	s.alertFailure(clewfile, clewfilePath, err)
+}
+
+// logDiffDecisions reports the action chosen for every item at -v.
+func logDiffDecisions(d *diff.Result) {
+	if !logging.Enabled(logging.LevelDecisions) {
+		return
+	}
+	for _, m := range d.Marketplaces {
+		logging.Decisionf("marketplace %s: %s", m.Alias, m.Action)
+	}
+	for _, p := range d.Plugins {
+		logging.Decisionf("plugin %s: %s", p.Name, p.Action)
+	}
+	for _, k := range d.Keybindings {
+		logging.Decisionf("keybinding %s: %s", k.Name(), k.Action)
+	}
+}
+
 // handleOutput formats and displays the sync result.
 func (s *SyncService) handleOutput(result *sync.Result, opts SyncOptions) error {
 	format, err := output.ParseFormat(opts.OutputFormat)
@@ -328,6 +1063,10 @@ func (s *SyncService) handleOutput(result *sync.Result, opts SyncOptions) error
 			Quiet:   opts.Quiet,
 			Verbose: opts.Verbose,
 		})
+		if len(result.Timings) > 0 {
+			fmt.Fprintln(os.Stderr)
+			timing.Print(os.Stderr, result.Timings)
+		}
 	} else {
 		if err := s.FormatOutput(format, result); err != nil {
 			return fmt.Errorf("failed to write output: %w", err)
@@ -335,11 +1074,20 @@ func (s *SyncService) handleOutput(result *sync.Result, opts SyncOptions) error
 	}
 
 	// Handle exit codes
+	if result.Interrupted {
+		return errors.New(i18n.N(i18n.SyncErrInterrupted, result.Skipped))
+	}
+	if result.NotLoggedIn {
+		return fmt.Errorf("sync stopped: %w; %s", sync.ErrNotLoggedIn, sync.LoginHint)
+	}
+	if result.Aborted != "" {
+		return fmt.Errorf("sync aborted: %s failed (on_failure: abort)", result.Aborted)
+	}
 	if result.Failed > 0 {
 		if opts.Strict {
-			return fmt.Errorf("sync completed with %d failures (strict mode)", result.Failed)
+			return errors.New(i18n.N(i18n.SyncErrFailuresStrict, result.Failed))
 		}
-		return fmt.Errorf("sync completed with %d failures", result.Failed)
+		return errors.New(i18n.N(i18n.SyncErrFailures, result.Failed))
 	}
 
 	return nil
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/backup"
+	"github.com/adamancini/clew/internal/i18n"
+	"github.com/adamancini/clew/internal/journal"
+	"github.com/adamancini/clew/internal/state"
+	"github.com/adamancini/clew/internal/sync"
+)
+
+func newUndoCmd() *cobra.Command {
+	var (
+		yes  bool
+		wait bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Revert the most recent sync",
+		Long: `Undo reverts the most recent sync, apply or redo recorded in 'clew history'.
+
+It computes the inverse of every operation the run performed: plugins it
+installed are uninstalled, marketplaces it added are removed, and plugins
+it enabled or disabled are switched back. The backup taken before the run
+is used to avoid removing anything that existed beforehand.
+
+The operations are previewed and must be confirmed before they run.
+Running undo again reverts the run before that.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUndo(yes, wait)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for another running clew process instead of failing")
+
+	return cmd
+}
+
+// runUndo reverts the most recent run that has not been undone yet.
+func runUndo(skipConfirm, wait bool) error {
+	startedAt := time.Now()
+
+	// Lock before reading anything, so the plan shown is the one applied
+	l, err := acquireLock("undo", wait)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = l.Release() }()
+
+	j, err := journal.New()
+	if err != nil {
+		return err
+	}
+	run, err := j.LastUndoable()
+	if err != nil {
+		return err
+	}
+
+	before := loadRunBackup(run)
+
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	fmt.Printf("Undoing %s run: %s\n", run.Command, run.ID)
+	fmt.Printf("Started: %s\n", run.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Println()
+
+	plan := sync.RevertPlan(run.Operations, before, currentState)
+	if len(plan) == 0 {
+		fmt.Println("Nothing to undo: the run made no changes that are still in effect.")
+		return nil
+	}
+
+	fmt.Println("Changes to apply:")
+	for _, op := range plan {
+		fmt.Printf("  %s\n", op.Description)
+		fmt.Printf("    -> %s\n", op.Command)
+	}
+	fmt.Println()
+
+	if !skipConfirm {
+		ok, err := confirmProceed()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Undo cancelled.")
+			return nil
+		}
+	}
+
+	result, err := sync.NewSyncer().Revert(plan, currentState, sync.Options{
+		Verbose: verbose,
+		Quiet:   quiet,
+	})
+	if err != nil {
+		return fmt.Errorf("undo failed: %w", err)
+	}
+
+	undo := journal.NewRun("undo", startedAt, result)
+	undo.Reverts = run.ID
+	undo.ClewVersion = clewVersion
+	if err := j.Record(undo); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record run history: %v\n", err)
+	}
+
+	if result.Failed > 0 {
+		fmt.Println()
+		fmt.Printf("Undo completed with %d failures.\n", result.Failed)
+		for _, e := range result.Errors {
+			fmt.Printf("  - %v\n", e)
+		}
+		return fmt.Errorf("undo completed with errors")
+	}
+
+	fmt.Println()
+	fmt.Println(i18n.N(i18n.UndoReverted, result.Updated, run.ID))
+	return nil
+}
+
+// loadRunBackup returns the state backed up before run, or nil (with a
+// warning) when the run has no usable backup.
+func loadRunBackup(run *journal.Run) *state.State {
+	if run.BackupID == "" {
+		fmt.Fprintf(os.Stderr, "Warning: run %s has no pre-sync backup; reverting from the run history only\n", run.ID)
+		return nil
+	}
+
+	manager, err := backup.NewManager(clewVersion)
+	if err == nil {
+		var bak *backup.Backup
+		if bak, err = manager.Get(run.BackupID); err == nil {
+			return bak.ToState()
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Warning: cannot load backup %s (%v); reverting from the run history only\n", run.BackupID, err)
+	return nil
+}
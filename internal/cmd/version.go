@@ -7,14 +7,30 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/adamancini/clew/internal/github"
+	"github.com/adamancini/clew/internal/output"
 	"github.com/adamancini/clew/internal/update"
 )
 
 var (
-	checkOnly bool
-	doUpdate  bool
+	checkOnly      bool
+	doUpdate       bool
+	installVersion string
+	updateFrom     string
 )
 
+// buildInfo describes the running binary; it is set during command
+// initialization.
+var buildInfo = update.NewBuildInfo(clewVersion, "none", "unknown")
+
+// versionStatus is the structured output of clew version --check.
+type versionStatus struct {
+	update.BuildInfo `yaml:",inline"`
+	LatestVersion    string `json:"latest_version" yaml:"latest_version"`
+	UpdateAvailable  bool   `json:"update_available" yaml:"update_available"`
+	ReleaseURL       string `json:"release_url,omitempty" yaml:"release_url,omitempty"`
+}
+
 func newVersionCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "version",
@@ -24,7 +40,13 @@ func newVersionCmd() *cobra.Command {
 Examples:
   clew version              # Show current version
   clew version --check      # Check if update is available
-  clew version --update     # Download and install latest version`,
+  clew version --update     # Download and install latest version
+  clew version --install v0.8.1  # Install a specific release, e.g. to roll back
+  clew version --update --from ./clew-linux-amd64  # Offline update; checksums.txt alongside
+  clew version -o json      # Version, commit, build date, Go version and platform
+
+With -o json or -o yaml, the version and --check output is structured for
+scripts and fleet tooling instead of the human-readable lines.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runVersion()
 		},
@@ -32,23 +54,44 @@ Examples:
 
 	cmd.Flags().BoolVar(&checkOnly, "check", false, "Check for updates without installing")
 	cmd.Flags().BoolVar(&doUpdate, "update", false, "Update to the latest version")
+	cmd.Flags().StringVar(&installVersion, "install", "", "Install a specific release (e.g. v0.8.1), including older ones")
+	cmd.Flags().StringVar(&updateFrom, "from", "", "With --update, install a downloaded release binary (with its checksums.txt alongside) or archive without network access")
+	cmd.MarkFlagsMutuallyExclusive("check", "update", "install")
 
 	return cmd
 }
 
 func runVersion() error {
+	if installVersion != "" {
+		return runInstallVersion(installVersion)
+	}
+	if updateFrom != "" {
+		if !doUpdate {
+			return fmt.Errorf("--from requires --update")
+		}
+		return performLocalUpdate(updateFrom)
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	structured := format != output.FormatText && !doUpdate
+
 	// If no flags, just show version
 	if !checkOnly && !doUpdate {
+		if structured {
+			return output.NewWriter(os.Stdout, format).Write(buildInfo)
+		}
 		fmt.Printf("clew version %s\n", clewVersion)
 		return nil
 	}
 
 	// Check for updates
-	checker := update.NewGitHubChecker(clewVersion, "adamancini", "clew")
-
-	// Use GITHUB_TOKEN if available
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		checker = checker.WithToken(token)
+	checker := update.NewGitHubChecker(clewVersion, "adamancini", "clew").
+		WithToken(github.TokenFromEnv())
+	if cacheDir, err := github.DefaultCacheDir(); err == nil {
+		checker = checker.WithCache(cacheDir)
 	}
 
 	info, err := checker.CheckForUpdate()
@@ -56,6 +99,15 @@ func runVersion() error {
 		return fmt.Errorf("failed to check for updates: %w", err)
 	}
 
+	if structured {
+		return output.NewWriter(os.Stdout, format).Write(versionStatus{
+			BuildInfo:       buildInfo,
+			LatestVersion:   update.NormalizeVersion(info.LatestVersion),
+			UpdateAvailable: info.Available,
+			ReleaseURL:      info.ReleaseURL,
+		})
+	}
+
 	// Display current version
 	fmt.Printf("Current version: %s\n", info.CurrentVersion)
 
@@ -85,6 +137,30 @@ func runVersion() error {
 	return performUpdate(info)
 }
 
+// runInstallVersion downloads, verifies and installs an exact release, which
+// may be older than the running one.
+func runInstallVersion(version string) error {
+	checker := update.NewGitHubChecker(clewVersion, "adamancini", "clew").
+		WithToken(github.TokenFromEnv())
+	if cacheDir, err := github.DefaultCacheDir(); err == nil {
+		checker = checker.WithCache(cacheDir)
+	}
+
+	info, err := checker.CheckVersion(version)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Current version: %s\n", info.CurrentVersion)
+	if !info.Available {
+		fmt.Printf("Already running version %s\n", info.LatestVersion)
+		return nil
+	}
+	fmt.Printf("Installing version: %s\n", info.LatestVersion)
+
+	return performUpdate(info)
+}
+
 func performUpdate(info *update.UpdateInfo) error {
 	fmt.Println("\nDownloading update...")
 
@@ -111,7 +187,9 @@ func performUpdate(info *update.UpdateInfo) error {
 	defer func() { _ = os.RemoveAll(tmpDir) }()
 
 	// Download binary
-	downloader := update.NewHTTPDownloader()
+	ctx, stop := interruptContext()
+	defer stop()
+	downloader := update.NewHTTPDownloader().WithContext(ctx)
 	tmpBinary := filepath.Join(tmpDir, platform.BinaryName())
 
 	fmt.Printf("Downloading %s...\n", platform.BinaryName())
@@ -127,6 +205,50 @@ func performUpdate(info *update.UpdateInfo) error {
 	}
 	fmt.Println("✓ Checksum verified")
 
+	if err := installBinary(tmpBinary); err != nil {
+		return err
+	}
+	fmt.Printf("\nSuccessfully updated to v%s!\n", info.LatestVersion)
+
+	return nil
+}
+
+// performLocalUpdate installs a downloaded release binary or archive,
+// verified against the release's checksums.txt. It never touches the
+// network.
+func performLocalUpdate(from string) error {
+	platform := update.Detect()
+	if !platform.IsSupported() {
+		return fmt.Errorf("unsupported platform: %s/%s", platform.OS, platform.Arch)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "clew-update-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	tmpBinary, checksums, err := update.LocalRelease(from, tmpDir, platform)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Verifying checksum...")
+	if err := update.VerifyLocalChecksum(tmpBinary, checksums); err != nil {
+		return fmt.Errorf("checksum verification failed: %w", err)
+	}
+	fmt.Println("✓ Checksum verified")
+
+	if err := installBinary(tmpBinary); err != nil {
+		return err
+	}
+	fmt.Printf("\nSuccessfully updated from %s!\n", filepath.Base(from))
+
+	return nil
+}
+
+// installBinary replaces the running clew binary with a verified one.
+func installBinary(tmpBinary string) error {
 	// Get current binary path
 	currentBinary, err := os.Executable()
 	if err != nil {
@@ -148,7 +270,6 @@ func performUpdate(info *update.UpdateInfo) error {
 	}
 
 	fmt.Println("✓ Installation complete")
-	fmt.Printf("\nSuccessfully updated to v%s!\n", info.LatestVersion)
 
 	return nil
 }
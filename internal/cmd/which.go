@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adamancini/clew/internal/drift"
+	"github.com/adamancini/clew/internal/output"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// Plugin sources reported by clew which.
+const (
+	sourceMarketplace = "marketplace"
+	sourceLocal       = "local"
+)
+
+// WhichResult describes where an installed plugin lives and where it came from.
+type WhichResult struct {
+	Name          string         `json:"name" yaml:"name"`
+	InstallPath   string         `json:"install_path" yaml:"install_path"`
+	Version       string         `json:"version,omitempty" yaml:"version,omitempty"`
+	Commit        string         `json:"commit,omitempty" yaml:"commit,omitempty"` // Marketplace commit the plugin was installed from
+	SHA256        string         `json:"sha256,omitempty" yaml:"sha256,omitempty"` // Hash of the installed files, as used by Clewfile pins
+	Source        string         `json:"source" yaml:"source"`                     // "marketplace" or "local"
+	Marketplace   *WhichSource   `json:"marketplace,omitempty" yaml:"marketplace,omitempty"`
+	Enabled       bool           `json:"enabled" yaml:"enabled"`
+	EnabledSource string         `json:"enabled_source,omitempty" yaml:"enabled_source,omitempty"`
+	Installs      []WhichInstall `json:"installs" yaml:"installs"`
+}
+
+// WhichSource is the marketplace that provides a plugin.
+type WhichSource struct {
+	Alias           string `json:"alias" yaml:"alias"`
+	Repo            string `json:"repo,omitempty" yaml:"repo,omitempty"`
+	InstallLocation string `json:"install_location,omitempty" yaml:"install_location,omitempty"`
+}
+
+// WhichInstall is one scope the plugin is installed at.
+type WhichInstall struct {
+	Scope       string `json:"scope" yaml:"scope"`
+	ProjectPath string `json:"project_path,omitempty" yaml:"project_path,omitempty"`
+	InstallPath string `json:"install_path" yaml:"install_path"`
+	Version     string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+func newWhichCmd() *cobra.Command {
+	var long bool
+
+	cmd := &cobra.Command{
+		Use:   "which <plugin>",
+		Short: "Print where an installed plugin lives",
+		Long: `Which prints the install path of a plugin, for use in scripts:
+
+  cd "$(clew which code-review)"
+
+With --long it also shows provenance: the owning marketplace and its
+repository, whether the plugin is local or marketplace-sourced, where its
+enabled state comes from, every scope it is installed at, and the commit
+and sha256 to pin it to in the Clewfile. Use --output json for all of it in
+machine-readable form.
+
+The plugin can be given as name@marketplace or, when unambiguous, by name.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWhich(args[0], long)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&long, "long", "l", false, "Show provenance as well as the install path")
+
+	return cmd
+}
+
+// runWhich resolves a plugin and prints its location.
+func runWhich(name string, long bool) error {
+	reader := &state.FilesystemReader{}
+	currentState, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	result, err := resolveWhich(name, currentState)
+	if err != nil {
+		return err
+	}
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if (long || format != output.FormatText) && result.InstallPath != "" {
+		if sum, err := drift.HashPlugin(result.InstallPath); err == nil {
+			result.SHA256 = sum
+		}
+	}
+	if format != output.FormatText {
+		writer := output.NewWriter(os.Stdout, format)
+		return writer.Write(result)
+	}
+
+	if !long {
+		fmt.Println(result.InstallPath)
+		return nil
+	}
+	printWhichResult(result)
+	return nil
+}
+
+// resolveWhich finds an installed plugin by full or bare name.
+func resolveWhich(name string, currentState *state.State) (*WhichResult, error) {
+	fullName, err := resolveInstalledPlugin(name, currentState)
+	if err != nil {
+		return nil, err
+	}
+	p := currentState.Plugins[fullName]
+
+	result := &WhichResult{
+		Name:          fullName,
+		InstallPath:   p.InstallPath,
+		Version:       p.Version,
+		Commit:        p.GitCommitSha,
+		Source:        sourceMarketplace,
+		Enabled:       p.Enabled,
+		EnabledSource: p.EnabledSource,
+		Installs:      make([]WhichInstall, 0, len(p.Installs)),
+	}
+	if p.IsLocal {
+		result.Source = sourceLocal
+	}
+	if m, ok := currentState.Marketplaces[p.Marketplace]; ok {
+		result.Marketplace = &WhichSource{Alias: m.Alias, Repo: m.Repo, InstallLocation: m.InstallLocation}
+	} else if p.Marketplace != "" {
+		result.Marketplace = &WhichSource{Alias: p.Marketplace}
+	}
+	for _, i := range p.Installs {
+		result.Installs = append(result.Installs, WhichInstall(i))
+	}
+	return result, nil
+}
+
+// resolveInstalledPlugin maps name to the full name of an installed plugin.
+// A bare name matches any marketplace but must match exactly one.
+func resolveInstalledPlugin(name string, currentState *state.State) (string, error) {
+	if _, ok := currentState.Plugins[name]; ok {
+		return name, nil
+	}
+
+	var matches []string
+	if !strings.Contains(name, "@") {
+		for fullName, p := range currentState.Plugins {
+			if p.Name == name {
+				matches = append(matches, fullName)
+			}
+		}
+	}
+	sort.Strings(matches)
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("plugin %q is not installed", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("plugin %q is ambiguous: %s", name, strings.Join(matches, ", "))
+	}
+}
+
+// printWhichResult prints a plugin's location and provenance.
+func printWhichResult(r *WhichResult) {
+	fmt.Printf("Plugin: %s\n", r.Name)
+	fmt.Printf("  Path:         %s\n", r.InstallPath)
+	if r.Version != "" {
+		fmt.Printf("  Version:      %s\n", r.Version)
+	}
+	if r.Commit != "" {
+		fmt.Printf("  Commit:       %s\n", r.Commit)
+	}
+	if r.SHA256 != "" {
+		fmt.Printf("  SHA256:       %s\n", r.SHA256)
+	}
+	fmt.Printf("  Source:       %s\n", r.Source)
+	if m := r.Marketplace; m != nil {
+		fmt.Printf("  Marketplace:  %s\n", m.Alias)
+		if m.Repo != "" {
+			fmt.Printf("  Repo:         %s\n", m.Repo)
+		}
+		if m.InstallLocation != "" {
+			fmt.Printf("  Clone:        %s\n", m.InstallLocation)
+		}
+	}
+	enabled := "disabled"
+	if r.Enabled {
+		enabled = "enabled"
+	}
+	if r.EnabledSource != "" {
+		enabled += " (" + r.EnabledSource + ")"
+	}
+	fmt.Printf("  State:        %s\n", enabled)
+	if len(r.Installs) > 0 {
+		fmt.Println("  Installs:")
+		for _, i := range r.Installs {
+			scope := i.Scope
+			if i.ProjectPath != "" {
+				scope += " " + i.ProjectPath
+			}
+			fmt.Printf("    %s: %s\n", scope, i.InstallPath)
+		}
+	}
+}
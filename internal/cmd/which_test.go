@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestResolveWhich(t *testing.T) {
+	current := &state.State{
+		Marketplaces: map[string]state.MarketplaceState{
+			"official": {Alias: "official", Repo: "owner/plugins", InstallLocation: "/m/official"},
+		},
+		Plugins: map[string]state.PluginState{
+			"review@official": {
+				Name: "review", Marketplace: "official", InstallPath: "/p/review", Version: "1.0.0", Enabled: true,
+				Installs: []state.PluginInstall{
+					{Scope: "user", InstallPath: "/p/review", Version: "1.0.0"},
+					{Scope: "project", ProjectPath: "/work", InstallPath: "/p/review-0.9", Version: "0.9.0"},
+				},
+			},
+			"lint@official":  {Name: "lint", Marketplace: "official", InstallPath: "/p/lint"},
+			"lint@community": {Name: "lint", Marketplace: "community", InstallPath: "/p/lint2"},
+			"mine":           {Name: "mine", InstallPath: "/src/mine", IsLocal: true},
+		},
+	}
+
+	r, err := resolveWhich("review", current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Name != "review@official" || r.InstallPath != "/p/review" || r.Source != sourceMarketplace {
+		t.Errorf("review = %+v", r)
+	}
+	if r.Marketplace == nil || r.Marketplace.Repo != "owner/plugins" {
+		t.Errorf("review marketplace = %+v", r.Marketplace)
+	}
+	if len(r.Installs) != 2 || r.Installs[1].ProjectPath != "/work" {
+		t.Errorf("review installs = %+v", r.Installs)
+	}
+
+	r, err = resolveWhich("mine", current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Source != sourceLocal || r.Marketplace != nil {
+		t.Errorf("mine = %+v", r)
+	}
+
+	if r, err := resolveWhich("lint@community", current); err != nil || r.InstallPath != "/p/lint2" {
+		t.Errorf("lint@community = %+v, %v", r, err)
+	}
+	if _, err := resolveWhich("lint", current); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("lint error = %v, want ambiguous", err)
+	}
+	if _, err := resolveWhich("missing", current); err == nil {
+		t.Error("missing plugin should fail")
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/adamancini/clew/internal/state"
 	"github.com/adamancini/clew/internal/types"
+	"github.com/adamancini/clew/internal/xdg"
 )
 
 // Type aliases for backward compatibility.
@@ -24,15 +27,197 @@ const (
 // Marketplace represents a plugin marketplace source.
 // Marketplaces are repositories containing multiple plugins that can be installed.
 type Marketplace struct {
-	Repo string `yaml:"repo" toml:"repo" json:"repo"` // Repository URL (e.g., "owner/repo", "https://gitlab.com/company/plugins.git")
-	Ref  string `yaml:"ref,omitempty" toml:"ref,omitempty" json:"ref,omitempty"` // Optional git ref (branch/tag/SHA)
+	Repo      string `yaml:"repo" toml:"repo" json:"repo"`                                                 // Repository URL (e.g., "owner/repo", "https://gitlab.com/company/plugins.git")
+	Ref       string `yaml:"ref,omitempty" toml:"ref,omitempty" json:"ref,omitempty"`                      // Optional git ref (branch/tag/SHA)
+	OnFailure string `yaml:"on_failure,omitempty" toml:"on_failure,omitempty" json:"on_failure,omitempty"` // abort, continue or retry; default from the top-level on_failure
+
+	Defaults *PluginDefaults `yaml:"defaults,omitempty" toml:"defaults,omitempty" json:"defaults,omitempty"` // Settings its plugins inherit
+}
+
+// PluginDefaults are settings every plugin from a marketplace inherits
+// unless the plugin sets its own.
+type PluginDefaults struct {
+	Enabled *bool  `yaml:"enabled,omitempty" toml:"enabled,omitempty" json:"enabled,omitempty"`
+	Scope   string `yaml:"scope,omitempty" toml:"scope,omitempty" json:"scope,omitempty"`
 }
 
+// Failure policies for on_failure: what sync does when an entry fails.
+const (
+	OnFailureAbort    = "abort"    // Stop; the remaining entries are skipped
+	OnFailureContinue = "continue" // Report the failure and go on (the default)
+	OnFailureRetry    = "retry"    // Try once more, then go on
+)
+
+// OnFailurePolicies lists the valid on_failure values.
+var OnFailurePolicies = []string{OnFailureAbort, OnFailureContinue, OnFailureRetry}
+
 // Clewfile represents the parsed configuration file.
 type Clewfile struct {
-	Version      int                    `yaml:"version" toml:"version" json:"version"`
-	Marketplaces map[string]Marketplace `yaml:"marketplaces,omitempty" toml:"marketplaces,omitempty" json:"marketplaces,omitempty"`
-	Plugins      []Plugin               `yaml:"plugins" toml:"plugins" json:"plugins"`
+	Version      int                          `yaml:"version" toml:"version" json:"version"`
+	ExpandEnv    *bool                        `yaml:"expand_env,omitempty" toml:"expand_env,omitempty" json:"expand_env,omitempty"` // false leaves ${...} unexpanded; default true
+	Vars         map[string]string            `yaml:"vars,omitempty" toml:"vars,omitempty" json:"vars,omitempty"`                   // Referenced elsewhere as ${vars.NAME}
+	OnFailure    string                       `yaml:"on_failure,omitempty" toml:"on_failure,omitempty" json:"on_failure,omitempty"` // Default failure policy for entries without one
+	Marketplaces map[string]Marketplace       `yaml:"marketplaces,omitempty" toml:"marketplaces,omitempty" json:"marketplaces,omitempty"`
+	Plugins      []Plugin                     `yaml:"plugins" toml:"plugins" json:"plugins"`
+	Lint         LintConfig                   `yaml:"lint,omitempty" toml:"lint,omitempty" json:"lint,omitempty"`
+	Ignore       IgnoreConfig                 `yaml:"ignore,omitempty" toml:"ignore,omitempty" json:"ignore,omitempty"`
+	Updates      UpdatesConfig                `yaml:"updates,omitempty" toml:"updates,omitempty" json:"updates,omitempty"`
+	Scan         ScanConfig                   `yaml:"scan,omitempty" toml:"scan,omitempty" json:"scan,omitempty"`
+	Git          GitConfig                    `yaml:"git,omitempty" toml:"git,omitempty" json:"git,omitempty"`
+	Requires     RequiresConfig               `yaml:"requires,omitempty" toml:"requires,omitempty" json:"requires,omitempty"`
+	Alerts       AlertsConfig                 `yaml:"alerts,omitempty" toml:"alerts,omitempty" json:"alerts,omitempty"`
+	Backups      BackupsConfig                `yaml:"backups,omitempty" toml:"backups,omitempty" json:"backups,omitempty"`
+	Keybindings  map[string]map[string]string `yaml:"keybindings,omitempty" toml:"keybindings,omitempty" json:"keybindings,omitempty"` // Context -> key -> action; "" unbinds. Only declared keys are managed
+
+	// Encrypted is set by Load for a SOPS-encrypted Clewfile, whose values
+	// must not be persisted in plain text (see Secrets).
+	Encrypted bool `yaml:"-" toml:"-" json:"-"`
+}
+
+// BackupsConfig configures automatic backups and backup housekeeping.
+type BackupsConfig struct {
+	Auto      *bool                      `yaml:"auto,omitempty" toml:"auto,omitempty" json:"auto,omitempty"`                // Back up before each sync (default true)
+	Retention map[string]RetentionPolicy `yaml:"retention,omitempty" toml:"retention,omitempty" json:"retention,omitempty"` // Named policies for backup prune --policy
+}
+
+// RetentionPolicy decides which backups clew backup prune keeps. A backup
+// is kept if any rule keeps it: the KeepLast newest, and the newest backup
+// of each of the KeepDaily, KeepWeekly and KeepMonthly most recent days,
+// weeks and months that have backups.
+type RetentionPolicy struct {
+	KeepLast    int `yaml:"keep_last,omitempty" toml:"keep_last,omitempty" json:"keep_last,omitempty"`
+	KeepDaily   int `yaml:"keep_daily,omitempty" toml:"keep_daily,omitempty" json:"keep_daily,omitempty"`
+	KeepWeekly  int `yaml:"keep_weekly,omitempty" toml:"keep_weekly,omitempty" json:"keep_weekly,omitempty"`
+	KeepMonthly int `yaml:"keep_monthly,omitempty" toml:"keep_monthly,omitempty" json:"keep_monthly,omitempty"`
+}
+
+// DefaultRetentionPolicyName is the policy name that works without being
+// declared, using DefaultRetentionPolicy.
+const DefaultRetentionPolicyName = "default"
+
+// DefaultRetentionPolicy keeps the last 10 backups, one a day for a week
+// and one a week for four weeks.
+var DefaultRetentionPolicy = RetentionPolicy{KeepLast: 10, KeepDaily: 7, KeepWeekly: 4}
+
+// AutoEnabled reports whether sync backs up before making changes when
+// neither --backup nor --no-backup is given.
+func (b BackupsConfig) AutoEnabled() bool {
+	return b.Auto == nil || *b.Auto
+}
+
+// Policy returns the retention policy with the given name. "default" falls
+// back to DefaultRetentionPolicy when the Clewfile does not declare it.
+func (b BackupsConfig) Policy(name string) (RetentionPolicy, bool) {
+	if p, ok := b.Retention[name]; ok {
+		return p, true
+	}
+	if name == DefaultRetentionPolicyName {
+		return DefaultRetentionPolicy, true
+	}
+	return RetentionPolicy{}, false
+}
+
+// AlertsConfig configures what sync does when operations fail, so machines
+// synced unattended (cron, CI) do not stay broken silently.
+type AlertsConfig struct {
+	Webhook string `yaml:"webhook,omitempty" toml:"webhook,omitempty" json:"webhook,omitempty"` // URL the failure report is POSTed to as JSON
+	Exec    string `yaml:"exec,omitempty" toml:"exec,omitempty" json:"exec,omitempty"`          // Shell command run with the failure report on stdin
+}
+
+// Enabled reports whether any failure action is configured.
+func (a AlertsConfig) Enabled() bool {
+	return a.Webhook != "" || a.Exec != ""
+}
+
+// RequiresConfig declares tool versions the Clewfile needs. Sync checks
+// them in its preflight phase.
+type RequiresConfig struct {
+	Claude string `yaml:"claude,omitempty" toml:"claude,omitempty" json:"claude,omitempty"` // Version constraint, e.g. ">=1.0.30"
+}
+
+// GitConfig configures the git checks on local plugin and marketplace
+// repositories. Setting either signer list requires their HEAD commit to
+// be signed by one of the listed keys.
+type GitConfig struct {
+	AllowedSigners string   `yaml:"allowed_signers,omitempty" toml:"allowed_signers,omitempty" json:"allowed_signers,omitempty"`    // SSH allowed signers file (git's gpg.ssh.allowedSignersFile format)
+	AllowedGPGKeys []string `yaml:"allowed_gpg_keys,omitempty" toml:"allowed_gpg_keys,omitempty" json:"allowed_gpg_keys,omitempty"` // Fingerprints of trusted GPG keys
+	FetchInterval  string   `yaml:"fetch_interval,omitempty" toml:"fetch_interval,omitempty" json:"fetch_interval,omitempty"`       // Minimum time between fetches from one host, e.g. "2s"
+}
+
+// IsZero reports whether no git settings are configured.
+func (g GitConfig) IsZero() bool {
+	return !g.RequireSignatures() && g.FetchInterval == ""
+}
+
+// FetchDelay returns the parsed fetch_interval, or 0 (no limit) when it is
+// unset or invalid (validation reports invalid values).
+func (g GitConfig) FetchDelay() time.Duration {
+	d, err := time.ParseDuration(g.FetchInterval)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// RequireSignatures reports whether local repositories must have a signed HEAD.
+func (g GitConfig) RequireSignatures() bool {
+	return g.AllowedSigners != "" || len(g.AllowedGPGKeys) > 0
+}
+
+// MaxScanScore is the highest risk score clew scan assigns.
+const MaxScanScore = 10
+
+// ScanConfig is the policy applied to clew scan results during sync.
+type ScanConfig struct {
+	BlockScore int      `yaml:"block_score,omitempty" toml:"block_score,omitempty" json:"block_score,omitempty"` // Block plugins scoring at least this (0 = report only)
+	Allow      []string `yaml:"allow,omitempty" toml:"allow,omitempty" json:"allow,omitempty"`                   // Plugins reviewed and trusted despite findings
+}
+
+// Blocks reports whether a plugin with the given scan score is blocked.
+func (s ScanConfig) Blocks(plugin string, score int) bool {
+	if s.BlockScore == 0 || score < s.BlockScore {
+		return false
+	}
+	return !s.allows(plugin)
+}
+
+// BlocksUnscanned reports whether a plugin whose files could not be scanned
+// is blocked. With a block score set, unscanned plugins are blocked unless
+// allowed, so a plugin is never installed without passing the scan.
+func (s ScanConfig) BlocksUnscanned(plugin string) bool {
+	return s.BlockScore > 0 && !s.allows(plugin)
+}
+
+// allows reports whether plugin is listed in scan.allow.
+func (s ScanConfig) allows(plugin string) bool {
+	for _, allowed := range s.Allow {
+		if allowed == plugin {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdatesConfig configures the new-release notice.
+type UpdatesConfig struct {
+	Notify *bool `yaml:"notify,omitempty" toml:"notify,omitempty" json:"notify,omitempty"` // Show the daily update notice (default true)
+}
+
+// NotifyEnabled reports whether the update notice is shown.
+func (u UpdatesConfig) NotifyEnabled() bool {
+	return u.Notify == nil || *u.Notify
+}
+
+// IgnoreConfig lists installed items that are deliberately left out of the
+// Clewfile. clew does not report them as unmanaged.
+type IgnoreConfig struct {
+	Marketplaces []string `yaml:"marketplaces,omitempty" toml:"marketplaces,omitempty" json:"marketplaces,omitempty"`
+	Plugins      []string `yaml:"plugins,omitempty" toml:"plugins,omitempty" json:"plugins,omitempty"`
+}
+
+// LintConfig configures clew lint.
+type LintConfig struct {
+	Disable []string `yaml:"disable,omitempty" toml:"disable,omitempty" json:"disable,omitempty"` // Rule names to skip
 }
 
 // GetMarketplace finds a marketplace by its alias (map key).
@@ -46,18 +231,40 @@ func (c *Clewfile) GetMarketplace(alias string) (*Marketplace, error) {
 // Plugin represents a plugin to install.
 // Can be specified as:
 //   - Simple string: "name@marketplace" (e.g., "context7@official")
-//   - Struct with name, enabled, and scope
+//   - Struct with name, enabled, scope and pins
 //
 // The name must be in "plugin@marketplace" format where marketplace
 // refers to a key in the marketplaces map.
+//
+// SHA256 and Commit pin what the marketplace must deliver: after install,
+// sync verifies the plugin's files and commit against them and fails the
+// install on mismatch.
 type Plugin struct {
 	Name    string `yaml:"name" toml:"name" json:"name"`
 	Enabled *bool  `yaml:"enabled,omitempty" toml:"enabled,omitempty" json:"enabled,omitempty"`
 	Scope   string `yaml:"scope,omitempty" toml:"scope,omitempty" json:"scope,omitempty"`
+	SHA256  string `yaml:"sha256,omitempty" toml:"sha256,omitempty" json:"sha256,omitempty"` // Hash of the installed files (see drift.HashPlugin)
+	Commit  string `yaml:"commit,omitempty" toml:"commit,omitempty" json:"commit,omitempty"` // Marketplace commit, full or abbreviated
+
+	OnFailure string `yaml:"on_failure,omitempty" toml:"on_failure,omitempty" json:"on_failure,omitempty"` // abort, continue or retry; default from the top-level on_failure
 }
 
-// FindClewfile searches for a Clewfile in the standard locations.
-// Returns the path to the first Clewfile found, or an error if none exists.
+// Simple reports whether the plugin has no settings beyond its name, so it
+// can be written in the simple string form.
+func (p Plugin) Simple() bool {
+	return p.Enabled == nil && p.Scope == "" && p.SHA256 == "" && p.Commit == "" && p.OnFailure == ""
+}
+
+// Pinned reports whether the plugin pins its content or commit.
+func (p Plugin) Pinned() bool {
+	return p.SHA256 != "" || p.Commit != ""
+}
+
+// FindClewfile searches for a Clewfile in the standard locations. Returns
+// the path to the first Clewfile found, or an error if none exists. A
+// repository's .clew project directory is never picked implicitly: clew
+// installs at user scope, so a cloned repository must not change what is
+// installed for the user unless asked to (see FindProjectClewfile).
 func FindClewfile(explicitPath string) (string, error) {
 	if explicitPath != "" {
 		if _, err := os.Stat(explicitPath); err != nil {
@@ -83,60 +290,82 @@ func FindClewfile(explicitPath string) (string, error) {
 	var searchPaths []string
 
 	// XDG_CONFIG_HOME or default
-	xdgConfig := os.Getenv("XDG_CONFIG_HOME")
-	if xdgConfig == "" {
-		xdgConfig = filepath.Join(home, ".config")
+	configHome, err := xdg.ConfigHome()
+	if err != nil {
+		return "", err
 	}
-	searchPaths = append(searchPaths, filepath.Join(xdgConfig, "claude"))
+	searchPaths = append(searchPaths, filepath.Join(configHome, "claude"))
 
-	// ~/.claude
-	searchPaths = append(searchPaths, filepath.Join(home, ".claude"))
+	// ~/.claude, or $CLAUDE_CONFIG_DIR
+	if claudeDir, err := state.DefaultClaudeDir(); err == nil {
+		searchPaths = append(searchPaths, claudeDir)
+	}
 
 	// Home directory root
 	searchPaths = append(searchPaths, home)
 
-	// File name variants
-	fileNames := []string{
-		"Clewfile",
-		"Clewfile.yaml",
-		"Clewfile.yml",
-		"Clewfile.toml",
-		"Clewfile.json",
-		".Clewfile",
-		".Clewfile.yaml",
-		".Clewfile.yml",
-		".Clewfile.toml",
-		".Clewfile.json",
-	}
-
 	for _, dir := range searchPaths {
-		for _, name := range fileNames {
-			path := filepath.Join(dir, name)
-			if _, err := os.Stat(path); err == nil {
-				return path, nil
-			}
+		if path, ok := FindClewfileInDir(dir); ok {
+			return path, nil
 		}
 	}
 
 	return "", fmt.Errorf("no Clewfile found in standard locations")
 }
 
-// Load reads and parses a Clewfile from the given path.
+// clewfileNames lists the recognized Clewfile names in order of precedence.
+var clewfileNames = []string{
+	"Clewfile",
+	"Clewfile.yaml",
+	"Clewfile.yml",
+	"Clewfile.toml",
+	"Clewfile.json",
+	".Clewfile",
+	".Clewfile.yaml",
+	".Clewfile.yml",
+	".Clewfile.toml",
+	".Clewfile.json",
+}
+
+// FindClewfileInDir returns the first recognized Clewfile in dir.
+func FindClewfileInDir(dir string) (string, bool) {
+	for _, name := range clewfileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// Load reads and parses a Clewfile from the given path. SOPS-encrypted
+// Clewfiles are decrypted in memory. A Clewfile in a .clew project
+// directory also takes variables and policy from the files next to it.
 func Load(path string) (*Clewfile, error) {
-	content, err := os.ReadFile(path)
+	content, format, encrypted, err := readClewfile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read Clewfile: %w", err)
+		return nil, err
 	}
 
-	format := detectFormat(path, content)
-	if format == FormatUnknown {
-		return nil, fmt.Errorf("unable to detect file format for %s", path)
+	projectDir := projectDirOf(path)
+	var shared map[string]string
+	if projectDir != "" {
+		if shared, err = readProjectVars(projectDir); err != nil {
+			return nil, err
+		}
 	}
 
-	clewfile, err := parse(content, format)
+	clewfile, err := parseWithVars(content, format, shared)
 	if err != nil {
 		return nil, err
 	}
+	clewfile.Encrypted = encrypted
+	applyMarketplaceDefaults(clewfile)
+	if projectDir != "" {
+		if err := applyProjectPolicy(clewfile, projectDir); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := Validate(clewfile); err != nil {
 		return nil, err
@@ -145,6 +374,41 @@ func Load(path string) (*Clewfile, error) {
 	return clewfile, nil
 }
 
+// LoadAlerts reads the alerts section of a Clewfile that Load rejects, so
+// a Clewfile that no longer validates can still report the failure. The
+// Clewfile is parsed but not validated; it is returned for redacting the
+// error it caused.
+func LoadAlerts(path string) (*Clewfile, error) {
+	content, format, encrypted, err := readClewfile(path)
+	if err != nil {
+		return nil, err
+	}
+	clewfile, err := parse(content, format)
+	if err != nil {
+		return nil, err
+	}
+	clewfile.Encrypted = encrypted
+	return clewfile, nil
+}
+
+// applyMarketplaceDefaults fills in the settings each plugin leaves unset
+// from its marketplace's defaults.
+func applyMarketplaceDefaults(c *Clewfile) {
+	for i, p := range c.Plugins {
+		m, ok := c.Marketplaces[marketplaceOf(p.Name)]
+		if !ok || m.Defaults == nil {
+			continue
+		}
+		if p.Enabled == nil && m.Defaults.Enabled != nil {
+			enabled := *m.Defaults.Enabled
+			c.Plugins[i].Enabled = &enabled
+		}
+		if p.Scope == "" {
+			c.Plugins[i].Scope = m.Defaults.Scope
+		}
+	}
+}
+
 // InferScope determines the default scope based on Clewfile location.
 // clew 1.0 only supports user scope, so this always returns "user".
 func InferScope(clewfilePath string) string {
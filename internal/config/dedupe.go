@@ -0,0 +1,262 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/jsonedit"
+)
+
+// Duplicate is a plugin declared more than once and the single entry that
+// replaces its declarations.
+type Duplicate struct {
+	Name    string `json:"name" yaml:"name"`
+	Indexes []int  `json:"indexes" yaml:"indexes"` // Positions in plugins; the first is kept
+	Merged  Plugin `json:"merged" yaml:"merged"`
+}
+
+// FindDuplicates returns every plugin declared more than once, in order of
+// first declaration. Settings are merged so the most specific wins: a field
+// set on any declaration beats a default, and a later declaration beats an
+// earlier one.
+func FindDuplicates(plugins []Plugin) []Duplicate {
+	var duplicates []Duplicate
+	firstIndex := make(map[string]int) // name -> first declaration
+	dupIndex := make(map[string]int)   // name -> position in duplicates
+	for i, p := range plugins {
+		j, ok := firstIndex[p.Name]
+		if !ok {
+			firstIndex[p.Name] = i
+			continue
+		}
+		k, ok := dupIndex[p.Name]
+		if !ok {
+			duplicates = append(duplicates, Duplicate{Name: p.Name, Indexes: []int{j}, Merged: plugins[j]})
+			k = len(duplicates) - 1
+			dupIndex[p.Name] = k
+		}
+		d := &duplicates[k]
+		d.Indexes = append(d.Indexes, i)
+		d.Merged = mergePlugin(d.Merged, p)
+	}
+	return duplicates
+}
+
+// mergePlugin overlays the settings of later onto earlier.
+func mergePlugin(earlier, later Plugin) Plugin {
+	merged := earlier
+	if later.Enabled != nil {
+		merged.Enabled = later.Enabled
+	}
+	if later.Scope != "" {
+		merged.Scope = later.Scope
+	}
+	if later.SHA256 != "" {
+		merged.SHA256 = later.SHA256
+	}
+	if later.Commit != "" {
+		merged.Commit = later.Commit
+	}
+	if later.OnFailure != "" {
+		merged.OnFailure = later.OnFailure
+	}
+	return merged
+}
+
+// DuplicatesIn returns the duplicate plugin declarations in the Clewfile at
+// path without changing it. Unlike Load, it does not fail on duplicates.
+func DuplicatesIn(path string) ([]Duplicate, error) {
+	_, _, written, err := readWritten(path)
+	if err != nil {
+		return nil, err
+	}
+	return FindDuplicates(written.Plugins), nil
+}
+
+// readWritten reads a Clewfile as written. Variables are not expanded:
+// expanding them could merge entries that only match on this machine.
+func readWritten(path string) ([]byte, Format, *Clewfile, error) {
+	content, format, _, err := readClewfile(path)
+	if err != nil {
+		return nil, format, nil, err
+	}
+	written, err := decode(content, format)
+	if err != nil {
+		return nil, format, nil, err
+	}
+	return content, format, written, nil
+}
+
+// Dedupe merges duplicate plugin declarations in the Clewfile at path into
+// the position of the first one and returns what it merged. YAML files are
+// edited in place so comments survive; JSON files are rewritten. TOML and
+// SOPS-encrypted files are not supported.
+func Dedupe(path string) ([]Duplicate, error) {
+	if raw, err := os.ReadFile(path); err == nil && IsEncrypted(raw, detectFormat(path, raw)) {
+		return nil, errEncrypted(path)
+	}
+	content, format, written, err := readWritten(path)
+	if err != nil {
+		return nil, err
+	}
+	duplicates := FindDuplicates(written.Plugins)
+	if len(duplicates) == 0 {
+		return nil, nil
+	}
+
+	var updated []byte
+	switch format {
+	case FormatYAML:
+		updated, err = dedupeYAML(content, duplicates)
+	case FormatJSON:
+		updated, err = dedupeJSON(content, duplicates)
+	default:
+		return nil, fmt.Errorf("editing TOML Clewfiles is not supported; merge the duplicates in %s manually", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat Clewfile: %w", err)
+	}
+	if err := atomicfile.WriteFileWithBackup(path, updated, info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+// dedupePositions returns the merged entry for each kept position and the
+// set of positions to drop.
+func dedupePositions(duplicates []Duplicate) (map[int]Plugin, map[int]bool) {
+	keep := make(map[int]Plugin)
+	drop := make(map[int]bool)
+	for _, d := range duplicates {
+		keep[d.Indexes[0]] = d.Merged
+		for _, i := range d.Indexes[1:] {
+			drop[i] = true
+		}
+	}
+	return keep, drop
+}
+
+func dedupeYAML(content []byte, duplicates []Duplicate) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, yamlParseError(content, err)
+	}
+	list := yamlLookup(doc.Content[0], "plugins")
+
+	keep, drop := dedupePositions(duplicates)
+	items := make([]*yaml.Node, 0, len(list.Content))
+	for i, item := range list.Content {
+		if drop[i] {
+			continue
+		}
+		if merged, ok := keep[i]; ok {
+			node := pluginYAMLNode(merged)
+			node.HeadComment = item.HeadComment
+			node.LineComment = item.LineComment
+			node.FootComment = item.FootComment
+			node.Anchor = item.Anchor // Keep aliases to the entry valid
+			item = node
+		}
+		items = append(items, item)
+	}
+	list.Content = items
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// pluginYAMLNode renders a plugin in the simple form when it has no
+// settings, or as a mapping otherwise.
+func pluginYAMLNode(p Plugin) *yaml.Node {
+	if p.Simple() {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: p.Name}
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: "name"},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: p.Name})
+	if p.Enabled != nil {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "enabled"},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(*p.Enabled)})
+	}
+	if p.Scope != "" {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "scope"},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: p.Scope})
+	}
+	if p.SHA256 != "" {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "sha256"},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: p.SHA256})
+	}
+	if p.Commit != "" {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "commit"},
+			&yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: p.Commit})
+	}
+	if p.OnFailure != "" {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "on_failure"},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: p.OnFailure})
+	}
+	return node
+}
+
+// dedupeJSON rewrites only the plugins of a JSON Clewfile, leaving the
+// other keys as they were.
+func dedupeJSON(content []byte, duplicates []Duplicate) ([]byte, error) {
+	doc, err := jsonedit.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("JSON parse error: %w", err)
+	}
+	var list []json.RawMessage
+	if _, err := doc.Decode("plugins", &list); err != nil {
+		return nil, fmt.Errorf("JSON parse error: %w", err)
+	}
+
+	keep, drop := dedupePositions(duplicates)
+	items := make([]json.RawMessage, 0, len(list))
+	for i, item := range list {
+		if drop[i] {
+			continue
+		}
+		if merged, ok := keep[i]; ok {
+			var v any = merged
+			if merged.Enabled == nil && merged.Scope == "" {
+				v = merged.Name
+			}
+			if item, err = jsonedit.Marshal(v); err != nil {
+				return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+			}
+		}
+		items = append(items, item)
+	}
+	if err := doc.Set("plugins", items); err != nil {
+		return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+	}
+
+	data, err := doc.Indent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	disabled := false
+	plugins := []Plugin{
+		{Name: "a@m"},
+		{Name: "b@m", Scope: "user"},
+		{Name: "a@m", Enabled: &disabled},
+		{Name: "b@m"},
+		{Name: "c@m"},
+		{Name: "a@m", Scope: "user"},
+	}
+
+	got := FindDuplicates(plugins)
+	if len(got) != 2 {
+		t.Fatalf("FindDuplicates() = %+v, want 2 duplicates", got)
+	}
+
+	a := got[0]
+	if a.Name != "a@m" || len(a.Indexes) != 3 || a.Indexes[0] != 0 || a.Indexes[2] != 5 {
+		t.Errorf("a@m duplicate = %+v", a)
+	}
+	if a.Merged.Enabled == nil || *a.Merged.Enabled || a.Merged.Scope != "user" {
+		t.Errorf("a@m merged = %+v, want disabled with user scope", a.Merged)
+	}
+
+	// The simple form later does not erase settings
+	if b := got[1]; b.Merged.Scope != "user" {
+		t.Errorf("b@m merged = %+v, want user scope kept", b.Merged)
+	}
+
+	if got := FindDuplicates(plugins[:2]); len(got) != 0 {
+		t.Errorf("FindDuplicates(unique) = %+v", got)
+	}
+}
+
+func TestValidate_Duplicates(t *testing.T) {
+	c := &Clewfile{
+		Version:      1,
+		Marketplaces: map[string]Marketplace{"m": {Repo: "o/r"}},
+		Plugins:      []Plugin{{Name: "a@m"}, {Name: "a@m"}},
+	}
+	err := Validate(c)
+	if err == nil || !strings.Contains(err.Error(), "plugins[1].name: duplicate of plugins[0]") {
+		t.Errorf("Validate() error = %v, want duplicate error", err)
+	}
+}
+
+func TestDedupe_YAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Clewfile.yaml")
+	content := `version: 1
+marketplaces:
+  m:
+    repo: o/r
+plugins:
+  # Docs lookup
+  - a@m
+  - b@m
+  - name: a@m
+    enabled: false
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("Load() should reject duplicates")
+	}
+
+	if found, err := DuplicatesIn(path); err != nil || len(found) != 1 {
+		t.Fatalf("DuplicatesIn() = %+v, %v", found, err)
+	}
+
+	merged, err := Dedupe(path)
+	if err != nil {
+		t.Fatalf("Dedupe() error = %v", err)
+	}
+	if len(merged) != 1 || merged[0].Name != "a@m" {
+		t.Errorf("Dedupe() = %+v", merged)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() after Dedupe() error = %v", err)
+	}
+	if len(c.Plugins) != 2 || c.Plugins[0].Name != "a@m" || c.Plugins[0].Enabled == nil || *c.Plugins[0].Enabled {
+		t.Errorf("Plugins = %+v, want disabled a@m first", c.Plugins)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "# Docs lookup") {
+		t.Errorf("comment was lost:\n%s", data)
+	}
+
+	// Nothing left to merge
+	if merged, err := Dedupe(path); err != nil || len(merged) != 0 {
+		t.Errorf("second Dedupe() = %+v, %v", merged, err)
+	}
+}
+
+func TestDedupe_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Clewfile.json")
+	content := `{"$schema": "clewfile.schema.json", "version": 1, "marketplaces": {"m": {"repo": "o/r", "x-team": "platform"}}, "plugins": ["a@m", {"name": "a@m", "scope": "user"}, "b@m"]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Dedupe(path); err != nil {
+		t.Fatalf("Dedupe() error = %v", err)
+	}
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Plugins) != 2 || c.Plugins[0].Scope != "user" || c.Plugins[1].Name != "b@m" {
+		t.Errorf("Plugins = %+v", c.Plugins)
+	}
+	updated, _ := os.ReadFile(path)
+	for _, want := range []string{`"$schema": "clewfile.schema.json"`, `"x-team": "platform"`} {
+		if !strings.Contains(string(updated), want) {
+			t.Errorf("unknown key %s dropped:\n%s", want, updated)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/jsonedit"
+)
+
+// Edit is a set of additions to make to a Clewfile. Entries the file
+// already has are left alone.
+type Edit struct {
+	Plugins      []Plugin               // Appended to plugins
+	Marketplaces map[string]Marketplace // Declared unless the alias exists
+	Ignore       IgnoreConfig           // Appended to the ignore lists
+}
+
+// AddPlugins appends plugins to the Clewfile at path, declaring any
+// marketplace from known they need that the file does not have yet.
+func AddPlugins(path string, plugins []string, known map[string]Marketplace) error {
+	e := Edit{Marketplaces: make(map[string]Marketplace)}
+	for _, name := range plugins {
+		e.Plugins = append(e.Plugins, Plugin{Name: name})
+		alias := marketplaceOf(name)
+		if m, ok := known[alias]; ok {
+			e.Marketplaces[alias] = m
+		}
+	}
+	return ApplyEdit(path, e)
+}
+
+// ApplyEdit makes the additions in e to the Clewfile at path. YAML files are
+// edited in place so comments and ordering survive; JSON files are
+// rewritten. TOML and SOPS-encrypted files are not supported. The original
+// is kept as a backup.
+func ApplyEdit(path string, e Edit) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read Clewfile: %w", err)
+	}
+
+	format := detectFormat(path, content)
+	if IsEncrypted(content, format) {
+		return errEncrypted(path)
+	}
+
+	var updated []byte
+	switch format {
+	case FormatYAML:
+		updated, err = editYAML(content, e)
+	case FormatJSON:
+		updated, err = editJSON(content, e)
+	case FormatTOML:
+		return fmt.Errorf("editing TOML Clewfiles is not supported; update %s manually", path)
+	default:
+		return fmt.Errorf("unknown file format")
+	}
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat Clewfile: %w", err)
+	}
+	return atomicfile.WriteFileWithBackup(path, updated, info.Mode().Perm())
+}
+
+// editYAML edits the YAML node tree so comments are preserved.
+func editYAML(content []byte, e Edit) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, yamlParseError(content, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("YAML parse error: Clewfile is not a mapping")
+	}
+
+	if len(e.Plugins) > 0 {
+		list := yamlMappingValue(root, "plugins", yaml.SequenceNode)
+		listed := make(map[string]bool)
+		for _, item := range list.Content {
+			item = yamlResolve(item)
+			switch item.Kind {
+			case yaml.ScalarNode:
+				listed[item.Value] = true
+			case yaml.MappingNode:
+				if name := yamlLookup(item, "name"); name != nil {
+					listed[name.Value] = true
+				}
+			}
+		}
+		for _, p := range e.Plugins {
+			if !listed[p.Name] {
+				listed[p.Name] = true
+				list.Content = append(list.Content, pluginYAMLNode(p))
+			}
+		}
+	}
+
+	if len(e.Marketplaces) > 0 {
+		marketplaces := yamlMappingValue(root, "marketplaces", yaml.MappingNode)
+		for _, alias := range sortedKeys(e.Marketplaces) {
+			if yamlLookup(marketplaces, alias) != nil {
+				continue
+			}
+			m := e.Marketplaces[alias]
+			entry := &yaml.Node{Kind: yaml.MappingNode}
+			entry.Content = append(entry.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: "repo"},
+				&yaml.Node{Kind: yaml.ScalarNode, Value: m.Repo})
+			if m.Ref != "" {
+				entry.Content = append(entry.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Value: "ref"},
+					&yaml.Node{Kind: yaml.ScalarNode, Value: m.Ref})
+			}
+			marketplaces.Content = append(marketplaces.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: alias}, entry)
+		}
+	}
+
+	if len(e.Ignore.Marketplaces) > 0 || len(e.Ignore.Plugins) > 0 {
+		ignore := yamlMappingValue(root, "ignore", yaml.MappingNode)
+		appendYAMLStrings(yamlMappingValue(ignore, "marketplaces", yaml.SequenceNode), e.Ignore.Marketplaces)
+		appendYAMLStrings(yamlMappingValue(ignore, "plugins", yaml.SequenceNode), e.Ignore.Plugins)
+		// Drop a list that stayed empty
+		if len(yamlLookup(ignore, "marketplaces").Content) == 0 {
+			yamlDelete(ignore, "marketplaces")
+		}
+		if len(yamlLookup(ignore, "plugins").Content) == 0 {
+			yamlDelete(ignore, "plugins")
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlLookup returns the value for key in a mapping node, or nil. Aliases
+// are followed, and keys merged in with "<<: *anchor" are found too.
+func yamlLookup(mapping *yaml.Node, key string) *yaml.Node {
+	mapping = yamlResolve(mapping)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return yamlResolve(mapping.Content[i+1])
+		}
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != "<<" {
+			continue
+		}
+		merged := yamlResolve(mapping.Content[i+1])
+		sources := []*yaml.Node{merged}
+		if merged.Kind == yaml.SequenceNode {
+			sources = merged.Content
+		}
+		for _, source := range sources {
+			if v := yamlLookup(source, key); v != nil {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// yamlResolve returns the node an alias refers to, or n itself.
+func yamlResolve(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// yamlMappingValue returns the value for key, creating it with kind if it
+// is missing or null.
+func yamlMappingValue(mapping *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	mapping = yamlResolve(mapping)
+	if v := yamlLookup(mapping, key); v != nil {
+		if v.Kind != kind {
+			// e.g. "plugins:" with no items parses as a null scalar
+			*v = yaml.Node{Kind: kind}
+		}
+		return v
+	}
+	v := &yaml.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	return v
+}
+
+// yamlDelete removes key from a mapping node.
+func yamlDelete(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// appendYAMLStrings appends values missing from a sequence of strings.
+func appendYAMLStrings(list *yaml.Node, values []string) {
+	listed := make(map[string]bool)
+	for _, item := range list.Content {
+		listed[item.Value] = true
+	}
+	for _, v := range values {
+		if !listed[v] {
+			listed[v] = true
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: v})
+		}
+	}
+}
+
+// editJSON rewrites a JSON Clewfile with the additions. Only the plugins,
+// marketplaces and ignore keys are touched; everything else, including keys
+// clew does not know, is written back as it was.
+func editJSON(content []byte, e Edit) ([]byte, error) {
+	doc, err := jsonedit.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("JSON parse error: %w", err)
+	}
+
+	if len(e.Plugins) > 0 {
+		var items []json.RawMessage
+		if _, err := doc.Decode("plugins", &items); err != nil {
+			return nil, fmt.Errorf("JSON parse error: %w", err)
+		}
+		listed := make(map[string]bool)
+		for _, item := range items {
+			var name string
+			var entry struct {
+				Name string `json:"name"`
+			}
+			if json.Unmarshal(item, &name) == nil {
+				listed[name] = true
+			} else if json.Unmarshal(item, &entry) == nil {
+				listed[entry.Name] = true
+			}
+		}
+		for _, p := range e.Plugins {
+			if listed[p.Name] {
+				continue
+			}
+			listed[p.Name] = true
+			var v any = p
+			if p.Simple() {
+				v = p.Name
+			}
+			item, err := jsonedit.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+			}
+			items = append(items, item)
+		}
+		if err := doc.Set("plugins", items); err != nil {
+			return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+		}
+	}
+
+	if len(e.Marketplaces) > 0 {
+		marketplaces, err := doc.Object("marketplaces")
+		if err != nil {
+			return nil, fmt.Errorf("JSON parse error: %w", err)
+		}
+		for _, alias := range sortedKeys(e.Marketplaces) {
+			if !marketplaces.Has(alias) {
+				if err := marketplaces.Set(alias, e.Marketplaces[alias]); err != nil {
+					return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+				}
+			}
+		}
+		if err := doc.Set("marketplaces", marketplaces); err != nil {
+			return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+		}
+	}
+
+	if len(e.Ignore.Marketplaces) > 0 || len(e.Ignore.Plugins) > 0 {
+		ignore, err := doc.Object("ignore")
+		if err != nil {
+			return nil, fmt.Errorf("JSON parse error: %w", err)
+		}
+		for _, field := range []struct {
+			key    string
+			values []string
+		}{{"marketplaces", e.Ignore.Marketplaces}, {"plugins", e.Ignore.Plugins}} {
+			key, values := field.key, field.values
+			if len(values) == 0 {
+				continue
+			}
+			var list []string
+			if _, err := ignore.Decode(key, &list); err != nil {
+				return nil, fmt.Errorf("JSON parse error: ignore.%w", err)
+			}
+			if err := ignore.Set(key, appendMissing(list, values)); err != nil {
+				return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+			}
+		}
+		if err := doc.Set("ignore", ignore); err != nil {
+			return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+		}
+	}
+
+	data, err := doc.Indent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Clewfile: %w", err)
+	}
+	return data, nil
+}
+
+// appendMissing appends the values not already in list.
+func appendMissing(list, values []string) []string {
+	listed := make(map[string]bool)
+	for _, v := range list {
+		listed[v] = true
+	}
+	for _, v := range values {
+		if !listed[v] {
+			listed[v] = true
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// sortedKeys returns the keys of m in order, for stable output.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// marketplaceOf returns the marketplace part of a plugin@marketplace name.
+func marketplaceOf(name string) string {
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
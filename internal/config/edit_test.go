@@ -0,0 +1,229 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddPlugins_YAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Clewfile.yaml")
+	original := `version: 1
+
+# Where plugins come from
+marketplaces:
+  official:
+    repo: anthropics/claude-plugins
+
+plugins:
+  - context7@official # docs lookup
+  - name: linter@official
+    enabled: false
+`
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	marketplaces := map[string]Marketplace{
+		"official":  {Repo: "anthropics/claude-plugins"},
+		"community": {Repo: "someone/plugins", Ref: "main"},
+	}
+	err := AddPlugins(path, []string{"context7@official", "linter@official", "go-tools@community"}, marketplaces)
+	if err != nil {
+		t.Fatalf("AddPlugins() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{"# Where plugins come from", "# docs lookup"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("comment %q was lost:\n%s", want, content)
+		}
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v\n%s", err, content)
+	}
+	if len(c.Plugins) != 3 || c.Plugins[2].Name != "go-tools@community" {
+		t.Errorf("Plugins = %+v, want go-tools@community appended once", c.Plugins)
+	}
+	if m := c.Marketplaces["community"]; m.Repo != "someone/plugins" || m.Ref != "main" {
+		t.Errorf("community marketplace = %+v", m)
+	}
+	if err := Validate(c); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	if _, err := os.Stat(path + ".bak"); err != nil {
+		t.Errorf("expected a backup of the original Clewfile: %v", err)
+	}
+}
+
+func TestAddPlugins_YAMLEmptyList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Clewfile.yaml")
+	if err := os.WriteFile(path, []byte("version: 1\nplugins:\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	marketplaces := map[string]Marketplace{"official": {Repo: "anthropics/claude-plugins"}}
+	if err := AddPlugins(path, []string{"context7@official"}, marketplaces); err != nil {
+		t.Fatalf("AddPlugins() error = %v", err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Plugins) != 1 || c.Marketplaces["official"].Repo != "anthropics/claude-plugins" {
+		t.Errorf("Clewfile = %+v", c)
+	}
+}
+
+func TestAddPlugins_YAMLAnchors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Clewfile.yaml")
+	content := `version: 1
+marketplaces:
+  official:
+    repo: anthropics/claude-plugins
+x-plugins: &shared
+  - name: code-review@official
+    scope: user
+plugins: *shared
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The plugin already listed through the alias is not added again
+	if err := AddPlugins(path, []string{"code-review@official", "context7@official"}, nil); err != nil {
+		t.Fatalf("AddPlugins() error = %v", err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Plugins) != 2 || c.Plugins[0].Name != "code-review@official" || c.Plugins[1].Name != "context7@official" {
+		t.Errorf("Plugins = %+v, want code-review and context7", c.Plugins)
+	}
+	updated, _ := os.ReadFile(path)
+	if !strings.Contains(string(updated), "plugins: *shared") {
+		t.Errorf("alias not preserved:\n%s", updated)
+	}
+}
+
+func TestAddPlugins_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Clewfile.json")
+	original := `{"version": 1, "marketplaces": {"official": {"repo": "anthropics/claude-plugins"}}, "plugins": ["context7@official"]}`
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddPlugins(path, []string{"linter@official"}, nil); err != nil {
+		t.Fatalf("AddPlugins() error = %v", err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Plugins) != 2 || c.Plugins[1].Name != "linter@official" {
+		t.Errorf("Plugins = %+v", c.Plugins)
+	}
+}
+
+func TestAddPlugins_JSONKeepsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Clewfile.json")
+	original := `{
+  "$schema": "https://example.com/clewfile.schema.json",
+  "version": 1,
+  "marketplaces": {
+    "official": {"repo": "anthropics/claude-plugins", "x-team": "platform"}
+  },
+  "plugins": ["context7@official"],
+  "x-owner": {"uid": 501}
+}`
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	edit := Edit{
+		Plugins:      []Plugin{{Name: "linter@tools"}},
+		Marketplaces: map[string]Marketplace{"tools": {Repo: "acme/tools"}},
+	}
+	if err := ApplyEdit(path, edit); err != nil {
+		t.Fatalf("ApplyEdit() error = %v", err)
+	}
+
+	updated, _ := os.ReadFile(path)
+	got := string(updated)
+	for _, want := range []string{`"$schema": "https://example.com/clewfile.schema.json"`, `"x-team": "platform"`, `"uid": 501`, `"linter@tools"`, `"acme/tools"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("updated Clewfile missing %s:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, `"$schema"`) > strings.Index(got, `"version"`) {
+		t.Errorf("key order not kept:\n%s", got)
+	}
+}
+
+func TestAddPlugins_TOMLUnsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Clewfile.toml")
+	original := "version = 1\nplugins = []\n"
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddPlugins(path, []string{"context7@official"}, nil); err == nil {
+		t.Error("AddPlugins() on a TOML Clewfile should fail")
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Error("TOML Clewfile should be left untouched")
+	}
+}
+
+func TestApplyEdit_Ignore(t *testing.T) {
+	disabled := false
+	edit := Edit{
+		Plugins: []Plugin{{Name: "linter@official", Enabled: &disabled}},
+		Ignore:  IgnoreConfig{Marketplaces: []string{"scratch"}, Plugins: []string{"experiment@scratch", "old@official"}},
+	}
+
+	for _, tt := range []struct {
+		file     string
+		original string
+	}{
+		{"Clewfile.yaml", "version: 1\nmarketplaces:\n  official:\n    repo: anthropics/claude-plugins\nplugins:\n  - context7@official\nignore:\n  plugins:\n    - old@official\n"},
+		{"Clewfile.json", `{"version": 1, "marketplaces": {"official": {"repo": "anthropics/claude-plugins"}}, "plugins": ["context7@official"], "ignore": {"plugins": ["old@official"]}}`},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.original), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ApplyEdit(path, edit); err != nil {
+				t.Fatalf("ApplyEdit() error = %v", err)
+			}
+
+			c, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(c.Plugins) != 2 || c.Plugins[1].Name != "linter@official" || c.Plugins[1].Enabled == nil || *c.Plugins[1].Enabled {
+				t.Errorf("Plugins = %+v, want linter@official appended disabled", c.Plugins)
+			}
+			if got := c.Ignore.Marketplaces; len(got) != 1 || got[0] != "scratch" {
+				t.Errorf("Ignore.Marketplaces = %v, want [scratch]", got)
+			}
+			if got := c.Ignore.Plugins; len(got) != 2 || got[0] != "old@official" || got[1] != "experiment@scratch" {
+				t.Errorf("Ignore.Plugins = %v, want [old@official experiment@scratch]", got)
+			}
+		})
+	}
+}
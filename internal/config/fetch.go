@@ -0,0 +1,136 @@
+// Package config handles Clewfile parsing and location resolution.
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/logging"
+)
+
+// fetchClient is the HTTP client used to download remote Clewfiles.
+var fetchClient = &http.Client{Timeout: 30 * time.Second}
+
+// IsGitSource reports whether source refers to a git repository rather than
+// a plain file URL. Git sources are cloned and searched for a Clewfile.
+func IsGitSource(source string) bool {
+	if strings.HasPrefix(source, "git@") || strings.HasPrefix(source, "git://") {
+		return true
+	}
+	if strings.HasSuffix(source, ".git") {
+		return true
+	}
+	// Bare owner/repo shorthand is treated as a GitHub repository
+	return !strings.Contains(source, "://") && strings.Count(source, "/") == 1 &&
+		!strings.HasPrefix(source, ".") && !strings.HasPrefix(source, "/")
+}
+
+// Fetch retrieves a Clewfile from a URL or git repository and writes it into
+// destDir. Returns the path of the written file. The file keeps the name it
+// had at the source so format detection continues to work.
+func Fetch(source, destDir string) (string, error) {
+	var (
+		name    string
+		content []byte
+		err     error
+	)
+
+	if IsGitSource(source) {
+		name, content, err = fetchFromGit(source)
+	} else {
+		name, content, err = fetchFromURL(source)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", destDir, err)
+	}
+
+	dest := filepath.Join(destDir, name)
+	if err := atomicfile.WriteFile(dest, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write Clewfile: %w", err)
+	}
+
+	return dest, nil
+}
+
+// fetchFromURL downloads a Clewfile over HTTP(S).
+func fetchFromURL(source string) (string, []byte, error) {
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", nil, fmt.Errorf("unsupported Clewfile source: %s", source)
+	}
+
+	resp, err := fetchClient.Get(source)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to download Clewfile: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("failed to download Clewfile: status %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read Clewfile: %w", err)
+	}
+
+	return clewfileNameFor(path.Base(u.Path)), content, nil
+}
+
+// fetchFromGit shallow-clones a repository and reads the Clewfile at its root.
+func fetchFromGit(source string) (string, []byte, error) {
+	repoURL := source
+	if !strings.Contains(source, "://") && !strings.HasPrefix(source, "git@") &&
+		!strings.HasSuffix(source, ".git") {
+		repoURL = "https://github.com/" + source + ".git"
+	}
+
+	tmpDir, err := os.MkdirTemp("", "clew-fetch-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	args := []string{"clone", "--depth", "1", "--quiet", repoURL, tmpDir}
+	done := logging.StartCommand("git", args)
+	output, err := exec.Command("git", args...).CombinedOutput()
+	done(output, err)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to clone %s: %w\nOutput: %s", repoURL, err, string(output))
+	}
+
+	found, ok := FindClewfileInDir(tmpDir)
+	if !ok {
+		return "", nil, fmt.Errorf("no Clewfile found in %s", source)
+	}
+
+	content, err := os.ReadFile(found)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read Clewfile: %w", err)
+	}
+
+	return clewfileNameFor(filepath.Base(found)), content, nil
+}
+
+// clewfileNameFor maps a source file name onto a canonical Clewfile name,
+// preserving the extension so the format can still be detected.
+func clewfileNameFor(base string) string {
+	switch strings.ToLower(filepath.Ext(base)) {
+	case ".yaml", ".yml", ".toml", ".json":
+		return "Clewfile" + strings.ToLower(filepath.Ext(base))
+	}
+	return "Clewfile"
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsGitSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"owner/repo", true},
+		{"git@github.com:owner/repo.git", true},
+		{"https://github.com/owner/repo.git", true},
+		{"git://example.com/repo", true},
+		{"https://example.com/Clewfile.yaml", false},
+		{"./local/path", false},
+		{"/abs/path", false},
+		{"a/b/c", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			if got := IsGitSource(tt.source); got != tt.want {
+				t.Errorf("IsGitSource(%q) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dotfiles/Clewfile.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("version: 1\nplugins: []\n"))
+	}))
+	defer server.Close()
+
+	destDir := t.TempDir()
+
+	path, err := Fetch(server.URL+"/dotfiles/Clewfile.yaml", destDir)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if filepath.Base(path) != "Clewfile.yaml" {
+		t.Errorf("Fetch() wrote %s, want Clewfile.yaml", filepath.Base(path))
+	}
+	if _, err := Load(path); err != nil {
+		t.Errorf("fetched Clewfile failed to load: %v", err)
+	}
+
+	if _, err := Fetch(server.URL+"/missing", destDir); err == nil {
+		t.Error("Fetch() expected error for 404")
+	}
+}
+
+func TestFetchFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "--quiet")
+	if err := os.WriteFile(filepath.Join(repo, "Clewfile.toml"), []byte("version = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "--quiet", "-m", "init")
+
+	destDir := t.TempDir()
+	path, err := Fetch("file://"+repo+"/.git", destDir)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if filepath.Base(path) != "Clewfile.toml" {
+		t.Errorf("Fetch() wrote %s, want Clewfile.toml", filepath.Base(path))
+	}
+}
+
+func TestFindClewfileInDir(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, ok := FindClewfileInDir(dir); ok {
+		t.Fatal("FindClewfileInDir() found a Clewfile in an empty directory")
+	}
+
+	// Clewfile.yaml takes precedence over .Clewfile
+	for _, name := range []string{".Clewfile", "Clewfile.yaml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("version: 1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path, ok := FindClewfileInDir(dir)
+	if !ok || filepath.Base(path) != "Clewfile.yaml" {
+		t.Errorf("FindClewfileInDir() = %s, %v; want Clewfile.yaml", path, ok)
+	}
+}
@@ -2,11 +2,13 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/pelletier/go-toml/v2"
@@ -81,15 +83,27 @@ func sniffFormat(content []byte) Format {
 // rawClewfile is an intermediate representation for parsing.
 // It handles the flexible Plugin format (string or struct).
 type rawClewfile struct {
-	Version      int                    `yaml:"version" toml:"version" json:"version"`
-	Marketplaces map[string]Marketplace `yaml:"marketplaces" toml:"marketplaces" json:"marketplaces"`
-	Plugins      []interface{}          `yaml:"plugins" toml:"plugins" json:"plugins"`
+	Version      int                          `yaml:"version" toml:"version" json:"version"`
+	ExpandEnv    *bool                        `yaml:"expand_env,omitempty" toml:"expand_env,omitempty" json:"expand_env,omitempty"`
+	Vars         map[string]string            `yaml:"vars,omitempty" toml:"vars,omitempty" json:"vars,omitempty"`
+	OnFailure    string                       `yaml:"on_failure,omitempty" toml:"on_failure,omitempty" json:"on_failure,omitempty"`
+	Marketplaces map[string]Marketplace       `yaml:"marketplaces" toml:"marketplaces" json:"marketplaces"`
+	Plugins      []interface{}                `yaml:"plugins" toml:"plugins" json:"plugins"`
+	Lint         *LintConfig                  `yaml:"lint,omitempty" toml:"lint,omitempty" json:"lint,omitempty"`
+	Ignore       *IgnoreConfig                `yaml:"ignore,omitempty" toml:"ignore,omitempty" json:"ignore,omitempty"`
+	Updates      *UpdatesConfig               `yaml:"updates,omitempty" toml:"updates,omitempty" json:"updates,omitempty"`
+	Scan         *ScanConfig                  `yaml:"scan,omitempty" toml:"scan,omitempty" json:"scan,omitempty"`
+	Git          *GitConfig                   `yaml:"git,omitempty" toml:"git,omitempty" json:"git,omitempty"`
+	Requires     *RequiresConfig              `yaml:"requires,omitempty" toml:"requires,omitempty" json:"requires,omitempty"`
+	Alerts       *AlertsConfig                `yaml:"alerts,omitempty" toml:"alerts,omitempty" json:"alerts,omitempty"`
+	Backups      *BackupsConfig               `yaml:"backups,omitempty" toml:"backups,omitempty" json:"backups,omitempty"`
+	Keybindings  map[string]map[string]string `yaml:"keybindings,omitempty" toml:"keybindings,omitempty" json:"keybindings,omitempty"`
 }
 
 // parsePlugins converts the flexible plugin format to Plugin structs.
 // Plugins can be specified as:
 //   - Simple string: "name@marketplace" (e.g., "context7@official")
-//   - Struct with name, enabled, and scope fields
+//   - Struct with name, enabled, scope, sha256, commit and on_failure fields
 func parsePlugins(raw []interface{}) ([]Plugin, error) {
 	plugins := make([]Plugin, 0, len(raw))
 
@@ -117,6 +131,23 @@ func parsePlugins(raw []interface{}) ([]Plugin, error) {
 				plugin.Scope = scope
 			}
 
+			if onFailure, ok := v["on_failure"].(string); ok {
+				plugin.OnFailure = onFailure
+			}
+
+			// Quote pins in YAML: an all-digit hash would parse as a number
+			for key, field := range map[string]*string{"sha256": &plugin.SHA256, "commit": &plugin.Commit} {
+				value, present := v[key]
+				if !present {
+					continue
+				}
+				s, ok := value.(string)
+				if !ok {
+					return nil, fmt.Errorf("plugin[%d]: '%s' must be a string (quote it)", i, key)
+				}
+				*field = s
+			}
+
 			plugins = append(plugins, plugin)
 
 		default:
@@ -127,42 +158,243 @@ func parsePlugins(raw []interface{}) ([]Plugin, error) {
 	return plugins, nil
 }
 
-// envVarPattern matches ${VAR} and ${VAR:-default} patterns.
-var envVarPattern = regexp.MustCompile(`\$\{([^}:]+)(?::-([^}]*))?\}`)
+// envVarPattern matches ${VAR} and ${VAR:-default} patterns, and their
+// escaped $${VAR} form.
+var envVarPattern = regexp.MustCompile(`\$?\$\{([^}:]+)(?::-([^}]*))?\}`)
+
+// varsPrefix marks a reference to the Clewfile's vars block: ${vars.NAME}.
+const varsPrefix = "vars."
+
+// builtinVars are placeholders the parser resolves itself, so values can
+// name the machine portably. They take precedence over environment
+// variables of the same (lowercase) name.
+var builtinVars = map[string]func() string{
+	"hostname": func() string { h, _ := os.Hostname(); return h },
+	"os":       func() string { return runtime.GOOS },
+	"arch":     func() string { return runtime.GOARCH },
+	"home":     func() string { h, _ := os.UserHomeDir(); return h },
+}
 
-// expandEnvVars replaces ${VAR} and ${VAR:-default} patterns in content.
-func expandEnvVars(content []byte) []byte {
-	result := envVarPattern.ReplaceAllFunc(content, func(match []byte) []byte {
-		parts := envVarPattern.FindSubmatch(match)
-		if len(parts) < 2 {
-			return match
+// expandEnvVars replaces ${VAR} and ${VAR:-default} patterns in content with
+// environment variables, ${vars.NAME} with values from vars, and the
+// builtinVars (${hostname}, ${os}, ${arch}, ${home}) with their values.
+// $${VAR} is left as the literal text ${VAR}. A vars reference that is not
+// declared and has no default is an error.
+func expandEnvVars(content []byte, vars map[string]string) ([]byte, error) {
+	var out bytes.Buffer
+	last := 0
+	for _, m := range envVarPattern.FindAllSubmatchIndex(content, -1) {
+		out.Write(content[last:m[0]])
+		last = m[1]
+		match := content[m[0]:m[1]]
+		if bytes.HasPrefix(match, []byte("$$")) {
+			out.Write(match[1:])
+			continue
 		}
 
-		varName := string(parts[1])
-		value := os.Getenv(varName)
+		name := string(content[m[2]:m[3]])
+		var def string
+		if m[4] >= 0 {
+			def = string(content[m[4]:m[5]])
+		}
 
-		if value == "" && len(parts) >= 3 && len(parts[2]) > 0 {
+		var value string
+		if varName, ok := strings.CutPrefix(name, varsPrefix); ok {
+			v, declared := vars[varName]
+			if !declared && def == "" {
+				lineStart := bytes.LastIndexByte(content[:m[0]], '\n') + 1
+				if commentStart(string(content[lineStart:m[0]])) >= 0 {
+					// Leave references in comments as written
+					out.Write(match)
+					continue
+				}
+				line := bytes.Count(content[:m[0]], []byte("\n")) + 1
+				return nil, fmt.Errorf("line %d: undefined variable ${%s}; declare %s under vars", line, name, varName)
+			}
+			value = v
+		} else if builtin, ok := builtinVars[name]; ok {
+			value = builtin()
+		} else {
+			value = os.Getenv(name)
+		}
+		if value == "" && def != "" {
 			// Use default value
-			value = string(parts[2])
+			value = def
 		}
+		out.WriteString(value)
+	}
+	out.Write(content[last:])
+	return out.Bytes(), nil
+}
+
+// fileSettings are the top-level keys that control how the rest of the
+// Clewfile is read, decoded before anything is expanded.
+type fileSettings struct {
+	ExpandEnv *bool             `yaml:"expand_env" toml:"expand_env" json:"expand_env"`
+	Vars      map[string]string `yaml:"vars" toml:"vars" json:"vars"`
+}
+
+// readSettings decodes the settings in content. Content that does not parse
+// yields the defaults; decode reports the error.
+func readSettings(content []byte, format Format) fileSettings {
+	var settings fileSettings
+	var err error
+	switch format {
+	case FormatYAML:
+		err = yaml.Unmarshal(content, &settings)
+	case FormatTOML:
+		err = toml.Unmarshal(content, &settings)
+	case FormatJSON:
+		err = json.Unmarshal(content, &settings)
+	}
+	if err != nil {
+		return fileSettings{}
+	}
+	return settings
+}
 
-		return []byte(value)
-	})
+// resolveVars expands environment variables in the vars block. Variables
+// cannot refer to each other.
+func resolveVars(vars map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(vars))
+	for _, name := range sortedKeys(vars) {
+		if strings.Contains(vars[name], "${"+varsPrefix) {
+			return nil, fmt.Errorf("vars.%s: variables cannot refer to other variables", name)
+		}
+		value, err := expandEnvVars([]byte(vars[name]), nil)
+		if err != nil {
+			return nil, err
+		}
+		resolved[name] = string(value)
+	}
+	return resolved, nil
+}
 
-	return result
+// unknownAnchorPattern matches yaml.v3's error for an alias whose anchor is
+// not defined, which carries no line number.
+var unknownAnchorPattern = regexp.MustCompile(`unknown anchor '([^']*)' referenced`)
+
+// yamlParseError describes a YAML parse failure. For an alias to a missing
+// anchor it reports where the alias is used and, if the anchor is defined
+// further down, where.
+func yamlParseError(content []byte, err error) error {
+	m := unknownAnchorPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return fmt.Errorf("YAML parse error: %w", err)
+	}
+	name := m[1]
+	used, defined := anchorLines(content, name)
+	switch {
+	case used == 0:
+		return fmt.Errorf("YAML parse error: alias *%s refers to an anchor that is not defined", name)
+	case defined > used:
+		return fmt.Errorf("YAML parse error: line %d: alias *%s is used before its anchor &%s on line %d; anchors must be defined before they are referenced", used, name, name, defined)
+	default:
+		return fmt.Errorf("YAML parse error: line %d: alias *%s refers to an anchor that is not defined; mark the shared value with &%s first", used, name, name)
+	}
+}
+
+// anchorLines returns the first line using the alias *name and the first
+// line defining the anchor &name, or 0 where there is none. Comments and
+// quoted strings are skipped.
+func anchorLines(content []byte, name string) (used, defined int) {
+	for i, line := range strings.Split(string(content), "\n") {
+		if c := commentStart(line); c >= 0 {
+			line = line[:c]
+		}
+		var quote rune
+		prev := ' '
+		for j, r := range line {
+			switch {
+			case quote != 0:
+				if r == quote {
+					quote = 0
+				}
+			case r == '\'' || r == '"':
+				quote = r
+			case (r == '*' || r == '&') && strings.ContainsRune(" \t[{,:-", prev) && anchorNameAt(line[j+1:], name):
+				if r == '*' && used == 0 {
+					used = i + 1
+				} else if r == '&' && defined == 0 {
+					defined = i + 1
+				}
+			}
+			prev = r
+		}
+	}
+	return used, defined
+}
+
+// commentStart returns the index of the # starting a YAML or TOML comment on
+// line, or -1. A # inside quotes or directly after other text is not one.
+func commentStart(line string) int {
+	var quote rune
+	prev := ' '
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '#' && (prev == ' ' || prev == '\t'):
+			return i
+		}
+		prev = r
+	}
+	return -1
+}
+
+// anchorNameAt reports whether rest starts with exactly the anchor name.
+func anchorNameAt(rest, name string) bool {
+	if !strings.HasPrefix(rest, name) {
+		return false
+	}
+	rest = rest[len(name):]
+	return rest == "" || strings.ContainsRune(" \t,]}", rune(rest[0]))
 }
 
 // parse parses the content according to the specified format.
 func parse(content []byte, format Format) (*Clewfile, error) {
-	// Expand environment variables first
-	content = expandEnvVars(content)
+	return parseWithVars(content, format, nil)
+}
+
+// parseWithVars is parse with shared variables (a project's vars.yaml)
+// that the Clewfile's own vars block overrides.
+func parseWithVars(content []byte, format Format, shared map[string]string) (*Clewfile, error) {
+	settings := readSettings(content, format)
+	if settings.ExpandEnv != nil && !*settings.ExpandEnv {
+		return decode(content, format)
+	}
 
+	// Expand variables first
+	merged := make(map[string]string, len(shared)+len(settings.Vars))
+	for name, value := range shared {
+		merged[name] = value
+	}
+	for name, value := range settings.Vars {
+		merged[name] = value
+	}
+	vars, err := resolveVars(merged)
+	if err != nil {
+		return nil, err
+	}
+	expanded, err := expandEnvVars(content, vars)
+	if err != nil {
+		return nil, err
+	}
+	return decode(expanded, format)
+}
+
+// decode parses content as written, without expanding environment variables.
+func decode(content []byte, format Format) (*Clewfile, error) {
 	var raw rawClewfile
 
 	switch format {
 	case FormatYAML:
 		if err := yaml.Unmarshal(content, &raw); err != nil {
-			return nil, fmt.Errorf("YAML parse error: %w", err)
+			return nil, yamlParseError(content, err)
 		}
 	case FormatTOML:
 		if err := toml.Unmarshal(content, &raw); err != nil {
@@ -184,8 +416,36 @@ func parse(content []byte, format Format) (*Clewfile, error) {
 
 	clewfile := &Clewfile{
 		Version:      raw.Version,
+		ExpandEnv:    raw.ExpandEnv,
+		Vars:         raw.Vars,
+		OnFailure:    raw.OnFailure,
 		Marketplaces: raw.Marketplaces,
 		Plugins:      plugins,
+		Keybindings:  raw.Keybindings,
+	}
+	if raw.Lint != nil {
+		clewfile.Lint = *raw.Lint
+	}
+	if raw.Ignore != nil {
+		clewfile.Ignore = *raw.Ignore
+	}
+	if raw.Updates != nil {
+		clewfile.Updates = *raw.Updates
+	}
+	if raw.Scan != nil {
+		clewfile.Scan = *raw.Scan
+	}
+	if raw.Git != nil {
+		clewfile.Git = *raw.Git
+	}
+	if raw.Requires != nil {
+		clewfile.Requires = *raw.Requires
+	}
+	if raw.Alerts != nil {
+		clewfile.Alerts = *raw.Alerts
+	}
+	if raw.Backups != nil {
+		clewfile.Backups = *raw.Backups
 	}
 
 	// Initialize nil maps
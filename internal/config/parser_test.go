@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -36,6 +39,8 @@ func TestExpandEnvVars(t *testing.T) {
 	_ = os.Setenv("EMPTY_VAR", "")
 	defer func() { _ = os.Unsetenv("TEST_VAR") }()
 	defer func() { _ = os.Unsetenv("EMPTY_VAR") }()
+	home, _ := os.UserHomeDir()
+	hostname, _ := os.Hostname()
 
 	tests := []struct {
 		name     string
@@ -48,13 +53,22 @@ func TestExpandEnvVars(t *testing.T) {
 		{"empty var uses default", "${EMPTY_VAR:-default_value}", "default_value"},
 		{"no var", "plain text", "plain text"},
 		{"mixed content", "prefix ${TEST_VAR} suffix", "prefix test_value suffix"},
+		{"escaped", "$${TEST_VAR}", "${TEST_VAR}"},
+		{"escaped with default", "$${MISSING_VAR:-x} ${TEST_VAR}", "${MISSING_VAR:-x} test_value"},
+		{"builtin os and arch", "${os}-${arch}", runtime.GOOS + "-" + runtime.GOARCH},
+		{"builtin home", "${home}/.claude", home + "/.claude"},
+		{"builtin hostname", "${hostname}", hostname},
+		{"escaped builtin", "$${os}", "${os}"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := string(expandEnvVars([]byte(tt.input)))
-			if got != tt.expected {
-				t.Errorf("expandEnvVars() = %q, want %q", got, tt.expected)
+			expanded, err := expandEnvVars([]byte(tt.input), nil)
+			if err != nil {
+				t.Fatalf("expandEnvVars() error = %v", err)
+			}
+			if got := string(expanded); got != tt.expected {
+				t.Errorf("expandEnvVars() = %q, want %q", expanded, tt.expected)
 			}
 		})
 	}
@@ -124,6 +138,194 @@ plugins:
 	}
 }
 
+func TestParseExpandEnvDisabled(t *testing.T) {
+	t.Setenv("CLEW_TEST_REF", "v2.0.0")
+
+	tests := []struct {
+		name    string
+		format  Format
+		content string
+	}{
+		{"yaml", FormatYAML, "version: 1\nexpand_env: false\nmarketplaces:\n  official:\n    repo: anthropics/claude-plugins\n    ref: ${CLEW_TEST_REF}\n"},
+		{"toml", FormatTOML, "version = 1\nexpand_env = false\n[marketplaces.official]\nrepo = \"anthropics/claude-plugins\"\nref = \"${CLEW_TEST_REF}\"\n"},
+		{"json", FormatJSON, `{"version": 1, "expand_env": false, "marketplaces": {"official": {"repo": "anthropics/claude-plugins", "ref": "${CLEW_TEST_REF}"}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clewfile, err := parse([]byte(tt.content), tt.format)
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			if ref := clewfile.Marketplaces["official"].Ref; ref != "${CLEW_TEST_REF}" {
+				t.Errorf("ref = %q, want it left unexpanded", ref)
+			}
+			if clewfile.ExpandEnv == nil || *clewfile.ExpandEnv {
+				t.Errorf("ExpandEnv = %v, want false", clewfile.ExpandEnv)
+			}
+		})
+	}
+
+	// Expansion stays on by default
+	clewfile, err := parse([]byte("version: 1\nmarketplaces:\n  official:\n    repo: anthropics/claude-plugins\n    ref: ${CLEW_TEST_REF}\n"), FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref := clewfile.Marketplaces["official"].Ref; ref != "v2.0.0" || clewfile.ExpandEnv != nil {
+		t.Errorf("ref = %q, ExpandEnv = %v, want expanded by default", ref, clewfile.ExpandEnv)
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	t.Setenv("CLEW_TEST_HOME", "/home/dev")
+	content := []byte(`
+version: 1
+vars:
+  plugins_dir: ${CLEW_TEST_HOME}/src/plugins
+  org: acme
+marketplaces:
+  local:
+    repo: ${vars.plugins_dir}/marketplace
+  team:
+    repo: ${vars.org}/claude-plugins
+    ref: ${vars.ref:-main}
+plugins:
+  - tool@local
+  - name: literal@team
+    scope: user
+`)
+
+	clewfile, err := parse(content, FormatYAML)
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if repo := clewfile.Marketplaces["local"].Repo; repo != "/home/dev/src/plugins/marketplace" {
+		t.Errorf("local repo = %q", repo)
+	}
+	if m := clewfile.Marketplaces["team"]; m.Repo != "acme/claude-plugins" || m.Ref != "main" {
+		t.Errorf("team = %+v, want vars and default expanded", m)
+	}
+	if clewfile.Vars["plugins_dir"] != "/home/dev/src/plugins" {
+		t.Errorf("Vars = %v", clewfile.Vars)
+	}
+
+	// The same references work in TOML and JSON
+	toml := []byte("version = 1\n[vars]\norg = \"acme\"\n[marketplaces.team]\nrepo = \"${vars.org}/claude-plugins\"\n")
+	if c, err := parse(toml, FormatTOML); err != nil || c.Marketplaces["team"].Repo != "acme/claude-plugins" {
+		t.Errorf("parse(TOML) = %+v, %v", c, err)
+	}
+	json := []byte(`{"version": 1, "vars": {"org": "acme"}, "marketplaces": {"team": {"repo": "${vars.org}/claude-plugins"}}}`)
+	if c, err := parse(json, FormatJSON); err != nil || c.Marketplaces["team"].Repo != "acme/claude-plugins" {
+		t.Errorf("parse(JSON) = %+v, %v", c, err)
+	}
+}
+
+func TestParseVarsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"undefined", "version: 1\nvars:\n  org: acme\nmarketplaces:\n  team:\n    repo: ${vars.orgs}/plugins\n", "line 6: undefined variable ${vars.orgs}"},
+		{"no vars block", "version: 1\nmarketplaces:\n  team:\n    repo: ${vars.org}/plugins\n", "line 4: undefined variable ${vars.org}"},
+		{"nested", "version: 1\nvars:\n  a: x\n  b: ${vars.a}/y\n", "vars.b: variables cannot refer to other variables"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse([]byte(tt.content), FormatYAML)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// References in comments do not need to be declared
+	if _, err := parse([]byte("version: 1\n# Use ${vars.NAME} to share values\nplugins: [] # or ${vars.more}\n"), FormatYAML); err != nil {
+		t.Errorf("parse(comment) error = %v", err)
+	}
+
+	// Escaped and disabled references are left alone
+	for _, content := range []string{
+		"version: 1\nmarketplaces:\n  team:\n    repo: acme/plugins\n    ref: $${vars.ref}\n",
+		"version: 1\nexpand_env: false\nmarketplaces:\n  team:\n    repo: acme/plugins\n    ref: ${vars.ref}\n",
+	} {
+		c, err := parse([]byte(content), FormatYAML)
+		if err != nil || c.Marketplaces["team"].Ref != "${vars.ref}" {
+			t.Errorf("parse(%q) = %+v, %v, want literal ${vars.ref}", content, c, err)
+		}
+	}
+}
+
+func TestParseYAMLAnchors(t *testing.T) {
+	content := []byte(`
+version: 1
+x-defaults: &project
+  scope: project
+  enabled: true
+marketplaces:
+  official: &official
+    repo: anthropics/claude-plugins
+  pinned:
+    <<: *official
+    ref: v1.0.0
+plugins:
+  - &review
+    name: code-review@official
+    <<: *project
+  - name: context7@official
+    <<: *project
+    enabled: false
+`)
+
+	clewfile, err := parse(content, FormatYAML)
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if m := clewfile.Marketplaces["pinned"]; m.Repo != "anthropics/claude-plugins" || m.Ref != "v1.0.0" {
+		t.Errorf("pinned = %+v, want merged repo with own ref", m)
+	}
+	if len(clewfile.Plugins) != 2 {
+		t.Fatalf("Plugins count = %d, want 2", len(clewfile.Plugins))
+	}
+	if p := clewfile.Plugins[0]; p.Scope != "project" || p.Enabled == nil || !*p.Enabled {
+		t.Errorf("Plugins[0] = %+v, want merged project defaults", p)
+	}
+	if p := clewfile.Plugins[1]; p.Scope != "project" || p.Enabled == nil || *p.Enabled {
+		t.Errorf("Plugins[1] = %+v, want enabled overridden to false", p)
+	}
+}
+
+func TestParseYAMLUnknownAnchor(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "undefined",
+			content: "version: 1\nplugins:\n  # *defaults is shared below\n  - name: a@official\n    <<: *defaults\n",
+			want:    "line 5: alias *defaults refers to an anchor that is not defined",
+		},
+		{
+			name:    "defined after use",
+			content: "version: 1\nplugins:\n  - *review\nx-shared:\n  review: &review code-review@official\n",
+			want:    "line 3: alias *review is used before its anchor &review on line 5",
+		},
+		{
+			name:    "similar name",
+			content: "version: 1\nx: &defaults-v2 {scope: user}\nplugins:\n  - name: 'a*defaults'\n    <<: *defaults\n",
+			want:    "line 5: alias *defaults refers",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse([]byte(tt.content), FormatYAML)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
 func TestParseTOML(t *testing.T) {
 	content := []byte(`
 version = 1
@@ -210,3 +412,93 @@ plugins: []
 		t.Error("Marketplaces should be initialized to empty map, not nil")
 	}
 }
+
+func TestParseUpdatesNotify(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		format  Format
+		want    bool
+	}{
+		{"default", "version: 1\nplugins: []\n", FormatYAML, true},
+		{"yaml opt-out", "version: 1\nplugins: []\nupdates:\n  notify: false\n", FormatYAML, false},
+		{"toml opt-out", "version = 1\nplugins = []\n[updates]\nnotify = false\n", FormatTOML, false},
+		{"json opt-in", `{"version": 1, "plugins": [], "updates": {"notify": true}}`, FormatJSON, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clewfile, err := parse([]byte(tt.content), tt.format)
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			if got := clewfile.Updates.NotifyEnabled(); got != tt.want {
+				t.Errorf("NotifyEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBackups(t *testing.T) {
+	content := "version: 1\nplugins: []\nbackups:\n  auto: false\n  retention:\n    archive:\n      keep_monthly: 12\n"
+	clewfile, err := parse([]byte(content), FormatYAML)
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if clewfile.Backups.AutoEnabled() {
+		t.Error("AutoEnabled() = true, want false")
+	}
+	if p, ok := clewfile.Backups.Policy("archive"); !ok || p.KeepMonthly != 12 {
+		t.Errorf("Policy(archive) = %+v, %v; want keep_monthly 12", p, ok)
+	}
+	if !(BackupsConfig{}).AutoEnabled() {
+		t.Error("AutoEnabled() = false by default, want true")
+	}
+}
+
+func TestLoadMarketplaceDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Clewfile.yaml")
+	content := `version: 1
+marketplaces:
+  acme:
+    repo: acme/plugins
+    defaults:
+      enabled: false
+      scope: user
+  official:
+    repo: anthropics/claude-plugins
+plugins:
+  - lint@acme
+  - name: review@acme
+    enabled: true
+  - docs@official
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	clewfile, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	lint, review, docs := clewfile.Plugins[0], clewfile.Plugins[1], clewfile.Plugins[2]
+	if lint.Enabled == nil || *lint.Enabled || lint.Scope != "user" {
+		t.Errorf("lint@acme = %+v, want inherited enabled: false, scope: user", lint)
+	}
+	if review.Enabled == nil || !*review.Enabled || review.Scope != "user" {
+		t.Errorf("review@acme = %+v, want its own enabled: true and inherited scope", review)
+	}
+	if docs.Enabled != nil || docs.Scope != "" {
+		t.Errorf("docs@official = %+v, want no settings", docs)
+	}
+
+	// Defaults are not copied into the written Clewfile's plugins
+	_, _, written, err := readWritten(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !written.Plugins[0].Simple() {
+		t.Errorf("written lint@acme = %+v, want simple", written.Plugins[0])
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectDir is the directory at a repository root that holds the
+// project's clew configuration: a Clewfile (e.g. .clew/Clewfile.yaml) and
+// the optional ProjectVarsFile and ProjectPolicyFile next to it.
+const ProjectDir = ".clew"
+
+// Files a project directory may hold besides its Clewfile.
+const (
+	ProjectVarsFile   = "vars.yaml"   // Variables for ${vars.NAME}; the Clewfile's vars block wins
+	ProjectPolicyFile = "policy.yaml" // on_failure, scan, git, requires and lint sections the Clewfile does not set
+)
+
+// ProjectPolicy is the content of a project's policy.yaml: the policy
+// sections of a Clewfile, kept apart from the plugins it declares.
+type ProjectPolicy struct {
+	OnFailure string          `yaml:"on_failure,omitempty"`
+	Scan      *ScanConfig     `yaml:"scan,omitempty"`
+	Git       *GitConfig      `yaml:"git,omitempty"`
+	Requires  *RequiresConfig `yaml:"requires,omitempty"`
+	Lint      *LintConfig     `yaml:"lint,omitempty"`
+}
+
+// RepoRoot returns the closest directory at or above dir that contains
+// .git, and reports whether there is one.
+func RepoRoot(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		// .git is a file in worktrees and submodules
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// FindProjectClewfile returns the Clewfile in the .clew directory at the
+// root of the repository containing dir.
+func FindProjectClewfile(dir string) (string, bool) {
+	root, ok := RepoRoot(dir)
+	if !ok {
+		return "", false
+	}
+	return FindClewfileInDir(filepath.Join(root, ProjectDir))
+}
+
+// projectDirOf returns the directory of a Clewfile in a .clew project
+// directory, or "" for any other Clewfile.
+func projectDirOf(clewfilePath string) string {
+	dir := filepath.Dir(clewfilePath)
+	if filepath.Base(dir) != ProjectDir {
+		return ""
+	}
+	return dir
+}
+
+// readProjectVars reads the variables in dir's vars.yaml. A missing file
+// yields none.
+func readProjectVars(dir string) (map[string]string, error) {
+	var vars map[string]string
+	if err := readProjectFile(filepath.Join(dir, ProjectVarsFile), &vars); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// applyProjectPolicy fills in the policy sections clewfile leaves unset
+// from dir's policy.yaml. A missing file changes nothing.
+func applyProjectPolicy(clewfile *Clewfile, dir string) error {
+	var policy ProjectPolicy
+	if err := readProjectFile(filepath.Join(dir, ProjectPolicyFile), &policy); err != nil {
+		return err
+	}
+	if clewfile.OnFailure == "" {
+		clewfile.OnFailure = policy.OnFailure
+	}
+	if policy.Scan != nil && clewfile.Scan.BlockScore == 0 && len(clewfile.Scan.Allow) == 0 {
+		clewfile.Scan = *policy.Scan
+	}
+	if policy.Git != nil && clewfile.Git.IsZero() {
+		clewfile.Git = *policy.Git
+	}
+	if policy.Requires != nil && clewfile.Requires.Claude == "" {
+		clewfile.Requires = *policy.Requires
+	}
+	if policy.Lint != nil && len(clewfile.Lint.Disable) == 0 {
+		clewfile.Lint = *policy.Lint
+	}
+	return nil
+}
+
+// readProjectFile decodes the YAML file at path into v. A missing file
+// leaves v unchanged.
+func readProjectFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectClewfile(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "src", "pkg")
+	for _, dir := range []string{filepath.Join(root, ".git"), filepath.Join(root, ProjectDir), sub} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, ok := FindProjectClewfile(sub); ok {
+		t.Error("FindProjectClewfile() found a Clewfile in an empty .clew directory")
+	}
+
+	want := filepath.Join(root, ProjectDir, "Clewfile.yaml")
+	if err := os.WriteFile(want, []byte("version: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := FindProjectClewfile(sub)
+	if !ok || got != want {
+		t.Errorf("FindProjectClewfile() = %q, %t, want %q", got, ok, want)
+	}
+
+	if _, ok := FindProjectClewfile(t.TempDir()); ok {
+		t.Error("FindProjectClewfile() outside a repository found a Clewfile")
+	}
+
+	// The user's Clewfile wins: a project Clewfile is only used on request
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	t.Setenv("CLEWFILE", "")
+	t.Chdir(sub)
+	if got, err := FindClewfile(""); err == nil {
+		t.Errorf("FindClewfile() = %q inside a repository, want no Clewfile", got)
+	}
+}
+
+func TestLoadProjectClewfile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ProjectDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"Clewfile.yaml": `version: 1
+vars:
+  org: mine
+marketplaces:
+  tools:
+    repo: ${vars.org}/${vars.repo}
+plugins:
+  - lint@tools
+scan:
+  block_score: 5
+`,
+		ProjectVarsFile: "org: shared\nrepo: claude-tools\n",
+		ProjectPolicyFile: `on_failure: abort
+scan:
+  block_score: 8
+requires:
+  claude: ">=1.0.30"
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clewfile, err := Load(filepath.Join(dir, "Clewfile.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := clewfile.Marketplaces["tools"].Repo; got != "mine/claude-tools" {
+		t.Errorf("repo = %q, want the Clewfile's org and vars.yaml's repo", got)
+	}
+	if clewfile.OnFailure != OnFailureAbort || clewfile.Requires.Claude != ">=1.0.30" {
+		t.Errorf("on_failure = %q, requires = %q, want them from policy.yaml", clewfile.OnFailure, clewfile.Requires.Claude)
+	}
+	if clewfile.Scan.BlockScore != 5 {
+		t.Errorf("scan.block_score = %d, want the Clewfile's 5", clewfile.Scan.BlockScore)
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// Origin records where an entry of the effective Clewfile came from.
+type Origin struct {
+	File    string `json:"file" yaml:"file"`
+	Line    int    `json:"line,omitempty" yaml:"line,omitempty"`       // Known for YAML Clewfiles only
+	Written string `json:"written,omitempty" yaml:"written,omitempty"` // A marketplace's repo or a plugin's name before environment variable expansion, when it differs
+}
+
+// Resolved is the effective Clewfile together with the origin of each entry.
+type Resolved struct {
+	Path         string            `json:"path" yaml:"path"`
+	Clewfile     *Clewfile         `json:"clewfile" yaml:"clewfile"`
+	Marketplaces map[string]Origin `json:"marketplaces" yaml:"marketplaces"`
+	Plugins      []Origin          `json:"plugins" yaml:"plugins"` // Parallel to Clewfile.Plugins
+}
+
+// Resolve loads the Clewfile at path like Load and records where each
+// marketplace and plugin was declared.
+func Resolve(path string) (*Resolved, error) {
+	clewfile, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	content, format, _, err := readClewfile(path)
+	if err != nil {
+		return nil, err
+	}
+	written, err := decode(content, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var marketplaceLines map[string]int
+	var pluginLines []int
+	if format == FormatYAML {
+		marketplaceLines, pluginLines = yamlEntryLines(content)
+	}
+
+	r := &Resolved{
+		Path:         path,
+		Clewfile:     clewfile,
+		Marketplaces: make(map[string]Origin, len(clewfile.Marketplaces)),
+		Plugins:      make([]Origin, len(clewfile.Plugins)),
+	}
+	for alias, m := range clewfile.Marketplaces {
+		origin := Origin{File: path, Line: marketplaceLines[alias]}
+		if w, ok := written.Marketplaces[alias]; ok && w.Repo != m.Repo {
+			origin.Written = w.Repo
+		}
+		r.Marketplaces[alias] = origin
+	}
+	for i, p := range clewfile.Plugins {
+		origin := Origin{File: path}
+		if i < len(pluginLines) {
+			origin.Line = pluginLines[i]
+		}
+		if i < len(written.Plugins) && written.Plugins[i].Name != p.Name {
+			origin.Written = written.Plugins[i].Name
+		}
+		r.Plugins[i] = origin
+	}
+	return r, nil
+}
+
+// yamlEntryLines returns the line of each marketplace key and plugin item.
+func yamlEntryLines(content []byte) (map[string]int, []int) {
+	marketplaces := make(map[string]int)
+	var plugins []int
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil || len(doc.Content) == 0 {
+		return marketplaces, plugins
+	}
+	root := doc.Content[0]
+
+	if m := yamlLookup(root, "marketplaces"); m != nil && m.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			marketplaces[m.Content[i].Value] = m.Content[i].Line
+		}
+	}
+	if p := yamlLookup(root, "plugins"); p != nil && p.Kind == yaml.SequenceNode {
+		for _, item := range p.Content {
+			plugins = append(plugins, item.Line)
+		}
+	}
+	return marketplaces, plugins
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	t.Setenv("PLUGIN_ORG", "acme")
+	t.Setenv("PLUGIN_REF", "v2")
+	path := filepath.Join(t.TempDir(), "Clewfile.yaml")
+	content := `version: 1
+marketplaces:
+  official:
+    repo: anthropics/claude-plugins
+    ref: ${PLUGIN_REF}
+  company:
+    repo: ${PLUGIN_ORG}/plugins
+plugins:
+  - context7@official
+  - name: linter@company
+    enabled: false
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Resolve(path)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	if got := r.Clewfile.Marketplaces["company"].Repo; got != "acme/plugins" {
+		t.Errorf("company repo = %q, want acme/plugins", got)
+	}
+	if o := r.Marketplaces["company"]; o.Line != 6 || o.Written != "${PLUGIN_ORG}/plugins" || o.File != path {
+		t.Errorf("company origin = %+v", o)
+	}
+	// Only the repo is recorded as written; official's ref is expanded
+	if o := r.Marketplaces["official"]; o.Line != 3 || o.Written != "" {
+		t.Errorf("official origin = %+v", o)
+	}
+	if len(r.Plugins) != 2 || r.Plugins[0].Line != 9 || r.Plugins[1].Line != 10 {
+		t.Errorf("plugin origins = %+v", r.Plugins)
+	}
+}
+
+func TestResolve_JSONHasNoLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Clewfile.json")
+	content := `{"version": 1, "marketplaces": {"official": {"repo": "o/r"}}, "plugins": ["a@official"]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Resolve(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := r.Plugins[0]; o.File != path || o.Line != 0 {
+		t.Errorf("plugin origin = %+v", o)
+	}
+}
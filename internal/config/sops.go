@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/adamancini/clew/internal/logging"
+)
+
+// sopsCommand is the sops executable used to decrypt Clewfiles.
+var sopsCommand = "sops"
+
+// IsEncrypted reports whether content is a SOPS-encrypted YAML or JSON
+// document, recognized by the top-level sops metadata SOPS adds.
+func IsEncrypted(content []byte, format Format) bool {
+	var probe struct {
+		SOPS *struct {
+			MAC string `yaml:"mac" json:"mac"`
+		} `yaml:"sops" json:"sops"`
+	}
+	switch format {
+	case FormatYAML:
+		_ = yaml.Unmarshal(content, &probe)
+	case FormatJSON:
+		_ = json.Unmarshal(content, &probe)
+	}
+	return probe.SOPS != nil && probe.SOPS.MAC != ""
+}
+
+// readClewfile reads the Clewfile at path and detects its format. A
+// SOPS-encrypted file is decrypted with the sops CLI; the plaintext only
+// ever lives in memory. encrypted reports whether it was.
+func readClewfile(path string) (content []byte, format Format, encrypted bool, err error) {
+	content, err = os.ReadFile(path)
+	if err != nil {
+		return nil, FormatUnknown, false, fmt.Errorf("failed to read Clewfile: %w", err)
+	}
+	format = detectFormat(path, content)
+	if format == FormatUnknown {
+		return nil, format, false, fmt.Errorf("unable to detect file format for %s", path)
+	}
+	if IsEncrypted(content, format) {
+		content, err = decryptSOPS(path, format)
+		if err != nil {
+			return nil, format, true, err
+		}
+		return content, format, true, nil
+	}
+	return content, format, false, nil
+}
+
+// redactedValue replaces secrets in text clew keeps or sends.
+const redactedValue = "[redacted]"
+
+// minSecretLength is the length below which a variable is not treated as a
+// secret: short values like "main" or "true" would be redacted out of
+// unrelated words.
+const minSecretLength = 6
+
+// Secrets returns the values of an encrypted Clewfile that must not be
+// written in plain text: marketplace repos and the credentials in them,
+// variables, and alert targets. It returns nil for a plain Clewfile.
+func (c *Clewfile) Secrets() []string {
+	if !c.Encrypted {
+		return nil
+	}
+	var secrets []string
+	add := func(s string) {
+		if len(s) >= minSecretLength {
+			secrets = append(secrets, s)
+		}
+	}
+	for _, m := range c.Marketplaces {
+		add(m.Repo)
+		if u, err := url.Parse(m.Repo); err == nil && u.User != nil {
+			add(u.User.Username())
+			if password, ok := u.User.Password(); ok {
+				add(password)
+			}
+		}
+	}
+	for _, v := range c.Vars {
+		add(v)
+	}
+	add(c.Alerts.Webhook)
+	add(c.Alerts.Exec)
+	return secrets
+}
+
+// Redact replaces the Clewfile's secrets in s, so output of commands run
+// with decrypted values can be stored in history and sent in alerts.
+// Longer secrets are replaced first, so a repo URL is redacted whole
+// rather than around the token inside it.
+func (c *Clewfile) Redact(s string) string {
+	secrets := c.Secrets()
+	if len(secrets) == 0 {
+		return s
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, secret := range secrets {
+		pairs = append(pairs, secret, redactedValue)
+	}
+	return strings.NewReplacer(pairs...).Replace(s)
+}
+
+// sopsTypes maps the formats SOPS can encrypt to its --input-type names.
+var sopsTypes = map[Format]string{
+	FormatYAML: "yaml",
+	FormatJSON: "json",
+}
+
+// decryptSOPS decrypts path to stdout, using whatever keys sops finds
+// (age, PGP, cloud KMS).
+func decryptSOPS(path string, format Format) ([]byte, error) {
+	args := []string{"--decrypt", "--input-type", sopsTypes[format], "--output-type", sopsTypes[format], path}
+	logging.Commandf("%s %s", sopsCommand, strings.Join(args, " "))
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(sopsCommand, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("%s is encrypted with SOPS; install sops to decrypt it", path)
+	}
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("failed to decrypt %s with sops: %s", path, msg)
+	}
+	return out, nil
+}
+
+// errEncrypted is returned when clew would have to write an encrypted
+// Clewfile, which would mean storing its decrypted content.
+func errEncrypted(path string) error {
+	return fmt.Errorf("%s is encrypted with SOPS; clew does not edit encrypted Clewfiles, make the change with 'sops edit %s'", path, path)
+}
@@ -13,12 +13,26 @@
 //   - Marketplace repo: non-empty string (validateMarketplaces)
 //   - Plugin scopes: user only (validatePlugin)
 //   - Plugin name format: plugin@marketplace (validatePluginReferences)
+//   - Plugin pins: sha256 is 64 hex digits, commit 7 to 40 (validatePlugin)
+//   - Scan block score: 0 to MaxScanScore (validateScan)
+//   - GPG key fingerprints: 40 hex digits (validateGit)
+//   - git.fetch_interval: Go duration (validateGit)
+//   - requires.claude: optional operator and x.y.z version (validateRequires)
+//   - alerts.webhook: http or https URL (validateAlerts)
+//   - vars names: letters, digits, _ and -, not starting with a digit (validateVars)
+//   - on_failure: abort, continue or retry (validateOnFailure)
+//   - backups.retention keep counts: non-negative, at least one set (validateBackups)
+//
+// Not expressible in the schema:
+//   - Each plugin is declared once (FindDuplicates)
 package config
 
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/adamancini/clew/internal/types"
 )
@@ -26,6 +40,24 @@ import (
 // pluginNamePattern validates plugin names in the format "plugin@marketplace"
 var pluginNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+@[a-zA-Z0-9_-]+$`)
 
+// Plugin pin formats: a full SHA-256 digest and a full or abbreviated commit
+var (
+	sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	commitPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+)
+
+// versionConstraintPattern matches a requires constraint such as ">=1.0.30".
+var versionConstraintPattern = regexp.MustCompile(`^(>=|<=|>|<|=)?\s*v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+// gpgFingerprintPattern matches a full GPG key fingerprint.
+var gpgFingerprintPattern = regexp.MustCompile(`^[0-9A-Fa-f]{40}$`)
+
+// webhookPattern matches an http or https URL with a host.
+var webhookPattern = regexp.MustCompile(`^https?://[^\s/]+\S*$`)
+
+// varNamePattern matches a name usable in a ${vars.NAME} reference.
+var varNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
 // ValidationError represents a Clewfile validation error.
 type ValidationError struct {
 	Field   string
@@ -57,6 +89,51 @@ func Validate(c *Clewfile) error {
 		}
 	}
 
+	// Validate scan policy
+	if err := validateScan(c.Scan); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	// Validate git signature policy
+	if err := validateGit(c.Git); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	// Validate required tool versions
+	if err := validateRequires(c.Requires); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	// Validate failure alerts
+	if err := validateAlerts(c.Alerts); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	// Validate backup retention policies
+	if err := validateBackups(c.Backups); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	// Validate variable names
+	if err := validateVars(c.Vars); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	// Validate the default failure policy
+	if err := validateOnFailure("on_failure", c.OnFailure); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	// Reject duplicate plugin declarations
+	for _, d := range FindDuplicates(c.Plugins) {
+		for _, i := range d.Indexes[1:] {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("plugins[%d].name", i),
+				Message: fmt.Sprintf("duplicate of plugins[%d] '%s' (run 'clew dedupe' to merge them)", d.Indexes[0], d.Name),
+			}.Error())
+		}
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("validation errors:\n  - %s", strings.Join(errors, "\n  - "))
 	}
@@ -64,6 +141,87 @@ func Validate(c *Clewfile) error {
 	return nil
 }
 
+func validateScan(s ScanConfig) error {
+	if s.BlockScore < 0 || s.BlockScore > MaxScanScore {
+		return ValidationError{
+			Field:   "scan.block_score",
+			Message: fmt.Sprintf("must be between 0 and %d, got %d", MaxScanScore, s.BlockScore),
+		}
+	}
+	return nil
+}
+
+func validateGit(g GitConfig) error {
+	for i, key := range g.AllowedGPGKeys {
+		if !gpgFingerprintPattern.MatchString(key) {
+			return ValidationError{
+				Field:   fmt.Sprintf("git.allowed_gpg_keys[%d]", i),
+				Message: fmt.Sprintf("invalid fingerprint '%s' (must be 40 hex digits, without spaces)", key),
+			}
+		}
+	}
+	if g.FetchInterval != "" {
+		if d, err := time.ParseDuration(g.FetchInterval); err != nil || d < 0 {
+			return ValidationError{
+				Field:   "git.fetch_interval",
+				Message: fmt.Sprintf("invalid duration '%s' (e.g. \"2s\" or \"500ms\")", g.FetchInterval),
+			}
+		}
+	}
+	return nil
+}
+
+func validateRequires(r RequiresConfig) error {
+	if r.Claude != "" && !versionConstraintPattern.MatchString(r.Claude) {
+		return ValidationError{
+			Field:   "requires.claude",
+			Message: fmt.Sprintf("invalid version constraint '%s' (e.g. \">=1.0.30\")", r.Claude),
+		}
+	}
+	return nil
+}
+
+func validateAlerts(a AlertsConfig) error {
+	if a.Webhook != "" && !webhookPattern.MatchString(a.Webhook) {
+		return ValidationError{
+			Field:   "alerts.webhook",
+			Message: fmt.Sprintf("invalid URL '%s' (must start with http:// or https://)", a.Webhook),
+		}
+	}
+	return nil
+}
+
+func validateBackups(b BackupsConfig) error {
+	names := make([]string, 0, len(b.Retention))
+	for name := range b.Retention {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		p := b.Retention[name]
+		field := "backups.retention." + name
+		if p.KeepLast < 0 || p.KeepDaily < 0 || p.KeepWeekly < 0 || p.KeepMonthly < 0 {
+			return ValidationError{Field: field, Message: "keep counts must not be negative"}
+		}
+		if p == (RetentionPolicy{}) {
+			return ValidationError{Field: field, Message: "must keep something (set keep_last, keep_daily, keep_weekly or keep_monthly)"}
+		}
+	}
+	return nil
+}
+
+func validateVars(vars map[string]string) error {
+	for _, name := range sortedKeys(vars) {
+		if !varNamePattern.MatchString(name) {
+			return ValidationError{
+				Field:   "vars." + name,
+				Message: fmt.Sprintf("invalid variable name '%s' (use letters, digits, _ and -, not starting with a digit)", name),
+			}
+		}
+	}
+	return nil
+}
+
 func validateMarketplaces(marketplaces map[string]Marketplace) error {
 	for alias, m := range marketplaces {
 		// Validate alias (map key) is not empty
@@ -83,6 +241,19 @@ func validateMarketplaces(marketplaces map[string]Marketplace) error {
 		}
 
 		// Note: ref is optional, git will validate if it exists
+
+		if err := validateOnFailure(fmt.Sprintf("marketplaces.%s.on_failure", alias), m.OnFailure); err != nil {
+			return err
+		}
+
+		if m.Defaults != nil {
+			if err := types.Scope(m.Defaults.Scope).Validate(); err != nil {
+				return ValidationError{
+					Field:   fmt.Sprintf("marketplaces.%s.defaults.scope", alias),
+					Message: err.Error(),
+				}
+			}
+		}
 	}
 
 	return nil
@@ -131,5 +302,31 @@ func validatePlugin(index int, p Plugin) error {
 		}
 	}
 
+	if p.SHA256 != "" && !sha256Pattern.MatchString(p.SHA256) {
+		return ValidationError{
+			Field:   fmt.Sprintf("plugins[%d].sha256", index),
+			Message: fmt.Sprintf("invalid sha256 '%s' (must be 64 lowercase hex digits)", p.SHA256),
+		}
+	}
+	if p.Commit != "" && !commitPattern.MatchString(p.Commit) {
+		return ValidationError{
+			Field:   fmt.Sprintf("plugins[%d].commit", index),
+			Message: fmt.Sprintf("invalid commit '%s' (must be 7 to 40 lowercase hex digits)", p.Commit),
+		}
+	}
+	if err := validateOnFailure(fmt.Sprintf("plugins[%d].on_failure", index), p.OnFailure); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+func validateOnFailure(field, policy string) error {
+	if policy == "" || slices.Contains(OnFailurePolicies, policy) {
+		return nil
+	}
+	return ValidationError{
+		Field:   field,
+		Message: fmt.Sprintf("invalid policy '%s' (must be %s)", policy, strings.Join(OnFailurePolicies, ", ")),
+	}
+}
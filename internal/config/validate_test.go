@@ -48,6 +48,14 @@ func TestValidateMarketplaces(t *testing.T) {
 			wantErr:     true,
 			errContains: "repo is required",
 		},
+		{
+			name: "invalid default scope",
+			marketplaces: map[string]Marketplace{
+				"acme": {Repo: "acme/plugins", Defaults: &PluginDefaults{Scope: "galaxy"}},
+			},
+			wantErr:     true,
+			errContains: "marketplaces.acme.defaults.scope",
+		},
 		{
 			name:         "empty marketplaces map is valid",
 			marketplaces: map[string]Marketplace{},
@@ -249,3 +257,106 @@ func TestValidateFull(t *testing.T) {
 		t.Errorf("error should mention validation errors, got: %v", err)
 	}
 }
+
+func TestValidateAlerts(t *testing.T) {
+	tests := []struct {
+		webhook string
+		wantErr bool
+	}{
+		{"", false},
+		{"https://hooks.example.com/clew", false},
+		{"http://alerts.internal:8080/sync?team=dev", false},
+		{"hooks.example.com/clew", true},
+		{"https://", true},
+	}
+	for _, tt := range tests {
+		err := validateAlerts(AlertsConfig{Webhook: tt.webhook})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateAlerts(%q) error = %v, wantErr %v", tt.webhook, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateGitFetchInterval(t *testing.T) {
+	tests := []struct {
+		interval string
+		wantErr  bool
+	}{
+		{"", false},
+		{"2s", false},
+		{"1m30s", false},
+		{"-1s", true},
+		{"2", true},
+	}
+	for _, tt := range tests {
+		err := validateGit(GitConfig{FetchInterval: tt.interval})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateGit(fetch_interval %q) error = %v, wantErr %v", tt.interval, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateBackups(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetentionPolicy
+		wantErr bool
+	}{
+		{"counts", RetentionPolicy{KeepLast: 10, KeepDaily: 7, KeepWeekly: 4}, false},
+		{"negative", RetentionPolicy{KeepLast: -1, KeepDaily: 7}, true},
+		{"keeps nothing", RetentionPolicy{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBackups(BackupsConfig{Retention: map[string]RetentionPolicy{"default": tt.policy}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateBackups() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if p, ok := (BackupsConfig{}).Policy(DefaultRetentionPolicyName); !ok || p != DefaultRetentionPolicy {
+		t.Errorf("Policy(default) = %+v, %t, want the built-in default", p, ok)
+	}
+	if _, ok := (BackupsConfig{}).Policy("archive"); ok {
+		t.Error("Policy(archive) found an undeclared policy")
+	}
+}
+
+func TestValidateVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"plugins_dir", false},
+		{"team-org", false},
+		{"_private", false},
+		{"2fa", true},
+		{"with.dot", true},
+		{"a:b", true},
+	}
+	for _, tt := range tests {
+		err := validateVars(map[string]string{tt.name: "value"})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateVars(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateOnFailure(t *testing.T) {
+	c := &Clewfile{
+		Version:      1,
+		OnFailure:    OnFailureContinue,
+		Marketplaces: map[string]Marketplace{"official": {Repo: "org/repo", OnFailure: OnFailureAbort}},
+		Plugins:      []Plugin{{Name: "test@official", OnFailure: OnFailureRetry}},
+	}
+	if err := Validate(c); err != nil {
+		t.Errorf("Validate() unexpected error = %v", err)
+	}
+
+	c.Plugins[0].OnFailure = "ignore"
+	err := Validate(c)
+	if err == nil || !strings.Contains(err.Error(), "plugins[0].on_failure: invalid policy 'ignore'") {
+		t.Errorf("Validate() error = %v, want invalid plugins[0].on_failure", err)
+	}
+}
@@ -7,8 +7,15 @@ import (
 
 // Command represents a CLI command to reconcile state.
 type Command struct {
-	Command     string `json:"command" yaml:"command"`
-	Description string `json:"description" yaml:"description"`
+	Command     string   `json:"command" yaml:"command"`
+	Description string   `json:"description" yaml:"description"`
+	Args        []string `json:"-" yaml:"-"` // Command split into arguments, for quoting
+	Removal     bool     `json:"-" yaml:"-"` // Shown for reference; sync does not remove items
+}
+
+// newCommand builds a Command from its arguments.
+func newCommand(description string, args ...string) Command {
+	return Command{Command: strings.Join(args, " "), Description: description, Args: args}
 }
 
 // GenerateCommands generates CLI commands to reconcile the diff.
@@ -19,11 +26,8 @@ func (r *Result) GenerateCommands() []Command {
 	// 1. Add marketplaces first (plugins depend on them)
 	for _, m := range r.Marketplaces {
 		if m.Action == ActionAdd && m.Desired != nil {
-			cmd := fmt.Sprintf("claude plugin marketplace add %s", m.Desired.Repo)
-			commands = append(commands, Command{
-				Command:     cmd,
-				Description: fmt.Sprintf("Add marketplace: %s", m.Alias),
-			})
+			commands = append(commands, newCommand(fmt.Sprintf("Add marketplace: %s", m.Alias),
+				"claude", "plugin", "marketplace", "add", m.Desired.Repo))
 		}
 	}
 
@@ -33,50 +37,71 @@ func (r *Result) GenerateCommands() []Command {
 		case ActionAdd:
 			if p.Desired != nil {
 				// All plugins are installed from github sources
-				cmd := fmt.Sprintf("claude plugin install %s", p.Name)
+				args := []string{"claude", "plugin", "install", p.Name}
 				if p.Desired.Scope != "" && p.Desired.Scope != "user" {
-					cmd += fmt.Sprintf(" --scope %s", p.Desired.Scope)
+					args = append(args, "--scope", p.Desired.Scope)
 				}
-				desc := fmt.Sprintf("Install plugin: %s", p.Name)
-				commands = append(commands, Command{
-					Command:     cmd,
-					Description: desc,
-				})
-			}
+				commands = append(commands, newCommand(fmt.Sprintf("Install plugin: %s", p.Name), args...))
 
-		case ActionRemove:
-			// Non-destructive by default, but show the command
-			cmd := fmt.Sprintf("claude plugin uninstall %s", p.Name)
-			commands = append(commands, Command{
-				Command:     cmd,
-				Description: fmt.Sprintf("Remove plugin not in Clewfile: %s", p.Name),
-			})
+				// claude installs plugins enabled
+				if p.Desired.Enabled != nil && !*p.Desired.Enabled {
+					commands = append(commands, newCommand(fmt.Sprintf("Disable plugin: %s", p.Name),
+						"claude", "plugin", "disable", p.Name))
+				}
+			}
 
 		case ActionEnable:
-			cmd := fmt.Sprintf("claude plugin enable %s", p.Name)
+			args := []string{"claude", "plugin", "enable", p.Name}
 			if p.Current != nil && p.Current.Scope != "" && p.Current.Scope != "user" {
-				cmd += fmt.Sprintf(" --scope %s", p.Current.Scope)
+				args = append(args, "--scope", p.Current.Scope)
 			}
-			commands = append(commands, Command{
-				Command:     cmd,
-				Description: fmt.Sprintf("Enable plugin: %s", p.Name),
-			})
+			commands = append(commands, newCommand(fmt.Sprintf("Enable plugin: %s", p.Name), args...))
 
 		case ActionDisable:
-			cmd := fmt.Sprintf("claude plugin disable %s", p.Name)
+			args := []string{"claude", "plugin", "disable", p.Name}
 			if p.Current != nil && p.Current.Scope != "" && p.Current.Scope != "user" {
-				cmd += fmt.Sprintf(" --scope %s", p.Current.Scope)
+				args = append(args, "--scope", p.Current.Scope)
 			}
-			commands = append(commands, Command{
-				Command:     cmd,
-				Description: fmt.Sprintf("Disable plugin: %s", p.Name),
-			})
+			commands = append(commands, newCommand(fmt.Sprintf("Disable plugin: %s", p.Name), args...))
+		}
+	}
+
+	// 3. Removals, non-destructive by default but shown for reference:
+	// plugins first, then marketplaces no remaining plugin comes from
+	used := make(map[string]bool)
+	for _, p := range r.Plugins {
+		if p.Action == ActionRemove {
+			cmd := newCommand(fmt.Sprintf("Remove plugin not in Clewfile: %s", p.Name),
+				"claude", "plugin", "uninstall", p.Name)
+			cmd.Removal = true
+			commands = append(commands, cmd)
+		} else {
+			used[p.Marketplace()] = true
+		}
+	}
+	for _, m := range r.Marketplaces {
+		if m.Action == ActionRemove && !used[m.Alias] {
+			cmd := newCommand(fmt.Sprintf("Remove marketplace not in Clewfile: %s", m.Alias),
+				"claude", "plugin", "marketplace", "remove", m.Alias)
+			cmd.Removal = true
+			commands = append(commands, cmd)
 		}
 	}
 
 	return commands
 }
 
+// Marketplace returns the marketplace p comes from.
+func (p PluginDiff) Marketplace() string {
+	if p.Current != nil && p.Current.Marketplace != "" {
+		return p.Current.Marketplace
+	}
+	if i := strings.LastIndex(p.Name, "@"); i >= 0 {
+		return p.Name[i+1:]
+	}
+	return ""
+}
+
 // FormatCommands formats commands for shell execution.
 func FormatCommands(commands []Command, includeComments bool) string {
 	var output strings.Builder
@@ -0,0 +1,52 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestGenerateCommandsInstallDisabled(t *testing.T) {
+	disabled := false
+	r := &Result{
+		Plugins: []PluginDiff{
+			{Name: "linear@official", Action: ActionAdd, Desired: &config.Plugin{Name: "linear@official", Enabled: &disabled}},
+		},
+	}
+
+	commands := r.GenerateCommands()
+	if len(commands) != 2 || commands[1].Command != "claude plugin disable linear@official" {
+		t.Errorf("GenerateCommands() = %+v, want install then disable", commands)
+	}
+}
+
+func TestGenerateCommandsRemovalOrder(t *testing.T) {
+	r := &Result{
+		Marketplaces: []MarketplaceDiff{
+			{Alias: "old", Action: ActionRemove, Current: &state.MarketplaceState{}},
+			{Alias: "shared", Action: ActionRemove, Current: &state.MarketplaceState{}},
+		},
+		Plugins: []PluginDiff{
+			{Name: "a@old", Action: ActionRemove, Current: &state.PluginState{Marketplace: "old"}},
+			{Name: "b@shared", Action: ActionRemove, Current: &state.PluginState{Marketplace: "shared"}},
+			{Name: "c@shared", Action: ActionDisable, Current: &state.PluginState{Marketplace: "shared", Enabled: true}},
+		},
+	}
+
+	var got []string
+	for _, cmd := range r.GenerateCommands() {
+		got = append(got, cmd.Command)
+	}
+	// shared is kept: c@shared stays installed
+	want := []string{
+		"claude plugin disable c@shared",
+		"claude plugin uninstall a@old",
+		"claude plugin uninstall b@shared",
+		"claude plugin marketplace remove old",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("GenerateCommands() = %q, want %q", got, want)
+	}
+}
@@ -2,17 +2,107 @@
 package diff
 
 import (
+	"maps"
+	"slices"
+	"strings"
+
 	"github.com/adamancini/clew/internal/config"
 	"github.com/adamancini/clew/internal/state"
 )
 
-// Compute calculates the diff between a Clewfile and current state.
-func compute(clewfile *config.Clewfile, current *state.State) *Result {
-	result := &Result{
-		Marketplaces: computeMarketplaceDiffs(clewfile.Marketplaces, current.Marketplaces),
-		Plugins:      computePluginDiffs(clewfile.Plugins, current.Plugins),
+// compareMarketplaces is the marketplaces stage of the default pipeline.
+func compareMarketplaces(clewfile *config.Clewfile, current *state.State, result *Result) {
+	result.Marketplaces = append(result.Marketplaces, computeMarketplaceDiffs(clewfile.Marketplaces, current.Marketplaces)...)
+}
+
+// comparePlugins is the plugins stage of the default pipeline.
+func comparePlugins(clewfile *config.Clewfile, current *state.State, result *Result) {
+	result.Plugins = append(result.Plugins, computePluginDiffs(clewfile.Plugins, current.Plugins)...)
+}
+
+// compareKeybindings is the key bindings stage of the default pipeline.
+// Only the keys the Clewfile declares are compared; other custom bindings
+// are left alone rather than reported as unmanaged.
+func compareKeybindings(clewfile *config.Clewfile, current *state.State, result *Result) {
+	for _, context := range slices.Sorted(maps.Keys(clewfile.Keybindings)) {
+		declared := clewfile.Keybindings[context]
+		for _, key := range slices.Sorted(maps.Keys(declared)) {
+			k := KeybindingDiff{Context: context, Key: key, Action: ActionNone, Desired: declared[key]}
+			currentAction, bound := current.Keybindings[context][key]
+			k.Current = currentAction
+			switch {
+			case !bound:
+				k.Action = ActionAdd
+			case currentAction != k.Desired:
+				k.Action = ActionUpdate
+			}
+			result.Keybindings = append(result.Keybindings, k)
+		}
+	}
+}
+
+// applyIgnores drops installed items the Clewfile deliberately leaves out,
+// so they are not reported as unmanaged.
+func applyIgnores(result *Result, ignore config.IgnoreConfig) {
+	ignored := make(map[string]bool)
+	for _, alias := range ignore.Marketplaces {
+		ignored[alias] = true
+	}
+	marketplaces := result.Marketplaces[:0]
+	for _, m := range result.Marketplaces {
+		if m.Action != ActionRemove || !ignored[m.Alias] {
+			marketplaces = append(marketplaces, m)
+		}
+	}
+	result.Marketplaces = marketplaces
+
+	ignored = make(map[string]bool)
+	for _, name := range ignore.Plugins {
+		ignored[name] = true
+	}
+	plugins := result.Plugins[:0]
+	for _, p := range result.Plugins {
+		if p.Action != ActionRemove || !ignored[p.Name] {
+			plugins = append(plugins, p)
+		}
+	}
+	result.Plugins = plugins
+}
+
+// applyManagedPolicy marks changes that managed settings would prevent so
+// sync reports them instead of failing on every run.
+func applyManagedPolicy(result *Result, policy *state.ManagedPolicy) {
+	blocked := make(map[string]bool)
+	for i, m := range result.Marketplaces {
+		if m.Action != ActionAdd && m.Action != ActionUpdate {
+			continue
+		}
+		repo := ""
+		if m.Desired != nil {
+			repo = m.Desired.Repo
+		}
+		if policy.MarketplaceBlocked(m.Alias, repo) {
+			result.Marketplaces[i].Action = ActionManaged
+			blocked[m.Alias] = true
+		}
+	}
+
+	for i, p := range result.Plugins {
+		switch p.Action {
+		case ActionEnable, ActionDisable:
+			// The current state already reflects any forced value, so a
+			// remaining change on a forced plugin can never be applied
+			if _, forced := policy.ForcedPluginState(p.Name); forced {
+				result.Plugins[i].Action = ActionManaged
+			}
+		case ActionAdd:
+			if _, marketplace, ok := strings.Cut(p.Name, "@"); ok && blocked[marketplace] {
+				result.Plugins[i].Action = ActionManaged
+			} else if enabled, forced := policy.ForcedPluginState(p.Name); forced && !enabled {
+				result.Plugins[i].Action = ActionManaged
+			}
+		}
 	}
-	return result
 }
 
 func computeMarketplaceDiffs(desired map[string]config.Marketplace, current map[string]state.MarketplaceState) []MarketplaceDiff {
@@ -90,7 +180,19 @@ func computePluginDiffs(desired []config.Plugin, current map[string]state.Plugin
 
 		seen[fullName] = true
 
-		if c, exists := current[fullName]; exists {
+		// Clewfile plugins are installed at user scope. Installs of the
+		// same plugin for projects are separate, so a plugin installed only
+		// for projects still needs a user install.
+		scope := d.Scope
+		if scope == "" {
+			scope = "user"
+		}
+		c, exists := current[fullName]
+		if exists {
+			c, exists = c.AtScope(scope, "")
+		}
+
+		if exists {
 			currentCopy := c
 			action := ActionNone
 
@@ -102,11 +204,6 @@ func computePluginDiffs(desired []config.Plugin, current map[string]state.Plugin
 				action = ActionDisable
 			}
 
-			// Check scope mismatch (would need reinstall)
-			if d.Scope != "" && d.Scope != c.Scope {
-				action = ActionUpdate
-			}
-
 			diffs = append(diffs, PluginDiff{
 				Name:    fullName,
 				Action:  action,
@@ -100,6 +100,42 @@ func TestComputePlugins(t *testing.T) {
 	}
 }
 
+func TestComputePluginUserInstall(t *testing.T) {
+	clewfile := &config.Clewfile{
+		Plugins: []config.Plugin{
+			{Name: "both@marketplace"},
+			{Name: "project-only@marketplace", Scope: "user"},
+		},
+		Marketplaces: make(map[string]config.Marketplace),
+	}
+	current := &state.State{
+		Plugins: map[string]state.PluginState{
+			// Most recent install first, as in installed_plugins.json
+			"both@marketplace": {Scope: "project", Version: "2.0.0", Enabled: true, Installs: []state.PluginInstall{
+				{Scope: "project", ProjectPath: "/work/app", Version: "2.0.0"},
+				{Scope: "user", Version: "1.0.0"},
+			}},
+			"project-only@marketplace": {Scope: "project", Enabled: true, Installs: []state.PluginInstall{
+				{Scope: "project", ProjectPath: "/work/app"},
+			}},
+		},
+		Marketplaces: make(map[string]state.MarketplaceState),
+	}
+
+	for _, p := range Compute(clewfile, current).Plugins {
+		switch p.Name {
+		case "both@marketplace":
+			if p.Action != ActionNone || p.Current.Scope != "user" || p.Current.Version != "1.0.0" {
+				t.Errorf("both: action %s, current %+v; want none against the user install", p.Action, p.Current)
+			}
+		case "project-only@marketplace":
+			if p.Action != ActionAdd {
+				t.Errorf("project-only: action %s, want add at user scope", p.Action)
+			}
+		}
+	}
+}
+
 func TestSummary(t *testing.T) {
 	result := &Result{
 		Marketplaces: []MarketplaceDiff{
@@ -129,3 +165,143 @@ func TestSummary(t *testing.T) {
 		t.Errorf("attention = %d, want 2", attention)
 	}
 }
+
+func TestComputeManagedPolicy(t *testing.T) {
+	clewfile := &config.Clewfile{
+		Marketplaces: map[string]config.Marketplace{
+			"allowed": {Repo: "corp/allowed"},
+			"blocked": {Repo: "evil/blocked"},
+		},
+		Plugins: []config.Plugin{
+			{Name: "forced-on@allowed", Enabled: boolPtr(false)},
+			{Name: "free@allowed", Enabled: boolPtr(false)},
+			{Name: "tool@blocked"},
+			{Name: "banned@allowed"},
+		},
+	}
+
+	current := &state.State{
+		Marketplaces: map[string]state.MarketplaceState{},
+		Plugins: map[string]state.PluginState{
+			"forced-on@allowed": {Name: "forced-on", Enabled: true, EnabledSource: state.ManagedSettingsFile},
+			"free@allowed":      {Name: "free", Enabled: true},
+		},
+		Managed: &state.ManagedPolicy{
+			EnabledPlugins:      map[string]bool{"forced-on@allowed": true, "banned@allowed": false},
+			AllowedMarketplaces: []string{"corp/allowed"},
+		},
+	}
+
+	result := Compute(clewfile, current)
+
+	wantMarketplaces := map[string]Action{"allowed": ActionAdd, "blocked": ActionManaged}
+	for _, m := range result.Marketplaces {
+		if m.Action != wantMarketplaces[m.Alias] {
+			t.Errorf("marketplace %s action = %s, want %s", m.Alias, m.Action, wantMarketplaces[m.Alias])
+		}
+	}
+
+	wantPlugins := map[string]Action{
+		"forced-on@allowed": ActionManaged,
+		"free@allowed":      ActionDisable,
+		"tool@blocked":      ActionManaged,
+		"banned@allowed":    ActionManaged,
+	}
+	for _, p := range result.Plugins {
+		if p.Action != wantPlugins[p.Name] {
+			t.Errorf("plugin %s action = %s, want %s", p.Name, p.Action, wantPlugins[p.Name])
+		}
+	}
+
+	_, _, _, attention := result.Summary()
+	if attention != 4 {
+		t.Errorf("attention = %d, want 4 managed items", attention)
+	}
+}
+
+func TestComputeIgnores(t *testing.T) {
+	clewfile := &config.Clewfile{
+		Marketplaces: map[string]config.Marketplace{
+			"official": {Repo: "anthropics/claude-plugins"},
+		},
+		Plugins: []config.Plugin{
+			{Name: "scratch-tool@scratch"},
+		},
+		Ignore: config.IgnoreConfig{
+			Marketplaces: []string{"scratch"},
+			Plugins:      []string{"experiment@official", "scratch-tool@scratch"},
+		},
+	}
+
+	current := &state.State{
+		Marketplaces: map[string]state.MarketplaceState{
+			"official": {Alias: "official", Repo: "anthropics/claude-plugins"},
+			"scratch":  {Alias: "scratch", Repo: "me/scratch"},
+			"other":    {Alias: "other", Repo: "someone/other"},
+		},
+		Plugins: map[string]state.PluginState{
+			"experiment@official": {Name: "experiment", Marketplace: "official", Enabled: true},
+			"extra@official":      {Name: "extra", Marketplace: "official", Enabled: true},
+		},
+	}
+
+	result := Compute(clewfile, current)
+
+	wantMarketplaces := map[string]Action{"official": ActionNone, "other": ActionRemove}
+	if len(result.Marketplaces) != len(wantMarketplaces) {
+		t.Errorf("Marketplaces = %+v, want ignored scratch dropped", result.Marketplaces)
+	}
+	for _, m := range result.Marketplaces {
+		if m.Action != wantMarketplaces[m.Alias] {
+			t.Errorf("marketplace %s action = %s, want %s", m.Alias, m.Action, wantMarketplaces[m.Alias])
+		}
+	}
+
+	// Ignoring only hides extras; a declared plugin is still installed
+	wantPlugins := map[string]Action{"extra@official": ActionRemove, "scratch-tool@scratch": ActionAdd}
+	if len(result.Plugins) != len(wantPlugins) {
+		t.Errorf("Plugins = %+v, want ignored experiment dropped", result.Plugins)
+	}
+	for _, p := range result.Plugins {
+		if p.Action != wantPlugins[p.Name] {
+			t.Errorf("plugin %s action = %s, want %s", p.Name, p.Action, wantPlugins[p.Name])
+		}
+	}
+}
+
+func TestComputeKeybindings(t *testing.T) {
+	clewfile := &config.Clewfile{
+		Keybindings: map[string]map[string]string{
+			"Chat":   {"ctrl+e": "chat:externalEditor", "ctrl+s": "chat:stash"},
+			"Global": {"ctrl+t": ""},
+		},
+	}
+	current := &state.State{
+		Keybindings: state.Keybindings{
+			"Chat": {"ctrl+e": "chat:externalEditor", "ctrl+s": "chat:submit", "ctrl+x": "chat:cancel"},
+		},
+	}
+
+	result := Compute(clewfile, current)
+	got := make(map[string]Action)
+	for _, k := range result.Keybindings {
+		got[k.Name()] = k.Action
+	}
+	want := map[string]Action{
+		"Chat ctrl+e":   ActionNone,
+		"Chat ctrl+s":   ActionUpdate,
+		"Global ctrl+t": ActionAdd,
+	}
+	if len(got) != len(want) {
+		t.Errorf("Keybindings = %v, want %v (undeclared keys left alone)", got, want)
+	}
+	for name, action := range want {
+		if got[name] != action {
+			t.Errorf("%s: action = %q, want %q", name, got[name], action)
+		}
+	}
+
+	if add, update, _, _ := result.Summary(); add != 1 || update != 1 {
+		t.Errorf("Summary() add = %d, update = %d; want 1, 1", add, update)
+	}
+}
@@ -17,6 +17,8 @@ const (
 	ActionEnable  Action = "enable"   // Needs to be enabled
 	ActionDisable Action = "disable"  // Needs to be disabled
 	ActionSkipGit Action = "skip_git" // Skipped due to git status issues
+	ActionManaged Action = "managed"  // Blocked by managed (enterprise) settings; cannot change
+	ActionBlocked Action = "blocked"  // Blocked by the Clewfile's scan policy (clew scan)
 )
 
 // MarketplaceDiff represents the diff for a marketplace.
@@ -35,15 +37,48 @@ type PluginDiff struct {
 	Desired *config.Plugin
 }
 
+// KeybindingDiff represents the diff for one key binding declared in the
+// Clewfile. Current is "" when the key has no custom binding.
+type KeybindingDiff struct {
+	Context string
+	Key     string
+	Action  Action
+	Current string
+	Desired string
+}
+
+// Name identifies the binding in output, e.g. "Chat ctrl+e".
+func (k KeybindingDiff) Name() string {
+	return k.Context + " " + k.Key
+}
+
+// OnFailure returns the marketplace's on_failure policy, or "" if it sets none.
+func (m MarketplaceDiff) OnFailure() string {
+	if m.Desired == nil {
+		return ""
+	}
+	return m.Desired.OnFailure
+}
+
+// OnFailure returns the plugin's on_failure policy, or "" if it sets none.
+func (p PluginDiff) OnFailure() string {
+	if p.Desired == nil {
+		return ""
+	}
+	return p.Desired.OnFailure
+}
+
 // Result contains the complete diff between desired and current state.
 type Result struct {
 	Marketplaces []MarketplaceDiff
 	Plugins      []PluginDiff
+	Keybindings  []KeybindingDiff `json:",omitempty" yaml:",omitempty"`
 }
 
-// Compute calculates the diff between a Clewfile and current state.
+// Compute calculates the diff between a Clewfile and current state with
+// the default pipeline.
 func Compute(clewfile *config.Clewfile, current *state.State) *Result {
-	return compute(clewfile, current)
+	return DefaultPipeline().Compute(clewfile, current)
 }
 
 // Summary returns counts of actions needed.
@@ -54,7 +89,7 @@ func (r *Result) Summary() (add, update, remove, attention int) {
 			add++
 		case ActionUpdate:
 			update++
-		case ActionRemove, ActionSkipGit:
+		case ActionRemove, ActionSkipGit, ActionManaged, ActionBlocked:
 			attention++
 		}
 	}
@@ -64,9 +99,17 @@ func (r *Result) Summary() (add, update, remove, attention int) {
 			add++
 		case ActionUpdate, ActionEnable, ActionDisable:
 			update++
-		case ActionRemove, ActionSkipGit:
+		case ActionRemove, ActionSkipGit, ActionManaged, ActionBlocked:
 			attention++
 		}
 	}
+	for _, k := range r.Keybindings {
+		switch k.Action {
+		case ActionAdd:
+			add++
+		case ActionUpdate:
+			update++
+		}
+	}
 	return
 }
@@ -0,0 +1,71 @@
+package diff
+
+import "fmt"
+
+// Reason explains which comparison produced the marketplace's action.
+func (m MarketplaceDiff) Reason() string {
+	switch m.Action {
+	case ActionNone:
+		return "declared and installed with the same repo and ref"
+	case ActionAdd:
+		return "declared in the Clewfile but not installed"
+	case ActionRemove:
+		return "installed but not declared in the Clewfile"
+	case ActionUpdate:
+		if m.Desired != nil && m.Current != nil {
+			if m.Desired.Repo != m.Current.Repo {
+				return fmt.Sprintf("repo differs: the Clewfile has %s, installed from %s", orNone(m.Desired.Repo), orNone(m.Current.Repo))
+			}
+			return fmt.Sprintf("ref differs: the Clewfile has %s, installed at %s", orNone(m.Desired.Ref), orNone(m.Current.Ref))
+		}
+		return "repo or ref differs from the Clewfile"
+	case ActionSkipGit:
+		return "local repository has uncommitted changes or a HEAD commit not signed by an allowed key, so it is left alone"
+	case ActionManaged:
+		return "managed settings do not allow adding or changing this marketplace"
+	default:
+		return string(m.Action)
+	}
+}
+
+// Reason explains which comparison produced the plugin's action.
+func (p PluginDiff) Reason() string {
+	switch p.Action {
+	case ActionNone:
+		if p.Current != nil && !p.Current.Enabled {
+			return "declared disabled and installed disabled"
+		}
+		return "declared and installed, enabled state matches"
+	case ActionAdd:
+		return "declared in the Clewfile but not installed"
+	case ActionRemove:
+		return "installed but not declared in the Clewfile"
+	case ActionEnable:
+		if p.Desired != nil && p.Desired.Enabled == nil {
+			return "the Clewfile does not set enabled, which means enabled, but the plugin is disabled"
+		}
+		return "the Clewfile sets enabled: true, but the plugin is disabled"
+	case ActionDisable:
+		return "the Clewfile sets enabled: false, but the plugin is enabled"
+	case ActionUpdate:
+		if p.Desired != nil && p.Current != nil {
+			return fmt.Sprintf("scope differs: the Clewfile has %s, installed at %s; changing scope needs a reinstall", p.Desired.Scope, orNone(p.Current.Scope))
+		}
+		return "scope differs from the Clewfile"
+	case ActionSkipGit:
+		return "local repository has uncommitted changes or a HEAD commit not signed by an allowed key, so it is left alone"
+	case ActionManaged:
+		return "managed settings force this plugin's state or block its marketplace"
+	case ActionBlocked:
+		return "clew scan scored the plugin at or above scan.block_score in the Clewfile, or could not scan its files (plugins of a marketplace this sync adds are scanned on the next sync)"
+	default:
+		return string(p.Action)
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
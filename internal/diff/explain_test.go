@@ -0,0 +1,47 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestMarketplaceReason(t *testing.T) {
+	tests := []struct {
+		diff MarketplaceDiff
+		want string
+	}{
+		{MarketplaceDiff{Action: ActionAdd}, "not installed"},
+		{MarketplaceDiff{Action: ActionRemove}, "not declared"},
+		{MarketplaceDiff{Action: ActionUpdate, Desired: &config.Marketplace{Repo: "a/new"}, Current: &state.MarketplaceState{Repo: "a/old"}},
+			"repo differs: the Clewfile has a/new, installed from a/old"},
+		{MarketplaceDiff{Action: ActionUpdate, Desired: &config.Marketplace{Repo: "a/b", Ref: "v2"}, Current: &state.MarketplaceState{Repo: "a/b"}},
+			"ref differs: the Clewfile has v2, installed at (none)"},
+	}
+	for _, tt := range tests {
+		if got := tt.diff.Reason(); !strings.Contains(got, tt.want) {
+			t.Errorf("Reason() for %s = %q, want it to contain %q", tt.diff.Action, got, tt.want)
+		}
+	}
+}
+
+func TestPluginReason(t *testing.T) {
+	tests := []struct {
+		diff PluginDiff
+		want string
+	}{
+		{PluginDiff{Action: ActionEnable, Desired: &config.Plugin{}}, "does not set enabled"},
+		{PluginDiff{Action: ActionEnable, Desired: &config.Plugin{Enabled: boolPtr(true)}}, "sets enabled: true"},
+		{PluginDiff{Action: ActionDisable, Desired: &config.Plugin{Enabled: boolPtr(false)}}, "sets enabled: false"},
+		{PluginDiff{Action: ActionUpdate, Desired: &config.Plugin{Scope: "user"}, Current: &state.PluginState{Scope: "project"}},
+			"scope differs: the Clewfile has user, installed at project"},
+		{PluginDiff{Action: ActionNone, Current: &state.PluginState{}}, "declared disabled"},
+	}
+	for _, tt := range tests {
+		if got := tt.diff.Reason(); !strings.Contains(got, tt.want) {
+			t.Errorf("Reason() for %s = %q, want it to contain %q", tt.diff.Action, got, tt.want)
+		}
+	}
+}
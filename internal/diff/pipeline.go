@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// Comparator is one stage of diff computation. It compares one kind of
+// resource and adds its items to result, or adjusts the items earlier
+// stages added (as the ignore and managed stages do).
+type Comparator func(clewfile *config.Clewfile, current *state.State, result *Result)
+
+// Names of the default stages, in the order they run.
+const (
+	StageMarketplaces = "marketplaces"
+	StagePlugins      = "plugins"
+	StageKeybindings  = "keybindings"
+	StageIgnore       = "ignore"
+	StageManaged      = "managed"
+)
+
+// Pipeline runs comparators in order to compute a diff. New kinds of
+// resources are compared by registering a stage rather than by changing
+// Compute.
+type Pipeline struct {
+	stages []stage
+}
+
+// stage is a registered comparator.
+type stage struct {
+	name    string
+	compare Comparator
+}
+
+// NewPipeline returns a pipeline without stages.
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// DefaultPipeline returns the stages Compute runs: marketplaces, plugins,
+// key bindings, then the Clewfile's ignore list and managed settings
+// policy.
+func DefaultPipeline() *Pipeline {
+	p := NewPipeline()
+	p.Register(StageMarketplaces, compareMarketplaces)
+	p.Register(StagePlugins, comparePlugins)
+	p.Register(StageKeybindings, compareKeybindings)
+	p.Register(StageIgnore, func(clewfile *config.Clewfile, _ *state.State, result *Result) {
+		applyIgnores(result, clewfile.Ignore)
+	})
+	p.Register(StageManaged, func(_ *config.Clewfile, current *state.State, result *Result) {
+		if current.Managed != nil {
+			applyManagedPolicy(result, current.Managed)
+		}
+	})
+	return p
+}
+
+// Register adds a stage at the end of the pipeline. A stage registered
+// under an existing name replaces that stage in place.
+func (p *Pipeline) Register(name string, compare Comparator) {
+	for i, s := range p.stages {
+		if s.name == name {
+			p.stages[i].compare = compare
+			return
+		}
+	}
+	p.stages = append(p.stages, stage{name: name, compare: compare})
+}
+
+// RegisterBefore adds a stage just before the stage named before, or at
+// the end if there is no such stage.
+func (p *Pipeline) RegisterBefore(before, name string, compare Comparator) {
+	for i, s := range p.stages {
+		if s.name == before {
+			p.stages = append(p.stages[:i], append([]stage{{name: name, compare: compare}}, p.stages[i:]...)...)
+			return
+		}
+	}
+	p.Register(name, compare)
+}
+
+// Stages returns the names of the stages in the order they run.
+func (p *Pipeline) Stages() []string {
+	names := make([]string, len(p.stages))
+	for i, s := range p.stages {
+		names[i] = s.name
+	}
+	return names
+}
+
+// Compute runs every stage in order and returns the diff.
+func (p *Pipeline) Compute(clewfile *config.Clewfile, current *state.State) *Result {
+	result := &Result{}
+	for _, s := range p.stages {
+		s.compare(clewfile, current, result)
+	}
+	return result
+}
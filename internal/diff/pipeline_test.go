@@ -0,0 +1,53 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestDefaultPipelineStages(t *testing.T) {
+	got := strings.Join(DefaultPipeline().Stages(), ",")
+	if want := "marketplaces,plugins,keybindings,ignore,managed"; got != want {
+		t.Errorf("Stages() = %s, want %s", got, want)
+	}
+}
+
+func TestPipelineRegister(t *testing.T) {
+	clewfile := &config.Clewfile{
+		Plugins: []config.Plugin{{Name: "lint@tools"}},
+		Ignore:  config.IgnoreConfig{Plugins: []string{"extra@tools"}},
+	}
+	current := &state.State{Plugins: map[string]state.PluginState{}}
+
+	p := DefaultPipeline()
+	// A custom stage before ignore sees the items it filters out
+	var seen []string
+	p.RegisterBefore(StageIgnore, "audit", func(_ *config.Clewfile, _ *state.State, result *Result) {
+		result.Plugins = append(result.Plugins, PluginDiff{Name: "extra@tools", Action: ActionRemove})
+		for _, d := range result.Plugins {
+			seen = append(seen, d.Name)
+		}
+	})
+	// Replacing a stage keeps its position
+	p.Register(StageMarketplaces, func(_ *config.Clewfile, _ *state.State, result *Result) {
+		result.Marketplaces = append(result.Marketplaces, MarketplaceDiff{Alias: "custom", Action: ActionAdd})
+	})
+
+	if got, want := strings.Join(p.Stages(), ","), "marketplaces,plugins,keybindings,audit,ignore,managed"; got != want {
+		t.Errorf("Stages() = %s, want %s", got, want)
+	}
+
+	result := p.Compute(clewfile, current)
+	if strings.Join(seen, ",") != "lint@tools,extra@tools" {
+		t.Errorf("audit stage saw %v", seen)
+	}
+	if len(result.Plugins) != 1 || result.Plugins[0].Name != "lint@tools" {
+		t.Errorf("Plugins = %+v, want extra@tools ignored", result.Plugins)
+	}
+	if len(result.Marketplaces) != 1 || result.Marketplaces[0].Alias != "custom" {
+		t.Errorf("Marketplaces = %+v, want the replacement stage's", result.Marketplaces)
+	}
+}
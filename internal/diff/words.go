@@ -0,0 +1,144 @@
+package diff
+
+import (
+	"strconv"
+	"unicode"
+)
+
+// FieldChange is one setting of an updated item that differs between the
+// current state and the Clewfile.
+type FieldChange struct {
+	Field   string
+	Current string
+	Desired string
+}
+
+// Changes returns the fields an update would change: repo and ref.
+func (m MarketplaceDiff) Changes() []FieldChange {
+	if m.Current == nil || m.Desired == nil {
+		return nil
+	}
+	var changes []FieldChange
+	if m.Current.Repo != m.Desired.Repo {
+		changes = append(changes, FieldChange{"repo", m.Current.Repo, m.Desired.Repo})
+	}
+	if m.Current.Ref != m.Desired.Ref {
+		changes = append(changes, FieldChange{"ref", m.Current.Ref, m.Desired.Ref})
+	}
+	return changes
+}
+
+// Changes returns the fields an update would change: scope and enabled.
+func (p PluginDiff) Changes() []FieldChange {
+	if p.Current == nil || p.Desired == nil {
+		return nil
+	}
+	var changes []FieldChange
+	if p.Desired.Scope != "" && p.Desired.Scope != p.Current.Scope {
+		changes = append(changes, FieldChange{"scope", p.Current.Scope, p.Desired.Scope})
+	}
+	if enabled := p.Desired.Enabled == nil || *p.Desired.Enabled; enabled != p.Current.Enabled {
+		changes = append(changes, FieldChange{"enabled", strconv.FormatBool(p.Current.Enabled), strconv.FormatBool(enabled)})
+	}
+	return changes
+}
+
+// Changes returns the action an added or updated binding would change.
+// An empty action is shown as "(unbound)".
+func (k KeybindingDiff) Changes() []FieldChange {
+	if k.Action != ActionAdd && k.Action != ActionUpdate {
+		return nil
+	}
+	show := func(action string) string {
+		if action == "" {
+			return "(unbound)"
+		}
+		return action
+	}
+	current := ""
+	if k.Action == ActionUpdate {
+		current = show(k.Current)
+	}
+	return []FieldChange{{"action", current, show(k.Desired)}}
+}
+
+// Segment is a run of text in a word-level comparison. Changed segments
+// are missing from the other side.
+type Segment struct {
+	Text    string
+	Changed bool
+}
+
+// Words compares before and after word by word, where words are runs of
+// letters and digits and every other character stands alone, so a changed
+// path component or version number is marked rather than the whole value.
+func Words(before, after string) (from, to []Segment) {
+	a, b := splitWords(before), splitWords(after)
+
+	// Longest common subsequence of words
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			from = appendSegment(from, a[i], false)
+			to = appendSegment(to, b[j], false)
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			to = appendSegment(to, b[j], true)
+			j++
+		default:
+			from = appendSegment(from, a[i], true)
+			i++
+		}
+	}
+	return from, to
+}
+
+// splitWords splits s into runs of letters and digits and single other
+// characters.
+func splitWords(s string) []string {
+	var words []string
+	start := -1
+	for i, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, s[start:i])
+			start = -1
+		}
+		words = append(words, string(r))
+	}
+	if start >= 0 {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+// appendSegment adds text, merging it into the last segment when both are
+// changed or both unchanged.
+func appendSegment(segments []Segment, text string, changed bool) []Segment {
+	if n := len(segments); n > 0 && segments[n-1].Changed == changed {
+		segments[n-1].Text += text
+		return segments
+	}
+	return append(segments, Segment{Text: text, Changed: changed})
+}
@@ -0,0 +1,71 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		before, after string
+		from, to      []Segment
+	}{
+		{
+			"v1.2.0", "v1.3.0",
+			[]Segment{{"v1.", false}, {"2", true}, {".0", false}},
+			[]Segment{{"v1.", false}, {"3", true}, {".0", false}},
+		},
+		{
+			"https://github.com/acme/tools.git", "https://gitlab.com/acme/tools.git",
+			[]Segment{{"https://", false}, {"github", true}, {".com/acme/tools.git", false}},
+			[]Segment{{"https://", false}, {"gitlab", true}, {".com/acme/tools.git", false}},
+		},
+		{
+			"", "main",
+			nil,
+			[]Segment{{"main", true}},
+		},
+		{
+			"acme/tools", "acme/tools-next",
+			[]Segment{{"acme/tools", false}},
+			[]Segment{{"acme/tools", false}, {"-next", true}},
+		},
+	}
+	for _, tt := range tests {
+		from, to := Words(tt.before, tt.after)
+		if !reflect.DeepEqual(from, tt.from) || !reflect.DeepEqual(to, tt.to) {
+			t.Errorf("Words(%q, %q) = %v, %v, want %v, %v", tt.before, tt.after, from, to, tt.from, tt.to)
+		}
+	}
+}
+
+func TestChanges(t *testing.T) {
+	m := MarketplaceDiff{
+		Alias:   "tools",
+		Action:  ActionUpdate,
+		Current: &state.MarketplaceState{Repo: "acme/tools", Ref: "v1"},
+		Desired: &config.Marketplace{Repo: "acme/tools", Ref: "v2"},
+	}
+	if got, want := m.Changes(), []FieldChange{{"ref", "v1", "v2"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MarketplaceDiff.Changes() = %v, want %v", got, want)
+	}
+
+	disabled := false
+	p := PluginDiff{
+		Name:    "lint@tools",
+		Action:  ActionUpdate,
+		Current: &state.PluginState{Scope: "project", Enabled: true},
+		Desired: &config.Plugin{Name: "lint@tools", Scope: "user", Enabled: &disabled},
+	}
+	want := []FieldChange{{"scope", "project", "user"}, {"enabled", "true", "false"}}
+	if got := p.Changes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PluginDiff.Changes() = %v, want %v", got, want)
+	}
+
+	if got := (MarketplaceDiff{Action: ActionAdd, Desired: &config.Marketplace{Repo: "acme/tools"}}).Changes(); got != nil {
+		t.Errorf("Changes() for an add = %v, want nil", got)
+	}
+}
@@ -0,0 +1,170 @@
+// Package drift detects content-level changes to local plugins.
+//
+// Version numbers of local plugins rarely change when their commands,
+// agents or hooks are edited, so clew records a hash of those files when a
+// plugin is installed and compares against it later.
+package drift
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/state"
+	"github.com/adamancini/clew/internal/xdg"
+)
+
+// contentDirs are the plugin subdirectories whose files are hashed.
+var contentDirs = []string{"commands", "agents", "hooks"}
+
+// HashPlugin returns a hash over every file in the plugin's commands,
+// agents and hooks directories. Missing directories contribute nothing.
+func HashPlugin(dir string) (string, error) {
+	var files []string
+	for _, sub := range contentDirs {
+		root := filepath.Join(dir, sub)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == root {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if d.Type().IsRegular() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to walk %s: %w", root, err)
+		}
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, path := range files {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		// Length-prefix each part so renames and content moves change the hash
+		_, _ = fmt.Fprintf(h, "%d:%s%d:", len(rel), filepath.ToSlash(rel), len(data))
+		_, _ = h.Write(data)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Record is the hash recorded for a plugin when it was installed.
+type Record struct {
+	Hash       string    `json:"hash"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Store persists recorded plugin hashes.
+type Store struct {
+	path    string
+	Records map[string]Record `json:"plugins"`
+}
+
+// DefaultPath returns the hash store location in clew's cache directory.
+func DefaultPath() (string, error) {
+	dir, err := xdg.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "plugin-hashes.json"), nil
+}
+
+// Load reads the store at path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, Records: make(map[string]Record)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read plugin hashes: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse plugin hashes: %w", err)
+	}
+	if s.Records == nil {
+		s.Records = make(map[string]Record)
+	}
+	return s, nil
+}
+
+// Save writes the store back to disk.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plugin hashes: %w", err)
+	}
+	return atomicfile.WriteFile(s.path, data, 0644)
+}
+
+// Update records hashes for local plugins. Plugins named in reinstalled
+// get a fresh hash; others are only recorded if no baseline exists yet, so
+// later edits remain detectable. It reports whether any record changed.
+func (s *Store) Update(plugins map[string]state.PluginState, reinstalled map[string]bool) (bool, error) {
+	now := time.Now().UTC()
+	changed := false
+	for name, p := range plugins {
+		if !p.IsLocal || p.InstallPath == "" {
+			continue
+		}
+		if _, known := s.Records[name]; known && !reinstalled[name] {
+			continue
+		}
+		hash, err := HashPlugin(p.InstallPath)
+		if err != nil {
+			return changed, err
+		}
+		s.Records[name] = Record{Hash: hash, RecordedAt: now}
+		changed = true
+	}
+	return changed, nil
+}
+
+// Change describes a local plugin whose contents differ from the recorded hash.
+type Change struct {
+	Name       string    `json:"name" yaml:"name"`
+	RecordedAt time.Time `json:"recorded_at" yaml:"recorded_at"`
+}
+
+// Detect returns local plugins whose current contents no longer match
+// their recorded hash, sorted by name. Plugins without a record are skipped.
+func (s *Store) Detect(plugins map[string]state.PluginState) ([]Change, error) {
+	var changes []Change
+	for name, p := range plugins {
+		rec, known := s.Records[name]
+		if !known || !p.IsLocal || p.InstallPath == "" {
+			continue
+		}
+		hash, err := HashPlugin(p.InstallPath)
+		if err != nil {
+			return nil, err
+		}
+		if hash != rec.Hash {
+			changes = append(changes, Change{Name: name, RecordedAt: rec.RecordedAt})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes, nil
+}
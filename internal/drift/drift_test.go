@@ -0,0 +1,104 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adamancini/clew/internal/state"
+)
+
+func writePluginFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHashPlugin(t *testing.T) {
+	dir := t.TempDir()
+	writePluginFile(t, dir, "commands/review.md", "review")
+	writePluginFile(t, dir, "agents/helper.md", "helper")
+
+	h1, err := HashPlugin(dir)
+	if err != nil {
+		t.Fatalf("HashPlugin() error = %v", err)
+	}
+
+	// Files outside the hashed directories are ignored
+	writePluginFile(t, dir, "README.md", "docs")
+	if h2, _ := HashPlugin(dir); h2 != h1 {
+		t.Error("hash changed after editing a non-content file")
+	}
+
+	writePluginFile(t, dir, "hooks/hooks.json", "{}")
+	h3, _ := HashPlugin(dir)
+	if h3 == h1 {
+		t.Error("hash did not change after adding a hook")
+	}
+
+	writePluginFile(t, dir, "commands/review.md", "review v2")
+	if h4, _ := HashPlugin(dir); h4 == h3 {
+		t.Error("hash did not change after editing a command")
+	}
+}
+
+func TestStoreDetect(t *testing.T) {
+	pluginDir := t.TempDir()
+	writePluginFile(t, pluginDir, "commands/a.md", "a")
+
+	plugins := map[string]state.PluginState{
+		"local":          {Name: "local", IsLocal: true, InstallPath: pluginDir},
+		"remote@market":  {Name: "remote", InstallPath: pluginDir},
+		"unrecorded-dir": {Name: "unrecorded-dir", IsLocal: true, InstallPath: ""},
+	}
+
+	storePath := filepath.Join(t.TempDir(), "clew", "plugin-hashes.json")
+	store, err := Load(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := store.Update(plugins, nil); err != nil || !changed {
+		t.Fatalf("Update() = %v, %v; want true, nil", changed, err)
+	}
+	if len(store.Records) != 1 {
+		t.Fatalf("Records = %v, want only the local plugin", store.Records)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = Load(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changes, _ := store.Detect(plugins); len(changes) != 0 {
+		t.Errorf("Detect() = %v, want no changes", changes)
+	}
+
+	writePluginFile(t, pluginDir, "commands/a.md", "edited")
+
+	// A baseline is not overwritten unless the plugin was reinstalled
+	if changed, err := store.Update(plugins, nil); err != nil || changed {
+		t.Fatalf("Update() = %v, %v; want false, nil", changed, err)
+	}
+	changes, err := store.Detect(plugins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Name != "local" {
+		t.Errorf("Detect() = %v, want local", changes)
+	}
+
+	if _, err := store.Update(plugins, map[string]bool{"local": true}); err != nil {
+		t.Fatal(err)
+	}
+	if changes, _ := store.Detect(plugins); len(changes) != 0 {
+		t.Errorf("Detect() after reinstall = %v, want none", changes)
+	}
+}
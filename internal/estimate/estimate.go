@@ -0,0 +1,186 @@
+// Package estimate predicts how long planned operations will take from how
+// long the same kinds of operation took before. Timings are kept per
+// operation kind ("plugin add", "marketplace add", ...) in clew's state
+// directory and updated after every sync, apply and redo.
+package estimate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/xdg"
+)
+
+// window bounds how many past runs an average reflects: the mean moves a
+// 1/window step toward each new timing once window have been seen, so the
+// estimate follows a machine that got faster or slower.
+const window = 20
+
+// Defaults are used for kinds that have not been timed on this machine.
+var Defaults = map[string]time.Duration{
+	"marketplace add": 8 * time.Second,
+	"plugin add":      5 * time.Second,
+	"plugin enable":   2 * time.Second,
+	"plugin disable":  2 * time.Second,
+	"keybinding set":  100 * time.Millisecond,
+}
+
+// fallback is used for kinds without a default.
+const fallback = 3 * time.Second
+
+// Timing is the running average duration of one kind of operation.
+type Timing struct {
+	Count  int     `json:"count"`
+	MeanMs float64 `json:"mean_ms"`
+}
+
+// History holds the timings recorded on this machine.
+type History struct {
+	Kinds map[string]Timing `json:"kinds"`
+}
+
+// Kind names an operation kind, e.g. Kind("plugin", "add") is "plugin add".
+func Kind(itemType, action string) string {
+	return itemType + " " + action
+}
+
+// DefaultPath returns the timings file in clew's state directory.
+func DefaultPath() (string, error) {
+	dir, err := xdg.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "timings.json"), nil
+}
+
+// Load reads the history at path. A machine without one starts empty.
+func Load(path string) (*History, error) {
+	h := &History{Kinds: make(map[string]Timing)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("failed to read timings: %w", err)
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse timings: %w", err)
+	}
+	if h.Kinds == nil {
+		h.Kinds = make(map[string]Timing)
+	}
+	return h, nil
+}
+
+// Save writes the history to path.
+func (h *History) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal timings: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write timings: %w", err)
+	}
+	return nil
+}
+
+// Observe adds one measured duration of kind to the history.
+func (h *History) Observe(kind string, d time.Duration) {
+	t := h.Kinds[kind]
+	t.Count++
+	ms := float64(d) / float64(time.Millisecond)
+	t.MeanMs += (ms - t.MeanMs) / float64(min(t.Count, window))
+	h.Kinds[kind] = t
+}
+
+// measured reports whether the history has timings for kind.
+func (h *History) measured(kind string) bool {
+	t, ok := h.Kinds[kind]
+	return ok && t.Count > 0
+}
+
+// Estimate returns how long one operation of kind is expected to take.
+func (h *History) Estimate(kind string) time.Duration {
+	if h.measured(kind) {
+		return time.Duration(h.Kinds[kind].MeanMs * float64(time.Millisecond))
+	}
+	if d, ok := Defaults[kind]; ok {
+		return d
+	}
+	return fallback
+}
+
+// Operation is one operation a diff would run, with its estimate.
+type Operation struct {
+	Type     string        `json:"type" yaml:"type"`
+	Name     string        `json:"name" yaml:"name"`
+	Action   string        `json:"action" yaml:"action"`
+	Estimate time.Duration `json:"-" yaml:"-"`
+	Ms       int64         `json:"estimate_ms" yaml:"estimate_ms"`
+	Measured bool          `json:"measured" yaml:"measured"` // Based on timings from this machine rather than a default
+}
+
+// Total returns the estimated time to run every operation in d.
+func (h *History) Total(d *diff.Result) time.Duration {
+	_, total := h.Plan(d)
+	return total
+}
+
+// Plan lists the operations sync would run for d, in the order it runs
+// them, each with its estimate, and returns their total.
+func (h *History) Plan(d *diff.Result) ([]Operation, time.Duration) {
+	var ops []Operation
+	var total time.Duration
+	add := func(itemType, name, action string) {
+		kind := Kind(itemType, action)
+		est := h.Estimate(kind)
+		ops = append(ops, Operation{Type: itemType, Name: name, Action: action, Estimate: est, Ms: est.Milliseconds(), Measured: h.measured(kind)})
+		total += est
+	}
+
+	for _, m := range d.Marketplaces {
+		if m.Action == diff.ActionAdd {
+			add("marketplace", m.Alias, "add")
+		}
+	}
+	for _, p := range d.Plugins {
+		switch p.Action {
+		case diff.ActionAdd:
+			add("plugin", p.Name, "add")
+			// claude installs plugins enabled
+			if p.Desired != nil && p.Desired.Enabled != nil && !*p.Desired.Enabled {
+				add("plugin", p.Name, "disable")
+			}
+		case diff.ActionEnable, diff.ActionDisable:
+			add("plugin", p.Name, string(p.Action))
+		}
+	}
+	for _, k := range d.Keybindings {
+		if k.Action == diff.ActionAdd || k.Action == diff.ActionUpdate {
+			add("keybinding", k.Name(), "set")
+		}
+	}
+	return ops, total
+}
+
+// Format renders an estimate for people: "~400ms", "~12s", "~2m10s".
+func Format(d time.Duration) string {
+	switch {
+	case d < 50*time.Millisecond:
+		return "<100ms"
+	case d < time.Second:
+		return "~" + d.Round(100*time.Millisecond).String()
+	case d < time.Minute:
+		return "~" + d.Round(time.Second).String()
+	default:
+		return "~" + d.Round(10*time.Second).String()
+	}
+}
@@ -0,0 +1,124 @@
+package estimate
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+)
+
+func TestEstimateDefaults(t *testing.T) {
+	h := &History{Kinds: make(map[string]Timing)}
+
+	if got := h.Estimate("plugin add"); got != Defaults["plugin add"] {
+		t.Errorf("Estimate(plugin add) = %v, want default %v", got, Defaults["plugin add"])
+	}
+	if got := h.Estimate("widget frob"); got != fallback {
+		t.Errorf("Estimate(widget frob) = %v, want fallback %v", got, fallback)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	h := &History{Kinds: make(map[string]Timing)}
+	h.Observe("plugin add", 2*time.Second)
+	h.Observe("plugin add", 4*time.Second)
+
+	if got := h.Estimate("plugin add"); got != 3*time.Second {
+		t.Errorf("Estimate after two runs = %v, want 3s", got)
+	}
+
+	// Past the window, old timings fade instead of being averaged forever
+	for range 200 {
+		h.Observe("plugin add", time.Second)
+	}
+	if got := h.Estimate("plugin add"); got > 1100*time.Millisecond {
+		t.Errorf("Estimate after many 1s runs = %v, want close to 1s", got)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	disabled := false
+	d := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "acme", Action: diff.ActionAdd},
+			{Alias: "old", Action: diff.ActionRemove},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "a@acme", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "a@acme"}},
+			{Name: "b@acme", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "b@acme", Enabled: &disabled}},
+			{Name: "c@acme", Action: diff.ActionEnable},
+			{Name: "d@acme", Action: diff.ActionNone},
+		},
+	}
+
+	h := &History{Kinds: make(map[string]Timing)}
+	h.Observe("plugin add", time.Second)
+	h.Kinds["marketplace add"] = Timing{} // Recorded without a measurement
+
+	ops, total := h.Plan(d)
+
+	want := []string{"marketplace acme add", "plugin a@acme add", "plugin b@acme add", "plugin b@acme disable", "plugin c@acme enable"}
+	if len(ops) != len(want) {
+		t.Fatalf("got %d operations, want %d: %+v", len(ops), len(want), ops)
+	}
+	for i, op := range ops {
+		if got := op.Type + " " + op.Name + " " + op.Action; got != want[i] {
+			t.Errorf("ops[%d] = %q, want %q", i, got, want[i])
+		}
+	}
+	if !ops[1].Measured || ops[0].Measured {
+		t.Errorf("expected only plugin add to be measured, got %+v", ops)
+	}
+
+	wantTotal := Defaults["marketplace add"] + 2*time.Second + Defaults["plugin disable"] + Defaults["plugin enable"]
+	if total != wantTotal {
+		t.Errorf("total = %v, want %v", total, wantTotal)
+	}
+	if got := h.Total(d); got != total {
+		t.Errorf("Total() = %v, want %v", got, total)
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clew", "timings.json")
+
+	h, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() on missing file: %v", err)
+	}
+	if len(h.Kinds) != 0 {
+		t.Errorf("expected empty history, got %+v", h.Kinds)
+	}
+
+	h.Observe("marketplace add", 1500*time.Millisecond)
+	if err := h.Save(path); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := loaded.Estimate("marketplace add"); got != 1500*time.Millisecond {
+		t.Errorf("Estimate after round trip = %v, want 1.5s", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{10 * time.Millisecond, "<100ms"},
+		{420 * time.Millisecond, "~400ms"},
+		{12300 * time.Millisecond, "~12s"},
+		{130 * time.Second, "~2m10s"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.d); got != tt.want {
+			t.Errorf("Format(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
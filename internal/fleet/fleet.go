@@ -0,0 +1,204 @@
+// Package fleet compares the Claude Code configuration of several machines
+// against a shared Clewfile.
+//
+// Each host is described in an inventory file and reached either over SSH,
+// where clew runs `clew export --output json`, or through a manifest file
+// holding that output, for machines that upload it instead.
+package fleet
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// Inventory lists the hosts of a fleet.
+type Inventory struct {
+	Hosts []Host `yaml:"hosts" json:"hosts"`
+}
+
+// Host is a machine in the fleet. Exactly one of SSH and Manifest is set.
+type Host struct {
+	Name     string `yaml:"name" json:"name"`
+	SSH      string `yaml:"ssh,omitempty" json:"ssh,omitempty"`           // ssh destination, e.g. me@laptop
+	Clew     string `yaml:"clew,omitempty" json:"clew,omitempty"`         // clew binary on the host (default: clew)
+	Manifest string `yaml:"manifest,omitempty" json:"manifest,omitempty"` // File with the host's `clew export --output json`
+}
+
+// Source describes where a host's state comes from.
+func (h Host) Source() string {
+	if h.SSH != "" {
+		return "ssh " + h.SSH
+	}
+	return h.Manifest
+}
+
+// LoadInventory reads an inventory file. Manifest paths are relative to it.
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts file: %w", err)
+	}
+	var inv Inventory
+	if err := yaml.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(inv.Hosts) == 0 {
+		return nil, fmt.Errorf("%s lists no hosts", path)
+	}
+
+	seen := make(map[string]bool)
+	for i, h := range inv.Hosts {
+		switch {
+		case h.Name == "":
+			return nil, fmt.Errorf("hosts[%d]: name is required", i)
+		case seen[h.Name]:
+			return nil, fmt.Errorf("hosts[%d]: duplicate host %q", i, h.Name)
+		case (h.SSH == "") == (h.Manifest == ""):
+			return nil, fmt.Errorf("hosts[%d] (%s): set exactly one of ssh and manifest", i, h.Name)
+		}
+		seen[h.Name] = true
+		if h.Manifest != "" && !filepath.IsAbs(h.Manifest) {
+			inv.Hosts[i].Manifest = filepath.Join(filepath.Dir(path), h.Manifest)
+		}
+	}
+	return &inv, nil
+}
+
+// Drift is one difference between a host and the Clewfile.
+type Drift struct {
+	Type   string      `json:"type" yaml:"type"` // "marketplace" or "plugin"
+	Name   string      `json:"name" yaml:"name"`
+	Action diff.Action `json:"action" yaml:"action"`
+}
+
+// Status is the sync status of one host.
+type Status struct {
+	Host   string  `json:"host" yaml:"host"`
+	Source string  `json:"source" yaml:"source"`
+	InSync bool    `json:"in_sync" yaml:"in_sync"`
+	Drift  []Drift `json:"drift,omitempty" yaml:"drift,omitempty"`
+	Error  string  `json:"error,omitempty" yaml:"error,omitempty"` // Set when the host could not be checked
+}
+
+// Runner runs a command and returns its standard output.
+type Runner func(name string, args ...string) ([]byte, error)
+
+// DefaultRunner runs commands with os/exec.
+func DefaultRunner(name string, args ...string) ([]byte, error) {
+	done := logging.StartCommand(name, args)
+	out, err := exec.Command(name, args...).Output()
+	done(out, err)
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return out, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return out, err
+}
+
+// Check compares every host with clewfile, querying hosts in parallel.
+// Results are in inventory order.
+func Check(clewfile *config.Clewfile, hosts []Host, run Runner) []Status {
+	statuses := make([]Status, len(hosts))
+	var wg sync.WaitGroup
+	for i, h := range hosts {
+		wg.Add(1)
+		go func(i int, h Host) {
+			defer wg.Done()
+			statuses[i] = check(clewfile, h, run)
+		}(i, h)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// check compares a single host with clewfile.
+func check(clewfile *config.Clewfile, h Host, run Runner) Status {
+	status := Status{Host: h.Name, Source: h.Source()}
+
+	var data []byte
+	var err error
+	if h.SSH != "" {
+		clew := h.Clew
+		if clew == "" {
+			clew = "clew"
+		}
+		// BatchMode fails instead of prompting for a password
+		data, err = run("ssh", "-o", "BatchMode=yes", h.SSH, clew, "export", "--output", "json")
+	} else {
+		data, err = os.ReadFile(h.Manifest)
+	}
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	hostState, err := parseManifest(data)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	result := diff.Compute(clewfile, hostState)
+	for _, m := range result.Marketplaces {
+		if m.Action != diff.ActionNone {
+			status.Drift = append(status.Drift, Drift{Type: "marketplace", Name: m.Alias, Action: m.Action})
+		}
+	}
+	for _, p := range result.Plugins {
+		if p.Action != diff.ActionNone {
+			status.Drift = append(status.Drift, Drift{Type: "plugin", Name: p.Name, Action: p.Action})
+		}
+	}
+	status.InSync = len(status.Drift) == 0
+	return status
+}
+
+// manifest is the output of `clew export`, in JSON or YAML.
+type manifest struct {
+	Marketplaces map[string]struct {
+		Repo string `yaml:"repo"`
+		Ref  string `yaml:"ref"`
+	} `yaml:"marketplaces"`
+	Plugins []struct {
+		Name    string `yaml:"name"`
+		Enabled *bool  `yaml:"enabled"`
+		Scope   string `yaml:"scope"`
+	} `yaml:"plugins"`
+}
+
+// parseManifest converts an exported Clewfile to the state it describes.
+func parseManifest(data []byte) (*state.State, error) {
+	var m manifest
+	// YAML is a superset of JSON, so this reads both
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	s := &state.State{
+		Marketplaces: make(map[string]state.MarketplaceState, len(m.Marketplaces)),
+		Plugins:      make(map[string]state.PluginState, len(m.Plugins)),
+	}
+	for alias, mp := range m.Marketplaces {
+		s.Marketplaces[alias] = state.MarketplaceState{Alias: alias, Repo: mp.Repo, Ref: mp.Ref}
+	}
+	for _, p := range m.Plugins {
+		name, marketplace, _ := strings.Cut(p.Name, "@")
+		s.Plugins[p.Name] = state.PluginState{
+			Name:        name,
+			Marketplace: marketplace,
+			Scope:       p.Scope,
+			Enabled:     p.Enabled == nil || *p.Enabled,
+		}
+	}
+	return s, nil
+}
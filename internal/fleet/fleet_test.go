@@ -0,0 +1,143 @@
+package fleet
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+)
+
+func TestLoadInventory(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hosts.yaml")
+	content := `hosts:
+  - name: laptop
+    ssh: me@laptop
+  - name: ci
+    manifest: manifests/ci.json
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	inv, err := LoadInventory(path)
+	if err != nil {
+		t.Fatalf("LoadInventory() error = %v", err)
+	}
+	if len(inv.Hosts) != 2 || inv.Hosts[0].Source() != "ssh me@laptop" {
+		t.Errorf("Hosts = %+v", inv.Hosts)
+	}
+	if want := filepath.Join(dir, "manifests", "ci.json"); inv.Hosts[1].Manifest != want {
+		t.Errorf("manifest = %s, want %s", inv.Hosts[1].Manifest, want)
+	}
+}
+
+func TestLoadInventoryInvalid(t *testing.T) {
+	tests := map[string]string{
+		"no hosts":   "hosts: []\n",
+		"no name":    "hosts:\n  - ssh: me@laptop\n",
+		"duplicate":  "hosts:\n  - {name: a, ssh: a}\n  - {name: a, ssh: b}\n",
+		"no source":  "hosts:\n  - name: a\n",
+		"both":       "hosts:\n  - {name: a, ssh: a, manifest: a.json}\n",
+		"not a list": "hosts: laptop\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "hosts.yaml")
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadInventory(path); err == nil {
+				t.Error("LoadInventory() should fail")
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	disabled := false
+	clewfile := &config.Clewfile{
+		Marketplaces: map[string]config.Marketplace{
+			"official": {Repo: "anthropics/claude-plugins"},
+		},
+		Plugins: []config.Plugin{
+			{Name: "context7@official"},
+			{Name: "linter@official", Enabled: &disabled},
+		},
+	}
+
+	manifest := filepath.Join(t.TempDir(), "desk.yaml")
+	content := `version: 1
+marketplaces:
+  official:
+    repo: anthropics/claude-plugins
+plugins:
+  - name: linter@official
+  - name: extra@official
+`
+	if err := os.WriteFile(manifest, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hosts are checked in parallel
+	var mu sync.Mutex
+	var commands []string
+	run := func(name string, args ...string) ([]byte, error) {
+		mu.Lock()
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		mu.Unlock()
+		if args[2] == "me@down" {
+			return nil, errors.New("ssh: connect to host down: Connection refused")
+		}
+		return []byte(`{"version": 1, "marketplaces": {"official": {"repo": "anthropics/claude-plugins"}},
+			"plugins": [{"name": "context7@official"}, {"name": "linter@official", "enabled": false}]}`), nil
+	}
+
+	hosts := []Host{
+		{Name: "laptop", SSH: "me@laptop", Clew: "/opt/bin/clew"},
+		{Name: "desk", Manifest: manifest},
+		{Name: "down", SSH: "me@down"},
+	}
+	statuses := Check(clewfile, hosts, run)
+
+	if len(statuses) != 3 {
+		t.Fatalf("statuses = %+v", statuses)
+	}
+	if s := statuses[0]; !s.InSync || s.Error != "" {
+		t.Errorf("laptop = %+v, want in sync", s)
+	}
+
+	desk := statuses[1]
+	want := map[string]diff.Action{
+		"context7@official": diff.ActionAdd,
+		"linter@official":   diff.ActionDisable,
+		"extra@official":    diff.ActionRemove,
+	}
+	if desk.InSync || len(desk.Drift) != len(want) {
+		t.Fatalf("desk = %+v", desk)
+	}
+	for _, d := range desk.Drift {
+		if d.Action != want[d.Name] {
+			t.Errorf("desk %s action = %s, want %s", d.Name, d.Action, want[d.Name])
+		}
+	}
+
+	if s := statuses[2]; s.InSync || !strings.Contains(s.Error, "Connection refused") {
+		t.Errorf("down = %+v, want the ssh error", s)
+	}
+
+	found := false
+	for _, c := range commands {
+		if c == "ssh -o BatchMode=yes me@laptop /opt/bin/clew export --output json" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("commands = %v, want the configured clew binary run over ssh", commands)
+	}
+}
@@ -44,9 +44,11 @@ func (r *CheckResult) HasInfo() bool {
 	return len(r.Info) > 0
 }
 
-// CheckClewfile checks git status for all local marketplaces and plugins in the Clewfile.
+// CheckClewfile checks git status for all local marketplaces and plugins in
+// the Clewfile, applying its git signature policy.
 func (c *Checker) CheckClewfile(clewfile *config.Clewfile) *CheckResult {
 	result := NewCheckResult()
+	c.SetSignaturePolicy(clewfile.Git)
 
 	// Check if git is available
 	if !c.GitAvailable() {
@@ -0,0 +1,128 @@
+package git
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fetcher runs the checker's git fetches. A repository is fetched once per
+// remote however many of its paths are checked (plugins installed from one
+// marketplace checkout, or worktrees of one clone), and fetches from the
+// same host are spaced at least interval apart.
+type fetcher struct {
+	disabled bool          // Never fetch; use the local tracking refs
+	interval time.Duration // Minimum time between fetches from one host (0 = no limit)
+
+	mu      sync.Mutex
+	fetched map[string]error     // Fetch result by repository and remote
+	last    map[string]time.Time // Last fetch start by host
+	now     func() time.Time
+	sleep   func(time.Duration)
+}
+
+// SetNoFetch makes ahead/behind checks use the local remote-tracking refs
+// without fetching, for fast offline status.
+func (c *Checker) SetNoFetch(noFetch bool) {
+	c.fetches.disabled = noFetch
+}
+
+// SetFetchInterval spaces fetches from the same host at least d apart, so
+// checking many repositories on one server does not trip its rate limits.
+func (c *Checker) SetFetchInterval(d time.Duration) {
+	c.fetches.interval = d
+}
+
+// fetch fetches the remote of the tracking branch (e.g. "origin/main") in
+// the repository at path, unless that repository and remote were fetched
+// already. Failures are returned but checks treat them as best effort.
+func (c *Checker) fetch(path, tracking string) error {
+	f := &c.fetches
+	if f.disabled {
+		return nil
+	}
+	remote, _, _ := strings.Cut(tracking, "/")
+	key := c.commonDir(path) + "\x00" + remote
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err, ok := f.fetched[key]; ok {
+		return err
+	}
+	f.wait(c.remoteHost(path, remote))
+	_, err := c.runner.RunInDir(path, "git", "fetch", "--quiet", remote)
+	if f.fetched == nil {
+		f.fetched = make(map[string]error)
+	}
+	f.fetched[key] = err
+	return err
+}
+
+// wait blocks until host may be fetched from again and records the fetch.
+func (f *fetcher) wait(host string) {
+	if f.interval <= 0 || host == "" {
+		return
+	}
+	now, sleep := f.now, f.sleep
+	if now == nil {
+		now = time.Now
+	}
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	if last, ok := f.last[host]; ok {
+		if d := f.interval - now().Sub(last); d > 0 {
+			sleep(d)
+		}
+	}
+	if f.last == nil {
+		f.last = make(map[string]time.Time)
+	}
+	f.last[host] = now()
+}
+
+// commonDir returns the repository's git directory shared by all its
+// worktrees, or path itself if git cannot tell.
+func (c *Checker) commonDir(path string) string {
+	output, err := c.runner.RunInDir(path, "git", "rev-parse", "--git-common-dir")
+	dir := strings.TrimSpace(string(output))
+	if err != nil || dir == "" {
+		return path
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// remoteHost returns the host a remote is fetched from, or "" for local
+// and unknown remotes.
+func (c *Checker) remoteHost(path, remote string) string {
+	output, err := c.runner.RunInDir(path, "git", "remote", "get-url", remote)
+	if err != nil {
+		return ""
+	}
+	return urlHost(strings.TrimSpace(string(output)))
+}
+
+// urlHost returns the host of a git remote URL: scheme://[user@]host/...
+// or scp-like [user@]host:path.
+func urlHost(remote string) string {
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil || u.Scheme == "file" {
+			return ""
+		}
+		return u.Hostname()
+	}
+	host, _, ok := strings.Cut(remote, ":")
+	if !ok || len(host) == 1 || strings.ContainsAny(host, "/\\") {
+		return "" // A local path (or Windows drive)
+	}
+	if _, h, ok := strings.Cut(host, "@"); ok {
+		host = h
+	}
+	return host
+}
@@ -0,0 +1,88 @@
+package git
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingRunner counts the commands run through a MockCommandRunner.
+type countingRunner struct {
+	*MockCommandRunner
+	counts map[string]int
+}
+
+func (r *countingRunner) RunInDir(dir, name string, args ...string) ([]byte, error) {
+	r.counts[dir+":"+name+" "+strings.Join(args, " ")]++
+	return r.MockCommandRunner.RunInDir(dir, name, args...)
+}
+
+func TestFetchCoalescesAndRateLimits(t *testing.T) {
+	mock := NewMockCommandRunner()
+	// Two paths in one clone, and a second clone on the same host
+	for path, common := range map[string]string{
+		"/src/tools":         "/src/tools/.git",
+		"/src/tools/plugins": "/src/tools/.git",
+		"/src/other":         ".git",
+	} {
+		mock.AddCommand(path, "git rev-parse --git-common-dir", []byte(common+"\n"), nil)
+		mock.AddCommand(path, "git remote get-url origin", []byte("git@github.com:acme/tools.git\n"), nil)
+		mock.AddCommand(path, "git fetch --quiet origin", nil, nil)
+	}
+	runner := &countingRunner{MockCommandRunner: mock, counts: map[string]int{}}
+	checker := NewCheckerWithRunner(runner)
+	checker.SetFetchInterval(time.Second)
+
+	clock := time.Unix(0, 0)
+	var slept time.Duration
+	checker.fetches.now = func() time.Time { return clock }
+	checker.fetches.sleep = func(d time.Duration) { slept += d; clock = clock.Add(d) }
+
+	for _, path := range []string{"/src/tools", "/src/tools/plugins", "/src/other"} {
+		if err := checker.fetch(path, "origin/main"); err != nil {
+			t.Fatalf("fetch(%s) error = %v", path, err)
+		}
+	}
+
+	if n := runner.counts["/src/tools:git fetch --quiet origin"]; n != 1 {
+		t.Errorf("/src/tools fetched %d times, want 1", n)
+	}
+	if n := runner.counts["/src/tools/plugins:git fetch --quiet origin"]; n != 0 {
+		t.Errorf("/src/tools/plugins fetched %d times, want 0 (same clone)", n)
+	}
+	if n := runner.counts["/src/other:git fetch --quiet origin"]; n != 1 {
+		t.Errorf("/src/other fetched %d times, want 1", n)
+	}
+	if slept != time.Second {
+		t.Errorf("slept %v between fetches from github.com, want 1s", slept)
+	}
+}
+
+func TestNoFetch(t *testing.T) {
+	runner := &countingRunner{MockCommandRunner: NewMockCommandRunner(), counts: map[string]int{}}
+	checker := NewCheckerWithRunner(runner)
+	checker.SetNoFetch(true)
+
+	if err := checker.fetch("/src/tools", "origin/main"); err != nil {
+		t.Errorf("fetch() error = %v", err)
+	}
+	if len(runner.counts) != 0 {
+		t.Errorf("ran %v, want no commands", runner.counts)
+	}
+}
+
+func TestURLHost(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/acme/tools.git":   "github.com",
+		"ssh://git@gitlab.example.com:2222/x": "gitlab.example.com",
+		"git@github.com:acme/tools.git":       "github.com",
+		"/srv/git/tools.git":                  "",
+		"file:///srv/git/tools.git":           "",
+		"C:\\repos\\tools":                    "",
+	}
+	for remote, want := range tests {
+		if got := urlHost(remote); got != want {
+			t.Errorf("urlHost(%q) = %q, want %q", remote, got, want)
+		}
+	}
+}
@@ -2,12 +2,16 @@
 package git
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/proc"
 )
 
 // Level represents the severity of a git status.
@@ -16,6 +20,7 @@ type Level string
 const (
 	LevelOK      Level = "ok"      // Clean and in sync
 	LevelInfo    Level = "info"    // Ahead or behind remote
+	LevelBranch  Level = "branch"  // Detached HEAD or not on the expected branch
 	LevelWarning Level = "warning" // Uncommitted changes
 	LevelError   Level = "error"   // Git operation failed
 )
@@ -28,7 +33,9 @@ type Status struct {
 	HasUncommitted bool   // Has uncommitted changes (staged or unstaged)
 	Ahead          int    // Number of commits ahead of remote
 	Behind         int    // Number of commits behind remote
-	CurrentBranch  string // Current branch name
+	CurrentBranch  string // Current branch name ("HEAD" when detached)
+	Detached       bool   // HEAD is detached
+	ExpectedBranch string // Declared ref, or the remote's default branch
 	Remote         string // Remote tracking branch (e.g., "origin/main")
 	Level          Level  // Overall severity level
 	Message        string // Human-readable status message
@@ -42,26 +49,37 @@ type CommandRunner interface {
 	RunInDir(dir, name string, args ...string) ([]byte, error)
 }
 
-// DefaultCommandRunner uses os/exec to run commands.
-type DefaultCommandRunner struct{}
+// DefaultCommandRunner uses os/exec to run commands. Cancelling Ctx (nil
+// means never) stops a running command and the processes it started.
+type DefaultCommandRunner struct {
+	Ctx context.Context
+}
 
 // Run executes a command in the current directory.
 func (r *DefaultCommandRunner) Run(name string, args ...string) ([]byte, error) {
-	cmd := exec.Command(name, args...)
-	return cmd.CombinedOutput()
+	cmd := proc.Command(r.Ctx, name, args...)
+	done := logging.StartCommand(name, args)
+	output, err := cmd.CombinedOutput()
+	done(output, err)
+	return output, err
 }
 
 // RunInDir executes a command in the specified directory.
 func (r *DefaultCommandRunner) RunInDir(dir, name string, args ...string) ([]byte, error) {
-	cmd := exec.Command(name, args...)
+	cmd := proc.Command(r.Ctx, name, args...)
 	cmd.Dir = dir
-	return cmd.CombinedOutput()
+	done := logging.StartCommand(name, args)
+	output, err := cmd.CombinedOutput()
+	done(output, err)
+	return output, err
 }
 
 // Checker checks git status for repositories.
 type Checker struct {
 	runner          CommandRunner
 	skipPathCheck   bool // For testing: skip filesystem path existence check
+	signatures      config.GitConfig // Signed-HEAD policy (see SetSignaturePolicy)
+	fetches         fetcher          // Coalesced, rate-limited fetches (see SetFetchInterval)
 }
 
 // NewChecker creates a new Checker with the default command runner.
@@ -74,23 +92,39 @@ func NewCheckerWithRunner(runner CommandRunner) *Checker {
 	return &Checker{runner: runner}
 }
 
+// SetContext makes the checker's git commands stop when ctx is cancelled.
+// It has no effect with a custom command runner.
+func (c *Checker) SetContext(ctx context.Context) {
+	if r, ok := c.runner.(*DefaultCommandRunner); ok {
+		r.Ctx = ctx
+	}
+}
+
 // SetSkipPathCheck sets whether to skip filesystem path existence checks (for testing).
 func (c *Checker) SetSkipPathCheck(skip bool) {
 	c.skipPathCheck = skip
 }
 
 // CheckRepository checks the git status of a repository at the given path.
+// The checkout is expected to be on the remote's default branch.
 func (c *Checker) CheckRepository(path string) Status {
-	return c.checkRepository(path, !c.skipPathCheck)
+	return c.checkRepository(path, "", !c.skipPathCheck)
+}
+
+// CheckRepositoryAtRef checks the git status of a repository that is
+// declared at ref (a branch, tag or commit). An empty ref means the
+// remote's default branch.
+func (c *Checker) CheckRepositoryAtRef(path, ref string) Status {
+	return c.checkRepository(path, ref, !c.skipPathCheck)
 }
 
 // checkRepositorySkipPathCheck checks the git status without verifying path exists (for testing).
 func (c *Checker) checkRepositorySkipPathCheck(path string) Status {
-	return c.checkRepository(path, false)
+	return c.checkRepository(path, "", false)
 }
 
 // checkRepository is the internal implementation.
-func (c *Checker) checkRepository(path string, checkPathExists bool) Status {
+func (c *Checker) checkRepository(path, ref string, checkPathExists bool) Status {
 	status := Status{Path: path}
 
 	// Expand ~ to home directory
@@ -125,6 +159,7 @@ func (c *Checker) checkRepository(path string, checkPathExists bool) Status {
 		return status
 	}
 	status.CurrentBranch = branch
+	status.Detached = branch == "HEAD"
 
 	// Check for uncommitted changes using porcelain format
 	hasChanges, err := c.hasUncommittedChanges(expandedPath)
@@ -144,6 +179,21 @@ func (c *Checker) checkRepository(path string, checkPathExists bool) Status {
 		return status
 	}
 
+	// A checkout on the wrong branch would sync content nobody declared
+	if message, ok := c.checkBranch(expandedPath, ref, &status); !ok {
+		status.Level = LevelBranch
+		status.Message = message
+		return status
+	}
+
+	// Only commits signed by an allowed key may be synced
+	if err := c.VerifySignature(expandedPath); err != nil {
+		status.Level = LevelSignature
+		status.Error = err
+		status.Message = err.Error()
+		return status
+	}
+
 	// Get remote tracking branch
 	remote, err := c.getRemoteTrackingBranch(expandedPath)
 	if err != nil {
@@ -155,7 +205,7 @@ func (c *Checker) checkRepository(path string, checkPathExists bool) Status {
 	status.Remote = remote
 
 	// Fetch from remote (best effort, continue if fails)
-	_ = c.fetch(expandedPath)
+	_ = c.fetch(expandedPath, remote)
 
 	// Check ahead/behind
 	ahead, behind, err := c.getAheadBehind(expandedPath, remote)
@@ -204,6 +254,56 @@ func (c *Checker) getCurrentBranch(path string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// checkBranch compares the checkout with ref, or with the remote's default
+// branch if ref is empty, and records the expected branch in status. It
+// returns false with a message suggesting a checkout command on a mismatch.
+// If the expected branch cannot be determined, the checkout is accepted.
+func (c *Checker) checkBranch(path, ref string, status *Status) (string, bool) {
+	expected := ref
+	if expected == "" {
+		var err error
+		expected, err = c.getDefaultBranch(path)
+		if err != nil {
+			if status.Detached {
+				return "detached HEAD (consider: git checkout <branch>)", false
+			}
+			return "", true
+		}
+	}
+	status.ExpectedBranch = expected
+
+	if status.CurrentBranch == expected {
+		return "", true
+	}
+	if status.Detached {
+		// A tag or commit ref is checked out detached; accept it if HEAD is there
+		if ref != "" && c.sameCommit(path, "HEAD", ref) {
+			return "", true
+		}
+		return fmt.Sprintf("detached HEAD, expected %s (consider: git checkout %s)", expected, expected), false
+	}
+	return fmt.Sprintf("on branch %s, expected %s (consider: git checkout %s)", status.CurrentBranch, expected, expected), false
+}
+
+// getDefaultBranch returns the remote's default branch (e.g., "main").
+func (c *Checker) getDefaultBranch(path string) (string, error) {
+	output, err := c.runner.RunInDir(path, "git", "symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if err != nil {
+		return "", fmt.Errorf("no default branch for origin")
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), "origin/"), nil
+}
+
+// sameCommit reports whether two revisions resolve to the same commit.
+func (c *Checker) sameCommit(path, a, b string) bool {
+	output, err := c.runner.RunInDir(path, "git", "rev-parse", a+"^{commit}", b+"^{commit}")
+	if err != nil {
+		return false
+	}
+	commits := strings.Fields(string(output))
+	return len(commits) == 2 && commits[0] == commits[1]
+}
+
 // hasUncommittedChanges checks for uncommitted or unstaged changes.
 func (c *Checker) hasUncommittedChanges(path string) (bool, error) {
 	output, err := c.runner.RunInDir(path, "git", "status", "--porcelain")
@@ -223,12 +323,6 @@ func (c *Checker) getRemoteTrackingBranch(path string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-// fetch fetches from the remote (best effort).
-func (c *Checker) fetch(path string) error {
-	_, err := c.runner.RunInDir(path, "git", "fetch", "--quiet")
-	return err
-}
-
 // getAheadBehind returns the number of commits ahead and behind the remote.
 func (c *Checker) getAheadBehind(path, remote string) (ahead, behind int, err error) {
 	output, err := c.runner.RunInDir(path, "git", "rev-list", "--left-right", "--count", "HEAD..."+remote)
@@ -106,7 +106,7 @@ func TestCheckRepositoryClean(t *testing.T) {
 	// Remote tracking
 	mock.AddCommand(path, "git rev-parse --abbrev-ref --symbolic-full-name @{u}", []byte("origin/main\n"), nil)
 	// Fetch
-	mock.AddCommand(path, "git fetch --quiet", []byte(""), nil)
+	mock.AddCommand(path, "git fetch --quiet origin", []byte(""), nil)
 	// Ahead/behind (in sync)
 	mock.AddCommand(path, "git rev-list --left-right --count HEAD...origin/main", []byte("0\t0\n"), nil)
 
@@ -168,7 +168,7 @@ func TestCheckRepositoryBehindRemote(t *testing.T) {
 	// Remote tracking
 	mock.AddCommand(path, "git rev-parse --abbrev-ref --symbolic-full-name @{u}", []byte("origin/main\n"), nil)
 	// Fetch
-	mock.AddCommand(path, "git fetch --quiet", []byte(""), nil)
+	mock.AddCommand(path, "git fetch --quiet origin", []byte(""), nil)
 	// Ahead/behind (3 behind)
 	mock.AddCommand(path, "git rev-list --left-right --count HEAD...origin/main", []byte("0\t3\n"), nil)
 
@@ -199,7 +199,7 @@ func TestCheckRepositoryAheadOfRemote(t *testing.T) {
 	// Remote tracking
 	mock.AddCommand(path, "git rev-parse --abbrev-ref --symbolic-full-name @{u}", []byte("origin/feature\n"), nil)
 	// Fetch
-	mock.AddCommand(path, "git fetch --quiet", []byte(""), nil)
+	mock.AddCommand(path, "git fetch --quiet origin", []byte(""), nil)
 	// Ahead/behind (2 ahead)
 	mock.AddCommand(path, "git rev-list --left-right --count HEAD...origin/feature", []byte("2\t0\n"), nil)
 
@@ -262,6 +262,101 @@ func TestCheckRepositoryNoRemoteTracking(t *testing.T) {
 	}
 }
 
+func TestCheckRepositoryBranch(t *testing.T) {
+	const sha = "3f2a9c1d"
+	tests := []struct {
+		name          string
+		ref           string
+		branch        string
+		defaultBranch string // Empty when origin/HEAD is not set
+		headCommits   string // Output of rev-parse HEAD and ref when detached
+		wantLevel     Level
+		wantMessage   string
+	}{
+		{
+			name:          "on default branch",
+			branch:        "main",
+			defaultBranch: "origin/main",
+			wantLevel:     LevelOK,
+			wantMessage:   "clean (no remote tracking branch)",
+		},
+		{
+			name:          "on another branch",
+			branch:        "experiment",
+			defaultBranch: "origin/main",
+			wantLevel:     LevelBranch,
+			wantMessage:   "on branch experiment, expected main (consider: git checkout main)",
+		},
+		{
+			name:          "detached from default branch",
+			branch:        "HEAD",
+			defaultBranch: "origin/main",
+			wantLevel:     LevelBranch,
+			wantMessage:   "detached HEAD, expected main (consider: git checkout main)",
+		},
+		{
+			name:        "detached with unknown default branch",
+			branch:      "HEAD",
+			wantLevel:   LevelBranch,
+			wantMessage: "detached HEAD (consider: git checkout <branch>)",
+		},
+		{
+			name:        "declared branch",
+			ref:         "release",
+			branch:      "release",
+			wantLevel:   LevelOK,
+			wantMessage: "clean (no remote tracking branch)",
+		},
+		{
+			name:        "declared tag checked out",
+			ref:         "v1.2.0",
+			branch:      "HEAD",
+			headCommits: sha + "\n" + sha + "\n",
+			wantLevel:   LevelOK,
+			wantMessage: "clean (no remote tracking branch)",
+		},
+		{
+			name:        "declared tag, other commit checked out",
+			ref:         "v1.2.0",
+			branch:      "HEAD",
+			headCommits: sha + "\n9b8c7d6e\n",
+			wantLevel:   LevelBranch,
+			wantMessage: "detached HEAD, expected v1.2.0 (consider: git checkout v1.2.0)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := NewMockCommandRunner()
+			path := "/tmp/testrepo"
+			mock.AddCommand(path, "git rev-parse --git-dir", []byte(".git\n"), nil)
+			mock.AddCommand(path, "git rev-parse --abbrev-ref HEAD", []byte(tt.branch+"\n"), nil)
+			mock.AddCommand(path, "git status --porcelain", []byte(""), nil)
+			if tt.defaultBranch != "" {
+				mock.AddCommand(path, "git symbolic-ref --short refs/remotes/origin/HEAD", []byte(tt.defaultBranch+"\n"), nil)
+			}
+			if tt.headCommits != "" {
+				mock.AddCommand(path, "git rev-parse HEAD^{commit} "+tt.ref+"^{commit}", []byte(tt.headCommits), nil)
+			}
+			mock.AddCommand(path, "git rev-parse --abbrev-ref --symbolic-full-name @{u}", nil, errors.New("no upstream"))
+
+			checker := NewCheckerWithRunner(mock)
+			checker.SetSkipPathCheck(true)
+			status := checker.CheckRepositoryAtRef(path, tt.ref)
+
+			if status.Level != tt.wantLevel {
+				t.Errorf("Level = %v, want %v", status.Level, tt.wantLevel)
+			}
+			if status.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", status.Message, tt.wantMessage)
+			}
+			if status.Detached != (tt.branch == "HEAD") {
+				t.Errorf("Detached = %v for branch %s", status.Detached, tt.branch)
+			}
+		})
+	}
+}
+
 func TestCheckClewfile(t *testing.T) {
 	// NOTE: As of v0.8.0, CheckClewfile no longer checks git status since
 	// marketplaces are remote repositories. This test verifies it returns empty results.
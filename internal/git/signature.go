@@ -0,0 +1,72 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adamancini/clew/internal/config"
+)
+
+// LevelSignature marks a repository whose HEAD commit is not signed by an
+// allowed key. Like uncommitted changes, it makes sync skip the item.
+const LevelSignature Level = "signature"
+
+// SetSignaturePolicy makes the checker require a signed HEAD commit when the
+// policy lists allowed signers. An empty policy turns the check off.
+func (c *Checker) SetSignaturePolicy(policy config.GitConfig) {
+	c.signatures = policy
+}
+
+// VerifySignature checks that the HEAD commit of the repository at path is
+// signed by a key the signature policy allows. SSH signatures are checked
+// by git against the allowed signers file; GPG signatures must be good and
+// made by a key in the fingerprint list. It returns nil when no policy is set.
+func (c *Checker) VerifySignature(path string) error {
+	if !c.signatures.RequireSignatures() {
+		return nil
+	}
+	path = expandPath(path)
+
+	args := []string{"log", "-1", "--format=%G?%n%GF%n%GP", "HEAD"}
+	if signers := c.signatures.AllowedSigners; signers != "" {
+		args = append([]string{"-c", "gpg.ssh.allowedSignersFile=" + expandPath(signers)}, args...)
+	}
+	output, err := c.runner.RunInDir(path, "git", args...)
+	if err != nil {
+		return fmt.Errorf("failed to read HEAD signature: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	for len(lines) < 3 {
+		lines = append(lines, "")
+	}
+	code, fingerprint, primary := lines[0], lines[1], lines[2]
+
+	switch code {
+	case "N":
+		return fmt.Errorf("HEAD commit is not signed")
+	case "B":
+		return fmt.Errorf("HEAD commit has a bad signature")
+	case "E":
+		return fmt.Errorf("HEAD signature cannot be checked (missing key or signing program)")
+	case "X", "Y":
+		return fmt.Errorf("HEAD commit is signed with an expired signature or key %s", fingerprint)
+	case "R":
+		return fmt.Errorf("HEAD commit is signed by revoked key %s", fingerprint)
+	}
+
+	// git reports SSH key fingerprints as "SHA256:..."
+	if strings.HasPrefix(fingerprint, "SHA256:") {
+		if code != "G" {
+			return fmt.Errorf("HEAD commit is signed by SSH key %s, which is not in %s", fingerprint, c.signatures.AllowedSigners)
+		}
+		return nil
+	}
+
+	for _, allowed := range c.signatures.AllowedGPGKeys {
+		if strings.EqualFold(allowed, fingerprint) || strings.EqualFold(allowed, primary) {
+			return nil
+		}
+	}
+	return fmt.Errorf("HEAD commit is signed by GPG key %s, which is not in git.allowed_gpg_keys", fingerprint)
+}
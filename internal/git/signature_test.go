@@ -0,0 +1,75 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+)
+
+func TestVerifySignature(t *testing.T) {
+	const (
+		path    = "/repos/plugin"
+		sshCmd  = "git -c gpg.ssh.allowedSignersFile=/keys/allowed_signers log -1 --format=%G?%n%GF%n%GP HEAD"
+		gpgCmd  = "git log -1 --format=%G?%n%GF%n%GP HEAD"
+		gpgKey  = "4AEE18F83AFDEB23B21E1F4C7C5B0E3F9A1D2C3B"
+		subKey  = "1111111111111111111111111111111111111111"
+		sshKey  = "SHA256:Zm9vYmFyYmF6"
+		signers = "/keys/allowed_signers"
+	)
+
+	tests := []struct {
+		name    string
+		policy  config.GitConfig
+		cmd     string
+		output  string
+		wantErr string
+	}{
+		{"no policy", config.GitConfig{}, "", "", ""},
+		{"ssh key in allowed signers", config.GitConfig{AllowedSigners: signers}, sshCmd, "G\n" + sshKey + "\n\n", ""},
+		{"ssh key not in allowed signers", config.GitConfig{AllowedSigners: signers}, sshCmd, "U\n" + sshKey + "\n\n", "not in /keys/allowed_signers"},
+		{"unsigned", config.GitConfig{AllowedSigners: signers}, sshCmd, "N\n\n\n", "not signed"},
+		{"bad signature", config.GitConfig{AllowedSigners: signers}, sshCmd, "B\n" + sshKey + "\n\n", "bad signature"},
+		{"gpg primary key allowed", config.GitConfig{AllowedGPGKeys: []string{strings.ToLower(gpgKey)}}, gpgCmd, "U\n" + subKey + "\n" + gpgKey + "\n", ""},
+		{"gpg key not allowed", config.GitConfig{AllowedGPGKeys: []string{gpgKey}}, gpgCmd, "G\n" + subKey + "\n" + subKey + "\n", "not in git.allowed_gpg_keys"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := NewMockCommandRunner()
+			if tt.cmd != "" {
+				mock.AddCommand(path, tt.cmd, []byte(tt.output), nil)
+			}
+			checker := NewCheckerWithRunner(mock)
+			checker.SetSignaturePolicy(tt.policy)
+
+			err := checker.VerifySignature(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("VerifySignature() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("VerifySignature() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckRepositoryUnsigned(t *testing.T) {
+	mock := NewMockCommandRunner()
+	path := "/tmp/testrepo"
+	mock.AddCommand(path, "git rev-parse --git-dir", []byte(".git\n"), nil)
+	mock.AddCommand(path, "git rev-parse --abbrev-ref HEAD", []byte("main\n"), nil)
+	mock.AddCommand(path, "git status --porcelain", []byte(""), nil)
+	mock.AddCommand(path, "git log -1 --format=%G?%n%GF%n%GP HEAD", []byte("N\n\n\n"), nil)
+
+	checker := NewCheckerWithRunner(mock)
+	checker.SetSignaturePolicy(config.GitConfig{AllowedGPGKeys: []string{"4AEE18F83AFDEB23B21E1F4C7C5B0E3F9A1D2C3B"}})
+	status := checker.checkRepositorySkipPathCheck(path)
+
+	if status.Level != LevelSignature {
+		t.Errorf("Level = %v, want %v (message %q)", status.Level, LevelSignature, status.Message)
+	}
+}
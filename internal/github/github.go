@@ -0,0 +1,305 @@
+// Package github is a minimal GitHub REST API client shared by the commands
+// that query GitHub. It authenticates with a token when one is configured,
+// tracks rate limits, backs off briefly when throttled, and caches
+// responses (revalidated with ETags) so repeated runs rarely spend quota.
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/xdg"
+)
+
+// DefaultBaseURL is the public GitHub API endpoint.
+const DefaultBaseURL = "https://api.github.com"
+
+// Defaults for caching and backoff.
+const (
+	DefaultCacheTTL = 15 * time.Minute // Serve cached responses without a request
+	DefaultMaxWait  = 10 * time.Second // Longest backoff before giving up
+)
+
+// TokenFromEnv returns the configured GitHub token, preferring GITHUB_TOKEN
+// over GH_TOKEN (the GitHub CLI's variable).
+func TokenFromEnv() string {
+	for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// DefaultCacheDir returns the response cache location in clew's cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := xdg.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "github"), nil
+}
+
+// RateLimit is the quota reported by the most recent response.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// RateLimitError is returned when the API refuses a request because the
+// quota is exhausted and no cached response is available.
+type RateLimitError struct {
+	Reset         time.Time
+	Authenticated bool
+}
+
+func (e *RateLimitError) Error() string {
+	msg := "GitHub API rate limit exceeded"
+	if !e.Reset.IsZero() {
+		msg += fmt.Sprintf("; resets at %s", e.Reset.Local().Format("15:04"))
+	}
+	if !e.Authenticated {
+		msg += " (set GITHUB_TOKEN to raise the limit)"
+	}
+	return msg
+}
+
+// Client performs GET requests against the GitHub API.
+type Client struct {
+	baseURL  string
+	token    string
+	http     *http.Client
+	cacheDir string        // Empty disables caching
+	cacheTTL time.Duration // How long a cached response is served without revalidation
+	maxWait  time.Duration
+	sleep    func(time.Duration)
+
+	rateLimit RateLimit
+	limited   *RateLimitError // Set once the rate limit stops a request; no further requests are sent
+}
+
+// NewClient creates a client for baseURL using httpClient.
+func NewClient(httpClient *http.Client, baseURL string) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{
+		baseURL:  baseURL,
+		http:     httpClient,
+		cacheTTL: DefaultCacheTTL,
+		maxWait:  DefaultMaxWait,
+		sleep:    time.Sleep,
+	}
+}
+
+// WithToken authenticates requests with token. Empty means anonymous.
+func (c *Client) WithToken(token string) *Client {
+	c.token = token
+	return c
+}
+
+// WithCache enables the response cache in dir.
+func (c *Client) WithCache(dir string) *Client {
+	c.cacheDir = dir
+	return c
+}
+
+// RateLimit returns the quota reported by the most recent response.
+func (c *Client) RateLimit() RateLimit {
+	return c.rateLimit
+}
+
+// cacheEntry is a cached response body with its validator.
+type cacheEntry struct {
+	ETag      string          `json:"etag,omitempty"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Body      json.RawMessage `json:"body"`
+}
+
+// Get fetches path (e.g. "/repos/owner/repo") and decodes the JSON response
+// into v. Fresh cached responses are used without a request; stale ones are
+// revalidated, and served as a fallback when the rate limit is exhausted.
+// Once the rate limit has stopped a request, the client sends no more: later
+// calls are served from the cache or fail with the same RateLimitError.
+func (c *Client) Get(path string, v any) error {
+	url := c.baseURL + path
+	cached := c.readCache(url)
+	if cached != nil && time.Since(cached.FetchedAt) < c.cacheTTL {
+		logging.Tracef("github: GET %s served from cache", path)
+		return json.Unmarshal(cached.Body, v)
+	}
+	if c.limited != nil {
+		if cached != nil {
+			logging.Tracef("github: GET %s served from stale cache while rate limited", path)
+			return json.Unmarshal(cached.Body, v)
+		}
+		return c.limited
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(url, cached)
+		if err != nil {
+			return err
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			c.writeCache(url, &cacheEntry{ETag: resp.Header.Get("ETag"), FetchedAt: time.Now(), Body: body})
+			return json.Unmarshal(body, v)
+
+		case resp.StatusCode == http.StatusNotModified && cached != nil:
+			logging.Tracef("github: GET %s not modified", path)
+			cached.FetchedAt = time.Now()
+			c.writeCache(url, cached)
+			return json.Unmarshal(cached.Body, v)
+
+		case isRateLimited(resp):
+			wait := c.retryAfter(resp)
+			if attempt == 0 && wait <= c.maxWait {
+				logging.Decisionf("github: rate limited, retrying in %s", wait)
+				c.sleep(wait)
+				continue
+			}
+			c.limited = &RateLimitError{Reset: c.rateLimit.Reset, Authenticated: c.token != ""}
+			logging.Decisionf("github: rate limited, sending no more requests this run")
+			if cached != nil {
+				logging.Decisionf("github: rate limited, using cached response from %s", cached.FetchedAt.Format(time.RFC3339))
+				return json.Unmarshal(cached.Body, v)
+			}
+			return c.limited
+
+		default:
+			return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+		}
+	}
+}
+
+// do sends a single GET request and records the rate limit headers.
+func (c *Client) do(url string, cached *cacheEntry) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if cached != nil && cached.ETag != "" {
+		// 304 responses do not count against the rate limit
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit")); err == nil {
+		c.rateLimit.Limit = limit
+		c.rateLimit.Remaining, _ = strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			c.rateLimit.Reset = time.Unix(reset, 0)
+		}
+		logging.Tracef("github: %d/%d requests remaining", c.rateLimit.Remaining, c.rateLimit.Limit)
+	}
+	return resp, nil
+}
+
+// isRateLimited reports whether a response is a primary or secondary rate
+// limit rejection rather than a permissions error.
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	return resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// retryAfter returns how long to wait before the next request may succeed.
+func (c *Client) retryAfter(resp *http.Response) time.Duration {
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if !c.rateLimit.Reset.IsZero() {
+		if wait := time.Until(c.rateLimit.Reset); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+	return time.Minute
+}
+
+func (c *Client) cachePath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.cacheDir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// readCache returns the cached entry for url, or nil.
+func (c *Client) readCache(url string) *cacheEntry {
+	if c.cacheDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.cachePath(url))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// writeCache stores entry for url. Failures are ignored; the cache is an
+// optimization.
+func (c *Client) writeCache(url string, entry *cacheEntry) {
+	if c.cacheDir == "" {
+		return
+	}
+	if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	_ = atomicfile.WriteFile(c.cachePath(url), data, 0644)
+}
+
+// Repository is the repository metadata shown to help judge whether a
+// plugin or marketplace is maintained.
+type Repository struct {
+	FullName    string    `json:"full_name" yaml:"full_name"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Stars       int       `json:"stargazers_count" yaml:"stars"`
+	PushedAt    time.Time `json:"pushed_at" yaml:"pushed_at"` // Last push to any branch
+	Archived    bool      `json:"archived" yaml:"archived"`
+	HTMLURL     string    `json:"html_url" yaml:"html_url"`
+}
+
+// Repository fetches metadata for an "owner/repo".
+func (c *Client) Repository(repo string) (*Repository, error) {
+	var r Repository
+	if err := c.Get("/repos/"+repo, &r); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", repo, err)
+	}
+	return &r, nil
+}
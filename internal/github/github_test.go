@@ -0,0 +1,217 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type repo struct {
+	Stars int `json:"stargazers_count"`
+}
+
+func newTestClient(url string) *Client {
+	c := NewClient(nil, url)
+	c.sleep = func(time.Duration) {}
+	return c
+}
+
+func TestTokenFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "gh-token")
+	if got := TokenFromEnv(); got != "gh-token" {
+		t.Errorf("TokenFromEnv() = %q, want gh-token", got)
+	}
+	t.Setenv("GITHUB_TOKEN", "github-token")
+	if got := TokenFromEnv(); got != "github-token" {
+		t.Errorf("TokenFromEnv() = %q, want github-token", got)
+	}
+}
+
+func TestGet_TokenAndRateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		_, _ = w.Write([]byte(`{"stargazers_count": 42}`))
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL).WithToken("secret")
+	var r repo
+	if err := c.Get("/repos/o/r", &r); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if r.Stars != 42 {
+		t.Errorf("Stars = %d, want 42", r.Stars)
+	}
+
+	rl := c.RateLimit()
+	if rl.Limit != 5000 || rl.Remaining != 4999 || rl.Reset.Unix() != reset {
+		t.Errorf("RateLimit() = %+v", rl)
+	}
+}
+
+func TestGet_CacheAndETag(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"stargazers_count": 7}`))
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL).WithCache(t.TempDir())
+
+	var r repo
+	if err := c.Get("/repos/o/r", &r); err != nil || r.Stars != 7 {
+		t.Fatalf("Get() = %+v, %v", r, err)
+	}
+
+	// Fresh cache: no request
+	r = repo{}
+	if err := c.Get("/repos/o/r", &r); err != nil || r.Stars != 7 || requests != 1 {
+		t.Fatalf("cached Get() = %+v, %v after %d requests", r, err, requests)
+	}
+
+	// Stale cache: revalidated with the ETag
+	c.cacheTTL = 0
+	r = repo{}
+	if err := c.Get("/repos/o/r", &r); err != nil || r.Stars != 7 || requests != 2 {
+		t.Fatalf("revalidated Get() = %+v, %v after %d requests", r, err, requests)
+	}
+}
+
+func TestGet_RateLimited(t *testing.T) {
+	t.Run("short Retry-After is retried", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.Header().Set("Retry-After", "2")
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"stargazers_count": 1}`))
+		}))
+		defer server.Close()
+
+		c := newTestClient(server.URL)
+		var waited time.Duration
+		c.sleep = func(d time.Duration) { waited = d }
+
+		var r repo
+		if err := c.Get("/repos/o/r", &r); err != nil || r.Stars != 1 {
+			t.Fatalf("Get() = %+v, %v", r, err)
+		}
+		if waited != 2*time.Second || requests != 2 {
+			t.Errorf("waited %s over %d requests, want 2s over 2", waited, requests)
+		}
+	})
+
+	exhausted := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}
+
+	t.Run("exhausted quota returns RateLimitError", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			exhausted(w, r)
+		}))
+		defer server.Close()
+
+		c := newTestClient(server.URL)
+		var r repo
+		err := c.Get("/repos/o/r", &r)
+		var rlErr *RateLimitError
+		if !errors.As(err, &rlErr) {
+			t.Fatalf("Get() error = %v, want RateLimitError", err)
+		}
+		if rlErr.Authenticated || rlErr.Reset.IsZero() {
+			t.Errorf("RateLimitError = %+v", rlErr)
+		}
+
+		// The client sends nothing more once limited
+		if err := c.Get("/repos/o/other", &r); !errors.As(err, &rlErr) {
+			t.Errorf("second Get() error = %v, want RateLimitError", err)
+		}
+		if requests != 1 {
+			t.Errorf("sent %d requests, want 1", requests)
+		}
+	})
+
+	t.Run("exhausted quota falls back to stale cache", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"stargazers_count": 3}`))
+		}))
+		defer ok.Close()
+		var r repo
+		if err := newTestClient(ok.URL).WithCache(cacheDir).Get("/repos/o/r", &r); err != nil {
+			t.Fatal(err)
+		}
+
+		// Same URL, now throttled
+		limited := newTestClient(ok.URL).WithCache(cacheDir)
+		limited.cacheTTL = 0
+		ok.Config.Handler = http.HandlerFunc(exhausted)
+
+		r = repo{}
+		if err := limited.Get("/repos/o/r", &r); err != nil || r.Stars != 3 {
+			t.Errorf("Get() = %+v, %v; want cached response", r, err)
+		}
+	})
+
+	t.Run("forbidden without rate limit headers is an error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		var r repo
+		err := newTestClient(server.URL).Get("/repos/o/r", &r)
+		var rlErr *RateLimitError
+		if err == nil || errors.As(err, &rlErr) {
+			t.Errorf("Get() error = %v, want status error", err)
+		}
+	})
+}
+
+func TestRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/plugins" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"full_name": "owner/plugins", "stargazers_count": 12, "pushed_at": "2025-05-01T10:00:00Z", "archived": true}`))
+	}))
+	defer server.Close()
+
+	c := newTestClient(server.URL)
+	r, err := c.Repository("owner/plugins")
+	if err != nil {
+		t.Fatalf("Repository() error = %v", err)
+	}
+	if r.Stars != 12 || !r.Archived || r.PushedAt.Year() != 2025 {
+		t.Errorf("Repository() = %+v", r)
+	}
+
+	if _, err := c.Repository("owner/missing"); err == nil {
+		t.Error("Repository() for a missing repo should fail")
+	}
+}
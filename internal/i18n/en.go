@@ -0,0 +1,91 @@
+package i18n
+
+// english is the reference catalog. Every key has an entry here; other
+// catalogs may leave keys out.
+var english = map[Key]Message{
+	SummaryHeader:  {Other: "Summary:"},
+	UnmanagedItems: {Other: "Unmanaged items:"},
+	ErrorsHeader:   {Other: "Errors:"},
+	ListSeparator:  {Other: ", "},
+
+	SyncInstalled:   {Other: "  Installed: %d"},
+	SyncUpdated:     {Other: "  Updated: %d"},
+	SyncFailed:      {Other: "  Failed: %d"},
+	SyncSkipped:     {Other: "  Skipped: %d"},
+	SyncAborted:     {Other: "  Aborted: %s failed (on_failure: abort)"},
+	SyncInterrupted: {Other: "  Interrupted: remaining items skipped"},
+	SyncNotLoggedIn: {Other: "  Not logged in: remaining items skipped (%s)"},
+
+	SyncShortSummary:     {Other: "Summary: %s"},
+	SyncShortInstalled:   {Other: "%d installed"},
+	SyncShortUpdated:     {Other: "%d updated"},
+	SyncShortFailed:      {Other: "%d failed"},
+	SyncShortAborted:     {Other: "aborted after %s"},
+	SyncShortInterrupted: {Other: "interrupted"},
+	SyncShortNotLoggedIn: {Other: "not logged in"},
+
+	SyncErrInterrupted:    {One: "sync interrupted: %d item skipped", Other: "sync interrupted: %d items skipped"},
+	SyncErrFailures:       {One: "sync completed with %d failure", Other: "sync completed with %d failures"},
+	SyncErrFailuresStrict: {One: "sync completed with %d failure (strict mode)", Other: "sync completed with %d failures (strict mode)"},
+	SyncWroteScript:       {One: "Wrote %d command to %s", Other: "Wrote %d commands to %s"},
+
+	DiffSummary:   {Other: "Summary: %d to add, %d to update, %d to remove, %d unmanaged"},
+	DiffStatTotal: {Other: " %d to add, %d to update, %d not in Clewfile, %d blocked"},
+
+	StatusInSync:        {Other: "In sync"},
+	StatusSummary:       {Other: "Add: %d, Update: %d, Remove: %d, Unmanaged: %d"},
+	StatusInSyncLine:    {Other: "Status: In sync"},
+	StatusOutOfSyncLine: {Other: "Status: Out of sync"},
+	StatusToAdd:         {Other: "  To add:       %d"},
+	StatusToUpdate:      {Other: "  To update:    %d"},
+	StatusToRemove:      {Other: "  To remove:    %d"},
+	StatusUnmanaged:     {Other: "  Unmanaged:     %d"},
+	StatusNextSteps:     {Other: "Run 'clew diff' for details or 'clew sync' to apply changes."},
+	StatusStaleSync:     {One: "the last sync is more than %d day old", Other: "the last sync is more than %d days old"},
+	StatusOtherClewfile: {Other: "the last sync used another Clewfile (%s)"},
+	StatusClewfileEdit:  {Other: "the Clewfile changed since the last sync"},
+	StatusMCPServers:    {One: "MCP servers: %[2]d user, %[3]d in %[1]d project", Other: "MCP servers: %[2]d user, %[3]d in %[1]d projects"},
+	StatusGroupPlugins:  {One: "%[2]s: %[1]d plugin", Other: "%[2]s: %[1]d plugins"},
+
+	CheckChanges: {One: "%d change would be made", Other: "%d changes would be made"},
+
+	UndoReverted: {One: "Reverted %d change from run %s", Other: "Reverted %d changes from run %s"},
+
+	SnoozeCleared: {One: "Cleared %d snooze", Other: "Cleared %d snoozes"},
+
+	ExportRedacted:             {One: "Note: Redacted %d credential from marketplace URLs; set %s before syncing", Other: "Note: Redacted %d credentials from marketplace URLs; set %s before syncing"},
+	ExportSkippedMarketplaces:  {One: "Note: Skipped %d local marketplace (no repo): %v", Other: "Note: Skipped %d local marketplaces (no repo): %v"},
+	ExportSkippedNoMarketplace: {One: "Note: Skipped %d plugin referencing non-marketplace sources: %v", Other: "Note: Skipped %d plugins referencing non-marketplace sources: %v"},
+	ExportSkippedProject:       {One: "Note: Skipped %d plugin installed only for projects (see 'clew projects'): %v", Other: "Note: Skipped %d plugins installed only for projects (see 'clew projects'): %v"},
+	ExportSkippedOrphaned:      {One: "Note: Skipped %d plugin not found in marketplace directory: %v", Other: "Note: Skipped %d plugins not found in marketplace directory: %v"},
+
+	FleetInSync:    {One: "%[2]d of %[1]d host in sync", Other: "%[2]d of %[1]d hosts in sync"},
+	LintProblems:   {One: "%d lint problem found", Other: "%d lint problems found"},
+	MCPProblems:    {One: "%d MCP server with problems", Other: "%d MCP servers with problems"},
+	RecommendAdded: {One: "Added %d plugin to %s. Run 'clew sync' to install it.", Other: "Added %d plugins to %s. Run 'clew sync' to install them."},
+	ScanBlocked: {
+		One:   "%d plugin blocked: at or above block score %d, or not available to scan (review it, then add it to scan.allow to trust it)",
+		Other: "%d plugins blocked: at or above block score %d, or not available to scan (review them, then add them to scan.allow to trust them)",
+	},
+
+	RepairDroppedInstalls: {One: "plugin %[2]q: dropped %[1]d malformed install", Other: "plugin %[2]q: dropped %[1]d malformed installs"},
+
+	BaselineSaved:        {One: "Saved baseline of %[2]s and %[1]d plugin to %[3]s", Other: "Saved baseline of %[2]s and %[1]d plugins to %[3]s"},
+	BaselineMarketplaces: {One: "%d marketplace", Other: "%d marketplaces"},
+	BaselineChanges:      {One: "%d change since the approved baseline (run 'clew baseline save' to approve it)", Other: "%d changes since the approved baseline (run 'clew baseline save' to approve them)"},
+
+	NukeRemoved:      {One: "Removed %d plugin and %s", Other: "Removed %d plugins and %s"},
+	NukeMarketplaces: {One: "%d marketplace", Other: "%d marketplaces"},
+	NukeFailures:     {One: "Nuke completed with %d failure.", Other: "Nuke completed with %d failures."},
+	NukeKeptDir:      {Other: "Kept %s: nothing is deleted until every removal succeeds"},
+
+	BackupPruneNone: {One: "No backups to prune. Keeping %d backup.", Other: "No backups to prune. Keeping %d backups."},
+	BackupPruned:    {One: "Pruned %d backup, keeping %d:", Other: "Pruned %d backups, keeping %d:"},
+
+	ProjectsSummary: {One: "%d project, %s, %s", Other: "%d projects, %s, %s"},
+	ProjectsIssues:  {One: "%d issue", Other: "%d issues"},
+	ProjectsStale:   {One: "%d stale install", Other: "%d stale installs"},
+
+	DedupeWouldMerge: {One: "Would merge %d plugin in %s.", Other: "Would merge %d plugins in %s."},
+	DedupeMerged:     {One: "Merged %d plugin in %s.", Other: "Merged %d plugins in %s."},
+}
@@ -0,0 +1,124 @@
+// Package i18n formats clew's user-facing messages in the user's language,
+// choosing singular or plural forms by count. The locale comes from LC_ALL,
+// LC_MESSAGES or LANG; messages missing from its catalog, and locales
+// without one, fall back to English.
+//
+// Adding a language means adding a catalog (see english in en.go) to
+// catalogs, with the plural rule for that language.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Key identifies a message in the catalogs.
+type Key string
+
+// Message is one message in one language. Other is used for every count
+// that does not take the One form, and for messages without a count.
+type Message struct {
+	One   string
+	Other string
+}
+
+// catalog holds a language's messages and its plural rule.
+type catalog struct {
+	one      func(n int) bool // Whether n takes the One form
+	messages map[Key]Message
+}
+
+// fallbackLocale is used when the environment names no locale with a
+// catalog, and for messages a catalog lacks.
+const fallbackLocale = "en"
+
+// catalogs are the available languages by locale: a language ("de") or a
+// language and region ("pt_br").
+var catalogs = map[string]catalog{
+	"en": {one: func(n int) bool { return n == 1 }, messages: english},
+}
+
+var (
+	mu     sync.Mutex
+	locale string // Resolved locale; "" until first use or SetLocale
+)
+
+// SetLocale selects the catalog used by T and N, e.g. "de_DE.UTF-8".
+// Empty re-reads the locale from the environment.
+func SetLocale(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if name == "" {
+		name = envLocale()
+	}
+	locale = resolve(name)
+}
+
+// Locale returns the locale whose catalog is in use.
+func Locale() string {
+	mu.Lock()
+	defer mu.Unlock()
+	if locale == "" {
+		locale = resolve(envLocale())
+	}
+	return locale
+}
+
+// T formats the message for key with args.
+func T(key Key, args ...any) string {
+	return fmt.Sprintf(lookup(key).Other, args...)
+}
+
+// N formats the message for key in the form n takes, with n as the first
+// argument followed by args. Messages can refer to arguments by index,
+// e.g. "%[2]d user, %[1]d projects".
+func N(key Key, n int, args ...any) string {
+	m := lookup(key)
+	format := m.Other
+	if catalogs[Locale()].one(n) && m.One != "" {
+		format = m.One
+	}
+	return fmt.Sprintf(format, append([]any{n}, args...)...)
+}
+
+// lookup returns the message for key in the current locale, or in English
+// when the catalog does not have it.
+func lookup(key Key) Message {
+	if m, ok := catalogs[Locale()].messages[key]; ok {
+		return m
+	}
+	if m, ok := catalogs[fallbackLocale].messages[key]; ok {
+		return m
+	}
+	return Message{Other: string(key)}
+}
+
+// envLocale returns the message locale named by the environment, in the
+// order the C library consults it.
+func envLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// resolve maps a POSIX locale name such as "pt_BR.UTF-8@euro" to the most
+// specific catalog available: "pt_br", then "pt", then English.
+func resolve(name string) string {
+	name, _, _ = strings.Cut(name, ".")
+	name, _, _ = strings.Cut(name, "@")
+	name = strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+	if _, ok := catalogs[name]; ok {
+		return name
+	}
+	if lang, _, ok := strings.Cut(name, "_"); ok {
+		if _, ok := catalogs[lang]; ok {
+			return lang
+		}
+	}
+	return fallbackLocale
+}
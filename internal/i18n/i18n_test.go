@@ -0,0 +1,64 @@
+package i18n
+
+import "testing"
+
+func TestN(t *testing.T) {
+	SetLocale("en_US.UTF-8")
+	t.Cleanup(func() { SetLocale("") })
+
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "sync completed with 0 failures"},
+		{1, "sync completed with 1 failure"},
+		{2, "sync completed with 2 failures"},
+	}
+	for _, tt := range tests {
+		if got := N(SyncErrFailures, tt.n); got != tt.want {
+			t.Errorf("N(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+
+	// Indexed arguments put the count anywhere in the message
+	if got := N(StatusMCPServers, 1, 2, 3); got != "MCP servers: 2 user, 3 in 1 project" {
+		t.Errorf("N(StatusMCPServers) = %q", got)
+	}
+}
+
+func TestLocaleSelection(t *testing.T) {
+	catalogs["fr"] = catalog{
+		one: func(n int) bool { return n == 0 || n == 1 },
+		messages: map[Key]Message{
+			SyncErrFailures: {One: "synchronisation terminée avec %d échec", Other: "synchronisation terminée avec %d échecs"},
+		},
+	}
+	t.Cleanup(func() {
+		delete(catalogs, "fr")
+		SetLocale("")
+	})
+
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "fr_CA.UTF-8@euro")
+	t.Setenv("LANG", "en_US.UTF-8")
+	SetLocale("")
+	if Locale() != "fr" {
+		t.Fatalf("Locale() = %q, want fr from LC_MESSAGES", Locale())
+	}
+
+	// French uses the singular for zero
+	if got := N(SyncErrFailures, 0); got != "synchronisation terminée avec 0 échec" {
+		t.Errorf("N(0) = %q", got)
+	}
+	// Messages the catalog lacks fall back to English
+	if got := T(StatusInSync); got != "In sync" {
+		t.Errorf("T(StatusInSync) = %q, want the English fallback", got)
+	}
+
+	for _, name := range []string{"C", "POSIX", "de_DE.UTF-8"} {
+		SetLocale(name)
+		if Locale() != "en" {
+			t.Errorf("SetLocale(%q): Locale() = %q, want en", name, Locale())
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package i18n
+
+// Shared
+const (
+	SummaryHeader  Key = "summary.header"
+	UnmanagedItems Key = "unmanaged.header"
+	ErrorsHeader   Key = "errors.header"
+	ListSeparator  Key = "list.separator"
+)
+
+// clew sync
+const (
+	SyncInstalled   Key = "sync.installed"
+	SyncUpdated     Key = "sync.updated"
+	SyncFailed      Key = "sync.failed"
+	SyncSkipped     Key = "sync.skipped"
+	SyncAborted     Key = "sync.aborted"
+	SyncInterrupted Key = "sync.interrupted"
+	SyncNotLoggedIn Key = "sync.not_logged_in"
+
+	SyncShortSummary     Key = "sync.short.summary"
+	SyncShortInstalled   Key = "sync.short.installed"
+	SyncShortUpdated     Key = "sync.short.updated"
+	SyncShortFailed      Key = "sync.short.failed"
+	SyncShortAborted     Key = "sync.short.aborted"
+	SyncShortInterrupted Key = "sync.short.interrupted"
+	SyncShortNotLoggedIn Key = "sync.short.not_logged_in"
+
+	SyncErrInterrupted    Key = "sync.err.interrupted"
+	SyncErrFailures       Key = "sync.err.failures"
+	SyncErrFailuresStrict Key = "sync.err.failures_strict"
+	SyncWroteScript       Key = "sync.wrote_script"
+)
+
+// clew diff
+const (
+	DiffSummary   Key = "diff.summary"
+	DiffStatTotal Key = "diff.stat_total"
+)
+
+// clew status
+const (
+	StatusInSync        Key = "status.in_sync"
+	StatusSummary       Key = "status.summary"
+	StatusInSyncLine    Key = "status.in_sync_line"
+	StatusOutOfSyncLine Key = "status.out_of_sync_line"
+	StatusToAdd         Key = "status.to_add"
+	StatusToUpdate      Key = "status.to_update"
+	StatusToRemove      Key = "status.to_remove"
+	StatusUnmanaged     Key = "status.unmanaged"
+	StatusNextSteps     Key = "status.next_steps"
+	StatusStaleSync     Key = "status.stale_sync"
+	StatusOtherClewfile Key = "status.other_clewfile"
+	StatusClewfileEdit  Key = "status.clewfile_changed"
+	StatusMCPServers    Key = "status.mcp_servers"
+	StatusGroupPlugins  Key = "status.group_plugins"
+)
+
+// clew check
+const (
+	CheckChanges Key = "check.changes"
+)
+
+// clew undo
+const (
+	UndoReverted Key = "undo.reverted"
+)
+
+// clew snooze clear
+const (
+	SnoozeCleared Key = "snooze.cleared"
+)
+
+// clew export
+const (
+	ExportRedacted             Key = "export.redacted"
+	ExportSkippedMarketplaces  Key = "export.skipped_marketplaces"
+	ExportSkippedNoMarketplace Key = "export.skipped_no_marketplace"
+	ExportSkippedProject       Key = "export.skipped_project"
+	ExportSkippedOrphaned      Key = "export.skipped_orphaned"
+)
+
+// clew fleet, lint, mcp doctor, recommend and scan
+const (
+	FleetInSync    Key = "fleet.in_sync"
+	LintProblems   Key = "lint.problems"
+	MCPProblems    Key = "mcp.problems"
+	RecommendAdded Key = "recommend.added"
+	ScanBlocked    Key = "scan.blocked"
+)
+
+// clew repair
+const (
+	RepairDroppedInstalls Key = "repair.dropped_installs"
+)
+
+// clew baseline
+const (
+	BaselineSaved        Key = "baseline.saved"
+	BaselineMarketplaces Key = "baseline.marketplaces"
+	BaselineChanges      Key = "baseline.changes"
+)
+
+// clew nuke
+const (
+	NukeRemoved      Key = "nuke.removed"
+	NukeMarketplaces Key = "nuke.marketplaces"
+	NukeFailures     Key = "nuke.failures"
+	NukeKeptDir      Key = "nuke.kept_dir"
+)
+
+// clew backup prune
+const (
+	BackupPruneNone Key = "backup.prune.none"
+	BackupPruned    Key = "backup.prune.pruned"
+)
+
+// clew projects
+const (
+	ProjectsSummary Key = "projects.summary"
+	ProjectsIssues  Key = "projects.issues"
+	ProjectsStale   Key = "projects.stale"
+)
+
+// clew dedupe
+const (
+	DedupeWouldMerge Key = "dedupe.would_merge"
+	DedupeMerged     Key = "dedupe.merged"
+)
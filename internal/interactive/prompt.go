@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"golang.org/x/term"
 
+	"github.com/adamancini/clew/internal/config"
 	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/estimate"
 )
 
 // titleCase capitalizes the first letter of a string.
@@ -29,34 +34,76 @@ func titleCase(s string) string {
 type Response int
 
 const (
-	ResponseYes Response = iota // Proceed with this change
-	ResponseNo                  // Skip this change
-	ResponseAll                 // Approve all remaining changes
-	ResponseQuit                // Abort interactive mode
+	ResponseYes  Response = iota // Proceed with this change
+	ResponseNo                   // Skip this change
+	ResponseAll                  // Approve all remaining changes
+	ResponseQuit                 // Abort interactive mode
+	ResponseEdit                 // Change the item before deciding
 )
 
 // Prompter handles interactive prompts for diff confirmation.
 type Prompter struct {
-	in       io.Reader
-	out      io.Writer
-	scanner  *bufio.Scanner
+	in         io.Reader
+	out        io.Writer
+	scanner    *bufio.Scanner
 	approveAll bool
+	section    *Response // Answer for the rest of the current section (Y or N)
+	estimate   func(*diff.Result) time.Duration
 }
 
+// Resolution is what to do with an item installed but not in the Clewfile.
+type Resolution int
+
+const (
+	ResolutionKeep   Resolution = iota // Leave it as it is
+	ResolutionAdopt                    // Add it to the Clewfile
+	ResolutionRemove                   // Remove it from the system
+	ResolutionIgnore                   // Add it to the Clewfile's ignore list
+)
+
 // Selection tracks which items were approved or skipped.
 type Selection struct {
-	Marketplaces map[string]bool // alias -> approved
-	Plugins      map[string]bool // name -> approved
+	Marketplaces      map[string]bool       // alias -> approved
+	Plugins           map[string]bool       // name -> approved
+	Keybindings       map[string]bool       // "context key" -> approved
+	ExtraMarketplaces map[string]Resolution // alias -> resolution
+	ExtraPlugins      map[string]Resolution // name -> resolution
+
+	EditedMarketplaces map[string]config.Marketplace // alias -> desired state as edited
+	EditedPlugins      map[string]config.Plugin      // name -> desired state as edited
 }
 
 // NewSelection creates an empty selection.
 func NewSelection() *Selection {
 	return &Selection{
-		Marketplaces: make(map[string]bool),
-		Plugins:      make(map[string]bool),
+		Marketplaces:      make(map[string]bool),
+		Plugins:           make(map[string]bool),
+		Keybindings:       make(map[string]bool),
+		ExtraMarketplaces: make(map[string]Resolution),
+		ExtraPlugins:      make(map[string]Resolution),
+
+		EditedMarketplaces: make(map[string]config.Marketplace),
+		EditedPlugins:      make(map[string]config.Plugin),
 	}
 }
 
+// Extras returns the sorted marketplaces and plugins resolved as r.
+func (s *Selection) Extras(r Resolution) (marketplaces, plugins []string) {
+	for alias, res := range s.ExtraMarketplaces {
+		if res == r {
+			marketplaces = append(marketplaces, alias)
+		}
+	}
+	for name, res := range s.ExtraPlugins {
+		if res == r {
+			plugins = append(plugins, name)
+		}
+	}
+	sort.Strings(marketplaces)
+	sort.Strings(plugins)
+	return marketplaces, plugins
+}
+
 // NewPrompter creates a prompter with stdin/stdout.
 func NewPrompter() *Prompter {
 	return NewPrompterWithIO(os.Stdin, os.Stdout)
@@ -71,6 +118,12 @@ func NewPrompterWithIO(in io.Reader, out io.Writer) *Prompter {
 	}
 }
 
+// SetEstimate makes the summary before the final confirmation show how long
+// the approved changes are expected to take, as estimated by f.
+func (p *Prompter) SetEstimate(f func(*diff.Result) time.Duration) {
+	p.estimate = f
+}
+
 // IsTerminal checks if stdin is a terminal (TTY).
 func IsTerminal() bool {
 	return term.IsTerminal(int(os.Stdin.Fd()))
@@ -78,33 +131,72 @@ func IsTerminal() bool {
 
 // prompt displays a question and reads the response.
 func (p *Prompter) prompt(format string, args ...interface{}) Response {
+	return p.ask(false, format, args...)
+}
+
+// ask displays a question and reads the response, offering the edit
+// response if editable.
+func (p *Prompter) ask(editable bool, format string, args ...interface{}) Response {
 	if p.approveAll {
 		return ResponseYes
 	}
+	if p.section != nil {
+		return *p.section
+	}
 
 	_, _ = fmt.Fprintf(p.out, format, args...)
-	_, _ = fmt.Fprint(p.out, " [y/n/a/q] ")
+	if editable {
+		_, _ = fmt.Fprint(p.out, " [y/n/Y/N/e/a/q] ")
+	} else {
+		_, _ = fmt.Fprint(p.out, " [y/n/Y/N/a/q] ")
+	}
 
 	if !p.scanner.Scan() {
 		return ResponseQuit
 	}
 
-	input := strings.ToLower(strings.TrimSpace(p.scanner.Text()))
+	input := strings.TrimSpace(p.scanner.Text())
 	switch input {
+	case "Y", "N":
+		// This item and the rest of its section
+		resp := ResponseYes
+		if input == "N" {
+			resp = ResponseNo
+		}
+		p.section = &resp
+		return resp
+	}
+	switch strings.ToLower(input) {
 	case "y", "yes":
 		return ResponseYes
 	case "n", "no":
 		return ResponseNo
+	case "e", "edit":
+		if editable {
+			return ResponseEdit
+		}
 	case "a", "all":
 		p.approveAll = true
 		return ResponseYes
 	case "q", "quit":
 		return ResponseQuit
-	default:
-		// Default to no for invalid input
-		_, _ = fmt.Fprintln(p.out, "Invalid response, skipping.")
-		return ResponseNo
 	}
+	// Default to no for invalid input
+	_, _ = fmt.Fprintln(p.out, "Invalid response, skipping.")
+	return ResponseNo
+}
+
+// readValue asks for a new value of a field. An empty answer, or end of
+// input, keeps the current value.
+func (p *Prompter) readValue(field, current string) string {
+	_, _ = fmt.Fprintf(p.out, "      %s [%s]: ", field, current)
+	if !p.scanner.Scan() {
+		return current
+	}
+	if input := strings.TrimSpace(p.scanner.Text()); input != "" {
+		return input
+	}
+	return current
 }
 
 // confirmFinal asks for final confirmation before executing.
@@ -129,19 +221,23 @@ func (p *Prompter) PromptForSelection(result *diff.Result) (*Selection, bool) {
 
 	// Process marketplaces
 	hasMarketplaces := false
+	p.section = nil
 	for _, m := range result.Marketplaces {
-		if m.Action == diff.ActionNone || m.Action == diff.ActionRemove {
+		if m.Action == diff.ActionNone || m.Action == diff.ActionRemove || m.Action == diff.ActionManaged {
 			continue
 		}
 		if !hasMarketplaces {
 			_, _ = fmt.Fprintln(p.out, "\nMarketplaces:")
 			hasMarketplaces = true
 		}
-		approved, quit := p.promptMarketplace(m)
+		approved, edited, quit := p.promptMarketplace(m)
 		if quit {
 			return nil, false
 		}
 		selection.Marketplaces[m.Alias] = approved
+		if edited != nil {
+			selection.EditedMarketplaces[m.Alias] = *edited
+		}
 		if approved {
 			if m.Action == diff.ActionAdd {
 				willAdd++
@@ -155,19 +251,23 @@ func (p *Prompter) PromptForSelection(result *diff.Result) (*Selection, bool) {
 
 	// Process plugins
 	hasPlugins := false
+	p.section = nil
 	for _, pl := range result.Plugins {
-		if pl.Action == diff.ActionNone || pl.Action == diff.ActionRemove {
+		if pl.Action == diff.ActionNone || pl.Action == diff.ActionRemove || pl.Action == diff.ActionManaged || pl.Action == diff.ActionBlocked {
 			continue
 		}
 		if !hasPlugins {
 			_, _ = fmt.Fprintln(p.out, "\nPlugins:")
 			hasPlugins = true
 		}
-		approved, quit := p.promptPlugin(pl)
+		approved, edited, quit := p.promptPlugin(pl)
 		if quit {
 			return nil, false
 		}
 		selection.Plugins[pl.Name] = approved
+		if edited != nil {
+			selection.EditedPlugins[pl.Name] = *edited
+		}
 		if approved {
 			if pl.Action == diff.ActionAdd {
 				willAdd++
@@ -179,14 +279,87 @@ func (p *Prompter) PromptForSelection(result *diff.Result) (*Selection, bool) {
 		}
 	}
 
+	// Process key bindings
+	hasKeybindings := false
+	p.section = nil
+	for _, k := range result.Keybindings {
+		if k.Action != diff.ActionAdd && k.Action != diff.ActionUpdate {
+			continue
+		}
+		if !hasKeybindings {
+			_, _ = fmt.Fprintln(p.out, "\nKeybindings:")
+			hasKeybindings = true
+		}
+		approved, quit := p.promptKeybinding(k)
+		if quit {
+			return nil, false
+		}
+		selection.Keybindings[k.Name()] = approved
+		if !approved {
+			skipped++
+		} else if k.Action == diff.ActionAdd {
+			willAdd++
+		} else {
+			willUpdate++
+		}
+	}
+
+	// Process items installed but not in the Clewfile
+	willRemove := 0
+	clewfileUpdates := 0
+	hasExtras := false
+	resolveExtra := func(kind, name string) (Resolution, bool) {
+		if !hasExtras {
+			_, _ = fmt.Fprintln(p.out, "\nNot in Clewfile:")
+			hasExtras = true
+		}
+		res, quit := p.promptExtra(kind, name)
+		switch res {
+		case ResolutionRemove:
+			willRemove++
+		case ResolutionAdopt, ResolutionIgnore:
+			clewfileUpdates++
+		}
+		return res, quit
+	}
+	for _, m := range result.Marketplaces {
+		if m.Action != diff.ActionRemove {
+			continue
+		}
+		res, quit := resolveExtra("marketplace", m.Alias)
+		if quit {
+			return nil, false
+		}
+		selection.ExtraMarketplaces[m.Alias] = res
+	}
+	for _, pl := range result.Plugins {
+		if pl.Action != diff.ActionRemove {
+			continue
+		}
+		res, quit := resolveExtra("plugin", pl.Name)
+		if quit {
+			return nil, false
+		}
+		selection.ExtraPlugins[pl.Name] = res
+	}
+
 	// Show summary
 	_, _ = fmt.Fprintln(p.out, "\nSummary:")
 	_, _ = fmt.Fprintf(p.out, "  Will apply: %d changes\n", willAdd+willUpdate)
+	if p.estimate != nil && willAdd+willUpdate > 0 {
+		_, _ = fmt.Fprintf(p.out, "  Estimated time: %s\n", estimate.Format(p.estimate(FilterDiffBySelection(result, selection))))
+	}
+	if willRemove > 0 {
+		_, _ = fmt.Fprintf(p.out, "  Will remove: %d\n", willRemove)
+	}
+	if clewfileUpdates > 0 {
+		_, _ = fmt.Fprintf(p.out, "  Clewfile updates: %d\n", clewfileUpdates)
+	}
 	if skipped > 0 {
 		_, _ = fmt.Fprintf(p.out, "  Skipped: %d\n", skipped)
 	}
 
-	if willAdd+willUpdate == 0 {
+	if willAdd+willUpdate+willRemove+clewfileUpdates == 0 {
 		_, _ = fmt.Fprintln(p.out, "No changes selected.")
 		return selection, false
 	}
@@ -200,48 +373,125 @@ func (p *Prompter) PromptForSelection(result *diff.Result) (*Selection, bool) {
 	return selection, true
 }
 
-// promptMarketplace prompts for a single marketplace action.
-func (p *Prompter) promptMarketplace(m diff.MarketplaceDiff) (approved bool, quit bool) {
+// promptMarketplace prompts for a single marketplace action. A marketplace
+// being added can be edited first; the edited desired state is returned.
+func (p *Prompter) promptMarketplace(m diff.MarketplaceDiff) (approved bool, edited *config.Marketplace, quit bool) {
 	symbol, verb := actionSymbolVerb(m.Action)
 	_, _ = fmt.Fprintf(p.out, "  %s %s (will %s)\n", symbol, m.Alias, verb)
 
-	repo := ""
+	var desired config.Marketplace
 	if m.Desired != nil {
-		repo = m.Desired.Repo
+		desired = *m.Desired
+	}
+	editable := m.Action == diff.ActionAdd && m.Desired != nil
+
+	for {
+		resp := p.ask(editable, "    -> %s marketplace %s from %s?", titleCase(verb), m.Alias, desired.Repo)
+		switch resp {
+		case ResponseEdit:
+			desired.Repo = p.readValue("repo", desired.Repo)
+			edited = &desired
+			continue
+		case ResponseNo:
+			_, _ = fmt.Fprintf(p.out, "    %s Skipped\n", skipSymbol)
+			return false, nil, false
+		case ResponseQuit:
+			_, _ = fmt.Fprintln(p.out, "\nAborted.")
+			return false, nil, true
+		}
+		return true, edited, false
 	}
+}
+
+// promptPlugin prompts for a single plugin action. A plugin being installed
+// can be edited first, e.g. to install it disabled; the edited desired state
+// is returned.
+func (p *Prompter) promptPlugin(pl diff.PluginDiff) (approved bool, edited *config.Plugin, quit bool) {
+	symbol, verb := actionSymbolVerb(pl.Action)
+	_, _ = fmt.Fprintf(p.out, "  %s %s (will %s)\n", symbol, pl.Name, verb)
 
-	resp := p.prompt("    -> %s marketplace %s from %s?", titleCase(verb), m.Alias, repo)
-	switch resp {
-	case ResponseYes:
-		return true, false
+	var desired config.Plugin
+	if pl.Desired != nil {
+		desired = *pl.Desired
+	}
+	editable := pl.Action == diff.ActionAdd && pl.Desired != nil
+
+	for {
+		question := fmt.Sprintf("%s %s", titleCase(verb), pl.Name)
+		if desired.Enabled != nil && !*desired.Enabled {
+			question += " (disabled)"
+		}
+		resp := p.ask(editable, "    -> %s?", question)
+		switch resp {
+		case ResponseEdit:
+			enabled := desired.Enabled == nil || *desired.Enabled
+			value := p.readValue("enabled", strconv.FormatBool(enabled))
+			if b, err := strconv.ParseBool(value); err == nil {
+				desired.Enabled = &b
+			} else {
+				_, _ = fmt.Fprintf(p.out, "      Invalid value %q (true or false), keeping %t\n", value, enabled)
+			}
+			edited = &desired
+			continue
+		case ResponseNo:
+			_, _ = fmt.Fprintf(p.out, "    %s Skipped\n", skipSymbol)
+			return false, nil, false
+		case ResponseQuit:
+			_, _ = fmt.Fprintln(p.out, "\nAborted.")
+			return false, nil, true
+		}
+		return true, edited, false
+	}
+}
+
+// promptKeybinding prompts for a single key binding change.
+func (p *Prompter) promptKeybinding(k diff.KeybindingDiff) (approved, quit bool) {
+	symbol, verb := actionSymbolVerb(k.Action)
+	_, _ = fmt.Fprintf(p.out, "  %s %s (will %s)\n", symbol, k.Name(), verb)
+
+	action := k.Desired
+	if action == "" {
+		action = "(unbound)"
+	}
+	switch p.prompt("    -> Bind %s to %s?", k.Name(), action) {
 	case ResponseNo:
 		_, _ = fmt.Fprintf(p.out, "    %s Skipped\n", skipSymbol)
 		return false, false
 	case ResponseQuit:
 		_, _ = fmt.Fprintln(p.out, "\nAborted.")
 		return false, true
-	default:
-		return true, false
 	}
+	return true, false
 }
 
-// promptPlugin prompts for a single plugin action.
-func (p *Prompter) promptPlugin(pl diff.PluginDiff) (approved bool, quit bool) {
-	symbol, verb := actionSymbolVerb(pl.Action)
-	_, _ = fmt.Fprintf(p.out, "  %s %s (will %s)\n", symbol, pl.Name, verb)
+// promptExtra asks what to do with an item installed but not in the
+// Clewfile. Unlike other prompts, "approve all" does not answer it.
+func (p *Prompter) promptExtra(kind, name string) (res Resolution, quit bool) {
+	_, _ = fmt.Fprintf(p.out, "  %s %s %s (installed, not in Clewfile)\n", removeSymbol, kind, name)
+	_, _ = fmt.Fprint(p.out, "    -> [a]dd to Clewfile, [r]emove from system, [i]gnore, [s]kip, [q]uit? ")
 
-	resp := p.prompt("    -> %s %s?", titleCase(verb), pl.Name)
-	switch resp {
-	case ResponseYes:
-		return true, false
-	case ResponseNo:
+	if !p.scanner.Scan() {
+		_, _ = fmt.Fprintln(p.out, "\nAborted.")
+		return ResolutionKeep, true
+	}
+
+	input := strings.ToLower(strings.TrimSpace(p.scanner.Text()))
+	switch input {
+	case "a", "add":
+		return ResolutionAdopt, false
+	case "r", "remove":
+		return ResolutionRemove, false
+	case "i", "ignore":
+		return ResolutionIgnore, false
+	case "s", "skip":
 		_, _ = fmt.Fprintf(p.out, "    %s Skipped\n", skipSymbol)
-		return false, false
-	case ResponseQuit:
+		return ResolutionKeep, false
+	case "q", "quit":
 		_, _ = fmt.Fprintln(p.out, "\nAborted.")
-		return false, true
+		return ResolutionKeep, true
 	default:
-		return true, false
+		_, _ = fmt.Fprintln(p.out, "Invalid response, skipping.")
+		return ResolutionKeep, false
 	}
 }
 
@@ -272,7 +522,9 @@ func actionSymbolVerb(action diff.Action) (symbol, verb string) {
 	}
 }
 
-// FilterDiffBySelection returns a new diff.Result containing only approved items.
+// FilterDiffBySelection returns a new diff.Result containing only approved
+// items, with the desired state of edited items replaced. Extras that were
+// resolved are dropped; their outcome is applied separately.
 func FilterDiffBySelection(result *diff.Result, selection *Selection) *diff.Result {
 	filtered := &diff.Result{
 		Marketplaces: make([]diff.MarketplaceDiff, 0),
@@ -280,21 +532,41 @@ func FilterDiffBySelection(result *diff.Result, selection *Selection) *diff.Resu
 	}
 
 	for _, m := range result.Marketplaces {
-		// Keep ActionNone and ActionRemove (info only), filter actionable items by selection
-		if m.Action == diff.ActionNone || m.Action == diff.ActionRemove {
+		// Keep ActionNone, ActionManaged and unresolved ActionRemove (info only), filter actionable items by selection
+		if m.Action == diff.ActionRemove {
+			if selection.ExtraMarketplaces[m.Alias] == ResolutionKeep {
+				filtered.Marketplaces = append(filtered.Marketplaces, m)
+			}
+		} else if m.Action == diff.ActionNone || m.Action == diff.ActionManaged {
 			filtered.Marketplaces = append(filtered.Marketplaces, m)
 		} else if selection.Marketplaces[m.Alias] {
+			if edited, ok := selection.EditedMarketplaces[m.Alias]; ok {
+				m.Desired = &edited
+			}
 			filtered.Marketplaces = append(filtered.Marketplaces, m)
 		}
 	}
 
 	for _, p := range result.Plugins {
-		if p.Action == diff.ActionNone || p.Action == diff.ActionRemove {
+		if p.Action == diff.ActionRemove {
+			if selection.ExtraPlugins[p.Name] == ResolutionKeep {
+				filtered.Plugins = append(filtered.Plugins, p)
+			}
+		} else if p.Action == diff.ActionNone || p.Action == diff.ActionManaged || p.Action == diff.ActionBlocked {
 			filtered.Plugins = append(filtered.Plugins, p)
 		} else if selection.Plugins[p.Name] {
+			if edited, ok := selection.EditedPlugins[p.Name]; ok {
+				p.Desired = &edited
+			}
 			filtered.Plugins = append(filtered.Plugins, p)
 		}
 	}
 
+	for _, k := range result.Keybindings {
+		if k.Action == diff.ActionNone || selection.Keybindings[k.Name()] {
+			filtered.Keybindings = append(filtered.Keybindings, k)
+		}
+	}
+
 	return filtered
 }
@@ -312,6 +312,90 @@ func TestPromptForSelectionPartialApproval(t *testing.T) {
 	}
 }
 
+func TestPromptForSelectionEdit(t *testing.T) {
+	result := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "m1", Action: diff.ActionAdd, Desired: &config.Marketplace{Repo: "test/repo1"}},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "p1@m1", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "p1@m1"}},
+			{Name: "p2@m1", Action: diff.ActionEnable, Desired: &config.Plugin{Name: "p2@m1"}},
+		},
+	}
+
+	// Edit m1's repo then approve; edit p1 to install disabled, with one
+	// invalid value, then approve; "e" is not offered for p2 so skips it
+	input := strings.NewReader("e\nfork/repo1\ny\ne\nmaybe\ne\nfalse\ny\ne\ny\n")
+	output := &bytes.Buffer{}
+	p := NewPrompterWithIO(input, output)
+
+	selection, proceed := p.PromptForSelection(result)
+	if selection == nil || !proceed {
+		t.Fatalf("PromptForSelection() = %v, %v; output:\n%s", selection, proceed, output)
+	}
+
+	if got := selection.EditedMarketplaces["m1"].Repo; got != "fork/repo1" {
+		t.Errorf("edited m1 repo = %q, want fork/repo1", got)
+	}
+	if e := selection.EditedPlugins["p1@m1"].Enabled; e == nil || *e {
+		t.Errorf("edited p1 enabled = %v, want false", e)
+	}
+	if selection.Plugins["p2@m1"] {
+		t.Error("p2 should be skipped: only new items can be edited")
+	}
+	for _, want := range []string{"[y/n/Y/N/e/a/q]", "Add p1@m1 (disabled)?", `Invalid value "maybe"`} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+
+	filtered := FilterDiffBySelection(result, selection)
+	if filtered.Marketplaces[0].Desired.Repo != "fork/repo1" {
+		t.Errorf("filtered m1 repo = %q, want the edited repo", filtered.Marketplaces[0].Desired.Repo)
+	}
+	if result.Marketplaces[0].Desired.Repo != "test/repo1" {
+		t.Error("editing changed the original diff")
+	}
+	if e := filtered.Plugins[0].Desired.Enabled; e == nil || *e {
+		t.Errorf("filtered p1 enabled = %v, want false", e)
+	}
+}
+
+func TestPromptForSelectionSection(t *testing.T) {
+	result := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "m1", Action: diff.ActionAdd, Desired: &config.Marketplace{Repo: "test/repo1"}},
+			{Alias: "m2", Action: diff.ActionAdd, Desired: &config.Marketplace{Repo: "test/repo2"}},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "p1@m1", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "p1@m1"}},
+			{Name: "p2@m1", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "p2@m1"}},
+			{Name: "p3@m2", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "p3@m2"}},
+		},
+	}
+
+	// Skip every marketplace; approve p1, then approve the remaining plugins
+	input := strings.NewReader("N\ny\nY\ny\n")
+	output := &bytes.Buffer{}
+	p := NewPrompterWithIO(input, output)
+
+	selection, proceed := p.PromptForSelection(result)
+	if selection == nil || !proceed {
+		t.Fatalf("PromptForSelection() = %v, %v; output:\n%s", selection, proceed, output)
+	}
+	if selection.Marketplaces["m1"] || selection.Marketplaces["m2"] {
+		t.Errorf("Marketplaces = %v, want both skipped", selection.Marketplaces)
+	}
+	for _, name := range []string{"p1@m1", "p2@m1", "p3@m2"} {
+		if !selection.Plugins[name] {
+			t.Errorf("%s should be approved", name)
+		}
+	}
+	if n := strings.Count(output.String(), "? [y/n/Y/N/e/a/q]"); n != 3 {
+		t.Errorf("asked %d times, want 3 (m1, p1, p2):\n%s", n, output)
+	}
+}
+
 func TestPromptForSelectionNoChanges(t *testing.T) {
 	result := &diff.Result{
 		Marketplaces: []diff.MarketplaceDiff{
@@ -337,7 +421,7 @@ func TestPromptForSelectionNoChanges(t *testing.T) {
 	}
 }
 
-func TestPromptForSelectionSkipsRemoveActions(t *testing.T) {
+func TestPromptForSelectionSkipExtras(t *testing.T) {
 	result := &diff.Result{
 		Marketplaces: []diff.MarketplaceDiff{
 			{Alias: "remove-only", Action: diff.ActionRemove, Current: &state.MarketplaceState{}},
@@ -345,18 +429,83 @@ func TestPromptForSelectionSkipsRemoveActions(t *testing.T) {
 		Plugins: []diff.PluginDiff{},
 	}
 
-	input := strings.NewReader("")
+	input := strings.NewReader("s\n")
 	output := &bytes.Buffer{}
 	p := NewPrompterWithIO(input, output)
 
 	selection, proceed := p.PromptForSelection(result)
 
-	// ActionRemove items are info-only, no prompts for them
 	if selection == nil {
 		t.Fatal("expected non-nil selection")
 	}
 	if proceed {
-		t.Error("expected proceed=false when only remove actions")
+		t.Error("expected proceed=false when every extra is skipped")
+	}
+	if res := selection.ExtraMarketplaces["remove-only"]; res != ResolutionKeep {
+		t.Errorf("remove-only resolution = %v, want ResolutionKeep", res)
+	}
+}
+
+func TestPromptForSelectionResolveExtras(t *testing.T) {
+	enabled := true
+	result := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "scratch", Action: diff.ActionRemove, Current: &state.MarketplaceState{}},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "new@m", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "new@m", Enabled: &enabled}},
+			{Name: "keep@m", Action: diff.ActionRemove, Current: &state.PluginState{}},
+			{Name: "drop@m", Action: diff.ActionRemove, Current: &state.PluginState{}},
+			{Name: "skip@m", Action: diff.ActionRemove, Current: &state.PluginState{}},
+		},
+	}
+
+	// "a" approves all changes but does not answer the extras
+	input := strings.NewReader("a\ni\nadd\nr\ns\ny\n")
+	output := &bytes.Buffer{}
+	p := NewPrompterWithIO(input, output)
+
+	selection, proceed := p.PromptForSelection(result)
+
+	if !proceed {
+		t.Fatalf("expected proceed=true, output:\n%s", output.String())
+	}
+	if !selection.Plugins["new@m"] {
+		t.Error("expected new@m approved")
+	}
+	if res := selection.ExtraMarketplaces["scratch"]; res != ResolutionIgnore {
+		t.Errorf("scratch resolution = %v, want ResolutionIgnore", res)
+	}
+	want := map[string]Resolution{"keep@m": ResolutionAdopt, "drop@m": ResolutionRemove, "skip@m": ResolutionKeep}
+	for name, res := range want {
+		if got := selection.ExtraPlugins[name]; got != res {
+			t.Errorf("%s resolution = %v, want %v", name, got, res)
+		}
+	}
+
+	marketplaces, plugins := selection.Extras(ResolutionIgnore)
+	if len(marketplaces) != 1 || len(plugins) != 0 {
+		t.Errorf("Extras(ResolutionIgnore) = %v, %v", marketplaces, plugins)
+	}
+	filtered := FilterDiffBySelection(result, selection)
+	if len(filtered.Marketplaces) != 0 || len(filtered.Plugins) != 2 || filtered.Plugins[1].Name != "skip@m" {
+		t.Errorf("filtered = %+v, want resolved extras dropped", filtered)
+	}
+	if !strings.Contains(output.String(), "Will remove: 1") || !strings.Contains(output.String(), "Clewfile updates: 2") {
+		t.Errorf("summary missing removal and Clewfile counts:\n%s", output.String())
+	}
+}
+
+func TestPromptForSelectionQuitAtExtra(t *testing.T) {
+	result := &diff.Result{
+		Plugins: []diff.PluginDiff{
+			{Name: "extra@m", Action: diff.ActionRemove, Current: &state.PluginState{}},
+		},
+	}
+
+	p := NewPrompterWithIO(strings.NewReader("q\n"), &bytes.Buffer{})
+	if selection, proceed := p.PromptForSelection(result); selection != nil || proceed {
+		t.Error("expected nil selection and proceed=false after quit")
 	}
 }
 
@@ -0,0 +1,234 @@
+// Package journal records every sync and apply run so past changes can be
+// reviewed with `clew history`.
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/sync"
+	"github.com/adamancini/clew/internal/xdg"
+)
+
+// Run outcomes.
+const (
+	OutcomeSuccess = "success" // Every operation succeeded
+	OutcomePartial = "partial" // Some operations failed
+	OutcomeFailed  = "failed"  // Every attempted operation failed
+
+	OutcomeInterrupted = "interrupted" // Cancelled (Ctrl-C) before every operation ran
+)
+
+// Run is one recorded sync, apply, undo or redo.
+type Run struct {
+	ID          string           `json:"id" yaml:"id"`
+	StartedAt   time.Time        `json:"started_at" yaml:"started_at"`
+	Duration    time.Duration    `json:"duration" yaml:"duration"`
+	Command     string           `json:"command" yaml:"command"`                     // "sync", "apply", "undo" or "redo"
+	Reverts     string           `json:"reverts,omitempty" yaml:"reverts,omitempty"` // Run undone by an undo run
+	Clewfile    string           `json:"clewfile,omitempty" yaml:"clewfile,omitempty"`
+	BackupID    string           `json:"backup_id,omitempty" yaml:"backup_id,omitempty"`
+	ClewVersion string           `json:"clew_version" yaml:"clew_version"`
+	Outcome     string           `json:"outcome" yaml:"outcome"`
+	Installed   int              `json:"installed" yaml:"installed"`
+	Updated     int              `json:"updated" yaml:"updated"`
+	Skipped     int              `json:"skipped" yaml:"skipped"`
+	Failed      int              `json:"failed" yaml:"failed"`
+	Attention   []string         `json:"attention,omitempty" yaml:"attention,omitempty"`
+	Operations  []sync.Operation `json:"operations" yaml:"operations"`
+}
+
+// NewRun builds a journal entry from a sync result.
+func NewRun(command string, startedAt time.Time, result *sync.Result) *Run {
+	r := &Run{
+		ID:         startedAt.Format("2006-01-02-150405"),
+		StartedAt:  startedAt,
+		Duration:   time.Since(startedAt).Round(time.Millisecond),
+		Command:    command,
+		Installed:  result.Installed,
+		Updated:    result.Updated,
+		Skipped:    result.Skipped,
+		Failed:     result.Failed,
+		Attention:  result.Attention,
+		Operations: result.Operations,
+	}
+
+	switch {
+	case result.Interrupted:
+		r.Outcome = OutcomeInterrupted
+	case result.Failed == 0:
+		r.Outcome = OutcomeSuccess
+	case result.Installed+result.Updated == 0:
+		r.Outcome = OutcomeFailed
+	default:
+		r.Outcome = OutcomePartial
+	}
+	return r
+}
+
+// Journal stores runs as one JSON file each.
+type Journal struct {
+	dir string
+}
+
+// New returns the journal in clew's state directory, where it survives
+// clearing caches.
+func New() (*Journal, error) {
+	dir, err := xdg.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewWithDir(filepath.Join(dir, "history")), nil
+}
+
+// NewWithDir returns a journal stored in dir (for testing).
+func NewWithDir(dir string) *Journal {
+	return &Journal{dir: dir}
+}
+
+// Dir returns the directory runs are stored in.
+func (j *Journal) Dir() string {
+	return j.dir
+}
+
+// Record writes a run to the journal. Runs started within the same second
+// get a numeric suffix so none overwrite each other.
+func (j *Journal) Record(r *Run) error {
+	if err := os.MkdirAll(j.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	base := r.ID
+	for n := 2; ; n++ {
+		if _, err := os.Stat(j.path(r.ID)); os.IsNotExist(err) {
+			break
+		}
+		r.ID = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run: %w", err)
+	}
+	if err := atomicfile.WriteFile(j.path(r.ID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write run: %w", err)
+	}
+	return nil
+}
+
+// List returns all recorded runs, newest first.
+func (j *Journal) List() ([]*Run, error) {
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Run{}, nil
+		}
+		return nil, fmt.Errorf("failed to read history directory: %w", err)
+	}
+
+	runs := []*Run{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		r, err := j.load(filepath.Join(j.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		runs = append(runs, r)
+	}
+
+	sort.Slice(runs, func(a, b int) bool {
+		return runs[a].StartedAt.After(runs[b].StartedAt)
+	})
+	return runs, nil
+}
+
+// Get returns a run by ID. Use "latest" for the most recent run.
+func (j *Journal) Get(id string) (*Run, error) {
+	if id == "latest" {
+		runs, err := j.List()
+		if err != nil {
+			return nil, err
+		}
+		if len(runs) == 0 {
+			return nil, fmt.Errorf("no runs recorded")
+		}
+		return runs[0], nil
+	}
+	if id == "" || strings.ContainsAny(id, `/\`) || id == ".." {
+		return nil, fmt.Errorf("invalid run ID: %q", id)
+	}
+	return j.load(j.path(id))
+}
+
+// LastRedoable returns the run redo retries: the most recent sync, apply
+// or redo run or, when an undo is more recent, the run that undo reverted.
+func (j *Journal) LastRedoable() (*Run, error) {
+	runs, err := j.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range runs {
+		switch r.Command {
+		case "undo":
+			for _, reverted := range runs {
+				if reverted.ID == r.Reverts {
+					return reverted, nil
+				}
+			}
+			return nil, fmt.Errorf("run %s, reverted by undo run %s, not found", r.Reverts, r.ID)
+		case "sync", "apply", "redo":
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("no sync/apply/redo run recorded")
+}
+
+// LastUndoable returns the most recent sync, apply or redo run that has not
+// been reverted by a later undo.
+func (j *Journal) LastUndoable() (*Run, error) {
+	runs, err := j.List()
+	if err != nil {
+		return nil, err
+	}
+
+	reverted := make(map[string]bool)
+	for _, r := range runs {
+		if r.Reverts != "" {
+			reverted[r.Reverts] = true
+			continue
+		}
+		if r.Command != "undo" && !reverted[r.ID] {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("no run to undo")
+}
+
+func (j *Journal) path(id string) string {
+	return filepath.Join(j.dir, id+".json")
+}
+
+// load reads and parses a run file.
+func (j *Journal) load(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("run not found: %s", strings.TrimSuffix(filepath.Base(path), ".json"))
+		}
+		return nil, fmt.Errorf("failed to read run: %w", err)
+	}
+
+	var r Run
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse run: %w", err)
+	}
+	return &r, nil
+}
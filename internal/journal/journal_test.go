@@ -0,0 +1,160 @@
+package journal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamancini/clew/internal/sync"
+)
+
+func TestNewRunOutcome(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name   string
+		result *sync.Result
+		want   string
+	}{
+		{"all succeeded", &sync.Result{Installed: 2}, OutcomeSuccess},
+		{"some failed", &sync.Result{Installed: 1, Failed: 1}, OutcomePartial},
+		{"all failed", &sync.Result{Failed: 2}, OutcomeFailed},
+		{"interrupted", &sync.Result{Installed: 1, Skipped: 2, Interrupted: true}, OutcomeInterrupted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewRun("sync", start, tt.result).Outcome; got != tt.want {
+				t.Errorf("Outcome = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournalRecordListGet(t *testing.T) {
+	j := NewWithDir(t.TempDir())
+
+	if runs, err := j.List(); err != nil || len(runs) != 0 {
+		t.Fatalf("List() on empty journal = %v, %v", runs, err)
+	}
+	if _, err := j.Get("latest"); err == nil {
+		t.Error("Get(latest) on empty journal should fail")
+	}
+
+	start := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	first := NewRun("sync", start, &sync.Result{
+		Installed:  1,
+		Operations: []sync.Operation{{Type: "plugin", Name: "a@m", Action: "add", Success: true}},
+	})
+	first.BackupID = "2025-03-04-100000"
+	if err := j.Record(first); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	// Same second: must not overwrite the first run
+	second := NewRun("apply", start, &sync.Result{Updated: 1})
+	second.StartedAt = start.Add(500 * time.Millisecond)
+	if err := j.Record(second); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if second.ID == first.ID {
+		t.Fatalf("second run reused ID %s", first.ID)
+	}
+
+	runs, err := j.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != second.ID {
+		t.Fatalf("List() = %d runs, first %v; want 2, newest first", len(runs), runs[0].ID)
+	}
+
+	got, err := j.Get(first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.BackupID != first.BackupID || len(got.Operations) != 1 || got.Operations[0].Name != "a@m" {
+		t.Errorf("Get() = %+v", got)
+	}
+
+	latest, err := j.Get("latest")
+	if err != nil || latest.ID != second.ID {
+		t.Errorf("Get(latest) = %v, %v; want %s", latest, err, second.ID)
+	}
+
+	if _, err := j.Get("missing"); err == nil {
+		t.Error("Get() for unknown ID should fail")
+	}
+	for _, id := range []string{"../backups/2025-03-04-100000", `..\x`, ".."} {
+		if _, err := j.Get(id); err == nil || !strings.Contains(err.Error(), "invalid run ID") {
+			t.Errorf("Get(%q) error = %v, want invalid run ID", id, err)
+		}
+	}
+}
+
+func TestJournalLastUndoable(t *testing.T) {
+	j := NewWithDir(t.TempDir())
+	start := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	record := func(command, reverts string, offset time.Duration) *Run {
+		t.Helper()
+		r := NewRun(command, start.Add(offset), &sync.Result{})
+		r.Reverts = reverts
+		if err := j.Record(r); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	if _, err := j.LastUndoable(); err == nil {
+		t.Error("LastUndoable() on empty journal should fail")
+	}
+
+	first := record("sync", "", 0)
+	second := record("apply", "", time.Minute)
+
+	if r, err := j.LastUndoable(); err != nil || r.ID != second.ID {
+		t.Fatalf("LastUndoable() = %v, %v; want %s", r, err, second.ID)
+	}
+
+	record("undo", second.ID, 2*time.Minute)
+	if r, err := j.LastUndoable(); err != nil || r.ID != first.ID {
+		t.Fatalf("LastUndoable() after undo = %v, %v; want %s", r, err, first.ID)
+	}
+
+	record("undo", first.ID, 3*time.Minute)
+	if _, err := j.LastUndoable(); err == nil {
+		t.Error("LastUndoable() with every run undone should fail")
+	}
+}
+
+func TestJournalLastRedoable(t *testing.T) {
+	j := NewWithDir(t.TempDir())
+	start := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	record := func(command, reverts string, offset time.Duration) *Run {
+		t.Helper()
+		r := NewRun(command, start.Add(offset), &sync.Result{})
+		r.Reverts = reverts
+		if err := j.Record(r); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	if _, err := j.LastRedoable(); err == nil {
+		t.Error("LastRedoable() on empty journal should fail")
+	}
+
+	first := record("sync", "", 0)
+	second := record("apply", "", time.Minute)
+	if r, err := j.LastRedoable(); err != nil || r.ID != second.ID {
+		t.Fatalf("LastRedoable() = %v, %v; want %s", r, err, second.ID)
+	}
+
+	// After undoing twice, redo works on the run the last undo reverted
+	record("undo", second.ID, 2*time.Minute)
+	record("undo", first.ID, 3*time.Minute)
+	if r, err := j.LastRedoable(); err != nil || r.ID != first.ID {
+		t.Fatalf("LastRedoable() after undo = %v, %v; want %s", r, err, first.ID)
+	}
+}
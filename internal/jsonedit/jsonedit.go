@@ -0,0 +1,189 @@
+// Package jsonedit edits JSON documents clew does not own outright, such as
+// a JSON Clewfile or Claude Code's settings, without disturbing what it does
+// not change: members keep their order and their values are written back
+// exactly as read, so unknown keys survive and integers stay integers.
+package jsonedit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Object is a JSON object whose members keep their order and raw values.
+// Members added with Set go at the end.
+type Object struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+// NewObject returns an empty object.
+func NewObject() *Object {
+	return &Object{values: make(map[string]json.RawMessage)}
+}
+
+// Parse reads a JSON object. Any other JSON value is an error. A key that
+// appears twice keeps its first position and its last value, as
+// encoding/json would decode it.
+func Parse(data []byte) (*Object, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New("not a JSON object")
+	}
+
+	o := NewObject()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %v in object", tok)
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		o.SetRaw(key, value)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after JSON object")
+	}
+	return o, nil
+}
+
+// Has reports whether the object has key.
+func (o *Object) Has(key string) bool {
+	_, ok := o.values[key]
+	return ok
+}
+
+// Keys returns the object's keys in order.
+func (o *Object) Keys() []string {
+	return append([]string(nil), o.keys...)
+}
+
+// Get returns the raw value of key.
+func (o *Object) Get(key string) (json.RawMessage, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// Decode decodes the value of key into v, reporting whether key exists.
+// Numbers decode as json.Number where v leaves the type open, so they are
+// not turned into floats.
+func (o *Object) Decode(key string, v any) (bool, error) {
+	value, ok := o.values[key]
+	if !ok {
+		return false, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return true, fmt.Errorf("%s: %w", key, err)
+	}
+	return true, nil
+}
+
+// Object returns the value of key as an Object, or a new empty one when key
+// is missing.
+func (o *Object) Object(key string) (*Object, error) {
+	value, ok := o.values[key]
+	if !ok {
+		return NewObject(), nil
+	}
+	obj, err := Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	return obj, nil
+}
+
+// Set encodes v as the value of key.
+func (o *Object) Set(key string, v any) error {
+	value, err := Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	o.SetRaw(key, value)
+	return nil
+}
+
+// SetRaw sets the value of key to the encoded JSON value.
+func (o *Object) SetRaw(key string, value json.RawMessage) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Delete removes key.
+func (o *Object) Delete(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// MarshalJSON encodes the object compactly, members in order.
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(o.values[key])
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Indent encodes the object indented by two spaces, with a final newline,
+// as clew writes JSON files.
+func (o *Object) Indent() ([]byte, error) {
+	data, err := o.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// Marshal encodes v without escaping <, > and &, which encoding/json does
+// for HTML and would rewrite in values such as URLs.
+func Marshal(v any) (json.RawMessage, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
@@ -0,0 +1,74 @@
+package jsonedit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestObjectRoundTrip(t *testing.T) {
+	data := `{
+  "version": 1,
+  "$schema": "https://example.com/clewfile.schema.json",
+  "cleanupPeriodDays": 30,
+  "nested": {"b": 1, "a": 2}
+}
+`
+	o, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := strings.Join(o.Keys(), " "); got != "version $schema cleanupPeriodDays nested" {
+		t.Errorf("Keys() = %s", got)
+	}
+
+	if err := o.Set("model", "opus"); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Set("version", 2); err != nil {
+		t.Fatal(err)
+	}
+	o.Delete("cleanupPeriodDays")
+
+	out, err := o.Indent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "version": 2,
+  "$schema": "https://example.com/clewfile.schema.json",
+  "nested": {
+    "b": 1,
+    "a": 2
+  },
+  "model": "opus"
+}
+`
+	if string(out) != want {
+		t.Errorf("Indent() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestDecodeKeepsIntegers(t *testing.T) {
+	o, err := Parse([]byte(`{"limits": {"maxTokens": 12345678901234567}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var limits map[string]any
+	if ok, err := o.Decode("limits", &limits); !ok || err != nil {
+		t.Fatalf("Decode() = %t, %v", ok, err)
+	}
+	if err := o.Set("limits", limits); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := o.Get("limits"); string(raw) != `{"maxTokens":12345678901234567}` {
+		t.Errorf("limits = %s, want the integer kept", raw)
+	}
+}
+
+func TestParseRejectsNonObjects(t *testing.T) {
+	for _, data := range []string{`[]`, `"x"`, `{"a": 1} {}`, `{"a": `} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", data)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Package lint checks a Clewfile against best practices. Unlike validation,
+// findings do not stop clew from using the Clewfile; each rule can be
+// disabled under lint.disable.
+package lint
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/adamancini/clew/internal/config"
+)
+
+// Rule names, as used in lint.disable.
+const (
+	RuleUnpinnedMarketplace  = "unpinned-marketplace"
+	RuleInlineSecret         = "inline-secret"
+	RuleLocalPathOutsideHome = "local-path-outside-home"
+	RuleUnusedMarketplace    = "unused-marketplace"
+)
+
+// Rule is a single best-practice check.
+type Rule struct {
+	Name        string
+	Description string
+	check       func(r *config.Resolved, env environment) []Finding
+}
+
+// environment is what rules may depend on besides the Clewfile.
+type environment struct {
+	home string
+}
+
+// Rules lists every rule.
+var Rules = []Rule{
+	{RuleUnpinnedMarketplace, "marketplace without a ref, so plugin versions follow its default branch", checkUnpinnedMarketplace},
+	{RuleInlineSecret, "credentials written into a repository URL instead of an environment variable reference", checkInlineSecret},
+	{RuleLocalPathOutsideHome, "local marketplace path outside the home directory", checkLocalPathOutsideHome},
+	{RuleUnusedMarketplace, "marketplace that no plugin uses", checkUnusedMarketplace},
+}
+
+// Finding is a rule violation.
+type Finding struct {
+	Rule     string        `json:"rule" yaml:"rule"`
+	Field    string        `json:"field" yaml:"field"`
+	Message  string        `json:"message" yaml:"message"`
+	Location config.Origin `json:"location" yaml:"location"`
+}
+
+// Run checks the Clewfile with every rule not disabled in its lint section.
+// Findings are sorted by location. Disabling an unknown rule is an error.
+func Run(r *config.Resolved) ([]Finding, error) {
+	home, _ := os.UserHomeDir()
+	return run(r, environment{home: home})
+}
+
+func run(r *config.Resolved, env environment) ([]Finding, error) {
+	disabled := make(map[string]bool)
+	for _, name := range r.Clewfile.Lint.Disable {
+		if !isRule(name) {
+			return nil, fmt.Errorf("lint.disable: unknown rule %q", name)
+		}
+		disabled[name] = true
+	}
+
+	var findings []Finding
+	for _, rule := range Rules {
+		if disabled[rule.Name] {
+			continue
+		}
+		findings = append(findings, rule.check(r, env)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Location.Line < findings[j].Location.Line
+	})
+	return findings, nil
+}
+
+func isRule(name string) bool {
+	for _, rule := range Rules {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedAliases returns the marketplace aliases in a stable order.
+func sortedAliases(r *config.Resolved) []string {
+	aliases := make([]string, 0, len(r.Clewfile.Marketplaces))
+	for alias := range r.Clewfile.Marketplaces {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// isLocalPath reports whether a marketplace repo is a directory on disk.
+func isLocalPath(repo string) bool {
+	return strings.HasPrefix(repo, "/") || strings.HasPrefix(repo, ".") || strings.HasPrefix(repo, "~")
+}
+
+func checkUnpinnedMarketplace(r *config.Resolved, _ environment) []Finding {
+	var findings []Finding
+	for _, alias := range sortedAliases(r) {
+		m := r.Clewfile.Marketplaces[alias]
+		if m.Ref != "" || isLocalPath(m.Repo) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Rule:     RuleUnpinnedMarketplace,
+			Field:    "marketplaces." + alias,
+			Message:  "no ref; pin a tag or commit so plugin versions only change when you choose",
+			Location: r.Marketplaces[alias],
+		})
+	}
+	return findings
+}
+
+func checkInlineSecret(r *config.Resolved, _ environment) []Finding {
+	var findings []Finding
+	for _, alias := range sortedAliases(r) {
+		origin := r.Marketplaces[alias]
+		// Credentials that came from ${VAR} are fine
+		written := origin.Written
+		if written == "" {
+			written = r.Clewfile.Marketplaces[alias].Repo
+		}
+		if !hasCredentials(written) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Rule:     RuleInlineSecret,
+			Field:    "marketplaces." + alias + ".repo",
+			Message:  "credentials in the repository URL; reference an environment variable like ${GITHUB_TOKEN} instead",
+			Location: origin,
+		})
+	}
+	return findings
+}
+
+// hasCredentials reports whether a URL embeds a password or token that is
+// not an environment variable reference.
+func hasCredentials(repo string) bool {
+	u, err := url.Parse(repo)
+	if err != nil || u.User == nil || u.Scheme == "" {
+		return false
+	}
+	secret, ok := u.User.Password()
+	if !ok {
+		// https://TOKEN@host carries the token as the user name; ssh's git@ does not
+		if u.Scheme == "ssh" || u.User.Username() == "git" {
+			return false
+		}
+		secret = u.User.Username()
+	}
+	return secret != "" && !strings.Contains(secret, "${")
+}
+
+func checkLocalPathOutsideHome(r *config.Resolved, env environment) []Finding {
+	if env.home == "" {
+		return nil
+	}
+	var findings []Finding
+	for _, alias := range sortedAliases(r) {
+		repo := r.Clewfile.Marketplaces[alias].Repo
+		if !isLocalPath(repo) || strings.HasPrefix(repo, "~") {
+			continue
+		}
+		path := repo
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(r.Path), path)
+		}
+		rel, err := filepath.Rel(env.home, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Rule:     RuleLocalPathOutsideHome,
+			Field:    "marketplaces." + alias + ".repo",
+			Message:  fmt.Sprintf("%s is outside your home directory, so the Clewfile will not work on other machines", repo),
+			Location: r.Marketplaces[alias],
+		})
+	}
+	return findings
+}
+
+func checkUnusedMarketplace(r *config.Resolved, _ environment) []Finding {
+	used := make(map[string]bool)
+	for _, p := range r.Clewfile.Plugins {
+		if i := strings.LastIndex(p.Name, "@"); i >= 0 {
+			used[p.Name[i+1:]] = true
+		}
+	}
+
+	var findings []Finding
+	for _, alias := range sortedAliases(r) {
+		if used[alias] {
+			continue
+		}
+		findings = append(findings, Finding{
+			Rule:     RuleUnusedMarketplace,
+			Field:    "marketplaces." + alias,
+			Message:  "no plugin in the Clewfile uses this marketplace",
+			Location: r.Marketplaces[alias],
+		})
+	}
+	return findings
+}
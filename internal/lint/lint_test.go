@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+)
+
+func resolved(marketplaces map[string]config.Marketplace, plugins ...string) *config.Resolved {
+	r := &config.Resolved{
+		Path:         "/home/me/.config/claude/Clewfile.yaml",
+		Clewfile:     &config.Clewfile{Version: 1, Marketplaces: marketplaces},
+		Marketplaces: make(map[string]config.Origin),
+	}
+	line := 1
+	for alias := range marketplaces {
+		r.Marketplaces[alias] = config.Origin{File: r.Path, Line: line}
+		line++
+	}
+	for _, p := range plugins {
+		r.Clewfile.Plugins = append(r.Clewfile.Plugins, config.Plugin{Name: p})
+		r.Plugins = append(r.Plugins, config.Origin{File: r.Path})
+	}
+	return r
+}
+
+func rules(findings []Finding) []string {
+	var names []string
+	for _, f := range findings {
+		names = append(names, f.Field+" "+f.Rule)
+	}
+	return names
+}
+
+func TestRun(t *testing.T) {
+	env := environment{home: "/home/me"}
+
+	tests := []struct {
+		name         string
+		marketplaces map[string]config.Marketplace
+		plugins      []string
+		want         []string
+	}{
+		{
+			name:         "clean",
+			marketplaces: map[string]config.Marketplace{"official": {Repo: "owner/repo", Ref: "v1.0.0"}},
+			plugins:      []string{"a@official"},
+		},
+		{
+			name:         "unpinned and unused",
+			marketplaces: map[string]config.Marketplace{"official": {Repo: "owner/repo"}},
+			want:         []string{"marketplaces.official unpinned-marketplace", "marketplaces.official unused-marketplace"},
+		},
+		{
+			name:         "inline token",
+			marketplaces: map[string]config.Marketplace{"company": {Repo: "https://ghp_secret@github.com/acme/plugins.git", Ref: "main"}},
+			plugins:      []string{"a@company"},
+			want:         []string{"marketplaces.company.repo inline-secret"},
+		},
+		{
+			name:         "ssh user is not a secret",
+			marketplaces: map[string]config.Marketplace{"company": {Repo: "ssh://git@github.com/acme/plugins.git", Ref: "main"}},
+			plugins:      []string{"a@company"},
+		},
+		{
+			name: "local paths",
+			marketplaces: map[string]config.Marketplace{
+				"inside":  {Repo: "/home/me/src/plugins"},
+				"outside": {Repo: "/opt/plugins"},
+			},
+			plugins: []string{"a@inside", "b@outside"},
+			want:    []string{"marketplaces.outside.repo local-path-outside-home"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := run(resolved(tt.marketplaces, tt.plugins...), env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rules(findings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_SecretFromEnvironment(t *testing.T) {
+	r := resolved(map[string]config.Marketplace{"company": {Repo: "https://ghp_secret@github.com/acme/plugins.git", Ref: "main"}}, "a@company")
+	r.Marketplaces["company"] = config.Origin{File: r.Path, Written: "https://${TOKEN}@github.com/acme/plugins.git"}
+
+	findings, err := run(r, environment{home: "/home/me"})
+	if err != nil || len(findings) != 0 {
+		t.Errorf("findings = %v, %v; want none", findings, err)
+	}
+}
+
+func TestRun_Disable(t *testing.T) {
+	r := resolved(map[string]config.Marketplace{"official": {Repo: "owner/repo"}})
+	r.Clewfile.Lint.Disable = []string{RuleUnpinnedMarketplace}
+
+	findings, err := run(r, environment{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rules(findings); !reflect.DeepEqual(got, []string{"marketplaces.official unused-marketplace"}) {
+		t.Errorf("findings = %v", got)
+	}
+
+	r.Clewfile.Lint.Disable = []string{"no-such-rule"}
+	if _, err := run(r, environment{}); err == nil {
+		t.Error("disabling an unknown rule should fail")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package lock
+
+import "os"
+
+// clew only ships for darwin and linux; elsewhere locking is a no-op.
+
+func tryLock(f *os.File) error  { return nil }
+func waitLock(f *os.File) error { return nil }
+func unlock(f *os.File) error   { return nil }
@@ -0,0 +1,159 @@
+// Package lock provides a cross-process advisory lock so concurrent clew runs
+// cannot interleave writes to Claude Code's plugin state.
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adamancini/clew/internal/state"
+)
+
+// FileName is the lock file name inside the Claude plugins directory.
+const FileName = ".clew.lock"
+
+// errWouldBlock is returned by tryLock when another process holds the lock.
+var errWouldBlock = errors.New("lock held by another process")
+
+// Holder describes the process holding the lock.
+type Holder struct {
+	PID       int       `json:"pid"`
+	Hostname  string    `json:"hostname"`
+	Command   string    `json:"command"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// String returns a human-readable description of the holder.
+func (h *Holder) String() string {
+	return fmt.Sprintf("pid %d on %s (clew %s) since %s",
+		h.PID, h.Hostname, h.Command, h.StartedAt.Format("2006-01-02 15:04:05"))
+}
+
+// LockedError is returned when the lock is held and waiting was not requested.
+type LockedError struct {
+	Path   string
+	Holder *Holder // nil if the holder could not be determined
+}
+
+func (e *LockedError) Error() string {
+	if e.Holder == nil {
+		return fmt.Sprintf("another clew process is running (lock: %s); use --wait to wait for it", e.Path)
+	}
+	return fmt.Sprintf("another clew process is running: %s (lock: %s); use --wait to wait for it", e.Holder, e.Path)
+}
+
+// Lock is a held advisory lock. Release it when done.
+type Lock struct {
+	path string
+	file *os.File
+}
+
+// DefaultPath returns the lock path for the given Claude directory.
+// An empty claudeDir resolves to $CLAUDE_CONFIG_DIR or ~/.claude.
+func DefaultPath(claudeDir string) (string, error) {
+	if claudeDir == "" {
+		var err error
+		if claudeDir, err = state.DefaultClaudeDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(claudeDir, "plugins", FileName), nil
+}
+
+// Acquire takes the lock at path for the named command. If the lock is held
+// and wait is false, a *LockedError is returned. If wait is true, onWait (if
+// non-nil) is called with the current holder and Acquire blocks until the
+// lock is released.
+func Acquire(path, command string, wait bool, onWait func(*Holder)) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := tryLock(file); err != nil {
+		if !errors.Is(err, errWouldBlock) {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		holder := readHolder(path)
+		if !wait {
+			_ = file.Close()
+			return nil, &LockedError{Path: path, Holder: holder}
+		}
+
+		if onWait != nil {
+			onWait(holder)
+		}
+		if err := waitLock(file); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+	}
+
+	l := &Lock{path: path, file: file}
+	if err := l.writeHolder(command); err != nil {
+		_ = l.Release()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Release drops the lock. The lock file is left in place so that waiting
+// processes keep locking the same inode.
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	_ = l.file.Truncate(0)
+	err := unlock(l.file)
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	l.file = nil
+	return err
+}
+
+// writeHolder records this process as the lock holder.
+func (l *Lock) writeHolder(command string) error {
+	hostname, _ := os.Hostname()
+	data, err := json.Marshal(Holder{
+		PID:       os.Getpid(),
+		Hostname:  hostname,
+		Command:   command,
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal lock holder: %w", err)
+	}
+
+	if err := l.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	if _, err := l.file.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return l.file.Sync()
+}
+
+// readHolder reads the holder recorded in the lock file, if any.
+func readHolder(path string) *Holder {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	var h Holder
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil
+	}
+	return &h
+}
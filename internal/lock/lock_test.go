@@ -0,0 +1,102 @@
+package lock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireAndRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugins", FileName)
+
+	l, err := Acquire(path, "sync", false, nil)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	holder := readHolder(path)
+	if holder == nil {
+		t.Fatal("expected holder to be recorded in lock file")
+	}
+	if holder.PID != os.Getpid() || holder.Command != "sync" {
+		t.Errorf("holder = %+v, want pid %d command sync", holder, os.Getpid())
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	// Lock can be taken again after release
+	l2, err := Acquire(path, "sync", false, nil)
+	if err != nil {
+		t.Fatalf("Acquire() after release error = %v", err)
+	}
+	_ = l2.Release()
+}
+
+func TestAcquireWhileHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	held, err := Acquire(path, "sync", false, nil)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer func() { _ = held.Release() }()
+
+	_, err = Acquire(path, "backup restore", false, nil)
+	var lockedErr *LockedError
+	if !errors.As(err, &lockedErr) {
+		t.Fatalf("Acquire() error = %v, want *LockedError", err)
+	}
+	if lockedErr.Holder == nil || lockedErr.Holder.Command != "sync" {
+		t.Errorf("LockedError.Holder = %+v, want command sync", lockedErr.Holder)
+	}
+}
+
+func TestAcquireWait(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	held, err := Acquire(path, "sync", false, nil)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	waited := make(chan *Holder, 1)
+	acquired := make(chan error, 1)
+	go func() {
+		l, err := Acquire(path, "apply", true, func(h *Holder) { waited <- h })
+		if err == nil {
+			_ = l.Release()
+		}
+		acquired <- err
+	}()
+
+	select {
+	case h := <-waited:
+		if h == nil || h.Command != "sync" {
+			t.Errorf("onWait holder = %+v, want command sync", h)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("onWait was not called")
+	}
+
+	_ = held.Release()
+
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Errorf("waiting Acquire() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting Acquire() did not return after release")
+	}
+}
+
+func TestReleaseNil(t *testing.T) {
+	var l *Lock
+	if err := l.Release(); err != nil {
+		t.Errorf("Release() on nil lock error = %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Package logging provides leveled diagnostic output for -v, -vv and -vvv,
+// and a --trace mode that reports every external command as it runs.
+//
+// Diagnostics go to stderr so they never mix with text, JSON or YAML
+// results on stdout.
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the verbosity level, equal to the number of -v flags.
+type Level int
+
+const (
+	LevelNormal    Level = iota // No diagnostics
+	LevelDecisions              // -v: what clew decided and why
+	LevelCommands               // -vv: full output of external commands
+	LevelTrace                  // -vvv: raw state file parsing
+)
+
+var (
+	mu    sync.Mutex
+	level Level
+	trace bool
+	out   io.Writer = os.Stderr
+
+	// now is replaced in tests to make durations deterministic
+	now = time.Now
+)
+
+// SetLevel sets the global verbosity level.
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// SetTrace turns tracing of external commands on or off.
+func SetTrace(on bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	trace = on
+}
+
+// SetOutput redirects diagnostics (for testing).
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+}
+
+// Enabled reports whether messages at level l are shown.
+func Enabled(l Level) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return level >= l
+}
+
+// Decisionf logs a decision at -v.
+func Decisionf(format string, args ...any) {
+	logf(LevelDecisions, format, args...)
+}
+
+// Commandf logs an external command and its output at -vv.
+func Commandf(format string, args ...any) {
+	logf(LevelCommands, format, args...)
+}
+
+// Tracef logs state parsing details at -vvv.
+func Tracef(format string, args ...any) {
+	logf(LevelTrace, format, args...)
+}
+
+// Command logs an executed command with its combined output at -vv.
+func Command(name string, args []string, output []byte, err error) {
+	if !Enabled(LevelCommands) {
+		return
+	}
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+	Commandf("$ %s %s (%s)", name, strings.Join(args, " "), status)
+	if trimmed := strings.TrimRight(string(output), "\n"); trimmed != "" {
+		for _, line := range strings.Split(trimmed, "\n") {
+			Commandf("  | %s", line)
+		}
+	}
+}
+
+// StartCommand records that an external command is about to run and returns
+// a function to call with its result once it finishes. With tracing on, the
+// command is printed immediately and its exit code and duration when it
+// completes, followed by its output at -vv. Without tracing it behaves like
+// Command.
+func StartCommand(name string, args []string) func(output []byte, err error) {
+	mu.Lock()
+	tracing := trace
+	mu.Unlock()
+	if !tracing {
+		return func(output []byte, err error) {
+			Command(name, args, output, err)
+		}
+	}
+
+	line := strings.TrimSpace(name + " " + strings.Join(args, " "))
+	writef("trace: $ %s", line)
+	start := now()
+	return func(output []byte, err error) {
+		elapsed := now().Sub(start).Round(time.Millisecond)
+		writef("trace:   %s after %s", exitStatus(err), elapsed)
+		if !Enabled(LevelCommands) {
+			return
+		}
+		if trimmed := strings.TrimRight(string(output), "\n"); trimmed != "" {
+			for _, l := range strings.Split(trimmed, "\n") {
+				writef("trace:   | %s", l)
+			}
+		}
+	}
+}
+
+// exitStatus describes how a command ended.
+func exitStatus(err error) string {
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return "exit 0"
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		return fmt.Sprintf("exit %d", exitErr.ExitCode())
+	default:
+		return "failed: " + err.Error()
+	}
+}
+
+// writef writes a message regardless of level.
+func writef(format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	write(format, args...)
+}
+
+func logf(l Level, format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if level < l {
+		return
+	}
+	write(format, args...)
+}
+
+// write formats a message and writes it to out. mu must be held.
+func write(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	_, _ = io.WriteString(out, msg)
+}
@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withLevel(t *testing.T, l Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	SetLevel(l)
+	SetOutput(&buf)
+	t.Cleanup(func() {
+		SetLevel(LevelNormal)
+		SetOutput(os.Stderr)
+	})
+	return &buf
+}
+
+func TestLevels(t *testing.T) {
+	tests := []struct {
+		level Level
+		want  []string
+		skip  []string
+	}{
+		{LevelNormal, nil, []string{"decision", "command", "trace"}},
+		{LevelDecisions, []string{"decision"}, []string{"command", "trace"}},
+		{LevelCommands, []string{"decision", "command"}, []string{"trace"}},
+		{LevelTrace, []string{"decision", "command", "trace"}, nil},
+	}
+
+	for _, tt := range tests {
+		buf := withLevel(t, tt.level)
+		Decisionf("decision")
+		Commandf("command")
+		Tracef("trace")
+
+		out := buf.String()
+		for _, w := range tt.want {
+			if !strings.Contains(out, w) {
+				t.Errorf("level %d: output missing %q: %q", tt.level, w, out)
+			}
+		}
+		for _, s := range tt.skip {
+			if strings.Contains(out, s) {
+				t.Errorf("level %d: output should not contain %q: %q", tt.level, s, out)
+			}
+		}
+	}
+}
+
+func TestCommand(t *testing.T) {
+	buf := withLevel(t, LevelCommands)
+	Command("claude", []string{"plugin", "install", "foo"}, []byte("line one\nline two\n"), errors.New("exit status 1"))
+
+	want := "$ claude plugin install foo (exit status 1)\n  | line one\n  | line two\n"
+	if buf.String() != want {
+		t.Errorf("Command() output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestStartCommandTrace(t *testing.T) {
+	tick := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time {
+		tick = tick.Add(1500 * time.Millisecond)
+		return tick
+	}
+	t.Cleanup(func() {
+		now = time.Now
+		SetTrace(false)
+	})
+
+	tests := []struct {
+		name  string
+		level Level
+		err   error
+		want  string
+	}{
+		{"normal", LevelNormal, nil,
+			"trace: $ git status --porcelain\ntrace:   exit 0 after 1.5s\n"},
+		{"output at -vv", LevelCommands, nil,
+			"trace: $ git status --porcelain\ntrace:   exit 0 after 1.5s\ntrace:   | M file.go\n"},
+		{"start failure", LevelNormal, exec.ErrNotFound,
+			"trace: $ git status --porcelain\ntrace:   failed: executable file not found in $PATH after 1.5s\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := withLevel(t, tt.level)
+			SetTrace(true)
+			done := StartCommand("git", []string{"status", "--porcelain"})
+			done([]byte("M file.go\n"), tt.err)
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStartCommandExitCode(t *testing.T) {
+	buf := withLevel(t, LevelNormal)
+	SetTrace(true)
+	t.Cleanup(func() { SetTrace(false) })
+
+	done := StartCommand("sh", []string{"-c", "exit 3"})
+	done(exec.Command("sh", "-c", "exit 3").CombinedOutput())
+	if !strings.Contains(buf.String(), "trace:   exit 3 after ") {
+		t.Errorf("output = %q, want exit 3", buf.String())
+	}
+}
+
+func TestStartCommandWithoutTrace(t *testing.T) {
+	buf := withLevel(t, LevelCommands)
+	done := StartCommand("claude", []string{"--version"})
+	done([]byte("1.0.0\n"), nil)
+
+	want := "$ claude --version (ok)\n  | 1.0.0\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
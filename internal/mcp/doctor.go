@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Finding is one problem in a server's configuration.
+type Finding struct {
+	Field   string `json:"field" yaml:"field"` // e.g. "env.GITHUB_TOKEN", "args[2]", "url", "headers.Authorization"
+	Problem string `json:"problem" yaml:"problem"`
+}
+
+// Diagnosis is the doctor's report on one server. It leaves out the
+// server's environment and headers, which usually hold credentials.
+type Diagnosis struct {
+	Name     string    `json:"name" yaml:"name"`
+	Scope    string    `json:"scope" yaml:"scope"`
+	Source   string    `json:"source" yaml:"source"`
+	Findings []Finding `json:"findings" yaml:"findings"`
+}
+
+// placeholderPattern matches values left as they were copied from setup
+// instructions, such as YOUR_TOKEN, <api-key> or changeme.
+var placeholderPattern = regexp.MustCompile(`(?i)(\byour[_-][a-z0-9_-]+|<[^<>]+>|\b(changeme|replace[_-]?me|xxx+|placeholder)\b)`)
+
+// envRefPattern matches ${VAR} and ${VAR:-default} references.
+var envRefPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// Diagnose checks each server's configuration without starting it.
+func Diagnose(servers []Server) []Diagnosis {
+	diagnoses := make([]Diagnosis, 0, len(servers))
+	for _, s := range servers {
+		diagnoses = append(diagnoses, Diagnosis{
+			Name:     s.Name,
+			Scope:    s.Scope,
+			Source:   s.Source,
+			Findings: Doctor(s),
+		})
+	}
+	return diagnoses
+}
+
+// Doctor checks one server's configuration: ${VAR} references resolve,
+// the command and the paths in its arguments exist, its URL parses, and
+// no environment value or header is an obvious placeholder.
+func Doctor(s Server) []Finding {
+	findings := []Finding{}
+	add := func(field, format string, args ...any) {
+		findings = append(findings, Finding{Field: field, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	// Every value Claude expands, in a stable order
+	type value struct{ field, text string }
+	var values []value
+	if s.Remote() {
+		values = append(values, value{"url", s.URL})
+	} else {
+		values = append(values, value{"command", s.Command})
+		for i, arg := range s.Args {
+			values = append(values, value{fmt.Sprintf("args[%d]", i), arg})
+		}
+	}
+	for _, name := range sortedNames(s.Env) {
+		values = append(values, value{"env." + name, s.Env[name]})
+	}
+	for _, name := range sortedNames(s.Headers) {
+		values = append(values, value{"headers." + name, s.Headers[name]})
+	}
+
+	for _, v := range values {
+		for _, name := range unsetVars(v.text) {
+			add(v.field, "${%s} is not set", name)
+		}
+		if !strings.HasPrefix(v.field, "env.") && !strings.HasPrefix(v.field, "headers.") {
+			continue
+		}
+		if v.text == "" && strings.HasPrefix(v.field, "headers.") {
+			add(v.field, "is empty")
+		} else if m := placeholderPattern.FindString(expandEnv(v.text)); m != "" {
+			add(v.field, "looks like a placeholder (%q)", m)
+		}
+	}
+
+	if s.Remote() {
+		if problem := checkURL(expandEnv(s.URL)); problem != "" {
+			add("url", "%s", problem)
+		}
+		return findings
+	}
+
+	if problem := checkCommand(expandEnv(s.Command)); problem != "" {
+		add("command", "%s", problem)
+	}
+	for i, arg := range s.Args {
+		path := expandPath(expandEnv(arg))
+		if !isPath(path) {
+			continue
+		}
+		if !filepath.IsAbs(path) && s.Project != "" {
+			// Claude starts project servers in the project directory
+			path = filepath.Join(s.Project, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			add(fmt.Sprintf("args[%d]", i), "%s does not exist", arg)
+		}
+	}
+	return findings
+}
+
+// unsetVars returns the variables s references without a default that are
+// not set in the environment.
+func unsetVars(s string) []string {
+	var names []string
+	for _, m := range envRefPattern.FindAllStringSubmatch(s, -1) {
+		name, _, hasDefault := strings.Cut(m[1], ":-")
+		if hasDefault {
+			continue
+		}
+		if _, ok := os.LookupEnv(name); !ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// checkURL describes what is wrong with a remote server's URL, if anything.
+func checkURL(raw string) string {
+	if raw == "" {
+		return "is not set"
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Sprintf("does not parse: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Sprintf("%s is not an http or https URL", raw)
+	}
+	if u.Host == "" {
+		return fmt.Sprintf("%s has no host", raw)
+	}
+	return ""
+}
+
+// checkCommand describes why a stdio server's command cannot run, if it
+// cannot.
+func checkCommand(command string) string {
+	if command == "" {
+		return "is not set"
+	}
+	if strings.ContainsRune(command, filepath.Separator) {
+		if _, err := os.Stat(expandPath(command)); err != nil {
+			return fmt.Sprintf("%s does not exist", command)
+		}
+		return ""
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return fmt.Sprintf("%s is not on PATH", command)
+	}
+	return ""
+}
+
+// isPath reports whether an argument names a file rather than a flag or
+// other value.
+func isPath(arg string) bool {
+	return filepath.IsAbs(arg) || strings.HasPrefix(arg, "./") || strings.HasPrefix(arg, "../")
+}
+
+// expandPath expands a leading ~/ to the home directory.
+func expandPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// sortedNames returns the keys of m in order.
+func sortedNames(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "server.js")
+	if err := os.WriteFile(script, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CLEW_DOCTOR_TOKEN", "ghp_real")
+
+	tests := []struct {
+		name   string
+		server Server
+		want   []string // "field: problem" prefixes, in order
+	}{
+		{
+			name: "healthy stdio",
+			server: Server{
+				Command: "sh",
+				Args:    []string{"-c", script, "./server.js", "${CLEW_DOCTOR_UNSET:-x}"},
+				Env:     map[string]string{"TOKEN": "${CLEW_DOCTOR_TOKEN}", "DEBUG": ""},
+				Project: dir,
+			},
+		},
+		{
+			name: "broken stdio",
+			server: Server{
+				Command: "clew-no-such-command",
+				Args:    []string{filepath.Join(dir, "missing.js"), "--token=${CLEW_DOCTOR_UNSET}"},
+				Env:     map[string]string{"API_KEY": "YOUR_API_KEY", "GITHUB_TOKEN": "<token>"},
+			},
+			want: []string{
+				"args[1]: ${CLEW_DOCTOR_UNSET} is not set",
+				`env.API_KEY: looks like a placeholder ("YOUR_API_KEY")`,
+				`env.GITHUB_TOKEN: looks like a placeholder ("<token>")`,
+				"command: clew-no-such-command is not on PATH",
+				"args[0]: " + filepath.Join(dir, "missing.js") + " does not exist",
+			},
+		},
+		{
+			name: "healthy remote",
+			server: Server{
+				Type:    "http",
+				URL:     "https://${CLEW_DOCTOR_HOST:-mcp.example.com}/mcp",
+				Headers: map[string]string{"Authorization": "Bearer ${CLEW_DOCTOR_TOKEN}"},
+			},
+		},
+		{
+			name: "broken remote",
+			server: Server{
+				Type:    "sse",
+				URL:     "mcp.example.com/sse",
+				Headers: map[string]string{"Authorization": "Bearer YOUR_TOKEN", "X-Api-Key": ""},
+			},
+			want: []string{
+				`headers.Authorization: looks like a placeholder ("YOUR_TOKEN")`,
+				"headers.X-Api-Key: is empty",
+				"url: mcp.example.com/sse is not an http or https URL",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := Doctor(tt.server)
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.Field+": "+f.Problem)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Doctor() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
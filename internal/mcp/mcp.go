@@ -0,0 +1,294 @@
+// Package mcp reads the MCP servers Claude is configured with and checks
+// that remote (http and sse) servers can be reached.
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adamancini/clew/internal/logging"
+)
+
+// Files MCP servers are declared in.
+const (
+	UserConfigFile = ".claude.json" // In the home directory (or $CLAUDE_CONFIG_DIR): user and per-project servers
+	ProjectFile    = ".mcp.json"    // In a project directory
+)
+
+// Scopes a server can be declared at, as Claude names them.
+const (
+	ScopeUser    = "user"    // mcpServers in ~/.claude.json
+	ScopeLocal   = "local"   // projects[dir].mcpServers in ~/.claude.json
+	ScopeProject = "project" // mcpServers in dir/.mcp.json
+)
+
+// DefaultTimeout bounds each reachability check.
+const DefaultTimeout = 3 * time.Second
+
+// Server is one MCP server entry.
+type Server struct {
+	Name    string `json:"name" yaml:"name"`
+	Scope   string `json:"scope" yaml:"scope"`
+	Source  string `json:"source" yaml:"source"`                       // File the server is declared in
+	Project string `json:"project,omitempty" yaml:"project,omitempty"` // Project directory, for local and project servers
+	Type    string `json:"type,omitempty" yaml:"type,omitempty"`
+	URL     string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Headers are sent to remote servers. They are left out of output
+	// because they usually hold credentials.
+	Headers map[string]string `json:"-" yaml:"-"`
+
+	// Stdio servers
+	Command string            `json:"command,omitempty" yaml:"command,omitempty"`
+	Args    []string          `json:"args,omitempty" yaml:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// Remote reports whether Claude reaches the server over HTTP.
+func (s Server) Remote() bool {
+	return s.Type == "http" || s.Type == "sse"
+}
+
+// serverEntry is the part of an mcpServers entry clew reads.
+type serverEntry struct {
+	Type    string            `json:"type"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+}
+
+// userConfig is the part of ~/.claude.json clew reads.
+type userConfig struct {
+	MCPServers map[string]serverEntry `json:"mcpServers"`
+	Projects   map[string]struct {
+		MCPServers map[string]serverEntry `json:"mcpServers"`
+	} `json:"projects"`
+}
+
+// Declared returns the servers that apply in dir: user servers and dir's
+// per-project (local) servers from ~/.claude.json, then servers from
+// dir/.mcp.json. configDir holds ~/.claude.json (see
+// state.GlobalConfigDir). Missing files are skipped.
+func Declared(configDir, dir string) ([]Server, error) {
+	userPath := filepath.Join(configDir, UserConfigFile)
+	var user userConfig
+	if err := readJSON(userPath, &user); err != nil {
+		return nil, err
+	}
+	servers := appendServers(nil, ScopeUser, userPath, "", user.MCPServers)
+	return appendProjectServers(servers, userPath, dir, user.Projects[dir].MCPServers)
+}
+
+// DeclaredAll returns the user servers and, for every project recorded in
+// ~/.claude.json, that project's local and .mcp.json servers, projects in
+// path order. A project directory without .mcp.json contributes its local
+// servers only; one whose .mcp.json cannot be read is skipped with a
+// warning, so a single broken project does not hide every other server.
+func DeclaredAll(configDir string) ([]Server, error) {
+	userPath := filepath.Join(configDir, UserConfigFile)
+	var user userConfig
+	if err := readJSON(userPath, &user); err != nil {
+		return nil, err
+	}
+	servers := appendServers(nil, ScopeUser, userPath, "", user.MCPServers)
+
+	dirs := make([]string, 0, len(user.Projects))
+	for dir := range user.Projects {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		withProject, err := appendProjectServers(servers, userPath, dir, user.Projects[dir].MCPServers)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping the MCP servers of %s: %v\n", dir, err)
+			continue
+		}
+		servers = withProject
+	}
+	return servers, nil
+}
+
+// ForProject returns the servers that apply in dir: user servers and those
+// declared for dir.
+func ForProject(servers []Server, dir string) []Server {
+	var filtered []Server
+	for _, s := range servers {
+		if s.Project == "" || s.Project == dir {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// appendProjectServers adds dir's local servers, then those in
+// dir/.mcp.json.
+func appendProjectServers(servers []Server, userPath, dir string, local map[string]serverEntry) ([]Server, error) {
+	servers = appendServers(servers, ScopeLocal, userPath, dir, local)
+
+	projectPath := filepath.Join(dir, ProjectFile)
+	var project struct {
+		MCPServers map[string]serverEntry `json:"mcpServers"`
+	}
+	if err := readJSON(projectPath, &project); err != nil {
+		return nil, err
+	}
+	return appendServers(servers, ScopeProject, projectPath, dir, project.MCPServers), nil
+}
+
+// Find returns the declaration of name Claude would use when it is declared
+// more than once: local, then project, then user.
+func Find(servers []Server, name string) (Server, bool) {
+	for _, scope := range []string{ScopeLocal, ScopeProject, ScopeUser} {
+		for _, s := range servers {
+			if s.Name == name && s.Scope == scope {
+				return s, true
+			}
+		}
+	}
+	return Server{}, false
+}
+
+// appendServers adds entries from one mcpServers map, sorted by name.
+func appendServers(servers []Server, scope, source, project string, entries map[string]serverEntry) []Server {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e := entries[name]
+		servers = append(servers, Server{
+			Name:    name,
+			Scope:   scope,
+			Source:  source,
+			Project: project,
+			Type:    e.Type,
+			URL:     e.URL,
+			Headers: e.Headers,
+			Command: e.Command,
+			Args:    e.Args,
+			Env:     e.Env,
+		})
+	}
+	return servers
+}
+
+// readJSON decodes path into v. A missing file leaves v untouched.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// Unreachable is a remote server that failed its reachability check.
+type Unreachable struct {
+	Server  `yaml:",inline"`
+	Problem string `json:"problem" yaml:"problem"`
+}
+
+// Checker probes remote MCP servers.
+type Checker struct {
+	client *http.Client
+}
+
+// NewChecker returns a checker whose requests time out after timeout.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{client: &http.Client{Timeout: timeout}}
+}
+
+// CheckAll checks every remote server concurrently and returns those that
+// cannot be reached, in the order given. Local (stdio) servers are skipped.
+func (c *Checker) CheckAll(servers []Server) []Unreachable {
+	problems := make([]string, len(servers))
+	var wg sync.WaitGroup
+	for i, s := range servers {
+		if !s.Remote() {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			problems[i] = c.Check(s.URL)
+		}()
+	}
+	wg.Wait()
+
+	var unreachable []Unreachable
+	for i, problem := range problems {
+		if problem != "" {
+			unreachable = append(unreachable, Unreachable{Server: servers[i], Problem: problem})
+		}
+	}
+	return unreachable
+}
+
+// Check returns why the URL cannot be reached, or "" if it answers. Any
+// response short of 404, 410 or a server error counts: an endpoint asking
+// for credentials is up. URLs that use ${VAR} expansion are not checked,
+// since the variable may only be set when Claude runs.
+func (c *Checker) Check(endpoint string) string {
+	if endpoint == "" {
+		return "no url configured"
+	}
+	if strings.Contains(endpoint, "${") {
+		return ""
+	}
+
+	status, err := c.request(http.MethodHead, endpoint)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		// Streaming endpoints often accept only GET or POST
+		status, err = c.request(http.MethodGet, endpoint)
+	}
+	switch {
+	case err != nil:
+		return err.Error()
+	case status == http.StatusNotFound, status == http.StatusGone, status >= 500:
+		return fmt.Sprintf("returned %d %s", status, http.StatusText(status))
+	}
+	return ""
+}
+
+// request sends one request and returns the status without reading the
+// body, so an event stream does not hold the check open.
+func (c *Checker) request(method, endpoint string) (int, error) {
+	req, err := http.NewRequest(method, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			if urlErr.Timeout() {
+				return 0, fmt.Errorf("no response within %s", c.client.Timeout)
+			}
+			// The URL is reported alongside the problem already
+			return 0, urlErr.Err
+		}
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	logging.Tracef("mcp: %s %s -> %d", method, endpoint, resp.StatusCode)
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeclared(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+
+	userConfig := `{
+  "mcpServers": {
+    "zeta": {"type": "http", "url": "https://zeta.example.com/mcp"},
+    "alpha": {"type": "stdio", "command": "alpha-mcp"}
+  },
+  "projects": {
+    "` + dir + `": {"mcpServers": {"project-sse": {"type": "sse", "url": "https://sse.example.com"}}},
+    "/elsewhere": {"mcpServers": {"other": {"type": "http", "url": "https://other.example.com"}}}
+  }
+}`
+	if err := os.WriteFile(filepath.Join(home, UserConfigFile), []byte(userConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ProjectFile), []byte(`{"mcpServers": {"docs": {"type": "http", "url": "https://docs.example.com"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	servers, err := Declared(home, dir)
+	if err != nil {
+		t.Fatalf("Declared() error = %v", err)
+	}
+	var names []string
+	for _, s := range servers {
+		names = append(names, s.Name)
+	}
+	if got := strings.Join(names, ","); got != "alpha,zeta,project-sse,docs" {
+		t.Errorf("Declared() = %s, want alpha,zeta,project-sse,docs", got)
+	}
+	if servers[2].Scope != ScopeLocal || servers[3].Scope != ScopeProject || servers[0].Command != "alpha-mcp" {
+		t.Errorf("Declared() scopes = %+v", servers)
+	}
+	if servers[3].Source != filepath.Join(dir, ProjectFile) || !servers[3].Remote() || servers[0].Remote() {
+		t.Errorf("Declared() servers = %+v", servers)
+	}
+
+	// Neither file existing is not an error
+	if servers, err := Declared(t.TempDir(), t.TempDir()); err != nil || len(servers) != 0 {
+		t.Errorf("Declared(empty) = %v, %v", servers, err)
+	}
+}
+
+func TestDeclaredAll(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	broken := t.TempDir()
+
+	userConfig := `{
+  "mcpServers": {"alpha": {"type": "stdio", "command": "alpha-mcp"}},
+  "projects": {
+    "` + dir + `": {"mcpServers": {"local": {"type": "stdio", "command": "local-mcp"}}},
+    "/elsewhere": {"mcpServers": {"other": {"type": "http", "url": "https://other.example.com"}}},
+    "` + broken + `": {"mcpServers": {"hidden": {"type": "stdio", "command": "hidden-mcp"}}}
+  }
+}`
+	if err := os.WriteFile(filepath.Join(home, UserConfigFile), []byte(userConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ProjectFile), []byte(`{"mcpServers": {"docs": {"type": "http", "url": "https://docs.example.com"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A malformed .mcp.json skips its project only
+	if err := os.WriteFile(filepath.Join(broken, ProjectFile), []byte(`{"mcpServers": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	servers, err := DeclaredAll(home)
+	if err != nil {
+		t.Fatalf("DeclaredAll() error = %v", err)
+	}
+	var got []string
+	for _, s := range servers {
+		got = append(got, s.Project+":"+s.Name)
+	}
+	// Projects in path order; /elsewhere has no .mcp.json
+	want := []string{":alpha", "/elsewhere:other", dir + ":local", dir + ":docs"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("DeclaredAll() = %v, want %v", got, want)
+	}
+
+	got = nil
+	for _, s := range ForProject(servers, dir) {
+		got = append(got, s.Name)
+	}
+	if strings.Join(got, ",") != "alpha,local,docs" {
+		t.Errorf("ForProject() = %v, want alpha,local,docs", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusUnauthorized) })
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadGateway) })
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) { time.Sleep(200 * time.Millisecond) })
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	checker := NewChecker(50 * time.Millisecond)
+	tests := []struct {
+		url  string
+		want string // Substring of the problem, "" if reachable
+	}{
+		{server.URL + "/ok", ""},
+		{server.URL + "/auth", ""},
+		{server.URL + "/get-only", ""},
+		{server.URL + "/${MCP_PATH}", ""},
+		{server.URL + "/missing", "404"},
+		{server.URL + "/broken", "502"},
+		{server.URL + "/slow", "no response within 50ms"},
+		{"", "no url"},
+	}
+	for _, tt := range tests {
+		got := checker.Check(tt.url)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("Check(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	servers := []Server{
+		{Name: "up", Type: "http", URL: server.URL},
+		{Name: "local", Type: "stdio"},
+		{Name: "down", Type: "sse", URL: "http://127.0.0.1:1"},
+	}
+	unreachable := NewChecker(time.Second).CheckAll(servers)
+	if len(unreachable) != 1 || unreachable[0].Name != "down" {
+		t.Errorf("CheckAll() = %+v, want only down", unreachable)
+	}
+}
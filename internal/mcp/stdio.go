@@ -0,0 +1,231 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adamancini/clew/internal/logging"
+)
+
+// ProtocolVersion is the MCP revision clew offers when initializing.
+const ProtocolVersion = "2025-06-18"
+
+// DefaultStartTimeout bounds how long a stdio server has to start and answer
+// the initialize request. Servers launched through npx or uvx may download
+// packages first.
+const DefaultStartTimeout = 30 * time.Second
+
+// maxStderr is how much of a failing server's stderr is kept for the error.
+const maxStderr = 4096
+
+// Stdio reports whether Claude launches the server as a local process.
+// Entries without a type default to stdio.
+func (s Server) Stdio() bool {
+	return s.Type == "stdio" || (s.Type == "" && s.Command != "")
+}
+
+// InitializeResult is a server's answer to the initialize request.
+type InitializeResult struct {
+	ProtocolVersion string                    `json:"protocolVersion" yaml:"protocol_version"`
+	ServerInfo      ServerInfo                `json:"serverInfo" yaml:"server_info"`
+	Capabilities    map[string]map[string]any `json:"capabilities" yaml:"capabilities"`
+	Instructions    string                    `json:"instructions,omitempty" yaml:"instructions,omitempty"`
+}
+
+// ServerInfo identifies the server implementation.
+type ServerInfo struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// rpcMessage is a JSON-RPC message read from the server.
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Initialize launches a stdio server the way Claude would, with its
+// configured arguments and environment, sends an initialize request and
+// returns the server's answer. The process is stopped once it has answered
+// or ctx is done. clientVersion is reported to the server as clew's version.
+func Initialize(ctx context.Context, s Server, clientVersion string) (*InitializeResult, error) {
+	if !s.Stdio() {
+		return nil, fmt.Errorf("%s is an %s server, not stdio", s.Name, s.Type)
+	}
+	if s.Command == "" {
+		return nil, fmt.Errorf("%s has no command configured", s.Name)
+	}
+
+	args := make([]string, len(s.Args))
+	for i, arg := range s.Args {
+		args[i] = expandEnv(arg)
+	}
+	cmd := exec.CommandContext(ctx, expandEnv(s.Command), args...)
+	cmd.Env = os.Environ()
+	for k, v := range s.Env {
+		cmd.Env = append(cmd.Env, k+"="+expandEnv(v))
+	}
+	// Launchers such as npx leave children holding the pipes after a kill
+	cmd.WaitDelay = time.Second
+	var stderr limitedBuffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Commandf("mcp: starting %s: %s %s", s.Name, cmd.Path, strings.Join(args, " "))
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start: %w", err)
+	}
+	defer func() {
+		_ = stdin.Close()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	request := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "initialize",
+		"params": map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]any{},
+			"clientInfo":      map[string]string{"name": "clew", "version": clientVersion},
+		},
+	}
+	if err := writeMessage(stdin, request); err != nil {
+		// A server that exits at once closes its stdin; reading its output
+		// reports that along with what it printed
+		logging.Tracef("mcp: failed to send initialize request: %v", err)
+	}
+
+	type answer struct {
+		result *InitializeResult
+		err    error
+	}
+	answers := make(chan answer, 1)
+	go func() {
+		result, err := readInitializeResult(stdout)
+		answers <- answer{result, err}
+	}()
+
+	select {
+	case a := <-answers:
+		if a.err != nil {
+			// Let the server finish exiting so its stderr is complete
+			_ = cmd.Wait()
+			return nil, withStderr(a.err, stderr.String())
+		}
+		// Complete the handshake so the server does not log a protocol error
+		_ = writeMessage(stdin, map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"})
+		return a.result, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, withStderr(errors.New("no response to initialize before the timeout"), stderr.String())
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// readInitializeResult reads newline-delimited messages until the response
+// to request 1 arrives. Notifications and log lines the server writes
+// before it are skipped.
+func readInitializeResult(r io.Reader) (*InitializeResult, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		var msg rpcMessage
+		if len(line) == 0 || json.Unmarshal(line, &msg) != nil {
+			logging.Tracef("mcp: skipping output %q", line)
+			continue
+		}
+		if string(msg.ID) != "1" {
+			continue
+		}
+		if msg.Error != nil {
+			return nil, fmt.Errorf("server rejected initialize: %s (code %d)", msg.Error.Message, msg.Error.Code)
+		}
+		var result InitializeResult
+		if err := json.Unmarshal(msg.Result, &result); err != nil {
+			return nil, fmt.Errorf("invalid initialize result: %w", err)
+		}
+		return &result, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("server exited before answering initialize")
+}
+
+func writeMessage(w io.Writer, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// withStderr appends what the server printed on stderr, which usually says
+// why it failed.
+func withStderr(err error, stderr string) error {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" {
+		return err
+	}
+	return fmt.Errorf("%w\nserver stderr:\n%s", err, stderr)
+}
+
+// expandEnv expands ${VAR} and ${VAR:-default} as Claude does in MCP server
+// commands, arguments and environment values.
+func expandEnv(s string) string {
+	return os.Expand(s, func(key string) string {
+		name, def, hasDefault := strings.Cut(key, ":-")
+		if v, ok := os.LookupEnv(name); ok && (v != "" || !hasDefault) {
+			return v
+		}
+		return def
+	})
+}
+
+// limitedBuffer keeps the last maxStderr bytes written to it.
+type limitedBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > maxStderr {
+		b.buf = b.buf[len(b.buf)-maxStderr:]
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
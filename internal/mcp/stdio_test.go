@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeServer writes a shell script standing in for a stdio MCP server.
+func writeServer(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "server.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInitialize(t *testing.T) {
+	script := writeServer(t, `echo "starting $1"
+read request
+echo '{"jsonrpc":"2.0","method":"notifications/message","params":{}}'
+echo '{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-06-18","serverInfo":{"name":"fake","version":"'"$FAKE_VERSION"'"},"capabilities":{"tools":{"listChanged":true},"logging":{}}}}'
+cat >/dev/null
+`)
+	t.Setenv("CLEW_TEST_VERSION", "")
+	server := Server{
+		Name:    "fake",
+		Command: script,
+		Args:    []string{"--flag"},
+		Env:     map[string]string{"FAKE_VERSION": "${CLEW_TEST_VERSION:-1.2.3}"},
+	}
+
+	result, err := Initialize(context.Background(), server, "test")
+	if err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if result.ServerInfo.Name != "fake" || result.ServerInfo.Version != "1.2.3" || result.ProtocolVersion != "2025-06-18" {
+		t.Errorf("Initialize() = %+v", result)
+	}
+	if result.Capabilities["tools"]["listChanged"] != true {
+		t.Errorf("Initialize() capabilities = %v", result.Capabilities)
+	}
+}
+
+func TestInitializeFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"exits", "echo 'missing API key' >&2\nexit 1\n", []string{"exited before answering", "missing API key"}},
+		{"rejects", `read request
+echo '{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"unsupported version"}}'
+`, []string{"rejected initialize: unsupported version"}},
+		{"hangs", "sleep 5\n", []string{"no response"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			_, err := Initialize(ctx, Server{Name: tt.name, Command: writeServer(t, tt.script)}, "test")
+			if err == nil {
+				t.Fatal("Initialize() error = nil")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Initialize() error = %q, want %q", err, want)
+				}
+			}
+		})
+	}
+
+	if _, err := Initialize(context.Background(), Server{Name: "web", Type: "http", URL: "https://example.com"}, "test"); err == nil {
+		t.Error("Initialize(http) error = nil")
+	}
+}
+
+func TestFind(t *testing.T) {
+	servers := []Server{
+		{Name: "github", Scope: ScopeUser},
+		{Name: "github", Scope: ScopeLocal},
+		{Name: "github", Scope: ScopeProject},
+		{Name: "docs", Scope: ScopeUser},
+	}
+	if s, ok := Find(servers, "github"); !ok || s.Scope != ScopeLocal {
+		t.Errorf("Find(github) = %+v, %v, want local scope", s, ok)
+	}
+	if s, ok := Find(servers, "docs"); !ok || s.Scope != ScopeUser {
+		t.Errorf("Find(docs) = %+v, %v", s, ok)
+	}
+	if _, ok := Find(servers, "missing"); ok {
+		t.Error("Find(missing) found a server")
+	}
+}
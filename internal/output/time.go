@@ -0,0 +1,57 @@
+package output
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeLayout is the exact timestamp format used in text listings.
+const TimeLayout = "2006-01-02 15:04:05"
+
+// Timestamp formats t for a text listing: relative to now ("2 days ago"),
+// or the exact local time when exact is set (e.g. with --verbose). JSON and
+// YAML output keep the full time value instead.
+func Timestamp(t time.Time, exact bool) string {
+	if exact {
+		return t.Local().Format(TimeLayout)
+	}
+	return RelativeTime(t, time.Now())
+}
+
+// RelativeTime describes t relative to now in the largest whole unit, such
+// as "just now", "5 minutes ago", "3 weeks ago" or "in 2 hours". Times over
+// a year away fall back to the date.
+func RelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var n int
+	var unit string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		n, unit = int(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		n, unit = int(d/time.Hour), "hour"
+	case d < 7*24*time.Hour:
+		n, unit = int(d/(24*time.Hour)), "day"
+	case d < 30*24*time.Hour:
+		n, unit = int(d/(7*24*time.Hour)), "week"
+	case d < 365*24*time.Hour:
+		n, unit = int(d/(30*24*time.Hour)), "month"
+	default:
+		return t.Local().Format("2006-01-02")
+	}
+
+	if n != 1 {
+		unit += "s"
+	}
+	if future {
+		return fmt.Sprintf("in %d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %s ago", n, unit)
+}
@@ -0,0 +1,42 @@
+package output
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{45 * time.Minute, "45 minutes ago"},
+		{3 * time.Hour, "3 hours ago"},
+		{26 * time.Hour, "1 day ago"},
+		{2 * 24 * time.Hour, "2 days ago"},
+		{15 * 24 * time.Hour, "2 weeks ago"},
+		{65 * 24 * time.Hour, "2 months ago"},
+		{-2 * time.Hour, "in 2 hours"},
+	}
+	for _, tt := range tests {
+		if got := RelativeTime(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("RelativeTime(-%s) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+
+	old := now.AddDate(-2, 0, 0)
+	if got, want := RelativeTime(old, now), old.Local().Format("2006-01-02"); got != want {
+		t.Errorf("RelativeTime(2 years ago) = %q, want date %q", got, want)
+	}
+}
+
+func TestTimestampExact(t *testing.T) {
+	ts := time.Date(2025, 6, 15, 12, 30, 45, 0, time.Local)
+	if got := Timestamp(ts, true); got != "2025-06-15 12:30:45" {
+		t.Errorf("Timestamp(exact) = %q", got)
+	}
+}
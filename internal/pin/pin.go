@@ -0,0 +1,41 @@
+// Package pin verifies marketplace plugins against the sha256 and commit
+// pins declared for them in the Clewfile.
+package pin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/drift"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// Verify checks an installed plugin against its pins. It returns nil if
+// the plugin is not pinned.
+func Verify(p config.Plugin, installed state.PluginState) error {
+	if p.Commit != "" {
+		got := installed.GitCommitSha
+		if got == "" {
+			return fmt.Errorf("plugin %s is pinned to commit %s, but the marketplace recorded no commit for the install", p.Name, p.Commit)
+		}
+		if !strings.HasPrefix(got, p.Commit) {
+			return fmt.Errorf("plugin %s was installed from commit %s, but the Clewfile pins commit %s", p.Name, got, p.Commit)
+		}
+	}
+
+	if p.SHA256 != "" {
+		if installed.InstallPath == "" {
+			return fmt.Errorf("plugin %s is pinned to sha256 %s, but its install path is unknown", p.Name, p.SHA256)
+		}
+		got, err := drift.HashPlugin(installed.InstallPath)
+		if err != nil {
+			return err
+		}
+		if got != p.SHA256 {
+			return fmt.Errorf("plugin %s has sha256 %s, but the Clewfile pins %s", p.Name, got, p.SHA256)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package pin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/drift"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func writePlugin(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestVerify(t *testing.T) {
+	dir := writePlugin(t, map[string]string{"hooks/run.sh": "echo hi"})
+	sum, err := drift.HashPlugin(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	installed := state.PluginState{InstallPath: dir, GitCommitSha: "3f9c2a1b7e0d4c5a6b8f9e0d1c2b3a4f5e6d7c8b"}
+
+	tests := []struct {
+		name    string
+		plugin  config.Plugin
+		wantErr string
+	}{
+		{"unpinned", config.Plugin{Name: "p@m"}, ""},
+		{"matching pins", config.Plugin{Name: "p@m", SHA256: sum, Commit: "3f9c2a1"}, ""},
+		{"wrong commit", config.Plugin{Name: "p@m", Commit: "0000000"}, "pins commit 0000000"},
+		{"wrong sha256", config.Plugin{Name: "p@m", SHA256: strings.Repeat("0", 64)}, "has sha256 " + sum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.plugin, installed)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Verify() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Verify() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := Verify(config.Plugin{Name: "p@m", Commit: "3f9c2a1"}, state.PluginState{}); err == nil {
+		t.Error("Verify() with no recorded commit = nil, want error")
+	}
+}
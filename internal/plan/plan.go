@@ -0,0 +1,179 @@
+// Package plan serializes a computed diff so it can be reviewed and applied later.
+package plan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/estimate"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// FormatVersion is the plan file format version.
+const FormatVersion = 1
+
+// Plan is a saved diff together with fingerprints of the inputs it was
+// computed from. Applying a plan is refused if the system state changed.
+type Plan struct {
+	FormatVersion       int          `json:"format_version"`
+	CreatedAt           time.Time    `json:"created_at"`
+	ClewVersion         string       `json:"clew_version"`
+	ClewfilePath        string       `json:"clewfile_path"`
+	ClewfileFingerprint string       `json:"clewfile_fingerprint"`
+	StateFingerprint    string       `json:"state_fingerprint"`
+	Diff                *diff.Result `json:"diff"`
+
+	// Estimate lists the operations applying the plan would run with how
+	// long each is expected to take, from this machine's recorded timings.
+	Estimate []estimate.Operation `json:"estimate,omitempty"`
+}
+
+// New creates a plan for the given diff, fingerprinting the current state
+// and the Clewfile at clewfilePath.
+func New(d *diff.Result, current *state.State, clewfilePath, clewVersion string) (*Plan, error) {
+	stateFP, err := Fingerprint(current)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(clewfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Clewfile: %w", err)
+	}
+
+	return &Plan{
+		FormatVersion:       FormatVersion,
+		CreatedAt:           time.Now(),
+		ClewVersion:         clewVersion,
+		ClewfilePath:        clewfilePath,
+		ClewfileFingerprint: hashBytes(content),
+		StateFingerprint:    stateFP,
+		Diff:                d,
+	}, nil
+}
+
+// fingerprintMarketplace and fingerprintPlugin hold the parts of a
+// marketplace and plugin the diff compares. Timestamps, versions and commit
+// SHAs change whenever Claude refreshes a marketplace or updates a plugin
+// and would make plans go stale without changing what they do.
+type fingerprintMarketplace struct {
+	Repo            string
+	Ref             string
+	InstallLocation string
+}
+
+type fingerprintPlugin struct {
+	Enabled       bool
+	EnabledSource string
+	IsLocal       bool
+	InstallPath   string
+	Installs      []fingerprintInstall
+}
+
+type fingerprintInstall struct {
+	Scope       string
+	ProjectPath string
+	InstallPath string
+}
+
+// Fingerprint returns a stable SHA256 fingerprint of the parts of a state
+// the diff uses. Map keys are sorted by encoding/json, so equal states hash
+// equally.
+func Fingerprint(s *state.State) (string, error) {
+	marketplaces := make(map[string]fingerprintMarketplace, len(s.Marketplaces))
+	for alias, m := range s.Marketplaces {
+		marketplaces[alias] = fingerprintMarketplace{Repo: m.Repo, Ref: m.Ref, InstallLocation: m.InstallLocation}
+	}
+	plugins := make(map[string]fingerprintPlugin, len(s.Plugins))
+	for name, p := range s.Plugins {
+		fp := fingerprintPlugin{Enabled: p.Enabled, EnabledSource: p.EnabledSource, IsLocal: p.IsLocal, InstallPath: p.InstallPath}
+		if len(p.Installs) == 0 {
+			fp.Installs = []fingerprintInstall{{Scope: p.Scope}}
+		}
+		for _, i := range p.Installs {
+			fp.Installs = append(fp.Installs, fingerprintInstall{Scope: i.Scope, ProjectPath: i.ProjectPath, InstallPath: i.InstallPath})
+		}
+		plugins[name] = fp
+	}
+
+	data, err := json.Marshal(struct {
+		Marketplaces map[string]fingerprintMarketplace
+		Plugins      map[string]fingerprintPlugin
+		Managed      *state.ManagedPolicy
+		Keybindings  state.Keybindings
+	}{marketplaces, plugins, s.Managed, s.Keybindings})
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint state: %w", err)
+	}
+	return hashBytes(data), nil
+}
+
+// hashBytes returns the hex SHA256 of data.
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify returns an error if the current state differs from the state the
+// plan was computed against.
+func (p *Plan) Verify(current *state.State) error {
+	fp, err := Fingerprint(current)
+	if err != nil {
+		return err
+	}
+	if fp != p.StateFingerprint {
+		return fmt.Errorf("system state changed since the plan was created (%s); run 'clew plan' again",
+			p.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+// ClewfileChanged reports whether the Clewfile the plan was computed from
+// has been modified or removed since.
+func (p *Plan) ClewfileChanged() bool {
+	content, err := os.ReadFile(p.ClewfilePath)
+	if err != nil {
+		return true
+	}
+	return hashBytes(content) != p.ClewfileFingerprint
+}
+
+// Save writes the plan to path as indented JSON.
+func (p *Plan) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+	return nil
+}
+
+// Load reads a plan file.
+func Load(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var p Plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %w", err)
+	}
+
+	if p.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported plan format version %d (expected %d)", p.FormatVersion, FormatVersion)
+	}
+	if p.Diff == nil {
+		return nil, fmt.Errorf("plan file contains no diff")
+	}
+
+	return &p, nil
+}
@@ -0,0 +1,159 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func testState() *state.State {
+	return &state.State{
+		Marketplaces: map[string]state.MarketplaceState{
+			"official": {Alias: "official", Repo: "anthropics/claude-plugins-official"},
+		},
+		Plugins: map[string]state.PluginState{
+			"a@official": {Name: "a", Marketplace: "official", Enabled: true},
+			"b@official": {Name: "b", Marketplace: "official", Enabled: false},
+		},
+	}
+}
+
+func writeClewfile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "Clewfile.yaml")
+	if err := os.WriteFile(path, []byte("version: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFingerprintStable(t *testing.T) {
+	fp1, err := Fingerprint(testState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp2, err := Fingerprint(testState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fp1 != fp2 {
+		t.Errorf("fingerprints differ for equal states: %s != %s", fp1, fp2)
+	}
+
+	changed := testState()
+	p := changed.Plugins["b@official"]
+	p.Enabled = true
+	changed.Plugins["b@official"] = p
+
+	fp3, err := Fingerprint(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fp1 == fp3 {
+		t.Error("fingerprint did not change when state changed")
+	}
+
+	// Timestamps and versions the diff does not compare leave it unchanged
+	refreshed := testState()
+	m := refreshed.Marketplaces["official"]
+	m.LastUpdated = "2026-01-02T03:04:05Z"
+	refreshed.Marketplaces["official"] = m
+	a := refreshed.Plugins["a@official"]
+	a.LastUpdated, a.Version, a.GitCommitSha = "2026-01-02T03:04:05Z", "1.1.0", "abc123"
+	refreshed.Plugins["a@official"] = a
+
+	fp4, err := Fingerprint(refreshed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fp1 != fp4 {
+		t.Error("fingerprint changed when only timestamps and versions changed")
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	clewfilePath := writeClewfile(t)
+	d := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "new", Action: diff.ActionAdd, Desired: &config.Marketplace{Repo: "owner/new"}},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "c@new", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "c@new"}},
+		},
+	}
+
+	p, err := New(d, testState(), clewfilePath, "1.0.0")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	if err := p.Save(planPath); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(planPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if loaded.StateFingerprint != p.StateFingerprint {
+		t.Error("state fingerprint not preserved")
+	}
+	if len(loaded.Diff.Marketplaces) != 1 || loaded.Diff.Marketplaces[0].Desired.Repo != "owner/new" {
+		t.Errorf("marketplace diff not preserved: %+v", loaded.Diff.Marketplaces)
+	}
+	if len(loaded.Diff.Plugins) != 1 || loaded.Diff.Plugins[0].Action != diff.ActionAdd {
+		t.Errorf("plugin diff not preserved: %+v", loaded.Diff.Plugins)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	p, err := New(&diff.Result{}, testState(), writeClewfile(t), "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Verify(testState()); err != nil {
+		t.Errorf("Verify() on unchanged state error = %v", err)
+	}
+
+	changed := testState()
+	delete(changed.Plugins, "a@official")
+	if err := p.Verify(changed); err == nil {
+		t.Error("Verify() expected error for changed state")
+	}
+}
+
+func TestClewfileChanged(t *testing.T) {
+	path := writeClewfile(t)
+	p, err := New(&diff.Result{}, testState(), path, "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.ClewfileChanged() {
+		t.Error("ClewfileChanged() = true for unmodified file")
+	}
+
+	if err := os.WriteFile(path, []byte("version: 1\nplugins: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !p.ClewfileChanged() {
+		t.Error("ClewfileChanged() = false after modification")
+	}
+}
+
+func TestLoadRejectsUnknownFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(`{"format_version": 99, "diff": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() expected error for unsupported format version")
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package preflight
+
+// clew only ships for darwin and linux; elsewhere the disk check is skipped.
+
+func freeSpace(path string) (uint64, bool) { return 0, false }
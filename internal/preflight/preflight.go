@@ -0,0 +1,189 @@
+// Package preflight checks that sync can succeed before it changes
+// anything: the claude CLI is present, new enough and supports the needed
+// commands, marketplace hosts are reachable, and there is room on disk for
+// new clones.
+package preflight
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adamancini/clew/internal/claudecli"
+	"github.com/adamancini/clew/internal/update"
+)
+
+// MinFreeDisk is the free space sync needs for marketplace clones and
+// plugin installs.
+const MinFreeDisk = 100 << 20
+
+// dialTimeout bounds each reachability check.
+const dialTimeout = 5 * time.Second
+
+// Options describes what the pending sync needs.
+type Options struct {
+	ClaudePath    string                  // Resolved claude binary, empty if not on PATH
+	ClaudeVersion string                  // Output of `claude --version`
+	Requires      string                  // Clewfile requires.claude constraint, e.g. ">=1.0.30"
+	Capabilities  *claudecli.Capabilities // Detected claude capabilities, nil if unknown
+	Needs         []string                // Capabilities the pending operations need (see claudecli.Capabilities.Missing)
+	Hosts         []string                // Hosts marketplaces will be cloned from
+	DiskPath      string                  // Directory that receives clones and installs
+}
+
+// Checker runs the preflight checks. Its network and disk probes can be
+// replaced in tests.
+type Checker struct {
+	dial      func(addr string) error
+	freeSpace func(path string) (uint64, bool)
+}
+
+// New creates a Checker that probes the real network and filesystem.
+func New() *Checker {
+	return &Checker{dial: dialHost, freeSpace: freeSpace}
+}
+
+// Run performs every check and returns one error listing all failures, each
+// with what to do about it.
+func (c *Checker) Run(opts Options) error {
+	var problems []string
+
+	if opts.ClaudePath == "" {
+		problems = append(problems, "claude CLI not found on PATH (install Claude Code, or add its directory to PATH)")
+	} else if opts.Requires != "" {
+		if problem := checkVersion(opts.ClaudeVersion, opts.Requires); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	if opts.ClaudePath != "" && opts.Capabilities != nil {
+		for _, need := range opts.Needs {
+			if missing := opts.Capabilities.Missing(need); missing != "" {
+				problems = append(problems, missing)
+			}
+		}
+	}
+
+	for _, host := range opts.Hosts {
+		if err := c.dial(host); err != nil {
+			problems = append(problems, fmt.Sprintf("cannot reach %s: %v (check your network or proxy settings)", host, err))
+		}
+	}
+
+	if opts.DiskPath != "" {
+		if free, ok := c.freeSpace(opts.DiskPath); ok && free < MinFreeDisk {
+			problems = append(problems, fmt.Sprintf("only %d MiB free on the disk holding %s, need %d MiB (free up space)", free>>20, opts.DiskPath, MinFreeDisk>>20))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("preflight failed:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// claudeVersionPattern finds the version in `claude --version` output,
+// e.g. "1.0.30 (Claude Code)".
+var claudeVersionPattern = regexp.MustCompile(`\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?`)
+
+// constraintPattern splits a constraint into operator and version.
+var constraintPattern = regexp.MustCompile(`^\s*(>=|<=|>|<|=)?\s*(v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?)\s*$`)
+
+// checkVersion returns a problem if the claude version output does not
+// satisfy the constraint, or "" if it does.
+func checkVersion(output, constraint string) string {
+	m := constraintPattern.FindStringSubmatch(constraint)
+	if m == nil {
+		return fmt.Sprintf("invalid requires.claude constraint %q", constraint)
+	}
+	op, want := m[1], m[2]
+	if op == "" {
+		op = ">="
+	}
+
+	have := claudeVersionPattern.FindString(output)
+	if have == "" {
+		return fmt.Sprintf("cannot determine the claude version to check requires.claude %q (is 'claude --version' working?)", constraint)
+	}
+	cmp, err := update.CompareVersions(have, want)
+	if err != nil {
+		return err.Error()
+	}
+
+	ok := map[string]bool{
+		">=": cmp >= 0,
+		">":  cmp > 0,
+		"<=": cmp <= 0,
+		"<":  cmp < 0,
+		"=":  cmp == 0,
+	}[op]
+	if !ok {
+		return fmt.Sprintf("claude %s does not satisfy requires.claude %q (update with 'claude update')", have, constraint)
+	}
+	return ""
+}
+
+// MarketplaceHosts returns the distinct hosts that marketplace repos are
+// cloned from. "owner/repo" shorthand means GitHub.
+func MarketplaceHosts(repos []string) []string {
+	seen := make(map[string]bool)
+	for _, repo := range repos {
+		if host := repoHost(repo); host != "" {
+			seen[host] = true
+		}
+	}
+	hosts := make([]string, 0, len(seen))
+	for host := range seen {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// repoHost returns the host:port a repo is fetched from, or "" for local paths.
+func repoHost(repo string) string {
+	switch {
+	case strings.HasPrefix(repo, "/"), strings.HasPrefix(repo, "."), strings.HasPrefix(repo, "~"):
+		return ""
+	case strings.Contains(repo, "://"):
+		u, err := url.Parse(repo)
+		if err != nil || u.Hostname() == "" || u.Scheme == "file" {
+			return ""
+		}
+		port := u.Port()
+		if port == "" {
+			port = map[string]string{"ssh": "22", "git": "9418", "http": "80"}[u.Scheme]
+		}
+		if port == "" {
+			port = "443"
+		}
+		return net.JoinHostPort(u.Hostname(), port)
+	case strings.Contains(repo, "@") && strings.Contains(repo, ":"):
+		// scp-like syntax: git@host:owner/repo.git
+		host := repo[strings.Index(repo, "@")+1 : strings.Index(repo, ":")]
+		return net.JoinHostPort(host, "22")
+	default:
+		return "github.com:443"
+	}
+}
+
+// dialHost opens and closes a TCP connection to addr, through the HTTPS
+// proxy if one is configured, since git would use it too.
+func dialHost(addr string) error {
+	req := &http.Request{URL: &url.URL{Scheme: "https", Host: addr}}
+	if proxy, err := http.ProxyFromEnvironment(req); err == nil && proxy != nil {
+		addr = proxy.Host
+		if proxy.Port() == "" {
+			addr = net.JoinHostPort(proxy.Hostname(), "80")
+		}
+	}
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,90 @@
+package preflight
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/claudecli"
+)
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		output     string
+		constraint string
+		want       string
+	}{
+		{"1.0.30 (Claude Code)", ">=1.0.30", ""},
+		{"1.0.31 (Claude Code)", ">= 1.0.30", ""},
+		{"1.0.29 (Claude Code)", ">=1.0.30", `claude 1.0.29 does not satisfy requires.claude ">=1.0.30"`},
+		{"1.0.29", "1.0.30", "does not satisfy"},
+		{"2.0.0", "<2.0.0", "does not satisfy"},
+		{"1.0.30", "=1.0.30", ""},
+		{"", ">=1.0.30", "cannot determine the claude version"},
+		{"1.0.30", "~1.0", "invalid requires.claude constraint"},
+	}
+	for _, tt := range tests {
+		got := checkVersion(tt.output, tt.constraint)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("checkVersion(%q, %q) = %q, want %q", tt.output, tt.constraint, got, tt.want)
+		}
+	}
+}
+
+func TestMarketplaceHosts(t *testing.T) {
+	got := MarketplaceHosts([]string{
+		"anthropics/claude-plugins-official",
+		"obra/superpowers-marketplace",
+		"https://gitlab.com/company/plugins.git",
+		"git@git.example.com:team/plugins.git",
+		"ssh://git@git.example.com:2222/team/plugins.git",
+		"/srv/plugins",
+		"file:///srv/plugins",
+	})
+	want := "git.example.com:22,git.example.com:2222,github.com:443,gitlab.com:443"
+	if strings.Join(got, ",") != want {
+		t.Errorf("MarketplaceHosts() = %v, want %s", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	c := &Checker{
+		dial: func(addr string) error {
+			if addr == "gitlab.com:443" {
+				return errors.New("i/o timeout")
+			}
+			return nil
+		},
+		freeSpace: func(path string) (uint64, bool) { return 10 << 20, true },
+	}
+
+	if err := c.Run(Options{ClaudePath: "/usr/bin/claude", ClaudeVersion: "1.0.30", Requires: ">=1.0.0", Hosts: []string{"github.com:443"}}); err != nil {
+		t.Errorf("Run() = %v, want nil", err)
+	}
+
+	err := c.Run(Options{Hosts: []string{"github.com:443", "gitlab.com:443"}, DiskPath: "/home/me/.claude"})
+	if err == nil {
+		t.Fatal("Run() = nil, want error")
+	}
+	for _, want := range []string{
+		"claude CLI not found on PATH",
+		"cannot reach gitlab.com:443: i/o timeout",
+		"only 10 MiB free on the disk holding /home/me/.claude",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Run() error missing %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "github.com") {
+		t.Errorf("Run() reported a reachable host:\n%v", err)
+	}
+
+	err = c.Run(Options{
+		ClaudePath:   "/usr/bin/claude",
+		Capabilities: &claudecli.Capabilities{Version: "0.9.0", Install: true},
+		Needs:        []string{"marketplace", "install"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not support 'claude plugin marketplace'") || strings.Contains(err.Error(), "plugin install") {
+		t.Errorf("Run() = %v, want only the missing marketplace command", err)
+	}
+}
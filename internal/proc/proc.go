@@ -0,0 +1,27 @@
+// Package proc starts external commands that stop with a context, so an
+// interrupted clew does not leave claude or git processes running.
+package proc
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// WaitDelay is how long a cancelled command may take to exit before it is
+// killed outright.
+const WaitDelay = 5 * time.Second
+
+// Command is exec.CommandContext for a context that may be nil (never
+// cancelled). When ctx can be cancelled, cancelling it terminates the
+// command and the helpers it started, so they do not outlive it (see
+// terminateGroup for how).
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if ctx == nil || ctx.Done() == nil {
+		return exec.Command(name, args...)
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = WaitDelay
+	terminateGroup(cmd)
+	return cmd
+}
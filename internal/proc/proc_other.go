@@ -0,0 +1,9 @@
+//go:build !unix
+
+package proc
+
+import "os/exec"
+
+// clew only ships for darwin and linux; elsewhere cancelling kills the
+// command itself, as exec.CommandContext does.
+func terminateGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,95 @@
+//go:build unix
+
+package proc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// withTerminal makes hasTerminal report has for the test.
+func withTerminal(t *testing.T, has bool) {
+	saved := hasTerminal
+	hasTerminal = func() bool { return has }
+	t.Cleanup(func() { hasTerminal = saved })
+}
+
+func TestCommandCancelKillsGroup(t *testing.T) {
+	withTerminal(t, false)
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The shell starts a child that would outlive it if only the shell died
+	cmd := Command(ctx, "sh", "-c", "sleep 30 & echo $! > "+pidFile+"; wait")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	var pid int
+	for deadline := time.Now().Add(5 * time.Second); pid == 0 && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		data, _ := os.ReadFile(pidFile)
+		pid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+	if pid == 0 {
+		t.Fatal("child did not start")
+	}
+
+	cancel()
+	start := time.Now()
+	if err := cmd.Wait(); err == nil {
+		t.Error("Wait() = nil after cancel, want an error")
+	}
+	if elapsed := time.Since(start); elapsed > WaitDelay {
+		t.Errorf("Wait() took %s after cancel", elapsed)
+	}
+	// The child is gone (or a zombie about to be reaped by init)
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if syscall.Kill(pid, 0) != nil || isZombie(pid) {
+			return
+		}
+	}
+	t.Errorf("child %d still running after cancel", pid)
+}
+
+// isZombie reports whether pid has exited but not been reaped (Linux only;
+// elsewhere it reports false).
+func isZombie(pid int) bool {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(data))
+	return len(fields) > 2 && fields[2] == "Z"
+}
+
+func TestCommandWithoutCancel(t *testing.T) {
+	if cmd := Command(nil, "true"); cmd.SysProcAttr != nil || cmd.Cancel != nil {
+		t.Error("Command(nil) set up group cancellation")
+	}
+	if cmd := Command(context.Background(), "true"); cmd.SysProcAttr != nil {
+		t.Error("Command(Background) set up group cancellation")
+	}
+}
+
+func TestCommandWithTerminal(t *testing.T) {
+	withTerminal(t, true)
+
+	// The command stays in the foreground group so it can prompt
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := Command(ctx, "sleep", "30")
+	if cmd.SysProcAttr != nil {
+		t.Error("Command() started a new process group with a terminal")
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := cmd.Wait(); err == nil {
+		t.Error("Wait() = nil after cancel, want an error")
+	}
+}
@@ -0,0 +1,42 @@
+//go:build unix
+
+package proc
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+)
+
+// terminateGroup makes cancelling cmd stop it and the helpers it started.
+//
+// Without a controlling terminal, cmd runs in a new process group and
+// cancelling sends SIGTERM to the group. With one, cmd stays in clew's
+// foreground group: in a group of its own, a claude or git prompt on the
+// terminal would stop it with SIGTTIN or SIGTTOU. Ctrl-C already reaches
+// the whole foreground group from the terminal, so cancelling only sends
+// SIGTERM to cmd.
+func terminateGroup(cmd *exec.Cmd) {
+	if hasTerminal() {
+		cmd.Cancel = func() error {
+			return cmd.Process.Signal(syscall.SIGTERM)
+		}
+		return
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+}
+
+// hasTerminal reports whether clew has a controlling terminal its commands
+// could prompt on.
+var hasTerminal = sync.OnceValue(func() bool {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	_ = tty.Close()
+	return true
+})
@@ -0,0 +1,350 @@
+// Package projects finds Claude Code project configurations under a
+// directory and reports where they disagree with what is installed.
+package projects
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/adamancini/clew/internal/state"
+)
+
+// Issue kinds.
+const (
+	IssueNotInstalled       = "not-installed"       // Enabled by the project but not installed for it
+	IssueMissingMarketplace = "missing-marketplace" // Enabled plugin whose marketplace is not installed
+	IssueUnreadable         = "unreadable"          // Project configuration could not be read
+	IssueMCPCommand         = "mcp-command"         // stdio MCP server whose command cannot be found
+)
+
+// Files that make a directory a Claude project.
+const (
+	settingsDir = ".claude"
+	mcpFile     = ".mcp.json"
+)
+
+// Report is the result of scanning a directory tree.
+type Report struct {
+	Root     string    `json:"root" yaml:"root"`
+	Projects []Project `json:"projects" yaml:"projects"`
+	Stale    []Stale   `json:"stale,omitempty" yaml:"stale,omitempty"`
+}
+
+// Project is a directory with Claude project configuration.
+type Project struct {
+	Path       string   `json:"path" yaml:"path"`
+	Plugins    []Plugin `json:"plugins" yaml:"plugins"`
+	MCPServers []string `json:"mcp_servers,omitempty" yaml:"mcp_servers,omitempty"` // Servers declared in .mcp.json
+	Issues     []Issue  `json:"issues,omitempty" yaml:"issues,omitempty"`
+}
+
+// Plugin is a plugin a project installs or configures.
+type Plugin struct {
+	Name          string `json:"name" yaml:"name"`
+	Enabled       bool   `json:"enabled" yaml:"enabled"`
+	EnabledSource string `json:"enabled_source,omitempty" yaml:"enabled_source,omitempty"` // Project settings file, empty if defaulted
+	Scope         string `json:"scope,omitempty" yaml:"scope,omitempty"`                   // Scope of the install that applies, empty if not installed
+}
+
+// Issue is a disagreement between a project's configuration and what is
+// installed.
+type Issue struct {
+	Kind    string `json:"kind" yaml:"kind"`
+	Plugin  string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+	Server  string `json:"server,omitempty" yaml:"server,omitempty"` // MCP server, for mcp-command issues
+	Message string `json:"message" yaml:"message"`
+}
+
+// Stale is a project-scope install whose project directory is gone.
+type Stale struct {
+	Plugin      string `json:"plugin" yaml:"plugin"`
+	ProjectPath string `json:"project_path" yaml:"project_path"`
+}
+
+// Scan walks root for Claude projects and compares each with the installed
+// state. Hidden directories, node_modules and vendor are not descended into.
+func Scan(root string, current *state.State) (*Report, error) {
+	home, _ := os.UserHomeDir()
+	return scan(root, current, home)
+}
+
+func scan(root string, current *state.State, home string) (*Report, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+	if info, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("cannot scan %s: %w", root, err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("cannot scan %s: not a directory", root)
+	}
+
+	// Projects with recorded installs count even without settings
+	installed := projectInstalls(current)
+	found := make(map[string]bool)
+	report := &Report{Root: root}
+	for path, names := range installed {
+		if !within(root, path) {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			for _, name := range names {
+				report.Stale = append(report.Stale, Stale{Plugin: name, ProjectPath: path})
+			}
+			continue
+		}
+		found[path] = true
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped, not fatal
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		// ~/.claude holds user settings, not a project's
+		if path != filepath.Clean(home) && isProject(path) {
+			found[path] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	for path := range found {
+		p, err := readProject(path, current, installed[path])
+		if err != nil {
+			// One broken project should not hide the rest
+			p.Issues = append(p.Issues, Issue{Kind: IssueUnreadable, Message: err.Error()})
+		}
+		report.Projects = append(report.Projects, p)
+	}
+	sort.Slice(report.Projects, func(i, j int) bool { return report.Projects[i].Path < report.Projects[j].Path })
+	sort.Slice(report.Stale, func(i, j int) bool {
+		if report.Stale[i].ProjectPath != report.Stale[j].ProjectPath {
+			return report.Stale[i].ProjectPath < report.Stale[j].ProjectPath
+		}
+		return report.Stale[i].Plugin < report.Stale[j].Plugin
+	})
+	return report, nil
+}
+
+// skipDir reports whether a directory is never searched for projects.
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor"
+}
+
+// isProject reports whether dir has Claude project configuration.
+func isProject(dir string) bool {
+	for _, path := range []string{
+		filepath.Join(dir, settingsDir, state.SettingsFile),
+		filepath.Join(dir, settingsDir, state.SettingsLocalFile),
+		filepath.Join(dir, mcpFile),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether path is root or below it.
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// projectInstalls maps each project path to the plugins installed for it.
+func projectInstalls(current *state.State) map[string][]string {
+	installs := make(map[string][]string)
+	for name, p := range current.Plugins {
+		for _, i := range p.Installs {
+			if i.ProjectPath != "" {
+				path := filepath.Clean(i.ProjectPath)
+				installs[path] = append(installs[path], name)
+			}
+		}
+	}
+	for _, names := range installs {
+		sort.Strings(names)
+	}
+	return installs
+}
+
+// readProject reads a project's settings and checks them against current.
+func readProject(dir string, current *state.State, installedFor []string) (Project, error) {
+	project := Project{Path: dir, Plugins: []Plugin{}}
+
+	// settings.local.json overrides settings.json, as for user settings
+	enabled := make(map[string]bool)
+	source := make(map[string]string)
+	for _, name := range []string{state.SettingsFile, state.SettingsLocalFile} {
+		var settings struct {
+			EnabledPlugins map[string]bool `json:"enabledPlugins"`
+		}
+		path := filepath.Join(dir, settingsDir, name)
+		if ok, err := readJSON(path, &settings); err != nil {
+			return project, err
+		} else if !ok {
+			continue
+		}
+		for plugin, on := range settings.EnabledPlugins {
+			enabled[plugin] = on
+			source[plugin] = filepath.Join(settingsDir, name)
+		}
+	}
+
+	var mcp struct {
+		MCPServers map[string]mcpServer `json:"mcpServers"`
+	}
+	if _, err := readJSON(filepath.Join(dir, mcpFile), &mcp); err != nil {
+		return project, err
+	}
+	for name := range mcp.MCPServers {
+		project.MCPServers = append(project.MCPServers, name)
+	}
+	sort.Strings(project.MCPServers)
+	for _, name := range project.MCPServers {
+		if problem := checkMCPCommand(dir, mcp.MCPServers[name]); problem != "" {
+			project.Issues = append(project.Issues, Issue{Kind: IssueMCPCommand, Server: name, Message: problem})
+		}
+	}
+
+	names := make(map[string]bool)
+	for name := range enabled {
+		names[name] = true
+	}
+	scopes := make(map[string]string)
+	for _, name := range installedFor {
+		names[name] = true
+		for _, i := range current.Plugins[name].Installs {
+			if i.ProjectPath != "" && filepath.Clean(i.ProjectPath) == dir {
+				scopes[name] = i.Scope
+				break
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		p := Plugin{Name: name, Enabled: true, EnabledSource: source[name], Scope: scopes[name]}
+		if on, ok := enabled[name]; ok {
+			p.Enabled = on
+		}
+		if p.Scope == "" && userInstalled(current, name) {
+			p.Scope = "user"
+		}
+		project.Plugins = append(project.Plugins, p)
+
+		if !p.Enabled || p.Scope != "" {
+			continue
+		}
+		if _, alias, ok := strings.Cut(name, "@"); ok {
+			if _, exists := current.Marketplaces[alias]; !exists {
+				project.Issues = append(project.Issues, Issue{
+					Kind:    IssueMissingMarketplace,
+					Plugin:  name,
+					Message: fmt.Sprintf("enabled in %s but marketplace %s is not installed", p.EnabledSource, alias),
+				})
+				continue
+			}
+		}
+		project.Issues = append(project.Issues, Issue{
+			Kind:    IssueNotInstalled,
+			Plugin:  name,
+			Message: fmt.Sprintf("enabled in %s but not installed; run 'claude plugin install %s --scope project' in %s", p.EnabledSource, name, dir),
+		})
+	}
+	return project, nil
+}
+
+// mcpServer is the part of an .mcp.json server entry needed to check it.
+type mcpServer struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+}
+
+// lookPath resolves a bare command on PATH; replaced in tests.
+var lookPath = exec.LookPath
+
+// checkMCPCommand returns why a stdio server's command would fail to
+// start, or "" if it resolves. Claude starts the server from the project
+// directory, so relative paths are resolved there. Commands that use
+// ${VAR} expansion are not checked, since the variable may only be set
+// when Claude runs.
+func checkMCPCommand(dir string, server mcpServer) string {
+	if server.Type != "" && server.Type != "stdio" {
+		return ""
+	}
+	command := server.Command
+	if command == "" {
+		return "stdio server has no command"
+	}
+	if strings.Contains(command, "${") {
+		return ""
+	}
+
+	if !strings.ContainsRune(command, filepath.Separator) && !strings.Contains(command, "/") {
+		if _, err := lookPath(command); err != nil {
+			return fmt.Sprintf("command %q not found on PATH", command)
+		}
+		return ""
+	}
+
+	path := command
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("command %s does not exist", command)
+	case info.IsDir():
+		return fmt.Sprintf("command %s is a directory", command)
+	case info.Mode()&0111 == 0:
+		return fmt.Sprintf("command %s is not executable", command)
+	}
+	return ""
+}
+
+// userInstalled reports whether a plugin is installed at user scope, which
+// applies to every project.
+func userInstalled(current *state.State, name string) bool {
+	p, ok := current.Plugins[name]
+	return ok && p.InstalledAt("user")
+}
+
+// readJSON decodes a JSON file into v, reporting false if it does not exist.
+func readJSON(path string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return true, nil
+}
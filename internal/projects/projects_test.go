@@ -0,0 +1,162 @@
+package projects
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/adamancini/clew/internal/state"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	api := filepath.Join(root, "api")
+	web := filepath.Join(root, "web")
+	bare := filepath.Join(root, "bare")
+
+	writeFile(t, filepath.Join(api, ".claude", "settings.json"),
+		`{"enabledPlugins": {"linter@official": true, "docs@official": true, "ghost@unknown": true}}`)
+	writeFile(t, filepath.Join(api, ".claude", "settings.local.json"),
+		`{"enabledPlugins": {"docs@official": false}}`)
+	writeFile(t, filepath.Join(web, ".mcp.json"), `{"mcpServers": {"postgres": {"type": "http", "url": "http://localhost:8080/mcp"}, "browser": {"command": "${BROWSER_MCP}"}}}`)
+	writeFile(t, filepath.Join(bare, "README.md"), "not a project")
+	// Home's .claude holds user settings, and dependencies are not searched
+	writeFile(t, filepath.Join(home, ".claude", "settings.json"), `{"enabledPlugins": {}}`)
+	writeFile(t, filepath.Join(web, "node_modules", "pkg", ".mcp.json"), `{}`)
+	writeFile(t, filepath.Join(root, ".cache", "x", ".mcp.json"), `{}`)
+
+	current := &state.State{
+		Marketplaces: map[string]state.MarketplaceState{
+			"official": {Alias: "official", Repo: "anthropics/claude-plugins"},
+		},
+		Plugins: map[string]state.PluginState{
+			"context7@official": {
+				Name: "context7", Marketplace: "official", Scope: "user",
+				Installs: []state.PluginInstall{{Scope: "user"}},
+			},
+			"tester@official": {
+				Name: "tester", Marketplace: "official", Scope: "project",
+				Installs: []state.PluginInstall{
+					{Scope: "project", ProjectPath: web},
+					{Scope: "project", ProjectPath: filepath.Join(root, "deleted")},
+				},
+			},
+		},
+	}
+
+	report, err := scan(root, current, home)
+	if err != nil {
+		t.Fatalf("scan() error = %v", err)
+	}
+
+	if len(report.Projects) != 2 || report.Projects[0].Path != api || report.Projects[1].Path != web {
+		t.Fatalf("Projects = %+v, want api and web", report.Projects)
+	}
+
+	apiProject := report.Projects[0]
+	if len(apiProject.Plugins) != 3 {
+		t.Fatalf("api plugins = %+v", apiProject.Plugins)
+	}
+	docs := apiProject.Plugins[0]
+	if docs.Name != "docs@official" || docs.Enabled || docs.EnabledSource != filepath.Join(".claude", "settings.local.json") {
+		t.Errorf("docs = %+v, want disabled by settings.local.json", docs)
+	}
+	wantIssues := map[string]string{"linter@official": IssueNotInstalled, "ghost@unknown": IssueMissingMarketplace}
+	if len(apiProject.Issues) != len(wantIssues) {
+		t.Errorf("api issues = %+v", apiProject.Issues)
+	}
+	for _, issue := range apiProject.Issues {
+		if issue.Kind != wantIssues[issue.Plugin] {
+			t.Errorf("issue for %s = %s, want %s", issue.Plugin, issue.Kind, wantIssues[issue.Plugin])
+		}
+	}
+
+	webProject := report.Projects[1]
+	if len(webProject.MCPServers) != 2 || webProject.MCPServers[0] != "browser" {
+		t.Errorf("web MCP servers = %v", webProject.MCPServers)
+	}
+	if len(webProject.Plugins) != 1 || webProject.Plugins[0].Scope != "project" || !webProject.Plugins[0].Enabled {
+		t.Errorf("web plugins = %+v, want tester installed at project scope", webProject.Plugins)
+	}
+	if len(webProject.Issues) != 0 {
+		t.Errorf("web issues = %+v, want none", webProject.Issues)
+	}
+
+	if len(report.Stale) != 1 || report.Stale[0].Plugin != "tester@official" {
+		t.Errorf("Stale = %+v, want tester's install in the deleted project", report.Stale)
+	}
+}
+
+func TestCheckMCPCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bin", "server"), "#!/bin/sh\n")
+	if err := os.Chmod(filepath.Join(dir, "bin", "server"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "bin", "notes.txt"), "")
+
+	defer func(orig func(string) (string, error)) { lookPath = orig }(lookPath)
+	lookPath = func(file string) (string, error) {
+		if file == "npx" {
+			return "/usr/bin/npx", nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	tests := []struct {
+		name   string
+		server mcpServer
+		want   string
+	}{
+		{"on PATH", mcpServer{Command: "npx"}, ""},
+		{"explicit stdio", mcpServer{Type: "stdio", Command: "npx"}, ""},
+		{"not on PATH", mcpServer{Command: "uvx"}, `command "uvx" not found on PATH`},
+		{"relative to project", mcpServer{Command: "./bin/server"}, ""},
+		{"absolute", mcpServer{Command: filepath.Join(dir, "bin", "server")}, ""},
+		{"missing file", mcpServer{Command: "./bin/missing"}, "command ./bin/missing does not exist"},
+		{"not executable", mcpServer{Command: "bin/notes.txt"}, "command bin/notes.txt is not executable"},
+		{"no command", mcpServer{}, "stdio server has no command"},
+		{"env expansion", mcpServer{Command: "${HOME}/bin/server"}, ""},
+		{"remote server", mcpServer{Type: "http"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkMCPCommand(dir, tt.server); got != tt.want {
+				t.Errorf("checkMCPCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanUnreadableProject(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "broken", ".claude", "settings.json"), `{not json`)
+
+	report, err := scan(root, &state.State{}, "")
+	if err != nil {
+		t.Fatalf("scan() error = %v", err)
+	}
+	if len(report.Projects) != 1 || len(report.Projects[0].Issues) != 1 || report.Projects[0].Issues[0].Kind != IssueUnreadable {
+		t.Errorf("Projects = %+v, want one unreadable project", report.Projects)
+	}
+}
+
+func TestScanNotADirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	writeFile(t, path, "")
+	if _, err := scan(path, &state.State{}, ""); err == nil {
+		t.Error("scan() of a file should fail")
+	}
+}
@@ -0,0 +1,173 @@
+// Package recommend suggests marketplace plugins that suit the current
+// project and pair with the plugins already installed.
+package recommend
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/adamancini/clew/internal/catalog"
+)
+
+// markers maps files found in a project root to the language or tool they
+// indicate. Patterns are matched with filepath.Glob.
+var markers = []struct {
+	pattern  string
+	language string
+}{
+	{"go.mod", "go"},
+	{"package.json", "javascript"},
+	{"tsconfig.json", "typescript"},
+	{"pyproject.toml", "python"},
+	{"requirements.txt", "python"},
+	{"setup.py", "python"},
+	{"Cargo.toml", "rust"},
+	{"Gemfile", "ruby"},
+	{"pom.xml", "java"},
+	{"build.gradle", "java"},
+	{"build.gradle.kts", "kotlin"},
+	{"composer.json", "php"},
+	{"mix.exs", "elixir"},
+	{"Package.swift", "swift"},
+	{"*.csproj", "csharp"},
+	{"Dockerfile", "docker"},
+	{"Chart.yaml", "kubernetes"},
+	{"*.tf", "terraform"},
+}
+
+// aliases are additional words that identify a language in plugin listings.
+var aliases = map[string][]string{
+	"go":         {"golang"},
+	"javascript": {"js", "node", "nodejs"},
+	"typescript": {"ts"},
+	"python":     {"py"},
+	"csharp":     {"dotnet", "c#"},
+	"kubernetes": {"k8s", "helm"},
+	"docker":     {"container", "containers"},
+}
+
+// DetectLanguages returns the languages and tools used by the project in
+// dir, in marker order and without duplicates.
+func DetectLanguages(dir string) []string {
+	var languages []string
+	seen := make(map[string]bool)
+	for _, m := range markers {
+		if seen[m.language] {
+			continue
+		}
+		matches, _ := filepath.Glob(filepath.Join(dir, m.pattern))
+		if len(matches) == 0 {
+			continue
+		}
+		seen[m.language] = true
+		languages = append(languages, m.language)
+	}
+	return languages
+}
+
+// Recommendation is a suggested plugin and why it was suggested.
+type Recommendation struct {
+	catalog.Entry `yaml:",inline"`
+
+	Score   int      `json:"score" yaml:"score"`
+	Reasons []string `json:"reasons" yaml:"reasons"`
+}
+
+// Scores for each kind of match. A listing that names the language in its
+// name, category or keywords is a stronger signal than a passing mention in
+// its description; sharing a category with an installed plugin is weakest.
+const (
+	scoreLanguageTag         = 3
+	scoreLanguageDescription = 1
+	scorePairedCategory      = 1
+)
+
+// Recommend ranks catalog entries by how well they fit the project's
+// languages and the plugins in have (full plugin@marketplace names that are
+// installed or already declared). Entries in have are never recommended.
+// Results are sorted by score, then name.
+func Recommend(entries []catalog.Entry, languages []string, have map[string]bool) []Recommendation {
+	// Categories of plugins the user already has, to suggest companions
+	pairedWith := make(map[string]string)
+	for _, e := range entries {
+		if have[e.FullName()] && e.Category != "" {
+			if _, ok := pairedWith[strings.ToLower(e.Category)]; !ok {
+				pairedWith[strings.ToLower(e.Category)] = e.Name
+			}
+		}
+	}
+
+	var recs []Recommendation
+	for _, e := range entries {
+		if have[e.FullName()] {
+			continue
+		}
+
+		rec := Recommendation{Entry: e}
+		tags := tagWords(e)
+		description := words(e.Description)
+		for _, lang := range languages {
+			switch {
+			case matchesLanguage(tags, lang):
+				rec.Score += scoreLanguageTag
+				rec.Reasons = append(rec.Reasons, lang+" project")
+			case matchesLanguage(description, lang):
+				rec.Score += scoreLanguageDescription
+				rec.Reasons = append(rec.Reasons, lang+" project")
+			}
+		}
+		if name, ok := pairedWith[strings.ToLower(e.Category)]; ok {
+			rec.Score += scorePairedCategory
+			rec.Reasons = append(rec.Reasons, "pairs with "+name)
+		}
+
+		if rec.Score > 0 {
+			recs = append(recs, rec)
+		}
+	}
+
+	sort.SliceStable(recs, func(i, j int) bool {
+		if recs[i].Score != recs[j].Score {
+			return recs[i].Score > recs[j].Score
+		}
+		return recs[i].FullName() < recs[j].FullName()
+	})
+	return recs
+}
+
+// tagWords returns the words of an entry's name, category and keywords.
+func tagWords(e catalog.Entry) map[string]bool {
+	tags := words(e.Name + " " + e.Category)
+	for _, k := range e.Keywords {
+		for w := range words(k) {
+			tags[w] = true
+		}
+		tags[strings.ToLower(k)] = true
+	}
+	return tags
+}
+
+// words splits s into a set of lowercase words.
+func words(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '#')
+	}) {
+		set[w] = true
+	}
+	return set
+}
+
+// matchesLanguage reports whether a word set names lang or one of its aliases.
+func matchesLanguage(set map[string]bool, lang string) bool {
+	if set[lang] {
+		return true
+	}
+	for _, alias := range aliases[lang] {
+		if set[alias] {
+			return true
+		}
+	}
+	return false
+}
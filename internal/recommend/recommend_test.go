@@ -0,0 +1,60 @@
+package recommend
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/adamancini/clew/internal/catalog"
+)
+
+func TestDetectLanguages(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"go.mod", "requirements.txt", "pyproject.toml", "main.tf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := DetectLanguages(dir)
+	want := []string{"go", "python", "terraform"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectLanguages() = %v, want %v", got, want)
+	}
+
+	if got := DetectLanguages(t.TempDir()); len(got) != 0 {
+		t.Errorf("DetectLanguages(empty) = %v, want none", got)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	entries := []catalog.Entry{
+		{Name: "go-tools", Marketplace: "official", Keywords: []string{"golang"}},
+		{Name: "gopher-docs", Marketplace: "official", Description: "Docs for Go packages"},
+		{Name: "google-search", Marketplace: "official", Description: "Search the web"},
+		{Name: "code-review", Marketplace: "official", Category: "development"},
+		{Name: "pr-summary", Marketplace: "official", Category: "development"},
+		{Name: "py-lint", Marketplace: "official", Category: "development", Keywords: []string{"python"}},
+	}
+	have := map[string]bool{"code-review@official": true}
+
+	recs := Recommend(entries, []string{"go"}, have)
+
+	var names []string
+	for _, r := range recs {
+		names = append(names, r.FullName())
+	}
+	// google-search must not match "go"; code-review is already installed
+	want := []string{"go-tools@official", "gopher-docs@official", "pr-summary@official", "py-lint@official"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("Recommend() = %v, want %v", names, want)
+	}
+
+	if r := recs[0]; r.Score != scoreLanguageTag || !reflect.DeepEqual(r.Reasons, []string{"go project"}) {
+		t.Errorf("go-tools = %+v", r)
+	}
+	if r := recs[2]; !reflect.DeepEqual(r.Reasons, []string{"pairs with code-review"}) {
+		t.Errorf("pr-summary reasons = %v", r.Reasons)
+	}
+}
@@ -0,0 +1,493 @@
+// Package repair detects and repairs corrupted Claude Code state files.
+//
+// Claude keeps plugin state in three JSON files. A crash mid-write or a
+// manual edit can leave trailing garbage, duplicate keys, or entries that no
+// longer match the expected schema, after which Claude (and clew) silently
+// ignore the whole file. Repair salvages whatever still parses, drops
+// entries that do not fit the schema, and reconstructs unreadable files from
+// the plugins directory and the Clewfile.
+package repair
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/i18n"
+)
+
+// Kind identifies which state file is being inspected.
+type Kind string
+
+const (
+	KindMarketplaces Kind = "known_marketplaces.json"
+	KindPlugins      Kind = "installed_plugins.json"
+	KindSettings     Kind = "settings.json"
+)
+
+// installedPluginsVersion is the installed_plugins.json schema version
+// written when the file has to be reconstructed.
+const installedPluginsVersion = 2
+
+// FileReport describes the problems found in one state file and what was done.
+type FileReport struct {
+	Path          string   `json:"path" yaml:"path"`
+	Issues        []string `json:"issues" yaml:"issues"`
+	Repaired      bool     `json:"repaired" yaml:"repaired"`
+	Reconstructed bool     `json:"reconstructed,omitempty" yaml:"reconstructed,omitempty"` // File was unreadable and rebuilt from disk/Clewfile
+	BackupPath    string   `json:"backup_path,omitempty" yaml:"backup_path,omitempty"`
+}
+
+// Report is the result of checking or repairing all state files.
+type Report struct {
+	Files []FileReport `json:"files" yaml:"files"`
+}
+
+// HasIssues reports whether any file needs repair.
+func (r *Report) HasIssues() bool {
+	for _, f := range r.Files {
+		if len(f.Issues) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Repairer checks and repairs the state files under a Claude directory.
+type Repairer struct {
+	claudeDir string
+	clewfile  *config.Clewfile // Optional; used to reconstruct unreadable files
+	now       func() time.Time
+}
+
+// NewRepairer creates a Repairer. clewfile may be nil.
+func NewRepairer(claudeDir string, clewfile *config.Clewfile) *Repairer {
+	return &Repairer{claudeDir: claudeDir, clewfile: clewfile, now: time.Now}
+}
+
+// paths returns the location of each state file.
+func (r *Repairer) paths() map[Kind]string {
+	return map[Kind]string{
+		KindMarketplaces: filepath.Join(r.claudeDir, "plugins", string(KindMarketplaces)),
+		KindPlugins:      filepath.Join(r.claudeDir, "plugins", string(KindPlugins)),
+		KindSettings:     filepath.Join(r.claudeDir, string(KindSettings)),
+	}
+}
+
+// kinds is the order files are checked and reported in.
+var kinds = []Kind{KindMarketplaces, KindPlugins, KindSettings}
+
+// Check inspects every state file without modifying anything.
+// Missing files are not reported.
+func (r *Repairer) Check() (*Report, error) {
+	return r.run(false)
+}
+
+// Repair inspects every state file and rewrites those with issues, backing
+// up the original next to it first.
+func (r *Repairer) Repair() (*Report, error) {
+	return r.run(true)
+}
+
+func (r *Repairer) run(write bool) (*Report, error) {
+	report := &Report{Files: []FileReport{}}
+	paths := r.paths()
+
+	for _, kind := range kinds {
+		path := paths[kind]
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		value, issues := inspect(kind, data)
+		fr := FileReport{Path: path, Issues: issues}
+		if fr.Issues == nil {
+			fr.Issues = []string{}
+		}
+
+		if write && len(issues) > 0 {
+			if value == nil {
+				value = r.reconstruct(kind)
+				fr.Reconstructed = true
+			}
+			if fr.BackupPath, err = r.rewrite(path, data, value); err != nil {
+				return nil, err
+			}
+			fr.Repaired = true
+		}
+
+		report.Files = append(report.Files, fr)
+	}
+
+	return report, nil
+}
+
+// rewrite backs up the original contents and writes the repaired value.
+func (r *Repairer) rewrite(path string, original []byte, value any) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	backupPath := fmt.Sprintf("%s.corrupt-%s", path, r.now().Format("20060102-150405"))
+	if err := os.WriteFile(backupPath, original, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode repaired %s: %w", path, err)
+	}
+	if err := atomicfile.WriteFile(path, append(data, '\n'), info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to write repaired %s: %w", path, err)
+	}
+
+	return backupPath, nil
+}
+
+// inspect parses a state file and returns the salvaged, schema-conforming
+// value along with every problem found. A nil value means nothing could be
+// salvaged.
+func inspect(kind Kind, data []byte) (any, []string) {
+	var issues []string
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var raw any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+
+	end := int(dec.InputOffset())
+	if rest := bytes.TrimSpace(data[end:]); len(rest) > 0 {
+		issues = append(issues, fmt.Sprintf("trailing garbage after JSON value (%d bytes)", len(rest)))
+	}
+
+	for _, key := range duplicateKeys(data[:end]) {
+		issues = append(issues, fmt.Sprintf("duplicate key %q (last value kept)", key))
+	}
+
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, append(issues, "top-level value is not an object")
+	}
+
+	var schemaIssues []string
+	switch kind {
+	case KindMarketplaces:
+		schemaIssues = normalizeMarketplaces(obj)
+	case KindPlugins:
+		schemaIssues = normalizePlugins(obj)
+	case KindSettings:
+		schemaIssues = normalizeSettings(obj)
+	}
+
+	return obj, append(issues, schemaIssues...)
+}
+
+// normalizeMarketplaces drops marketplace entries without a usable source.
+// A versioned layout keeps its entries under "marketplaces"; one in another
+// shape is newer than clew and left alone.
+func normalizeMarketplaces(obj map[string]any) []string {
+	if _, versioned := obj["version"].(float64); versioned {
+		if entries, ok := obj["marketplaces"].(map[string]any); ok {
+			return normalizeMarketplaces(entries)
+		}
+		return nil
+	}
+
+	var issues []string
+	for _, alias := range sortedKeys(obj) {
+		entry, ok := obj[alias].(map[string]any)
+		if !ok {
+			issues = append(issues, fmt.Sprintf("marketplace %q: entry is not an object (dropped)", alias))
+			delete(obj, alias)
+			continue
+		}
+		source, ok := entry["source"].(map[string]any)
+		if !ok {
+			issues = append(issues, fmt.Sprintf("marketplace %q: missing source (dropped)", alias))
+			delete(obj, alias)
+			continue
+		}
+		if _, ok := source["source"].(string); !ok {
+			issues = append(issues, fmt.Sprintf("marketplace %q: source type is not a string (dropped)", alias))
+			delete(obj, alias)
+			continue
+		}
+		if loc, present := entry["installLocation"]; present {
+			if _, ok := loc.(string); !ok {
+				issues = append(issues, fmt.Sprintf("marketplace %q: installLocation is not a string (dropped)", alias))
+				delete(obj, alias)
+			}
+		}
+	}
+	return issues
+}
+
+// normalizePlugins ensures a version number and a plugins map of install lists.
+func normalizePlugins(obj map[string]any) []string {
+	var issues []string
+
+	if _, ok := obj["version"].(float64); !ok {
+		issues = append(issues, fmt.Sprintf("missing or invalid version (set to %d)", installedPluginsVersion))
+		obj["version"] = installedPluginsVersion
+	}
+
+	plugins, ok := obj["plugins"].(map[string]any)
+	if !ok {
+		issues = append(issues, "missing or invalid plugins map (reset to empty)")
+		obj["plugins"] = map[string]any{}
+		return issues
+	}
+
+	for _, name := range sortedKeys(plugins) {
+		installs, ok := plugins[name].([]any)
+		if !ok {
+			issues = append(issues, fmt.Sprintf("plugin %q: installs is not a list (dropped)", name))
+			delete(plugins, name)
+			continue
+		}
+		kept := make([]any, 0, len(installs))
+		for _, install := range installs {
+			m, ok := install.(map[string]any)
+			if !ok {
+				continue
+			}
+			if _, ok := m["installPath"].(string); !ok {
+				continue
+			}
+			kept = append(kept, m)
+		}
+		if len(kept) != len(installs) {
+			issues = append(issues, i18n.N(i18n.RepairDroppedInstalls, len(installs)-len(kept), name))
+		}
+		if len(kept) == 0 {
+			delete(plugins, name)
+			continue
+		}
+		plugins[name] = kept
+	}
+
+	return issues
+}
+
+// normalizeSettings drops non-boolean enabledPlugins values. All other
+// settings are preserved untouched.
+func normalizeSettings(obj map[string]any) []string {
+	raw, present := obj["enabledPlugins"]
+	if !present {
+		return nil
+	}
+
+	enabled, ok := raw.(map[string]any)
+	if !ok {
+		obj["enabledPlugins"] = map[string]any{}
+		return []string{"enabledPlugins is not an object (reset to empty)"}
+	}
+
+	var issues []string
+	for _, name := range sortedKeys(enabled) {
+		if _, ok := enabled[name].(bool); !ok {
+			issues = append(issues, fmt.Sprintf("enabledPlugins %q: value is not a boolean (dropped)", name))
+			delete(enabled, name)
+		}
+	}
+	return issues
+}
+
+// reconstruct rebuilds an unreadable state file from the plugins directory
+// and the Clewfile.
+func (r *Repairer) reconstruct(kind Kind) any {
+	switch kind {
+	case KindMarketplaces:
+		return r.reconstructMarketplaces()
+	case KindPlugins:
+		return r.reconstructPlugins()
+	default:
+		return r.reconstructSettings()
+	}
+}
+
+// reconstructMarketplaces lists Clewfile marketplaces that are cloned on disk.
+func (r *Repairer) reconstructMarketplaces() map[string]any {
+	out := map[string]any{}
+	if r.clewfile == nil {
+		return out
+	}
+
+	now := r.now().UTC().Format(time.RFC3339)
+	for alias, m := range r.clewfile.Marketplaces {
+		dir := filepath.Join(r.claudeDir, "plugins", "marketplaces", alias)
+		if !isDir(dir) || m.Repo == "" {
+			continue
+		}
+		out[alias] = map[string]any{
+			"source": map[string]any{
+				"source": "github",
+				"repo":   m.Repo,
+			},
+			"installLocation": dir,
+			"lastUpdated":     now,
+		}
+	}
+	return out
+}
+
+// reconstructPlugins lists Clewfile plugins found in their marketplace
+// directory plus every local plugin in the repos directory.
+func (r *Repairer) reconstructPlugins() map[string]any {
+	now := r.now().UTC().Format(time.RFC3339)
+	plugins := map[string]any{}
+
+	install := func(path string) []any {
+		return []any{map[string]any{
+			"scope":       "user",
+			"installPath": path,
+			"version":     "unknown",
+			"installedAt": now,
+			"lastUpdated": now,
+		}}
+	}
+
+	if r.clewfile != nil {
+		for _, p := range r.clewfile.Plugins {
+			name, marketplace, ok := strings.Cut(p.Name, "@")
+			if !ok {
+				continue
+			}
+			dir := filepath.Join(r.claudeDir, "plugins", "marketplaces", marketplace, "plugins", name)
+			if isDir(dir) {
+				plugins[p.Name] = install(dir)
+			}
+		}
+	}
+
+	reposDir := filepath.Join(r.claudeDir, "plugins", "repos")
+	if entries, err := os.ReadDir(reposDir); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				plugins[e.Name()] = install(filepath.Join(reposDir, e.Name()))
+			}
+		}
+	}
+
+	return map[string]any{
+		"version": installedPluginsVersion,
+		"plugins": plugins,
+	}
+}
+
+// reconstructSettings recreates enabledPlugins from the Clewfile. Any other
+// settings in an unreadable file are lost; they remain in the backup.
+func (r *Repairer) reconstructSettings() map[string]any {
+	enabled := map[string]any{}
+	if r.clewfile != nil {
+		for _, p := range r.clewfile.Plugins {
+			enabled[p.Name] = p.Enabled == nil || *p.Enabled
+		}
+	}
+	return map[string]any{"enabledPlugins": enabled}
+}
+
+// duplicateKeys walks the token stream and returns the dotted path of every
+// object key that appears more than once in the same object.
+func duplicateKeys(data []byte) []string {
+	type frame struct {
+		object    bool
+		expectKey bool
+		key       string
+		path      string
+		seen      map[string]bool
+	}
+
+	var (
+		stack []*frame
+		dups  []string
+	)
+
+	top := func() *frame {
+		if len(stack) == 0 {
+			return nil
+		}
+		return stack[len(stack)-1]
+	}
+	valueDone := func() {
+		if f := top(); f != nil && f.object {
+			f.expectKey = true
+		}
+	}
+	childPath := func() string {
+		f := top()
+		switch {
+		case f == nil:
+			return ""
+		case f.object:
+			return strings.TrimPrefix(f.path+"."+f.key, ".")
+		default:
+			return f.path + "[]"
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+
+		if f := top(); f != nil && f.object && f.expectKey {
+			if d, ok := tok.(json.Delim); ok && d == '}' {
+				stack = stack[:len(stack)-1]
+				valueDone()
+				continue
+			}
+			key, _ := tok.(string)
+			if f.seen[key] {
+				dups = append(dups, strings.TrimPrefix(f.path+"."+key, "."))
+			}
+			f.seen[key] = true
+			f.key = key
+			f.expectKey = false
+			continue
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &frame{object: true, expectKey: true, path: childPath(), seen: map[string]bool{}})
+		case json.Delim('['):
+			stack = append(stack, &frame{path: childPath()})
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueDone()
+		default:
+			valueDone()
+		}
+	}
+
+	return dups
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
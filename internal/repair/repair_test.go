@@ -0,0 +1,203 @@
+package repair
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamancini/clew/internal/config"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readJSON(t *testing.T, path string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v map[string]any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("repaired %s is not valid JSON: %v\n%s", path, err, data)
+	}
+	return v
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	data := `{"a": 1, "b": {"x": [1, {"y": 1, "y": 2}], "x": 3}, "a": 2}`
+	got := duplicateKeys([]byte(data))
+	want := []string{"b.x[].y", "b.x", "a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("duplicateKeys() = %v, want %v", got, want)
+	}
+
+	if got := duplicateKeys([]byte(`{"a": {"a": 1}, "b": [{"a": 1}, {"a": 2}]}`)); len(got) != 0 {
+		t.Errorf("duplicateKeys() = %v, want none", got)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      Kind
+		data      string
+		wantIssue string
+		wantNil   bool
+	}{
+		{"clean settings", KindSettings, `{"enabledPlugins": {"a@b": true}}`, "", false},
+		{"trailing garbage", KindSettings, `{"enabledPlugins": {}}}}`, "trailing garbage", false},
+		{"duplicate key", KindSettings, `{"theme": "dark", "theme": "light"}`, `duplicate key "theme"`, false},
+		{"non-bool enabled", KindSettings, `{"enabledPlugins": {"a@b": "yes"}}`, `enabledPlugins "a@b"`, false},
+		{"truncated", KindPlugins, `{"version": 2, "plugins": {`, "invalid JSON", true},
+		{"not an object", KindMarketplaces, `[]`, "not an object", true},
+		{"marketplace without source", KindMarketplaces, `{"m": {"installLocation": "/x"}}`, "missing source", false},
+		{"versioned marketplaces", KindMarketplaces, `{"version": 2, "marketplaces": {"m": {"installLocation": "/x"}}}`, "missing source", false},
+		{"newer marketplaces layout", KindMarketplaces, `{"version": 3, "marketplaces": []}`, "", false},
+		{"plugins wrong shape", KindPlugins, `{"version": 2, "plugins": {"a@b": {"installPath": "/x"}}}`, "not a list", false},
+		{"plugins missing version", KindPlugins, `{"plugins": {}}`, "invalid version", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, issues := inspect(tt.kind, []byte(tt.data))
+			if (value == nil) != tt.wantNil {
+				t.Errorf("value nil = %v, want %v", value == nil, tt.wantNil)
+			}
+			if tt.wantIssue == "" {
+				if len(issues) != 0 {
+					t.Errorf("issues = %v, want none", issues)
+				}
+				return
+			}
+			found := false
+			for _, issue := range issues {
+				if strings.Contains(issue, tt.wantIssue) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("issues = %v, want one containing %q", issues, tt.wantIssue)
+			}
+		})
+	}
+}
+
+func TestRepair_SalvagesAndBacksUp(t *testing.T) {
+	claudeDir := t.TempDir()
+	settingsPath := filepath.Join(claudeDir, "settings.json")
+	original := `{"theme": "dark", "enabledPlugins": {"a@m": true, "b@m": 1}}garbage`
+	writeFile(t, settingsPath, original)
+
+	r := NewRepairer(claudeDir, nil)
+	r.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	report, err := r.Repair()
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	if len(report.Files) != 1 || !report.Files[0].Repaired || report.Files[0].Reconstructed {
+		t.Fatalf("report = %+v, want one salvaged file", report.Files)
+	}
+
+	backup, err := os.ReadFile(settingsPath + ".corrupt-20260102-030405")
+	if err != nil || string(backup) != original {
+		t.Errorf("backup = %q, %v; want original contents", backup, err)
+	}
+
+	got := readJSON(t, settingsPath)
+	if got["theme"] != "dark" {
+		t.Errorf("unrelated settings not preserved: %v", got)
+	}
+	enabled := got["enabledPlugins"].(map[string]any)
+	if len(enabled) != 1 || enabled["a@m"] != true {
+		t.Errorf("enabledPlugins = %v, want only a@m", enabled)
+	}
+
+	// Repaired file is clean
+	report, err = r.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HasIssues() {
+		t.Errorf("Check() after repair found issues: %+v", report.Files)
+	}
+}
+
+func TestRepair_Reconstructs(t *testing.T) {
+	claudeDir := t.TempDir()
+	pluginsDir := filepath.Join(claudeDir, "plugins")
+	writeFile(t, filepath.Join(pluginsDir, "known_marketplaces.json"), `{"official": {"sour`)
+	writeFile(t, filepath.Join(pluginsDir, "installed_plugins.json"), "\x00\x00")
+	writeFile(t, filepath.Join(pluginsDir, "marketplaces", "official", "plugins", "context7", "plugin.json"), "{}")
+	writeFile(t, filepath.Join(pluginsDir, "repos", "my-local", "plugin.json"), "{}")
+
+	disabled := false
+	clewfile := &config.Clewfile{
+		Marketplaces: map[string]config.Marketplace{
+			"official": {Repo: "anthropics/claude-plugins-official"},
+			"missing":  {Repo: "example/missing"},
+		},
+		Plugins: []config.Plugin{
+			{Name: "context7@official"},
+			{Name: "gone@official", Enabled: &disabled},
+		},
+	}
+
+	report, err := NewRepairer(claudeDir, clewfile).Repair()
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	for _, f := range report.Files {
+		if !f.Reconstructed {
+			t.Errorf("%s: expected reconstruction, got %+v", f.Path, f)
+		}
+	}
+
+	marketplaces := readJSON(t, filepath.Join(pluginsDir, "known_marketplaces.json"))
+	if _, ok := marketplaces["official"]; !ok || len(marketplaces) != 1 {
+		t.Errorf("marketplaces = %v, want only official", marketplaces)
+	}
+
+	installed := readJSON(t, filepath.Join(pluginsDir, "installed_plugins.json"))
+	plugins := installed["plugins"].(map[string]any)
+	if _, ok := plugins["context7@official"]; !ok {
+		t.Error("expected context7@official to be reconstructed")
+	}
+	if _, ok := plugins["my-local"]; !ok {
+		t.Error("expected local plugin from repos dir to be reconstructed")
+	}
+	if _, ok := plugins["gone@official"]; ok {
+		t.Error("plugin missing from disk should not be reconstructed")
+	}
+}
+
+func TestCheck_DoesNotModify(t *testing.T) {
+	claudeDir := t.TempDir()
+	path := filepath.Join(claudeDir, "settings.json")
+	writeFile(t, path, `{"a": 1, "a": 2}`)
+
+	report, err := NewRepairer(claudeDir, nil).Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.HasIssues() || report.Files[0].Repaired {
+		t.Errorf("report = %+v, want unrepaired issue", report.Files)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != `{"a": 1, "a": 2}` {
+		t.Errorf("Check() modified file: %s", data)
+	}
+}
@@ -0,0 +1,215 @@
+// Package scan inspects plugin files for risky patterns: hooks that pipe a
+// download into a shell, scripts that read credential files, and
+// obfuscated code. It is a heuristic aid for reviewing plugins before
+// trusting them, not a guarantee that a plugin is safe.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/adamancini/clew/internal/catalog"
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// Rule names.
+const (
+	RulePipeToShell      = "pipe-to-shell"
+	RuleCredentialRead   = "credential-read"
+	RuleObfuscatedScript = "obfuscated-script"
+)
+
+// Rule is a risky pattern and the score a match contributes.
+type Rule struct {
+	Name        string
+	Description string
+	Score       int
+	pattern     *regexp.Regexp
+}
+
+// Rules lists every rule, riskiest first.
+var Rules = []Rule{
+	{
+		Name:        RulePipeToShell,
+		Description: "downloads a script and pipes it straight into a shell",
+		Score:       8,
+		pattern:     regexp.MustCompile(`\b(curl|wget)\b[^|\n]*\|\s*(sudo\s+)?(ba|z|da|k)?sh\b`),
+	},
+	{
+		Name:        RuleCredentialRead,
+		Description: "reads a credential or key file",
+		Score:       7,
+		pattern:     regexp.MustCompile(`\.ssh/(id_[a-z0-9]+|authorized_keys)|\.aws/credentials|\.netrc\b|\.git-credentials|\.npmrc\b|\.docker/config\.json|\.kube/config|gh/hosts\.yml|security\s+find-(generic|internet)-password`),
+	},
+	{
+		Name:        RuleObfuscatedScript,
+		Description: "decodes and runs hidden code, or embeds a long encoded payload",
+		Score:       6,
+		pattern:     regexp.MustCompile(`base64\s+(-d|--decode|-D)\b[^\n]*\|\s*(ba|z)?sh\b|\beval\b[^\n]*(base64|\\x[0-9a-fA-F]{2})|(\\x[0-9a-fA-F]{2}){16,}|[A-Za-z0-9+/]{200,}={0,2}`),
+	},
+}
+
+// maxFileSize skips files too large to be hand-written hooks or scripts.
+const maxFileSize = 1 << 20
+
+// skipDirs are directories never scanned.
+var skipDirs = map[string]bool{".git": true, "node_modules": true}
+
+// Finding is a rule match in a plugin file.
+type Finding struct {
+	Rule  string `json:"rule" yaml:"rule"`
+	Score int    `json:"score" yaml:"score"`
+	File  string `json:"file" yaml:"file"` // Relative to the plugin directory
+	Line  int    `json:"line" yaml:"line"`
+	Text  string `json:"text" yaml:"text"` // The matching line, trimmed
+}
+
+// Report is the scan result for one plugin.
+type Report struct {
+	Plugin   string    `json:"plugin" yaml:"plugin"`
+	Dir      string    `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Score    int       `json:"score" yaml:"score"` // Sum of distinct rule scores, capped at config.MaxScanScore
+	Findings []Finding `json:"findings,omitempty" yaml:"findings,omitempty"`
+	Blocked  bool      `json:"blocked,omitempty" yaml:"blocked,omitempty"` // Blocked by the Clewfile's scan policy
+	Error    string    `json:"error,omitempty" yaml:"error,omitempty"`     // Set when the plugin could not be scanned
+}
+
+// Dir scans every file under dir and returns the findings and score.
+func Dir(dir string) ([]Finding, int, error) {
+	var findings []Finding
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > maxFileSize {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		found, err := scanFile(path, filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		findings = append(findings, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	return findings, score(findings), nil
+}
+
+// scanFile matches every rule against each line of a text file.
+func scanFile(path, rel string) ([]Finding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// Binary files cannot be read as scripts
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, nil
+	}
+
+	var findings []Finding
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for _, rule := range Rules {
+			if rule.pattern.MatchString(text) {
+				findings = append(findings, Finding{Rule: rule.Name, Score: rule.Score, File: rel, Line: line, Text: excerpt(text)})
+			}
+		}
+	}
+	return findings, scanner.Err()
+}
+
+// score sums the scores of the distinct rules matched, so one risky
+// pattern repeated across files does not outweigh several different ones.
+func score(findings []Finding) int {
+	seen := make(map[string]bool)
+	total := 0
+	for _, f := range findings {
+		if !seen[f.Rule] {
+			seen[f.Rule] = true
+			total += f.Score
+		}
+	}
+	return min(total, config.MaxScanScore)
+}
+
+// excerpt trims a matching line for display.
+func excerpt(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) > 120 {
+		return line[:117] + "..."
+	}
+	return line
+}
+
+// PluginDir returns the directory holding a plugin's files: its install
+// path if installed, otherwise its directory in a cloned marketplace.
+// It returns "" if the files are not on this machine.
+func PluginDir(name string, s *state.State, entries []catalog.Entry) string {
+	if p, ok := s.Plugins[name]; ok && p.InstallPath != "" {
+		return p.InstallPath
+	}
+	for _, e := range entries {
+		if e.FullName() == name {
+			return e.Dir
+		}
+	}
+	return ""
+}
+
+// Plugins scans each named plugin and applies policy. Plugins whose files
+// are not on this machine, or cannot be read, are reported with an error
+// and blocked when policy has a block score (see BlocksUnscanned).
+// Reports are sorted by score, highest first, then name.
+func Plugins(names []string, s *state.State, policy config.ScanConfig) []Report {
+	entries := catalog.Load(s.Marketplaces)
+	reports := make([]Report, 0, len(names))
+	for _, name := range names {
+		r := Report{Plugin: name, Dir: PluginDir(name, s, entries)}
+		if r.Dir == "" {
+			r.Error = "plugin files not available locally (not installed and not in a cloned marketplace)"
+			r.Blocked = policy.BlocksUnscanned(name)
+			reports = append(reports, r)
+			continue
+		}
+		findings, total, err := Dir(r.Dir)
+		if err != nil {
+			r.Error = err.Error()
+		}
+		r.Findings, r.Score = findings, total
+		r.Blocked = policy.Blocks(name, total) || (err != nil && policy.BlocksUnscanned(name))
+		reports = append(reports, r)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		if reports[i].Score != reports[j].Score {
+			return reports[i].Score > reports[j].Score
+		}
+		return reports[i].Plugin < reports[j].Plugin
+	})
+	return reports
+}
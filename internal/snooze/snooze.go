@@ -0,0 +1,202 @@
+// Package snooze keeps the list of items the user has asked clew to stop
+// reporting for a while.
+//
+// A snoozed marketplace or plugin is left out of diff, status and sync
+// until its snooze expires, whatever the Clewfile says about it. Unlike the
+// Clewfile's ignore block, snoozes are local to the machine and temporary.
+package snooze
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/xdg"
+)
+
+// Item types that can be snoozed.
+const (
+	TypeMarketplace = "marketplace"
+	TypePlugin      = "plugin"
+)
+
+// Entry is a snoozed item.
+type Entry struct {
+	Type      string    `json:"type" yaml:"type"`
+	Name      string    `json:"name" yaml:"name"`
+	Until     time.Time `json:"until" yaml:"until"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
+
+// Store persists snoozed items.
+type Store struct {
+	path    string
+	Entries []Entry `json:"snoozes"`
+}
+
+// DefaultPath returns the snooze list location in clew's state directory.
+func DefaultPath() (string, error) {
+	dir, err := xdg.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snoozes.json"), nil
+}
+
+// Load reads the store at path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read snoozes: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse snoozes: %w", err)
+	}
+	return s, nil
+}
+
+// Save writes the store back to disk.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if s.Entries == nil {
+		s.Entries = []Entry{}
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snoozes: %w", err)
+	}
+	return atomicfile.WriteFile(s.path, data, 0644)
+}
+
+// Add snoozes an item until the given time, replacing any earlier snooze
+// of the same item.
+func (s *Store) Add(itemType, name string, until, now time.Time) Entry {
+	s.Remove(itemType, name)
+	e := Entry{Type: itemType, Name: name, Until: until.UTC(), CreatedAt: now.UTC()}
+	s.Entries = append(s.Entries, e)
+	sort.Slice(s.Entries, func(i, j int) bool {
+		if s.Entries[i].Type != s.Entries[j].Type {
+			return s.Entries[i].Type < s.Entries[j].Type
+		}
+		return s.Entries[i].Name < s.Entries[j].Name
+	})
+	return e
+}
+
+// Remove drops the snoozes of items named name, of itemType or of any
+// type if itemType is empty, and returns them.
+func (s *Store) Remove(itemType, name string) []Entry {
+	var removed []Entry
+	kept := s.Entries[:0]
+	for _, e := range s.Entries {
+		if e.Name == name && (itemType == "" || e.Type == itemType) {
+			removed = append(removed, e)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	s.Entries = kept
+	return removed
+}
+
+// Prune drops expired snoozes and reports whether any were dropped.
+func (s *Store) Prune(now time.Time) bool {
+	kept := s.Entries[:0]
+	for _, e := range s.Entries {
+		if e.Until.After(now) {
+			kept = append(kept, e)
+		}
+	}
+	pruned := len(kept) != len(s.Entries)
+	s.Entries = kept
+	return pruned
+}
+
+// Active returns the snoozes that have not expired at now.
+func (s *Store) Active(now time.Time) []Entry {
+	var active []Entry
+	for _, e := range s.Entries {
+		if e.Until.After(now) {
+			active = append(active, e)
+		}
+	}
+	return active
+}
+
+// Filter removes snoozed items from result, whatever their action, and
+// returns the snoozes that matched an item. Snoozing a marketplace also
+// snoozes the plugins that come from it.
+func (s *Store) Filter(result *diff.Result, now time.Time) []Entry {
+	snoozed := make(map[string]Entry)
+	for _, e := range s.Active(now) {
+		snoozed[e.Type+"\x00"+e.Name] = e
+	}
+	if len(snoozed) == 0 {
+		return nil
+	}
+
+	var applied []Entry
+	marketplaces := result.Marketplaces[:0]
+	for _, m := range result.Marketplaces {
+		if e, ok := snoozed[TypeMarketplace+"\x00"+m.Alias]; ok {
+			applied = append(applied, e)
+			continue
+		}
+		marketplaces = append(marketplaces, m)
+	}
+	result.Marketplaces = marketplaces
+
+	plugins := result.Plugins[:0]
+	for _, p := range result.Plugins {
+		if e, ok := snoozed[TypePlugin+"\x00"+p.Name]; ok {
+			applied = append(applied, e)
+			continue
+		}
+		if e, ok := snoozed[TypeMarketplace+"\x00"+p.Marketplace()]; ok {
+			if !slices.Contains(applied, e) {
+				applied = append(applied, e)
+			}
+			continue
+		}
+		plugins = append(plugins, p)
+	}
+	result.Plugins = plugins
+	return applied
+}
+
+// ParseDuration parses a snooze length. Besides Go durations ("36h") it
+// accepts whole days and weeks ("7d", "2w").
+func ParseDuration(s string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+
+	var d time.Duration
+	var err error
+	if unit, ok := units[s[max(len(s)-1, 0):]]; ok {
+		var n int
+		n, err = strconv.Atoi(s[:len(s)-1])
+		d = time.Duration(n) * unit
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (e.g. 7d, 2w or 36h)", s)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q (must be positive)", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,114 @@
+package snooze
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adamancini/clew/internal/diff"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clew", "snoozes.json")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() missing file error = %v", err)
+	}
+	s.Add(TypePlugin, "b@official", now.Add(time.Hour), now)
+	s.Add(TypePlugin, "a@official", now.Add(time.Hour), now)
+	s.Add(TypePlugin, "a@official", now.Add(2*time.Hour), now) // Replaces the first
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Entries) != 2 {
+		t.Fatalf("Entries = %+v, want 2", loaded.Entries)
+	}
+	if e := loaded.Entries[0]; e.Name != "a@official" || !e.Until.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("Entries[0] = %+v, want a@official until now+2h", e)
+	}
+}
+
+func TestRemoveAndPrune(t *testing.T) {
+	now := time.Now()
+	s := &Store{}
+	s.Add(TypeMarketplace, "shared", now.Add(time.Hour), now)
+	s.Add(TypePlugin, "shared", now.Add(time.Hour), now)
+	s.Add(TypePlugin, "old@official", now.Add(-time.Minute), now.Add(-time.Hour))
+
+	if !s.Prune(now) {
+		t.Error("Prune() = false, want true for an expired snooze")
+	}
+	if removed := s.Remove("", "shared"); len(removed) != 2 {
+		t.Errorf("Remove() removed %d, want both types", len(removed))
+	}
+	if len(s.Entries) != 0 {
+		t.Errorf("Entries = %+v, want none", s.Entries)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Now()
+	s := &Store{}
+	s.Add(TypePlugin, "broken@official", now.Add(time.Hour), now)
+	s.Add(TypeMarketplace, "mirror", now.Add(time.Hour), now)
+	s.Add(TypePlugin, "expired@official", now.Add(-time.Hour), now.Add(-2*time.Hour))
+
+	result := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "mirror", Action: diff.ActionAdd},
+			{Alias: "official", Action: diff.ActionAdd},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "broken@official", Action: diff.ActionAdd},
+			{Name: "expired@official", Action: diff.ActionRemove},
+			{Name: "mirror", Action: diff.ActionRemove},   // Same name, other type
+			{Name: "tool@mirror", Action: diff.ActionAdd}, // From the snoozed marketplace
+		},
+	}
+
+	applied := s.Filter(result, now)
+	if len(applied) != 2 {
+		t.Errorf("Filter() applied %+v, want 2 snoozes", applied)
+	}
+	if len(result.Marketplaces) != 1 || result.Marketplaces[0].Alias != "official" {
+		t.Errorf("Marketplaces = %+v, want only official", result.Marketplaces)
+	}
+	if len(result.Plugins) != 2 || result.Plugins[0].Name != "expired@official" || result.Plugins[1].Name != "mirror" {
+		t.Errorf("Plugins = %+v, want expired@official and mirror", result.Plugins)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"d", 0, true},
+		{"", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDuration(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package stamp records the last successful sync on this machine: which
+// machine, clew version and Clewfile, and when. clew status reads it to say
+// how long ago the machine was synced and to warn when that sync is stale.
+package stamp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/xdg"
+)
+
+// StaleAfter is how old the last sync may get before status calls it stale.
+const StaleAfter = 7 * 24 * time.Hour
+
+// Stamp describes the last successful sync.
+type Stamp struct {
+	MachineID    string    `json:"machine_id" yaml:"machine_id"` // Random, generated by the first sync on the machine
+	Hostname     string    `json:"hostname" yaml:"hostname"`
+	ClewVersion  string    `json:"clew_version" yaml:"clew_version"`
+	Clewfile     string    `json:"clewfile" yaml:"clewfile"`
+	ClewfileHash string    `json:"clewfile_hash" yaml:"clewfile_hash"` // Hex SHA256 of the Clewfile as read
+	SyncedAt     time.Time `json:"synced_at" yaml:"synced_at"`
+}
+
+// DefaultPath returns the stamp location in clew's state directory.
+func DefaultPath() (string, error) {
+	dir, err := xdg.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "last-sync.json"), nil
+}
+
+// Load reads the stamp at path. A machine that never synced has none, and
+// yields nil without an error.
+func Load(path string) (*Stamp, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read last sync: %w", err)
+	}
+	var s Stamp
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse last sync: %w", err)
+	}
+	return &s, nil
+}
+
+// Write records a successful sync of clewfilePath at now, keeping the
+// machine ID of an earlier stamp.
+func Write(path, clewVersion, clewfilePath string, now time.Time) (*Stamp, error) {
+	clewfilePath = absPath(clewfilePath)
+	content, err := os.ReadFile(clewfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Clewfile: %w", err)
+	}
+
+	s := &Stamp{
+		ClewVersion:  clewVersion,
+		Clewfile:     clewfilePath,
+		ClewfileHash: hashBytes(content),
+		SyncedAt:     now.UTC(),
+	}
+	s.Hostname, _ = os.Hostname()
+	if previous, err := Load(path); err == nil && previous != nil && previous.MachineID != "" {
+		s.MachineID = previous.MachineID
+	} else if s.MachineID, err = newMachineID(); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal last sync: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write last sync: %w", err)
+	}
+	return s, nil
+}
+
+// ShortHash returns the abbreviated Clewfile hash, as shown by status.
+func (s *Stamp) ShortHash() string {
+	return s.ClewfileHash[:min(len(s.ClewfileHash), 7)]
+}
+
+// ClewfileChanged reports whether the Clewfile at path differs from the one
+// last synced, or is a different file.
+func (s *Stamp) ClewfileChanged(path string) bool {
+	path = absPath(path)
+	if path != s.Clewfile {
+		return true
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return true
+	}
+	return hashBytes(content) != s.ClewfileHash
+}
+
+// Stale reports whether the last sync is older than StaleAfter at now.
+func (s *Stamp) Stale(now time.Time) bool {
+	return now.Sub(s.SyncedAt) > StaleAfter
+}
+
+// absPath returns path made absolute, or as given if that fails.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// newMachineID returns a random identifier for this machine.
+func newMachineID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate machine ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashBytes returns the hex SHA256 of data.
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
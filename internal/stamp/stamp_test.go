@@ -0,0 +1,56 @@
+package stamp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state", "clew", "last-sync.json")
+	clewfile := filepath.Join(dir, "Clewfile.yaml")
+	if err := os.WriteFile(clewfile, []byte("version: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if s, err := Load(path); err != nil || s != nil {
+		t.Fatalf("Load() before any sync = %+v, %v; want nil, nil", s, err)
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first, err := Write(path, "1.2.0", clewfile, now)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	second, err := Write(path, "1.3.0", clewfile, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if first.MachineID == "" || second.MachineID != first.MachineID {
+		t.Errorf("machine IDs %q, %q; want one kept across syncs", first.MachineID, second.MachineID)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.ClewVersion != "1.3.0" || !loaded.SyncedAt.Equal(now.Add(time.Hour)) || len(loaded.ShortHash()) != 7 {
+		t.Errorf("Load() = %+v", loaded)
+	}
+
+	if loaded.ClewfileChanged(clewfile) {
+		t.Error("ClewfileChanged() = true for the synced Clewfile")
+	}
+	if err := os.WriteFile(clewfile, []byte("version: 1\nplugins: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.ClewfileChanged(clewfile) {
+		t.Error("ClewfileChanged() = false after editing the Clewfile")
+	}
+
+	if loaded.Stale(now.Add(StaleAfter)) || !loaded.Stale(now.Add(StaleAfter+2*time.Hour)) {
+		t.Errorf("Stale() does not switch after %v", StaleAfter)
+	}
+}
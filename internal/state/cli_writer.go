@@ -0,0 +1,40 @@
+package state
+
+import "fmt"
+
+// Runner runs the claude CLI. sync.CommandRunner satisfies it.
+type Runner interface {
+	Run(name string, args ...string) ([]byte, error)
+}
+
+// CLIWriter implements Writer with claude plugin enable/disable, one
+// command per change.
+type CLIWriter struct {
+	Runner Runner
+}
+
+// SetEnabled implements Writer. It stops at the first command that fails.
+func (w *CLIWriter) SetEnabled(changes []EnabledChange) ([]byte, error) {
+	var output []byte
+	for _, c := range changes {
+		out, err := w.Runner.Run("claude", "plugin", enableVerb(c.Enabled), c.Plugin)
+		output = append(output, out...)
+		if err != nil {
+			return output, err
+		}
+	}
+	return output, nil
+}
+
+// Command implements Writer.
+func (w *CLIWriter) Command(c EnabledChange) string {
+	return fmt.Sprintf("claude plugin %s %s", enableVerb(c.Enabled), c.Plugin)
+}
+
+// enableVerb returns the claude plugin subcommand for an enabled state.
+func enableVerb(enabled bool) string {
+	if enabled {
+		return "enable"
+	}
+	return "disable"
+}
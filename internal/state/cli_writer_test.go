@@ -0,0 +1,40 @@
+package state
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeRunner returns canned output per command; unknown commands fail the
+// way claude does.
+type fakeRunner struct {
+	outputs map[string]string
+}
+
+func (f *fakeRunner) Run(name string, args ...string) ([]byte, error) {
+	cmd := name + " " + strings.Join(args, " ")
+	if out, ok := f.outputs[cmd]; ok {
+		return []byte(out), nil
+	}
+	return []byte("error: unknown command\n"), errors.New("exit status 1")
+}
+
+func TestCLIWriterSetEnabled(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{
+		"claude plugin disable a@m": "Disabled a@m\n",
+	}}
+	w := &CLIWriter{Runner: runner}
+
+	// Stops at the first failure, keeping the output so far
+	out, err := w.SetEnabled([]EnabledChange{{Plugin: "a@m"}, {Plugin: "b@m", Enabled: true}, {Plugin: "c@m"}})
+	if err == nil {
+		t.Fatal("SetEnabled() error = nil, want the failed enable")
+	}
+	if !strings.HasPrefix(string(out), "Disabled a@m\nerror: unknown command") {
+		t.Errorf("SetEnabled() output = %q", out)
+	}
+	if got := w.Command(EnabledChange{Plugin: "b@m", Enabled: true}); got != "claude plugin enable b@m" {
+		t.Errorf("Command() = %q", got)
+	}
+}
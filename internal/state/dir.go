@@ -0,0 +1,39 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ConfigDirEnv is the environment variable Claude Code reads to keep its
+// configuration somewhere other than ~/.claude. clew --claude-dir sets it,
+// so the claude commands clew runs use the same directory.
+const ConfigDirEnv = "CLAUDE_CONFIG_DIR"
+
+// DefaultClaudeDir returns the directory Claude Code keeps its settings and
+// plugins in: $CLAUDE_CONFIG_DIR, or ~/.claude when it is not set.
+func DefaultClaudeDir() (string, error) {
+	if dir := os.Getenv(ConfigDirEnv); dir != "" {
+		return filepath.Clean(dir), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude"), nil
+}
+
+// GlobalConfigDir returns the directory holding .claude.json, where Claude
+// Code records user and per-project MCP servers: $CLAUDE_CONFIG_DIR, or the
+// home directory when it is not set.
+func GlobalConfigDir() (string, error) {
+	if dir := os.Getenv(ConfigDirEnv); dir != "" {
+		return filepath.Clean(dir), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return home, nil
+}
@@ -0,0 +1,27 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultClaudeDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	t.Setenv(ConfigDirEnv, "")
+	if dir, err := DefaultClaudeDir(); err != nil || dir != filepath.Join(home, ".claude") {
+		t.Errorf("DefaultClaudeDir() = %q, %v; want ~/.claude", dir, err)
+	}
+	if dir, err := GlobalConfigDir(); err != nil || dir != home {
+		t.Errorf("GlobalConfigDir() = %q, %v; want home", dir, err)
+	}
+
+	t.Setenv(ConfigDirEnv, "/srv/claude/")
+	if dir, err := DefaultClaudeDir(); err != nil || dir != "/srv/claude" {
+		t.Errorf("DefaultClaudeDir() = %q, %v; want $CLAUDE_CONFIG_DIR", dir, err)
+	}
+	if dir, err := GlobalConfigDir(); err != nil || dir != "/srv/claude" {
+		t.Errorf("GlobalConfigDir() = %q, %v; want $CLAUDE_CONFIG_DIR", dir, err)
+	}
+}
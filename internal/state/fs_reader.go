@@ -7,9 +7,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/adamancini/clew/internal/logging"
 )
 
-// fsMarketplaceEntry represents a single marketplace in known_marketplaces.json.
+// fsMarketplaceEntry represents a single marketplace in known_marketplaces.json
+// (the v1 layout, and each entry of v2).
 type fsMarketplaceEntry struct {
 	Source struct {
 		Source string `json:"source"` // "github" or "local"
@@ -22,8 +25,8 @@ type fsMarketplaceEntry struct {
 
 // fsInstalledPlugins represents the structure of installed_plugins.json.
 type fsInstalledPlugins struct {
-	Version int                            `json:"version"`
-	Plugins map[string][]fsPluginInstall   `json:"plugins"`
+	Version int                          `json:"version"`
+	Plugins map[string][]fsPluginInstall `json:"plugins"`
 }
 
 type fsPluginInstall struct {
@@ -45,11 +48,10 @@ type fsSettings struct {
 func (r *FilesystemReader) Read() (*State, error) {
 	claudeDir := r.ClaudeDir
 	if claudeDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		var err error
+		if claudeDir, err = DefaultClaudeDir(); err != nil {
+			return nil, err
 		}
-		claudeDir = filepath.Join(home, ".claude")
 	}
 
 	state := &State{
@@ -69,40 +71,66 @@ func (r *FilesystemReader) Read() (*State, error) {
 		fmt.Fprintf(os.Stderr, "Warning: could not read plugins: %v\n", err)
 	}
 
-	// Read enabled state from settings
-	if err := r.readSettings(claudeDir, state); err != nil {
-		// Non-fatal, continue with default enabled state
-		fmt.Fprintf(os.Stderr, "Warning: could not read settings: %v\n", err)
+	// Read enabled state from settings; settings.local.json overrides
+	// settings.json, matching Claude's precedence
+	for _, name := range []string{SettingsFile, SettingsLocalFile} {
+		if err := r.readSettings(claudeDir, name, state); err != nil {
+			// Non-fatal, continue with default enabled state
+			fmt.Fprintf(os.Stderr, "Warning: could not read %s: %v\n", name, err)
+		}
 	}
 
-	return state, nil
-}
+	// Read custom key bindings
+	if err := r.readKeybindings(claudeDir, state); err != nil {
+		// Non-fatal, continue without key bindings
+		fmt.Fprintf(os.Stderr, "Warning: could not read %s: %v\n", KeybindingsFile, err)
+	}
 
-func (r *FilesystemReader) readMarketplaces(claudeDir string, state *State) error {
-	path := filepath.Join(claudeDir, "plugins", "known_marketplaces.json")
-	data, err := os.ReadFile(path)
+	// Managed (enterprise) settings override everything else
+	managedPath := r.ManagedSettingsPath
+	if managedPath == "" {
+		managedPath = DefaultManagedSettingsPath()
+	}
+	policy, err := ReadManagedPolicy(managedPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // No marketplaces file is okay
+		fmt.Fprintf(os.Stderr, "Warning: could not read managed settings: %v\n", err)
+	}
+	if policy != nil {
+		state.Managed = policy
+		for name, enabled := range policy.EnabledPlugins {
+			if plugin, ok := state.Plugins[name]; ok {
+				plugin.Enabled = enabled
+				plugin.EnabledSource = ManagedSettingsFile
+				state.Plugins[name] = plugin
+			}
 		}
-		return err
 	}
 
-	var marketplaces map[string]fsMarketplaceEntry
-	if err := json.Unmarshal(data, &marketplaces); err != nil {
-		return fmt.Errorf("failed to parse known_marketplaces.json: %w", err)
-	}
+	return state, nil
+}
 
-	for alias, m := range marketplaces {
-		state.Marketplaces[alias] = MarketplaceState{
-			Alias:           alias,
-			Repo:            m.Source.Repo,
-			InstallLocation: m.InstallLocation,
-			LastUpdated:     m.LastUpdated,
+// Files returns the paths Read reads, in the order it reads them, whether
+// or not they exist.
+func (r *FilesystemReader) Files() ([]string, error) {
+	claudeDir := r.ClaudeDir
+	if claudeDir == "" {
+		var err error
+		if claudeDir, err = DefaultClaudeDir(); err != nil {
+			return nil, err
 		}
 	}
-
-	return nil
+	managedPath := r.ManagedSettingsPath
+	if managedPath == "" {
+		managedPath = DefaultManagedSettingsPath()
+	}
+	return []string{
+		filepath.Join(claudeDir, "plugins", KnownMarketplacesFile),
+		filepath.Join(claudeDir, "plugins", "installed_plugins.json"),
+		filepath.Join(claudeDir, SettingsFile),
+		filepath.Join(claudeDir, SettingsLocalFile),
+		filepath.Join(claudeDir, KeybindingsFile),
+		managedPath,
+	}, nil
 }
 
 func (r *FilesystemReader) readPlugins(claudeDir string, state *State) error {
@@ -110,10 +138,12 @@ func (r *FilesystemReader) readPlugins(claudeDir string, state *State) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
+			logging.Tracef("state: %s not found", path)
 			return nil // No plugins file is okay
 		}
 		return err
 	}
+	logging.Tracef("state: read %s (%d bytes)\n%s", path, len(data), data)
 
 	var plugins fsInstalledPlugins
 	if err := json.Unmarshal(data, &plugins); err != nil {
@@ -141,6 +171,7 @@ func (r *FilesystemReader) readPlugins(claudeDir string, state *State) error {
 			//    and has a valid local path
 			isLocal := strings.HasPrefix(install.InstallPath, reposDir) ||
 				(marketplace == "" && install.InstallPath != "")
+			logging.Tracef("state: plugin %s -> scope=%s version=%s local=%t path=%s", fullName, install.Scope, install.Version, isLocal, install.InstallPath)
 
 			state.Plugins[fullName] = PluginState{
 				Name:         pluginName,
@@ -151,6 +182,8 @@ func (r *FilesystemReader) readPlugins(claudeDir string, state *State) error {
 				InstallPath:  install.InstallPath,
 				IsLocal:      isLocal,
 				GitCommitSha: install.GitCommitSha,
+				LastUpdated:  install.LastUpdated,
+				Installs:     pluginInstalls(installs),
 			}
 		}
 	}
@@ -158,26 +191,44 @@ func (r *FilesystemReader) readPlugins(claudeDir string, state *State) error {
 	return nil
 }
 
-func (r *FilesystemReader) readSettings(claudeDir string, state *State) error {
-	path := filepath.Join(claudeDir, "settings.json")
+// pluginInstalls converts every install entry of a plugin.
+func pluginInstalls(installs []fsPluginInstall) []PluginInstall {
+	result := make([]PluginInstall, 0, len(installs))
+	for _, i := range installs {
+		result = append(result, PluginInstall{
+			Scope:       i.Scope,
+			ProjectPath: i.ProjectPath,
+			InstallPath: i.InstallPath,
+			Version:     i.Version,
+		})
+	}
+	return result
+}
+
+func (r *FilesystemReader) readSettings(claudeDir, name string, state *State) error {
+	path := filepath.Join(claudeDir, name)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
+			logging.Tracef("state: %s not found", path)
 			return nil // No settings file is okay
 		}
 		return err
 	}
+	logging.Tracef("state: read %s (%d bytes)\n%s", path, len(data), data)
 
 	var settings fsSettings
 	if err := json.Unmarshal(data, &settings); err != nil {
-		return fmt.Errorf("failed to parse settings.json: %w", err)
+		return fmt.Errorf("failed to parse %s: %w", name, err)
 	}
 
 	// Update enabled state for plugins
-	for name, enabled := range settings.EnabledPlugins {
-		if plugin, ok := state.Plugins[name]; ok {
+	for pluginName, enabled := range settings.EnabledPlugins {
+		if plugin, ok := state.Plugins[pluginName]; ok {
+			logging.Tracef("state: plugin %s enabled=%t (%s)", pluginName, enabled, name)
 			plugin.Enabled = enabled
-			state.Plugins[name] = plugin
+			plugin.EnabledSource = name
+			state.Plugins[pluginName] = plugin
 		}
 	}
 
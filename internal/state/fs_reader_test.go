@@ -134,6 +134,55 @@ func TestFilesystemReaderPlugins(t *testing.T) {
 		if p.Enabled {
 			t.Error("Plugin should be disabled")
 		}
+		want := PluginInstall{Scope: "project", ProjectPath: "/project", InstallPath: "/path/to/another", Version: "2.0.0"}
+		if len(p.Installs) != 1 || p.Installs[0] != want {
+			t.Errorf("Plugin installs = %+v, want [%+v]", p.Installs, want)
+		}
+	}
+}
+
+func TestFilesystemReaderSettingsLocalPrecedence(t *testing.T) {
+	tmpDir := t.TempDir()
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	pluginsDir := filepath.Join(claudeDir, "plugins")
+	if err := os.MkdirAll(pluginsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	pluginsJSON := `{"version": 2, "plugins": {
+  "a@m": [{"scope": "user", "installPath": "/a"}],
+  "b@m": [{"scope": "user", "installPath": "/b"}],
+  "c@m": [{"scope": "user", "installPath": "/c"}]
+}}`
+	if err := os.WriteFile(filepath.Join(pluginsDir, "installed_plugins.json"), []byte(pluginsJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(claudeDir, SettingsFile), []byte(`{"enabledPlugins": {"a@m": true, "b@m": true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(claudeDir, SettingsLocalFile), []byte(`{"enabledPlugins": {"b@m": false}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := (&FilesystemReader{ClaudeDir: claudeDir}).Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		wantEnable bool
+		wantSource string
+	}{
+		{"a@m", true, SettingsFile},
+		{"b@m", false, SettingsLocalFile},
+		{"c@m", true, ""},
+	}
+	for _, tt := range tests {
+		p := state.Plugins[tt.name]
+		if p.Enabled != tt.wantEnable || p.EnabledSource != tt.wantSource {
+			t.Errorf("%s: Enabled=%v EnabledSource=%q, want %v %q", tt.name, p.Enabled, p.EnabledSource, tt.wantEnable, tt.wantSource)
+		}
 	}
 }
 
@@ -0,0 +1,103 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/jsonedit"
+)
+
+// ErrUnknownLayout is returned for a settings file clew cannot edit safely:
+// it is not a JSON object, or its enabledPlugins is not a map of booleans.
+var ErrUnknownLayout = errors.New("unrecognised settings file layout")
+
+// Files reads and writes Claude Code's files. It allows for mocking in
+// tests; AtomicFiles is the real implementation.
+type Files interface {
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte, perm os.FileMode) error
+}
+
+// AtomicFiles writes atomically and fsynced, keeping the previous contents
+// as a .bak file, so a crash mid-write can never leave Claude's state
+// half-written.
+type AtomicFiles struct{}
+
+func (AtomicFiles) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func (AtomicFiles) WriteFile(path string, data []byte, perm os.FileMode) error {
+	return atomicfile.WriteFileWithBackup(path, data, perm)
+}
+
+// FilesystemWriter implements Writer by editing enabledPlugins in one
+// settings file, preserving every other setting. All changes passed to
+// SetEnabled are written together.
+type FilesystemWriter struct {
+	Path  string // Settings file, e.g. ~/.claude/settings.json
+	Files Files  // nil uses AtomicFiles
+}
+
+// SetEnabled implements Writer with a single write of the settings file.
+func (w *FilesystemWriter) SetEnabled(changes []EnabledChange) ([]byte, error) {
+	return nil, EditJSONFile(w.files(), w.Path, func(settings *jsonedit.Object) error {
+		plugins, err := settings.Object("enabledPlugins")
+		if err != nil {
+			return fmt.Errorf("%w: %s: enabledPlugins is not an object", ErrUnknownLayout, w.Path)
+		}
+		for _, name := range plugins.Keys() {
+			var enabled bool
+			if _, err := plugins.Decode(name, &enabled); err != nil {
+				return fmt.Errorf("%w: %s: enabledPlugins[%q] is not true or false", ErrUnknownLayout, w.Path, name)
+			}
+		}
+		for _, c := range changes {
+			if err := plugins.Set(c.Plugin, c.Enabled); err != nil {
+				return err
+			}
+		}
+		return settings.Set("enabledPlugins", plugins)
+	})
+}
+
+// Command implements Writer.
+func (w *FilesystemWriter) Command(c EnabledChange) string {
+	return fmt.Sprintf("set enabledPlugins[%q] = %t in %s", c.Plugin, c.Enabled, w.Path)
+}
+
+func (w *FilesystemWriter) files() Files {
+	if w.Files == nil {
+		return AtomicFiles{}
+	}
+	return w.Files
+}
+
+// EditJSONFile applies edit to the JSON object in path and writes it back
+// indented, creating the file when it does not exist. Members edit does not
+// set keep their order and exact values. A file that is not a JSON object is
+// left alone and reported as ErrUnknownLayout.
+func EditJSONFile(files Files, path string, edit func(*jsonedit.Object) error) error {
+	doc := jsonedit.NewObject()
+	data, err := files.ReadFile(path)
+	switch {
+	case err == nil:
+		if doc, err = jsonedit.Parse(data); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrUnknownLayout, path, err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	if err := edit(doc); err != nil {
+		return err
+	}
+
+	out, err := doc.Indent()
+	if err != nil {
+		return err
+	}
+	return files.WriteFile(path, out, 0644)
+}
@@ -0,0 +1,58 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+)
+
+func TestFilesystemWriterSetEnabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SettingsFile)
+	w := &FilesystemWriter{Path: path}
+
+	// A missing file is created
+	if _, err := w.SetEnabled([]EnabledChange{{Plugin: "a@m", Enabled: true}}); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`{"model": "opus", "enabledPlugins": {"a@m": true}, "cleanupPeriodDays": 30}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []EnabledChange{{Plugin: "a@m", Enabled: false}, {Plugin: "b@m", Enabled: true}}
+	if _, err := w.SetEnabled(changes); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"model\": \"opus\",\n  \"enabledPlugins\": {\n    \"a@m\": false,\n    \"b@m\": true\n  },\n  \"cleanupPeriodDays\": 30\n}\n"
+	if string(data) != want {
+		t.Errorf("settings =\n%s\nwant\n%s", data, want)
+	}
+	if _, err := os.Stat(path + atomicfile.BackupSuffix); err != nil {
+		t.Errorf("previous settings not kept: %v", err)
+	}
+	if got := w.Command(changes[1]); got != `set enabledPlugins["b@m"] = true in `+path {
+		t.Errorf("Command() = %q", got)
+	}
+}
+
+func TestFilesystemWriterUnknownLayout(t *testing.T) {
+	for _, content := range []string{`[]`, `{"enabledPlugins": ["a@m"]}`, `{"enabledPlugins": {"a@m": "yes"}}`} {
+		path := filepath.Join(t.TempDir(), SettingsFile)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		w := &FilesystemWriter{Path: path}
+		if _, err := w.SetEnabled([]EnabledChange{{Plugin: "b@m", Enabled: true}}); !errors.Is(err, ErrUnknownLayout) {
+			t.Errorf("SetEnabled(%s) error = %v, want ErrUnknownLayout", content, err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Errorf("settings changed to %s", data)
+		}
+	}
+}
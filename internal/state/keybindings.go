@@ -0,0 +1,143 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/adamancini/clew/internal/jsonedit"
+	"github.com/adamancini/clew/internal/logging"
+)
+
+// KeybindingsFile holds Claude Code's custom key bindings, in the Claude
+// directory.
+const KeybindingsFile = "keybindings.json"
+
+// Keybindings maps a context (such as "Chat" or "Global") to its bindings,
+// key to action. An empty action unbinds the key.
+type Keybindings map[string]map[string]string
+
+// fsKeybindings is the layout of keybindings.json.
+type fsKeybindings struct {
+	Bindings []struct {
+		Context  string             `json:"context"`
+		Bindings map[string]*string `json:"bindings"`
+	} `json:"bindings"`
+}
+
+// ReadKeybindings reads the custom key bindings in path. A missing file
+// has none, and yields nil without an error.
+func ReadKeybindings(path string) (Keybindings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			logging.Tracef("state: %s not found", path)
+			return nil, nil
+		}
+		return nil, err
+	}
+	logging.Tracef("state: read %s (%d bytes)\n%s", path, len(data), data)
+	return ParseKeybindings(data)
+}
+
+// ParseKeybindings parses the contents of keybindings.json. A context
+// listed more than once has its bindings merged, later ones winning.
+func ParseKeybindings(data []byte) (Keybindings, error) {
+	var file fsKeybindings
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", KeybindingsFile, err)
+	}
+
+	bindings := make(Keybindings)
+	for _, block := range file.Bindings {
+		if block.Context == "" {
+			continue
+		}
+		if bindings[block.Context] == nil {
+			bindings[block.Context] = make(map[string]string)
+		}
+		for key, action := range block.Bindings {
+			if action == nil {
+				bindings[block.Context][key] = ""
+			} else {
+				bindings[block.Context][key] = *action
+			}
+		}
+	}
+	return bindings, nil
+}
+
+// SetKeybindings sets each binding in changes in a keybindings.json
+// document, for use with EditJSONFile. Other contexts, bindings and
+// top-level fields are kept; a context that is not in the document yet is
+// added at the end. An empty action is written as null, which unbinds the
+// key.
+func SetKeybindings(doc *jsonedit.Object, changes Keybindings) error {
+	var blocks []json.RawMessage
+	if raw, ok := doc.Get("bindings"); ok && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &blocks); err != nil {
+			return fmt.Errorf("%w: %s: bindings is not a list", ErrUnknownLayout, KeybindingsFile)
+		}
+	}
+
+	for _, context := range slices.Sorted(maps.Keys(changes)) {
+		// Later blocks win, so change the last one for the context
+		last := -1
+		var block *jsonedit.Object
+		for i, raw := range blocks {
+			b, err := jsonedit.Parse(raw)
+			if err != nil {
+				return fmt.Errorf("%w: %s: binding block is not an object", ErrUnknownLayout, KeybindingsFile)
+			}
+			var blockContext string
+			if _, err := b.Decode("context", &blockContext); err == nil && blockContext == context {
+				last, block = i, b
+			}
+		}
+		if block == nil {
+			block = jsonedit.NewObject()
+			if err := block.Set("context", context); err != nil {
+				return err
+			}
+		}
+		bindings, err := block.Object("bindings")
+		if err != nil {
+			bindings = jsonedit.NewObject()
+		}
+		for _, key := range slices.Sorted(maps.Keys(changes[context])) {
+			var action any
+			if a := changes[context][key]; a != "" {
+				action = a
+			}
+			if err := bindings.Set(key, action); err != nil {
+				return err
+			}
+		}
+		if err := block.Set("bindings", bindings); err != nil {
+			return err
+		}
+		raw, err := jsonedit.Marshal(block)
+		if err != nil {
+			return err
+		}
+		if last < 0 {
+			blocks = append(blocks, raw)
+		} else {
+			blocks[last] = raw
+		}
+	}
+	return doc.Set("bindings", blocks)
+}
+
+// readKeybindings reads keybindings.json in claudeDir into state.
+func (r *FilesystemReader) readKeybindings(claudeDir string, state *State) error {
+	bindings, err := ReadKeybindings(filepath.Join(claudeDir, KeybindingsFile))
+	if err != nil {
+		return err
+	}
+	state.Keybindings = bindings
+	return nil
+}
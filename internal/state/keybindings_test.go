@@ -0,0 +1,78 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adamancini/clew/internal/jsonedit"
+)
+
+func TestParseKeybindings(t *testing.T) {
+	data := []byte(`{
+  "$schema": "https://example.com/keybindings.json",
+  "bindings": [
+    {"context": "Chat", "bindings": {"ctrl+e": "chat:externalEditor"}},
+    {"context": "Global", "bindings": {"ctrl+t": null}},
+    {"context": "Chat", "bindings": {"ctrl+e": "chat:submit", "ctrl+g": "chat:stash"}}
+  ]
+}`)
+	got, err := ParseKeybindings(data)
+	if err != nil {
+		t.Fatalf("ParseKeybindings() error = %v", err)
+	}
+	want := Keybindings{
+		"Chat":   {"ctrl+e": "chat:submit", "ctrl+g": "chat:stash"},
+		"Global": {"ctrl+t": ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseKeybindings() = %v, want %v", got, want)
+	}
+
+	if _, err := ParseKeybindings([]byte(`{"bindings": {}}`)); err == nil {
+		t.Error("ParseKeybindings() with bindings as an object should fail")
+	}
+}
+
+func TestSetKeybindings(t *testing.T) {
+	doc, err := jsonedit.Parse([]byte(`{
+  "$schema": "https://example.com/keybindings.json",
+  "bindings": [
+    {"context": "Chat", "bindings": {"ctrl+e": "chat:externalEditor", "ctrl+x": "chat:cancel"}}
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := Keybindings{
+		"Chat":   {"ctrl+e": "chat:submit"},
+		"Global": {"ctrl+t": ""},
+	}
+	if err := SetKeybindings(doc, changes); err != nil {
+		t.Fatalf("SetKeybindings() error = %v", err)
+	}
+	if raw, _ := doc.Get("$schema"); string(raw) != `"https://example.com/keybindings.json"` {
+		t.Errorf("$schema = %s, want it kept", raw)
+	}
+
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseKeybindings(data)
+	if err != nil {
+		t.Fatalf("ParseKeybindings() error = %v", err)
+	}
+	want := Keybindings{
+		"Chat":   {"ctrl+e": "chat:submit", "ctrl+x": "chat:cancel"},
+		"Global": {"ctrl+t": ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("after SetKeybindings = %v, want %v", got, want)
+	}
+
+	bad, _ := jsonedit.Parse([]byte(`{"bindings": "nope"}`))
+	if err := SetKeybindings(bad, changes); err == nil {
+		t.Error("SetKeybindings() with bindings as a string should fail")
+	}
+}
@@ -0,0 +1,234 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/adamancini/clew/internal/logging"
+)
+
+// KnownMarketplacesFile lists the installed marketplaces, in the plugins
+// directory.
+const KnownMarketplacesFile = "known_marketplaces.json"
+
+// Layouts of known_marketplaces.json, in the order they are tried.
+const (
+	// LayoutV2 wraps the marketplaces in a versioned object, as
+	// installed_plugins.json already is: {"version": 2, "marketplaces": {...}}.
+	LayoutV2 = "v2"
+	// LayoutV1 is an object keyed by alias, with no version.
+	LayoutV1 = "v1"
+	// LayoutBestEffort is anything else that still names marketplaces: a
+	// list, a newer version, or entries in an unexpected shape.
+	LayoutBestEffort = "best-effort"
+)
+
+// Fields of known_marketplaces.json clew knows. Others are reported, so a
+// layout change upstream shows up as a warning rather than silently
+// missing data.
+var (
+	knownMarketplacesTopFields   = []string{"version", "marketplaces"}
+	knownMarketplacesEntryFields = []string{"source", "installLocation", "lastUpdated", "autoUpdate"}
+)
+
+// MarketplacesLayout describes how known_marketplaces.json was decoded.
+type MarketplacesLayout struct {
+	Layout  string   `json:"layout" yaml:"layout"`
+	Version int      `json:"version,omitempty" yaml:"version,omitempty"` // Declared version, 0 if none
+	Unknown []string `json:"unknown_fields,omitempty" yaml:"unknown_fields,omitempty"`
+}
+
+// Adapted reports whether clew had to guess at any of the file: it was read
+// best-effort or has fields clew does not know.
+func (l MarketplacesLayout) Adapted() bool {
+	return l.Layout == LayoutBestEffort || len(l.Unknown) > 0
+}
+
+// DecodeKnownMarketplaces decodes known_marketplaces.json, trying the v2
+// layout, then v1, then a best-effort read of whatever marketplaces it can
+// find. It fails only when none of them yields marketplaces.
+func DecodeKnownMarketplaces(data []byte) (map[string]MarketplaceState, MarketplacesLayout, error) {
+	// A numeric top-level version marks a versioned layout; in v1 every
+	// top-level value is a marketplace entry
+	var top map[string]json.RawMessage
+	_ = json.Unmarshal(data, &top)
+	var version int
+	versioned := json.Unmarshal(top["version"], &version) == nil && top["version"] != nil
+	switch {
+	case versioned && version == 2:
+		if marketplaces, unknown, err := decodeMarketplaceEntries(top["marketplaces"]); err == nil {
+			unknown = append(unknownFields(top, knownMarketplacesTopFields, ""), unknown...)
+			return marketplaces, MarketplacesLayout{Layout: LayoutV2, Version: version, Unknown: unknown}, nil
+		}
+	case !versioned && top != nil:
+		if marketplaces, unknown, err := decodeMarketplaceEntries(data); err == nil {
+			return marketplaces, MarketplacesLayout{Layout: LayoutV1, Unknown: unknown}, nil
+		}
+	}
+
+	// A version clew does not know, or entries in another shape: take what
+	// the lenient parser can find
+	marketplaces, err := parseMarketplacesJSON(data)
+	if err != nil {
+		return nil, MarketplacesLayout{}, fmt.Errorf("failed to parse %s: %w", KnownMarketplacesFile, err)
+	}
+	return marketplaces, MarketplacesLayout{Layout: LayoutBestEffort, Version: version}, nil
+}
+
+// decodeMarketplaceEntries decodes an object of v1 entries keyed by alias,
+// returning the entry fields it does not know as "alias.field".
+func decodeMarketplaceEntries(data []byte) (map[string]MarketplaceState, []string, error) {
+	var raw map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, err
+	}
+	var entries map[string]fsMarketplaceEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, nil, err
+	}
+
+	var unknown []string
+	marketplaces := make(map[string]MarketplaceState, len(entries))
+	for _, alias := range slices.Sorted(maps.Keys(entries)) {
+		m := entries[alias]
+		unknown = append(unknown, unknownFields(raw[alias], knownMarketplacesEntryFields, alias+".")...)
+		logging.Tracef("state: marketplace %s -> repo=%q source=%s location=%s", alias, m.Source.Repo, m.Source.Source, m.InstallLocation)
+		marketplaces[alias] = MarketplaceState{
+			Alias:           alias,
+			Repo:            m.Source.Repo,
+			InstallLocation: m.InstallLocation,
+			LastUpdated:     m.LastUpdated,
+		}
+	}
+	return marketplaces, unknown, nil
+}
+
+// unknownFields returns the keys of obj not in known, sorted and prefixed.
+func unknownFields(obj map[string]json.RawMessage, known []string, prefix string) []string {
+	var unknown []string
+	for key := range obj {
+		if !slices.Contains(known, key) {
+			unknown = append(unknown, prefix+key)
+		}
+	}
+	slices.Sort(unknown)
+	return unknown
+}
+
+// warnedLayouts holds the files a layout warning was printed for, so a
+// command that reads state several times warns once.
+var warnedLayouts sync.Map
+
+// warnLayout prints one warning for a file read with an adapted layout.
+func warnLayout(path string, layout MarketplacesLayout) {
+	if _, warned := warnedLayouts.LoadOrStore(path, true); warned {
+		return
+	}
+	var detail string
+	switch {
+	case layout.Layout == LayoutBestEffort && layout.Version != 0:
+		detail = fmt.Sprintf("unknown layout version %d", layout.Version)
+	case layout.Layout == LayoutBestEffort:
+		detail = "unrecognised layout"
+	}
+	if len(layout.Unknown) > 0 {
+		if detail != "" {
+			detail += ", "
+		}
+		detail += "unknown fields: " + strings.Join(layout.Unknown, ", ")
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %s has changed (%s); read what clew understands. Run 'clew state dump --raw' to inspect it.\n", filepath.Base(path), detail)
+}
+
+// readMarketplaces reads known_marketplaces.json in claudeDir into state,
+// adapting to layout changes with a warning.
+func (r *FilesystemReader) readMarketplaces(claudeDir string, state *State) error {
+	path := filepath.Join(claudeDir, "plugins", KnownMarketplacesFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			logging.Tracef("state: %s not found", path)
+			return nil // No marketplaces file is okay
+		}
+		return err
+	}
+	logging.Tracef("state: read %s (%d bytes)\n%s", path, len(data), data)
+
+	marketplaces, layout, err := DecodeKnownMarketplaces(data)
+	if err != nil {
+		return err
+	}
+	logging.Tracef("state: %s layout %s", KnownMarketplacesFile, layout.Layout)
+	if layout.Adapted() {
+		warnLayout(path, layout)
+	}
+	for alias, m := range marketplaces {
+		state.Marketplaces[alias] = m
+	}
+	return nil
+}
+
+// lenientMarketplace is a marketplace entry in any of the shapes
+// parseMarketplacesJSON accepts.
+type lenientMarketplace struct {
+	Name            string          `json:"name"`
+	Repo            string          `json:"repo"`
+	Source          json.RawMessage `json:"source"` // "owner/repo" or {"source": "github", "repo": ...}
+	InstallLocation string          `json:"installLocation"`
+	LastUpdated     string          `json:"lastUpdated"`
+}
+
+// parseMarketplacesJSON accepts a list of marketplaces, an object keyed by
+// alias (the known_marketplaces.json layout), or either wrapped in
+// {"marketplaces": ...}.
+func parseMarketplacesJSON(data []byte) (map[string]MarketplaceState, error) {
+	var wrapped struct {
+		Marketplaces json.RawMessage `json:"marketplaces"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err == nil && len(wrapped.Marketplaces) > 0 {
+		data = wrapped.Marketplaces
+	}
+
+	var list []lenientMarketplace
+	if err := json.Unmarshal(data, &list); err != nil {
+		var byAlias map[string]lenientMarketplace
+		if err := json.Unmarshal(data, &byAlias); err != nil {
+			return nil, fmt.Errorf("unrecognized marketplace JSON: %w", err)
+		}
+		for alias, m := range byAlias {
+			m.Name = alias
+			list = append(list, m)
+		}
+	}
+
+	result := make(map[string]MarketplaceState, len(list))
+	for _, m := range list {
+		if m.Name == "" {
+			return nil, fmt.Errorf("marketplace entry has no name")
+		}
+		repo := m.Repo
+		if repo == "" && len(m.Source) > 0 {
+			var source struct {
+				Repo string `json:"repo"`
+			}
+			if err := json.Unmarshal(m.Source, &source); err == nil {
+				repo = source.Repo
+			} else {
+				_ = json.Unmarshal(m.Source, &repo)
+			}
+		}
+		result[m.Name] = MarketplaceState{
+			Alias:           m.Name,
+			Repo:            repo,
+			InstallLocation: m.InstallLocation,
+			LastUpdated:     m.LastUpdated,
+		}
+	}
+	return result, nil
+}
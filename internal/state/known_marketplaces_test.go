@@ -0,0 +1,86 @@
+package state
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDecodeKnownMarketplaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		layout   string
+		version  int
+		unknown  []string
+		wantRepo string
+	}{
+		{
+			name:     "v1",
+			data:     `{"acme": {"source": {"source": "github", "repo": "acme/plugins"}, "installLocation": "/m/acme", "autoUpdate": true}}`,
+			layout:   LayoutV1,
+			wantRepo: "acme/plugins",
+		},
+		{
+			name:     "v1 with new entry fields",
+			data:     `{"acme": {"source": {"source": "github", "repo": "acme/plugins"}, "pinned": true, "trust": "high"}}`,
+			layout:   LayoutV1,
+			unknown:  []string{"acme.pinned", "acme.trust"},
+			wantRepo: "acme/plugins",
+		},
+		{
+			name:     "v2",
+			data:     `{"version": 2, "marketplaces": {"acme": {"source": {"source": "github", "repo": "acme/plugins"}}}}`,
+			layout:   LayoutV2,
+			version:  2,
+			wantRepo: "acme/plugins",
+		},
+		{
+			name:     "v2 with new top-level field",
+			data:     `{"version": 2, "updatedAt": "2026-01-01", "marketplaces": {"acme": {"source": {"source": "github", "repo": "acme/plugins"}}}}`,
+			layout:   LayoutV2,
+			version:  2,
+			unknown:  []string{"updatedAt"},
+			wantRepo: "acme/plugins",
+		},
+		{
+			name:     "unknown version",
+			data:     `{"version": 3, "marketplaces": [{"name": "acme", "source": {"repo": "acme/plugins"}}]}`,
+			layout:   LayoutBestEffort,
+			version:  3,
+			wantRepo: "acme/plugins",
+		},
+		{
+			name:     "list",
+			data:     `[{"name": "acme", "repo": "acme/plugins"}]`,
+			layout:   LayoutBestEffort,
+			wantRepo: "acme/plugins",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marketplaces, layout, err := DecodeKnownMarketplaces([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("DecodeKnownMarketplaces() error = %v", err)
+			}
+			if layout.Layout != tt.layout || layout.Version != tt.version {
+				t.Errorf("layout = %+v, want %s version %d", layout, tt.layout, tt.version)
+			}
+			if !slices.Equal(layout.Unknown, tt.unknown) {
+				t.Errorf("unknown = %v, want %v", layout.Unknown, tt.unknown)
+			}
+			if got := marketplaces["acme"]; got.Alias != "acme" || got.Repo != tt.wantRepo {
+				t.Errorf("acme = %+v, want repo %s", got, tt.wantRepo)
+			}
+			if want := tt.layout == LayoutBestEffort || len(tt.unknown) > 0; layout.Adapted() != want {
+				t.Errorf("Adapted() = %t, want %t", layout.Adapted(), want)
+			}
+		})
+	}
+}
+
+func TestDecodeKnownMarketplacesInvalid(t *testing.T) {
+	if _, _, err := DecodeKnownMarketplaces([]byte(`{not json`)); err == nil {
+		t.Error("expected an error for malformed JSON")
+	}
+}
@@ -0,0 +1,132 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/adamancini/clew/internal/logging"
+)
+
+// ManagedSettingsFile is the name of the enterprise policy file. Its
+// settings take precedence over every user and local settings file.
+const ManagedSettingsFile = "managed-settings.json"
+
+// ManagedPolicy is the subset of an enterprise managed-settings.json that
+// constrains plugins and marketplaces.
+type ManagedPolicy struct {
+	Path string `json:"path" yaml:"path"`
+
+	// EnabledPlugins force-enables (true) or force-disables (false) plugins
+	EnabledPlugins map[string]bool `json:"enabled_plugins,omitempty" yaml:"enabled_plugins,omitempty"`
+	// BlockedMarketplaces lists marketplace aliases or repos that may not be added
+	BlockedMarketplaces []string `json:"blocked_marketplaces,omitempty" yaml:"blocked_marketplaces,omitempty"`
+	// AllowedMarketplaces, when non-nil, is the only set of marketplace repos
+	// that may be added (strictKnownMarketplaces)
+	AllowedMarketplaces []string `json:"allowed_marketplaces,omitempty" yaml:"allowed_marketplaces,omitempty"`
+}
+
+// fsManagedSettings represents the relevant parts of managed-settings.json.
+// Marketplace lists accept either "owner/repo" strings or Claude's source
+// objects ({"source": "github", "repo": "owner/repo"}).
+type fsManagedSettings struct {
+	EnabledPlugins          map[string]bool   `json:"enabledPlugins"`
+	BlockedMarketplaces     []json.RawMessage `json:"blockedMarketplaces"`
+	StrictKnownMarketplaces []json.RawMessage `json:"strictKnownMarketplaces"`
+}
+
+// DefaultManagedSettingsPath returns the system-wide managed settings
+// location for the current platform.
+func DefaultManagedSettingsPath() string {
+	if runtime.GOOS == "darwin" {
+		return "/Library/Application Support/ClaudeCode/" + ManagedSettingsFile
+	}
+	return "/etc/claude-code/" + ManagedSettingsFile
+}
+
+// ReadManagedPolicy reads the managed settings file at path. It returns nil
+// without error if the file does not exist.
+func ReadManagedPolicy(path string) (*ManagedPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			logging.Tracef("state: %s not found", path)
+			return nil, nil
+		}
+		return nil, err
+	}
+	logging.Tracef("state: read %s (%d bytes)\n%s", path, len(data), data)
+
+	var settings fsManagedSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManagedSettingsFile, err)
+	}
+
+	policy := &ManagedPolicy{
+		Path:                path,
+		EnabledPlugins:      settings.EnabledPlugins,
+		BlockedMarketplaces: marketplaceRefs(settings.BlockedMarketplaces),
+	}
+	if settings.StrictKnownMarketplaces != nil {
+		policy.AllowedMarketplaces = marketplaceRefs(settings.StrictKnownMarketplaces)
+	}
+	return policy, nil
+}
+
+// marketplaceRefs flattens marketplace list entries to alias/repo strings.
+func marketplaceRefs(raw []json.RawMessage) []string {
+	refs := make([]string, 0, len(raw))
+	for _, r := range raw {
+		var s string
+		if err := json.Unmarshal(r, &s); err == nil {
+			refs = append(refs, s)
+			continue
+		}
+		var obj struct {
+			Repo string `json:"repo"`
+			URL  string `json:"url"`
+		}
+		if err := json.Unmarshal(r, &obj); err == nil {
+			switch {
+			case obj.Repo != "":
+				refs = append(refs, obj.Repo)
+			case obj.URL != "":
+				refs = append(refs, obj.URL)
+			}
+		}
+	}
+	return refs
+}
+
+// ForcedPluginState reports whether the policy forces a plugin's enabled
+// state, and to what.
+func (p *ManagedPolicy) ForcedPluginState(name string) (enabled, forced bool) {
+	if p == nil {
+		return false, false
+	}
+	enabled, forced = p.EnabledPlugins[name]
+	return enabled, forced
+}
+
+// MarketplaceBlocked reports whether the policy forbids adding a marketplace.
+func (p *ManagedPolicy) MarketplaceBlocked(alias, repo string) bool {
+	if p == nil {
+		return false
+	}
+	for _, b := range p.BlockedMarketplaces {
+		if strings.EqualFold(b, alias) || (repo != "" && strings.EqualFold(b, repo)) {
+			return true
+		}
+	}
+	if p.AllowedMarketplaces != nil {
+		for _, a := range p.AllowedMarketplaces {
+			if repo != "" && strings.EqualFold(a, repo) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
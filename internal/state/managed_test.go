@@ -0,0 +1,93 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadManagedPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ManagedSettingsFile)
+	content := `{
+  "enabledPlugins": {"security@corp": true, "risky@public": false},
+  "blockedMarketplaces": ["untrusted", {"source": "github", "repo": "evil/plugins"}],
+  "strictKnownMarketplaces": [{"source": "github", "repo": "corp/plugins"}],
+  "permissions": {"deny": ["Bash"]}
+}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := ReadManagedPolicy(path)
+	if err != nil {
+		t.Fatalf("ReadManagedPolicy() error = %v", err)
+	}
+
+	if enabled, forced := policy.ForcedPluginState("risky@public"); !forced || enabled {
+		t.Errorf("risky@public forced=%v enabled=%v, want forced disabled", forced, enabled)
+	}
+	if _, forced := policy.ForcedPluginState("other@public"); forced {
+		t.Error("other@public should not be forced")
+	}
+	if want := []string{"untrusted", "evil/plugins"}; !reflect.DeepEqual(policy.BlockedMarketplaces, want) {
+		t.Errorf("BlockedMarketplaces = %v, want %v", policy.BlockedMarketplaces, want)
+	}
+
+	tests := []struct {
+		alias, repo string
+		want        bool
+	}{
+		{"corp", "corp/plugins", false},
+		{"untrusted", "corp/plugins", true},
+		{"x", "evil/plugins", true},
+		{"public", "someone/else", true}, // not in strictKnownMarketplaces
+	}
+	for _, tt := range tests {
+		if got := policy.MarketplaceBlocked(tt.alias, tt.repo); got != tt.want {
+			t.Errorf("MarketplaceBlocked(%q, %q) = %v, want %v", tt.alias, tt.repo, got, tt.want)
+		}
+	}
+}
+
+func TestReadManagedPolicyMissing(t *testing.T) {
+	policy, err := ReadManagedPolicy(filepath.Join(t.TempDir(), ManagedSettingsFile))
+	if err != nil || policy != nil {
+		t.Errorf("ReadManagedPolicy(missing) = %v, %v; want nil, nil", policy, err)
+	}
+
+	var nilPolicy *ManagedPolicy
+	if nilPolicy.MarketplaceBlocked("a", "b/c") {
+		t.Error("nil policy should not block")
+	}
+}
+
+func TestFilesystemReaderManagedOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	if err := os.MkdirAll(filepath.Join(claudeDir, "plugins"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(claudeDir, "plugins", "installed_plugins.json"),
+		[]byte(`{"version": 2, "plugins": {"a@m": [{"scope": "user", "installPath": "/a"}]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(claudeDir, SettingsFile), []byte(`{"enabledPlugins": {"a@m": true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	managedPath := filepath.Join(tmpDir, ManagedSettingsFile)
+	if err := os.WriteFile(managedPath, []byte(`{"enabledPlugins": {"a@m": false}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := (&FilesystemReader{ClaudeDir: claudeDir, ManagedSettingsPath: managedPath}).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Managed == nil {
+		t.Fatal("expected managed policy to be recorded")
+	}
+	if p := s.Plugins["a@m"]; p.Enabled || p.EnabledSource != ManagedSettingsFile {
+		t.Errorf("a@m = %+v, want disabled by managed settings", p)
+	}
+}
@@ -5,6 +5,8 @@ package state
 type State struct {
 	Marketplaces map[string]MarketplaceState
 	Plugins      map[string]PluginState
+	Managed      *ManagedPolicy `json:",omitempty"` // Enterprise policy, nil if none
+	Keybindings  Keybindings    `json:",omitempty"` // Custom key bindings, nil if there is no keybindings.json
 }
 
 // MarketplaceState represents a marketplace's current state.
@@ -18,22 +20,92 @@ type MarketplaceState struct {
 
 // PluginState represents a plugin's current state.
 type PluginState struct {
-	Name         string
-	Marketplace  string
-	Scope        string
-	Enabled      bool
-	Version      string
-	InstallPath  string
-	IsLocal      bool   // True for local repository plugins (not marketplace)
-	GitCommitSha string // Git commit SHA for the plugin
+	Name          string
+	Marketplace   string
+	Scope         string
+	Enabled       bool
+	Version       string
+	InstallPath   string
+	IsLocal       bool            // True for local repository plugins (not marketplace)
+	GitCommitSha  string          // Git commit SHA for the plugin
+	LastUpdated   string          // Last install/update timestamp
+	EnabledSource string          // Settings file the enabled state came from (empty if defaulted)
+	Installs      []PluginInstall // Every recorded install, most recent first
 }
 
+// InstalledAt reports whether the plugin has an install at scope. State read
+// without install entries falls back to Scope, where empty means user.
+func (p PluginState) InstalledAt(scope string) bool {
+	if len(p.Installs) == 0 {
+		return p.Scope == scope || (p.Scope == "" && scope == "user")
+	}
+	for _, i := range p.Installs {
+		if i.Scope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AtScope returns the plugin as installed at scope for projectPath (empty
+// for user scope), with Scope, Version and InstallPath taken from that
+// install, and reports whether there is such an install.
+func (p PluginState) AtScope(scope, projectPath string) (PluginState, bool) {
+	if len(p.Installs) == 0 {
+		return p, p.InstalledAt(scope) && projectPath == ""
+	}
+	for _, i := range p.Installs {
+		if i.Scope == scope && i.ProjectPath == projectPath {
+			p.Scope = i.Scope
+			p.Version = i.Version
+			p.InstallPath = i.InstallPath
+			return p, true
+		}
+	}
+	return p, false
+}
+
+// PluginInstall is one entry in installed_plugins.json. A plugin can be
+// installed more than once, e.g. at user scope and for individual projects.
+type PluginInstall struct {
+	Scope       string
+	ProjectPath string // Project the install belongs to, for project/local scope
+	InstallPath string
+	Version     string
+}
+
+// Settings files that can carry enabledPlugins, lowest precedence first.
+const (
+	SettingsFile      = "settings.json"
+	SettingsLocalFile = "settings.local.json"
+)
+
 // Reader defines the interface for reading current state.
 type Reader interface {
 	Read() (*State, error)
 }
 
+// Writer changes which plugins are enabled. Like Reader it has two
+// implementations: FilesystemWriter edits a settings file and CLIWriter runs
+// claude plugin enable/disable. Callers choose one per change from the
+// claude CLI's capabilities and where the change should be written.
+type Writer interface {
+	// SetEnabled applies changes in order. Output is what the CLI printed
+	// (nil for FilesystemWriter), returned even when err is set.
+	SetEnabled(changes []EnabledChange) (output []byte, err error)
+
+	// Command describes how a change is applied, for dry runs and output.
+	Command(change EnabledChange) string
+}
+
+// EnabledChange sets one plugin's enabled state.
+type EnabledChange struct {
+	Plugin  string // plugin@marketplace
+	Enabled bool
+}
+
 // FilesystemReader reads state directly from Claude Code's files.
 type FilesystemReader struct {
-	ClaudeDir string // typically ~/.claude
+	ClaudeDir           string // typically ~/.claude
+	ManagedSettingsPath string // defaults to DefaultManagedSettingsPath()
 }
@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// flip is an enable/disable change held back to be written in one batch
+// (Options.DirectSettings).
+type flip struct {
+	plugin diff.PluginDiff
+	policy string // on_failure policy
+	count  bool   // Counts toward Updated/Skipped (false for disable-after-install)
+}
+
+// applyFlips writes the batched enable/disable changes with one edit per
+// settings file. When settings.json has a layout clew does not recognise,
+// its changes fall back to one claude command per plugin.
+func (s *Syncer) applyFlips(flips []flip, opts Options, result *Result) {
+	result.Interrupted = result.Interrupted || s.interrupted()
+	if result.stopped() {
+		for _, f := range flips {
+			s.skipAborted(result, "plugin", f.plugin.Name, string(f.plugin.Action))
+		}
+		return
+	}
+
+	byFile := make(map[string][]flip)
+	for _, f := range flips {
+		file := state.SettingsFile
+		if WritesLocalSettings(f.plugin, opts.SettingsTarget) {
+			file = state.SettingsLocalFile
+		}
+		byFile[file] = append(byFile[file], f)
+	}
+
+	for _, file := range []string{state.SettingsFile, state.SettingsLocalFile} {
+		group := byFile[file]
+		if len(group) == 0 {
+			continue
+		}
+		for _, f := range group {
+			s.started("plugin", f.plugin.Name, string(f.plugin.Action))
+		}
+		ops, err := s.setSettingsEnabledBatch(file, group)
+		if errors.Is(err, state.ErrUnknownLayout) && file == state.SettingsFile {
+			logging.Decisionf("Not editing %s directly (%v); using claude plugin enable/disable", file, err)
+			for _, f := range group {
+				op, err := s.attempt(f.policy, "plugin", f.plugin.Name, string(f.plugin.Action), func() (Operation, error) {
+					return s.updatePluginState(f.plugin, SettingsTargetUser)
+				})
+				s.recordFlip(result, f, op, err)
+			}
+			continue
+		}
+		for i, f := range group {
+			s.recordFlip(result, f, ops[i], err)
+		}
+	}
+}
+
+// recordFlip adds the outcome of a batched enable/disable change to r.
+func (s *Syncer) recordFlip(r *Result, f flip, op Operation, err error) {
+	s.record(r, op)
+	switch {
+	case err != nil:
+		r.fail(err, f.policy, "plugin "+f.plugin.Name)
+	case !f.count:
+	case op.Skipped:
+		r.Skipped++
+	default:
+		r.Updated++
+	}
+}
+
+// setSettingsEnabledBatch sets enabledPlugins for every change in flips
+// with a single write of the named settings file, preserving every other
+// setting. It returns one operation per change; on error they all failed.
+func (s *Syncer) setSettingsEnabledBatch(file string, flips []flip) ([]Operation, error) {
+	w := s.settingsWriter(file)
+	changes := make([]state.EnabledChange, len(flips))
+	ops := make([]Operation, len(flips))
+	for i, f := range flips {
+		changes[i] = state.EnabledChange{Plugin: f.plugin.Name, Enabled: f.plugin.Action == diff.ActionEnable}
+		ops[i] = Operation{
+			Type:        "plugin",
+			Name:        f.plugin.Name,
+			Action:      string(f.plugin.Action),
+			Command:     w.Command(changes[i]),
+			Description: fmt.Sprintf("%s plugin: %s (batched settings edit)", stateVerb(f.plugin.Action), f.plugin.Name),
+		}
+	}
+
+	_, err := w.SetEnabled(changes)
+	for i := range ops {
+		ops[i].Success = err == nil
+		if err != nil {
+			ops[i].Error = fmt.Sprintf("failed to %s plugin %s: %v", ops[i].Action, ops[i].Name, err)
+		}
+	}
+	return ops, err
+}
+
+// stateVerb returns "Enable" or "Disable" for an enable/disable action.
+func stateVerb(action diff.Action) string {
+	if action == diff.ActionEnable {
+		return "Enable"
+	}
+	return "Disable"
+}
@@ -0,0 +1,104 @@
+package sync
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// countingEditor counts the writes to a MockFileEditor.
+type countingEditor struct {
+	MockFileEditor
+	writes int
+}
+
+func (e *countingEditor) WriteFile(path string, data []byte, perm os.FileMode) error {
+	e.writes++
+	return e.MockFileEditor.WriteFile(path, data, perm)
+}
+
+func flipDiff() *diff.Result {
+	disabled := false
+	return &diff.Result{
+		Plugins: []diff.PluginDiff{
+			{Name: "new@tools", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "new@tools", Enabled: &disabled}},
+			{Name: "lint@tools", Action: diff.ActionEnable, Current: &state.PluginState{EnabledSource: state.SettingsFile}},
+			{Name: "test@tools", Action: diff.ActionDisable, Current: &state.PluginState{EnabledSource: state.SettingsFile}},
+		},
+	}
+}
+
+func TestExecuteDirectSettings(t *testing.T) {
+	path := "/home/test/.claude/" + state.SettingsFile
+	editor := &countingEditor{MockFileEditor: MockFileEditor{Files: map[string][]byte{
+		path: []byte(`{"model": "opus", "enabledPlugins": {"test@tools": true}}`),
+	}}}
+	_, mock := newMockSyncer()
+	syncer := NewSyncerWithRunnerAndEditor(mock, editor, "/home/test/.claude")
+
+	result, err := syncer.Execute(flipDiff(), Options{DirectSettings: true})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if got := strings.Join(mock.Commands, "\n"); got != "claude plugin install new@tools --scope user" {
+		t.Errorf("Commands = %q, want only the install", mock.Commands)
+	}
+	if editor.writes != 1 {
+		t.Errorf("settings.json written %d times, want 1", editor.writes)
+	}
+	var settings struct {
+		Model          string          `json:"model"`
+		EnabledPlugins map[string]bool `json:"enabledPlugins"`
+	}
+	if err := json.Unmarshal(editor.Files[path], &settings); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"new@tools": false, "lint@tools": true, "test@tools": false}
+	for name, enabled := range want {
+		if settings.EnabledPlugins[name] != enabled {
+			t.Errorf("enabledPlugins[%s] = %t, want %t", name, settings.EnabledPlugins[name], enabled)
+		}
+	}
+	if settings.Model != "opus" {
+		t.Errorf("model = %q, want other settings kept", settings.Model)
+	}
+	if result.Installed != 1 || result.Updated != 2 || result.Failed != 0 {
+		t.Errorf("Installed/Updated/Failed = %d/%d/%d, want 1/2/0", result.Installed, result.Updated, result.Failed)
+	}
+}
+
+func TestExecuteDirectSettingsUnknownLayout(t *testing.T) {
+	path := "/home/test/.claude/" + state.SettingsFile
+	editor := &countingEditor{MockFileEditor: MockFileEditor{Files: map[string][]byte{
+		path: []byte(`{"enabledPlugins": ["lint@tools"]}`),
+	}}}
+	_, mock := newMockSyncer()
+	syncer := NewSyncerWithRunnerAndEditor(mock, editor, "/home/test/.claude")
+
+	result, err := syncer.Execute(flipDiff(), Options{DirectSettings: true})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := []string{
+		"claude plugin install new@tools --scope user",
+		"claude plugin disable new@tools",
+		"claude plugin enable lint@tools",
+		"claude plugin disable test@tools",
+	}
+	if strings.Join(mock.Commands, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Commands = %q, want %q", mock.Commands, want)
+	}
+	if editor.writes != 0 {
+		t.Errorf("settings.json written %d times, want 0", editor.writes)
+	}
+	if result.Failed != 0 {
+		t.Errorf("Failed = %d, want 0", result.Failed)
+	}
+}
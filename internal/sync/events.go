@@ -0,0 +1,56 @@
+package sync
+
+import "time"
+
+// EventKind says what happened to an operation.
+type EventKind string
+
+// Kinds of Event.
+const (
+	OperationStarted  EventKind = "started"
+	OperationFinished EventKind = "finished"
+)
+
+// Event reports the progress of one operation while Execute, Revert or
+// RefreshMarketplaces runs, so callers can show progress without waiting for
+// the Result. Every operation in the Result gets a finished event, including
+// skipped ones; operations that run a command get a started event first.
+type Event struct {
+	Kind   EventKind `json:"kind"`
+	Type   string    `json:"type"` // "marketplace" or "plugin"
+	Name   string    `json:"name"`
+	Action string    `json:"action"`
+	Time   time.Time `json:"time"`
+
+	// Operation is the completed operation, on finished events. Its ID is
+	// not set until the run returns.
+	Operation *Operation `json:"operation,omitempty"`
+}
+
+// SetEvents makes the syncer send an Event to ch as each operation starts
+// and finishes. Sends block, so the receiver must keep reading until the
+// run returns; refreshes send from several goroutines at once. nil stops
+// sending.
+func (s *Syncer) SetEvents(ch chan<- Event) {
+	s.events = ch
+}
+
+// started reports that an operation is about to run.
+func (s *Syncer) started(itemType, name, action string) {
+	if s.events != nil {
+		s.events <- Event{Kind: OperationStarted, Type: itemType, Name: name, Action: action, Time: time.Now()}
+	}
+}
+
+// finished reports a completed operation.
+func (s *Syncer) finished(op Operation) {
+	if s.events != nil {
+		s.events <- Event{Kind: OperationFinished, Type: op.Type, Name: op.Name, Action: op.Action, Time: time.Now(), Operation: &op}
+	}
+}
+
+// record adds a completed operation to r and reports it finished.
+func (s *Syncer) record(r *Result, op Operation) {
+	r.add(op)
+	s.finished(op)
+}
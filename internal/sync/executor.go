@@ -2,11 +2,17 @@
 package sync
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/proc"
+	"github.com/adamancini/clew/internal/state"
 )
 
 // CommandRunner is an interface for running external commands.
@@ -15,12 +21,95 @@ type CommandRunner interface {
 	Run(name string, args ...string) ([]byte, error)
 }
 
-// DefaultCommandRunner uses os/exec to run commands.
-type DefaultCommandRunner struct{}
+// DefaultCommandRunner uses os/exec to run commands. Cancelling Ctx (nil
+// means never) stops a running command and the processes it started.
+type DefaultCommandRunner struct {
+	Ctx context.Context
+}
 
 func (r *DefaultCommandRunner) Run(name string, args ...string) ([]byte, error) {
-	cmd := exec.Command(name, args...)
-	return cmd.CombinedOutput()
+	cmd := proc.Command(r.Ctx, name, args...)
+	done := logging.StartCommand(name, args)
+	output, err := cmd.CombinedOutput()
+	done(output, err)
+	return output, err
+}
+
+// MaxOutputBytes caps the command output kept on an Operation. Errors are
+// usually printed last, so the end of the output is kept.
+const MaxOutputBytes = 4096
+
+// captureOutput returns output for Operation.Output, truncated to
+// MaxOutputBytes.
+func captureOutput(output []byte) string {
+	if len(output) <= MaxOutputBytes {
+		return string(output)
+	}
+	dropped := len(output) - MaxOutputBytes
+	return fmt.Sprintf("[... %d bytes truncated]\n%s", dropped, strings.ToValidUTF8(string(output[dropped:]), ""))
+}
+
+// alreadyDonePattern matches a whole line of the messages claude prints
+// when a command asks for a state that already holds, such as re-adding a
+// marketplace that was added outside clew ("Marketplace 'official' already
+// exists", "Plugin x@official is already installed"). Those exit non-zero
+// but leave nothing to do. The first group is the marketplace or plugin.
+var alreadyDonePattern = regexp.MustCompile(`(?i)^(?:error:\s*)?(?:marketplace|plugin)\s+["']?([^\s"']+)["']?\s+(?:is\s+)?already\s+(?:exists|added|installed|enabled|disabled)\.?$`)
+
+// errorLinePattern matches output lines that report a problem.
+var errorLinePattern = regexp.MustCompile(`(?i)\b(error|failed|fatal)\b`)
+
+// alreadyDone turns op into a skipped no-op when output is claude's
+// message that op's item is already in the requested state, with no other
+// error, and reports whether it did.
+func alreadyDone(op Operation, output []byte) (Operation, bool) {
+	line := ""
+	for _, l := range strings.Split(string(output), "\n") {
+		l = strings.TrimSpace(l)
+		switch m := alreadyDonePattern.FindStringSubmatch(l); {
+		case m != nil && m[1] == op.Name && line == "":
+			line = l
+		case errorLinePattern.MatchString(l):
+			return op, false
+		}
+	}
+	if line == "" {
+		return op, false
+	}
+	logging.Decisionf("%s %s: treating %q as a no-op", op.Type, op.Name, line)
+	op.Success = true
+	op.Skipped = true
+	op.Description = fmt.Sprintf("%s (skipped: %s)", op.Description, line)
+	return op, true
+}
+
+// ErrNotInstalled marks an install claude reported as successful that the
+// install verifier (see SetInstallVerifier) did not find.
+var ErrNotInstalled = errors.New("claude reported success but the plugin is not installed")
+
+// ErrNotLoggedIn marks a claude command that failed because the CLI is not
+// logged in or its session expired. Every later command would fail the same
+// way, so the sync stops at the first one.
+var ErrNotLoggedIn = errors.New("claude is not logged in")
+
+// LoginHint tells the user how to log claude back in.
+const LoginHint = "run 'claude' and enter /login, then run clew again"
+
+// notLoggedInPattern matches what claude prints when it has no valid
+// credentials ("Not logged in", "Invalid API key", "OAuth token has
+// expired"), each followed by its /login hint. Auth failures of git or a
+// marketplace host (a 401 from a private repository) do not match: they
+// fail only their own item.
+var notLoggedInPattern = regexp.MustCompile(`(?i)(not logged in|invalid api key|oauth token (has )?expired)\W+please run /login\b`)
+
+// classifyFailure wraps err in ErrNotLoggedIn when output shows the command
+// failed for lack of credentials.
+func classifyFailure(err error, output []byte) error {
+	if !notLoggedInPattern.Match(output) {
+		return err
+	}
+	logging.Decisionf("claude output shows it is not logged in; stopping the sync")
+	return fmt.Errorf("%w (%v)", ErrNotLoggedIn, err)
 }
 
 // addMarketplace executes `claude plugin marketplace add <repo>`.
@@ -39,14 +128,25 @@ func (s *Syncer) addMarketplace(m diff.MarketplaceDiff) (Operation, error) {
 
 	op.Description = fmt.Sprintf("Add marketplace: %s (%s)", m.Alias, m.Desired.Repo)
 
+	if missing := s.capabilities().Missing("marketplace"); missing != "" {
+		op.Success = false
+		op.Error = fmt.Sprintf("cannot add marketplace %s: %s", m.Alias, missing)
+		return op, fmt.Errorf("cannot add marketplace %s: %s", m.Alias, missing)
+	}
+
 	// Build command string before executing
 	op.Command = fmt.Sprintf("claude plugin marketplace add %s", m.Desired.Repo)
 
 	output, err := s.runner.Run("claude", "plugin", "marketplace", "add", m.Desired.Repo)
+	op.Output = captureOutput(output)
 	if err != nil {
+		if skipped, ok := alreadyDone(op, output); ok {
+			return skipped, nil
+		}
+		err = classifyFailure(err, output)
 		op.Success = false
-		op.Error = fmt.Sprintf("failed to add marketplace %s: %v\nOutput: %s", m.Alias, err, string(output))
-		return op, fmt.Errorf("failed to add marketplace %s: %w\nOutput: %s", m.Alias, err, string(output))
+		op.Error = fmt.Sprintf("failed to add marketplace %s: %v\nOutput: %s", m.Alias, err, op.Output)
+		return op, fmt.Errorf("failed to add marketplace %s: %w\nOutput: %s", m.Alias, err, op.Output)
 	}
 
 	op.Success = true
@@ -68,27 +168,54 @@ func (s *Syncer) installPlugin(p diff.PluginDiff) (Operation, error) {
 		return op, fmt.Errorf("no desired state for plugin %s", p.Name)
 	}
 
+	caps := s.capabilities()
+	if missing := caps.Missing("install"); missing != "" {
+		op.Success = false
+		op.Error = fmt.Sprintf("cannot install plugin %s: %s", p.Name, missing)
+		return op, fmt.Errorf("cannot install plugin %s: %s", p.Name, missing)
+	}
+
 	args := []string{"plugin", "install", p.Desired.Name}
 
-	// clew 1.0 always installs at user scope
-	args = append(args, "--scope", "user")
+	// clew 1.0 always installs at user scope, which is also the default
+	// for releases without --scope
+	if caps.InstallScope {
+		args = append(args, "--scope", "user")
+	}
 
 	// Build command string before executing
 	op.Command = "claude " + strings.Join(args, " ")
 
 	output, err := s.runner.Run("claude", args...)
+	op.Output = captureOutput(output)
 	if err != nil {
+		if skipped, ok := alreadyDone(op, output); ok {
+			return skipped, nil
+		}
+		err = classifyFailure(err, output)
 		op.Success = false
-		op.Error = fmt.Sprintf("failed to install plugin %s: %v\nOutput: %s", p.Name, err, string(output))
-		return op, fmt.Errorf("failed to install plugin %s: %w\nOutput: %s", p.Name, err, string(output))
+		op.Error = fmt.Sprintf("failed to install plugin %s: %v\nOutput: %s", p.Name, err, op.Output)
+		return op, fmt.Errorf("failed to install plugin %s: %w\nOutput: %s", p.Name, err, op.Output)
+	}
+
+	if s.verify != nil {
+		if err := s.verify(p.Name); err != nil {
+			err = fmt.Errorf("%w: %v", ErrNotInstalled, err)
+			op.Success = false
+			op.Error = err.Error()
+			return op, err
+		}
+		logging.Decisionf("Plugin %s is installed", p.Name)
 	}
 
 	op.Success = true
 	return op, nil
 }
 
-// updatePluginState executes `claude plugin enable/disable <plugin>`.
-func (s *Syncer) updatePluginState(p diff.PluginDiff) (Operation, error) {
+// updatePluginState sets a plugin's enabled state through the state.Writer
+// chosen for it: `claude plugin enable/disable`, or a direct edit of a
+// settings file.
+func (s *Syncer) updatePluginState(p diff.PluginDiff, target SettingsTarget) (Operation, error) {
 	op := Operation{
 		Type: "plugin",
 		Name: p.Name,
@@ -110,16 +237,62 @@ func (s *Syncer) updatePluginState(p diff.PluginDiff) (Operation, error) {
 		return op, fmt.Errorf("unexpected action for plugin state update: %s", p.Action)
 	}
 
+	change := state.EnabledChange{Plugin: p.Name, Enabled: p.Action == diff.ActionEnable}
+	w := s.writer(p, target)
+
 	// Build command string before executing
-	op.Command = fmt.Sprintf("claude plugin %s %s", action, p.Name)
+	op.Command = w.Command(change)
 
-	output, err := s.runner.Run("claude", "plugin", action, p.Name)
+	output, err := w.SetEnabled([]state.EnabledChange{change})
+	op.Output = captureOutput(output)
 	if err != nil {
+		if skipped, ok := alreadyDone(op, output); ok {
+			return skipped, nil
+		}
+		err = classifyFailure(err, output)
 		op.Success = false
-		op.Error = fmt.Sprintf("failed to %s plugin %s: %v\nOutput: %s", action, p.Name, err, string(output))
-		return op, fmt.Errorf("failed to %s plugin %s: %w\nOutput: %s", action, p.Name, err, string(output))
+		if output == nil {
+			op.Error = fmt.Sprintf("failed to %s plugin %s: %v", action, p.Name, err)
+			return op, fmt.Errorf("failed to %s plugin %s: %w", action, p.Name, err)
+		}
+		op.Error = fmt.Sprintf("failed to %s plugin %s: %v\nOutput: %s", action, p.Name, err, op.Output)
+		return op, fmt.Errorf("failed to %s plugin %s: %w\nOutput: %s", action, p.Name, err, op.Output)
 	}
 
 	op.Success = true
 	return op, nil
 }
+
+// writer returns the state.Writer for an enable/disable change to p.
+func (s *Syncer) writer(p diff.PluginDiff, target SettingsTarget) state.Writer {
+	switch {
+	case WritesLocalSettings(p, target):
+		return s.settingsWriter(state.SettingsLocalFile)
+	case s.capabilities().Missing("enable") != "":
+		// Releases without enable/disable read the same key from settings.json
+		return s.settingsWriter(state.SettingsFile)
+	default:
+		return &state.CLIWriter{Runner: s.runner}
+	}
+}
+
+// settingsWriter returns a writer for the named settings file in the
+// Claude directory.
+func (s *Syncer) settingsWriter(file string) *state.FilesystemWriter {
+	return &state.FilesystemWriter{Path: filepath.Join(s.claudeDir, file), Files: s.editor}
+}
+
+// WritesLocalSettings reports whether an enable/disable change for p should
+// go to settings.local.json rather than through the claude CLI.
+func WritesLocalSettings(p diff.PluginDiff, target SettingsTarget) bool {
+	switch target {
+	case SettingsTargetLocal:
+		return true
+	case SettingsTargetUser:
+		return false
+	default:
+		// settings.local.json overrides settings.json, so a change made via
+		// the CLI would be shadowed and never take effect
+		return p.Current != nil && p.Current.EnabledSource == state.SettingsLocalFile
+	}
+}
@@ -1,11 +1,15 @@
 package sync
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/adamancini/clew/internal/claudecli"
 	"github.com/adamancini/clew/internal/config"
 	"github.com/adamancini/clew/internal/diff"
 	"github.com/adamancini/clew/internal/state"
@@ -23,7 +27,7 @@ func (m *MockCommandRunner) Run(name string, args ...string) ([]byte, error) {
 	m.Commands = append(m.Commands, cmd)
 
 	if err, ok := m.Errors[cmd]; ok {
-		return nil, err
+		return m.Outputs[cmd], err
 	}
 	if output, ok := m.Outputs[cmd]; ok {
 		return output, nil
@@ -135,7 +139,7 @@ func TestUpdatePluginStateEnable(t *testing.T) {
 		},
 	}
 
-	op, err := syncer.updatePluginState(p)
+	op, err := syncer.updatePluginState(p, SettingsTargetAuto)
 	if err != nil {
 		t.Fatalf("updatePluginState() error = %v", err)
 	}
@@ -172,7 +176,7 @@ func TestUpdatePluginStateDisable(t *testing.T) {
 		},
 	}
 
-	op, err := syncer.updatePluginState(p)
+	op, err := syncer.updatePluginState(p, SettingsTargetAuto)
 	if err != nil {
 		t.Fatalf("updatePluginState() error = %v", err)
 	}
@@ -278,6 +282,388 @@ func TestExecuteWithErrors(t *testing.T) {
 	}
 }
 
+func TestExecuteAlreadyDone(t *testing.T) {
+	syncer, mock := newMockSyncer()
+	exit := fmt.Errorf("exit status 1")
+	mock.Errors["claude plugin marketplace add owner/official"] = exit
+	mock.Outputs["claude plugin marketplace add owner/official"] = []byte("Error: Marketplace 'official' already exists\n")
+	mock.Errors["claude plugin install present@official --scope user"] = exit
+	mock.Outputs["claude plugin install present@official --scope user"] = []byte("Plugin present@official is already installed")
+	mock.Errors["claude plugin enable on@official"] = exit
+	mock.Outputs["claude plugin enable on@official"] = []byte("Plugin on@official is already enabled")
+	mock.Errors["claude plugin install broken@official --scope user"] = exit
+	mock.Outputs["claude plugin install broken@official --scope user"] = []byte("Plugin not found")
+	// "already" in a real failure, or next to one, is not a no-op
+	mock.Errors["claude plugin install scoped@official --scope user"] = exit
+	mock.Outputs["claude plugin install scoped@official --scope user"] = []byte("Plugin scoped@official is already installed at project scope")
+	mock.Errors["claude plugin install mixed@official --scope user"] = exit
+	mock.Outputs["claude plugin install mixed@official --scope user"] = []byte("Plugin mixed@official is already installed\nError: failed to update cache\n")
+
+	d := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "official", Action: diff.ActionAdd, Desired: &config.Marketplace{Repo: "owner/official"}},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "present@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "present@official"}},
+			{Name: "on@official", Action: diff.ActionEnable, Desired: &config.Plugin{Name: "on@official"}},
+			{Name: "broken@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "broken@official"}},
+			{Name: "scoped@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "scoped@official"}},
+			{Name: "mixed@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "mixed@official"}},
+		},
+	}
+
+	result, err := syncer.Execute(d, Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Skipped != 3 || result.Failed != 3 || result.Installed != 0 || result.Updated != 0 {
+		t.Errorf("Skipped/Failed/Installed/Updated = %d/%d/%d/%d, want 3/3/0/0",
+			result.Skipped, result.Failed, result.Installed, result.Updated)
+	}
+	for _, op := range result.Operations[:3] {
+		if !op.Success || !op.Skipped || op.Error != "" {
+			t.Errorf("%s %s = %+v, want a successful skip", op.Type, op.Name, op)
+		}
+		if !strings.Contains(op.Description, "already") {
+			t.Errorf("%s %s description = %q, want the reason", op.Type, op.Name, op.Description)
+		}
+	}
+}
+
+func TestExecuteCapturesOutput(t *testing.T) {
+	syncer, mock := newMockSyncer()
+	mock.Errors["claude plugin install broken@official --scope user"] = fmt.Errorf("exit status 1")
+	mock.Outputs["claude plugin install broken@official --scope user"] = []byte(strings.Repeat("x", MaxOutputBytes) + "Error: plugin not found")
+
+	d := &diff.Result{
+		Plugins: []diff.PluginDiff{
+			{Name: "ok@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "ok@official"}},
+			{Name: "broken@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "broken@official"}},
+		},
+	}
+
+	result, err := syncer.Execute(d, Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := result.Operations[0].Output; got != "success" {
+		t.Errorf("Output = %q, want the command output", got)
+	}
+	got := result.Operations[1].Output
+	if !strings.HasPrefix(got, "[... 23 bytes truncated]\n") || !strings.HasSuffix(got, "Error: plugin not found") {
+		t.Errorf("Output = %.40q...%q, want the truncated tail", got, got[max(len(got)-30, 0):])
+	}
+	if errText := result.Operations[1].Error; len(errText) > MaxOutputBytes+200 || !strings.Contains(errText, "[... 23 bytes truncated]") {
+		t.Errorf("Error is %d bytes, want it built from the truncated output", len(errText))
+	}
+	for _, e := range result.Errors {
+		if len(e.Error()) > MaxOutputBytes+200 {
+			t.Errorf("result error is %d bytes, want it built from the truncated output", len(e.Error()))
+		}
+	}
+}
+
+func TestExecuteOnFailure(t *testing.T) {
+	failing := "claude plugin install broken@official --scope user"
+	tests := []struct {
+		name          string
+		itemPolicy    string
+		defaultPolicy string
+		wantAttempts  int
+		wantInstalled int
+		wantAborted   string
+	}{
+		{"continue by default", "", "", 1, 1, ""},
+		{"retry", config.OnFailureRetry, "", 2, 1, ""},
+		{"abort", config.OnFailureAbort, "", 1, 0, "plugin broken@official"},
+		{"default from Clewfile", "", config.OnFailureAbort, 1, 0, "plugin broken@official"},
+		{"item overrides default", config.OnFailureContinue, config.OnFailureAbort, 1, 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncer, mock := newMockSyncer()
+			mock.Errors[failing] = fmt.Errorf("install failed")
+
+			d := &diff.Result{
+				Plugins: []diff.PluginDiff{
+					{
+						Name:    "broken@official",
+						Action:  diff.ActionAdd,
+						Desired: &config.Plugin{Name: "broken@official", OnFailure: tt.itemPolicy},
+					},
+					{
+						Name:    "working@official",
+						Action:  diff.ActionAdd,
+						Desired: &config.Plugin{Name: "working@official"},
+					},
+				},
+			}
+
+			result, err := syncer.Execute(d, Options{OnFailure: tt.defaultPolicy})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			attempts := 0
+			for _, cmd := range mock.Commands {
+				if cmd == failing {
+					attempts++
+				}
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if result.Failed != 1 {
+				t.Errorf("Failed = %d, want 1", result.Failed)
+			}
+			if result.Installed != tt.wantInstalled {
+				t.Errorf("Installed = %d, want %d", result.Installed, tt.wantInstalled)
+			}
+			if result.Aborted != tt.wantAborted {
+				t.Errorf("Aborted = %q, want %q", result.Aborted, tt.wantAborted)
+			}
+			if tt.wantAborted != "" {
+				last := result.Operations[len(result.Operations)-1]
+				if last.Name != "working@official" || !last.Skipped {
+					t.Errorf("last operation = %+v, want working@official skipped", last)
+				}
+			}
+		})
+	}
+}
+
+// cancellingRunner cancels the sync's context while running its first command.
+type cancellingRunner struct {
+	MockCommandRunner
+	cancel context.CancelFunc
+}
+
+func (r *cancellingRunner) Run(name string, args ...string) ([]byte, error) {
+	r.cancel()
+	return r.MockCommandRunner.Run(name, args...)
+}
+
+func TestExecuteInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runner := &cancellingRunner{cancel: cancel}
+	syncer := NewSyncerWithRunner(runner)
+	syncer.SetContext(ctx)
+
+	d := &diff.Result{
+		Marketplaces: []diff.MarketplaceDiff{
+			{Alias: "official", Action: diff.ActionAdd, Desired: &config.Marketplace{Repo: "anthropics/claude-plugins-official"}},
+		},
+		Plugins: []diff.PluginDiff{
+			{Name: "lint@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "lint@official"}},
+			{Name: "test@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "test@official"}},
+		},
+	}
+
+	result, err := syncer.Execute(d, Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !result.Interrupted {
+		t.Error("Interrupted = false, want true")
+	}
+	if len(runner.Commands) != 1 {
+		t.Errorf("Commands = %q, want only the marketplace add", runner.Commands)
+	}
+	if result.Skipped != 2 {
+		t.Errorf("Skipped = %d, want 2", result.Skipped)
+	}
+	for _, op := range result.Operations[1:] {
+		if !op.Skipped || op.Description != "Skipped: sync interrupted" {
+			t.Errorf("operation = %+v, want skipped as interrupted", op)
+		}
+	}
+}
+
+func TestAttemptTimesOnlyTheLastTry(t *testing.T) {
+	syncer, _ := newMockSyncer()
+	tries := 0
+	op, err := syncer.attempt(config.OnFailureRetry, "plugin", "slow@official", "add", func() (Operation, error) {
+		tries++
+		if tries == 1 {
+			time.Sleep(50 * time.Millisecond)
+			return Operation{}, errors.New("install failed")
+		}
+		return Operation{Success: true}, nil
+	})
+	if err != nil || tries != 2 {
+		t.Fatalf("attempt() = %v after %d tries, want success on the second", err, tries)
+	}
+	if op.Duration >= 50*time.Millisecond {
+		t.Errorf("Duration = %s, want only the successful try", op.Duration)
+	}
+}
+
+func TestExecuteNotLoggedIn(t *testing.T) {
+	syncer, mock := newMockSyncer()
+	cmd := "claude plugin install lint@official --scope user"
+	mock.Outputs[cmd] = []byte("Invalid API key · Please run /login\n")
+	mock.Errors[cmd] = errors.New("exit status 1")
+
+	d := &diff.Result{
+		Plugins: []diff.PluginDiff{
+			{Name: "lint@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "lint@official"}},
+			{Name: "test@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "test@official"}},
+		},
+	}
+
+	// retry is not attempted: the second run would fail the same way
+	result, err := syncer.Execute(d, Options{OnFailure: config.OnFailureRetry})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !result.NotLoggedIn || result.Aborted != "" {
+		t.Errorf("NotLoggedIn = %v, Aborted = %q; want true, none", result.NotLoggedIn, result.Aborted)
+	}
+	if len(mock.Commands) != 1 {
+		t.Errorf("Commands = %q, want only the first install", mock.Commands)
+	}
+	if result.Failed != 1 || result.Skipped != 1 || !errors.Is(result.Errors[0], ErrNotLoggedIn) {
+		t.Errorf("Failed = %d, Skipped = %d, Errors = %v", result.Failed, result.Skipped, result.Errors)
+	}
+	if op := result.Operations[1]; op.Description != "Skipped: claude is not logged in" {
+		t.Errorf("operation = %+v, want skipped as not logged in", op)
+	}
+}
+
+func TestExecuteEvents(t *testing.T) {
+	syncer, mock := newMockSyncer()
+	cmd := "claude plugin install lint@official --scope user"
+	mock.Outputs[cmd] = []byte("Invalid API key · Please run /login\n")
+	mock.Errors[cmd] = errors.New("exit status 1")
+
+	events := make(chan Event)
+	var got []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range events {
+			got = append(got, fmt.Sprintf("%s %s", ev.Kind, ev.Name))
+			if ev.Kind == OperationFinished && ev.Operation == nil {
+				t.Errorf("finished event for %s has no operation", ev.Name)
+			}
+		}
+	}()
+	syncer.SetEvents(events)
+
+	d := &diff.Result{
+		Plugins: []diff.PluginDiff{
+			{Name: "lint@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "lint@official"}},
+			{Name: "test@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "test@official"}},
+		},
+	}
+	if _, err := syncer.Execute(d, Options{}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	syncer.SetEvents(nil)
+	close(events)
+	<-done
+
+	// the skipped install never starts
+	want := []string{"started lint@official", "finished lint@official", "finished test@official"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestExecuteVerifiesInstalls(t *testing.T) {
+	syncer, mock := newMockSyncer()
+	checks := 0
+	syncer.SetInstallVerifier(func(name string) error {
+		checks++
+		if name == "ghost@official" {
+			return fmt.Errorf("plugin %s is missing from installed_plugins.json after install", name)
+		}
+		return nil
+	})
+
+	events := make(chan Event)
+	var finished []Operation
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range events {
+			if ev.Kind == OperationFinished {
+				finished = append(finished, *ev.Operation)
+			}
+		}
+	}()
+	syncer.SetEvents(events)
+
+	// An install claude reports but that did not land is retried, then
+	// aborts the sync like any other failure
+	d := &diff.Result{
+		Plugins: []diff.PluginDiff{
+			{Name: "ghost@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "ghost@official"}},
+			{Name: "next@official", Action: diff.ActionAdd, Desired: &config.Plugin{Name: "next@official"}},
+		},
+	}
+	result, err := syncer.Execute(d, Options{OnFailure: config.OnFailureRetry})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if checks != 3 || len(mock.Commands) != 3 {
+		t.Errorf("checks = %d, commands = %q; want the ghost install tried twice", checks, mock.Commands)
+	}
+	if result.Installed != 1 || result.Failed != 1 || len(result.Attention) != 1 || !errors.Is(result.Errors[0], ErrNotInstalled) {
+		t.Errorf("result = %+v, want ghost@official failed as not installed", result)
+	}
+
+	checks = 0
+	mock.Commands = nil
+	result, err = syncer.Execute(d, Options{OnFailure: config.OnFailureAbort})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	syncer.SetEvents(nil)
+	close(events)
+	<-done
+
+	if result.Aborted != "plugin ghost@official" || len(mock.Commands) != 1 {
+		t.Errorf("Aborted = %q, commands = %q; want the sync stopped after ghost@official", result.Aborted, mock.Commands)
+	}
+	// Progress never reports the unverified install as a success
+	for _, op := range finished {
+		if op.Name == "ghost@official" && op.Success {
+			t.Errorf("finished event reported %+v as successful", op)
+		}
+	}
+}
+
+func TestExecuteInstallsDisabled(t *testing.T) {
+	syncer, mock := newMockSyncer()
+	disabled := false
+
+	d := &diff.Result{
+		Plugins: []diff.PluginDiff{
+			{
+				Name:    "linear@official",
+				Action:  diff.ActionAdd,
+				Desired: &config.Plugin{Name: "linear@official", Enabled: &disabled},
+			},
+		},
+	}
+
+	result, err := syncer.Execute(d, Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := []string{
+		"claude plugin install linear@official --scope user",
+		"claude plugin disable linear@official",
+	}
+	if strings.Join(mock.Commands, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Commands = %q, want %q", mock.Commands, want)
+	}
+	if result.Installed != 1 || len(result.Operations) != 2 {
+		t.Errorf("Installed = %d, operations = %d; want 1 and 2", result.Installed, len(result.Operations))
+	}
+}
+
 func TestInstallPluginAlwaysUserScope(t *testing.T) {
 	syncer, mock := newMockSyncer()
 
@@ -309,6 +695,51 @@ func TestInstallPluginAlwaysUserScope(t *testing.T) {
 	}
 }
 
+func TestExecutorAdaptsToCapabilities(t *testing.T) {
+	mock := &MockCommandRunner{Outputs: map[string][]byte{}, Errors: map[string]error{}}
+	editor := &MockFileEditor{Files: map[string][]byte{}}
+	syncer := NewSyncerWithRunnerAndEditor(mock, editor, "/home/test/.claude")
+	syncer.SetCapabilities(&claudecli.Capabilities{Version: "0.9.0", Install: true})
+
+	// Releases without --scope install at user scope by default
+	if _, err := syncer.installPlugin(diff.PluginDiff{
+		Name:    "test-plugin@marketplace",
+		Action:  diff.ActionAdd,
+		Desired: &config.Plugin{Name: "test-plugin@marketplace"},
+	}); err != nil {
+		t.Fatalf("installPlugin() error = %v", err)
+	}
+	if len(mock.Commands) != 1 || mock.Commands[0] != "claude plugin install test-plugin@marketplace" {
+		t.Errorf("Commands = %v, want install without --scope", mock.Commands)
+	}
+
+	// Without enable/disable, settings.json is edited directly
+	op, err := syncer.updatePluginState(diff.PluginDiff{
+		Name:    "test-plugin@marketplace",
+		Action:  diff.ActionEnable,
+		Current: &state.PluginState{Name: "test-plugin", EnabledSource: state.SettingsFile},
+	}, SettingsTargetAuto)
+	if err != nil || !op.Success {
+		t.Fatalf("updatePluginState() = %+v, %v", op, err)
+	}
+	if written := string(editor.Files["/home/test/.claude/settings.json"]); !strings.Contains(written, `"test-plugin@marketplace": true`) {
+		t.Errorf("settings.json not updated:\n%s", written)
+	}
+
+	// Without marketplace support, nothing is run and the error says why
+	op, err = syncer.addMarketplace(diff.MarketplaceDiff{
+		Alias:   "official",
+		Action:  diff.ActionAdd,
+		Desired: &config.Marketplace{Repo: "anthropics/claude-plugins-official"},
+	})
+	if err == nil || op.Success || !strings.Contains(op.Error, "claude 0.9.0 does not support 'claude plugin marketplace'") {
+		t.Errorf("addMarketplace() = %+v, %v, want a capability error", op, err)
+	}
+	if len(mock.Commands) != 1 {
+		t.Errorf("Commands = %v, want no marketplace command run", mock.Commands)
+	}
+}
+
 // MockFileEditor records file operations for testing.
 type MockFileEditor struct {
 	Files map[string][]byte
@@ -325,3 +756,127 @@ func (m *MockFileEditor) WriteFile(path string, data []byte, perm os.FileMode) e
 	m.Files[path] = data
 	return nil
 }
+
+func TestExecuteKeybindings(t *testing.T) {
+	mock := &MockCommandRunner{Outputs: map[string][]byte{}, Errors: map[string]error{}}
+	path := "/home/test/.claude/" + state.KeybindingsFile
+	editor := &MockFileEditor{Files: map[string][]byte{
+		path: []byte(`{"bindings": [{"context": "Chat", "bindings": {"ctrl+x": "chat:cancel"}}]}`),
+	}}
+	syncer := NewSyncerWithRunnerAndEditor(mock, editor, "/home/test/.claude")
+
+	d := &diff.Result{
+		Keybindings: []diff.KeybindingDiff{
+			{Context: "Chat", Key: "ctrl+e", Action: diff.ActionAdd, Desired: "chat:externalEditor"},
+			{Context: "Global", Key: "ctrl+t", Action: diff.ActionUpdate, Current: "app:toggleTodos"},
+			{Context: "Chat", Key: "ctrl+s", Action: diff.ActionNone, Current: "chat:stash", Desired: "chat:stash"},
+		},
+	}
+	result, err := syncer.Execute(d, Options{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Updated != 2 || result.Failed != 0 || len(result.Operations) != 2 {
+		t.Errorf("Updated = %d, Failed = %d, Operations = %d; want 2, 0, 2", result.Updated, result.Failed, len(result.Operations))
+	}
+	if len(mock.Commands) != 0 {
+		t.Errorf("Commands = %v, want keybindings.json edited directly", mock.Commands)
+	}
+
+	got, err := state.ParseKeybindings(editor.Files[path])
+	if err != nil {
+		t.Fatalf("written keybindings.json: %v", err)
+	}
+	want := state.Keybindings{
+		"Chat":   {"ctrl+x": "chat:cancel", "ctrl+e": "chat:externalEditor"},
+		"Global": {"ctrl+t": ""},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("keybindings = %v, want %v", got, want)
+	}
+}
+
+func TestUpdatePluginStateLocalSettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		target    SettingsTarget
+		wantLocal bool
+	}{
+		{"auto follows settings.json", state.SettingsFile, SettingsTargetAuto, false},
+		{"auto follows settings.local.json", state.SettingsLocalFile, SettingsTargetAuto, true},
+		{"settings target forces CLI", state.SettingsLocalFile, SettingsTargetUser, false},
+		{"local target forces local file", state.SettingsFile, SettingsTargetLocal, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCommandRunner{Outputs: map[string][]byte{}, Errors: map[string]error{}}
+			localPath := "/home/test/.claude/settings.local.json"
+			editor := &MockFileEditor{Files: map[string][]byte{
+				localPath: []byte(`{"permissions": {"allow": ["Bash"]}, "enabledPlugins": {"test-plugin@marketplace": true}}`),
+			}}
+			syncer := NewSyncerWithRunnerAndEditor(mock, editor, "/home/test/.claude")
+
+			p := diff.PluginDiff{
+				Name:    "test-plugin@marketplace",
+				Action:  diff.ActionDisable,
+				Current: &state.PluginState{Name: "test-plugin", Enabled: true, EnabledSource: tt.source},
+			}
+
+			op, err := syncer.updatePluginState(p, tt.target)
+			if err != nil {
+				t.Fatalf("updatePluginState() error = %v", err)
+			}
+			if !op.Success {
+				t.Errorf("Operation.Success = false")
+			}
+
+			if !tt.wantLocal {
+				if len(mock.Commands) != 1 {
+					t.Errorf("expected claude CLI to be used, commands = %v", mock.Commands)
+				}
+				return
+			}
+
+			if len(mock.Commands) != 0 {
+				t.Errorf("expected no CLI commands, got %v", mock.Commands)
+			}
+			written := string(editor.Files[localPath])
+			if !strings.Contains(written, `"test-plugin@marketplace": false`) {
+				t.Errorf("settings.local.json not updated:\n%s", written)
+			}
+			if !strings.Contains(written, `"permissions"`) {
+				t.Errorf("other settings not preserved:\n%s", written)
+			}
+		})
+	}
+}
+
+func TestParseSettingsTarget(t *testing.T) {
+	for _, s := range []string{"", "auto", "settings", "local"} {
+		if _, err := ParseSettingsTarget(s); err != nil {
+			t.Errorf("ParseSettingsTarget(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParseSettingsTarget("project"); err == nil {
+		t.Error("ParseSettingsTarget(\"project\") should fail")
+	}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	exit := errors.New("exit status 1")
+	tests := map[string]bool{
+		"Invalid API key · Please run /login\n":                        true,
+		"Not logged in · Please run /login\n":                          true,
+		"OAuth token has expired. Please run /login\n":                 true,
+		"remote: HTTP Basic: Access denied\nfatal: 401 Unauthorized\n": false,
+		"Error: failed to clone: authentication_error\n":               false,
+		"Error: invalid api key for marketplace host\n":                false,
+	}
+	for output, want := range tests {
+		if got := errors.Is(classifyFailure(exit, []byte(output)), ErrNotLoggedIn); got != want {
+			t.Errorf("classifyFailure(%q) not logged in = %t, want %t", output, got, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/jsonedit"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// applyKeybindings writes the key bindings that differ from the Clewfile
+// with one edit of keybindings.json, keeping every other binding. Each
+// binding is recorded as its own operation; on error they all failed.
+func (s *Syncer) applyKeybindings(bindings []diff.KeybindingDiff, opts Options, result *Result) {
+	var changed []diff.KeybindingDiff
+	for _, k := range bindings {
+		if k.Action == diff.ActionAdd || k.Action == diff.ActionUpdate {
+			changed = append(changed, k)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	result.Interrupted = result.Interrupted || s.interrupted()
+	if result.stopped() {
+		for _, k := range changed {
+			s.skipAborted(result, "keybinding", k.Name(), "set")
+		}
+		return
+	}
+
+	path := filepath.Join(s.claudeDir, state.KeybindingsFile)
+	changes := make(state.Keybindings)
+	for _, k := range changed {
+		s.started("keybinding", k.Name(), "set")
+		if changes[k.Context] == nil {
+			changes[k.Context] = make(map[string]string)
+		}
+		changes[k.Context][k.Key] = k.Desired
+	}
+
+	start := time.Now()
+	err := state.EditJSONFile(s.editor, path, func(doc *jsonedit.Object) error {
+		return state.SetKeybindings(doc, changes)
+	})
+	elapsed := time.Since(start)
+	policy := failurePolicy("", opts.OnFailure)
+	for _, k := range changed {
+		bound := fmt.Sprintf("%q", k.Desired)
+		if k.Desired == "" {
+			bound = "null"
+		}
+		kop := Operation{
+			Type:        "keybinding",
+			Name:        k.Name(),
+			Action:      "set",
+			Command:     fmt.Sprintf("set bindings[%q][%q] = %s in %s", k.Context, k.Key, bound, path),
+			Description: keybindingDescription(k),
+			Success:     err == nil,
+			Duration:    elapsed,
+		}
+		if err != nil {
+			kop.Error = fmt.Sprintf("failed to set keybinding %s: %v", k.Name(), err)
+		}
+		s.record(result, kop)
+		if err != nil {
+			result.fail(fmt.Errorf("failed to set keybinding %s: %w", k.Name(), err), policy, "keybinding "+k.Name())
+		} else {
+			result.Updated++
+		}
+	}
+}
+
+// keybindingDescription describes a key binding change for output.
+func keybindingDescription(k diff.KeybindingDiff) string {
+	if k.Desired == "" {
+		return fmt.Sprintf("Unbind %s", k.Name())
+	}
+	return fmt.Sprintf("Bind %s to %s", k.Name(), k.Desired)
+}
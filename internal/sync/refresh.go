@@ -0,0 +1,96 @@
+package sync
+
+import (
+	"fmt"
+	gosync "sync"
+	"time"
+
+	"github.com/adamancini/clew/internal/logging"
+)
+
+// Limits for refreshing marketplaces before a sync.
+const (
+	RefreshConcurrency = 4               // Marketplaces updated at once
+	RefreshAttempts    = 3               // Tries per marketplace before giving up
+	RefreshBackoff     = 2 * time.Second // Wait before the first retry; doubles after each
+)
+
+// RefreshMarketplaces runs `claude plugin marketplace update <alias>` for
+// each alias, a few at a time, so plugins published since the marketplace
+// was added can be installed. Failed updates are retried with exponential
+// backoff. Operations are returned in the order given.
+func (s *Syncer) RefreshMarketplaces(aliases []string) []Operation {
+	ops := make([]Operation, len(aliases))
+	slots := make(chan struct{}, RefreshConcurrency)
+	var wg gosync.WaitGroup
+	for i, alias := range aliases {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			s.started("marketplace", alias, "update")
+			ops[i], _ = timed(func() (Operation, error) { return s.refreshMarketplace(alias) })
+			s.finished(ops[i])
+		}()
+	}
+	wg.Wait()
+	return ops
+}
+
+// refreshMarketplace updates one marketplace, retrying failures.
+func (s *Syncer) refreshMarketplace(alias string) (Operation, error) {
+	op := Operation{
+		Type:        "marketplace",
+		Name:        alias,
+		Action:      "update",
+		Description: fmt.Sprintf("Refresh marketplace: %s", alias),
+		Command:     fmt.Sprintf("claude plugin marketplace update %s", alias),
+	}
+
+	if missing := s.capabilities().Missing("marketplace"); missing != "" {
+		op.Error = fmt.Sprintf("cannot refresh marketplace %s: %s", alias, missing)
+		return op, fmt.Errorf("cannot refresh marketplace %s: %s", alias, missing)
+	}
+
+	wait := RefreshBackoff
+	for try := 1; ; try++ {
+		output, err := s.runner.Run("claude", "plugin", "marketplace", "update", alias)
+		op.Output = captureOutput(output)
+		if err == nil {
+			op.Success = true
+			op.Error = ""
+			return op, nil
+		}
+		op.Error = fmt.Sprintf("failed to refresh marketplace %s: %v\nOutput: %s", alias, err, op.Output)
+		if try == RefreshAttempts {
+			return op, fmt.Errorf("failed to refresh marketplace %s: %w\nOutput: %s", alias, err, op.Output)
+		}
+		logging.Decisionf("marketplace %s: refresh failed, retrying in %s: %v", alias, wait, err)
+		if !s.wait(wait) {
+			return op, fmt.Errorf("failed to refresh marketplace %s: %w", alias, s.ctx.Err())
+		}
+		wait *= 2
+	}
+}
+
+// wait pauses between retries. It returns false without waiting the full
+// d when the sync is interrupted.
+func (s *Syncer) wait(d time.Duration) bool {
+	if s.sleep != nil {
+		s.sleep(d)
+		return !s.interrupted()
+	}
+	if s.ctx == nil {
+		time.Sleep(d)
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,105 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+)
+
+// flakyRunner fails each command the number of times given in failures,
+// then succeeds. It is safe for concurrent use.
+type flakyRunner struct {
+	mu       gosync.Mutex
+	failures map[string]int
+	calls    map[string]int
+}
+
+func (r *flakyRunner) Run(name string, args ...string) ([]byte, error) {
+	cmd := name + " " + strings.Join(args, " ")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[cmd]++
+	if r.failures[cmd] > 0 {
+		r.failures[cmd]--
+		return []byte("rate limited"), fmt.Errorf("exit status 1")
+	}
+	return []byte("updated"), nil
+}
+
+func TestRefreshMarketplaces(t *testing.T) {
+	runner := &flakyRunner{
+		failures: map[string]int{
+			"claude plugin marketplace update flaky":  1,
+			"claude plugin marketplace update broken": RefreshAttempts,
+		},
+		calls: make(map[string]int),
+	}
+	syncer := NewSyncerWithRunner(runner)
+	var waits []time.Duration
+	var mu gosync.Mutex
+	syncer.sleep = func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		waits = append(waits, d)
+	}
+
+	ops := syncer.RefreshMarketplaces([]string{"ok", "flaky", "broken"})
+	if len(ops) != 3 {
+		t.Fatalf("RefreshMarketplaces() returned %d operations, want 3", len(ops))
+	}
+	for i, want := range []struct {
+		name    string
+		success bool
+		calls   int
+	}{
+		{"ok", true, 1},
+		{"flaky", true, 2},
+		{"broken", false, RefreshAttempts},
+	} {
+		op := ops[i]
+		if op.Name != want.name || op.Action != "update" || op.Success != want.success {
+			t.Errorf("ops[%d] = %s %s success=%t, want %s update success=%t", i, op.Name, op.Action, op.Success, want.name, want.success)
+		}
+		if got := runner.calls["claude plugin marketplace update "+want.name]; got != want.calls {
+			t.Errorf("%s updated %d times, want %d", want.name, got, want.calls)
+		}
+	}
+	if ops[1].Error != "" {
+		t.Errorf("ops[1].Error = %q, want cleared after a successful retry", ops[1].Error)
+	}
+	if !strings.Contains(ops[2].Error, "failed to refresh marketplace broken") {
+		t.Errorf("ops[2].Error = %q", ops[2].Error)
+	}
+
+	// One retry for flaky, two (doubling) for broken
+	var total time.Duration
+	for _, d := range waits {
+		total += d
+	}
+	if len(waits) != 3 || total != 4*RefreshBackoff {
+		t.Errorf("waits = %v, want %s, %s and %s", waits, RefreshBackoff, RefreshBackoff, 2*RefreshBackoff)
+	}
+}
+
+func TestRefreshMarketplacesInterruptedDuringBackoff(t *testing.T) {
+	runner := &flakyRunner{
+		failures: map[string]int{"claude plugin marketplace update broken": RefreshAttempts},
+		calls:    make(map[string]int),
+	}
+	syncer := NewSyncerWithRunner(runner)
+	ctx, cancel := context.WithCancel(context.Background())
+	syncer.SetContext(ctx)
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	ops := syncer.RefreshMarketplaces([]string{"broken"})
+	if elapsed := time.Since(start); elapsed >= RefreshBackoff {
+		t.Errorf("RefreshMarketplaces() took %s, want the backoff cut short", elapsed)
+	}
+	if ops[0].Success || runner.calls["claude plugin marketplace update broken"] != 1 {
+		t.Errorf("ops = %+v after %d calls, want one failed attempt", ops, runner.calls["claude plugin marketplace update broken"])
+	}
+}
@@ -0,0 +1,259 @@
+package sync
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/state"
+)
+
+// Inverse returns the operation that reverts op. Only operations that
+// changed the system can be reverted: failed and skipped operations, and
+// actions without an inverse (such as update), report false.
+func Inverse(op Operation) (Operation, bool) {
+	if !op.Success || op.Skipped {
+		return Operation{}, false
+	}
+
+	inv := Operation{Type: op.Type, Name: op.Name}
+	switch {
+	case op.Type == "marketplace" && op.Action == "add":
+		inv.Action = "remove"
+		inv.Description = fmt.Sprintf("Remove marketplace: %s", op.Name)
+		inv.Command = fmt.Sprintf("claude plugin marketplace remove %s", op.Name)
+	case op.Type == "plugin" && op.Action == "add":
+		inv.Action = "uninstall"
+		inv.Description = fmt.Sprintf("Uninstall plugin: %s", op.Name)
+		inv.Command = fmt.Sprintf("claude plugin uninstall %s", op.Name)
+	case op.Type == "plugin" && op.Action == "enable":
+		inv.Action = "disable"
+		inv.Description = fmt.Sprintf("Disable plugin: %s", op.Name)
+		inv.Command = fmt.Sprintf("claude plugin disable %s", op.Name)
+	case op.Type == "plugin" && op.Action == "disable":
+		inv.Action = "enable"
+		inv.Description = fmt.Sprintf("Enable plugin: %s", op.Name)
+		inv.Command = fmt.Sprintf("claude plugin enable %s", op.Name)
+	default:
+		return Operation{}, false
+	}
+	return inv, true
+}
+
+// RevertPlan returns the inverse operations for a recorded run, last
+// operation first. When before (the pre-run backup) is non-nil, inverses
+// that would move away from it are dropped: a plugin present in the backup
+// is not uninstalled, and enable/disable is only reverted to the backed-up
+// state. Inverses that are already satisfied by current are dropped too.
+func RevertPlan(ops []Operation, before, current *state.State) []Operation {
+	var plan []Operation
+	for i := len(ops) - 1; i >= 0; i-- {
+		inv, ok := Inverse(ops[i])
+		if !ok || !revertNeeded(inv, before, current) {
+			continue
+		}
+		plan = append(plan, inv)
+	}
+	return plan
+}
+
+// revertNeeded reports whether an inverse operation still has work to do
+// and agrees with the pre-run state.
+func revertNeeded(inv Operation, before, current *state.State) bool {
+	if inv.Type == "marketplace" {
+		if before != nil {
+			if _, existed := before.Marketplaces[inv.Name]; existed {
+				return false
+			}
+		}
+		if current != nil {
+			if _, exists := current.Marketplaces[inv.Name]; !exists {
+				return false
+			}
+		}
+		return true
+	}
+
+	var cur *state.PluginState
+	if current != nil {
+		if p, ok := current.Plugins[inv.Name]; ok {
+			cur = &p
+		} else {
+			return false // Already uninstalled
+		}
+	}
+
+	switch inv.Action {
+	case "uninstall":
+		if before != nil {
+			if _, existed := before.Plugins[inv.Name]; existed {
+				return false
+			}
+		}
+	case "enable", "disable":
+		want := inv.Action == "enable"
+		if before != nil {
+			if p, existed := before.Plugins[inv.Name]; existed && p.Enabled != want {
+				return false
+			}
+		}
+		if cur != nil && cur.Enabled == want {
+			return false
+		}
+	}
+	return true
+}
+
+// Revert executes operations produced by RevertPlan or RemovalPlan.
+func (s *Syncer) Revert(plan []Operation, current *state.State, opts Options) (*Result, error) {
+	result := &Result{
+		Operations: []Operation{},
+	}
+
+	// A marketplace whose plugin failed to uninstall stays, so the plugin is
+	// not left without its marketplace
+	failed := make(map[string]bool)
+	for _, inv := range plan {
+		if inv.Type == "marketplace" && failed[inv.Name] {
+			inv.Success = true
+			inv.Skipped = true
+			inv.Description = fmt.Sprintf("%s (skipped: a plugin from it failed to uninstall)", inv.Description)
+			s.record(result, inv)
+			result.Skipped++
+			continue
+		}
+		s.started(inv.Type, inv.Name, inv.Action)
+		op, err := timed(func() (Operation, error) { return s.revertOperation(inv, current, opts.SettingsTarget) })
+		s.record(result, op)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, err)
+			if inv.Type == "plugin" {
+				failed[pluginMarketplace(inv.Name, current)] = true
+			}
+		} else {
+			result.Updated++
+		}
+	}
+
+	numberOperations(result.Operations)
+	return result, nil
+}
+
+// revertOperation runs a single inverse operation.
+func (s *Syncer) revertOperation(op Operation, current *state.State, target SettingsTarget) (Operation, error) {
+	var args []string
+	switch op.Action {
+	case "remove":
+		args = []string{"plugin", "marketplace", "remove", op.Name}
+	case "uninstall":
+		args = []string{"plugin", "uninstall", op.Name}
+	case "enable", "disable":
+		p := diff.PluginDiff{Name: op.Name, Action: diff.ActionDisable}
+		if op.Action == "enable" {
+			p.Action = diff.ActionEnable
+		}
+		if current != nil {
+			if cur, ok := current.Plugins[op.Name]; ok {
+				p.Current = &cur
+			}
+		}
+		return s.updatePluginState(p, target)
+	default:
+		op.Success = false
+		op.Error = fmt.Sprintf("unexpected revert action: %s", op.Action)
+		return op, fmt.Errorf("unexpected revert action: %s", op.Action)
+	}
+
+	output, err := s.runner.Run("claude", args...)
+	op.Output = captureOutput(output)
+	if err != nil {
+		op.Success = false
+		op.Error = fmt.Sprintf("failed to %s %s %s: %v\nOutput: %s", op.Action, op.Type, op.Name, err, op.Output)
+		return op, fmt.Errorf("failed to %s %s %s: %w\nOutput: %s", op.Action, op.Type, op.Name, err, op.Output)
+	}
+
+	op.Success = true
+	return op, nil
+}
+
+// RemovalPlan returns the operations that remove plugins and marketplaces
+// from the system, plugins first so no marketplace is removed from under
+// its plugins.
+func RemovalPlan(plugins, marketplaces []string) []Operation {
+	var plan []Operation
+	for _, name := range plugins {
+		plan = append(plan, Operation{
+			Type:        "plugin",
+			Name:        name,
+			Action:      "uninstall",
+			Description: fmt.Sprintf("Uninstall plugin: %s", name),
+			Command:     fmt.Sprintf("claude plugin uninstall %s", name),
+		})
+	}
+	for _, alias := range marketplaces {
+		plan = append(plan, Operation{
+			Type:        "marketplace",
+			Name:        alias,
+			Action:      "remove",
+			Description: fmt.Sprintf("Remove marketplace: %s", alias),
+			Command:     fmt.Sprintf("claude plugin marketplace remove %s", alias),
+		})
+	}
+	return plan
+}
+
+// RemovalOrphans returns, for each marketplace about to be removed, the
+// plugins that would be left without it: plugins the Clewfile declares from
+// it, and installed plugins from it that are not being removed too. Only
+// marketplaces with such plugins are included.
+func RemovalOrphans(plugins, marketplaces []string, clewfile *config.Clewfile, current *state.State) map[string][]string {
+	removing := make(map[string]bool)
+	for _, name := range plugins {
+		removing[name] = true
+	}
+	users := make(map[string]map[string]bool)
+	for _, alias := range marketplaces {
+		users[alias] = make(map[string]bool)
+	}
+	use := func(name, alias string) {
+		if names, ok := users[alias]; ok && !removing[name] {
+			names[name] = true
+		}
+	}
+	if clewfile != nil {
+		for _, p := range clewfile.Plugins {
+			use(p.Name, pluginMarketplace(p.Name, nil))
+		}
+	}
+	if current != nil {
+		for name := range current.Plugins {
+			use(name, pluginMarketplace(name, current))
+		}
+	}
+
+	orphans := make(map[string][]string)
+	for alias, names := range users {
+		for name := range names {
+			orphans[alias] = append(orphans[alias], name)
+		}
+		sort.Strings(orphans[alias])
+	}
+	return orphans
+}
+
+// pluginMarketplace returns the marketplace a plugin comes from, as
+// installed if current knows it, else from its name.
+func pluginMarketplace(name string, current *state.State) string {
+	if current != nil {
+		if p, ok := current.Plugins[name]; ok && p.Marketplace != "" {
+			return p.Marketplace
+		}
+	}
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,182 @@
+package sync
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/adamancini/clew/internal/config"
+	"github.com/adamancini/clew/internal/state"
+)
+
+func TestInverse(t *testing.T) {
+	tests := []struct {
+		name       string
+		op         Operation
+		wantOK     bool
+		wantAction string
+	}{
+		{"marketplace add", Operation{Type: "marketplace", Name: "m", Action: "add", Success: true}, true, "remove"},
+		{"plugin add", Operation{Type: "plugin", Name: "p@m", Action: "add", Success: true}, true, "uninstall"},
+		{"plugin enable", Operation{Type: "plugin", Name: "p@m", Action: "enable", Success: true}, true, "disable"},
+		{"plugin disable", Operation{Type: "plugin", Name: "p@m", Action: "disable", Success: true}, true, "enable"},
+		{"failed", Operation{Type: "plugin", Name: "p@m", Action: "add"}, false, ""},
+		{"skipped", Operation{Type: "plugin", Name: "p@m", Action: "add", Success: true, Skipped: true}, false, ""},
+		{"update", Operation{Type: "plugin", Name: "p@m", Action: "update", Success: true}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, ok := Inverse(tt.op)
+			if ok != tt.wantOK || inv.Action != tt.wantAction {
+				t.Errorf("Inverse() = %q, %v; want %q, %v", inv.Action, ok, tt.wantAction, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRevertPlan(t *testing.T) {
+	ops := []Operation{
+		{Type: "marketplace", Name: "new-market", Action: "add", Success: true},
+		{Type: "plugin", Name: "new@new-market", Action: "add", Success: true},
+		{Type: "plugin", Name: "old@official", Action: "add", Success: true},
+		{Type: "plugin", Name: "toggled@official", Action: "enable", Success: true},
+		{Type: "plugin", Name: "gone@official", Action: "add", Success: true},
+	}
+
+	before := &state.State{
+		Marketplaces: map[string]state.MarketplaceState{"official": {}},
+		Plugins: map[string]state.PluginState{
+			// Existed before the run; must not be uninstalled
+			"old@official":     {Name: "old", Enabled: true},
+			"toggled@official": {Name: "toggled", Enabled: false},
+		},
+	}
+	current := &state.State{
+		Marketplaces: map[string]state.MarketplaceState{"official": {}, "new-market": {}},
+		Plugins: map[string]state.PluginState{
+			"new@new-market":   {Name: "new", Enabled: true},
+			"old@official":     {Name: "old", Enabled: true},
+			"toggled@official": {Name: "toggled", Enabled: true},
+		},
+	}
+
+	plan := RevertPlan(ops, before, current)
+
+	want := []string{
+		"disable toggled@official",
+		"uninstall new@new-market",
+		"remove new-market",
+	}
+	if len(plan) != len(want) {
+		t.Fatalf("RevertPlan() = %v, want %v", plan, want)
+	}
+	for i, w := range want {
+		if got := plan[i].Action + " " + plan[i].Name; got != w {
+			t.Errorf("plan[%d] = %q, want %q", i, got, w)
+		}
+	}
+}
+
+func TestRevert(t *testing.T) {
+	syncer, mock := newMockSyncer()
+
+	plan := []Operation{
+		{Type: "plugin", Name: "p@m", Action: "uninstall"},
+		{Type: "plugin", Name: "q@m", Action: "enable"},
+		{Type: "marketplace", Name: "m", Action: "remove"},
+	}
+	current := &state.State{Plugins: map[string]state.PluginState{"q@m": {Name: "q"}}}
+
+	result, err := syncer.Revert(plan, current, Options{SettingsTarget: SettingsTargetAuto})
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if result.Updated != 3 || result.Failed != 0 {
+		t.Errorf("Updated = %d, Failed = %d; want 3, 0", result.Updated, result.Failed)
+	}
+	for i, op := range result.Operations {
+		if op.ID != i+1 {
+			t.Errorf("Operations[%d].ID = %d, want %d", i, op.ID, i+1)
+		}
+	}
+
+	want := []string{
+		"claude plugin uninstall p@m",
+		"claude plugin enable q@m",
+		"claude plugin marketplace remove m",
+	}
+	if len(mock.Commands) != len(want) {
+		t.Fatalf("Commands = %v, want %v", mock.Commands, want)
+	}
+	for i, w := range want {
+		if mock.Commands[i] != w {
+			t.Errorf("Commands[%d] = %q, want %q", i, mock.Commands[i], w)
+		}
+	}
+}
+
+func TestRemovalPlan(t *testing.T) {
+	syncer, mock := newMockSyncer()
+
+	plan := RemovalPlan([]string{"p@m", "q@other"}, []string{"m"})
+	removed, err := syncer.Revert(plan, nil, Options{})
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+
+	// Removals are reported after the sync they follow
+	result := &Result{Installed: 1, Operations: []Operation{{Type: "plugin", Name: "new@m", Action: "add", Success: true}}}
+	result.Merge(removed)
+	if result.Installed != 1 || result.Updated != 3 || len(result.Operations) != 4 || result.Operations[3].ID != 4 {
+		t.Errorf("merged result = %+v", result)
+	}
+
+	want := []string{
+		"claude plugin uninstall p@m",
+		"claude plugin uninstall q@other",
+		"claude plugin marketplace remove m",
+	}
+	if len(mock.Commands) != len(want) {
+		t.Fatalf("Commands = %v, want %v", mock.Commands, want)
+	}
+	for i, w := range want {
+		if mock.Commands[i] != w {
+			t.Errorf("Commands[%d] = %q, want %q", i, mock.Commands[i], w)
+		}
+	}
+}
+
+func TestRemovalKeepsMarketplaceOfFailedUninstall(t *testing.T) {
+	syncer, mock := newMockSyncer()
+	mock.Errors["claude plugin uninstall p@m"] = errors.New("exit status 1")
+
+	result, err := syncer.Revert(RemovalPlan([]string{"p@m"}, []string{"m"}), nil, Options{})
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if len(mock.Commands) != 1 {
+		t.Errorf("Commands = %v, want only the uninstall", mock.Commands)
+	}
+	if result.Failed != 1 || result.Skipped != 1 || !result.Operations[1].Skipped {
+		t.Errorf("result = %+v, want the marketplace removal skipped", result)
+	}
+}
+
+func TestRemovalOrphans(t *testing.T) {
+	clewfile := &config.Clewfile{Plugins: []config.Plugin{{Name: "declared@team"}}}
+	current := &state.State{Plugins: map[string]state.PluginState{
+		"gone@old":     {Marketplace: "old"},
+		"stays@shared": {Marketplace: "shared"},
+		"gone@shared":  {Marketplace: "shared"},
+	}}
+
+	got := RemovalOrphans([]string{"gone@old", "gone@shared"}, []string{"old", "shared", "team"}, clewfile, current)
+	want := map[string][]string{
+		"shared": {"stays@shared"},
+		"team":   {"declared@team"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RemovalOrphans() = %v, want %v", got, want)
+	}
+}
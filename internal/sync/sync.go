@@ -2,14 +2,23 @@
 package sync
 
 import (
-	"os"
-	"path/filepath"
+	"context"
+	"errors"
+	"fmt"
+	gosync "sync"
+	"time"
 
+	"github.com/adamancini/clew/internal/claudecli"
+	"github.com/adamancini/clew/internal/config"
 	"github.com/adamancini/clew/internal/diff"
+	"github.com/adamancini/clew/internal/logging"
+	"github.com/adamancini/clew/internal/state"
+	"github.com/adamancini/clew/internal/timing"
 )
 
 // Operation represents a single sync operation performed.
 type Operation struct {
+	ID          int    `json:"id"`              // Sequence number within the run (see clew redo)
 	Type        string `json:"type"`            // "marketplace" or "plugin"
 	Name        string `json:"name"`            // Item name
 	Action      string `json:"action"`          // "add", "enable", "disable"
@@ -18,6 +27,13 @@ type Operation struct {
 	Success     bool   `json:"success"`         // Whether operation succeeded
 	Skipped     bool   `json:"skipped"`         // Whether operation was skipped
 	Error       string `json:"error,omitempty"` // Error message if failed
+
+	// Output is the combined stdout and stderr of Command, cut to its last
+	// MaxOutputBytes, so failures can be debugged from JSON output, history
+	// and alerts without re-running the sync.
+	Output string `json:"output,omitempty"`
+
+	Duration time.Duration `json:"-"` // Time spent executing the operation
 }
 
 // Result represents the outcome of a sync operation.
@@ -28,7 +44,20 @@ type Result struct {
 	Failed     int
 	Attention  []string    // Items needing manual attention
 	Errors     []error     // Detailed error objects (not serialized to JSON)
-	Operations []Operation `json:"operations"` // Individual operations performed (always included in JSON)
+	Operations []Operation `json:"operations"`        // Individual operations performed (always included in JSON)
+	Aborted    string      `json:"aborted,omitempty"` // Item whose failure stopped the sync (on_failure: abort)
+
+	// Interrupted is set when the sync was cancelled (Ctrl-C): the running
+	// command was stopped and the remaining items were skipped.
+	Interrupted bool `json:"interrupted,omitempty"`
+
+	// NotLoggedIn is set when a claude command failed because the CLI is
+	// not logged in (ErrNotLoggedIn): the remaining items were skipped.
+	NotLoggedIn bool `json:"not_logged_in,omitempty"`
+
+	Timings []timing.Phase `json:"timings,omitempty"` // Per-phase durations (only with --timings)
+
+	mu gosync.Mutex // Guards Operations, Errors and Failed for add, fail and Merge
 }
 
 // Options configures sync behavior.
@@ -37,50 +66,78 @@ type Options struct {
 	Verbose bool
 	Quiet   bool
 	Short   bool // One-line-per-item output format
-}
 
-// FileEditor is an interface for filesystem operations.
-// This allows for mocking in tests.
-type FileEditor interface {
-	ReadFile(path string) ([]byte, error)
-	WriteFile(path string, data []byte, perm os.FileMode) error
+	SettingsTarget SettingsTarget // Where enable/disable changes are written
+	OnFailure      string         // Failure policy for items without one (the Clewfile's on_failure)
+
+	// DirectSettings writes all enable/disable changes with one edit per
+	// settings file after the installs, instead of one claude command each.
+	DirectSettings bool
 }
 
-// DefaultFileEditor uses os package for file operations.
-type DefaultFileEditor struct{}
+// SettingsTarget selects which settings file receives enable/disable changes.
+type SettingsTarget string
 
-func (e *DefaultFileEditor) ReadFile(path string) ([]byte, error) {
-	return os.ReadFile(path)
-}
+const (
+	// SettingsTargetAuto edits settings.local.json when it is where the
+	// plugin's current enabled state comes from, and otherwise uses
+	// `claude plugin enable/disable` (which writes settings.json).
+	SettingsTargetAuto SettingsTarget = "auto"
+	// SettingsTargetUser always uses `claude plugin enable/disable`.
+	SettingsTargetUser SettingsTarget = "settings"
+	// SettingsTargetLocal always writes settings.local.json directly.
+	SettingsTargetLocal SettingsTarget = "local"
+)
 
-func (e *DefaultFileEditor) WriteFile(path string, data []byte, perm os.FileMode) error {
-	return os.WriteFile(path, data, perm)
+// ParseSettingsTarget validates a --settings-target value. Empty means auto.
+func ParseSettingsTarget(s string) (SettingsTarget, error) {
+	switch t := SettingsTarget(s); t {
+	case "":
+		return SettingsTargetAuto, nil
+	case SettingsTargetAuto, SettingsTargetUser, SettingsTargetLocal:
+		return t, nil
+	default:
+		return "", fmt.Errorf("invalid settings target %q (must be auto, settings, or local)", s)
+	}
 }
 
+// FileEditor reads and writes Claude Code's settings files.
+// This allows for mocking in tests.
+type FileEditor = state.Files
+
+// DefaultFileEditor writes atomically, keeping the previous contents as a
+// .bak file (see state.AtomicFiles).
+type DefaultFileEditor = state.AtomicFiles
+
 // Syncer executes sync operations with a configurable command runner.
 type Syncer struct {
 	runner    CommandRunner
 	editor    FileEditor
-	claudeDir string // Path to ~/.claude directory
+	claudeDir string                  // Path to ~/.claude directory
+	caps      *claudecli.Capabilities // Supported claude commands (nil assumes all)
+	sleep     func(time.Duration)     // Waits between retries (nil uses a timer that ctx cuts short)
+	ctx       context.Context         // Cancels the sync (nil means never)
+	events    chan<- Event            // Progress events (nil means none)
+	verify    func(name string) error // Checks that an install landed (nil checks nothing)
 }
 
 // NewSyncer creates a Syncer with the default command runner and file editor.
 func NewSyncer() *Syncer {
-	home, _ := os.UserHomeDir()
+	claudeDir, _ := state.DefaultClaudeDir()
 	return &Syncer{
 		runner:    &DefaultCommandRunner{},
 		editor:    &DefaultFileEditor{},
-		claudeDir: filepath.Join(home, ".claude"),
+		claudeDir: claudeDir,
 	}
 }
 
 // NewSyncerWithRunner creates a Syncer with a custom command runner (for testing).
 func NewSyncerWithRunner(runner CommandRunner) *Syncer {
-	home, _ := os.UserHomeDir()
+	claudeDir, _ := state.DefaultClaudeDir()
 	return &Syncer{
 		runner:    runner,
 		editor:    &DefaultFileEditor{},
-		claudeDir: filepath.Join(home, ".claude"),
+		claudeDir: claudeDir,
 	}
 }
 
@@ -93,7 +150,44 @@ func NewSyncerWithRunnerAndEditor(runner CommandRunner, editor FileEditor, claud
 	}
 }
 
+// SetCapabilities adapts the commands the syncer runs to what the claude
+// CLI supports. Without it, every command is assumed to be available.
+func (s *Syncer) SetCapabilities(caps *claudecli.Capabilities) {
+	s.caps = caps
+}
+
+// SetInstallVerifier makes installPlugin call verify after claude reports
+// a plugin installed, and fail the install with ErrNotInstalled when verify
+// returns an error: claude can exit 0 without installing anything.
+func (s *Syncer) SetInstallVerifier(verify func(name string) error) {
+	s.verify = verify
+}
+
+// SetContext makes Execute stop when ctx is cancelled: the running claude
+// command is stopped and the items after it are skipped.
+func (s *Syncer) SetContext(ctx context.Context) {
+	s.ctx = ctx
+	if r, ok := s.runner.(*DefaultCommandRunner); ok {
+		r.Ctx = ctx
+	}
+}
+
+// interrupted reports whether the sync's context has been cancelled.
+func (s *Syncer) interrupted() bool {
+	return s.ctx != nil && s.ctx.Err() != nil
+}
+
+// capabilities returns the detected capabilities, or all of them.
+func (s *Syncer) capabilities() *claudecli.Capabilities {
+	if s.caps == nil {
+		return claudecli.All()
+	}
+	return s.caps
+}
+
 // Execute applies the diff to bring current state in line with Clewfile.
+// A failed item is retried or stops the sync according to its on_failure
+// policy; items after an abort or an interruption are recorded as skipped.
 func (s *Syncer) Execute(d *diff.Result, opts Options) (*Result, error) {
 	result := &Result{
 		Operations: []Operation{},
@@ -101,13 +195,20 @@ func (s *Syncer) Execute(d *diff.Result, opts Options) (*Result, error) {
 
 	// Process marketplaces first (plugins depend on them)
 	for _, m := range d.Marketplaces {
+		result.Interrupted = result.Interrupted || s.interrupted()
+		if result.stopped() {
+			if m.Action == diff.ActionAdd {
+				s.skipAborted(result, "marketplace", m.Alias, "add")
+			}
+			continue
+		}
 		switch m.Action {
 		case diff.ActionAdd:
-			op, err := s.addMarketplace(m)
-			result.Operations = append(result.Operations, op)
+			policy := failurePolicy(m.OnFailure(), opts.OnFailure)
+			op, err := s.attempt(policy, "marketplace", m.Alias, "add", func() (Operation, error) { return s.addMarketplace(m) })
+			s.record(result, op)
 			if err != nil {
-				result.Failed++
-				result.Errors = append(result.Errors, err)
+				result.fail(err, policy, "marketplace "+m.Alias)
 			} else if op.Skipped {
 				result.Skipped++
 			} else {
@@ -119,30 +220,70 @@ func (s *Syncer) Execute(d *diff.Result, opts Options) (*Result, error) {
 		case diff.ActionSkipGit:
 			// Skipped due to git status issues
 			result.Skipped++
-			result.Attention = append(result.Attention, "marketplace (git): "+m.Alias+" - has uncommitted changes")
+			result.Attention = append(result.Attention, "marketplace (git): "+m.Alias+" - has uncommitted changes or an unsigned HEAD")
+		case diff.ActionManaged:
+			// Blocked by enterprise policy; retrying would fail every run
+			result.Skipped++
+			result.Attention = append(result.Attention, "marketplace (managed): "+m.Alias+" - blocked by managed settings, cannot change")
 		}
 	}
 
 	// Process plugins
+	var flips []flip
 	for _, p := range d.Plugins {
+		result.Interrupted = result.Interrupted || s.interrupted()
+		if result.stopped() {
+			switch p.Action {
+			case diff.ActionAdd:
+				s.skipAborted(result, "plugin", p.Name, "add")
+			case diff.ActionEnable, diff.ActionDisable:
+				s.skipAborted(result, "plugin", p.Name, string(p.Action))
+			}
+			continue
+		}
 		switch p.Action {
 		case diff.ActionAdd:
-			op, err := s.installPlugin(p)
-			result.Operations = append(result.Operations, op)
+			policy := failurePolicy(p.OnFailure(), opts.OnFailure)
+			op, err := s.attempt(policy, "plugin", p.Name, "add", func() (Operation, error) { return s.installPlugin(p) })
+			s.record(result, op)
 			if err != nil {
-				result.Failed++
-				result.Errors = append(result.Errors, err)
-			} else if op.Skipped {
+				if errors.Is(err, ErrNotInstalled) {
+					result.Attention = append(result.Attention, "plugin (install): "+p.Name+" - claude reported success but the plugin is not installed")
+				}
+				result.fail(err, policy, "plugin "+p.Name)
+				break
+			}
+			if op.Skipped {
 				result.Skipped++
 			} else {
 				result.Installed++
 			}
+
+			// claude installs plugins enabled
+			if p.Desired.Enabled != nil && !*p.Desired.Enabled {
+				disable := diff.PluginDiff{Name: p.Name, Action: diff.ActionDisable, Desired: p.Desired}
+				if opts.DirectSettings {
+					flips = append(flips, flip{plugin: disable, policy: policy})
+					break
+				}
+				op, err := s.attempt(policy, "plugin", p.Name, "disable", func() (Operation, error) { return s.updatePluginState(disable, opts.SettingsTarget) })
+				s.record(result, op)
+				if err != nil {
+					result.fail(err, policy, "plugin "+p.Name)
+				}
+			}
 		case diff.ActionEnable, diff.ActionDisable:
-			op, err := s.updatePluginState(p)
-			result.Operations = append(result.Operations, op)
+			policy := failurePolicy(p.OnFailure(), opts.OnFailure)
+			if opts.DirectSettings {
+				flips = append(flips, flip{plugin: p, policy: policy, count: true})
+				break
+			}
+			op, err := s.attempt(policy, "plugin", p.Name, string(p.Action), func() (Operation, error) { return s.updatePluginState(p, opts.SettingsTarget) })
+			s.record(result, op)
 			if err != nil {
-				result.Failed++
-				result.Errors = append(result.Errors, err)
+				result.fail(err, policy, "plugin "+p.Name)
+			} else if op.Skipped {
+				result.Skipped++
 			} else {
 				result.Updated++
 			}
@@ -156,7 +297,7 @@ func (s *Syncer) Execute(d *diff.Result, opts Options) (*Result, error) {
 				Success:     true,
 				Skipped:     true,
 			}
-			result.Operations = append(result.Operations, op)
+			s.record(result, op)
 			result.Skipped++
 		case diff.ActionRemove:
 			// Info only - don't remove
@@ -164,9 +305,128 @@ func (s *Syncer) Execute(d *diff.Result, opts Options) (*Result, error) {
 		case diff.ActionSkipGit:
 			// Skipped due to git status issues
 			result.Skipped++
-			result.Attention = append(result.Attention, "plugin (git): "+p.Name+" - has uncommitted changes")
+			result.Attention = append(result.Attention, "plugin (git): "+p.Name+" - has uncommitted changes or an unsigned HEAD")
+		case diff.ActionManaged:
+			// Blocked by enterprise policy; retrying would fail every run
+			result.Skipped++
+			result.Attention = append(result.Attention, "plugin (managed): "+p.Name+" - managed settings, cannot change")
+		case diff.ActionBlocked:
+			// Flagged by clew scan; needs review before it may run
+			result.Skipped++
+			result.Attention = append(result.Attention, "plugin (scan): "+p.Name+" - blocked by scan policy (run 'clew scan "+p.Name+"')")
 		}
 	}
 
+	if len(flips) > 0 {
+		s.applyFlips(flips, opts, result)
+	}
+	s.applyKeybindings(d.Keybindings, opts, result)
+
+	numberOperations(result.Operations)
 	return result, nil
 }
+
+// Merge appends the operations and counts of other to r.
+func (r *Result) Merge(other *Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Installed += other.Installed
+	r.Updated += other.Updated
+	r.Skipped += other.Skipped
+	r.Failed += other.Failed
+	r.Attention = append(r.Attention, other.Attention...)
+	r.Errors = append(r.Errors, other.Errors...)
+	r.Operations = append(r.Operations, other.Operations...)
+	r.Interrupted = r.Interrupted || other.Interrupted
+	r.NotLoggedIn = r.NotLoggedIn || other.NotLoggedIn
+	numberOperations(r.Operations)
+}
+
+// numberOperations assigns each operation its 1-based position in the run.
+func numberOperations(ops []Operation) {
+	for i := range ops {
+		ops[i].ID = i + 1
+	}
+}
+
+// failurePolicy returns an item's on_failure policy, falling back to the
+// default and then to continue.
+func failurePolicy(item, fallback string) string {
+	switch {
+	case item != "":
+		return item
+	case fallback != "":
+		return fallback
+	default:
+		return config.OnFailureContinue
+	}
+}
+
+// attempt reports an operation started and runs it, running it a second
+// time if it fails and its policy is retry. The operation's Duration is
+// that of the last attempt only, so a retry does not inflate the timings
+// estimate learns from.
+func (s *Syncer) attempt(policy, itemType, name, action string, f func() (Operation, error)) (Operation, error) {
+	s.started(itemType, name, action)
+	op, err := timed(f)
+	if err != nil && policy == config.OnFailureRetry && !errors.Is(err, ErrNotLoggedIn) {
+		logging.Decisionf("%s %s failed, retrying (on_failure: retry): %v", op.Type, op.Name, err)
+		op, err = timed(f)
+	}
+	return op, err
+}
+
+// fail records a failed item and, when its policy is abort or claude is
+// not logged in, stops the sync.
+func (r *Result) fail(err error, policy, item string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Failed++
+	r.Errors = append(r.Errors, err)
+	if errors.Is(err, ErrNotLoggedIn) {
+		r.NotLoggedIn = true
+	} else if policy == config.OnFailureAbort {
+		r.Aborted = item
+	}
+}
+
+// stopped reports whether the remaining items are skipped.
+func (r *Result) stopped() bool {
+	return r.Aborted != "" || r.Interrupted || r.NotLoggedIn
+}
+
+// add appends a completed operation to r. It is safe for concurrent use.
+func (r *Result) add(op Operation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Operations = append(r.Operations, op)
+}
+
+// skipAborted records an item that was not run because the sync aborted
+// or was interrupted.
+func (s *Syncer) skipAborted(r *Result, itemType, name, action string) {
+	description := "Skipped: sync interrupted"
+	switch {
+	case r.Aborted != "":
+		description = "Skipped: sync aborted after " + r.Aborted + " failed"
+	case r.NotLoggedIn:
+		description = "Skipped: claude is not logged in"
+	}
+	r.Skipped++
+	s.record(r, Operation{
+		Type:        itemType,
+		Name:        name,
+		Action:      action,
+		Description: description,
+		Success:     true,
+		Skipped:     true,
+	})
+}
+
+// timed runs an operation and records how long it took.
+func timed(f func() (Operation, error)) (Operation, error) {
+	start := time.Now()
+	op, err := f()
+	op.Duration = time.Since(start)
+	return op, err
+}
@@ -0,0 +1,82 @@
+// Package timing records per-phase durations for performance diagnostics.
+package timing
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Phase is the measured duration of one named phase.
+type Phase struct {
+	Name       string        `json:"name" yaml:"name"`
+	Duration   time.Duration `json:"-" yaml:"-"`
+	DurationMs float64       `json:"duration_ms" yaml:"duration_ms"`
+}
+
+// Recorder collects phase durations in the order they complete.
+// A nil *Recorder is valid and records nothing, so callers can track
+// phases unconditionally.
+type Recorder struct {
+	start  time.Time
+	phases []Phase
+}
+
+// NewRecorder creates a recorder; the total is measured from now.
+func NewRecorder() *Recorder {
+	return &Recorder{start: time.Now()}
+}
+
+// Track starts timing a phase and returns a function that stops it.
+//
+//	defer rec.Track("state read")()
+func (r *Recorder) Track(name string) func() {
+	if r == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		r.Add(name, time.Since(start))
+	}
+}
+
+// Add records a phase with an already-measured duration.
+func (r *Recorder) Add(name string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.phases = append(r.phases, Phase{
+		Name:       name,
+		Duration:   d,
+		DurationMs: float64(d.Microseconds()) / 1000,
+	})
+}
+
+// Phases returns the recorded phases followed by a "total" entry.
+func (r *Recorder) Phases() []Phase {
+	if r == nil {
+		return nil
+	}
+	total := time.Since(r.start)
+	phases := make([]Phase, 0, len(r.phases)+1)
+	phases = append(phases, r.phases...)
+	return append(phases, Phase{
+		Name:       "total",
+		Duration:   total,
+		DurationMs: float64(total.Microseconds()) / 1000,
+	})
+}
+
+// Print writes a human-readable timing summary.
+func Print(w io.Writer, phases []Phase) {
+	if len(phases) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Timings:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, p := range phases {
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\t\n", p.Name, p.Duration.Round(time.Microsecond))
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,61 @@
+package timing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorderPhases(t *testing.T) {
+	rec := NewRecorder()
+	rec.Track("config load")()
+	rec.Add("plugin add foo@bar", 1500*time.Microsecond)
+
+	phases := rec.Phases()
+	if len(phases) != 3 {
+		t.Fatalf("len(Phases()) = %d, want 3", len(phases))
+	}
+
+	wantNames := []string{"config load", "plugin add foo@bar", "total"}
+	for i, name := range wantNames {
+		if phases[i].Name != name {
+			t.Errorf("phases[%d].Name = %q, want %q", i, phases[i].Name, name)
+		}
+	}
+
+	if phases[1].DurationMs != 1.5 {
+		t.Errorf("phases[1].DurationMs = %v, want 1.5", phases[1].DurationMs)
+	}
+}
+
+func TestNilRecorder(t *testing.T) {
+	var rec *Recorder
+	rec.Track("state read")()
+	rec.Add("diff", time.Second)
+
+	if phases := rec.Phases(); phases != nil {
+		t.Errorf("nil Recorder Phases() = %v, want nil", phases)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	var buf bytes.Buffer
+	Print(&buf, []Phase{
+		{Name: "state read", Duration: 2 * time.Millisecond},
+		{Name: "total", Duration: 3 * time.Millisecond},
+	})
+
+	out := buf.String()
+	for _, want := range []string{"Timings:", "state read", "2ms", "total", "3ms"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Print() output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	Print(&buf, nil)
+	if buf.Len() != 0 {
+		t.Errorf("Print(nil) wrote %q, want nothing", buf.String())
+	}
+}
@@ -0,0 +1,127 @@
+package update
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ChecksumsFile is the checksum list shipped with each release
+const ChecksumsFile = "checksums.txt"
+
+// LocalRelease prepares a downloaded release for installing without
+// network access and returns the binary and checksums.txt to verify it
+// with, both in dir. from is either a release tar.gz (see ExtractArchive)
+// or a bare binary as published on the release page (clew-linux-amd64),
+// with the release's checksums.txt downloaded next to it.
+func LocalRelease(from, dir string, platform Platform) (binary, checksums string, err error) {
+	gzipped, err := isGzip(from)
+	if err != nil {
+		return "", "", err
+	}
+	if gzipped {
+		return ExtractArchive(from, dir, platform)
+	}
+
+	checksums = filepath.Join(filepath.Dir(from), ChecksumsFile)
+	if _, err := os.Stat(checksums); err != nil {
+		return "", "", fmt.Errorf("no %s next to %s to verify it; download it from the same release", ChecksumsFile, from)
+	}
+	// Copied under its own name, which checksums.txt lists it by
+	src, err := os.Open(from)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open %s: %w", from, err)
+	}
+	defer func() { _ = src.Close() }()
+	binary = filepath.Join(dir, filepath.Base(from))
+	if err := extractFile(src, binary, 0755); err != nil {
+		return "", "", err
+	}
+	return binary, checksums, nil
+}
+
+// isGzip reports whether the file at path starts with the gzip magic bytes.
+func isGzip(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return false, nil // Too short for an archive
+	}
+	return magic[0] == 0x1f && magic[1] == 0x8b, nil
+}
+
+// ExtractArchive extracts the clew binary and checksums.txt from a release
+// tar.gz into dir, for installing without network access. The binary is the
+// entry named after the platform (clew-linux-amd64) or plain "clew". Entries
+// are written by base name only, so paths in the archive cannot escape dir.
+func ExtractArchive(archive, dir string, platform Platform) (binary, checksums string, err error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read archive %s: %w", archive, err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read archive %s: %w", archive, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Base(hdr.Name)
+		switch name {
+		case platform.BinaryName(), "clew":
+			binary = filepath.Join(dir, name)
+			if err := extractFile(tr, binary, 0755); err != nil {
+				return "", "", err
+			}
+		case ChecksumsFile:
+			checksums = filepath.Join(dir, name)
+			if err := extractFile(tr, checksums, 0644); err != nil {
+				return "", "", err
+			}
+		}
+	}
+
+	if binary == "" {
+		return "", "", fmt.Errorf("archive %s has no clew binary for %s/%s", archive, platform.OS, platform.Arch)
+	}
+	if checksums == "" {
+		return "", "", fmt.Errorf("archive %s has no %s to verify the binary", archive, ChecksumsFile)
+	}
+	return binary, checksums, nil
+}
+
+// extractFile writes the current archive entry to dst
+func extractFile(r io.Reader, dst string, mode os.FileMode) error {
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to extract %s: %w", filepath.Base(dst), err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,156 @@
+package update
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeArchive creates a tar.gz holding files.
+func writeArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	archive := filepath.Join(t.TempDir(), "clew_0.9.0_linux_amd64.tar.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range []interface{ Close() error }{tw, gz, f} {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return archive
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestExtractArchive(t *testing.T) {
+	platform := Platform{OS: "linux", Arch: "amd64"}
+	binary := "#!/bin/sh\necho clew\n"
+	archive := writeArchive(t, map[string]string{
+		"../../clew":    binary, // Must not escape the destination
+		"checksums.txt": sha256Hex(binary) + "  clew\n",
+		"README.md":     "ignored",
+	})
+
+	dir := t.TempDir()
+	gotBinary, gotChecksums, err := ExtractArchive(archive, dir, platform)
+	if err != nil {
+		t.Fatalf("ExtractArchive() error = %v", err)
+	}
+	if gotBinary != filepath.Join(dir, "clew") || gotChecksums != filepath.Join(dir, "checksums.txt") {
+		t.Errorf("ExtractArchive() = %s, %s", gotBinary, gotChecksums)
+	}
+	if info, err := os.Stat(gotBinary); err != nil || info.Mode()&0100 == 0 {
+		t.Errorf("binary should be extracted executable: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "README.md")); !os.IsNotExist(err) {
+		t.Error("unrelated entries should not be extracted")
+	}
+
+	if err := VerifyLocalChecksum(gotBinary, gotChecksums); err != nil {
+		t.Errorf("VerifyLocalChecksum() error = %v", err)
+	}
+}
+
+func TestExtractArchiveErrors(t *testing.T) {
+	platform := Platform{OS: "linux", Arch: "amd64"}
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"no binary", map[string]string{"checksums.txt": ""}, "no clew binary"},
+		{"no checksums", map[string]string{"clew-linux-amd64": "x"}, "no checksums.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ExtractArchive(writeArchive(t, tt.files), t.TempDir(), platform)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ExtractArchive() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyLocalChecksumMismatch(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "clew-linux-amd64")
+	checksums := filepath.Join(dir, "checksums.txt")
+	if err := os.WriteFile(binary, []byte("tampered"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(checksums, []byte(sha256Hex("original")+"  clew-linux-amd64\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := VerifyLocalChecksum(binary, checksums)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("VerifyLocalChecksum() error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestLocalReleaseBareBinary(t *testing.T) {
+	platform := Platform{OS: "linux", Arch: "amd64"}
+	binary := "#!/bin/sh\necho clew\n"
+	downloads := t.TempDir()
+	from := filepath.Join(downloads, platform.BinaryName())
+	if err := os.WriteFile(from, []byte(binary), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without checksums.txt the binary cannot be verified
+	if _, _, err := LocalRelease(from, t.TempDir(), platform); err == nil || !strings.Contains(err.Error(), ChecksumsFile) {
+		t.Errorf("LocalRelease() error = %v, want checksums.txt required", err)
+	}
+
+	checksums := sha256Hex(binary) + "  " + platform.BinaryName() + "\n"
+	if err := os.WriteFile(filepath.Join(downloads, ChecksumsFile), []byte(checksums), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	gotBinary, gotChecksums, err := LocalRelease(from, dir, platform)
+	if err != nil {
+		t.Fatalf("LocalRelease() error = %v", err)
+	}
+	if gotBinary != filepath.Join(dir, platform.BinaryName()) {
+		t.Errorf("binary = %s, want a copy in %s", gotBinary, dir)
+	}
+	if err := VerifyLocalChecksum(gotBinary, gotChecksums); err != nil {
+		t.Errorf("VerifyLocalChecksum() error = %v", err)
+	}
+}
+
+func TestLocalReleaseArchive(t *testing.T) {
+	platform := Platform{OS: "linux", Arch: "amd64"}
+	binary := "#!/bin/sh\necho clew\n"
+	archive := writeArchive(t, map[string]string{
+		"clew":          binary,
+		"checksums.txt": sha256Hex(binary) + "  clew\n",
+	})
+	gotBinary, gotChecksums, err := LocalRelease(archive, t.TempDir(), platform)
+	if err != nil {
+		t.Fatalf("LocalRelease() error = %v", err)
+	}
+	if err := VerifyLocalChecksum(gotBinary, gotChecksums); err != nil {
+		t.Errorf("VerifyLocalChecksum() error = %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package update
+
+import "runtime"
+
+// BuildInfo describes the running clew binary: the values stamped in at
+// build time plus the toolchain and platform it was built for.
+type BuildInfo struct {
+	Version   string `json:"version" yaml:"version"`       // Semantic version without a leading "v", or "dev"
+	Commit    string `json:"commit" yaml:"commit"`         // Git commit the binary was built from
+	Date      string `json:"date" yaml:"date"`             // Build date (RFC 3339 in release builds)
+	GoVersion string `json:"go_version" yaml:"go_version"` // Go toolchain, e.g. "go1.23.2"
+	OS        string `json:"os" yaml:"os"`
+	Arch      string `json:"arch" yaml:"arch"`
+}
+
+// NewBuildInfo returns the build info for a binary stamped with version,
+// commit and date.
+func NewBuildInfo(version, commit, date string) BuildInfo {
+	platform := Detect()
+	return BuildInfo{
+		Version:   NormalizeVersion(version),
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+		OS:        platform.OS,
+		Arch:      platform.Arch,
+	}
+}
@@ -0,0 +1,38 @@
+package update
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+)
+
+func TestNewBuildInfo(t *testing.T) {
+	info := NewBuildInfo("v0.9.0", "abc1234", "2026-01-02T03:04:05Z")
+
+	if info.Version != "0.9.0" {
+		t.Errorf("Version = %q, want 0.9.0", info.Version)
+	}
+	if info.Commit != "abc1234" || info.Date != "2026-01-02T03:04:05Z" {
+		t.Errorf("Commit/Date = %q/%q", info.Commit, info.Date)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+	if info.OS != runtime.GOOS || info.Arch != runtime.GOARCH {
+		t.Errorf("platform = %s/%s, want %s/%s", info.OS, info.Arch, runtime.GOOS, runtime.GOARCH)
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	var fields map[string]string
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	for _, key := range []string{"version", "commit", "date", "go_version", "os", "arch"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("JSON is missing %q: %s", key, data)
+		}
+	}
+}
@@ -1,10 +1,11 @@
 package update
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/adamancini/clew/internal/github"
 )
 
 // GitHubChecker checks for updates via GitHub API
@@ -15,6 +16,7 @@ type GitHubChecker struct {
 	repo           string      // Repository name
 	client         *http.Client
 	baseURL        string      // Base URL for GitHub API (for testing)
+	cacheDir       string      // Optional response cache directory
 }
 
 // GitHubRelease represents a GitHub release response
@@ -39,7 +41,7 @@ func NewGitHubChecker(currentVersion, owner, repo string) *GitHubChecker {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		baseURL: "https://api.github.com",
+		baseURL: github.DefaultBaseURL,
 	}
 }
 
@@ -49,6 +51,18 @@ func (c *GitHubChecker) WithToken(token string) *GitHubChecker {
 	return c
 }
 
+// WithCache caches GitHub API responses in dir
+func (c *GitHubChecker) WithCache(dir string) *GitHubChecker {
+	c.cacheDir = dir
+	return c
+}
+
+// WithTimeout limits how long a request to GitHub may take
+func (c *GitHubChecker) WithTimeout(d time.Duration) *GitHubChecker {
+	c.client.Timeout = d
+	return c
+}
+
 // CheckForUpdate checks if an update is available
 func (c *GitHubChecker) CheckForUpdate() (*UpdateInfo, error) {
 	// Get latest release from GitHub
@@ -88,34 +102,62 @@ func (c *GitHubChecker) CheckForUpdate() (*UpdateInfo, error) {
 	return info, nil
 }
 
-// getLatestRelease fetches the latest release from GitHub API
-func (c *GitHubChecker) getLatestRelease() (*GitHubRelease, error) {
-	url := fmt.Sprintf("%s/repos/%s/%s/releases/latest", c.baseURL, c.owner, c.repo)
+// CheckVersion looks up a specific release, e.g. "v0.8.1" or "0.8.1", for
+// installing it in place of the current version. Available reports whether
+// it differs from the current version.
+func (c *GitHubChecker) CheckVersion(version string) (*UpdateInfo, error) {
+	targetVer, err := ParseVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q: %w", version, err)
+	}
+	tag := "v" + NormalizeVersion(version)
 
-	req, err := http.NewRequest("GET", url, nil)
+	release, err := c.getRelease(tag)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get release %s: %w", tag, err)
 	}
 
-	// Set headers
-	req.Header.Set("Accept", "application/vnd.github+json")
-	if c.githubToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.githubToken)
+	available := true
+	if currentVer, err := ParseVersion(c.currentVersion); err == nil {
+		available = !targetVer.IsEqual(currentVer)
 	}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
+	assetURL, checksumURL := c.findAssetURLs(release, Detect())
+
+	return &UpdateInfo{
+		Available:      available,
+		CurrentVersion: NormalizeVersion(c.currentVersion),
+		LatestVersion:  NormalizeVersion(release.TagName),
+		ReleaseURL:     release.HTMLURL,
+		ReleaseNotes:   release.Body,
+		AssetURL:       assetURL,
+		ChecksumURL:    checksumURL,
+	}, nil
+}
+
+// getLatestRelease fetches the latest release from GitHub API
+func (c *GitHubChecker) getLatestRelease() (*GitHubRelease, error) {
+	client := github.NewClient(c.client, c.baseURL).
+		WithToken(c.githubToken).
+		WithCache(c.cacheDir)
+
+	var release GitHubRelease
+	if err := client.Get(fmt.Sprintf("/repos/%s/%s/releases/latest", c.owner, c.repo), &release); err != nil {
 		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
-	}
+	return &release, nil
+}
+
+// getRelease fetches the release for a tag from GitHub API
+func (c *GitHubChecker) getRelease(tag string) (*GitHubRelease, error) {
+	client := github.NewClient(c.client, c.baseURL).
+		WithToken(c.githubToken).
+		WithCache(c.cacheDir)
 
 	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := client.Get(fmt.Sprintf("/repos/%s/%s/releases/tags/%s", c.owner, c.repo, tag), &release); err != nil {
+		return nil, err
 	}
 
 	return &release, nil
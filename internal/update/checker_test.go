@@ -220,6 +220,64 @@ func TestGitHubCheckerCheckForUpdate_APIError(t *testing.T) {
 	}
 }
 
+func TestGitHubCheckerCheckVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/adamancini/clew/releases/tags/v0.8.1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		platform := Detect()
+		release := GitHubRelease{
+			TagName: "v0.8.1",
+			Body:    "Release notes for 0.8.1",
+			Assets: []struct {
+				Name               string `json:"name"`
+				BrowserDownloadURL string `json:"browser_download_url"`
+			}{
+				{Name: platform.BinaryName(), BrowserDownloadURL: "https://github.com/.../" + platform.BinaryName()},
+				{Name: "checksums.txt", BrowserDownloadURL: "https://github.com/.../checksums.txt"},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(release)
+	}))
+	defer server.Close()
+
+	checker := NewGitHubChecker("0.8.2", "adamancini", "clew")
+	checker.baseURL = server.URL
+
+	// Both spellings of the version find the tag
+	for _, version := range []string{"v0.8.1", "0.8.1"} {
+		info, err := checker.CheckVersion(version)
+		if err != nil {
+			t.Fatalf("CheckVersion(%s) error = %v", version, err)
+		}
+		if !info.Available {
+			t.Errorf("CheckVersion(%s): older release should be installable", version)
+		}
+		if info.LatestVersion != "0.8.1" {
+			t.Errorf("LatestVersion = %s, want 0.8.1", info.LatestVersion)
+		}
+		if info.AssetURL == "" || info.ChecksumURL == "" {
+			t.Errorf("CheckVersion(%s) missing asset URLs: %+v", version, info)
+		}
+	}
+
+	// Reinstalling the running version is a no-op
+	current := NewGitHubChecker("v0.8.1", "adamancini", "clew")
+	current.baseURL = server.URL
+	if info, err := current.CheckVersion("0.8.1"); err != nil || info.Available {
+		t.Errorf("CheckVersion(current) = %+v, %v; want not available", info, err)
+	}
+
+	if _, err := checker.CheckVersion("v9.9.9"); err == nil {
+		t.Error("Expected error for a release that does not exist")
+	}
+	if _, err := checker.CheckVersion("latest"); err == nil {
+		t.Error("Expected error for an invalid version")
+	}
+}
+
 func TestGitHubCheckerCheckForUpdate_WithToken(t *testing.T) {
 	// Create mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -2,6 +2,7 @@ package update
 
 import (
 	"bufio"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -14,19 +15,27 @@ import (
 // HTTPDownloader downloads binaries over HTTP
 type HTTPDownloader struct {
 	client *http.Client
+	ctx    context.Context // Cancels downloads in flight
 }
 
 // NewHTTPDownloader creates a new HTTP downloader
 func NewHTTPDownloader() *HTTPDownloader {
 	return &HTTPDownloader{
 		client: &http.Client{},
+		ctx:    context.Background(),
 	}
 }
 
+// WithContext cancels downloads in flight when ctx is cancelled
+func (d *HTTPDownloader) WithContext(ctx context.Context) *HTTPDownloader {
+	d.ctx = ctx
+	return d
+}
+
 // Download downloads a file from url to dst
 func (d *HTTPDownloader) Download(url string, dst string) error {
 	// Create the request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -75,6 +84,33 @@ func (d *HTTPDownloader) VerifyChecksum(file, checksumURL string) error {
 		return fmt.Errorf("failed to download checksums: %w", err)
 	}
 
+	return compareChecksum(file, actualChecksum, checksums)
+}
+
+// VerifyLocalChecksum verifies file against a checksums file on disk, for
+// updates that must not touch the network
+func VerifyLocalChecksum(file, checksumsPath string) error {
+	actualChecksum, err := calculateSHA256(file)
+	if err != nil {
+		return fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+
+	f, err := os.Open(checksumsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read checksums: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	checksums, err := parseChecksums(f)
+	if err != nil {
+		return err
+	}
+
+	return compareChecksum(file, actualChecksum, checksums)
+}
+
+// compareChecksum checks a file's checksum against its checksums entry
+func compareChecksum(file, actualChecksum string, checksums map[string]string) error {
 	// Find the expected checksum for this file
 	filename := getFilename(file)
 	expectedChecksum, found := checksums[filename]
@@ -107,9 +143,12 @@ func calculateSHA256(filepath string) (string, error) {
 }
 
 // downloadChecksums downloads and parses a checksums.txt file
-// Expected format: <sha256>  <filename>
 func (d *HTTPDownloader) downloadChecksums(url string) (map[string]string, error) {
-	resp, err := d.client.Get(url)
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -119,9 +158,14 @@ func (d *HTTPDownloader) downloadChecksums(url string) (map[string]string, error
 		return nil, fmt.Errorf("checksums download failed with status %d", resp.StatusCode)
 	}
 
-	// Parse the checksums file
+	return parseChecksums(resp.Body)
+}
+
+// parseChecksums parses a checksums.txt file
+// Expected format: <sha256>  <filename>
+func parseChecksums(r io.Reader) (map[string]string, error) {
 	checksums := make(map[string]string)
-	scanner := bufio.NewScanner(resp.Body)
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
 		parts := strings.Fields(line)
@@ -0,0 +1,93 @@
+package update
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adamancini/clew/internal/atomicfile"
+	"github.com/adamancini/clew/internal/xdg"
+)
+
+// NotifyInterval is how often the update notice queries GitHub.
+const NotifyInterval = 24 * time.Hour
+
+// notifyState is the cached result of the last update check.
+type notifyState struct {
+	CheckedAt     time.Time `json:"checked_at"`
+	LatestVersion string    `json:"latest_version,omitempty"`
+}
+
+// Notifier produces the "new version available" notice shown after normal
+// commands. It asks its Checker at most once per NotifyInterval and caches
+// the answer in a file, so most runs make no network request.
+type Notifier struct {
+	currentVersion string
+	checker        Checker
+	cachePath      string
+	now            func() time.Time
+}
+
+// NewNotifier creates a notifier for currentVersion that caches the latest
+// release in cachePath.
+func NewNotifier(currentVersion string, checker Checker, cachePath string) *Notifier {
+	return &Notifier{
+		currentVersion: currentVersion,
+		checker:        checker,
+		cachePath:      cachePath,
+		now:            time.Now,
+	}
+}
+
+// DefaultNotifyCachePath returns the update check cache in clew's cache directory.
+func DefaultNotifyCachePath() (string, error) {
+	dir, err := xdg.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "update-check.json"), nil
+}
+
+// Notice returns a one-line notice if a newer release exists, or "" if
+// clew is current, is a development build, or the check failed. Failed
+// checks are cached too, so an offline machine is not retried every run.
+func (n *Notifier) Notice() string {
+	current, err := ParseVersion(n.currentVersion)
+	if err != nil {
+		return "" // Development builds have no comparable version
+	}
+
+	latest := n.latest()
+	if latest == "" {
+		return ""
+	}
+	latestVer, err := ParseVersion(latest)
+	if err != nil || !latestVer.IsGreaterThan(current) {
+		return ""
+	}
+	return fmt.Sprintf("clew v%s available, run clew version --update", NormalizeVersion(latest))
+}
+
+// latest returns the latest release version, from the cache if it is
+// fresh enough.
+func (n *Notifier) latest() string {
+	var cached notifyState
+	if data, err := os.ReadFile(n.cachePath); err == nil {
+		if json.Unmarshal(data, &cached) == nil && n.now().Sub(cached.CheckedAt) < NotifyInterval {
+			return cached.LatestVersion
+		}
+	}
+
+	fresh := notifyState{CheckedAt: n.now().UTC()}
+	if info, err := n.checker.CheckForUpdate(); err == nil {
+		fresh.LatestVersion = info.LatestVersion
+	}
+	if data, err := json.Marshal(fresh); err == nil {
+		if os.MkdirAll(filepath.Dir(n.cachePath), 0755) == nil {
+			_ = atomicfile.WriteFile(n.cachePath, data, 0644)
+		}
+	}
+	return fresh.LatestVersion
+}
@@ -0,0 +1,78 @@
+package update
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeChecker reports a fixed latest version and counts calls.
+type fakeChecker struct {
+	latest string
+	err    error
+	calls  int
+}
+
+func (f *fakeChecker) CheckForUpdate() (*UpdateInfo, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &UpdateInfo{LatestVersion: f.latest}, nil
+}
+
+func TestNotifierNotice(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		latest  string
+		err     error
+		want    string
+	}{
+		{"newer release", "0.8.2", "v0.9.0", nil, "clew v0.9.0 available, run clew version --update"},
+		{"up to date", "0.9.0", "v0.9.0", nil, ""},
+		{"ahead of release", "1.0.0", "v0.9.0", nil, ""},
+		{"development build", "dev", "v0.9.0", nil, ""},
+		{"check fails", "0.8.2", "", errors.New("offline"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &fakeChecker{latest: tt.latest, err: tt.err}
+			n := NewNotifier(tt.current, checker, filepath.Join(t.TempDir(), "update-check.json"))
+			if got := n.Notice(); got != tt.want {
+				t.Errorf("Notice() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifierChecksOncePerInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clew", "update-check.json")
+	checker := &fakeChecker{latest: "v0.9.0"}
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	n := NewNotifier("0.8.2", checker, path)
+	n.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if n.Notice() == "" {
+			t.Fatal("Notice() = \"\", want a notice")
+		}
+	}
+	if checker.calls != 1 {
+		t.Errorf("calls = %d within one interval, want 1", checker.calls)
+	}
+
+	// A failed check is cached as well
+	checker.err = errors.New("offline")
+	now = now.Add(NotifyInterval)
+	if got := n.Notice(); got != "" {
+		t.Errorf("Notice() = %q after a failed check, want none", got)
+	}
+	_ = n.Notice()
+	if checker.calls != 2 {
+		t.Errorf("calls = %d, want one more check after the interval", checker.calls)
+	}
+}
@@ -0,0 +1,52 @@
+// Package xdg locates clew's own directories under the XDG base
+// directories: caches that can be rebuilt, and state that cannot. It also
+// locates the configuration directory Clewfiles are searched in.
+package xdg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CacheDir returns clew's cache directory, $XDG_CACHE_HOME/clew or
+// ~/.cache/clew. Files there can be deleted at any time.
+func CacheDir() (string, error) {
+	return dir("XDG_CACHE_HOME", ".cache")
+}
+
+// StateDir returns clew's state directory, $XDG_STATE_HOME/clew or
+// ~/.local/state/clew. Files there record what clew did on this machine
+// and should survive clearing caches.
+func StateDir() (string, error) {
+	return dir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// ConfigHome returns the user's configuration directory, $XDG_CONFIG_HOME
+// or ~/.config. Clewfiles are kept in its claude directory.
+func ConfigHome() (string, error) {
+	return base("XDG_CONFIG_HOME", ".config")
+}
+
+// dir returns the clew directory under the base directory named by env,
+// or under home/fallback when env is unset.
+func dir(env, fallback string) (string, error) {
+	b, err := base(env, fallback)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(b, "clew"), nil
+}
+
+// base returns the base directory named by env, or home/fallback when env
+// is unset.
+func base(env, fallback string) (string, error) {
+	if b := os.Getenv(env); b != "" {
+		return b, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, fallback), nil
+}
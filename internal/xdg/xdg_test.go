@@ -0,0 +1,38 @@
+package xdg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	if got, err := CacheDir(); err != nil || got != filepath.Join(home, ".cache", "clew") {
+		t.Errorf("CacheDir() = %q, %v", got, err)
+	}
+	if got, err := StateDir(); err != nil || got != filepath.Join(home, ".local", "state", "clew") {
+		t.Errorf("StateDir() = %q, %v", got, err)
+	}
+
+	if got, err := ConfigHome(); err != nil || got != filepath.Join(home, ".config") {
+		t.Errorf("ConfigHome() = %q, %v", got, err)
+	}
+
+	t.Setenv("XDG_CACHE_HOME", "/xdg/cache")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	if got, _ := CacheDir(); got != filepath.Join("/xdg/cache", "clew") {
+		t.Errorf("CacheDir() = %q, want under XDG_CACHE_HOME", got)
+	}
+	if got, _ := StateDir(); got != filepath.Join("/xdg/state", "clew") {
+		t.Errorf("StateDir() = %q, want under XDG_STATE_HOME", got)
+	}
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	if got, _ := ConfigHome(); got != "/xdg/config" {
+		t.Errorf("ConfigHome() = %q, want XDG_CONFIG_HOME", got)
+	}
+}